@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"marketplace/internal/cache/redis"
+	"marketplace/internal/config"
+	"marketplace/internal/dbs/postgres"
+	"marketplace/internal/models"
+	cachesessionrepo "marketplace/internal/repositories/cache/session"
+	userrepo "marketplace/internal/repositories/db/user"
+	userservice "marketplace/internal/services/user"
+	"os"
+)
+
+const usage = "usage: admin grant-role <login> <user|moderator|admin>"
+
+func main() {
+	if len(os.Args) != 4 || os.Args[1] != "grant-role" {
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	login, role := os.Args[2], models.Role(os.Args[3])
+
+	cfg := config.MustLoad()
+
+	log := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelInfo}))
+
+	ctx := context.Background()
+
+	db, err := postgres.New(ctx, postgres.Config{
+		Addr:     cfg.DB.Addr,
+		Port:     cfg.DB.Port,
+		User:     cfg.DB.User,
+		Password: cfg.DB.Password,
+		DB:       cfg.DB.DB})
+	if err != nil {
+		log.Error("failed connect to db", "err", err)
+		os.Exit(1)
+	}
+
+	// Sessions of the user are invalidated, so the cache is needed as well.
+	cache, err := redis.New(ctx, redis.Config{Addr: cfg.Cache.Addr, Password: cfg.Cache.Password, DB: cfg.Cache.DB})
+	if err != nil {
+		db.Close()
+		log.Error("failed connect to cache", "err", err)
+		os.Exit(1)
+	}
+
+	userRepo := userrepo.New(db)
+
+	sessionCacheRepo := cachesessionrepo.New(cache, cfg.Cache.AccessTTL, cfg.Cache.RefreshTTL)
+
+	userService := userservice.New(log, userRepo, userRepo, userRepo, sessionCacheRepo)
+
+	err = userService.SetRole(ctx, login, role)
+	db.Close()
+	cache.Close()
+	if err != nil {
+		log.Error("failed to grant role", "err", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("%s is now %s\n", login, role)
+}
@@ -25,13 +25,36 @@ func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	app, err := app.New(ctx, log, cfg.DB, cfg.Cache, cfg.FileStorage)
+	app, err := app.New(ctx, log, cfg)
 	if err != nil {
 		log.Error("failed to init app", slog.String("error", err.Error()))
 		os.Exit(1)
 	}
 
-	err = server.StartServer(ctx, &cfg.HTTPServer, log, app.AuthService, app.PostService)
+	err = server.StartServer(ctx, &cfg.HTTPServer, log, &server.Services{
+		Auth:         app.AuthService,
+		Post:         app.PostService,
+		Search:       app.SearchService,
+		Notification: app.NotificationService,
+		Events:       app.EventService,
+		Feed:         app.FeedService,
+		Conversation: app.ConversationService,
+		Offer:        app.OfferService,
+		Auction:      app.AuctionService,
+		Order:        app.OrderService,
+		Review:       app.ReviewService,
+		Profile:      app.ProfileService,
+		Password:     app.PasswordService,
+		Email:        app.EmailService,
+		TwoFactor:    app.TwoFactorService,
+		OIDC:         app.OIDCService,
+		APIKeys:      app.APIKeyService,
+		Admin:        app.AdminService,
+		Bans:         app.BanService,
+		Blocks:       app.BlockService,
+		Account:      app.AccountService,
+		Reports:      app.ReportService,
+	})
 	if err != nil {
 		log.Error("failed to start server", "error", err)
 		os.Exit(1)
@@ -7,34 +7,110 @@ import (
 	"marketplace/internal/cache/redis"
 	"marketplace/internal/config"
 	"marketplace/internal/dbs/postgres"
+	filemailer "marketplace/internal/mailer/file"
+	smtpmailer "marketplace/internal/mailer/smtp"
+	"marketplace/internal/models"
+	oidcclient "marketplace/internal/oidc/client"
+	"marketplace/internal/payments/fake"
+	cacheattemptsrepo "marketplace/internal/repositories/cache/attempts"
+	cacheblockrepo "marketplace/internal/repositories/cache/block"
+	cachechallengerepo "marketplace/internal/repositories/cache/challenge"
+	cacheeventrepo "marketplace/internal/repositories/cache/event"
+	cachefeedrepo "marketplace/internal/repositories/cache/feed"
+	cachefeedticketrepo "marketplace/internal/repositories/cache/feedticket"
+	cachejobrepo "marketplace/internal/repositories/cache/job"
+	cachemailsrepo "marketplace/internal/repositories/cache/mails"
+	cacheoidcstaterepo "marketplace/internal/repositories/cache/oidcstate"
 	cachepostrepo "marketplace/internal/repositories/cache/post"
+	cacheresetrepo "marketplace/internal/repositories/cache/reset"
 	cachesessionrepo "marketplace/internal/repositories/cache/session"
+	cacheverifyrepo "marketplace/internal/repositories/cache/verify"
+	apikeyrepo "marketplace/internal/repositories/db/apikey"
+	auctionrepo "marketplace/internal/repositories/db/auction"
+	banrepo "marketplace/internal/repositories/db/ban"
+	blockrepo "marketplace/internal/repositories/db/block"
+	conversationrepo "marketplace/internal/repositories/db/conversation"
+	identityrepo "marketplace/internal/repositories/db/identity"
+	notificationrepo "marketplace/internal/repositories/db/notification"
+	offerrepo "marketplace/internal/repositories/db/offer"
+	orderrepo "marketplace/internal/repositories/db/order"
 	postrepo "marketplace/internal/repositories/db/post"
+	reportrepo "marketplace/internal/repositories/db/report"
+	reviewrepo "marketplace/internal/repositories/db/review"
+	searchrepo "marketplace/internal/repositories/db/search"
+	twofactorrepo "marketplace/internal/repositories/db/twofactor"
 	userrepo "marketplace/internal/repositories/db/user"
 	filerepo "marketplace/internal/repositories/file"
+	accountservice "marketplace/internal/services/account"
+	apikeyservice "marketplace/internal/services/apikey"
+	auctionservice "marketplace/internal/services/auction"
 	authservice "marketplace/internal/services/auth"
+	banservice "marketplace/internal/services/ban"
+	blockservice "marketplace/internal/services/block"
+	conversationservice "marketplace/internal/services/conversation"
+	emailservice "marketplace/internal/services/email"
+	eventservice "marketplace/internal/services/event"
+	feedservice "marketplace/internal/services/feed"
+	lockoutservice "marketplace/internal/services/lockout"
+	notificationservice "marketplace/internal/services/notification"
+	offerservice "marketplace/internal/services/offer"
+	oidcservice "marketplace/internal/services/oidc"
+	orderservice "marketplace/internal/services/order"
+	passwordservice "marketplace/internal/services/password"
 	postservice "marketplace/internal/services/post"
+	profileservice "marketplace/internal/services/profile"
+	reportservice "marketplace/internal/services/report"
+	reviewservice "marketplace/internal/services/review"
+	searchservice "marketplace/internal/services/search"
+	twofactorservice "marketplace/internal/services/twofactor"
 	userservice "marketplace/internal/services/user"
+	jwttokens "marketplace/internal/tokens/jwt"
+	opaquetokens "marketplace/internal/tokens/opaque"
+	"marketplace/internal/utils/encryption"
+	"net/http"
+	"time"
 )
 
 type App struct {
-	AuthService AuthService
-	PostService PostService
+	AuthService         AuthService
+	PostService         PostService
+	SearchService       SearchService
+	NotificationService NotificationService
+	EventService        EventService
+	FeedService         FeedService
+	ConversationService ConversationService
+	OfferService        OfferService
+	AuctionService      AuctionService
+	OrderService        OrderService
+	ReviewService       ReviewService
+	ProfileService      ProfileService
+	PasswordService     PasswordService
+	EmailService        EmailService
+	TwoFactorService    TwoFactorService
+	OIDCService         OIDCService
+	APIKeyService       APIKeyService
+	AdminService        AdminService
+	BanService          BanService
+	BlockService        BlockService
+	AccountService      AccountService
+	ReportService       ReportService
 }
 
-func New(ctx context.Context, log *slog.Logger, dbCfg config.DB, cacheConfig config.Cache, fileStorageCfg config.FileStorage) (*App, error) {
+// New connects to the database and the cache and wires every service the
+// server needs from cfg.
+func New(ctx context.Context, log *slog.Logger, cfg *config.Config) (*App, error) {
 	db, err := postgres.New(ctx, postgres.Config{
-		Addr:     dbCfg.Addr,
-		Port:     dbCfg.Port,
-		User:     dbCfg.User,
-		Password: dbCfg.Password,
-		DB:       dbCfg.DB})
+		Addr:     cfg.DB.Addr,
+		Port:     cfg.DB.Port,
+		User:     cfg.DB.User,
+		Password: cfg.DB.Password,
+		DB:       cfg.DB.DB})
 	if err != nil {
 		log.Error("failed connect to db", "err", err)
 		return nil, fmt.Errorf("failed connect to db: %w", err)
 	}
 
-	cache, err := redis.New(ctx, redis.Config{Addr: cacheConfig.Addr, Password: cacheConfig.Password, DB: cacheConfig.DB})
+	cache, err := redis.New(ctx, redis.Config{Addr: cfg.Cache.Addr, Password: cfg.Cache.Password, DB: cfg.Cache.DB})
 	if err != nil {
 		log.Error("failed connect to cache", "err", err)
 		return nil, fmt.Errorf("failed connect to cache: %w", err)
@@ -42,22 +118,190 @@ func New(ctx context.Context, log *slog.Logger, dbCfg config.DB, cacheConfig con
 
 	userRepo := userrepo.New(db)
 
-	sessionCacheRepo := cachesessionrepo.New(cache, cacheConfig.SessionTTL)
+	sessionCacheRepo := cachesessionrepo.New(cache, cfg.Cache.AccessTTL, cfg.Cache.RefreshTTL)
 
-	postCacheRepo := cachepostrepo.New(cache, cacheConfig.DocumentsTTL)
+	postCacheRepo := cachepostrepo.New(cache, cfg.Cache.DocumentsTTL)
+	blockCacheRepo := cacheblockrepo.New(cache, cfg.Cache.BlocksTTL)
 
-	userService := userservice.New(log, userRepo, userRepo)
+	eventCacheRepo := cacheeventrepo.New(cache, cfg.Events.HistorySize, cfg.Events.HistoryTTL)
 
-	authService := authservice.New(log, userService, userService, sessionCacheRepo)
+	eventService := eventservice.New(log, eventCacheRepo)
+
+	userService := userservice.New(log, userRepo, userRepo, userRepo, sessionCacheRepo)
+
+	var mailer passwordservice.Mailer
+	switch cfg.Mailer.Driver {
+	case "smtp":
+		mailer = smtpmailer.New(cfg.Mailer.SMTP.Host, cfg.Mailer.SMTP.Port, cfg.Mailer.SMTP.Username, cfg.Mailer.SMTP.Password, cfg.Mailer.From)
+	case "file":
+		mailer = filemailer.New(log, cfg.Mailer.Dir)
+	default:
+		return nil, fmt.Errorf("unknown mailer driver: %s", cfg.Mailer.Driver)
+	}
+
+	mailsRepo := cachemailsrepo.New(cache, cfg.Emails.ResendWindow)
+
+	emailService := emailservice.New(log, userRepo, userRepo, cacheverifyrepo.New(cache, cfg.Emails.VerifyTTL), mailsRepo, mailer, cfg.Emails.VerifyURL, cfg.Emails.ResendLimit, cfg.Emails.RequireVerifiedToPost)
+
+	loginPolicy := lockoutservice.Policy{
+		FreeAttempts:     cfg.Logins.FreeAttempts,
+		BaseDelay:        cfg.Logins.BaseDelay,
+		MaxDelay:         cfg.Logins.MaxDelay,
+		LockoutThreshold: cfg.Logins.LockoutThreshold,
+		LockoutDuration:  cfg.Logins.LockoutDuration,
+	}
+
+	ipPolicy := loginPolicy
+	ipPolicy.FreeAttempts = cfg.Logins.IPFreeAttempts
+	ipPolicy.LockoutThreshold = cfg.Logins.IPLockoutThreshold
+
+	lockoutService := lockoutservice.New(log, cacheattemptsrepo.New(cache, cfg.Logins.Window), loginPolicy, ipPolicy)
+
+	secretCipher, err := encryption.New(cfg.TwoFactor.EncryptionKey)
+	if err != nil {
+		log.Error("failed to init totp encryption", "err", err)
+		return nil, fmt.Errorf("failed to init totp encryption: %w", err)
+	}
+
+	twoFactorService := twofactorservice.New(log, twofactorrepo.New(db), secretCipher, lockoutService, cfg.TwoFactor.Issuer, cfg.TwoFactor.Skew, cfg.TwoFactor.RecoveryCodes)
+
+	challengeCacheRepo := cachechallengerepo.New(cache, cfg.TwoFactor.ChallengeTTL)
+
+	var accessTokens authservice.AccessTokens
+	switch cfg.Tokens.Strategy {
+	case "opaque":
+		accessTokens = opaquetokens.New(sessionCacheRepo)
+	case "jwt":
+		keys := make([]jwttokens.Key, 0, len(cfg.Tokens.Keys))
+		for _, key := range cfg.Tokens.Keys {
+			keys = append(keys, jwttokens.Key{ID: key.ID, Algorithm: key.Algorithm, Secret: key.Secret})
+		}
+
+		jwtTokens, err := jwttokens.New(keys, cfg.Tokens.ActiveKey, cfg.Tokens.Issuer, cfg.Cache.AccessTTL, sessionCacheRepo)
+		if err != nil {
+			log.Error("failed to init jwt tokens", "err", err)
+			return nil, fmt.Errorf("failed to init jwt tokens: %w", err)
+		}
+		accessTokens = jwtTokens
+	default:
+		return nil, fmt.Errorf("unknown token strategy: %s", cfg.Tokens.Strategy)
+	}
+
+	authService := authservice.New(log, userService, userService, sessionCacheRepo, accessTokens, eventService, emailService, lockoutService, twoFactorService, challengeCacheRepo, cfg.Cache.UserTTL)
+
+	providers := make(map[string]oidcservice.Provider, len(cfg.OIDC.Providers))
+	for _, provider := range cfg.OIDC.Providers {
+		providers[provider.Name] = oidcclient.New(&http.Client{Timeout: 10 * time.Second}, oidcclient.Config{
+			Issuer:       provider.Issuer,
+			ClientID:     provider.ClientID,
+			ClientSecret: provider.ClientSecret,
+			RedirectURL:  provider.RedirectURL,
+			Scopes:       provider.Scopes,
+		})
+	}
+
+	identityRepo := identityrepo.New(db)
+
+	oidcService := oidcservice.New(log, providers, identityRepo, cacheoidcstaterepo.New(cache, cfg.OIDC.StateTTL), userService, authService, cfg.OIDC.StateTTL)
+
+	apiKeyService := apikeyservice.New(log, apikeyrepo.New(db), userService, cfg.APIKeys.MaxPerUser)
+
+	banService := banservice.New(log, userService, banrepo.New(db), authService, postCacheRepo, cfg.Bans.Admins)
+
+	passwordService := passwordservice.New(log, userRepo, userRepo, sessionCacheRepo, accessTokens, lockoutService, cacheresetrepo.New(cache, cfg.Passwords.ResetTTL), mailsRepo, mailer, cfg.Passwords.ResetURL, cfg.Emails.ResendLimit, cfg.Passwords.QueueSize)
+
+	go passwordService.Run(ctx)
 
 	postRepo := postrepo.New(db)
 
-	fileStorage := filerepo.NewRepository(fileStorageCfg.Path)
+	blockRepo := blockrepo.New(db)
+
+	blockService := blockservice.New(log, userService, blockRepo, blockCacheRepo)
+
+	fileStorage := filerepo.NewRepository(cfg.FileStorage.Path)
+
+	postService := postservice.New(log, postRepo, postRepo, postRepo, postRepo, fileStorage, postCacheRepo, eventService, blockRepo, blockCacheRepo)
+
+	profileService := profileservice.New(log, userRepo, userRepo, fileStorage)
+
+	notificationRepo := notificationrepo.New(db)
+
+	notificationService := notificationservice.New(log, notificationRepo, notificationRepo, notificationRepo)
+
+	conversationRepo := conversationrepo.New(db)
+
+	conversationService := conversationservice.New(log, conversationRepo, conversationRepo, conversationRepo, postRepo, notificationService)
+
+	offerRepo := offerrepo.New(db)
+
+	offerService := offerservice.New(log, offerRepo, offerRepo, offerRepo, postRepo, notificationService, cfg.Offers.TTL)
+
+	go offerservice.NewExpirer(log, offerRepo, notificationService, cfg.Offers.ExpireInterval).Run(ctx)
+
+	auctionRepo := auctionrepo.New(db)
+
+	auctionService := auctionservice.New(log, auctionRepo, postRepo, cfg.Auctions.SnipingWindow, cfg.Auctions.SnipingExtension)
+
+	go auctionservice.NewCloser(log, auctionRepo, notificationService, cfg.Auctions.CloseInterval).Run(ctx)
+
+	orderRepo := orderrepo.New(db)
+
+	orderService := orderservice.New(log, orderRepo, orderRepo, orderRepo, postRepo, offerRepo, fake.New(), notificationService, cfg.Orders.ReservationTTL)
+
+	go orderservice.NewExpirer(log, orderRepo, notificationService, cfg.Orders.ExpireInterval).Run(ctx)
+
+	reviewRepo := reviewrepo.New(db)
+
+	moderators := models.NewModerators(cfg.Moderators)
+
+	reviewService := reviewservice.New(log, reviewRepo, reviewRepo, reviewRepo, postRepo, orderRepo, userRepo, notificationService, moderators)
+
+	reportService := reportservice.New(log, reportrepo.New(db), postRepo, postCacheRepo, notificationService, cfg.Reports.Threshold, moderators)
+
+	searchRepo := searchrepo.New(db)
+
+	searchService := searchservice.New(log, searchRepo, searchRepo, searchRepo, searchRepo, searchRepo)
+
+	matcher := searchservice.NewMatcher(log, searchRepo, searchRepo, notificationService, cfg.Searches.MatcherQueueSize)
+
+	postService.AddListener(matcher)
+
+	go matcher.Run(ctx)
+
+	feedService := feedservice.New(log, cachefeedrepo.New(cache), cachefeedticketrepo.New(cache, cfg.Feed.TicketTTL), userService, cfg.Feed.QueueSize, cfg.Feed.ClientBuffer, cfg.Feed.MaxConnectionsPerUser, cfg.Feed.TicketTTL)
+
+	postService.AddListener(feedService)
+
+	go feedService.Run(ctx)
+
+	accountService := accountservice.New(log, userRepo, userRepo, postRepo, userRepo, fileStorage, filerepo.NewRepository(cfg.Accounts.ExportPath), cachejobrepo.New(cache, cfg.Accounts.JobTTL), sessionCacheRepo, orderService, postCacheRepo, cfg.Accounts.QueueSize, cfg.Accounts.ExportCooldown)
+
+	accountService.Resume(ctx)
 
-	postService := postservice.New(log, postRepo, postRepo, postRepo, fileStorage, postCacheRepo)
+	go accountService.Run(ctx)
 
 	return &App{
-		AuthService: authService,
-		PostService: postService,
+		AuthService:         authService,
+		PostService:         postService,
+		SearchService:       searchService,
+		NotificationService: notificationService,
+		EventService:        eventService,
+		FeedService:         feedService,
+		ConversationService: conversationService,
+		OfferService:        offerService,
+		AuctionService:      auctionService,
+		OrderService:        orderService,
+		ReviewService:       reviewService,
+		ProfileService:      profileService,
+		PasswordService:     passwordService,
+		EmailService:        emailService,
+		TwoFactorService:    twoFactorService,
+		OIDCService:         oidcService,
+		APIKeyService:       apiKeyService,
+		AdminService:        userService,
+		BanService:          banService,
+		BlockService:        blockService,
+		AccountService:      accountService,
+		ReportService:       reportService,
 	}, nil
 }
@@ -4,16 +4,151 @@ import (
 	"context"
 	"io"
 	"marketplace/internal/models"
+	"time"
 )
 
 type AuthService interface {
-	Register(ctx context.Context, login string, password string) error
-	Login(ctx context.Context, login string, password string) (string, error)
+	Register(ctx context.Context, login string, password string, email string) error
+	Login(ctx context.Context, login string, password string, client *models.ClientInfo) (*models.LoginResult, error)
+	CompleteTwoFactor(ctx context.Context, challengeToken string, code string, client *models.ClientInfo) (*models.TokenPair, error)
+	Refresh(ctx context.Context, refreshToken string, client *models.ClientInfo) (*models.TokenPair, error)
 	UserByToken(ctx context.Context, token string) (*models.User, error)
 	Logout(ctx context.Context, token string) error
+	Sessions(ctx context.Context, requester *models.User, currentToken string) ([]*models.Session, error)
+	RevokeSession(ctx context.Context, requester *models.User, id string) error
+	RevokeAllSessions(ctx context.Context, requester *models.User) error
 }
 
 type PostService interface {
 	AddPost(ctx context.Context, requerster *models.User, post *models.PostWithDocument, file io.Reader) (*models.PostWithDocument, error)
 	FilteredPosts(ctx context.Context, limit int, offset int, filter *models.PostsFilter, requester *models.User) ([]*models.PostWithDocument, error)
+	UpdatePost(ctx context.Context, requester *models.User, id string, update *models.PostUpdate) (*models.PostWithDocument, error)
+	DeletePost(ctx context.Context, requester *models.User, id string) error
+}
+
+type SearchService interface {
+	AddSearch(ctx context.Context, requester *models.User, filter *models.PostsFilter) (*models.SavedSearch, error)
+	Searches(ctx context.Context, requester *models.User) ([]*models.SavedSearch, error)
+	Search(ctx context.Context, requester *models.User, id string) (*models.SavedSearch, error)
+	UpdateSearch(ctx context.Context, requester *models.User, id string, filter *models.PostsFilter) (*models.SavedSearch, error)
+	DeleteSearch(ctx context.Context, requester *models.User, id string) error
+	Matches(ctx context.Context, requester *models.User, id string, limit int) ([]*models.SearchMatch, error)
+	MarkSeen(ctx context.Context, requester *models.User, id string, postIDs []string) error
+}
+
+type NotificationService interface {
+	Notifications(ctx context.Context, requester *models.User, limit int, offset int, unreadOnly bool) ([]*models.Notification, int, error)
+	MarkRead(ctx context.Context, requester *models.User, id string) error
+	MarkAllRead(ctx context.Context, requester *models.User) error
+}
+
+type EventService interface {
+	Subscribe(ctx context.Context, userID string, lastEventID string) (<-chan *models.Event, error)
+}
+
+type FeedService interface {
+	Subscribe(ctx context.Context, requester *models.User, filter *models.PostsFilter) (models.PostSubscription, error)
+	IssueTicket(ctx context.Context, requester *models.User) (*models.FeedTicket, error)
+	UserByTicket(ctx context.Context, ticket string) (*models.User, error)
+}
+
+type ConversationService interface {
+	StartConversation(ctx context.Context, requester *models.User, postID string, text string) (*models.Conversation, error)
+	Conversations(ctx context.Context, requester *models.User, limit int, offset int) ([]*models.Conversation, error)
+	Messages(ctx context.Context, requester *models.User, conversationID string, cursor string, limit int) ([]*models.Message, string, error)
+	SendMessage(ctx context.Context, requester *models.User, conversationID string, text string) (*models.Message, error)
+}
+
+type OfferService interface {
+	MakeOffer(ctx context.Context, requester *models.User, postID string, amount int64) (*models.Offer, error)
+	Offers(ctx context.Context, requester *models.User, postID string) ([]*models.Offer, error)
+	MyOffers(ctx context.Context, requester *models.User, role models.OfferRole, limit int, offset int) ([]*models.Offer, error)
+	AcceptOffer(ctx context.Context, requester *models.User, postID string, offerID string) (*models.Offer, error)
+	RejectOffer(ctx context.Context, requester *models.User, postID string, offerID string) (*models.Offer, error)
+	CounterOffer(ctx context.Context, requester *models.User, postID string, offerID string, amount int64) (*models.Offer, error)
+}
+
+type AuctionService interface {
+	PlaceBid(ctx context.Context, requester *models.User, postID string, amount int64) (*models.Bid, *models.Auction, error)
+}
+
+type OrderService interface {
+	CreateOrder(ctx context.Context, requester *models.User, postID string) (*models.Order, error)
+	Order(ctx context.Context, requester *models.User, id string) (*models.Order, error)
+	MyOrders(ctx context.Context, requester *models.User, role models.OrderRole, limit int, offset int) ([]*models.Order, error)
+	PayOrder(ctx context.Context, requester *models.User, id string, paymentToken string) (*models.Order, error)
+	CompleteOrder(ctx context.Context, requester *models.User, id string) (*models.Order, error)
+	CancelOrder(ctx context.Context, requester *models.User, id string) (*models.Order, error)
+}
+
+type ReviewService interface {
+	AddReview(ctx context.Context, requester *models.User, postID string, rating int, text string) (*models.Review, error)
+	Reviews(ctx context.Context, login string, limit int, offset int) ([]*models.Review, models.Rating, error)
+	Reply(ctx context.Context, requester *models.User, id string, text string) (*models.Review, error)
+	PendingReplies(ctx context.Context, requester *models.User, limit int, offset int) ([]*models.Review, error)
+	ApproveReply(ctx context.Context, requester *models.User, id string) (*models.Review, error)
+	RejectReply(ctx context.Context, requester *models.User, id string) (*models.Review, error)
+}
+
+type ProfileService interface {
+	Profile(ctx context.Context, login string) (*models.Profile, error)
+	MyProfile(ctx context.Context, requester *models.User) (*models.Profile, error)
+	UpdateProfile(ctx context.Context, requester *models.User, update *models.ProfileUpdate, avatar io.Reader) (*models.Profile, error)
+}
+
+type PasswordService interface {
+	ChangePassword(ctx context.Context, requester *models.User, token string, current string, newPassword string, client *models.ClientInfo) error
+	RequestReset(ctx context.Context, login string, client *models.ClientInfo) error
+	ResetPassword(ctx context.Context, token string, newPassword string) error
+}
+
+type EmailService interface {
+	Verify(ctx context.Context, token string) error
+	ResendVerification(ctx context.Context, requester *models.User) error
+	CheckCanPost(ctx context.Context, requester *models.User) error
+}
+
+type TwoFactorService interface {
+	Enroll(ctx context.Context, requester *models.User) (*models.TOTPEnrollment, error)
+	Confirm(ctx context.Context, requester *models.User, code string, client *models.ClientInfo) ([]string, error)
+	Disable(ctx context.Context, requester *models.User, code string, client *models.ClientInfo) error
+}
+
+type OIDCService interface {
+	Begin(ctx context.Context, providerName string, requester *models.User) (*models.ExternalStart, error)
+	Callback(ctx context.Context, providerName string, state string, binding string, code string, client *models.ClientInfo) (*models.ExternalLogin, error)
+}
+
+type APIKeyService interface {
+	APIKeys(ctx context.Context, requester *models.User) ([]*models.APIKey, error)
+	CreateAPIKey(ctx context.Context, requester *models.User, name string, scopes []string, expiresAt *time.Time) (*models.APIKey, string, error)
+	RevokeAPIKey(ctx context.Context, requester *models.User, id string) error
+	AuthenticateAPIKey(ctx context.Context, key string) (*models.User, *models.APIKey, error)
+}
+
+type AdminService interface {
+	SetRole(ctx context.Context, login string, role models.Role) error
+}
+
+type BanService interface {
+	Ban(ctx context.Context, requester *models.User, login string, reason string, until *time.Time) (*models.Ban, error)
+	Unban(ctx context.Context, requester *models.User, login string, reason string) error
+}
+
+type BlockService interface {
+	Block(ctx context.Context, requester *models.User, login string) error
+	Unblock(ctx context.Context, requester *models.User, login string) error
+}
+
+type AccountService interface {
+	Export(ctx context.Context, requester *models.User, refresh bool) (*models.Job, io.ReadCloser, error)
+	DeleteAccount(ctx context.Context, requester *models.User) (*models.Job, error)
+	Job(ctx context.Context, requester *models.User, id string) (*models.Job, error)
+}
+
+type ReportService interface {
+	Report(ctx context.Context, requester *models.User, postID string, reason models.ReportReason, text string) (*models.Report, error)
+	OpenReports(ctx context.Context, requester *models.User, limit int, offset int) ([]*models.Report, error)
+	Resolve(ctx context.Context, requester *models.User, id string) (*models.Report, error)
+	Dismiss(ctx context.Context, requester *models.User, id string) (*models.Report, error)
 }
@@ -3,6 +3,7 @@ package redis
 import (
 	"context"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/redis/go-redis/v9"
@@ -38,6 +39,96 @@ func (c *Client) Del(ctx context.Context, keys ...string) error {
 	return nil
 }
 
+func (c *Client) GetDel(ctx context.Context, key string) (string, error) {
+	res, err := c.redisClient.GetDel(ctx, key).Result()
+	if err != nil && err != redis.Nil {
+		return "", err
+	}
+	return res, nil
+}
+
+func (c *Client) SAdd(ctx context.Context, key string, members ...string) error {
+	return c.redisClient.SAdd(ctx, key, members).Err()
+}
+
+func (c *Client) SRem(ctx context.Context, key string, members ...string) error {
+	return c.redisClient.SRem(ctx, key, members).Err()
+}
+
+func (c *Client) SMembers(ctx context.Context, key string) ([]string, error) {
+	return c.redisClient.SMembers(ctx, key).Result()
+}
+
+func (c *Client) Incr(ctx context.Context, key string) (int64, error) {
+	return c.redisClient.Incr(ctx, key).Result()
+}
+
+func (c *Client) Expire(ctx context.Context, key string, expiration time.Duration) error {
+	return c.redisClient.Expire(ctx, key, expiration).Err()
+}
+
+func (c *Client) ZAdd(ctx context.Context, key string, score float64, member string) error {
+	return c.redisClient.ZAdd(ctx, key, redis.Z{Score: score, Member: member}).Err()
+}
+
+func (c *Client) ZRangeByScore(ctx context.Context, key string, min string, max string) ([]string, error) {
+	return c.redisClient.ZRangeByScore(ctx, key, &redis.ZRangeBy{Min: min, Max: max}).Result()
+}
+
+func (c *Client) ZRemRangeByRank(ctx context.Context, key string, start int64, stop int64) error {
+	return c.redisClient.ZRemRangeByRank(ctx, key, start, stop).Err()
+}
+
+func (c *Client) Publish(ctx context.Context, channel string, message string) error {
+	return c.redisClient.Publish(ctx, channel, message).Err()
+}
+
+// Subscribe subscribes to the channel and returns the stream of received
+// payloads. The returned function releases the subscription and closes the
+// stream.
+func (c *Client) Subscribe(ctx context.Context, channel string) (<-chan string, func() error, error) {
+	op := pkg + "Subscribe"
+
+	pubsub := c.redisClient.Subscribe(ctx, channel)
+
+	if _, err := pubsub.Receive(ctx); err != nil {
+		_ = pubsub.Close()
+		return nil, nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	messages := pubsub.Channel()
+	out := make(chan string)
+	done := make(chan struct{})
+
+	go func() {
+		defer close(out)
+		for {
+			select {
+			case msg, ok := <-messages:
+				if !ok {
+					return
+				}
+				select {
+				case out <- msg.Payload:
+				case <-done:
+					return
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+
+	unsubscribe := func() error {
+		once.Do(func() { close(done) })
+		return pubsub.Close()
+	}
+
+	return out, unsubscribe, nil
+}
+
 func New(ctx context.Context, cfg Config) (*Client, error) {
 	op := pkg + "New"
 
@@ -50,8 +141,14 @@ func New(ctx context.Context, cfg Config) (*Client, error) {
 	}
 
 	if err := client.redisClient.Ping(ctx).Err(); err != nil {
+		_ = client.redisClient.Close()
 		return nil, fmt.Errorf("%s: redis: ping failed: %w", op, err)
 	}
 
 	return client, nil
 }
+
+// Close releases the connections of the client.
+func (c *Client) Close() error {
+	return c.redisClient.Close()
+}
@@ -10,10 +10,30 @@ import (
 
 type Config struct {
 	Env string `yaml:"env" env-default:"prod"`
+	// Moderators are the logins that moderate reviews and work the report
+	// queue. Replaces reviews.moderators and reports.moderators.
+	Moderators []string `yaml:"moderators"`
 	DB
 	Cache       `yaml:"cache"`
 	FileStorage `yaml:"file_storage"`
 	HTTPServer  `yaml:"http_server"`
+	Searches    `yaml:"searches"`
+	Events      `yaml:"events"`
+	Feed        `yaml:"feed"`
+	Offers      `yaml:"offers"`
+	Auctions    `yaml:"auctions"`
+	Orders      `yaml:"orders"`
+	Passwords   `yaml:"passwords"`
+	Mailer      `yaml:"mailer"`
+	Emails      `yaml:"emails"`
+	Logins      `yaml:"logins"`
+	TwoFactor   `yaml:"two_factor"`
+	OIDC        `yaml:"oidc"`
+	APIKeys     `yaml:"api_keys"`
+	Tokens      `yaml:"tokens"`
+	Bans        `yaml:"bans"`
+	Accounts    `yaml:"accounts"`
+	Reports     `yaml:"reports"`
 }
 
 type DB struct {
@@ -28,8 +48,11 @@ type Cache struct {
 	Addr         string        `env:"REDIS_ADDR" env-default:"localhost"`
 	Password     string        `env:"REDIS_PASSWORD" env-required:"true"`
 	DB           int           `env:"REDIS_DB" env-required:"true"`
-	SessionTTL   time.Duration `yaml:"session_ttl" env-default:"1h"`
+	AccessTTL    time.Duration `yaml:"access_ttl" env-default:"15m"`
+	RefreshTTL   time.Duration `yaml:"refresh_ttl" env-default:"720h"`
+	UserTTL      time.Duration `yaml:"user_ttl" env-default:"30s"`
 	DocumentsTTL time.Duration `yaml:"documents_ttl" env-default:"1m"`
+	BlocksTTL    time.Duration `yaml:"blocks_ttl" env-default:"10m"`
 }
 
 type FileStorage struct {
@@ -37,9 +60,159 @@ type FileStorage struct {
 }
 
 type HTTPServer struct {
-	Address     string        `yaml:"address" env-default:"0.0.0.0:8082"`
-	Timeout     time.Duration `yaml:"timeout" env-defalut:"4s"`
-	IdleTimeout time.Duration `yaml:"idle_timeout" env-defalut:"60s"`
+	Address      string        `yaml:"address" env-default:"0.0.0.0:8082"`
+	Timeout      time.Duration `yaml:"timeout" env-defalut:"4s"`
+	IdleTimeout  time.Duration `yaml:"idle_timeout" env-defalut:"60s"`
+	SSEHeartbeat time.Duration `yaml:"sse_heartbeat" env-default:"15s"`
+	WSPing       time.Duration `yaml:"ws_ping" env-default:"30s"`
+	// WSOrigins are the browser origins allowed to open WebSockets. Clients
+	// that send no Origin header are not browsers and are always allowed.
+	WSOrigins []string `yaml:"ws_origins"`
+}
+
+type Searches struct {
+	MatcherQueueSize int `yaml:"matcher_queue_size" env-default:"1000"`
+}
+
+type Events struct {
+	HistorySize int64         `yaml:"history_size" env-default:"100"`
+	HistoryTTL  time.Duration `yaml:"history_ttl" env-default:"24h"`
+}
+
+type Feed struct {
+	QueueSize             int           `yaml:"queue_size" env-default:"1000"`
+	ClientBuffer          int           `yaml:"client_buffer" env-default:"16"`
+	MaxConnectionsPerUser int           `yaml:"max_connections_per_user" env-default:"5"`
+	TicketTTL             time.Duration `yaml:"ticket_ttl" env-default:"30s"`
+}
+
+type Offers struct {
+	TTL            time.Duration `yaml:"ttl" env-default:"48h"`
+	ExpireInterval time.Duration `yaml:"expire_interval" env-default:"1m"`
+}
+
+type Auctions struct {
+	SnipingWindow    time.Duration `yaml:"sniping_window" env-default:"2m"`
+	SnipingExtension time.Duration `yaml:"sniping_extension" env-default:"2m"`
+	CloseInterval    time.Duration `yaml:"close_interval" env-default:"10s"`
+}
+
+type Orders struct {
+	ReservationTTL time.Duration `yaml:"reservation_ttl" env-default:"30m"`
+	ExpireInterval time.Duration `yaml:"expire_interval" env-default:"1m"`
+}
+
+type Passwords struct {
+	ResetTTL  time.Duration `yaml:"reset_ttl" env-default:"1h"`
+	ResetURL  string        `yaml:"reset_url" env-default:"http://localhost:8082/password/reset"`
+	QueueSize int           `yaml:"queue_size" env-default:"100"`
+}
+
+type Mailer struct {
+	Driver string `yaml:"driver" env-default:"file"`
+	Dir    string `yaml:"dir" env-default:"./mail/"`
+	From   string `yaml:"from" env-default:"no-reply@marketplace.local"`
+	SMTP   `yaml:"smtp"`
+}
+
+type Emails struct {
+	VerifyTTL             time.Duration `yaml:"verify_ttl" env-default:"24h"`
+	VerifyURL             string        `yaml:"verify_url" env-default:"http://localhost:8082/email/verify"`
+	ResendLimit           int64         `yaml:"resend_limit" env-default:"3"`
+	ResendWindow          time.Duration `yaml:"resend_window" env-default:"1h"`
+	RequireVerifiedToPost bool          `yaml:"require_verified_to_post" env-default:"false"`
+}
+
+// Logins limits password guessing. Failures are counted per login and per
+// client IP within Window; see lockoutservice.Policy for how they turn into
+// delays and lockouts.
+type Logins struct {
+	Window             time.Duration `yaml:"window" env-default:"1h"`
+	BaseDelay          time.Duration `yaml:"base_delay" env-default:"1s"`
+	MaxDelay           time.Duration `yaml:"max_delay" env-default:"1m"`
+	LockoutDuration    time.Duration `yaml:"lockout_duration" env-default:"15m"`
+	FreeAttempts       int64         `yaml:"free_attempts" env-default:"3"`
+	LockoutThreshold   int64         `yaml:"lockout_threshold" env-default:"10"`
+	IPFreeAttempts     int64         `yaml:"ip_free_attempts" env-default:"20"`
+	IPLockoutThreshold int64         `yaml:"ip_lockout_threshold" env-default:"100"`
+}
+
+// TwoFactor configures TOTP. EncryptionKey is a base64 encoded 32 byte
+// AES-256 key the TOTP secrets are encrypted with at rest.
+type TwoFactor struct {
+	Issuer        string        `yaml:"issuer" env-default:"Marketplace"`
+	EncryptionKey string        `env:"TOTP_ENCRYPTION_KEY" env-required:"true"`
+	Skew          int           `yaml:"skew" env-default:"1"`
+	RecoveryCodes int           `yaml:"recovery_codes" env-default:"10"`
+	ChallengeTTL  time.Duration `yaml:"challenge_ttl" env-default:"5m"`
+}
+
+// OIDC lists the OpenID Connect providers users can sign in with. A provider
+// is addressed by its name in /api/auth/oidc/{provider}, and its redirect URL
+// must point at the matching callback.
+type OIDC struct {
+	StateTTL  time.Duration  `yaml:"state_ttl" env-default:"10m"`
+	Providers []OIDCProvider `yaml:"providers"`
+}
+
+type OIDCProvider struct {
+	Name         string   `yaml:"name"`
+	Issuer       string   `yaml:"issuer"`
+	ClientID     string   `yaml:"client_id"`
+	ClientSecret string   `yaml:"client_secret"`
+	RedirectURL  string   `yaml:"redirect_url"`
+	Scopes       []string `yaml:"scopes"`
+}
+
+type APIKeys struct {
+	MaxPerUser int `yaml:"max_per_user" env-default:"10"`
+}
+
+// Tokens chooses how access tokens are made. "opaque" tokens are looked up in
+// the cache on every request; "jwt" tokens are signed and verified without it,
+// with only a denylist of revoked tokens kept there. JWTs are signed with the
+// active key and verified with any of the keys, so that keys can be rotated.
+type Tokens struct {
+	Strategy  string     `yaml:"strategy" env-default:"opaque"`
+	Issuer    string     `yaml:"issuer" env-default:"marketplace"`
+	ActiveKey string     `yaml:"active_key"`
+	Keys      []TokenKey `yaml:"keys"`
+}
+
+// TokenKey is a JWT signing key. Secret is base64 encoded: a secret of at
+// least 32 bytes for HS256, or a 32 byte Ed25519 seed for EdDSA.
+type TokenKey struct {
+	ID        string `yaml:"id"`
+	Algorithm string `yaml:"algorithm"`
+	Secret    string `yaml:"secret"`
+}
+
+type SMTP struct {
+	Host     string `yaml:"host" env:"SMTP_HOST" env-default:"localhost"`
+	Port     int    `yaml:"port" env:"SMTP_PORT" env-default:"587"`
+	Username string `yaml:"username" env:"SMTP_USERNAME"`
+	Password string `env:"SMTP_PASSWORD"`
+}
+
+// Bans lists the logins that may ban and unban users.
+type Bans struct {
+	Admins []string `yaml:"admins"`
+}
+
+// Accounts configures data exports and account deletion. Jobs, and the
+// latest export of each user, are kept for JobTTL. A user may ask for a fresh
+// export once per ExportCooldown.
+type Accounts struct {
+	JobTTL         time.Duration `yaml:"job_ttl" env-default:"24h"`
+	QueueSize      int           `yaml:"queue_size" env-default:"100"`
+	ExportPath     string        `yaml:"export_path" env-default:"./static/exports/"`
+	ExportCooldown time.Duration `yaml:"export_cooldown" env-default:"1h"`
+}
+
+// Reports configures abuse reports. A post is hidden once it has Threshold
+// open reports; zero never hides posts.
+type Reports struct {
+	Threshold int `yaml:"threshold" env-default:"5"`
 }
 
 func MustLoad() *Config {
@@ -57,5 +230,9 @@ func MustLoad() *Config {
 		log.Fatalf("cannot read config: %s", err)
 	}
 
+	if cfg.Auctions.SnipingWindow <= 0 || cfg.Auctions.SnipingExtension <= 0 {
+		log.Fatal("auctions: sniping_window and sniping_extension must be positive")
+	}
+
 	return &cfg
 }
@@ -0,0 +1,26 @@
+package dto
+
+import "time"
+
+type BidRequest struct {
+	Amount int64 `json:"amount"`
+}
+
+type AuctionResponse struct {
+	StartPrice   int64     `json:"start_price"`
+	MinIncrement int64     `json:"min_increment"`
+	CurrentBid   int64     `json:"current_bid"`
+	MinimumBid   int64     `json:"minimum_bid"`
+	BidCount     int       `json:"bid_count"`
+	LeaderLogin  string    `json:"leader_login,omitempty"`
+	EndsAt       time.Time `json:"ends_at"`
+	Closed       bool      `json:"closed"`
+}
+
+type BidResponse struct {
+	ID        string           `json:"id"`
+	PostID    string           `json:"post_id"`
+	Amount    int64            `json:"amount"`
+	CreatedAt time.Time        `json:"created_at"`
+	Auction   *AuctionResponse `json:"auction"`
+}
@@ -0,0 +1,32 @@
+package dto
+
+import "time"
+
+type ConversationRequest struct {
+	PostID string `json:"post_id"`
+	Text   string `json:"text"`
+}
+
+type ConversationResponse struct {
+	ID            string    `json:"id"`
+	PostID        string    `json:"post_id"`
+	PostHeader    string    `json:"post_header"`
+	BuyerLogin    string    `json:"buyer_login"`
+	SellerLogin   string    `json:"seller_login"`
+	CreatedAt     time.Time `json:"created_at"`
+	LastMessageAt time.Time `json:"last_message_at"`
+	UnreadCount   int       `json:"unread_count"`
+}
+
+type MessageRequest struct {
+	Text string `json:"text"`
+}
+
+type MessageResponse struct {
+	ID          string     `json:"id"`
+	SenderLogin string     `json:"sender_login"`
+	Text        string     `json:"text"`
+	IsMine      bool       `json:"is_mine"`
+	CreatedAt   time.Time  `json:"created_at"`
+	ReadAt      *time.Time `json:"read_at,omitempty"`
+}
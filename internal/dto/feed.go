@@ -0,0 +1,12 @@
+package dto
+
+type FeedFilterRequest struct {
+	MinPrice uint `json:"min_price"`
+	MaxPrice uint `json:"max_price"`
+}
+
+type FeedMessage struct {
+	PostID string        `json:"post_id,omitempty"`
+	Post   *PostResponse `json:"post,omitempty"`
+	Error  string        `json:"error,omitempty"`
+}
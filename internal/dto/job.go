@@ -0,0 +1,34 @@
+package dto
+
+import "time"
+
+type JobResponse struct {
+	ID         string     `json:"id"`
+	Kind       string     `json:"kind"`
+	Status     string     `json:"status"`
+	Error      string     `json:"error,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// ExportProfile is the profile.json entry of a personal data export. Avatar
+// is the name of the avatar file within the archive.
+type ExportProfile struct {
+	Login        string    `json:"login"`
+	Email        string    `json:"email,omitempty"`
+	DisplayName  string    `json:"display_name"`
+	Bio          string    `json:"bio"`
+	Avatar       string    `json:"avatar,omitempty"`
+	RegisteredAt time.Time `json:"registered_at"`
+}
+
+// ExportPost is an entry of posts.json in a personal data export. Image is
+// the name of the image file within the archive.
+type ExportPost struct {
+	ID        string    `json:"id"`
+	Header    string    `json:"header"`
+	Text      string    `json:"text"`
+	Price     int64     `json:"price"`
+	CreatedAt time.Time `json:"created_at"`
+	Image     string    `json:"image"`
+}
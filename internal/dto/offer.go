@@ -0,0 +1,21 @@
+package dto
+
+import "time"
+
+type OfferRequest struct {
+	Amount int64 `json:"amount"`
+}
+
+type OfferResponse struct {
+	ID            string     `json:"id"`
+	PostID        string     `json:"post_id"`
+	BuyerLogin    string     `json:"buyer_login"`
+	SellerLogin   string     `json:"seller_login"`
+	ProposerLogin string     `json:"proposer_login"`
+	PreviousID    string     `json:"previous_id,omitempty"`
+	Amount        int64      `json:"amount"`
+	Status        string     `json:"status"`
+	CreatedAt     time.Time  `json:"created_at"`
+	ExpiresAt     time.Time  `json:"expires_at"`
+	RespondedAt   *time.Time `json:"responded_at,omitempty"`
+}
@@ -0,0 +1,19 @@
+package dto
+
+import "time"
+
+type PaymentRequest struct {
+	PaymentToken string `json:"payment_token"`
+}
+
+type OrderResponse struct {
+	ID          string    `json:"id"`
+	PostID      string    `json:"post_id"`
+	BuyerLogin  string    `json:"buyer_login"`
+	SellerLogin string    `json:"seller_login"`
+	Amount      int64     `json:"amount"`
+	Status      string    `json:"status"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
@@ -1,10 +1,19 @@
 package dto
 
 type PostResponse struct {
-	Header           string `json:"header"`
-	Text             string `json:"text"`
-	PathToImage      string `json:"image_path"`
-	Price            int64  `json:"price"`
-	OwnerLogin       string `json:"owner_login"`
-	RequesterIsOwner bool   `json:"is_owner,omitempty"`
+	Header           string           `json:"header"`
+	Text             string           `json:"text"`
+	PathToImage      string           `json:"image_path"`
+	Price            int64            `json:"price"`
+	OwnerLogin       string           `json:"owner_login"`
+	OwnerRating      float64          `json:"owner_rating"`
+	OwnerReviewCount int              `json:"owner_review_count"`
+	RequesterIsOwner bool             `json:"is_owner,omitempty"`
+	Auction          *AuctionResponse `json:"auction,omitempty"`
+}
+
+type PostUpdateRequest struct {
+	Header *string `json:"header"`
+	Text   *string `json:"text"`
+	Price  *int64  `json:"price"`
 }
@@ -0,0 +1,21 @@
+package dto
+
+import "time"
+
+type ReportRequest struct {
+	Reason string `json:"reason"`
+	Text   string `json:"text"`
+}
+
+type ReportResponse struct {
+	ID            string     `json:"id"`
+	PostID        string     `json:"post_id"`
+	PostHeader    string     `json:"post_header,omitempty"`
+	PostHidden    bool       `json:"post_hidden"`
+	ReporterLogin string     `json:"reporter_login,omitempty"`
+	Reason        string     `json:"reason"`
+	Text          string     `json:"text,omitempty"`
+	Status        string     `json:"status"`
+	CreatedAt     time.Time  `json:"created_at"`
+	ClosedAt      *time.Time `json:"closed_at,omitempty"`
+}
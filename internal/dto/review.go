@@ -0,0 +1,30 @@
+package dto
+
+import "time"
+
+type ReviewRequest struct {
+	Rating int    `json:"rating"`
+	Text   string `json:"text"`
+}
+
+type ReviewReplyRequest struct {
+	Text string `json:"text"`
+}
+
+type ReviewResponse struct {
+	ID          string     `json:"id"`
+	PostID      string     `json:"post_id,omitempty"`
+	SellerLogin string     `json:"seller_login"`
+	AuthorLogin string     `json:"author_login"`
+	Rating      int        `json:"rating"`
+	Text        string     `json:"text"`
+	Reply       string     `json:"reply,omitempty"`
+	ReplyStatus string     `json:"reply_status,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	RepliedAt   *time.Time `json:"replied_at,omitempty"`
+}
+
+type RatingResponse struct {
+	Average float64 `json:"average"`
+	Count   int     `json:"count"`
+}
@@ -0,0 +1,29 @@
+package dto
+
+import "time"
+
+type SearchRequest struct {
+	MinPrice  uint   `json:"min_price"`
+	MaxPrice  uint   `json:"max_price"`
+	SortBy    string `json:"sort_by"`
+	SortOrder string `json:"sort_order"`
+}
+
+type SearchResponse struct {
+	ID        string    `json:"id"`
+	MinPrice  uint      `json:"min_price"`
+	MaxPrice  uint      `json:"max_price"`
+	SortBy    string    `json:"sort_by,omitempty"`
+	SortOrder string    `json:"sort_order,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type SearchMatchResponse struct {
+	PostID    string        `json:"post_id"`
+	Post      *PostResponse `json:"post"`
+	MatchedAt time.Time     `json:"matched_at"`
+}
+
+type MarkSeenRequest struct {
+	PostIDs []string `json:"post_ids"`
+}
@@ -1,6 +1,54 @@
 package dto
 
+import "time"
+
 type UserRequest struct {
 	Login    string `json:"login"`
 	Password string `json:"password"`
+	Email    string `json:"email"`
+}
+
+type ProfileUpdateRequest struct {
+	DisplayName *string `json:"display_name"`
+	Bio         *string `json:"bio"`
+}
+
+type ProfileResponse struct {
+	Login        string    `json:"login"`
+	DisplayName  string    `json:"display_name"`
+	Bio          string    `json:"bio"`
+	AvatarPath   string    `json:"avatar_path,omitempty"`
+	RegisteredAt time.Time `json:"registered_at"`
+	PostCount    int       `json:"post_count"`
+}
+
+type PasswordChangeRequest struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+}
+
+type PasswordResetRequest struct {
+	Login string `json:"login"`
+}
+
+type PasswordResetConfirmRequest struct {
+	Token       string `json:"token"`
+	NewPassword string `json:"new_password"`
+}
+
+type EmailVerifyRequest struct {
+	Token string `json:"token"`
+}
+
+type RoleRequest struct {
+	Role string `json:"role"`
+}
+
+type BanRequest struct {
+	Reason string     `json:"reason"`
+	Until  *time.Time `json:"until"`
+}
+
+type UnbanRequest struct {
+	Reason string `json:"reason"`
 }
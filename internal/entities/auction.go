@@ -0,0 +1,26 @@
+package entities
+
+import (
+	"database/sql"
+	"time"
+)
+
+type Auction struct {
+	PostID       string         `db:"post_id"`
+	StartPrice   int64          `db:"start_price"`
+	MinIncrement int64          `db:"min_increment"`
+	EndsAt       time.Time      `db:"ends_at"`
+	CurrentBid   int64          `db:"current_bid"`
+	BidCount     int            `db:"bid_count"`
+	LeaderID     sql.NullString `db:"leader_id"`
+	ClosedAt     sql.NullTime   `db:"closed_at"`
+}
+
+type AuctionResult struct {
+	PostID      string         `db:"post_id"`
+	SellerID    string         `db:"seller_id"`
+	WinnerID    sql.NullString `db:"winner_id"`
+	WinnerLogin sql.NullString `db:"winner_login"`
+	Amount      int64          `db:"amount"`
+	ClosedAt    time.Time      `db:"closed_at"`
+}
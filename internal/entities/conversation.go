@@ -0,0 +1,29 @@
+package entities
+
+import (
+	"database/sql"
+	"time"
+)
+
+type Conversation struct {
+	ID            string    `db:"id"`
+	PostID        string    `db:"post_id"`
+	PostHeader    string    `db:"post_header"`
+	BuyerID       string    `db:"buyer_id"`
+	BuyerLogin    string    `db:"buyer_login"`
+	SellerID      string    `db:"seller_id"`
+	SellerLogin   string    `db:"seller_login"`
+	CreatedAt     time.Time `db:"created_at"`
+	LastMessageAt time.Time `db:"last_message_at"`
+	UnreadCount   int       `db:"unread_count"`
+}
+
+type Message struct {
+	ID             string       `db:"id"`
+	ConversationID string       `db:"conversation_id"`
+	SenderID       string       `db:"sender_id"`
+	SenderLogin    string       `db:"sender_login"`
+	Text           string       `db:"text"`
+	CreatedAt      time.Time    `db:"created_at"`
+	ReadAt         sql.NullTime `db:"read_at"`
+}
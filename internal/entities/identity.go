@@ -0,0 +1,10 @@
+package entities
+
+import "time"
+
+type ExternalIdentity struct {
+	Provider  string    `db:"provider"`
+	Subject   string    `db:"subject"`
+	UserID    string    `db:"user_id"`
+	CreatedAt time.Time `db:"created_at"`
+}
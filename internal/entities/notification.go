@@ -0,0 +1,15 @@
+package entities
+
+import (
+	"database/sql"
+	"time"
+)
+
+type Notification struct {
+	ID        string       `db:"id"`
+	UserID    string       `db:"user_id"`
+	Kind      string       `db:"kind"`
+	Payload   []byte       `db:"payload"`
+	CreatedAt time.Time    `db:"created_at"`
+	ReadAt    sql.NullTime `db:"read_at"`
+}
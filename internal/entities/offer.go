@@ -0,0 +1,22 @@
+package entities
+
+import (
+	"database/sql"
+	"time"
+)
+
+type Offer struct {
+	ID          string         `db:"id"`
+	PostID      string         `db:"post_id"`
+	BuyerID     string         `db:"buyer_id"`
+	BuyerLogin  string         `db:"buyer_login"`
+	SellerID    string         `db:"seller_id"`
+	SellerLogin string         `db:"seller_login"`
+	ProposerID  string         `db:"proposer_id"`
+	PreviousID  sql.NullString `db:"previous_id"`
+	Amount      int64          `db:"amount"`
+	Status      string         `db:"status"`
+	CreatedAt   time.Time      `db:"created_at"`
+	ExpiresAt   time.Time      `db:"expires_at"`
+	RespondedAt sql.NullTime   `db:"responded_at"`
+}
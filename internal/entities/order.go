@@ -0,0 +1,21 @@
+package entities
+
+import (
+	"database/sql"
+	"time"
+)
+
+type Order struct {
+	ID          string         `db:"id"`
+	PostID      string         `db:"post_id"`
+	BuyerID     string         `db:"buyer_id"`
+	BuyerLogin  string         `db:"buyer_login"`
+	SellerID    string         `db:"seller_id"`
+	SellerLogin string         `db:"seller_login"`
+	Amount      int64          `db:"amount"`
+	Status      string         `db:"status"`
+	PaymentID   sql.NullString `db:"payment_id"`
+	CreatedAt   time.Time      `db:"created_at"`
+	UpdatedAt   time.Time      `db:"updated_at"`
+	ExpiresAt   time.Time      `db:"expires_at"`
+}
@@ -1,6 +1,9 @@
 package entities
 
-import "time"
+import (
+	"database/sql"
+	"time"
+)
 
 type PostWithDocument struct {
 	ID         string    `db:"id"`
@@ -14,4 +17,16 @@ type PostWithDocument struct {
 	DocName    string    `db:"document_name"`
 	DocMime    string    `db:"document_mime"`
 	DocPath    string    `db:"document_path"`
+
+	AuctionStartPrice   sql.NullInt64  `db:"auction_start_price"`
+	AuctionMinIncrement sql.NullInt64  `db:"auction_min_increment"`
+	AuctionEndsAt       sql.NullTime   `db:"auction_ends_at"`
+	AuctionCurrentBid   sql.NullInt64  `db:"auction_current_bid"`
+	AuctionBidCount     sql.NullInt64  `db:"auction_bid_count"`
+	AuctionLeaderID     sql.NullString `db:"auction_leader_id"`
+	AuctionLeaderLogin  sql.NullString `db:"auction_leader_login"`
+	AuctionClosedAt     sql.NullTime   `db:"auction_closed_at"`
+
+	OwnerReviewCount int   `db:"owner_review_count"`
+	OwnerRatingSum   int64 `db:"owner_rating_sum"`
 }
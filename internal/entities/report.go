@@ -0,0 +1,21 @@
+package entities
+
+import (
+	"database/sql"
+	"time"
+)
+
+type Report struct {
+	ID            string       `db:"id"`
+	PostID        string       `db:"post_id"`
+	PostOwnerID   string       `db:"post_owner_id"`
+	PostHeader    string       `db:"post_header"`
+	PostHidden    bool         `db:"post_hidden"`
+	ReporterID    string       `db:"reporter_id"`
+	ReporterLogin string       `db:"reporter_login"`
+	Reason        string       `db:"reason"`
+	Text          string       `db:"text"`
+	Status        string       `db:"status"`
+	CreatedAt     time.Time    `db:"created_at"`
+	ClosedAt      sql.NullTime `db:"closed_at"`
+}
@@ -0,0 +1,26 @@
+package entities
+
+import (
+	"database/sql"
+	"time"
+)
+
+type Review struct {
+	ID          string         `db:"id"`
+	PostID      sql.NullString `db:"post_id"`
+	SellerID    string         `db:"seller_id"`
+	SellerLogin string         `db:"seller_login"`
+	AuthorID    string         `db:"author_id"`
+	AuthorLogin string         `db:"author_login"`
+	Rating      int            `db:"rating"`
+	Text        string         `db:"text"`
+	Reply       string         `db:"reply"`
+	ReplyStatus string         `db:"reply_status"`
+	CreatedAt   time.Time      `db:"created_at"`
+	RepliedAt   sql.NullTime   `db:"replied_at"`
+}
+
+type Rating struct {
+	Count int   `db:"review_count"`
+	Sum   int64 `db:"rating_sum"`
+}
@@ -0,0 +1,19 @@
+package entities
+
+import "time"
+
+type SavedSearch struct {
+	ID        string    `db:"id"`
+	UserID    string    `db:"user_id"`
+	MinPrice  int64     `db:"min_price"`
+	MaxPrice  int64     `db:"max_price"`
+	SortBy    string    `db:"sort_by"`
+	SortOrder string    `db:"sort_order"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+type SearchMatch struct {
+	SearchID  string    `db:"search_id"`
+	MatchedAt time.Time `db:"matched_at"`
+	PostWithDocument
+}
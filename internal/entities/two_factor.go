@@ -0,0 +1,14 @@
+package entities
+
+import (
+	"database/sql"
+	"time"
+)
+
+type TOTP struct {
+	UserID    string       `db:"user_id"`
+	Secret    string       `db:"secret"`
+	EnabledAt sql.NullTime `db:"enabled_at"`
+	LastStep  int64        `db:"last_step"`
+	CreatedAt time.Time    `db:"created_at"`
+}
@@ -1,7 +1,29 @@
 package entities
 
+import (
+	"database/sql"
+	"time"
+)
+
 type User struct {
-	ID       string `db:"id"`
-	Login    string `db:"login"`
-	PassHash []byte `db:"pass_hash"`
+	ID              string         `db:"id"`
+	Login           string         `db:"login"`
+	PassHash        []byte         `db:"pass_hash"`
+	Email           sql.NullString `db:"email"`
+	EmailVerifiedAt sql.NullTime   `db:"email_verified_at"`
+	Role            string         `db:"role"`
+	BannedAt        sql.NullTime   `db:"banned_at"`
+	BannedUntil     sql.NullTime   `db:"banned_until"`
+	BanReason       sql.NullString `db:"ban_reason"`
+	BannedBy        sql.NullString `db:"banned_by"`
+}
+
+type Profile struct {
+	UserID       string    `db:"id"`
+	Login        string    `db:"login"`
+	DisplayName  string    `db:"display_name"`
+	Bio          string    `db:"bio"`
+	AvatarPath   string    `db:"avatar_path"`
+	RegisteredAt time.Time `db:"registered_at"`
+	PostCount    int       `db:"post_count"`
 }
@@ -0,0 +1,32 @@
+package accounthandler
+
+import (
+	"context"
+	"log/slog"
+	"marketplace/internal/models"
+	utils "marketplace/internal/utils/http_errors"
+	"net/http"
+)
+
+// Delete starts removing the requester's account and answers 202 with the
+// job doing it.
+func Delete(ctx context.Context, log *slog.Logger, w http.ResponseWriter, r *http.Request, ad AccountDeleter) {
+	op := pkg + "Delete"
+
+	log = log.With(slog.String("op", op))
+
+	requester, ok := ctx.Value(models.UserContextKey).(*models.User)
+	if !ok {
+		log.Error("failed to parse user from context")
+		utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+		return
+	}
+
+	job, err := ad.DeleteAccount(ctx, requester)
+	if err != nil {
+		writeError(log, w, err, "failed to delete account")
+		return
+	}
+
+	writeJob(log, w, http.StatusAccepted, job)
+}
@@ -0,0 +1,111 @@
+package accounthandler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"marketplace/internal/models"
+	utils "marketplace/internal/utils/http_errors"
+	"marketplace/internal/utils/mapper"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// Export streams the requester's data export as a ZIP once it is ready.
+// Until then it answers 202 with the job building it. With ?refresh=true a
+// finished export is built again from the current data.
+func Export(ctx context.Context, log *slog.Logger, w http.ResponseWriter, r *http.Request, e Exporter) {
+	op := pkg + "Export"
+
+	log = log.With(slog.String("op", op))
+
+	requester, ok := ctx.Value(models.UserContextKey).(*models.User)
+	if !ok {
+		log.Error("failed to parse user from context")
+		utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+		return
+	}
+
+	refresh := r.URL.Query().Get("refresh") == "true"
+
+	job, archive, err := e.Export(ctx, requester, refresh)
+	if err != nil {
+		writeError(log, w, err, "failed to get export")
+		return
+	}
+
+	if archive == nil {
+		writeJob(log, w, http.StatusAccepted, job)
+		return
+	}
+	defer archive.Close()
+
+	// An archive can take longer to download than the server WriteTimeout
+	// allows for regular requests.
+	if err := http.NewResponseController(w).SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		log.Warn("failed to clear write deadline", slog.String("error", err.Error()))
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", `attachment; filename="export.zip"`)
+	if _, err := io.Copy(w, archive); err != nil {
+		log.Error("failed to write export", slog.String("error", err.Error()))
+	}
+}
+
+// Job returns the status of a background job of the requester.
+func Job(ctx context.Context, log *slog.Logger, w http.ResponseWriter, r *http.Request, jp JobProvider) {
+	op := pkg + "Job"
+
+	log = log.With(slog.String("op", op))
+
+	requester, ok := ctx.Value(models.UserContextKey).(*models.User)
+	if !ok {
+		log.Error("failed to parse user from context")
+		utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+		return
+	}
+
+	job, err := jp.Job(ctx, requester, mux.Vars(r)["id"])
+	if err != nil {
+		writeError(log, w, err, "failed to get job")
+		return
+	}
+
+	writeJob(log, w, http.StatusOK, job)
+}
+
+func writeJob(log *slog.Logger, w http.ResponseWriter, status int, job *models.Job) {
+	response := map[string]any{
+		"data": map[string]any{
+			"job": mapper.DtoFromJob(job),
+		},
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Error("failed to write response", slog.String("error", err.Error()))
+	}
+}
+
+func writeError(log *slog.Logger, w http.ResponseWriter, err error, msg string) {
+	switch {
+	case errors.Is(err, models.ErrJobNotFound):
+		log.Warn(msg, slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, models.ErrJobQueueFull):
+		log.Warn(msg, slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusServiceUnavailable, err.Error())
+	case errors.Is(err, models.ErrTooManyRequests):
+		log.Warn(msg, slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusTooManyRequests, err.Error())
+	default:
+		log.Error(msg, slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+	}
+}
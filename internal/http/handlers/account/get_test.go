@@ -0,0 +1,148 @@
+package accounthandler
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"marketplace/internal/models"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockAccountService struct {
+	mock.Mock
+}
+
+func (m *mockAccountService) Export(ctx context.Context, requester *models.User, refresh bool) (*models.Job, io.ReadCloser, error) {
+	args := m.Called(ctx, requester, refresh)
+	job, _ := args.Get(0).(*models.Job)
+	archive, _ := args.Get(1).(io.ReadCloser)
+	return job, archive, args.Error(2)
+}
+
+func (m *mockAccountService) DeleteAccount(ctx context.Context, requester *models.User) (*models.Job, error) {
+	args := m.Called(ctx, requester)
+	job, _ := args.Get(0).(*models.Job)
+	return job, args.Error(1)
+}
+
+func (m *mockAccountService) Job(ctx context.Context, requester *models.User, id string) (*models.Job, error) {
+	args := m.Called(ctx, requester, id)
+	job, _ := args.Get(0).(*models.Job)
+	return job, args.Error(1)
+}
+
+var user = &models.User{ID: "u1", Login: "alice"}
+
+func TestExport(t *testing.T) {
+	t.Parallel()
+
+	pending := &models.Job{ID: "job-1", Kind: models.JobExport, Status: models.JobPending}
+
+	tests := []struct {
+		name       string
+		query      string
+		refresh    bool
+		job        *models.Job
+		archive    io.ReadCloser
+		serviceErr error
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "ready", job: &models.Job{ID: "job-1", Status: models.JobDone}, archive: io.NopCloser(strings.NewReader("PK-archive")), wantStatus: http.StatusOK, wantBody: "PK-archive"},
+		{name: "in progress", job: pending, wantStatus: http.StatusAccepted, wantBody: `"status":"pending"`},
+		{name: "refresh", query: "?refresh=true", refresh: true, job: pending, wantStatus: http.StatusAccepted, wantBody: `"status":"pending"`},
+		{name: "refresh too soon", query: "?refresh=true", refresh: true, serviceErr: models.ErrTooManyRequests, wantStatus: http.StatusTooManyRequests},
+		{name: "queue full", serviceErr: models.ErrJobQueueFull, wantStatus: http.StatusServiceUnavailable},
+		{name: "internal", serviceErr: models.ErrInternal, wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			service := new(mockAccountService)
+			service.On("Export", mock.Anything, user, tt.refresh).Return(tt.job, tt.archive, tt.serviceErr)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/me/export"+tt.query, nil)
+			req = req.WithContext(context.WithValue(req.Context(), models.UserContextKey, user))
+			w := httptest.NewRecorder()
+
+			Export(req.Context(), slog.New(slog.NewTextHandler(io.Discard, nil)), w, req, service)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.wantBody)
+			if tt.archive != nil {
+				assert.Equal(t, "application/zip", w.Header().Get("Content-Type"))
+			}
+		})
+	}
+}
+
+func TestJob(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		requester  *models.User
+		job        *models.Job
+		serviceErr error
+		wantStatus int
+	}{
+		{name: "owner", requester: user, job: &models.Job{ID: "job-1", Kind: models.JobDeletion, Status: models.JobRunning}, wantStatus: http.StatusOK},
+		{name: "no user in context", wantStatus: http.StatusInternalServerError},
+		{name: "not found", requester: user, serviceErr: models.ErrJobNotFound, wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			service := new(mockAccountService)
+			if tt.requester != nil {
+				service.On("Job", mock.Anything, tt.requester, "job-1").Return(tt.job, tt.serviceErr)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/api/jobs/job-1", nil)
+			if tt.requester != nil {
+				req = req.WithContext(context.WithValue(req.Context(), models.UserContextKey, tt.requester))
+			}
+			req = mux.SetURLVars(req, map[string]string{"id": "job-1"})
+			w := httptest.NewRecorder()
+
+			Job(req.Context(), slog.New(slog.NewTextHandler(io.Discard, nil)), w, req, service)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+
+			if tt.job != nil {
+				var result map[string]map[string]map[string]any
+				assert.NoError(t, json.NewDecoder(w.Body).Decode(&result))
+				assert.Equal(t, string(tt.job.Status), result["data"]["job"]["status"])
+			}
+		})
+	}
+}
+
+func TestDelete(t *testing.T) {
+	t.Parallel()
+
+	service := new(mockAccountService)
+	service.On("DeleteAccount", mock.Anything, user).Return(&models.Job{ID: "job-2", Kind: models.JobDeletion, Status: models.JobPending}, nil)
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/me", nil)
+	req = req.WithContext(context.WithValue(req.Context(), models.UserContextKey, user))
+	w := httptest.NewRecorder()
+
+	Delete(req.Context(), slog.New(slog.NewTextHandler(io.Discard, nil)), w, req, service)
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Contains(t, w.Body.String(), `"id":"job-2"`)
+	service.AssertExpectations(t)
+}
@@ -0,0 +1,21 @@
+package accounthandler
+
+import (
+	"context"
+	"io"
+	"marketplace/internal/models"
+)
+
+const pkg = "accountHandler/"
+
+type Exporter interface {
+	Export(ctx context.Context, requester *models.User, refresh bool) (*models.Job, io.ReadCloser, error)
+}
+
+type AccountDeleter interface {
+	DeleteAccount(ctx context.Context, requester *models.User) (*models.Job, error)
+}
+
+type JobProvider interface {
+	Job(ctx context.Context, requester *models.User, id string) (*models.Job, error)
+}
@@ -0,0 +1,12 @@
+package adminhandler
+
+import (
+	"context"
+	"marketplace/internal/models"
+)
+
+const pkg = "adminHandler/"
+
+type RoleSetter interface {
+	SetRole(ctx context.Context, login string, role models.Role) error
+}
@@ -0,0 +1,50 @@
+package adminhandler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"marketplace/internal/dto"
+	"marketplace/internal/models"
+	utils "marketplace/internal/utils/http_errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// SetRole gives the user from the path the role from the body.
+func SetRole(ctx context.Context, log *slog.Logger, w http.ResponseWriter, r *http.Request, rs RoleSetter) {
+	op := pkg + "SetRole"
+
+	log = log.With(slog.String("op", op))
+
+	login := mux.Vars(r)["login"]
+
+	var roleRequest dto.RoleRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&roleRequest); err != nil {
+		log.Warn("failed to decode body", slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusBadRequest, models.ErrInvalidParams.Error())
+		return
+	}
+	defer r.Body.Close()
+
+	err := rs.SetRole(ctx, login, models.Role(roleRequest.Role))
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrInvalidRole):
+			log.Warn("invalid role received", slog.String("error", err.Error()))
+			utils.WriteJSONError(w, http.StatusBadRequest, models.ErrInvalidRole.Error())
+		case errors.Is(err, models.ErrUserNotFound):
+			log.Warn("user not found", slog.String("error", err.Error()))
+			utils.WriteJSONError(w, http.StatusNotFound, models.ErrUserNotFound.Error())
+		default:
+			log.Error("failed to set role", slog.String("error", err.Error()))
+			utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,64 @@
+package adminhandler
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"marketplace/internal/models"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockRoleSetter struct {
+	mock.Mock
+}
+
+func (m *mockRoleSetter) SetRole(ctx context.Context, login string, role models.Role) error {
+	return m.Called(ctx, login, role).Error(0)
+}
+
+func TestSetRole(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		body       string
+		role       models.Role
+		err        error
+		wantCall   bool
+		wantStatus int
+	}{
+		{name: "success", body: `{"role":"moderator"}`, role: models.RoleModerator, wantCall: true, wantStatus: http.StatusNoContent},
+		{name: "invalid body", body: `{`, wantStatus: http.StatusBadRequest},
+		{name: "invalid role", body: `{"role":"root"}`, role: "root", err: models.ErrInvalidRole, wantCall: true, wantStatus: http.StatusBadRequest},
+		{name: "user not found", body: `{"role":"admin"}`, role: models.RoleAdmin, err: models.ErrUserNotFound, wantCall: true, wantStatus: http.StatusNotFound},
+		{name: "internal", body: `{"role":"user"}`, role: models.RoleUser, err: errors.New("db down"), wantCall: true, wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			setter := new(mockRoleSetter)
+			if tt.wantCall {
+				setter.On("SetRole", mock.Anything, "alice", tt.role).Return(tt.err)
+			}
+
+			req := httptest.NewRequest(http.MethodPut, "/api/admin/users/alice/role", strings.NewReader(tt.body))
+			req = mux.SetURLVars(req, map[string]string{"login": "alice"})
+			rr := httptest.NewRecorder()
+
+			SetRole(req.Context(), slog.New(slog.NewTextHandler(io.Discard, nil)), rr, req, setter)
+
+			assert.Equal(t, tt.wantStatus, rr.Code)
+			setter.AssertExpectations(t)
+		})
+	}
+}
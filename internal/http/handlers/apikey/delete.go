@@ -0,0 +1,39 @@
+package apikeyhandler
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"marketplace/internal/models"
+	utils "marketplace/internal/utils/http_errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+func Delete(ctx context.Context, log *slog.Logger, w http.ResponseWriter, r *http.Request, kr APIKeyRevoker) {
+	op := pkg + "Delete"
+
+	log = log.With(slog.String("op", op))
+
+	requester, ok := ctx.Value(models.UserContextKey).(*models.User)
+	if !ok {
+		log.Error("failed to parse user from context")
+		utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+		return
+	}
+
+	err := kr.RevokeAPIKey(ctx, requester, mux.Vars(r)["id"])
+	if err != nil {
+		if errors.Is(err, models.ErrAPIKeyNotFound) {
+			log.Warn("api key not found", slog.String("error", err.Error()))
+			utils.WriteJSONError(w, http.StatusNotFound, models.ErrAPIKeyNotFound.Error())
+			return
+		}
+		log.Error("failed to revoke api key", slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
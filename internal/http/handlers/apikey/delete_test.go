@@ -0,0 +1,59 @@
+package apikeyhandler
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"marketplace/internal/models"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockAPIKeyRevoker struct {
+	mock.Mock
+}
+
+func (m *mockAPIKeyRevoker) RevokeAPIKey(ctx context.Context, requester *models.User, id string) error {
+	return m.Called(ctx, requester, id).Error(0)
+}
+
+func TestDelete(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{name: "success", wantStatus: http.StatusNoContent},
+		{name: "not found", err: models.ErrAPIKeyNotFound, wantStatus: http.StatusNotFound},
+		{name: "internal", err: errors.New("db down"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			revoker := new(mockAPIKeyRevoker)
+			user := &models.User{ID: "u1"}
+
+			revoker.On("RevokeAPIKey", mock.Anything, user, "k1").Return(tt.err)
+
+			req := httptest.NewRequest(http.MethodDelete, "/api/me/api-keys/k1", nil)
+			req = mux.SetURLVars(req, map[string]string{"id": "k1"})
+			ctx := context.WithValue(req.Context(), models.UserContextKey, user)
+			rr := httptest.NewRecorder()
+
+			Delete(ctx, slog.New(slog.NewTextHandler(io.Discard, nil)), rr, req, revoker)
+
+			assert.Equal(t, tt.wantStatus, rr.Code)
+			revoker.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package apikeyhandler
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"marketplace/internal/models"
+	utils "marketplace/internal/utils/http_errors"
+	"marketplace/internal/utils/mapper"
+	"net/http"
+)
+
+func Get(ctx context.Context, log *slog.Logger, w http.ResponseWriter, r *http.Request, kp APIKeyProvider) {
+	op := pkg + "Get"
+
+	log = log.With(slog.String("op", op))
+
+	requester, ok := ctx.Value(models.UserContextKey).(*models.User)
+	if !ok {
+		log.Error("failed to parse user from context")
+		utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+		return
+	}
+
+	keys, err := kp.APIKeys(ctx, requester)
+	if err != nil {
+		log.Error("failed to list api keys", slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+		return
+	}
+
+	response := map[string]any{
+		"data": map[string]any{
+			"api_keys": mapper.DtoFromAPIKeys(keys),
+		},
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Error("failed to write response", slog.String("error", err.Error()))
+	}
+}
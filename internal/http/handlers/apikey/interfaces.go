@@ -0,0 +1,21 @@
+package apikeyhandler
+
+import (
+	"context"
+	"marketplace/internal/models"
+	"time"
+)
+
+const pkg = "apiKeyHandler/"
+
+type APIKeyProvider interface {
+	APIKeys(ctx context.Context, requester *models.User) ([]*models.APIKey, error)
+}
+
+type APIKeyCreator interface {
+	CreateAPIKey(ctx context.Context, requester *models.User, name string, scopes []string, expiresAt *time.Time) (*models.APIKey, string, error)
+}
+
+type APIKeyRevoker interface {
+	RevokeAPIKey(ctx context.Context, requester *models.User, id string) error
+}
@@ -0,0 +1,66 @@
+package apikeyhandler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"marketplace/internal/dto"
+	"marketplace/internal/models"
+	utils "marketplace/internal/utils/http_errors"
+	"marketplace/internal/utils/mapper"
+	"net/http"
+)
+
+// Add creates a key. Its plain value is in the response and is not shown
+// again.
+func Add(ctx context.Context, log *slog.Logger, w http.ResponseWriter, r *http.Request, kc APIKeyCreator) {
+	op := pkg + "Add"
+
+	log = log.With(slog.String("op", op))
+
+	requester, ok := ctx.Value(models.UserContextKey).(*models.User)
+	if !ok {
+		log.Error("failed to parse user from context")
+		utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+		return
+	}
+
+	var keyRequest dto.APIKeyRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&keyRequest); err != nil {
+		log.Warn("failed to decode body", slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusBadRequest, models.ErrInvalidParams.Error())
+		return
+	}
+	defer r.Body.Close()
+
+	key, plain, err := kc.CreateAPIKey(ctx, requester, keyRequest.Name, keyRequest.Scopes, keyRequest.ExpiresAt)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrInvalidParams):
+			log.Warn("invalid api key params", slog.String("error", err.Error()))
+			utils.WriteJSONError(w, http.StatusBadRequest, models.ErrInvalidParams.Error())
+		case errors.Is(err, models.ErrAPIKeyLimit):
+			log.Warn("api key limit reached", slog.String("error", err.Error()))
+			utils.WriteJSONError(w, http.StatusConflict, models.ErrAPIKeyLimit.Error())
+		default:
+			log.Error("failed to create api key", slog.String("error", err.Error()))
+			utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+		}
+		return
+	}
+
+	keyDto := mapper.DtoFromAPIKey(key)
+	keyDto.Key = plain
+
+	response := map[string]any{
+		"api_key": keyDto,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Error("failed to write response", slog.String("error", err.Error()))
+	}
+}
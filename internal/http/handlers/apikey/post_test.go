@@ -0,0 +1,79 @@
+package apikeyhandler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"marketplace/internal/models"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockAPIKeyCreator struct {
+	mock.Mock
+}
+
+func (m *mockAPIKeyCreator) CreateAPIKey(ctx context.Context, requester *models.User, name string, scopes []string, expiresAt *time.Time) (*models.APIKey, string, error) {
+	args := m.Called(ctx, requester, name, scopes, expiresAt)
+	key, _ := args.Get(0).(*models.APIKey)
+	return key, args.String(1), args.Error(2)
+}
+
+func TestAdd(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		body       string
+		key        *models.APIKey
+		plain      string
+		err        error
+		wantStatus int
+	}{
+		{
+			name:       "success",
+			body:       `{"name": "inventory", "scopes": ["posts:read"]}`,
+			key:        &models.APIKey{ID: "k1", Name: "inventory", Prefix: "mpk_abcdefgh", Scopes: []string{"posts:read"}},
+			plain:      "mpk_abcdefghsecret",
+			wantStatus: http.StatusCreated,
+		},
+		{name: "invalid json", body: `{invalid`, wantStatus: http.StatusBadRequest},
+		{name: "invalid params", body: `{"name": "", "scopes": []}`, err: models.ErrInvalidParams, wantStatus: http.StatusBadRequest},
+		{name: "limit", body: `{"name": "k", "scopes": ["posts:read"]}`, err: models.ErrAPIKeyLimit, wantStatus: http.StatusConflict},
+		{name: "internal", body: `{"name": "k", "scopes": ["posts:read"]}`, err: errors.New("db down"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			creator := new(mockAPIKeyCreator)
+			user := &models.User{ID: "u1"}
+
+			creator.On("CreateAPIKey", mock.Anything, user, mock.Anything, mock.Anything, mock.Anything).Return(tt.key, tt.plain, tt.err)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/me/api-keys", strings.NewReader(tt.body))
+			ctx := context.WithValue(req.Context(), models.UserContextKey, user)
+			rr := httptest.NewRecorder()
+
+			Add(ctx, slog.New(slog.NewTextHandler(io.Discard, nil)), rr, req, creator)
+
+			assert.Equal(t, tt.wantStatus, rr.Code)
+
+			if tt.wantStatus == http.StatusCreated {
+				var resp map[string]map[string]any
+				assert.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+				assert.Equal(t, "k1", resp["api_key"]["id"])
+				assert.Equal(t, tt.plain, resp["api_key"]["key"])
+			}
+		})
+	}
+}
@@ -0,0 +1,12 @@
+package auctionhandler
+
+import (
+	"context"
+	"marketplace/internal/models"
+)
+
+const pkg = "auctionHandler/"
+
+type BidPlacer interface {
+	PlaceBid(ctx context.Context, requester *models.User, postID string, amount int64) (*models.Bid, *models.Auction, error)
+}
@@ -0,0 +1,77 @@
+package auctionhandler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"marketplace/internal/dto"
+	"marketplace/internal/models"
+	utils "marketplace/internal/utils/http_errors"
+	"marketplace/internal/utils/mapper"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+func Bid(ctx context.Context, log *slog.Logger, w http.ResponseWriter, r *http.Request, bp BidPlacer) {
+	op := pkg + "Bid"
+
+	log = log.With(slog.String("op", op))
+
+	requester, ok := ctx.Value(models.UserContextKey).(*models.User)
+	if !ok {
+		log.Error("failed to parse user from context")
+		utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+		return
+	}
+
+	var bidRequest dto.BidRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&bidRequest); err != nil {
+		log.Warn("failed to decode body", slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusBadRequest, models.ErrInvalidParams.Error())
+		return
+	}
+	defer r.Body.Close()
+
+	postID := mux.Vars(r)["id"]
+
+	bid, auction, err := bp.PlaceBid(ctx, requester, postID, bidRequest.Amount)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrInvalidPrice):
+			log.Warn("invalid bid amount", slog.String("error", err.Error()))
+			utils.WriteJSONError(w, http.StatusBadRequest, models.ErrInvalidPrice.Error())
+		case errors.Is(err, models.ErrNotAuction):
+			log.Warn("post is not an auction", slog.String("error", err.Error()))
+			utils.WriteJSONError(w, http.StatusBadRequest, models.ErrNotAuction.Error())
+		case errors.Is(err, models.ErrPostNotFound):
+			log.Warn("post not found", slog.String("error", err.Error()))
+			utils.WriteJSONError(w, http.StatusNotFound, models.ErrPostNotFound.Error())
+		case errors.Is(err, models.ErrOwnPost):
+			log.Warn("bid on own post", slog.String("error", err.Error()))
+			utils.WriteJSONError(w, http.StatusForbidden, models.ErrOwnPost.Error())
+		case errors.Is(err, models.ErrBidTooLow):
+			log.Warn("bid is too low", slog.String("error", err.Error()))
+			utils.WriteJSONError(w, http.StatusConflict, models.ErrBidTooLow.Error())
+		case errors.Is(err, models.ErrAuctionEnded):
+			log.Warn("auction has ended", slog.String("error", err.Error()))
+			utils.WriteJSONError(w, http.StatusConflict, models.ErrAuctionEnded.Error())
+		default:
+			log.Error("failed to place bid", slog.String("error", err.Error()))
+			utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+		}
+		return
+	}
+
+	response := map[string]any{
+		"bid": mapper.DtoFromBid(bid, auction),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Error("failed to write response", slog.String("error", err.Error()))
+	}
+}
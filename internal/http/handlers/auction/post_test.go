@@ -0,0 +1,94 @@
+package auctionhandler
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"marketplace/internal/models"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockBidPlacer struct {
+	mock.Mock
+}
+
+func (m *mockBidPlacer) PlaceBid(ctx context.Context, requester *models.User, postID string, amount int64) (*models.Bid, *models.Auction, error) {
+	args := m.Called(ctx, requester, postID, amount)
+	return args.Get(0).(*models.Bid), args.Get(1).(*models.Auction), args.Error(2)
+}
+
+func TestBid(t *testing.T) {
+	t.Parallel()
+
+	user := &models.User{ID: "bidder"}
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{name: "success", err: nil, wantStatus: http.StatusCreated},
+		{name: "invalid amount", err: models.ErrInvalidPrice, wantStatus: http.StatusBadRequest},
+		{name: "not auction", err: models.ErrNotAuction, wantStatus: http.StatusBadRequest},
+		{name: "post not found", err: models.ErrPostNotFound, wantStatus: http.StatusNotFound},
+		{name: "own post", err: models.ErrOwnPost, wantStatus: http.StatusForbidden},
+		{name: "too low", err: models.ErrBidTooLow, wantStatus: http.StatusConflict},
+		{name: "ended", err: models.ErrAuctionEnded, wantStatus: http.StatusConflict},
+		{name: "internal", err: errors.New("db error"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			placer := new(mockBidPlacer)
+
+			var bid *models.Bid
+			var auction *models.Auction
+			if tt.err == nil {
+				bid = &models.Bid{ID: "b1", PostID: "p1", Amount: 150}
+				auction = &models.Auction{StartPrice: 100, MinIncrement: 10, CurrentBid: 150, BidCount: 1, EndsAt: time.Now().Add(time.Hour)}
+			}
+
+			placer.On("PlaceBid", mock.Anything, user, "p1", int64(150)).Return(bid, auction, tt.err)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/posts/p1/bids", strings.NewReader(`{"amount":150}`))
+			req = mux.SetURLVars(req, map[string]string{"id": "p1"})
+			ctx := context.WithValue(req.Context(), models.UserContextKey, user)
+			rr := httptest.NewRecorder()
+
+			Bid(ctx, slog.New(slog.NewTextHandler(io.Discard, nil)), rr, req, placer)
+
+			assert.Equal(t, tt.wantStatus, rr.Code)
+			if tt.err == nil {
+				assert.Contains(t, rr.Body.String(), `"current_bid":150`)
+				assert.Contains(t, rr.Body.String(), `"minimum_bid":160`)
+			}
+			placer.AssertExpectations(t)
+		})
+	}
+}
+
+func TestBid_InvalidBody(t *testing.T) {
+	t.Parallel()
+
+	placer := new(mockBidPlacer)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/posts/p1/bids", strings.NewReader(`{`))
+	ctx := context.WithValue(req.Context(), models.UserContextKey, &models.User{ID: "bidder"})
+	rr := httptest.NewRecorder()
+
+	Bid(ctx, slog.New(slog.NewTextHandler(io.Discard, nil)), rr, req, placer)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	placer.AssertNotCalled(t, "PlaceBid")
+}
@@ -0,0 +1,61 @@
+package banhandler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"marketplace/internal/dto"
+	"marketplace/internal/models"
+	utils "marketplace/internal/utils/http_errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// Unban lifts the ban of the user from the path.
+func Unban(ctx context.Context, log *slog.Logger, w http.ResponseWriter, r *http.Request, u Unbanner) {
+	op := pkg + "Unban"
+
+	log = log.With(slog.String("op", op))
+
+	requester, ok := ctx.Value(models.UserContextKey).(*models.User)
+	if !ok {
+		log.Error("failed to parse user from context")
+		utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+		return
+	}
+
+	var unbanRequest dto.UnbanRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&unbanRequest); err != nil {
+		log.Warn("failed to decode body", slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusBadRequest, models.ErrInvalidParams.Error())
+		return
+	}
+	defer r.Body.Close()
+
+	if err := u.Unban(ctx, requester, mux.Vars(r)["login"], unbanRequest.Reason); err != nil {
+		writeError(log, w, err, "failed to unban user")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeError(log *slog.Logger, w http.ResponseWriter, err error, msg string) {
+	switch {
+	case errors.Is(err, models.ErrForbidden):
+		log.Warn(msg, slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, models.ErrInvalidParams):
+		log.Warn(msg, slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, models.ErrUserNotFound):
+		log.Warn(msg, slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusNotFound, err.Error())
+	default:
+		log.Error(msg, slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+	}
+}
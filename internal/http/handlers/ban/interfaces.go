@@ -0,0 +1,17 @@
+package banhandler
+
+import (
+	"context"
+	"marketplace/internal/models"
+	"time"
+)
+
+const pkg = "banHandler/"
+
+type Banner interface {
+	Ban(ctx context.Context, requester *models.User, login string, reason string, until *time.Time) (*models.Ban, error)
+}
+
+type Unbanner interface {
+	Unban(ctx context.Context, requester *models.User, login string, reason string) error
+}
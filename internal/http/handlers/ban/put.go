@@ -0,0 +1,43 @@
+package banhandler
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"marketplace/internal/dto"
+	"marketplace/internal/models"
+	utils "marketplace/internal/utils/http_errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// Ban bans the user from the path, or suspends them when the body has an end.
+func Ban(ctx context.Context, log *slog.Logger, w http.ResponseWriter, r *http.Request, b Banner) {
+	op := pkg + "Ban"
+
+	log = log.With(slog.String("op", op))
+
+	requester, ok := ctx.Value(models.UserContextKey).(*models.User)
+	if !ok {
+		log.Error("failed to parse user from context")
+		utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+		return
+	}
+
+	var banRequest dto.BanRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&banRequest); err != nil {
+		log.Warn("failed to decode body", slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusBadRequest, models.ErrInvalidParams.Error())
+		return
+	}
+	defer r.Body.Close()
+
+	if _, err := b.Ban(ctx, requester, mux.Vars(r)["login"], banRequest.Reason, banRequest.Until); err != nil {
+		writeError(log, w, err, "failed to ban user")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,74 @@
+package banhandler
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"marketplace/internal/models"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockBanner struct {
+	mock.Mock
+}
+
+func (m *mockBanner) Ban(ctx context.Context, requester *models.User, login string, reason string, until *time.Time) (*models.Ban, error) {
+	args := m.Called(ctx, requester, login, reason, until)
+	ban, _ := args.Get(0).(*models.Ban)
+	return ban, args.Error(1)
+}
+
+func TestBan(t *testing.T) {
+	t.Parallel()
+
+	until := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		body       string
+		until      *time.Time
+		err        error
+		wantCall   bool
+		wantStatus int
+	}{
+		{name: "ban", body: `{"reason":"spam"}`, wantCall: true, wantStatus: http.StatusNoContent},
+		{name: "suspend", body: `{"reason":"spam","until":"2030-01-02T03:04:05Z"}`, until: &until, wantCall: true, wantStatus: http.StatusNoContent},
+		{name: "invalid body", body: `{`, wantStatus: http.StatusBadRequest},
+		{name: "not an admin", body: `{"reason":"spam"}`, err: models.ErrForbidden, wantCall: true, wantStatus: http.StatusForbidden},
+		{name: "invalid params", body: `{"reason":"spam"}`, err: models.ErrInvalidParams, wantCall: true, wantStatus: http.StatusBadRequest},
+		{name: "user not found", body: `{"reason":"spam"}`, err: models.ErrUserNotFound, wantCall: true, wantStatus: http.StatusNotFound},
+		{name: "internal", body: `{"reason":"spam"}`, err: errors.New("db down"), wantCall: true, wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			banner := new(mockBanner)
+			user := &models.User{ID: "a1", Login: "root"}
+
+			if tt.wantCall {
+				banner.On("Ban", mock.Anything, user, "spammer", "spam", tt.until).Return(&models.Ban{Reason: "spam"}, tt.err)
+			}
+
+			req := httptest.NewRequest(http.MethodPut, "/api/users/spammer/ban", strings.NewReader(tt.body))
+			req = mux.SetURLVars(req, map[string]string{"login": "spammer"})
+			ctx := context.WithValue(req.Context(), models.UserContextKey, user)
+			rr := httptest.NewRecorder()
+
+			Ban(ctx, slog.New(slog.NewTextHandler(io.Discard, nil)), rr, req, banner)
+
+			assert.Equal(t, tt.wantStatus, rr.Code)
+			banner.AssertExpectations(t)
+		})
+	}
+}
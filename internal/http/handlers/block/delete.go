@@ -0,0 +1,32 @@
+package blockhandler
+
+import (
+	"context"
+	"log/slog"
+	"marketplace/internal/models"
+	utils "marketplace/internal/utils/http_errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// Unblock shows the posts of the user from the path to the requester again.
+func Unblock(ctx context.Context, log *slog.Logger, w http.ResponseWriter, r *http.Request, u Unblocker) {
+	op := pkg + "Unblock"
+
+	log = log.With(slog.String("op", op))
+
+	requester, ok := ctx.Value(models.UserContextKey).(*models.User)
+	if !ok {
+		log.Error("failed to parse user from context")
+		utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+		return
+	}
+
+	if err := u.Unblock(ctx, requester, mux.Vars(r)["login"]); err != nil {
+		writeError(log, w, err, "failed to unblock user")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
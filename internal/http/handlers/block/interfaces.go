@@ -0,0 +1,16 @@
+package blockhandler
+
+import (
+	"context"
+	"marketplace/internal/models"
+)
+
+const pkg = "blockHandler/"
+
+type Blocker interface {
+	Block(ctx context.Context, requester *models.User, login string) error
+}
+
+type Unblocker interface {
+	Unblock(ctx context.Context, requester *models.User, login string) error
+}
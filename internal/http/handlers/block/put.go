@@ -0,0 +1,47 @@
+package blockhandler
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"marketplace/internal/models"
+	utils "marketplace/internal/utils/http_errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// Block hides the posts of the user from the path from the requester.
+func Block(ctx context.Context, log *slog.Logger, w http.ResponseWriter, r *http.Request, b Blocker) {
+	op := pkg + "Block"
+
+	log = log.With(slog.String("op", op))
+
+	requester, ok := ctx.Value(models.UserContextKey).(*models.User)
+	if !ok {
+		log.Error("failed to parse user from context")
+		utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+		return
+	}
+
+	if err := b.Block(ctx, requester, mux.Vars(r)["login"]); err != nil {
+		writeError(log, w, err, "failed to block user")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeError(log *slog.Logger, w http.ResponseWriter, err error, msg string) {
+	switch {
+	case errors.Is(err, models.ErrInvalidParams):
+		log.Warn(msg, slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, models.ErrUserNotFound):
+		log.Warn(msg, slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusNotFound, err.Error())
+	default:
+		log.Error(msg, slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+	}
+}
@@ -0,0 +1,60 @@
+package blockhandler
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"marketplace/internal/models"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockBlocker struct {
+	mock.Mock
+}
+
+func (m *mockBlocker) Block(ctx context.Context, requester *models.User, login string) error {
+	return m.Called(ctx, requester, login).Error(0)
+}
+
+func TestBlock(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{name: "success", wantStatus: http.StatusNoContent},
+		{name: "self", err: models.ErrInvalidParams, wantStatus: http.StatusBadRequest},
+		{name: "user not found", err: models.ErrUserNotFound, wantStatus: http.StatusNotFound},
+		{name: "internal", err: errors.New("db down"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			blocker := new(mockBlocker)
+			user := &models.User{ID: "u1", Login: "alice"}
+
+			blocker.On("Block", mock.Anything, user, "bob").Return(tt.err)
+
+			req := httptest.NewRequest(http.MethodPut, "/api/users/bob/block", nil)
+			req = mux.SetURLVars(req, map[string]string{"login": "bob"})
+			ctx := context.WithValue(req.Context(), models.UserContextKey, user)
+			rr := httptest.NewRecorder()
+
+			Block(ctx, slog.New(slog.NewTextHandler(io.Discard, nil)), rr, req, blocker)
+
+			assert.Equal(t, tt.wantStatus, rr.Code)
+			blocker.AssertExpectations(t)
+		})
+	}
+}
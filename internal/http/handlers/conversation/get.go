@@ -0,0 +1,93 @@
+package conversationhandler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"marketplace/internal/models"
+	utils "marketplace/internal/utils/http_errors"
+	"marketplace/internal/utils/mapper"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+func Get(ctx context.Context, log *slog.Logger, w http.ResponseWriter, r *http.Request, cp ConversationProvider) {
+	op := pkg + "Get"
+
+	log = log.With(slog.String("op", op))
+
+	requester, ok := ctx.Value(models.UserContextKey).(*models.User)
+	if !ok {
+		log.Error("failed to parse user from context")
+		utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+		return
+	}
+
+	limit := mapper.AtoiWithDefault(r.URL.Query().Get("limit"), 20)
+	offset := mapper.Atoi(r.URL.Query().Get("offset"))
+
+	conversations, err := cp.Conversations(ctx, requester, limit, offset)
+	if err != nil {
+		log.Error("failed to list conversations", slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+		return
+	}
+
+	response := map[string]any{
+		"data": map[string]any{
+			"conversations": mapper.DtoFromConversations(conversations),
+		},
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Error("failed to write response", slog.String("error", err.Error()))
+	}
+}
+
+func Messages(ctx context.Context, log *slog.Logger, w http.ResponseWriter, r *http.Request, mp MessageProvider) {
+	op := pkg + "Messages"
+
+	log = log.With(slog.String("op", op))
+
+	requester, ok := ctx.Value(models.UserContextKey).(*models.User)
+	if !ok {
+		log.Error("failed to parse user from context")
+		utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+		return
+	}
+
+	id := mux.Vars(r)["id"]
+	limit := mapper.AtoiWithDefault(r.URL.Query().Get("limit"), 50)
+	cursor := r.URL.Query().Get("cursor")
+
+	messages, next, err := mp.Messages(ctx, requester, id, cursor, limit)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrConversationNotFound):
+			log.Warn("conversation not found", slog.String("error", err.Error()))
+			utils.WriteJSONError(w, http.StatusNotFound, models.ErrConversationNotFound.Error())
+		case errors.Is(err, models.ErrInvalidCursor):
+			log.Warn("invalid cursor", slog.String("error", err.Error()))
+			utils.WriteJSONError(w, http.StatusBadRequest, models.ErrInvalidCursor.Error())
+		default:
+			log.Error("failed to list messages", slog.String("error", err.Error()))
+			utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+		}
+		return
+	}
+
+	response := map[string]any{
+		"data": map[string]any{
+			"messages":    mapper.DtoFromMessages(messages, requester.ID),
+			"next_cursor": next,
+		},
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Error("failed to write response", slog.String("error", err.Error()))
+	}
+}
@@ -0,0 +1,93 @@
+package conversationhandler
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"marketplace/internal/models"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockMessageProvider struct {
+	mock.Mock
+}
+
+func (m *mockMessageProvider) Messages(ctx context.Context, requester *models.User, conversationID string, cursor string, limit int) ([]*models.Message, string, error) {
+	args := m.Called(ctx, requester, conversationID, cursor, limit)
+	return args.Get(0).([]*models.Message), args.String(1), args.Error(2)
+}
+
+func TestMessages_Success(t *testing.T) {
+	t.Parallel()
+
+	user := &models.User{ID: "seller"}
+	provider := new(mockMessageProvider)
+
+	provider.On("Messages", mock.Anything, user, "c1", "abc", 2).
+		Return([]*models.Message{{ID: "m2", SenderID: "buyer"}, {ID: "m1", SenderID: "seller"}}, "next", nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/conversations/c1/messages?cursor=abc&limit=2", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "c1"})
+	ctx := context.WithValue(req.Context(), models.UserContextKey, user)
+	rr := httptest.NewRecorder()
+
+	Messages(ctx, slog.New(slog.NewTextHandler(io.Discard, nil)), rr, req, provider)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var resp struct {
+		Data struct {
+			Messages []struct {
+				ID     string `json:"id"`
+				IsMine bool   `json:"is_mine"`
+			} `json:"messages"`
+			NextCursor string `json:"next_cursor"`
+		} `json:"data"`
+	}
+	assert.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+	assert.Len(t, resp.Data.Messages, 2)
+	assert.False(t, resp.Data.Messages[0].IsMine)
+	assert.True(t, resp.Data.Messages[1].IsMine)
+	assert.Equal(t, "next", resp.Data.NextCursor)
+}
+
+func TestMessages_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{name: "not found", err: models.ErrConversationNotFound, wantStatus: http.StatusNotFound},
+		{name: "invalid cursor", err: models.ErrInvalidCursor, wantStatus: http.StatusBadRequest},
+		{name: "internal", err: models.ErrInternal, wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			user := &models.User{ID: "seller"}
+			provider := new(mockMessageProvider)
+
+			provider.On("Messages", mock.Anything, user, "c1", "", 50).Return(([]*models.Message)(nil), "", tt.err)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/conversations/c1/messages", nil)
+			req = mux.SetURLVars(req, map[string]string{"id": "c1"})
+			ctx := context.WithValue(req.Context(), models.UserContextKey, user)
+			rr := httptest.NewRecorder()
+
+			Messages(ctx, slog.New(slog.NewTextHandler(io.Discard, nil)), rr, req, provider)
+
+			assert.Equal(t, tt.wantStatus, rr.Code)
+		})
+	}
+}
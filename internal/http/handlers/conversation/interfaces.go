@@ -0,0 +1,24 @@
+package conversationhandler
+
+import (
+	"context"
+	"marketplace/internal/models"
+)
+
+const pkg = "conversationHandler/"
+
+type ConversationStarter interface {
+	StartConversation(ctx context.Context, requester *models.User, postID string, text string) (*models.Conversation, error)
+}
+
+type ConversationProvider interface {
+	Conversations(ctx context.Context, requester *models.User, limit int, offset int) ([]*models.Conversation, error)
+}
+
+type MessageProvider interface {
+	Messages(ctx context.Context, requester *models.User, conversationID string, cursor string, limit int) ([]*models.Message, string, error)
+}
+
+type MessageSender interface {
+	SendMessage(ctx context.Context, requester *models.User, conversationID string, text string) (*models.Message, error)
+}
@@ -0,0 +1,116 @@
+package conversationhandler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"marketplace/internal/dto"
+	"marketplace/internal/models"
+	utils "marketplace/internal/utils/http_errors"
+	"marketplace/internal/utils/mapper"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+func Add(ctx context.Context, log *slog.Logger, w http.ResponseWriter, r *http.Request, cs ConversationStarter) {
+	op := pkg + "Add"
+
+	log = log.With(slog.String("op", op))
+
+	requester, ok := ctx.Value(models.UserContextKey).(*models.User)
+	if !ok {
+		log.Error("failed to parse user from context")
+		utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+		return
+	}
+
+	var conversationRequest dto.ConversationRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&conversationRequest); err != nil {
+		log.Warn("failed to decode body", slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusBadRequest, models.ErrInvalidParams.Error())
+		return
+	}
+	defer r.Body.Close()
+
+	conversation, err := cs.StartConversation(ctx, requester, conversationRequest.PostID, conversationRequest.Text)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrInvalidText):
+			log.Warn("invalid message received", slog.String("error", err.Error()))
+			utils.WriteJSONError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, models.ErrPostNotFound):
+			log.Warn("post not found", slog.String("error", err.Error()))
+			utils.WriteJSONError(w, http.StatusNotFound, models.ErrPostNotFound.Error())
+		case errors.Is(err, models.ErrOwnPost):
+			log.Warn("conversation about own post", slog.String("error", err.Error()))
+			utils.WriteJSONError(w, http.StatusForbidden, models.ErrOwnPost.Error())
+		default:
+			log.Error("failed to start conversation", slog.String("error", err.Error()))
+			utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+		}
+		return
+	}
+
+	response := map[string]any{
+		"conversation": mapper.DtoFromConversation(conversation),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Error("failed to write response", slog.String("error", err.Error()))
+	}
+}
+
+func SendMessage(ctx context.Context, log *slog.Logger, w http.ResponseWriter, r *http.Request, ms MessageSender) {
+	op := pkg + "SendMessage"
+
+	log = log.With(slog.String("op", op))
+
+	requester, ok := ctx.Value(models.UserContextKey).(*models.User)
+	if !ok {
+		log.Error("failed to parse user from context")
+		utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+		return
+	}
+
+	var messageRequest dto.MessageRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&messageRequest); err != nil {
+		log.Warn("failed to decode body", slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusBadRequest, models.ErrInvalidParams.Error())
+		return
+	}
+	defer r.Body.Close()
+
+	id := mux.Vars(r)["id"]
+
+	message, err := ms.SendMessage(ctx, requester, id, messageRequest.Text)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrInvalidText):
+			log.Warn("invalid message received", slog.String("error", err.Error()))
+			utils.WriteJSONError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, models.ErrConversationNotFound):
+			log.Warn("conversation not found", slog.String("error", err.Error()))
+			utils.WriteJSONError(w, http.StatusNotFound, models.ErrConversationNotFound.Error())
+		default:
+			log.Error("failed to send message", slog.String("error", err.Error()))
+			utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+		}
+		return
+	}
+
+	response := map[string]any{
+		"message": mapper.DtoFromMessage(message, requester.ID),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Error("failed to write response", slog.String("error", err.Error()))
+	}
+}
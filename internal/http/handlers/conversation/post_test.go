@@ -0,0 +1,132 @@
+package conversationhandler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"marketplace/internal/models"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockConversationStarter struct {
+	mock.Mock
+}
+
+func (m *mockConversationStarter) StartConversation(ctx context.Context, requester *models.User, postID string, text string) (*models.Conversation, error) {
+	args := m.Called(ctx, requester, postID, text)
+	return args.Get(0).(*models.Conversation), args.Error(1)
+}
+
+type mockMessageSender struct {
+	mock.Mock
+}
+
+func (m *mockMessageSender) SendMessage(ctx context.Context, requester *models.User, conversationID string, text string) (*models.Message, error) {
+	args := m.Called(ctx, requester, conversationID, text)
+	return args.Get(0).(*models.Message), args.Error(1)
+}
+
+func TestAdd(t *testing.T) {
+	t.Parallel()
+
+	user := &models.User{ID: "buyer"}
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{name: "success", err: nil, wantStatus: http.StatusCreated},
+		{name: "invalid text", err: models.ErrInvalidText, wantStatus: http.StatusBadRequest},
+		{name: "post not found", err: models.ErrPostNotFound, wantStatus: http.StatusNotFound},
+		{name: "own post", err: models.ErrOwnPost, wantStatus: http.StatusForbidden},
+		{name: "internal", err: errors.New("db error"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			starter := new(mockConversationStarter)
+
+			var conversation *models.Conversation
+			if tt.err == nil {
+				conversation = &models.Conversation{ID: "c1", PostID: "p1"}
+			}
+
+			starter.On("StartConversation", mock.Anything, user, "p1", "hello").Return(conversation, tt.err)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/conversations", strings.NewReader(`{"post_id":"p1","text":"hello"}`))
+			ctx := context.WithValue(req.Context(), models.UserContextKey, user)
+			rr := httptest.NewRecorder()
+
+			Add(ctx, slog.New(slog.NewTextHandler(io.Discard, nil)), rr, req, starter)
+
+			assert.Equal(t, tt.wantStatus, rr.Code)
+			starter.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAdd_InvalidBody(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/conversations", strings.NewReader(`{`))
+	ctx := context.WithValue(req.Context(), models.UserContextKey, &models.User{ID: "buyer"})
+	rr := httptest.NewRecorder()
+
+	Add(ctx, slog.New(slog.NewTextHandler(io.Discard, nil)), rr, req, nil)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestSendMessage_Success(t *testing.T) {
+	t.Parallel()
+
+	user := &models.User{ID: "buyer", Login: "buyer"}
+	sender := new(mockMessageSender)
+
+	sender.On("SendMessage", mock.Anything, user, "c1", "hello").
+		Return(&models.Message{ID: "m1", SenderID: "buyer", SenderLogin: "buyer", Text: "hello"}, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/conversations/c1/messages", strings.NewReader(`{"text":"hello"}`))
+	req = mux.SetURLVars(req, map[string]string{"id": "c1"})
+	ctx := context.WithValue(req.Context(), models.UserContextKey, user)
+	rr := httptest.NewRecorder()
+
+	SendMessage(ctx, slog.New(slog.NewTextHandler(io.Discard, nil)), rr, req, sender)
+
+	assert.Equal(t, http.StatusCreated, rr.Code)
+
+	var resp map[string]map[string]any
+	assert.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+	assert.Equal(t, "m1", resp["message"]["id"])
+	assert.Equal(t, true, resp["message"]["is_mine"])
+}
+
+func TestSendMessage_NotFound(t *testing.T) {
+	t.Parallel()
+
+	user := &models.User{ID: "stranger"}
+	sender := new(mockMessageSender)
+
+	sender.On("SendMessage", mock.Anything, user, "c1", "hello").Return((*models.Message)(nil), models.ErrConversationNotFound)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/conversations/c1/messages", strings.NewReader(`{"text":"hello"}`))
+	req = mux.SetURLVars(req, map[string]string{"id": "c1"})
+	ctx := context.WithValue(req.Context(), models.UserContextKey, user)
+	rr := httptest.NewRecorder()
+
+	SendMessage(ctx, slog.New(slog.NewTextHandler(io.Discard, nil)), rr, req, sender)
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
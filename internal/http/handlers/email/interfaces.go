@@ -0,0 +1,13 @@
+package emailhandler
+
+import (
+	"context"
+	"marketplace/internal/models"
+)
+
+const pkg = "emailHandler/"
+
+type EmailVerifier interface {
+	Verify(ctx context.Context, token string) error
+	ResendVerification(ctx context.Context, requester *models.User) error
+}
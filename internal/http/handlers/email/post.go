@@ -0,0 +1,74 @@
+package emailhandler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"marketplace/internal/dto"
+	"marketplace/internal/models"
+	utils "marketplace/internal/utils/http_errors"
+	"net/http"
+)
+
+func Verify(ctx context.Context, log *slog.Logger, w http.ResponseWriter, r *http.Request, ev EmailVerifier) {
+	op := pkg + "Verify"
+
+	log = log.With(slog.String("op", op))
+
+	var verifyRequest dto.EmailVerifyRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&verifyRequest); err != nil || verifyRequest.Token == "" {
+		log.Warn("invalid verify request")
+		utils.WriteJSONError(w, http.StatusBadRequest, models.ErrInvalidParams.Error())
+		return
+	}
+	defer r.Body.Close()
+
+	if err := ev.Verify(ctx, verifyRequest.Token); err != nil {
+		writeEmailError(log, w, err, "failed to verify email")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func Resend(ctx context.Context, log *slog.Logger, w http.ResponseWriter, r *http.Request, ev EmailVerifier) {
+	op := pkg + "Resend"
+
+	log = log.With(slog.String("op", op))
+
+	requester, ok := ctx.Value(models.UserContextKey).(*models.User)
+	if !ok {
+		log.Error("failed to parse user from context")
+		utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+		return
+	}
+
+	if err := ev.ResendVerification(ctx, requester); err != nil {
+		writeEmailError(log, w, err, "failed to resend verification")
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func writeEmailError(log *slog.Logger, w http.ResponseWriter, err error, msg string) {
+	switch {
+	case errors.Is(err, models.ErrInvalidVerifyToken), errors.Is(err, models.ErrNoEmail):
+		log.Warn(msg, slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, models.ErrEmailAlreadyVerified):
+		log.Warn(msg, slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, models.ErrTooManyRequests):
+		log.Warn(msg, slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusTooManyRequests, err.Error())
+	case errors.Is(err, models.ErrUserNotFound):
+		log.Warn(msg, slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusNotFound, err.Error())
+	default:
+		log.Error(msg, slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+	}
+}
@@ -0,0 +1,94 @@
+package emailhandler
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"marketplace/internal/models"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockEmailVerifier struct {
+	mock.Mock
+}
+
+func (m *mockEmailVerifier) Verify(ctx context.Context, token string) error {
+	args := m.Called(ctx, token)
+	return args.Error(0)
+}
+
+func (m *mockEmailVerifier) ResendVerification(ctx context.Context, requester *models.User) error {
+	args := m.Called(ctx, requester)
+	return args.Error(0)
+}
+
+func TestVerify(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		body       string
+		serviceErr error
+		wantStatus int
+	}{
+		{name: "success", body: `{"token":"tok"}`, wantStatus: http.StatusNoContent},
+		{name: "invalid token", body: `{"token":"tok"}`, serviceErr: models.ErrInvalidVerifyToken, wantStatus: http.StatusBadRequest},
+		{name: "missing token", body: `{}`, wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			verifier := new(mockEmailVerifier)
+			verifier.On("Verify", mock.Anything, "tok").Return(tt.serviceErr)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/email/verify", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+
+			Verify(req.Context(), slog.New(slog.NewTextHandler(io.Discard, nil)), w, req, verifier)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
+
+func TestResend(t *testing.T) {
+	t.Parallel()
+
+	user := &models.User{ID: "1"}
+
+	tests := []struct {
+		name       string
+		serviceErr error
+		wantStatus int
+	}{
+		{name: "accepted", wantStatus: http.StatusAccepted},
+		{name: "rate limited", serviceErr: models.ErrTooManyRequests, wantStatus: http.StatusTooManyRequests},
+		{name: "already verified", serviceErr: models.ErrEmailAlreadyVerified, wantStatus: http.StatusConflict},
+		{name: "no email", serviceErr: models.ErrNoEmail, wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			verifier := new(mockEmailVerifier)
+			verifier.On("ResendVerification", mock.Anything, user).Return(tt.serviceErr)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/me/email/verification", nil)
+			ctx := context.WithValue(req.Context(), models.UserContextKey, user)
+			w := httptest.NewRecorder()
+
+			Resend(ctx, slog.New(slog.NewTextHandler(io.Discard, nil)), w, req, verifier)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package eventshandler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"marketplace/internal/models"
+	utils "marketplace/internal/utils/http_errors"
+	"net/http"
+	"time"
+)
+
+// Get streams the events of the requester as Server-Sent Events until the
+// client disconnects or the server shuts down.
+func Get(ctx context.Context, log *slog.Logger, w http.ResponseWriter, r *http.Request, es EventSubscriber, heartbeat time.Duration) {
+	op := pkg + "Get"
+
+	log = log.With(slog.String("op", op))
+
+	requester, ok := ctx.Value(models.UserContextKey).(*models.User)
+	if !ok {
+		log.Error("failed to parse user from context")
+		utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+		return
+	}
+
+	rc := http.NewResponseController(w)
+
+	// The server WriteTimeout is meant for regular requests and would cut the
+	// stream off after a few seconds.
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		log.Error("failed to reset write deadline", slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+		return
+	}
+
+	events, err := es.Subscribe(ctx, requester.ID, r.Header.Get("Last-Event-ID"))
+	if err != nil {
+		log.Error("failed to subscribe to events", slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	if err := rc.Flush(); err != nil {
+		log.Error("streaming is not supported", slog.String("error", err.Error()))
+		return
+	}
+
+	ticker := time.NewTicker(heartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				log.Debug("client gone", slog.String("error", err.Error()))
+				return
+			}
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			if _, err := fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", event.ID, event.Type, event.Payload); err != nil {
+				log.Debug("client gone", slog.String("error", err.Error()))
+				return
+			}
+		}
+
+		if err := rc.Flush(); err != nil {
+			log.Debug("failed to flush stream", slog.String("error", err.Error()))
+			return
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package eventshandler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"marketplace/internal/models"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockEventSubscriber struct {
+	mock.Mock
+}
+
+func (m *mockEventSubscriber) Subscribe(ctx context.Context, userID string, lastEventID string) (<-chan *models.Event, error) {
+	args := m.Called(ctx, userID, lastEventID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(chan *models.Event), args.Error(1)
+}
+
+func TestGet_StreamsEvents(t *testing.T) {
+	t.Parallel()
+
+	user := &models.User{ID: "user1"}
+	subscriber := new(mockEventSubscriber)
+
+	events := make(chan *models.Event, 2)
+	events <- &models.Event{ID: "5", Type: models.EventPostCreated, Payload: json.RawMessage(`{"post_id":"p1"}`)}
+	events <- &models.Event{ID: "6", Type: models.EventSessionEnded, Payload: json.RawMessage(`{"reason":"logout"}`)}
+	close(events)
+
+	subscriber.On("Subscribe", mock.Anything, "user1", "4").Return(events, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/me/events", nil)
+	req.Header.Set("Last-Event-ID", "4")
+
+	ctx := context.WithValue(req.Context(), models.UserContextKey, user)
+	rr := httptest.NewRecorder()
+
+	Get(ctx, slog.New(slog.NewTextHandler(io.Discard, nil)), rr, req, subscriber, time.Hour)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "text/event-stream", rr.Header().Get("Content-Type"))
+	assert.Equal(t,
+		"id: 5\nevent: post_created\ndata: {\"post_id\":\"p1\"}\n\n"+
+			"id: 6\nevent: session_ended\ndata: {\"reason\":\"logout\"}\n\n",
+		rr.Body.String(),
+	)
+	subscriber.AssertExpectations(t)
+}
+
+func TestGet_Heartbeat(t *testing.T) {
+	t.Parallel()
+
+	user := &models.User{ID: "user1"}
+	subscriber := new(mockEventSubscriber)
+
+	subscriber.On("Subscribe", mock.Anything, "user1", "").Return(make(chan *models.Event), nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/me/events", nil)
+
+	ctx, cancel := context.WithTimeout(context.WithValue(req.Context(), models.UserContextKey, user), 50*time.Millisecond)
+	defer cancel()
+
+	rr := httptest.NewRecorder()
+
+	Get(ctx, slog.New(slog.NewTextHandler(io.Discard, nil)), rr, req, subscriber, 10*time.Millisecond)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), ": heartbeat\n\n")
+}
+
+func TestGet_SubscribeFails(t *testing.T) {
+	t.Parallel()
+
+	user := &models.User{ID: "user1"}
+	subscriber := new(mockEventSubscriber)
+
+	subscriber.On("Subscribe", mock.Anything, "user1", "").Return(nil, errors.New("redis down"))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/me/events", nil)
+
+	ctx := context.WithValue(req.Context(), models.UserContextKey, user)
+	rr := httptest.NewRecorder()
+
+	Get(ctx, slog.New(slog.NewTextHandler(io.Discard, nil)), rr, req, subscriber, time.Hour)
+
+	assert.Equal(t, http.StatusInternalServerError, rr.Code)
+}
+
+func TestGet_NoUser(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/me/events", nil)
+	rr := httptest.NewRecorder()
+
+	Get(req.Context(), slog.New(slog.NewTextHandler(io.Discard, nil)), rr, req, nil, time.Hour)
+
+	assert.Equal(t, http.StatusInternalServerError, rr.Code)
+}
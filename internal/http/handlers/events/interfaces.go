@@ -0,0 +1,12 @@
+package eventshandler
+
+import (
+	"context"
+	"marketplace/internal/models"
+)
+
+const pkg = "eventsHandler/"
+
+type EventSubscriber interface {
+	Subscribe(ctx context.Context, userID string, lastEventID string) (<-chan *models.Event, error)
+}
@@ -0,0 +1,191 @@
+package feedhandler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"marketplace/internal/dto"
+	"marketplace/internal/models"
+	utils "marketplace/internal/utils/http_errors"
+	"marketplace/internal/utils/mapper"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	writeWait    = 10 * time.Second
+	maxFrameSize = 512
+)
+
+// Get upgrades the connection to a WebSocket and pushes newly published posts
+// matching the price range from the query. The client may change the range by
+// sending a dto.FeedFilterRequest message.
+//
+// Clients authenticate with the Authorization header or, in browsers, with a
+// ticket from POST /api/posts/live/ticket in the query. Browsers are only let
+// in from the allowed origins.
+func Get(ctx context.Context, log *slog.Logger, w http.ResponseWriter, r *http.Request, fs FeedSubscriber, pingInterval time.Duration, origins []string) {
+	op := pkg + "Get"
+
+	log = log.With(slog.String("op", op))
+
+	requester, ok := ctx.Value(models.UserContextKey).(*models.User)
+	if !ok {
+		ticket := r.URL.Query().Get("ticket")
+		if ticket == "" {
+			log.Debug("missing authorization header or ticket")
+			utils.WriteJSONError(w, http.StatusUnauthorized, "missing authorization header or ticket")
+			return
+		}
+
+		user, err := fs.UserByTicket(ctx, ticket)
+		if err != nil {
+			switch {
+			case errors.Is(err, models.ErrInvalidFeedTicket):
+				log.Warn("invalid feed ticket")
+				utils.WriteJSONError(w, http.StatusUnauthorized, err.Error())
+			case errors.Is(err, models.ErrUserBanned):
+				log.Warn("banned user opened feed")
+				utils.WriteJSONError(w, http.StatusForbidden, err.Error())
+			default:
+				log.Error("failed to redeem feed ticket", slog.String("error", err.Error()))
+				utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+			}
+			return
+		}
+		requester = user
+	}
+
+	upgrader := websocket.Upgrader{CheckOrigin: allowedOrigin(origins)}
+	if !upgrader.CheckOrigin(r) {
+		log.Warn("origin not allowed", slog.String("origin", r.Header.Get("Origin")))
+		utils.WriteJSONError(w, http.StatusForbidden, models.ErrForbidden.Error())
+		return
+	}
+
+	filter := models.PostsFilter{
+		MinPrice: uint(mapper.Atoi(r.URL.Query().Get("minprice"))),
+		MaxPrice: uint(mapper.Atoi(r.URL.Query().Get("maxprice"))),
+	}
+
+	sub, err := fs.Subscribe(ctx, requester, &filter)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrInvalidFilter):
+			log.Warn("invalid filter", slog.String("error", err.Error()))
+			utils.WriteJSONError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, models.ErrTooManyConnections):
+			log.Warn("too many feed connections", slog.String("error", err.Error()))
+			utils.WriteJSONError(w, http.StatusTooManyRequests, models.ErrTooManyConnections.Error())
+		default:
+			log.Error("failed to subscribe to feed", slog.String("error", err.Error()))
+			utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+		}
+		return
+	}
+	defer sub.Close()
+
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Warn("failed to upgrade connection", slog.String("error", err.Error()))
+		return
+	}
+	defer conn.Close()
+
+	replies := make(chan string, 1)
+	done := make(chan struct{})
+
+	go readFilters(conn, sub, replies, done, 2*pingInterval)
+
+	ticker := time.NewTicker(pingInterval)
+	defer ticker.Stop()
+
+	for {
+		var message *dto.FeedMessage
+
+		select {
+		case <-ctx.Done():
+			writeClose(conn, websocket.CloseGoingAway, "server is shutting down")
+			return
+		case <-done:
+			return
+		case <-ticker.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeWait)); err != nil {
+				log.Debug("failed to ping client", slog.String("error", err.Error()))
+				return
+			}
+			continue
+		case reply := <-replies:
+			message = &dto.FeedMessage{Error: reply}
+		case post, ok := <-sub.Posts():
+			if !ok {
+				log.Warn("client is too slow, closing feed")
+				writeClose(conn, websocket.CloseTryAgainLater, "client is too slow")
+				return
+			}
+			message = &dto.FeedMessage{PostID: post.ID, Post: mapper.DtoFromPost(post)}
+		}
+
+		_ = conn.SetWriteDeadline(time.Now().Add(writeWait))
+		if err := conn.WriteJSON(message); err != nil {
+			log.Debug("failed to write message", slog.String("error", err.Error()))
+			return
+		}
+	}
+}
+
+// allowedOrigin lets in browsers from the origins given. Clients that send no
+// Origin header are not browsers, so cross-site requests cannot come from
+// them.
+func allowedOrigin(origins []string) func(r *http.Request) bool {
+	return func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		return origin == "" || slices.Contains(origins, origin)
+	}
+}
+
+// readFilters applies filter updates sent by the client and keeps the read
+// deadline alive on pongs. It closes done once the connection is gone.
+func readFilters(conn *websocket.Conn, sub models.PostSubscription, replies chan<- string, done chan<- struct{}, pongWait time.Duration) {
+	defer close(done)
+
+	conn.SetReadLimit(maxFrameSize)
+	_ = conn.SetReadDeadline(time.Now().Add(pongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		var req dto.FeedFilterRequest
+		if err := json.Unmarshal(data, &req); err != nil {
+			reply(replies, models.ErrInvalidFilter.Error())
+			continue
+		}
+
+		if err := sub.SetFilter(&models.PostsFilter{MinPrice: req.MinPrice, MaxPrice: req.MaxPrice}); err != nil {
+			reply(replies, err.Error())
+		}
+	}
+}
+
+// reply never blocks the reader: the reply is dropped when the previous one
+// has not been written yet.
+func reply(replies chan<- string, message string) {
+	select {
+	case replies <- message:
+	default:
+	}
+}
+
+func writeClose(conn *websocket.Conn, code int, text string) {
+	_ = conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, text), time.Now().Add(writeWait))
+}
@@ -0,0 +1,267 @@
+package feedhandler
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"marketplace/internal/dto"
+	"marketplace/internal/models"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockFeedSubscriber struct {
+	mock.Mock
+}
+
+func (m *mockFeedSubscriber) Subscribe(ctx context.Context, requester *models.User, filter *models.PostsFilter) (models.PostSubscription, error) {
+	args := m.Called(ctx, requester, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(models.PostSubscription), args.Error(1)
+}
+
+func (m *mockFeedSubscriber) UserByTicket(ctx context.Context, ticket string) (*models.User, error) {
+	args := m.Called(ctx, ticket)
+	user, _ := args.Get(0).(*models.User)
+	return user, args.Error(1)
+}
+
+type fakeSubscription struct {
+	posts   chan *models.PostWithDocument
+	filters chan *models.PostsFilter
+	closed  chan struct{}
+}
+
+func newFakeSubscription() *fakeSubscription {
+	return &fakeSubscription{
+		posts:   make(chan *models.PostWithDocument, 1),
+		filters: make(chan *models.PostsFilter, 1),
+		closed:  make(chan struct{}),
+	}
+}
+
+func (s *fakeSubscription) Posts() <-chan *models.PostWithDocument {
+	return s.posts
+}
+
+func (s *fakeSubscription) SetFilter(filter *models.PostsFilter) error {
+	if filter.MaxPrice > 0 && filter.MinPrice > filter.MaxPrice {
+		return models.ErrInvalidFilter
+	}
+	s.filters <- filter
+	return nil
+}
+
+func (s *fakeSubscription) Close() {
+	close(s.closed)
+}
+
+// startServer serves the feed to user, or to anyone with a ticket when user
+// is nil.
+func startServer(t *testing.T, user *models.User, fs FeedSubscriber) *httptest.Server {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		if user != nil {
+			ctx = context.WithValue(ctx, models.UserContextKey, user)
+		}
+		Get(ctx, slog.New(slog.NewTextHandler(io.Discard, nil)), w, r, fs, time.Minute, []string{"https://market.example"})
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func wsURL(srv *httptest.Server, query string) string {
+	return "ws" + strings.TrimPrefix(srv.URL, "http") + "/api/posts/live" + query
+}
+
+func TestGet_PushesPosts(t *testing.T) {
+	t.Parallel()
+
+	user := &models.User{ID: "u1"}
+	sub := newFakeSubscription()
+	subscriber := new(mockFeedSubscriber)
+
+	subscriber.On("Subscribe", mock.Anything, user, &models.PostsFilter{MinPrice: 10, MaxPrice: 500}).Return(sub, nil)
+
+	srv := startServer(t, user, subscriber)
+
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL(srv, "?minprice=10&maxprice=500"), nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer conn.Close()
+
+	sub.posts <- &models.PostWithDocument{ID: "p1", Header: "bike", Price: 100, OwnerLogin: "seller"}
+
+	var message dto.FeedMessage
+	assert.NoError(t, conn.ReadJSON(&message))
+	assert.Equal(t, "p1", message.PostID)
+	assert.Equal(t, "bike", message.Post.Header)
+	assert.Equal(t, "seller", message.Post.OwnerLogin)
+
+	assert.NoError(t, conn.WriteJSON(dto.FeedFilterRequest{MinPrice: 1000}))
+
+	select {
+	case filter := <-sub.filters:
+		assert.Equal(t, uint(1000), filter.MinPrice)
+	case <-time.After(time.Second):
+		t.Fatal("filter was not updated")
+	}
+
+	assert.NoError(t, conn.WriteJSON(dto.FeedFilterRequest{MinPrice: 10, MaxPrice: 5}))
+
+	message = dto.FeedMessage{}
+	assert.NoError(t, conn.ReadJSON(&message))
+	assert.Equal(t, models.ErrInvalidFilter.Error(), message.Error)
+
+	conn.Close()
+
+	select {
+	case <-sub.closed:
+	case <-time.After(time.Second):
+		t.Fatal("subscription was not closed")
+	}
+}
+
+func TestGet_SlowClientClosed(t *testing.T) {
+	t.Parallel()
+
+	user := &models.User{ID: "u1"}
+	sub := newFakeSubscription()
+	subscriber := new(mockFeedSubscriber)
+
+	subscriber.On("Subscribe", mock.Anything, user, mock.Anything).Return(sub, nil)
+
+	srv := startServer(t, user, subscriber)
+
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL(srv, ""), nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer conn.Close()
+
+	close(sub.posts)
+
+	_, _, err = conn.ReadMessage()
+
+	var closeErr *websocket.CloseError
+	if assert.ErrorAs(t, err, &closeErr) {
+		assert.Equal(t, websocket.CloseTryAgainLater, closeErr.Code)
+	}
+}
+
+func TestGet_SubscribeErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{name: "invalid filter", err: models.ErrInvalidFilter, wantStatus: http.StatusBadRequest},
+		{name: "too many connections", err: models.ErrTooManyConnections, wantStatus: http.StatusTooManyRequests},
+		{name: "internal", err: errors.New("boom"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			user := &models.User{ID: "u1"}
+			subscriber := new(mockFeedSubscriber)
+
+			subscriber.On("Subscribe", mock.Anything, user, mock.Anything).Return(nil, tt.err)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/posts/live", nil)
+			ctx := context.WithValue(req.Context(), models.UserContextKey, user)
+			rr := httptest.NewRecorder()
+
+			Get(ctx, slog.New(slog.NewTextHandler(io.Discard, nil)), rr, req, subscriber, time.Minute, nil)
+
+			assert.Equal(t, tt.wantStatus, rr.Code)
+		})
+	}
+}
+
+func TestGet_Ticket(t *testing.T) {
+	t.Parallel()
+
+	user := &models.User{ID: "u1"}
+	sub := newFakeSubscription()
+	subscriber := new(mockFeedSubscriber)
+
+	subscriber.On("UserByTicket", mock.Anything, "t1").Return(user, nil)
+	subscriber.On("Subscribe", mock.Anything, user, mock.Anything).Return(sub, nil)
+
+	srv := startServer(t, nil, subscriber)
+
+	header := http.Header{"Origin": []string{"https://market.example"}}
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL(srv, "?ticket=t1"), header)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer conn.Close()
+
+	sub.posts <- &models.PostWithDocument{ID: "p1"}
+
+	var message dto.FeedMessage
+	assert.NoError(t, conn.ReadJSON(&message))
+	assert.Equal(t, "p1", message.PostID)
+}
+
+func TestGet_Rejects(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		query      string
+		origin     string
+		ticketErr  error
+		wantStatus int
+	}{
+		{name: "no credentials", wantStatus: http.StatusUnauthorized},
+		{name: "used ticket", query: "?ticket=t1", ticketErr: models.ErrInvalidFeedTicket, wantStatus: http.StatusUnauthorized},
+		{name: "banned", query: "?ticket=t1", ticketErr: models.ErrUserBanned, wantStatus: http.StatusForbidden},
+		{name: "foreign origin", query: "?ticket=t1", origin: "https://evil.example", wantStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			subscriber := new(mockFeedSubscriber)
+			if tt.ticketErr != nil {
+				subscriber.On("UserByTicket", mock.Anything, "t1").Return(nil, tt.ticketErr)
+			} else {
+				subscriber.On("UserByTicket", mock.Anything, "t1").Return(&models.User{ID: "u1"}, nil)
+			}
+
+			srv := startServer(t, nil, subscriber)
+
+			header := http.Header{}
+			if tt.origin != "" {
+				header.Set("Origin", tt.origin)
+			}
+			_, resp, err := websocket.DefaultDialer.Dial(wsURL(srv, tt.query), header)
+
+			assert.ErrorIs(t, err, websocket.ErrBadHandshake)
+			if assert.NotNil(t, resp) {
+				assert.Equal(t, tt.wantStatus, resp.StatusCode)
+			}
+			subscriber.AssertNotCalled(t, "Subscribe", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
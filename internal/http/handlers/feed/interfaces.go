@@ -0,0 +1,17 @@
+package feedhandler
+
+import (
+	"context"
+	"marketplace/internal/models"
+)
+
+const pkg = "feedHandler/"
+
+type FeedSubscriber interface {
+	Subscribe(ctx context.Context, requester *models.User, filter *models.PostsFilter) (models.PostSubscription, error)
+	UserByTicket(ctx context.Context, ticket string) (*models.User, error)
+}
+
+type FeedTicketIssuer interface {
+	IssueTicket(ctx context.Context, requester *models.User) (*models.FeedTicket, error)
+}
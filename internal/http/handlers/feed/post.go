@@ -0,0 +1,45 @@
+package feedhandler
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"marketplace/internal/models"
+	utils "marketplace/internal/utils/http_errors"
+	"net/http"
+)
+
+// Ticket issues a ticket for opening the live feed from a browser, which
+// cannot authenticate the WebSocket handshake with a header.
+func Ticket(ctx context.Context, log *slog.Logger, w http.ResponseWriter, r *http.Request, ti FeedTicketIssuer) {
+	op := pkg + "Ticket"
+
+	log = log.With(slog.String("op", op))
+
+	requester, ok := ctx.Value(models.UserContextKey).(*models.User)
+	if !ok {
+		log.Error("failed to parse user from context")
+		utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+		return
+	}
+
+	ticket, err := ti.IssueTicket(ctx, requester)
+	if err != nil {
+		log.Error("failed to issue feed ticket", slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+		return
+	}
+
+	response := map[string]any{
+		"data": map[string]any{
+			"ticket":     ticket.Ticket,
+			"expires_at": ticket.ExpiresAt,
+		},
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Error("failed to write response", slog.String("error", err.Error()))
+	}
+}
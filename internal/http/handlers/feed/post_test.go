@@ -0,0 +1,66 @@
+package feedhandler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"marketplace/internal/models"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockFeedTicketIssuer struct {
+	mock.Mock
+}
+
+func (m *mockFeedTicketIssuer) IssueTicket(ctx context.Context, requester *models.User) (*models.FeedTicket, error) {
+	args := m.Called(ctx, requester)
+	ticket, _ := args.Get(0).(*models.FeedTicket)
+	return ticket, args.Error(1)
+}
+
+func TestTicket(t *testing.T) {
+	t.Parallel()
+
+	user := &models.User{ID: "u1"}
+
+	tests := []struct {
+		name       string
+		ticket     *models.FeedTicket
+		serviceErr error
+		wantStatus int
+	}{
+		{name: "issued", ticket: &models.FeedTicket{Ticket: "t1", ExpiresAt: time.Now().Add(30 * time.Second)}, wantStatus: http.StatusCreated},
+		{name: "service error", serviceErr: errors.New("boom"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			issuer := new(mockFeedTicketIssuer)
+			issuer.On("IssueTicket", mock.Anything, user).Return(tt.ticket, tt.serviceErr)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/posts/live/ticket", nil)
+			ctx := context.WithValue(req.Context(), models.UserContextKey, user)
+			w := httptest.NewRecorder()
+
+			Ticket(ctx, slog.New(slog.NewTextHandler(io.Discard, nil)), w, req, issuer)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+
+			if tt.serviceErr == nil {
+				var result map[string]map[string]string
+				assert.NoError(t, json.NewDecoder(w.Body).Decode(&result))
+				assert.Equal(t, "t1", result["data"]["ticket"])
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package notificationhandler
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"marketplace/internal/models"
+	utils "marketplace/internal/utils/http_errors"
+	"marketplace/internal/utils/mapper"
+	"net/http"
+)
+
+func Get(ctx context.Context, log *slog.Logger, w http.ResponseWriter, r *http.Request, np NotificationProvider) {
+	op := pkg + "Get"
+
+	log = log.With(slog.String("op", op))
+
+	requester, ok := ctx.Value(models.UserContextKey).(*models.User)
+	if !ok {
+		log.Error("failed to parse user from context")
+		utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+		return
+	}
+
+	limit := mapper.AtoiWithDefault(r.URL.Query().Get("limit"), 20)
+	offset := mapper.Atoi(r.URL.Query().Get("offset"))
+	unreadOnly := r.URL.Query().Get("unread") == "true"
+
+	notifications, unread, err := np.Notifications(ctx, requester, limit, offset, unreadOnly)
+	if err != nil {
+		log.Error("failed to list notifications", slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+		return
+	}
+
+	response := map[string]any{
+		"data": map[string]any{
+			"notifications": mapper.DtoFromNotifications(notifications),
+			"unread_count":  unread,
+		},
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Error("failed to write response", slog.String("error", err.Error()))
+	}
+}
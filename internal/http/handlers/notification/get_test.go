@@ -0,0 +1,84 @@
+package notificationhandler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"marketplace/internal/models"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockNotificationProvider struct {
+	mock.Mock
+}
+
+func (m *mockNotificationProvider) Notifications(ctx context.Context, requester *models.User, limit int, offset int, unreadOnly bool) ([]*models.Notification, int, error) {
+	args := m.Called(ctx, requester, limit, offset, unreadOnly)
+	return args.Get(0).([]*models.Notification), args.Int(1), args.Error(2)
+}
+
+func TestGet_Success(t *testing.T) {
+	t.Parallel()
+
+	np := new(mockNotificationProvider)
+	user := &models.User{ID: "1"}
+
+	notifications := []*models.Notification{
+		{ID: "n1", Kind: models.NotificationSearchMatch, Payload: json.RawMessage(`{"search_id":"s1","post_id":"p1"}`), CreatedAt: time.Now()},
+	}
+
+	np.On("Notifications", mock.Anything, user, 5, 10, true).Return(notifications, 1, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/me/notifications?limit=5&offset=10&unread=true", nil)
+	ctx := context.WithValue(req.Context(), models.UserContextKey, user)
+	rr := httptest.NewRecorder()
+
+	Get(ctx, slog.New(slog.NewTextHandler(io.Discard, nil)), rr, req, np)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var resp struct {
+		Data struct {
+			Notifications []struct {
+				ID      string            `json:"id"`
+				Kind    string            `json:"kind"`
+				Payload map[string]string `json:"payload"`
+			} `json:"notifications"`
+			UnreadCount int `json:"unread_count"`
+		} `json:"data"`
+	}
+	err := json.NewDecoder(rr.Body).Decode(&resp)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, resp.Data.UnreadCount)
+	assert.Len(t, resp.Data.Notifications, 1)
+	assert.Equal(t, "p1", resp.Data.Notifications[0].Payload["post_id"])
+
+	np.AssertExpectations(t)
+}
+
+func TestGet_InternalError(t *testing.T) {
+	t.Parallel()
+
+	np := new(mockNotificationProvider)
+	user := &models.User{ID: "1"}
+
+	np.On("Notifications", mock.Anything, user, 20, 0, false).Return(([]*models.Notification)(nil), 0, errors.New("db down"))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/me/notifications", nil)
+	ctx := context.WithValue(req.Context(), models.UserContextKey, user)
+	rr := httptest.NewRecorder()
+
+	Get(ctx, slog.New(slog.NewTextHandler(io.Discard, nil)), rr, req, np)
+
+	assert.Equal(t, http.StatusInternalServerError, rr.Code)
+
+	np.AssertExpectations(t)
+}
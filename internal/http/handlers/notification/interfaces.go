@@ -0,0 +1,17 @@
+package notificationhandler
+
+import (
+	"context"
+	"marketplace/internal/models"
+)
+
+const pkg = "notificationHandler/"
+
+type NotificationProvider interface {
+	Notifications(ctx context.Context, requester *models.User, limit int, offset int, unreadOnly bool) ([]*models.Notification, int, error)
+}
+
+type NotificationUpdater interface {
+	MarkRead(ctx context.Context, requester *models.User, id string) error
+	MarkAllRead(ctx context.Context, requester *models.User) error
+}
@@ -0,0 +1,82 @@
+package notificationhandler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"marketplace/internal/models"
+	utils "marketplace/internal/utils/http_errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+func MarkRead(ctx context.Context, log *slog.Logger, w http.ResponseWriter, r *http.Request, nu NotificationUpdater) {
+	op := pkg + "MarkRead"
+
+	log = log.With(slog.String("op", op))
+
+	requester, ok := ctx.Value(models.UserContextKey).(*models.User)
+	if !ok {
+		log.Error("failed to parse user from context")
+		utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+		return
+	}
+
+	id := mux.Vars(r)["id"]
+
+	err := nu.MarkRead(ctx, requester, id)
+	if err != nil {
+		if errors.Is(err, models.ErrNotificationNotFound) {
+			log.Warn("notification not found", slog.String("error", err.Error()))
+			utils.WriteJSONError(w, http.StatusNotFound, models.ErrNotificationNotFound.Error())
+			return
+		}
+		log.Error("failed to mark notification as read", slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+		return
+	}
+
+	response := map[string]any{
+		"response": map[string]any{
+			id: true,
+		},
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Error("failed to write response", slog.String("error", err.Error()))
+	}
+}
+
+func MarkAllRead(ctx context.Context, log *slog.Logger, w http.ResponseWriter, r *http.Request, nu NotificationUpdater) {
+	op := pkg + "MarkAllRead"
+
+	log = log.With(slog.String("op", op))
+
+	requester, ok := ctx.Value(models.UserContextKey).(*models.User)
+	if !ok {
+		log.Error("failed to parse user from context")
+		utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+		return
+	}
+
+	err := nu.MarkAllRead(ctx, requester)
+	if err != nil {
+		log.Error("failed to mark notifications as read", slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+		return
+	}
+
+	response := map[string]any{
+		"response": map[string]any{
+			"read": true,
+		},
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Error("failed to write response", slog.String("error", err.Error()))
+	}
+}
@@ -0,0 +1,88 @@
+package notificationhandler
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"marketplace/internal/models"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockNotificationUpdater struct {
+	mock.Mock
+}
+
+func (m *mockNotificationUpdater) MarkRead(ctx context.Context, requester *models.User, id string) error {
+	args := m.Called(ctx, requester, id)
+	return args.Error(0)
+}
+
+func (m *mockNotificationUpdater) MarkAllRead(ctx context.Context, requester *models.User) error {
+	args := m.Called(ctx, requester)
+	return args.Error(0)
+}
+
+func TestMarkRead_Success(t *testing.T) {
+	t.Parallel()
+
+	nu := new(mockNotificationUpdater)
+	user := &models.User{ID: "1"}
+
+	nu.On("MarkRead", mock.Anything, user, "n1").Return(nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/me/notifications/n1/read", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "n1"})
+	ctx := context.WithValue(req.Context(), models.UserContextKey, user)
+	rr := httptest.NewRecorder()
+
+	MarkRead(ctx, slog.New(slog.NewTextHandler(io.Discard, nil)), rr, req, nu)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	nu.AssertExpectations(t)
+}
+
+func TestMarkRead_NotFound(t *testing.T) {
+	t.Parallel()
+
+	nu := new(mockNotificationUpdater)
+	user := &models.User{ID: "1"}
+
+	nu.On("MarkRead", mock.Anything, user, "n1").Return(models.ErrNotificationNotFound)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/me/notifications/n1/read", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "n1"})
+	ctx := context.WithValue(req.Context(), models.UserContextKey, user)
+	rr := httptest.NewRecorder()
+
+	MarkRead(ctx, slog.New(slog.NewTextHandler(io.Discard, nil)), rr, req, nu)
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	nu.AssertExpectations(t)
+}
+
+func TestMarkAllRead_Success(t *testing.T) {
+	t.Parallel()
+
+	nu := new(mockNotificationUpdater)
+	user := &models.User{ID: "1"}
+
+	nu.On("MarkAllRead", mock.Anything, user).Return(nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/me/notifications/read", nil)
+	ctx := context.WithValue(req.Context(), models.UserContextKey, user)
+	rr := httptest.NewRecorder()
+
+	MarkAllRead(ctx, slog.New(slog.NewTextHandler(io.Discard, nil)), rr, req, nu)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	nu.AssertExpectations(t)
+}
@@ -0,0 +1,94 @@
+package offerhandler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"marketplace/internal/models"
+	utils "marketplace/internal/utils/http_errors"
+	"marketplace/internal/utils/mapper"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+func Get(ctx context.Context, log *slog.Logger, w http.ResponseWriter, r *http.Request, ofp OfferProvider) {
+	op := pkg + "Get"
+
+	log = log.With(slog.String("op", op))
+
+	requester, ok := ctx.Value(models.UserContextKey).(*models.User)
+	if !ok {
+		log.Error("failed to parse user from context")
+		utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+		return
+	}
+
+	postID := mux.Vars(r)["id"]
+
+	offers, err := ofp.Offers(ctx, requester, postID)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrPostNotFound):
+			log.Warn("post not found", slog.String("error", err.Error()))
+			utils.WriteJSONError(w, http.StatusNotFound, models.ErrPostNotFound.Error())
+		default:
+			log.Error("failed to list offers", slog.String("error", err.Error()))
+			utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+		}
+		return
+	}
+
+	response := map[string]any{
+		"data": map[string]any{
+			"offers": mapper.DtoFromOffers(offers),
+		},
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Error("failed to write response", slog.String("error", err.Error()))
+	}
+}
+
+func Mine(ctx context.Context, log *slog.Logger, w http.ResponseWriter, r *http.Request, ofp OfferProvider) {
+	op := pkg + "Mine"
+
+	log = log.With(slog.String("op", op))
+
+	requester, ok := ctx.Value(models.UserContextKey).(*models.User)
+	if !ok {
+		log.Error("failed to parse user from context")
+		utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+		return
+	}
+
+	role := models.OfferRole(r.URL.Query().Get("role"))
+	limit := mapper.AtoiWithDefault(r.URL.Query().Get("limit"), 20)
+	offset := mapper.Atoi(r.URL.Query().Get("offset"))
+
+	offers, err := ofp.MyOffers(ctx, requester, role, limit, offset)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrInvalidParams):
+			log.Warn("invalid offer role", slog.String("error", err.Error()))
+			utils.WriteJSONError(w, http.StatusBadRequest, models.ErrInvalidParams.Error())
+		default:
+			log.Error("failed to list offers", slog.String("error", err.Error()))
+			utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+		}
+		return
+	}
+
+	response := map[string]any{
+		"data": map[string]any{
+			"offers": mapper.DtoFromOffers(offers),
+		},
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Error("failed to write response", slog.String("error", err.Error()))
+	}
+}
@@ -0,0 +1,108 @@
+package offerhandler
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"marketplace/internal/models"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockOfferProvider struct {
+	mock.Mock
+}
+
+func (m *mockOfferProvider) Offers(ctx context.Context, requester *models.User, postID string) ([]*models.Offer, error) {
+	args := m.Called(ctx, requester, postID)
+	return args.Get(0).([]*models.Offer), args.Error(1)
+}
+
+func (m *mockOfferProvider) MyOffers(ctx context.Context, requester *models.User, role models.OfferRole, limit int, offset int) ([]*models.Offer, error) {
+	args := m.Called(ctx, requester, role, limit, offset)
+	return args.Get(0).([]*models.Offer), args.Error(1)
+}
+
+func TestGet(t *testing.T) {
+	t.Parallel()
+
+	user := &models.User{ID: "seller"}
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{name: "success", err: nil, wantStatus: http.StatusOK},
+		{name: "post not found", err: models.ErrPostNotFound, wantStatus: http.StatusNotFound},
+		{name: "internal", err: errors.New("db error"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			provider := new(mockOfferProvider)
+			provider.On("Offers", mock.Anything, user, "p1").
+				Return([]*models.Offer{{ID: "o1", PostID: "p1"}}, tt.err)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/posts/p1/offers", nil)
+			req = mux.SetURLVars(req, map[string]string{"id": "p1"})
+			ctx := context.WithValue(req.Context(), models.UserContextKey, user)
+			rr := httptest.NewRecorder()
+
+			Get(ctx, slog.New(slog.NewTextHandler(io.Discard, nil)), rr, req, provider)
+
+			assert.Equal(t, tt.wantStatus, rr.Code)
+			provider.AssertExpectations(t)
+		})
+	}
+}
+
+func TestMine(t *testing.T) {
+	t.Parallel()
+
+	user := &models.User{ID: "buyer"}
+
+	tests := []struct {
+		name       string
+		query      string
+		role       models.OfferRole
+		err        error
+		wantStatus int
+	}{
+		{name: "all", query: "", role: models.OfferRoleAny, err: nil, wantStatus: http.StatusOK},
+		{name: "buyer", query: "?role=buyer&limit=5&offset=10", role: models.OfferRoleBuyer, err: nil, wantStatus: http.StatusOK},
+		{name: "invalid role", query: "?role=admin", role: models.OfferRole("admin"), err: models.ErrInvalidParams, wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			limit, offset := 20, 0
+			if tt.role == models.OfferRoleBuyer {
+				limit, offset = 5, 10
+			}
+
+			provider := new(mockOfferProvider)
+			provider.On("MyOffers", mock.Anything, user, tt.role, limit, offset).
+				Return([]*models.Offer{}, tt.err)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/me/offers"+tt.query, nil)
+			ctx := context.WithValue(req.Context(), models.UserContextKey, user)
+			rr := httptest.NewRecorder()
+
+			Mine(ctx, slog.New(slog.NewTextHandler(io.Discard, nil)), rr, req, provider)
+
+			assert.Equal(t, tt.wantStatus, rr.Code)
+			provider.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package offerhandler
+
+import (
+	"context"
+	"marketplace/internal/models"
+)
+
+const pkg = "offerHandler/"
+
+type OfferMaker interface {
+	MakeOffer(ctx context.Context, requester *models.User, postID string, amount int64) (*models.Offer, error)
+}
+
+type OfferProvider interface {
+	Offers(ctx context.Context, requester *models.User, postID string) ([]*models.Offer, error)
+	MyOffers(ctx context.Context, requester *models.User, role models.OfferRole, limit int, offset int) ([]*models.Offer, error)
+}
+
+type OfferResponder interface {
+	AcceptOffer(ctx context.Context, requester *models.User, postID string, offerID string) (*models.Offer, error)
+	RejectOffer(ctx context.Context, requester *models.User, postID string, offerID string) (*models.Offer, error)
+	CounterOffer(ctx context.Context, requester *models.User, postID string, offerID string, amount int64) (*models.Offer, error)
+}
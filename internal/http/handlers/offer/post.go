@@ -0,0 +1,166 @@
+package offerhandler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"marketplace/internal/dto"
+	"marketplace/internal/models"
+	utils "marketplace/internal/utils/http_errors"
+	"marketplace/internal/utils/mapper"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+func Add(ctx context.Context, log *slog.Logger, w http.ResponseWriter, r *http.Request, om OfferMaker) {
+	op := pkg + "Add"
+
+	log = log.With(slog.String("op", op))
+
+	requester, ok := ctx.Value(models.UserContextKey).(*models.User)
+	if !ok {
+		log.Error("failed to parse user from context")
+		utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+		return
+	}
+
+	var offerRequest dto.OfferRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&offerRequest); err != nil {
+		log.Warn("failed to decode body", slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusBadRequest, models.ErrInvalidParams.Error())
+		return
+	}
+	defer r.Body.Close()
+
+	postID := mux.Vars(r)["id"]
+
+	offer, err := om.MakeOffer(ctx, requester, postID, offerRequest.Amount)
+	if err != nil {
+		writeOfferError(log, w, err, "failed to make offer")
+		return
+	}
+
+	writeOffer(log, w, http.StatusCreated, offer)
+}
+
+func Accept(ctx context.Context, log *slog.Logger, w http.ResponseWriter, r *http.Request, or OfferResponder) {
+	op := pkg + "Accept"
+
+	log = log.With(slog.String("op", op))
+
+	requester, ok := ctx.Value(models.UserContextKey).(*models.User)
+	if !ok {
+		log.Error("failed to parse user from context")
+		utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+		return
+	}
+
+	vars := mux.Vars(r)
+
+	offer, err := or.AcceptOffer(ctx, requester, vars["id"], vars["offerID"])
+	if err != nil {
+		writeOfferError(log, w, err, "failed to accept offer")
+		return
+	}
+
+	writeOffer(log, w, http.StatusOK, offer)
+}
+
+func Reject(ctx context.Context, log *slog.Logger, w http.ResponseWriter, r *http.Request, or OfferResponder) {
+	op := pkg + "Reject"
+
+	log = log.With(slog.String("op", op))
+
+	requester, ok := ctx.Value(models.UserContextKey).(*models.User)
+	if !ok {
+		log.Error("failed to parse user from context")
+		utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+		return
+	}
+
+	vars := mux.Vars(r)
+
+	offer, err := or.RejectOffer(ctx, requester, vars["id"], vars["offerID"])
+	if err != nil {
+		writeOfferError(log, w, err, "failed to reject offer")
+		return
+	}
+
+	writeOffer(log, w, http.StatusOK, offer)
+}
+
+func Counter(ctx context.Context, log *slog.Logger, w http.ResponseWriter, r *http.Request, or OfferResponder) {
+	op := pkg + "Counter"
+
+	log = log.With(slog.String("op", op))
+
+	requester, ok := ctx.Value(models.UserContextKey).(*models.User)
+	if !ok {
+		log.Error("failed to parse user from context")
+		utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+		return
+	}
+
+	var offerRequest dto.OfferRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&offerRequest); err != nil {
+		log.Warn("failed to decode body", slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusBadRequest, models.ErrInvalidParams.Error())
+		return
+	}
+	defer r.Body.Close()
+
+	vars := mux.Vars(r)
+
+	offer, err := or.CounterOffer(ctx, requester, vars["id"], vars["offerID"], offerRequest.Amount)
+	if err != nil {
+		writeOfferError(log, w, err, "failed to counter offer")
+		return
+	}
+
+	writeOffer(log, w, http.StatusCreated, offer)
+}
+
+func writeOffer(log *slog.Logger, w http.ResponseWriter, status int, offer *models.Offer) {
+	response := map[string]any{
+		"offer": mapper.DtoFromOffer(offer),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Error("failed to write response", slog.String("error", err.Error()))
+	}
+}
+
+func writeOfferError(log *slog.Logger, w http.ResponseWriter, err error, msg string) {
+	switch {
+	case errors.Is(err, models.ErrInvalidPrice):
+		log.Warn("invalid offer amount", slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusBadRequest, models.ErrInvalidPrice.Error())
+	case errors.Is(err, models.ErrPostNotFound):
+		log.Warn("post not found", slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusNotFound, models.ErrPostNotFound.Error())
+	case errors.Is(err, models.ErrOfferNotFound):
+		log.Warn("offer not found", slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusNotFound, models.ErrOfferNotFound.Error())
+	case errors.Is(err, models.ErrOwnPost):
+		log.Warn("offer on own post", slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusForbidden, models.ErrOwnPost.Error())
+	case errors.Is(err, models.ErrForbidden):
+		log.Warn("offer is not addressed to requester", slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusForbidden, models.ErrForbidden.Error())
+	case errors.Is(err, models.ErrOfferExists):
+		log.Warn("offer already pending", slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusConflict, models.ErrOfferExists.Error())
+	case errors.Is(err, models.ErrOfferClosed):
+		log.Warn("offer is closed", slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusConflict, models.ErrOfferClosed.Error())
+	default:
+		log.Error(msg, slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+	}
+}
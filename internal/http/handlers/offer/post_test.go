@@ -0,0 +1,210 @@
+package offerhandler
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"marketplace/internal/models"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockOfferMaker struct {
+	mock.Mock
+}
+
+func (m *mockOfferMaker) MakeOffer(ctx context.Context, requester *models.User, postID string, amount int64) (*models.Offer, error) {
+	args := m.Called(ctx, requester, postID, amount)
+	return args.Get(0).(*models.Offer), args.Error(1)
+}
+
+type mockOfferResponder struct {
+	mock.Mock
+}
+
+func (m *mockOfferResponder) AcceptOffer(ctx context.Context, requester *models.User, postID string, offerID string) (*models.Offer, error) {
+	args := m.Called(ctx, requester, postID, offerID)
+	return args.Get(0).(*models.Offer), args.Error(1)
+}
+
+func (m *mockOfferResponder) RejectOffer(ctx context.Context, requester *models.User, postID string, offerID string) (*models.Offer, error) {
+	args := m.Called(ctx, requester, postID, offerID)
+	return args.Get(0).(*models.Offer), args.Error(1)
+}
+
+func (m *mockOfferResponder) CounterOffer(ctx context.Context, requester *models.User, postID string, offerID string, amount int64) (*models.Offer, error) {
+	args := m.Called(ctx, requester, postID, offerID, amount)
+	return args.Get(0).(*models.Offer), args.Error(1)
+}
+
+func TestAdd(t *testing.T) {
+	t.Parallel()
+
+	user := &models.User{ID: "buyer"}
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{name: "success", err: nil, wantStatus: http.StatusCreated},
+		{name: "invalid amount", err: models.ErrInvalidPrice, wantStatus: http.StatusBadRequest},
+		{name: "post not found", err: models.ErrPostNotFound, wantStatus: http.StatusNotFound},
+		{name: "own post", err: models.ErrOwnPost, wantStatus: http.StatusForbidden},
+		{name: "already pending", err: models.ErrOfferExists, wantStatus: http.StatusConflict},
+		{name: "internal", err: errors.New("db error"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			maker := new(mockOfferMaker)
+
+			var offer *models.Offer
+			if tt.err == nil {
+				offer = &models.Offer{ID: "o1", PostID: "p1", Amount: 900}
+			}
+
+			maker.On("MakeOffer", mock.Anything, user, "p1", int64(900)).Return(offer, tt.err)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/posts/p1/offers", strings.NewReader(`{"amount":900}`))
+			req = mux.SetURLVars(req, map[string]string{"id": "p1"})
+			ctx := context.WithValue(req.Context(), models.UserContextKey, user)
+			rr := httptest.NewRecorder()
+
+			Add(ctx, slog.New(slog.NewTextHandler(io.Discard, nil)), rr, req, maker)
+
+			assert.Equal(t, tt.wantStatus, rr.Code)
+			maker.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAdd_InvalidBody(t *testing.T) {
+	t.Parallel()
+
+	maker := new(mockOfferMaker)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/posts/p1/offers", strings.NewReader(`{`))
+	ctx := context.WithValue(req.Context(), models.UserContextKey, &models.User{ID: "buyer"})
+	rr := httptest.NewRecorder()
+
+	Add(ctx, slog.New(slog.NewTextHandler(io.Discard, nil)), rr, req, maker)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	maker.AssertNotCalled(t, "MakeOffer")
+}
+
+func TestAccept(t *testing.T) {
+	t.Parallel()
+
+	user := &models.User{ID: "seller"}
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{name: "success", err: nil, wantStatus: http.StatusOK},
+		{name: "offer not found", err: models.ErrOfferNotFound, wantStatus: http.StatusNotFound},
+		{name: "not recipient", err: models.ErrForbidden, wantStatus: http.StatusForbidden},
+		{name: "closed", err: models.ErrOfferClosed, wantStatus: http.StatusConflict},
+		{name: "internal", err: errors.New("db error"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			responder := new(mockOfferResponder)
+
+			var offer *models.Offer
+			if tt.err == nil {
+				offer = &models.Offer{ID: "o1", PostID: "p1", Status: models.OfferAccepted}
+			}
+
+			responder.On("AcceptOffer", mock.Anything, user, "p1", "o1").Return(offer, tt.err)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/posts/p1/offers/o1/accept", nil)
+			req = mux.SetURLVars(req, map[string]string{"id": "p1", "offerID": "o1"})
+			ctx := context.WithValue(req.Context(), models.UserContextKey, user)
+			rr := httptest.NewRecorder()
+
+			Accept(ctx, slog.New(slog.NewTextHandler(io.Discard, nil)), rr, req, responder)
+
+			assert.Equal(t, tt.wantStatus, rr.Code)
+			responder.AssertExpectations(t)
+		})
+	}
+}
+
+func TestReject_Success(t *testing.T) {
+	t.Parallel()
+
+	user := &models.User{ID: "seller"}
+
+	responder := new(mockOfferResponder)
+	responder.On("RejectOffer", mock.Anything, user, "p1", "o1").
+		Return(&models.Offer{ID: "o1", PostID: "p1", Status: models.OfferRejected}, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/posts/p1/offers/o1/reject", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "p1", "offerID": "o1"})
+	ctx := context.WithValue(req.Context(), models.UserContextKey, user)
+	rr := httptest.NewRecorder()
+
+	Reject(ctx, slog.New(slog.NewTextHandler(io.Discard, nil)), rr, req, responder)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"status":"rejected"`)
+	responder.AssertExpectations(t)
+}
+
+func TestCounter(t *testing.T) {
+	t.Parallel()
+
+	user := &models.User{ID: "seller"}
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{name: "success", err: nil, wantStatus: http.StatusCreated},
+		{name: "invalid amount", err: models.ErrInvalidPrice, wantStatus: http.StatusBadRequest},
+		{name: "not recipient", err: models.ErrForbidden, wantStatus: http.StatusForbidden},
+		{name: "closed", err: models.ErrOfferClosed, wantStatus: http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			responder := new(mockOfferResponder)
+
+			var offer *models.Offer
+			if tt.err == nil {
+				offer = &models.Offer{ID: "o2", PostID: "p1", PreviousID: "o1", Amount: 950}
+			}
+
+			responder.On("CounterOffer", mock.Anything, user, "p1", "o1", int64(950)).Return(offer, tt.err)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/posts/p1/offers/o1/counter", strings.NewReader(`{"amount":950}`))
+			req = mux.SetURLVars(req, map[string]string{"id": "p1", "offerID": "o1"})
+			ctx := context.WithValue(req.Context(), models.UserContextKey, user)
+			rr := httptest.NewRecorder()
+
+			Counter(ctx, slog.New(slog.NewTextHandler(io.Discard, nil)), rr, req, responder)
+
+			assert.Equal(t, tt.wantStatus, rr.Code)
+			responder.AssertExpectations(t)
+		})
+	}
+}
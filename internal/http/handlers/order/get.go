@@ -0,0 +1,87 @@
+package orderhandler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"marketplace/internal/models"
+	utils "marketplace/internal/utils/http_errors"
+	"marketplace/internal/utils/mapper"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+func Get(ctx context.Context, log *slog.Logger, w http.ResponseWriter, r *http.Request, orp OrderProvider) {
+	op := pkg + "Get"
+
+	log = log.With(slog.String("op", op))
+
+	requester, ok := ctx.Value(models.UserContextKey).(*models.User)
+	if !ok {
+		log.Error("failed to parse user from context")
+		utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+		return
+	}
+
+	id := mux.Vars(r)["id"]
+
+	order, err := orp.Order(ctx, requester, id)
+	if err != nil {
+		writeOrderError(log, w, err, "failed to get order")
+		return
+	}
+
+	response := map[string]any{
+		"data": map[string]any{
+			"order": mapper.DtoFromOrder(order),
+		},
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Error("failed to write response", slog.String("error", err.Error()))
+	}
+}
+
+func Mine(ctx context.Context, log *slog.Logger, w http.ResponseWriter, r *http.Request, orp OrderProvider) {
+	op := pkg + "Mine"
+
+	log = log.With(slog.String("op", op))
+
+	requester, ok := ctx.Value(models.UserContextKey).(*models.User)
+	if !ok {
+		log.Error("failed to parse user from context")
+		utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+		return
+	}
+
+	role := models.OrderRole(r.URL.Query().Get("role"))
+	limit := mapper.AtoiWithDefault(r.URL.Query().Get("limit"), 20)
+	offset := mapper.Atoi(r.URL.Query().Get("offset"))
+
+	orders, err := orp.MyOrders(ctx, requester, role, limit, offset)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrInvalidParams):
+			log.Warn("invalid order role", slog.String("error", err.Error()))
+			utils.WriteJSONError(w, http.StatusBadRequest, models.ErrInvalidParams.Error())
+		default:
+			log.Error("failed to list orders", slog.String("error", err.Error()))
+			utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+		}
+		return
+	}
+
+	response := map[string]any{
+		"data": map[string]any{
+			"orders": mapper.DtoFromOrders(orders),
+		},
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Error("failed to write response", slog.String("error", err.Error()))
+	}
+}
@@ -0,0 +1,104 @@
+package orderhandler
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"marketplace/internal/models"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockOrderProvider struct {
+	mock.Mock
+}
+
+func (m *mockOrderProvider) Order(ctx context.Context, requester *models.User, id string) (*models.Order, error) {
+	args := m.Called(ctx, requester, id)
+	return args.Get(0).(*models.Order), args.Error(1)
+}
+
+func (m *mockOrderProvider) MyOrders(ctx context.Context, requester *models.User, role models.OrderRole, limit int, offset int) ([]*models.Order, error) {
+	args := m.Called(ctx, requester, role, limit, offset)
+	return args.Get(0).([]*models.Order), args.Error(1)
+}
+
+func TestGet(t *testing.T) {
+	t.Parallel()
+
+	user := &models.User{ID: "buyer"}
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{name: "success", err: nil, wantStatus: http.StatusOK},
+		{name: "not found", err: models.ErrOrderNotFound, wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			provider := new(mockOrderProvider)
+
+			var order *models.Order
+			if tt.err == nil {
+				order = &models.Order{ID: "o1", Status: models.OrderPending}
+			}
+
+			provider.On("Order", mock.Anything, user, "o1").Return(order, tt.err)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/orders/o1", nil)
+			req = mux.SetURLVars(req, map[string]string{"id": "o1"})
+			ctx := context.WithValue(req.Context(), models.UserContextKey, user)
+			rr := httptest.NewRecorder()
+
+			Get(ctx, slog.New(slog.NewTextHandler(io.Discard, nil)), rr, req, provider)
+
+			assert.Equal(t, tt.wantStatus, rr.Code)
+			provider.AssertExpectations(t)
+		})
+	}
+}
+
+func TestMine(t *testing.T) {
+	t.Parallel()
+
+	user := &models.User{ID: "seller"}
+
+	tests := []struct {
+		name       string
+		query      string
+		role       models.OrderRole
+		err        error
+		wantStatus int
+	}{
+		{name: "seller", query: "?role=seller", role: models.OrderRoleSeller, wantStatus: http.StatusOK},
+		{name: "invalid role", query: "?role=admin", role: models.OrderRole("admin"), err: models.ErrInvalidParams, wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			provider := new(mockOrderProvider)
+			provider.On("MyOrders", mock.Anything, user, tt.role, 20, 0).Return([]*models.Order{}, tt.err)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/me/orders"+tt.query, nil)
+			ctx := context.WithValue(req.Context(), models.UserContextKey, user)
+			rr := httptest.NewRecorder()
+
+			Mine(ctx, slog.New(slog.NewTextHandler(io.Discard, nil)), rr, req, provider)
+
+			assert.Equal(t, tt.wantStatus, rr.Code)
+			provider.AssertExpectations(t)
+		})
+	}
+}
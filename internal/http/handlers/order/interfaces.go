@@ -0,0 +1,23 @@
+package orderhandler
+
+import (
+	"context"
+	"marketplace/internal/models"
+)
+
+const pkg = "orderHandler/"
+
+type OrderCreator interface {
+	CreateOrder(ctx context.Context, requester *models.User, postID string) (*models.Order, error)
+}
+
+type OrderProvider interface {
+	Order(ctx context.Context, requester *models.User, id string) (*models.Order, error)
+	MyOrders(ctx context.Context, requester *models.User, role models.OrderRole, limit int, offset int) ([]*models.Order, error)
+}
+
+type OrderUpdater interface {
+	PayOrder(ctx context.Context, requester *models.User, id string, paymentToken string) (*models.Order, error)
+	CompleteOrder(ctx context.Context, requester *models.User, id string) (*models.Order, error)
+	CancelOrder(ctx context.Context, requester *models.User, id string) (*models.Order, error)
+}
@@ -0,0 +1,160 @@
+package orderhandler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"marketplace/internal/dto"
+	"marketplace/internal/models"
+	utils "marketplace/internal/utils/http_errors"
+	"marketplace/internal/utils/mapper"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+func Add(ctx context.Context, log *slog.Logger, w http.ResponseWriter, r *http.Request, oc OrderCreator) {
+	op := pkg + "Add"
+
+	log = log.With(slog.String("op", op))
+
+	requester, ok := ctx.Value(models.UserContextKey).(*models.User)
+	if !ok {
+		log.Error("failed to parse user from context")
+		utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+		return
+	}
+
+	postID := mux.Vars(r)["id"]
+
+	order, err := oc.CreateOrder(ctx, requester, postID)
+	if err != nil {
+		writeOrderError(log, w, err, "failed to create order")
+		return
+	}
+
+	writeOrder(log, w, http.StatusCreated, order)
+}
+
+func Pay(ctx context.Context, log *slog.Logger, w http.ResponseWriter, r *http.Request, ou OrderUpdater) {
+	op := pkg + "Pay"
+
+	log = log.With(slog.String("op", op))
+
+	requester, ok := ctx.Value(models.UserContextKey).(*models.User)
+	if !ok {
+		log.Error("failed to parse user from context")
+		utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+		return
+	}
+
+	var paymentRequest dto.PaymentRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&paymentRequest); err != nil {
+		log.Warn("failed to decode body", slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusBadRequest, models.ErrInvalidParams.Error())
+		return
+	}
+	defer r.Body.Close()
+
+	id := mux.Vars(r)["id"]
+
+	order, err := ou.PayOrder(ctx, requester, id, paymentRequest.PaymentToken)
+	if err != nil {
+		writeOrderError(log, w, err, "failed to pay order")
+		return
+	}
+
+	writeOrder(log, w, http.StatusOK, order)
+}
+
+func Complete(ctx context.Context, log *slog.Logger, w http.ResponseWriter, r *http.Request, ou OrderUpdater) {
+	op := pkg + "Complete"
+
+	log = log.With(slog.String("op", op))
+
+	requester, ok := ctx.Value(models.UserContextKey).(*models.User)
+	if !ok {
+		log.Error("failed to parse user from context")
+		utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+		return
+	}
+
+	id := mux.Vars(r)["id"]
+
+	order, err := ou.CompleteOrder(ctx, requester, id)
+	if err != nil {
+		writeOrderError(log, w, err, "failed to complete order")
+		return
+	}
+
+	writeOrder(log, w, http.StatusOK, order)
+}
+
+func Cancel(ctx context.Context, log *slog.Logger, w http.ResponseWriter, r *http.Request, ou OrderUpdater) {
+	op := pkg + "Cancel"
+
+	log = log.With(slog.String("op", op))
+
+	requester, ok := ctx.Value(models.UserContextKey).(*models.User)
+	if !ok {
+		log.Error("failed to parse user from context")
+		utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+		return
+	}
+
+	id := mux.Vars(r)["id"]
+
+	order, err := ou.CancelOrder(ctx, requester, id)
+	if err != nil {
+		writeOrderError(log, w, err, "failed to cancel order")
+		return
+	}
+
+	writeOrder(log, w, http.StatusOK, order)
+}
+
+func writeOrder(log *slog.Logger, w http.ResponseWriter, status int, order *models.Order) {
+	response := map[string]any{
+		"order": mapper.DtoFromOrder(order),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Error("failed to write response", slog.String("error", err.Error()))
+	}
+}
+
+func writeOrderError(log *slog.Logger, w http.ResponseWriter, err error, msg string) {
+	switch {
+	case errors.Is(err, models.ErrPostNotFound):
+		log.Warn("post not found", slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusNotFound, models.ErrPostNotFound.Error())
+	case errors.Is(err, models.ErrOrderNotFound):
+		log.Warn("order not found", slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusNotFound, models.ErrOrderNotFound.Error())
+	case errors.Is(err, models.ErrOwnPost):
+		log.Warn("order on own post", slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusForbidden, models.ErrOwnPost.Error())
+	case errors.Is(err, models.ErrForbidden):
+		log.Warn("action is not allowed to requester", slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusForbidden, models.ErrForbidden.Error())
+	case errors.Is(err, models.ErrPaymentDeclined):
+		log.Warn("payment declined", slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusPaymentRequired, models.ErrPaymentDeclined.Error())
+	case errors.Is(err, models.ErrPostReserved):
+		log.Warn("post is already reserved", slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusConflict, models.ErrPostReserved.Error())
+	case errors.Is(err, models.ErrAuctionNotEnded):
+		log.Warn("auction has not ended", slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusConflict, models.ErrAuctionNotEnded.Error())
+	case errors.Is(err, models.ErrInvalidTransition):
+		log.Warn("invalid order transition", slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusConflict, models.ErrInvalidTransition.Error())
+	default:
+		log.Error(msg, slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+	}
+}
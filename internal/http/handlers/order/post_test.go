@@ -0,0 +1,187 @@
+package orderhandler
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"marketplace/internal/models"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockOrderCreator struct {
+	mock.Mock
+}
+
+func (m *mockOrderCreator) CreateOrder(ctx context.Context, requester *models.User, postID string) (*models.Order, error) {
+	args := m.Called(ctx, requester, postID)
+	return args.Get(0).(*models.Order), args.Error(1)
+}
+
+type mockOrderUpdater struct {
+	mock.Mock
+}
+
+func (m *mockOrderUpdater) PayOrder(ctx context.Context, requester *models.User, id string, paymentToken string) (*models.Order, error) {
+	args := m.Called(ctx, requester, id, paymentToken)
+	return args.Get(0).(*models.Order), args.Error(1)
+}
+
+func (m *mockOrderUpdater) CompleteOrder(ctx context.Context, requester *models.User, id string) (*models.Order, error) {
+	args := m.Called(ctx, requester, id)
+	return args.Get(0).(*models.Order), args.Error(1)
+}
+
+func (m *mockOrderUpdater) CancelOrder(ctx context.Context, requester *models.User, id string) (*models.Order, error) {
+	args := m.Called(ctx, requester, id)
+	return args.Get(0).(*models.Order), args.Error(1)
+}
+
+func TestAdd(t *testing.T) {
+	t.Parallel()
+
+	user := &models.User{ID: "buyer"}
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{name: "success", err: nil, wantStatus: http.StatusCreated},
+		{name: "post not found", err: models.ErrPostNotFound, wantStatus: http.StatusNotFound},
+		{name: "own post", err: models.ErrOwnPost, wantStatus: http.StatusForbidden},
+		{name: "lost auction", err: models.ErrForbidden, wantStatus: http.StatusForbidden},
+		{name: "reserved", err: models.ErrPostReserved, wantStatus: http.StatusConflict},
+		{name: "running auction", err: models.ErrAuctionNotEnded, wantStatus: http.StatusConflict},
+		{name: "internal", err: errors.New("db error"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			creator := new(mockOrderCreator)
+
+			var order *models.Order
+			if tt.err == nil {
+				order = &models.Order{ID: "o1", PostID: "p1", Status: models.OrderPending}
+			}
+
+			creator.On("CreateOrder", mock.Anything, user, "p1").Return(order, tt.err)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/posts/p1/orders", nil)
+			req = mux.SetURLVars(req, map[string]string{"id": "p1"})
+			ctx := context.WithValue(req.Context(), models.UserContextKey, user)
+			rr := httptest.NewRecorder()
+
+			Add(ctx, slog.New(slog.NewTextHandler(io.Discard, nil)), rr, req, creator)
+
+			assert.Equal(t, tt.wantStatus, rr.Code)
+			creator.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPay(t *testing.T) {
+	t.Parallel()
+
+	user := &models.User{ID: "buyer"}
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{name: "success", err: nil, wantStatus: http.StatusOK},
+		{name: "declined", err: models.ErrPaymentDeclined, wantStatus: http.StatusPaymentRequired},
+		{name: "not pending", err: models.ErrInvalidTransition, wantStatus: http.StatusConflict},
+		{name: "not buyer", err: models.ErrForbidden, wantStatus: http.StatusForbidden},
+		{name: "order not found", err: models.ErrOrderNotFound, wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			updater := new(mockOrderUpdater)
+
+			var order *models.Order
+			if tt.err == nil {
+				order = &models.Order{ID: "o1", Status: models.OrderConfirmed}
+			}
+
+			updater.On("PayOrder", mock.Anything, user, "o1", "tok_visa").Return(order, tt.err)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/orders/o1/pay", strings.NewReader(`{"payment_token":"tok_visa"}`))
+			req = mux.SetURLVars(req, map[string]string{"id": "o1"})
+			ctx := context.WithValue(req.Context(), models.UserContextKey, user)
+			rr := httptest.NewRecorder()
+
+			Pay(ctx, slog.New(slog.NewTextHandler(io.Discard, nil)), rr, req, updater)
+
+			assert.Equal(t, tt.wantStatus, rr.Code)
+			updater.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPay_InvalidBody(t *testing.T) {
+	t.Parallel()
+
+	updater := new(mockOrderUpdater)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/orders/o1/pay", strings.NewReader(`{`))
+	ctx := context.WithValue(req.Context(), models.UserContextKey, &models.User{ID: "buyer"})
+	rr := httptest.NewRecorder()
+
+	Pay(ctx, slog.New(slog.NewTextHandler(io.Discard, nil)), rr, req, updater)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	updater.AssertNotCalled(t, "PayOrder")
+}
+
+func TestComplete_Success(t *testing.T) {
+	t.Parallel()
+
+	user := &models.User{ID: "buyer"}
+
+	updater := new(mockOrderUpdater)
+	updater.On("CompleteOrder", mock.Anything, user, "o1").Return(&models.Order{ID: "o1", Status: models.OrderCompleted}, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/orders/o1/complete", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "o1"})
+	ctx := context.WithValue(req.Context(), models.UserContextKey, user)
+	rr := httptest.NewRecorder()
+
+	Complete(ctx, slog.New(slog.NewTextHandler(io.Discard, nil)), rr, req, updater)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"status":"completed"`)
+	updater.AssertExpectations(t)
+}
+
+func TestCancel_Completed(t *testing.T) {
+	t.Parallel()
+
+	user := &models.User{ID: "seller"}
+
+	updater := new(mockOrderUpdater)
+	updater.On("CancelOrder", mock.Anything, user, "o1").Return((*models.Order)(nil), models.ErrInvalidTransition)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/orders/o1/cancel", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "o1"})
+	ctx := context.WithValue(req.Context(), models.UserContextKey, user)
+	rr := httptest.NewRecorder()
+
+	Cancel(ctx, slog.New(slog.NewTextHandler(io.Discard, nil)), rr, req, updater)
+
+	assert.Equal(t, http.StatusConflict, rr.Code)
+	updater.AssertExpectations(t)
+}
@@ -0,0 +1,17 @@
+package passwordhandler
+
+import (
+	"context"
+	"marketplace/internal/models"
+)
+
+const pkg = "passwordHandler/"
+
+type PasswordChanger interface {
+	ChangePassword(ctx context.Context, requester *models.User, token string, current string, newPassword string, client *models.ClientInfo) error
+}
+
+type PasswordResetter interface {
+	RequestReset(ctx context.Context, login string, client *models.ClientInfo) error
+	ResetPassword(ctx context.Context, token string, newPassword string) error
+}
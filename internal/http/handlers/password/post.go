@@ -0,0 +1,125 @@
+package passwordhandler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"marketplace/internal/dto"
+	"marketplace/internal/models"
+	"marketplace/internal/utils/clientinfo"
+	utils "marketplace/internal/utils/http_errors"
+	"net/http"
+)
+
+func Change(ctx context.Context, log *slog.Logger, w http.ResponseWriter, r *http.Request, pc PasswordChanger) {
+	op := pkg + "Change"
+
+	log = log.With(slog.String("op", op))
+
+	requester, ok := ctx.Value(models.UserContextKey).(*models.User)
+	if !ok {
+		log.Error("failed to parse user from context")
+		utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+		return
+	}
+
+	token, ok := ctx.Value(models.TokenContextKey).(string)
+	if !ok {
+		log.Error("failed to parse token from context")
+		utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+		return
+	}
+
+	var changeRequest dto.PasswordChangeRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&changeRequest); err != nil {
+		log.Warn("failed to decode body", slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusBadRequest, models.ErrInvalidParams.Error())
+		return
+	}
+	defer r.Body.Close()
+
+	err := pc.ChangePassword(ctx, requester, token, changeRequest.CurrentPassword, changeRequest.NewPassword, clientinfo.FromRequest(r))
+	if err != nil {
+		writePasswordError(log, w, err, "failed to change password")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Reset always answers 202 for a well-formed request, whether the login
+// exists or not. Only the mail limit per login and per client IP turns a
+// request away with 429.
+func Reset(ctx context.Context, log *slog.Logger, w http.ResponseWriter, r *http.Request, pr PasswordResetter) {
+	op := pkg + "Reset"
+
+	log = log.With(slog.String("op", op))
+
+	var resetRequest dto.PasswordResetRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&resetRequest); err != nil || resetRequest.Login == "" {
+		log.Warn("invalid reset request")
+		utils.WriteJSONError(w, http.StatusBadRequest, models.ErrInvalidParams.Error())
+		return
+	}
+	defer r.Body.Close()
+
+	if err := pr.RequestReset(ctx, resetRequest.Login, clientinfo.FromRequest(r)); err != nil {
+		writePasswordError(log, w, err, "failed to request password reset")
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func ConfirmReset(ctx context.Context, log *slog.Logger, w http.ResponseWriter, r *http.Request, pr PasswordResetter) {
+	op := pkg + "ConfirmReset"
+
+	log = log.With(slog.String("op", op))
+
+	var confirmRequest dto.PasswordResetConfirmRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&confirmRequest); err != nil || confirmRequest.Token == "" {
+		log.Warn("invalid reset confirm request")
+		utils.WriteJSONError(w, http.StatusBadRequest, models.ErrInvalidParams.Error())
+		return
+	}
+	defer r.Body.Close()
+
+	if err := pr.ResetPassword(ctx, confirmRequest.Token, confirmRequest.NewPassword); err != nil {
+		writePasswordError(log, w, err, "failed to reset password")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writePasswordError(log *slog.Logger, w http.ResponseWriter, err error, msg string) {
+	var retry *models.RetryAfterError
+
+	switch {
+	case errors.As(err, &retry):
+		log.Warn(msg, slog.String("error", err.Error()))
+		utils.WriteRetryAfter(w, retry)
+	case errors.Is(err, models.ErrInvalidPassword):
+		log.Warn(msg, slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, models.ErrInvalidResetToken):
+		log.Warn(msg, slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, models.ErrInvalidCredentials):
+		log.Warn(msg, slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, models.ErrUserNotFound):
+		log.Warn(msg, slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, models.ErrTooManyRequests):
+		log.Warn(msg, slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusTooManyRequests, err.Error())
+	default:
+		log.Error(msg, slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+	}
+}
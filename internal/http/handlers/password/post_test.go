@@ -0,0 +1,136 @@
+package passwordhandler
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"marketplace/internal/models"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockPasswordChanger struct {
+	mock.Mock
+}
+
+func (m *mockPasswordChanger) ChangePassword(ctx context.Context, requester *models.User, token string, current string, newPassword string, client *models.ClientInfo) error {
+	args := m.Called(ctx, requester, token, current, newPassword, client)
+	return args.Error(0)
+}
+
+type mockPasswordResetter struct {
+	mock.Mock
+}
+
+func (m *mockPasswordResetter) RequestReset(ctx context.Context, login string, client *models.ClientInfo) error {
+	args := m.Called(ctx, login, client)
+	return args.Error(0)
+}
+
+func (m *mockPasswordResetter) ResetPassword(ctx context.Context, token string, newPassword string) error {
+	args := m.Called(ctx, token, newPassword)
+	return args.Error(0)
+}
+
+func TestChange(t *testing.T) {
+	t.Parallel()
+
+	user := &models.User{ID: "1", Login: "user1"}
+
+	tests := []struct {
+		name       string
+		body       string
+		serviceErr error
+		wantStatus int
+	}{
+		{name: "success", body: `{"current_password":"old","new_password":"new"}`, wantStatus: http.StatusNoContent},
+		{name: "wrong current password", body: `{"current_password":"old","new_password":"new"}`, serviceErr: models.ErrInvalidCredentials, wantStatus: http.StatusForbidden},
+		{name: "too many attempts", body: `{"current_password":"old","new_password":"new"}`, serviceErr: &models.RetryAfterError{After: time.Minute, Err: models.ErrLoginBlocked}, wantStatus: http.StatusTooManyRequests},
+		{name: "weak new password", body: `{"current_password":"old","new_password":"new"}`, serviceErr: models.ErrInvalidPassword, wantStatus: http.StatusBadRequest},
+		{name: "invalid json", body: `{`, wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			changer := new(mockPasswordChanger)
+			changer.On("ChangePassword", mock.Anything, user, "token", "old", "new", &models.ClientInfo{IP: "192.0.2.1"}).Return(tt.serviceErr)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/me/password", strings.NewReader(tt.body))
+			ctx := context.WithValue(req.Context(), models.UserContextKey, user)
+			ctx = context.WithValue(ctx, models.TokenContextKey, "token")
+			w := httptest.NewRecorder()
+
+			Change(ctx, slog.New(slog.NewTextHandler(io.Discard, nil)), w, req, changer)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
+
+func TestReset_UnknownLoginAccepted(t *testing.T) {
+	t.Parallel()
+
+	resetter := new(mockPasswordResetter)
+	resetter.On("RequestReset", mock.Anything, "ghost", &models.ClientInfo{IP: "192.0.2.1"}).Return(nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/password/reset", strings.NewReader(`{"login":"ghost"}`))
+	w := httptest.NewRecorder()
+
+	Reset(req.Context(), slog.New(slog.NewTextHandler(io.Discard, nil)), w, req, resetter)
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	resetter.AssertExpectations(t)
+}
+
+func TestReset_LimitReached(t *testing.T) {
+	t.Parallel()
+
+	resetter := new(mockPasswordResetter)
+	resetter.On("RequestReset", mock.Anything, "user1", mock.Anything).Return(models.ErrTooManyRequests)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/password/reset", strings.NewReader(`{"login":"user1"}`))
+	w := httptest.NewRecorder()
+
+	Reset(req.Context(), slog.New(slog.NewTextHandler(io.Discard, nil)), w, req, resetter)
+
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+}
+
+func TestConfirmReset(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		body       string
+		serviceErr error
+		wantStatus int
+	}{
+		{name: "success", body: `{"token":"tok","new_password":"new"}`, wantStatus: http.StatusNoContent},
+		{name: "invalid token", body: `{"token":"tok","new_password":"new"}`, serviceErr: models.ErrInvalidResetToken, wantStatus: http.StatusBadRequest},
+		{name: "missing token", body: `{"new_password":"new"}`, wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			resetter := new(mockPasswordResetter)
+			resetter.On("ResetPassword", mock.Anything, "tok", "new").Return(tt.serviceErr)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/password/reset/confirm", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+
+			ConfirmReset(req.Context(), slog.New(slog.NewTextHandler(io.Discard, nil)), w, req, resetter)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
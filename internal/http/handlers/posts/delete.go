@@ -0,0 +1,55 @@
+package postshandler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"marketplace/internal/models"
+	utils "marketplace/internal/utils/http_errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+func Delete(ctx context.Context, log *slog.Logger, w http.ResponseWriter, r *http.Request, pr PostRemover) {
+	op := pkg + "Delete"
+
+	log = log.With(slog.String("op", op))
+
+	requester, ok := ctx.Value(models.UserContextKey).(*models.User)
+	if !ok {
+		log.Error("failed to parse user from context")
+		utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+		return
+	}
+
+	id := mux.Vars(r)["id"]
+
+	err := pr.DeletePost(ctx, requester, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrPostNotFound):
+			log.Warn("post not found", slog.String("error", err.Error()))
+			utils.WriteJSONError(w, http.StatusNotFound, models.ErrPostNotFound.Error())
+		case errors.Is(err, models.ErrForbidden):
+			log.Warn("post belongs to another user", slog.String("error", err.Error()))
+			utils.WriteJSONError(w, http.StatusForbidden, models.ErrForbidden.Error())
+		default:
+			log.Error("failed to delete post", slog.String("error", err.Error()))
+			utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+		}
+		return
+	}
+
+	response := map[string]any{
+		"response": map[string]any{
+			id: true,
+		},
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Error("failed to write response", slog.String("error", err.Error()))
+	}
+}
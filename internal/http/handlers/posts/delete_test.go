@@ -0,0 +1,62 @@
+package postshandler
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"marketplace/internal/models"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockPostRemover struct {
+	mock.Mock
+}
+
+func (m *mockPostRemover) DeletePost(ctx context.Context, requester *models.User, id string) error {
+	args := m.Called(ctx, requester, id)
+	return args.Error(0)
+}
+
+func TestDelete(t *testing.T) {
+	t.Parallel()
+
+	user := &models.User{ID: "user1"}
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{name: "success", err: nil, wantStatus: http.StatusOK},
+		{name: "not found", err: models.ErrPostNotFound, wantStatus: http.StatusNotFound},
+		{name: "forbidden", err: models.ErrForbidden, wantStatus: http.StatusForbidden},
+		{name: "internal", err: errors.New("db error"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			remover := new(mockPostRemover)
+			remover.On("DeletePost", mock.Anything, user, "post1").Return(tt.err)
+
+			req := httptest.NewRequest(http.MethodDelete, "/api/posts/post1", nil)
+			req = mux.SetURLVars(req, map[string]string{"id": "post1"})
+
+			ctx := context.WithValue(req.Context(), models.UserContextKey, user)
+			rr := httptest.NewRecorder()
+
+			Delete(ctx, slog.New(slog.NewTextHandler(io.Discard, nil)), rr, req, remover)
+
+			assert.Equal(t, tt.wantStatus, rr.Code)
+			remover.AssertExpectations(t)
+		})
+	}
+}
@@ -15,3 +15,11 @@ type PostAdder interface {
 type PostProvider interface {
 	FilteredPosts(ctx context.Context, limit int, offset int, filter *models.PostsFilter, requester *models.User) ([]*models.PostWithDocument, error)
 }
+
+type PostUpdater interface {
+	UpdatePost(ctx context.Context, requester *models.User, id string, update *models.PostUpdate) (*models.PostWithDocument, error)
+}
+
+type PostRemover interface {
+	DeletePost(ctx context.Context, requester *models.User, id string) error
+}
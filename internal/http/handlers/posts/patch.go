@@ -0,0 +1,69 @@
+package postshandler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"marketplace/internal/dto"
+	"marketplace/internal/models"
+	utils "marketplace/internal/utils/http_errors"
+	"marketplace/internal/utils/mapper"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// Update changes the header, text or price of a post. Fields left out of the
+// body are kept.
+func Update(ctx context.Context, log *slog.Logger, w http.ResponseWriter, r *http.Request, pu PostUpdater) {
+	op := pkg + "Update"
+
+	log = log.With(slog.String("op", op))
+
+	requester, ok := ctx.Value(models.UserContextKey).(*models.User)
+	if !ok {
+		log.Error("failed to parse user from context")
+		utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+		return
+	}
+
+	var updateRequest dto.PostUpdateRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&updateRequest); err != nil {
+		log.Warn("failed to decode body", slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusBadRequest, models.ErrInvalidParams.Error())
+		return
+	}
+	defer r.Body.Close()
+
+	id := mux.Vars(r)["id"]
+
+	post, err := pu.UpdatePost(ctx, requester, id, mapper.PostUpdateFromDto(&updateRequest))
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrInvalidHeader), errors.Is(err, models.ErrInvalidText), errors.Is(err, models.ErrInvalidPrice):
+			log.Warn("invalid post recieved", slog.String("error", err.Error()))
+			utils.WriteJSONError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, models.ErrPostNotFound):
+			log.Warn("post not found", slog.String("error", err.Error()))
+			utils.WriteJSONError(w, http.StatusNotFound, models.ErrPostNotFound.Error())
+		case errors.Is(err, models.ErrForbidden):
+			log.Warn("post belongs to another user", slog.String("error", err.Error()))
+			utils.WriteJSONError(w, http.StatusForbidden, models.ErrForbidden.Error())
+		default:
+			log.Error("failed to update post", slog.String("error", err.Error()))
+			utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+		}
+		return
+	}
+
+	response := map[string]any{
+		"post": mapper.DtoFromPost(post),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Error("failed to write response", slog.String("error", err.Error()))
+	}
+}
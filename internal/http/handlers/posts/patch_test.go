@@ -0,0 +1,86 @@
+package postshandler
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"marketplace/internal/models"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockPostUpdater struct {
+	mock.Mock
+}
+
+func (m *mockPostUpdater) UpdatePost(ctx context.Context, requester *models.User, id string, update *models.PostUpdate) (*models.PostWithDocument, error) {
+	args := m.Called(ctx, requester, id, update)
+	return args.Get(0).(*models.PostWithDocument), args.Error(1)
+}
+
+func TestUpdate(t *testing.T) {
+	t.Parallel()
+
+	user := &models.User{ID: "user1"}
+	header := "new header"
+
+	tests := []struct {
+		name       string
+		post       *models.PostWithDocument
+		err        error
+		wantStatus int
+	}{
+		{name: "success", post: &models.PostWithDocument{Header: header, Text: "some text", Price: 100}, wantStatus: http.StatusOK},
+		{name: "invalid", err: models.ErrInvalidHeader, wantStatus: http.StatusBadRequest},
+		{name: "not found", err: models.ErrPostNotFound, wantStatus: http.StatusNotFound},
+		{name: "forbidden", err: models.ErrForbidden, wantStatus: http.StatusForbidden},
+		{name: "internal", err: errors.New("db error"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			updater := new(mockPostUpdater)
+			updater.On("UpdatePost", mock.Anything, user, "post1", &models.PostUpdate{Header: &header}).Return(tt.post, tt.err)
+
+			req := httptest.NewRequest(http.MethodPatch, "/api/posts/post1", strings.NewReader(`{"header":"new header"}`))
+			req = mux.SetURLVars(req, map[string]string{"id": "post1"})
+
+			ctx := context.WithValue(req.Context(), models.UserContextKey, user)
+			rr := httptest.NewRecorder()
+
+			Update(ctx, slog.New(slog.NewTextHandler(io.Discard, nil)), rr, req, updater)
+
+			assert.Equal(t, tt.wantStatus, rr.Code)
+			if tt.post != nil {
+				assert.Contains(t, rr.Body.String(), `"header":"new header"`)
+			}
+			updater.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUpdate_InvalidBody(t *testing.T) {
+	t.Parallel()
+
+	updater := new(mockPostUpdater)
+
+	req := httptest.NewRequest(http.MethodPatch, "/api/posts/post1", strings.NewReader(`{`))
+	req = mux.SetURLVars(req, map[string]string{"id": "post1"})
+
+	ctx := context.WithValue(req.Context(), models.UserContextKey, &models.User{ID: "user1"})
+	rr := httptest.NewRecorder()
+
+	Update(ctx, slog.New(slog.NewTextHandler(io.Discard, nil)), rr, req, updater)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	updater.AssertNotCalled(t, "UpdatePost")
+}
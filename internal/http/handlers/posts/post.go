@@ -92,7 +92,7 @@ func Add(ctx context.Context, log *slog.Logger, w http.ResponseWriter, r *http.R
 
 	_, err = pa.AddPost(ctx, requester, &post, file)
 	if err != nil {
-		if errors.Is(err, models.ErrInvalidHeader) || errors.Is(err, models.ErrInvalidText) || errors.Is(err, models.ErrInvalidPrice) {
+		if errors.Is(err, models.ErrInvalidHeader) || errors.Is(err, models.ErrInvalidText) || errors.Is(err, models.ErrInvalidPrice) || errors.Is(err, models.ErrInvalidAuction) {
 			log.Warn("invalid post recieved", slog.String("error", err.Error()))
 			utils.WriteJSONError(w, http.StatusBadRequest, err.Error())
 		}
@@ -0,0 +1,78 @@
+package profilehandler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"marketplace/internal/models"
+	utils "marketplace/internal/utils/http_errors"
+	"marketplace/internal/utils/mapper"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+func Get(ctx context.Context, log *slog.Logger, w http.ResponseWriter, r *http.Request, pp ProfileProvider) {
+	op := pkg + "Get"
+
+	log = log.With(slog.String("op", op))
+
+	login := mux.Vars(r)["login"]
+
+	profile, err := pp.Profile(ctx, login)
+	if err != nil {
+		writeProfileError(log, w, err, "failed to get profile")
+		return
+	}
+
+	writeProfile(log, w, profile)
+}
+
+func Me(ctx context.Context, log *slog.Logger, w http.ResponseWriter, r *http.Request, pp ProfileProvider) {
+	op := pkg + "Me"
+
+	log = log.With(slog.String("op", op))
+
+	requester, ok := ctx.Value(models.UserContextKey).(*models.User)
+	if !ok {
+		log.Error("failed to parse user from context")
+		utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+		return
+	}
+
+	profile, err := pp.MyProfile(ctx, requester)
+	if err != nil {
+		writeProfileError(log, w, err, "failed to get own profile")
+		return
+	}
+
+	writeProfile(log, w, profile)
+}
+
+func writeProfile(log *slog.Logger, w http.ResponseWriter, profile *models.Profile) {
+	response := map[string]any{
+		"data": map[string]any{
+			"profile": mapper.DtoFromProfile(profile),
+		},
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Error("failed to write response", slog.String("error", err.Error()))
+	}
+}
+
+func writeProfileError(log *slog.Logger, w http.ResponseWriter, err error, msg string) {
+	switch {
+	case errors.Is(err, models.ErrInvalidText):
+		log.Warn("invalid profile received", slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, models.ErrUserNotFound):
+		log.Warn("user not found", slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusNotFound, models.ErrUserNotFound.Error())
+	default:
+		log.Error(msg, slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+	}
+}
@@ -0,0 +1,91 @@
+package profilehandler
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"marketplace/internal/models"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockProfileProvider struct {
+	mock.Mock
+}
+
+func (m *mockProfileProvider) Profile(ctx context.Context, login string) (*models.Profile, error) {
+	args := m.Called(ctx, login)
+	return args.Get(0).(*models.Profile), args.Error(1)
+}
+
+func (m *mockProfileProvider) MyProfile(ctx context.Context, requester *models.User) (*models.Profile, error) {
+	args := m.Called(ctx, requester)
+	return args.Get(0).(*models.Profile), args.Error(1)
+}
+
+func TestGet(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{name: "success", err: nil, wantStatus: http.StatusOK},
+		{name: "not found", err: models.ErrUserNotFound, wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			provider := new(mockProfileProvider)
+
+			var profile *models.Profile
+			if tt.err == nil {
+				profile = &models.Profile{UserID: "1", Login: "seller", DisplayName: "Seller", RegisteredAt: time.Now(), PostCount: 2}
+			}
+
+			provider.On("Profile", mock.Anything, "seller").Return(profile, tt.err)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/users/seller", nil)
+			req = mux.SetURLVars(req, map[string]string{"login": "seller"})
+			rr := httptest.NewRecorder()
+
+			Get(req.Context(), slog.New(slog.NewTextHandler(io.Discard, nil)), rr, req, provider)
+
+			assert.Equal(t, tt.wantStatus, rr.Code)
+			if tt.err == nil {
+				assert.Contains(t, rr.Body.String(), `"post_count":2`)
+				assert.NotContains(t, rr.Body.String(), `"user_id"`)
+			}
+			provider.AssertExpectations(t)
+		})
+	}
+}
+
+func TestMe_Success(t *testing.T) {
+	t.Parallel()
+
+	user := &models.User{ID: "1", Login: "seller", PassHash: []byte("hashed")}
+
+	provider := new(mockProfileProvider)
+	provider.On("MyProfile", mock.Anything, user).Return(&models.Profile{UserID: "1", Login: "seller"}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/me", nil)
+	ctx := context.WithValue(req.Context(), models.UserContextKey, user)
+	rr := httptest.NewRecorder()
+
+	Me(ctx, slog.New(slog.NewTextHandler(io.Discard, nil)), rr, req, provider)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"login":"seller"`)
+	assert.NotContains(t, rr.Body.String(), "hashed")
+	provider.AssertExpectations(t)
+}
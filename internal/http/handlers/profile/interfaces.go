@@ -0,0 +1,18 @@
+package profilehandler
+
+import (
+	"context"
+	"io"
+	"marketplace/internal/models"
+)
+
+const pkg = "profileHandler/"
+
+type ProfileProvider interface {
+	Profile(ctx context.Context, login string) (*models.Profile, error)
+	MyProfile(ctx context.Context, requester *models.User) (*models.Profile, error)
+}
+
+type ProfileUpdater interface {
+	UpdateProfile(ctx context.Context, requester *models.User, update *models.ProfileUpdate, avatar io.Reader) (*models.Profile, error)
+}
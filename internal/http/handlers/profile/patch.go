@@ -0,0 +1,106 @@
+package profilehandler
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"marketplace/internal/dto"
+	"marketplace/internal/models"
+	utils "marketplace/internal/utils/http_errors"
+	"marketplace/internal/utils/mapper"
+	"mime"
+	"net/http"
+)
+
+const maxAvatarSize = 2 << 20
+
+// Update accepts either a JSON body with the profile fields, or a multipart
+// form with an optional "profile" JSON part and an optional "avatar" file.
+func Update(ctx context.Context, log *slog.Logger, w http.ResponseWriter, r *http.Request, pu ProfileUpdater) {
+	op := pkg + "Update"
+
+	log = log.With(slog.String("op", op))
+
+	requester, ok := ctx.Value(models.UserContextKey).(*models.User)
+	if !ok {
+		log.Error("failed to parse user from context")
+		utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+		return
+	}
+
+	var updateRequest dto.ProfileUpdateRequest
+	var avatar io.Reader
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+
+	if mediaType == "multipart/form-data" {
+		r.Body = http.MaxBytesReader(w, r.Body, maxAvatarSize+(1<<20))
+
+		if err := r.ParseMultipartForm(maxAvatarSize); err != nil {
+			log.Warn("failed to parse multipart form", slog.String("error", err.Error()))
+			utils.WriteJSONError(w, http.StatusBadRequest, "failed to parse multipart form")
+			return
+		}
+
+		if profilePart := r.FormValue("profile"); profilePart != "" {
+			if err := json.Unmarshal([]byte(profilePart), &updateRequest); err != nil {
+				log.Warn("failed to unmarshal profile", slog.String("error", err.Error()))
+				utils.WriteJSONError(w, http.StatusBadRequest, models.ErrInvalidParams.Error())
+				return
+			}
+		}
+
+		file, _, err := r.FormFile("avatar")
+		if err != nil && err != http.ErrMissingFile {
+			log.Warn("failed to parse avatar", slog.String("error", err.Error()))
+			utils.WriteJSONError(w, http.StatusBadRequest, "failed upload error")
+			return
+		}
+
+		if file != nil {
+			defer file.Close()
+
+			buf := make([]byte, 512)
+			n, err := file.Read(buf)
+			if err != nil && err != io.EOF {
+				utils.WriteJSONError(w, http.StatusInternalServerError, "file read error")
+				return
+			}
+
+			if contentType := http.DetectContentType(buf[:n]); contentType != "image/jpeg" {
+				utils.WriteJSONError(w, http.StatusUnsupportedMediaType, "only image/jpeg allowed")
+				return
+			}
+
+			if _, err := file.Seek(0, io.SeekStart); err != nil {
+				utils.WriteJSONError(w, http.StatusInternalServerError, "file seek error")
+				return
+			}
+
+			avatar = file
+		}
+	} else {
+		if err := json.NewDecoder(r.Body).Decode(&updateRequest); err != nil {
+			log.Warn("failed to decode body", slog.String("error", err.Error()))
+			utils.WriteJSONError(w, http.StatusBadRequest, models.ErrInvalidParams.Error())
+			return
+		}
+		defer r.Body.Close()
+	}
+
+	profile, err := pu.UpdateProfile(ctx, requester, mapper.ProfileUpdateFromDto(&updateRequest), avatar)
+	if err != nil {
+		writeProfileError(log, w, err, "failed to update profile")
+		return
+	}
+
+	response := map[string]any{
+		"profile": mapper.DtoFromProfile(profile),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Error("failed to write response", slog.String("error", err.Error()))
+	}
+}
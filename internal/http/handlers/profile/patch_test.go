@@ -0,0 +1,122 @@
+package profilehandler
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"marketplace/internal/models"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockProfileUpdater struct {
+	mock.Mock
+}
+
+func (m *mockProfileUpdater) UpdateProfile(ctx context.Context, requester *models.User, update *models.ProfileUpdate, avatar io.Reader) (*models.Profile, error) {
+	args := m.Called(ctx, requester, update, avatar)
+	return args.Get(0).(*models.Profile), args.Error(1)
+}
+
+func jpegBytes() []byte {
+	return append([]byte{0xFF, 0xD8, 0xFF, 0xE0}, bytes.Repeat([]byte{0}, 16)...)
+}
+
+func TestUpdate_JSON(t *testing.T) {
+	t.Parallel()
+
+	user := &models.User{ID: "1"}
+	name := "Seller"
+
+	updater := new(mockProfileUpdater)
+	updater.On("UpdateProfile", mock.Anything, user, &models.ProfileUpdate{DisplayName: &name}, nil).
+		Return(&models.Profile{UserID: "1", Login: "seller", DisplayName: "Seller"}, nil)
+
+	req := httptest.NewRequest(http.MethodPatch, "/api/me", strings.NewReader(`{"display_name":"Seller"}`))
+	req.Header.Set("Content-Type", "application/json")
+	ctx := context.WithValue(req.Context(), models.UserContextKey, user)
+	rr := httptest.NewRecorder()
+
+	Update(ctx, slog.New(slog.NewTextHandler(io.Discard, nil)), rr, req, updater)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"display_name":"Seller"`)
+	updater.AssertExpectations(t)
+}
+
+func TestUpdate_InvalidText(t *testing.T) {
+	t.Parallel()
+
+	user := &models.User{ID: "1"}
+
+	updater := new(mockProfileUpdater)
+	updater.On("UpdateProfile", mock.Anything, user, mock.Anything, nil).Return((*models.Profile)(nil), models.ErrInvalidText)
+
+	req := httptest.NewRequest(http.MethodPatch, "/api/me", strings.NewReader(`{"bio":"too long"}`))
+	ctx := context.WithValue(req.Context(), models.UserContextKey, user)
+	rr := httptest.NewRecorder()
+
+	Update(ctx, slog.New(slog.NewTextHandler(io.Discard, nil)), rr, req, updater)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestUpdate_MultipartAvatar(t *testing.T) {
+	t.Parallel()
+
+	user := &models.User{ID: "1"}
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	_ = writer.WriteField("profile", `{"bio":"hello"}`)
+	part, _ := writer.CreateFormFile("avatar", "me.jpg")
+	_, _ = part.Write(jpegBytes())
+	_ = writer.Close()
+
+	updater := new(mockProfileUpdater)
+	updater.On("UpdateProfile", mock.Anything, user, mock.MatchedBy(func(u *models.ProfileUpdate) bool {
+		return u.Bio != nil && *u.Bio == "hello" && u.DisplayName == nil
+	}), mock.MatchedBy(func(r io.Reader) bool {
+		return r != nil
+	})).Return(&models.Profile{UserID: "1", Bio: "hello", AvatarPath: "/static/a.jpg"}, nil)
+
+	req := httptest.NewRequest(http.MethodPatch, "/api/me", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	ctx := context.WithValue(req.Context(), models.UserContextKey, user)
+	rr := httptest.NewRecorder()
+
+	Update(ctx, slog.New(slog.NewTextHandler(io.Discard, nil)), rr, req, updater)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"avatar_path":"/static/a.jpg"`)
+	updater.AssertExpectations(t)
+}
+
+func TestUpdate_AvatarNotJPEG(t *testing.T) {
+	t.Parallel()
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, _ := writer.CreateFormFile("avatar", "me.png")
+	_, _ = part.Write([]byte("\x89PNG\r\n\x1a\n"))
+	_ = writer.Close()
+
+	updater := new(mockProfileUpdater)
+
+	req := httptest.NewRequest(http.MethodPatch, "/api/me", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	ctx := context.WithValue(req.Context(), models.UserContextKey, &models.User{ID: "1"})
+	rr := httptest.NewRecorder()
+
+	Update(ctx, slog.New(slog.NewTextHandler(io.Discard, nil)), rr, req, updater)
+
+	assert.Equal(t, http.StatusUnsupportedMediaType, rr.Code)
+	updater.AssertNotCalled(t, "UpdateProfile")
+}
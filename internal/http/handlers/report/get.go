@@ -0,0 +1,45 @@
+package reporthandler
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"marketplace/internal/models"
+	utils "marketplace/internal/utils/http_errors"
+	"marketplace/internal/utils/mapper"
+	"net/http"
+)
+
+// Queue returns the open reports for moderators.
+func Queue(ctx context.Context, log *slog.Logger, w http.ResponseWriter, r *http.Request, rm ReportModerator) {
+	op := pkg + "Queue"
+
+	log = log.With(slog.String("op", op))
+
+	requester, ok := ctx.Value(models.UserContextKey).(*models.User)
+	if !ok {
+		log.Error("failed to parse user from context")
+		utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+		return
+	}
+
+	limit := mapper.AtoiWithDefault(r.URL.Query().Get("limit"), 20)
+	offset := mapper.Atoi(r.URL.Query().Get("offset"))
+
+	reports, err := rm.OpenReports(ctx, requester, limit, offset)
+	if err != nil {
+		writeError(log, w, err, "failed to get open reports")
+		return
+	}
+
+	response := map[string]any{
+		"data": map[string]any{
+			"reports": mapper.DtoFromReports(reports),
+		},
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Error("failed to write response", slog.String("error", err.Error()))
+	}
+}
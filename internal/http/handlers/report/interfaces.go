@@ -0,0 +1,18 @@
+package reporthandler
+
+import (
+	"context"
+	"marketplace/internal/models"
+)
+
+const pkg = "reportHandler/"
+
+type ReportAdder interface {
+	Report(ctx context.Context, requester *models.User, postID string, reason models.ReportReason, text string) (*models.Report, error)
+}
+
+type ReportModerator interface {
+	OpenReports(ctx context.Context, requester *models.User, limit int, offset int) ([]*models.Report, error)
+	Resolve(ctx context.Context, requester *models.User, id string) (*models.Report, error)
+	Dismiss(ctx context.Context, requester *models.User, id string) (*models.Report, error)
+}
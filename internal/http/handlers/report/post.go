@@ -0,0 +1,121 @@
+package reporthandler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"marketplace/internal/dto"
+	"marketplace/internal/models"
+	utils "marketplace/internal/utils/http_errors"
+	"marketplace/internal/utils/mapper"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+func Add(ctx context.Context, log *slog.Logger, w http.ResponseWriter, r *http.Request, ra ReportAdder) {
+	op := pkg + "Add"
+
+	log = log.With(slog.String("op", op))
+
+	requester, ok := ctx.Value(models.UserContextKey).(*models.User)
+	if !ok {
+		log.Error("failed to parse user from context")
+		utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+		return
+	}
+
+	var reportRequest dto.ReportRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&reportRequest); err != nil {
+		log.Warn("failed to decode body", slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusBadRequest, models.ErrInvalidParams.Error())
+		return
+	}
+	defer r.Body.Close()
+
+	postID := mux.Vars(r)["id"]
+
+	report, err := ra.Report(ctx, requester, postID, models.ReportReason(reportRequest.Reason), reportRequest.Text)
+	if err != nil {
+		writeError(log, w, err, "failed to report post")
+		return
+	}
+
+	writeReport(log, w, http.StatusCreated, report)
+}
+
+func Resolve(ctx context.Context, log *slog.Logger, w http.ResponseWriter, r *http.Request, rm ReportModerator) {
+	op := pkg + "Resolve"
+
+	log = log.With(slog.String("op", op))
+
+	requester, ok := ctx.Value(models.UserContextKey).(*models.User)
+	if !ok {
+		log.Error("failed to parse user from context")
+		utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+		return
+	}
+
+	report, err := rm.Resolve(ctx, requester, mux.Vars(r)["id"])
+	if err != nil {
+		writeError(log, w, err, "failed to resolve report")
+		return
+	}
+
+	writeReport(log, w, http.StatusOK, report)
+}
+
+func Dismiss(ctx context.Context, log *slog.Logger, w http.ResponseWriter, r *http.Request, rm ReportModerator) {
+	op := pkg + "Dismiss"
+
+	log = log.With(slog.String("op", op))
+
+	requester, ok := ctx.Value(models.UserContextKey).(*models.User)
+	if !ok {
+		log.Error("failed to parse user from context")
+		utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+		return
+	}
+
+	report, err := rm.Dismiss(ctx, requester, mux.Vars(r)["id"])
+	if err != nil {
+		writeError(log, w, err, "failed to dismiss report")
+		return
+	}
+
+	writeReport(log, w, http.StatusOK, report)
+}
+
+func writeReport(log *slog.Logger, w http.ResponseWriter, status int, report *models.Report) {
+	response := map[string]any{
+		"report": mapper.DtoFromReport(report),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Error("failed to write response", slog.String("error", err.Error()))
+	}
+}
+
+func writeError(log *slog.Logger, w http.ResponseWriter, err error, msg string) {
+	switch {
+	case errors.Is(err, models.ErrInvalidReportReason), errors.Is(err, models.ErrInvalidText):
+		log.Warn(msg, slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, models.ErrPostNotFound), errors.Is(err, models.ErrReportNotFound):
+		log.Warn(msg, slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, models.ErrOwnPost), errors.Is(err, models.ErrForbidden):
+		log.Warn(msg, slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, models.ErrReportExists), errors.Is(err, models.ErrReportClosed):
+		log.Warn(msg, slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusConflict, err.Error())
+	default:
+		log.Error(msg, slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+	}
+}
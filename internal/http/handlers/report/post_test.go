@@ -0,0 +1,159 @@
+package reporthandler
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"marketplace/internal/models"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockReportService struct {
+	mock.Mock
+}
+
+func (m *mockReportService) Report(ctx context.Context, requester *models.User, postID string, reason models.ReportReason, text string) (*models.Report, error) {
+	args := m.Called(ctx, requester, postID, reason, text)
+	report, _ := args.Get(0).(*models.Report)
+	return report, args.Error(1)
+}
+
+func (m *mockReportService) OpenReports(ctx context.Context, requester *models.User, limit int, offset int) ([]*models.Report, error) {
+	args := m.Called(ctx, requester, limit, offset)
+	reports, _ := args.Get(0).([]*models.Report)
+	return reports, args.Error(1)
+}
+
+func (m *mockReportService) Resolve(ctx context.Context, requester *models.User, id string) (*models.Report, error) {
+	args := m.Called(ctx, requester, id)
+	report, _ := args.Get(0).(*models.Report)
+	return report, args.Error(1)
+}
+
+func (m *mockReportService) Dismiss(ctx context.Context, requester *models.User, id string) (*models.Report, error) {
+	args := m.Called(ctx, requester, id)
+	report, _ := args.Get(0).(*models.Report)
+	return report, args.Error(1)
+}
+
+var user = &models.User{ID: "u1", Login: "buyer"}
+
+func newRequest(method, target, body string, vars map[string]string) *http.Request {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req = req.WithContext(context.WithValue(req.Context(), models.UserContextKey, user))
+	return mux.SetURLVars(req, vars)
+}
+
+func TestAdd(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		body       string
+		report     *models.Report
+		serviceErr error
+		wantStatus int
+	}{
+		{name: "created", body: `{"reason":"scam","text":"fake"}`, report: &models.Report{ID: "r1", PostID: "p1", Reason: models.ReportScam, Status: models.ReportOpen}, wantStatus: http.StatusCreated},
+		{name: "invalid reason", body: `{"reason":"scam","text":"fake"}`, serviceErr: models.ErrInvalidReportReason, wantStatus: http.StatusBadRequest},
+		{name: "own post", body: `{"reason":"scam","text":"fake"}`, serviceErr: models.ErrOwnPost, wantStatus: http.StatusForbidden},
+		{name: "already reported", body: `{"reason":"scam","text":"fake"}`, serviceErr: models.ErrReportExists, wantStatus: http.StatusConflict},
+		{name: "post not found", body: `{"reason":"scam","text":"fake"}`, serviceErr: models.ErrPostNotFound, wantStatus: http.StatusNotFound},
+		{name: "invalid json", body: `{`, wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			service := new(mockReportService)
+			service.On("Report", mock.Anything, user, "p1", models.ReportScam, "fake").Return(tt.report, tt.serviceErr)
+
+			req := newRequest(http.MethodPost, "/api/posts/p1/reports", tt.body, map[string]string{"id": "p1"})
+			w := httptest.NewRecorder()
+
+			Add(req.Context(), slog.New(slog.NewTextHandler(io.Discard, nil)), w, req, service)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+
+			if tt.report != nil {
+				var result map[string]map[string]any
+				assert.NoError(t, json.NewDecoder(w.Body).Decode(&result))
+				assert.Equal(t, "r1", result["report"]["id"])
+				assert.Equal(t, "open", result["report"]["status"])
+			}
+		})
+	}
+}
+
+func TestResolveDismiss(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		dismiss    bool
+		serviceErr error
+		wantStatus int
+	}{
+		{name: "resolve", wantStatus: http.StatusOK},
+		{name: "dismiss", dismiss: true, wantStatus: http.StatusOK},
+		{name: "not a moderator", serviceErr: models.ErrForbidden, wantStatus: http.StatusForbidden},
+		{name: "already closed", dismiss: true, serviceErr: models.ErrReportClosed, wantStatus: http.StatusConflict},
+		{name: "not found", serviceErr: models.ErrReportNotFound, wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var report *models.Report
+			if tt.serviceErr == nil {
+				report = &models.Report{ID: "r1", PostID: "p1", Status: models.ReportResolved}
+			}
+
+			service := new(mockReportService)
+			service.On("Resolve", mock.Anything, user, "r1").Return(report, tt.serviceErr)
+			service.On("Dismiss", mock.Anything, user, "r1").Return(report, tt.serviceErr)
+
+			req := newRequest(http.MethodPost, "/api/admin/reports/r1", "", map[string]string{"id": "r1"})
+			w := httptest.NewRecorder()
+
+			log := slog.New(slog.NewTextHandler(io.Discard, nil))
+			if tt.dismiss {
+				Dismiss(req.Context(), log, w, req, service)
+			} else {
+				Resolve(req.Context(), log, w, req, service)
+			}
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
+
+func TestQueue(t *testing.T) {
+	t.Parallel()
+
+	service := new(mockReportService)
+	service.On("OpenReports", mock.Anything, user, 20, 0).
+		Return([]*models.Report{{ID: "r1", PostID: "p1", PostHidden: true, Reason: models.ReportScam, Status: models.ReportOpen}}, nil)
+
+	req := newRequest(http.MethodGet, "/api/admin/reports", "", nil)
+	w := httptest.NewRecorder()
+
+	Queue(req.Context(), slog.New(slog.NewTextHandler(io.Discard, nil)), w, req, service)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var result map[string]map[string][]map[string]any
+	assert.NoError(t, json.NewDecoder(w.Body).Decode(&result))
+	assert.Len(t, result["data"]["reports"], 1)
+	assert.Equal(t, true, result["data"]["reports"][0]["post_hidden"])
+}
@@ -0,0 +1,82 @@
+package reviewhandler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"marketplace/internal/models"
+	utils "marketplace/internal/utils/http_errors"
+	"marketplace/internal/utils/mapper"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+func Get(ctx context.Context, log *slog.Logger, w http.ResponseWriter, r *http.Request, rp ReviewProvider) {
+	op := pkg + "Get"
+
+	log = log.With(slog.String("op", op))
+
+	login := mux.Vars(r)["login"]
+	limit := mapper.AtoiWithDefault(r.URL.Query().Get("limit"), 20)
+	offset := mapper.Atoi(r.URL.Query().Get("offset"))
+
+	reviews, rating, err := rp.Reviews(ctx, login, limit, offset)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrUserNotFound):
+			log.Warn("seller not found", slog.String("error", err.Error()))
+			utils.WriteJSONError(w, http.StatusNotFound, models.ErrUserNotFound.Error())
+		default:
+			log.Error("failed to get reviews", slog.String("error", err.Error()))
+			utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+		}
+		return
+	}
+
+	response := map[string]any{
+		"data": map[string]any{
+			"rating":  mapper.DtoFromRating(rating),
+			"reviews": mapper.DtoFromReviews(reviews),
+		},
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Error("failed to write response", slog.String("error", err.Error()))
+	}
+}
+
+func Pending(ctx context.Context, log *slog.Logger, w http.ResponseWriter, r *http.Request, rm ReplyModerator) {
+	op := pkg + "Pending"
+
+	log = log.With(slog.String("op", op))
+
+	requester, ok := ctx.Value(models.UserContextKey).(*models.User)
+	if !ok {
+		log.Error("failed to parse user from context")
+		utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+		return
+	}
+
+	limit := mapper.AtoiWithDefault(r.URL.Query().Get("limit"), 20)
+	offset := mapper.Atoi(r.URL.Query().Get("offset"))
+
+	reviews, err := rm.PendingReplies(ctx, requester, limit, offset)
+	if err != nil {
+		writeReviewError(log, w, err, "failed to get pending replies")
+		return
+	}
+
+	response := map[string]any{
+		"data": map[string]any{
+			"reviews": mapper.DtoFromPendingReplies(reviews),
+		},
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Error("failed to write response", slog.String("error", err.Error()))
+	}
+}
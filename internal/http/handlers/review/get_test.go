@@ -0,0 +1,78 @@
+package reviewhandler
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"marketplace/internal/models"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockReviewProvider struct {
+	mock.Mock
+}
+
+func (m *mockReviewProvider) Reviews(ctx context.Context, login string, limit int, offset int) ([]*models.Review, models.Rating, error) {
+	args := m.Called(ctx, login, limit, offset)
+	return args.Get(0).([]*models.Review), args.Get(1).(models.Rating), args.Error(2)
+}
+
+func TestGet_HidesPendingReplies(t *testing.T) {
+	t.Parallel()
+
+	provider := new(mockReviewProvider)
+	provider.On("Reviews", mock.Anything, "seller", 20, 0).Return([]*models.Review{
+		{ID: "r1", Rating: 5, Reply: "approved reply", ReplyStatus: models.ReplyApproved},
+		{ID: "r2", Rating: 4, Reply: "pending reply", ReplyStatus: models.ReplyPending},
+	}, models.Rating{Count: 2, Sum: 9}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/users/seller/reviews", nil)
+	req = mux.SetURLVars(req, map[string]string{"login": "seller"})
+	rr := httptest.NewRecorder()
+
+	Get(req.Context(), slog.New(slog.NewTextHandler(io.Discard, nil)), rr, req, provider)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"rating":{"average":4.5,"count":2}`)
+	assert.Contains(t, rr.Body.String(), "approved reply")
+	assert.NotContains(t, rr.Body.String(), "pending reply")
+	provider.AssertExpectations(t)
+}
+
+func TestGet_UnknownSeller(t *testing.T) {
+	t.Parallel()
+
+	provider := new(mockReviewProvider)
+	provider.On("Reviews", mock.Anything, "ghost", 20, 0).Return([]*models.Review(nil), models.Rating{}, models.ErrUserNotFound)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/users/ghost/reviews", nil)
+	req = mux.SetURLVars(req, map[string]string{"login": "ghost"})
+	rr := httptest.NewRecorder()
+
+	Get(req.Context(), slog.New(slog.NewTextHandler(io.Discard, nil)), rr, req, provider)
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
+
+func TestPending_NotModerator(t *testing.T) {
+	t.Parallel()
+
+	user := &models.User{Login: "seller"}
+
+	moderator := new(mockReplyModerator)
+	moderator.On("PendingReplies", mock.Anything, user, 20, 0).Return([]*models.Review(nil), models.ErrForbidden)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/reviews/replies/pending", nil)
+	ctx := context.WithValue(req.Context(), models.UserContextKey, user)
+	rr := httptest.NewRecorder()
+
+	Pending(ctx, slog.New(slog.NewTextHandler(io.Discard, nil)), rr, req, moderator)
+
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+}
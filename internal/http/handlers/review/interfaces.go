@@ -0,0 +1,26 @@
+package reviewhandler
+
+import (
+	"context"
+	"marketplace/internal/models"
+)
+
+const pkg = "reviewHandler/"
+
+type ReviewAdder interface {
+	AddReview(ctx context.Context, requester *models.User, postID string, rating int, text string) (*models.Review, error)
+}
+
+type ReviewProvider interface {
+	Reviews(ctx context.Context, login string, limit int, offset int) ([]*models.Review, models.Rating, error)
+}
+
+type ReviewReplier interface {
+	Reply(ctx context.Context, requester *models.User, id string, text string) (*models.Review, error)
+}
+
+type ReplyModerator interface {
+	PendingReplies(ctx context.Context, requester *models.User, limit int, offset int) ([]*models.Review, error)
+	ApproveReply(ctx context.Context, requester *models.User, id string) (*models.Review, error)
+	RejectReply(ctx context.Context, requester *models.User, id string) (*models.Review, error)
+}
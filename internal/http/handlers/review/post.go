@@ -0,0 +1,169 @@
+package reviewhandler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"marketplace/internal/dto"
+	"marketplace/internal/models"
+	utils "marketplace/internal/utils/http_errors"
+	"marketplace/internal/utils/mapper"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+func Add(ctx context.Context, log *slog.Logger, w http.ResponseWriter, r *http.Request, ra ReviewAdder) {
+	op := pkg + "Add"
+
+	log = log.With(slog.String("op", op))
+
+	requester, ok := ctx.Value(models.UserContextKey).(*models.User)
+	if !ok {
+		log.Error("failed to parse user from context")
+		utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+		return
+	}
+
+	var reviewRequest dto.ReviewRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&reviewRequest); err != nil {
+		log.Warn("failed to decode body", slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusBadRequest, models.ErrInvalidParams.Error())
+		return
+	}
+	defer r.Body.Close()
+
+	postID := mux.Vars(r)["id"]
+
+	review, err := ra.AddReview(ctx, requester, postID, reviewRequest.Rating, reviewRequest.Text)
+	if err != nil {
+		writeReviewError(log, w, err, "failed to add review")
+		return
+	}
+
+	writeReview(log, w, http.StatusCreated, review)
+}
+
+func Reply(ctx context.Context, log *slog.Logger, w http.ResponseWriter, r *http.Request, rr ReviewReplier) {
+	op := pkg + "Reply"
+
+	log = log.With(slog.String("op", op))
+
+	requester, ok := ctx.Value(models.UserContextKey).(*models.User)
+	if !ok {
+		log.Error("failed to parse user from context")
+		utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+		return
+	}
+
+	var replyRequest dto.ReviewReplyRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&replyRequest); err != nil {
+		log.Warn("failed to decode body", slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusBadRequest, models.ErrInvalidParams.Error())
+		return
+	}
+	defer r.Body.Close()
+
+	id := mux.Vars(r)["id"]
+
+	review, err := rr.Reply(ctx, requester, id, replyRequest.Text)
+	if err != nil {
+		writeReviewError(log, w, err, "failed to reply to review")
+		return
+	}
+
+	writeReview(log, w, http.StatusOK, review)
+}
+
+func Approve(ctx context.Context, log *slog.Logger, w http.ResponseWriter, r *http.Request, rm ReplyModerator) {
+	op := pkg + "Approve"
+
+	log = log.With(slog.String("op", op))
+
+	requester, ok := ctx.Value(models.UserContextKey).(*models.User)
+	if !ok {
+		log.Error("failed to parse user from context")
+		utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+		return
+	}
+
+	id := mux.Vars(r)["id"]
+
+	review, err := rm.ApproveReply(ctx, requester, id)
+	if err != nil {
+		writeReviewError(log, w, err, "failed to approve reply")
+		return
+	}
+
+	writeReview(log, w, http.StatusOK, review)
+}
+
+func Reject(ctx context.Context, log *slog.Logger, w http.ResponseWriter, r *http.Request, rm ReplyModerator) {
+	op := pkg + "Reject"
+
+	log = log.With(slog.String("op", op))
+
+	requester, ok := ctx.Value(models.UserContextKey).(*models.User)
+	if !ok {
+		log.Error("failed to parse user from context")
+		utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+		return
+	}
+
+	id := mux.Vars(r)["id"]
+
+	review, err := rm.RejectReply(ctx, requester, id)
+	if err != nil {
+		writeReviewError(log, w, err, "failed to reject reply")
+		return
+	}
+
+	writeReview(log, w, http.StatusOK, review)
+}
+
+func writeReview(log *slog.Logger, w http.ResponseWriter, status int, review *models.Review) {
+	response := map[string]any{
+		"review": mapper.DtoFromReview(review),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Error("failed to write response", slog.String("error", err.Error()))
+	}
+}
+
+func writeReviewError(log *slog.Logger, w http.ResponseWriter, err error, msg string) {
+	switch {
+	case errors.Is(err, models.ErrInvalidRating), errors.Is(err, models.ErrInvalidText):
+		log.Warn("invalid review received", slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, models.ErrPostNotFound):
+		log.Warn("post not found", slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusNotFound, models.ErrPostNotFound.Error())
+	case errors.Is(err, models.ErrReviewNotFound):
+		log.Warn("review not found", slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusNotFound, models.ErrReviewNotFound.Error())
+	case errors.Is(err, models.ErrOwnPost):
+		log.Warn("review on own post", slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusForbidden, models.ErrOwnPost.Error())
+	case errors.Is(err, models.ErrNotPurchased):
+		log.Warn("review without completed order", slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusForbidden, models.ErrNotPurchased.Error())
+	case errors.Is(err, models.ErrForbidden):
+		log.Warn("action is not allowed to requester", slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusForbidden, models.ErrForbidden.Error())
+	case errors.Is(err, models.ErrReviewExists):
+		log.Warn("post already reviewed", slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusConflict, models.ErrReviewExists.Error())
+	case errors.Is(err, models.ErrNoPendingReply):
+		log.Warn("reply is not pending", slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusConflict, models.ErrNoPendingReply.Error())
+	default:
+		log.Error(msg, slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+	}
+}
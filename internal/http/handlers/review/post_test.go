@@ -0,0 +1,176 @@
+package reviewhandler
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"marketplace/internal/models"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockReviewAdder struct {
+	mock.Mock
+}
+
+func (m *mockReviewAdder) AddReview(ctx context.Context, requester *models.User, postID string, rating int, text string) (*models.Review, error) {
+	args := m.Called(ctx, requester, postID, rating, text)
+	return args.Get(0).(*models.Review), args.Error(1)
+}
+
+type mockReviewReplier struct {
+	mock.Mock
+}
+
+func (m *mockReviewReplier) Reply(ctx context.Context, requester *models.User, id string, text string) (*models.Review, error) {
+	args := m.Called(ctx, requester, id, text)
+	return args.Get(0).(*models.Review), args.Error(1)
+}
+
+type mockReplyModerator struct {
+	mock.Mock
+}
+
+func (m *mockReplyModerator) PendingReplies(ctx context.Context, requester *models.User, limit int, offset int) ([]*models.Review, error) {
+	args := m.Called(ctx, requester, limit, offset)
+	return args.Get(0).([]*models.Review), args.Error(1)
+}
+
+func (m *mockReplyModerator) ApproveReply(ctx context.Context, requester *models.User, id string) (*models.Review, error) {
+	args := m.Called(ctx, requester, id)
+	return args.Get(0).(*models.Review), args.Error(1)
+}
+
+func (m *mockReplyModerator) RejectReply(ctx context.Context, requester *models.User, id string) (*models.Review, error) {
+	args := m.Called(ctx, requester, id)
+	return args.Get(0).(*models.Review), args.Error(1)
+}
+
+func TestAdd(t *testing.T) {
+	t.Parallel()
+
+	user := &models.User{ID: "buyer"}
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{name: "success", err: nil, wantStatus: http.StatusCreated},
+		{name: "invalid rating", err: models.ErrInvalidRating, wantStatus: http.StatusBadRequest},
+		{name: "post not found", err: models.ErrPostNotFound, wantStatus: http.StatusNotFound},
+		{name: "own post", err: models.ErrOwnPost, wantStatus: http.StatusForbidden},
+		{name: "not purchased", err: models.ErrNotPurchased, wantStatus: http.StatusForbidden},
+		{name: "already reviewed", err: models.ErrReviewExists, wantStatus: http.StatusConflict},
+		{name: "internal", err: errors.New("db error"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			adder := new(mockReviewAdder)
+
+			var review *models.Review
+			if tt.err == nil {
+				review = &models.Review{ID: "r1", PostID: "p1", Rating: 5}
+			}
+
+			adder.On("AddReview", mock.Anything, user, "p1", 5, "great").Return(review, tt.err)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/posts/p1/reviews", strings.NewReader(`{"rating":5,"text":"great"}`))
+			req = mux.SetURLVars(req, map[string]string{"id": "p1"})
+			ctx := context.WithValue(req.Context(), models.UserContextKey, user)
+			rr := httptest.NewRecorder()
+
+			Add(ctx, slog.New(slog.NewTextHandler(io.Discard, nil)), rr, req, adder)
+
+			assert.Equal(t, tt.wantStatus, rr.Code)
+			adder.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAdd_InvalidBody(t *testing.T) {
+	t.Parallel()
+
+	adder := new(mockReviewAdder)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/posts/p1/reviews", strings.NewReader(`{"rating":"five"}`))
+	ctx := context.WithValue(req.Context(), models.UserContextKey, &models.User{ID: "buyer"})
+	rr := httptest.NewRecorder()
+
+	Add(ctx, slog.New(slog.NewTextHandler(io.Discard, nil)), rr, req, adder)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	adder.AssertNotCalled(t, "AddReview")
+}
+
+func TestReply_ShowsPendingStatus(t *testing.T) {
+	t.Parallel()
+
+	user := &models.User{ID: "seller"}
+
+	replier := new(mockReviewReplier)
+	replier.On("Reply", mock.Anything, user, "r1", "thanks").Return(&models.Review{ID: "r1", Reply: "thanks", ReplyStatus: models.ReplyPending}, nil)
+
+	req := httptest.NewRequest(http.MethodPut, "/api/reviews/r1/reply", strings.NewReader(`{"text":"thanks"}`))
+	req = mux.SetURLVars(req, map[string]string{"id": "r1"})
+	ctx := context.WithValue(req.Context(), models.UserContextKey, user)
+	rr := httptest.NewRecorder()
+
+	Reply(ctx, slog.New(slog.NewTextHandler(io.Discard, nil)), rr, req, replier)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"reply_status":"pending"`)
+	replier.AssertExpectations(t)
+}
+
+func TestApprove(t *testing.T) {
+	t.Parallel()
+
+	user := &models.User{Login: "moderator"}
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{name: "success", err: nil, wantStatus: http.StatusOK},
+		{name: "not moderator", err: models.ErrForbidden, wantStatus: http.StatusForbidden},
+		{name: "not pending", err: models.ErrNoPendingReply, wantStatus: http.StatusConflict},
+		{name: "not found", err: models.ErrReviewNotFound, wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			moderator := new(mockReplyModerator)
+
+			var review *models.Review
+			if tt.err == nil {
+				review = &models.Review{ID: "r1", Reply: "thanks", ReplyStatus: models.ReplyApproved}
+			}
+
+			moderator.On("ApproveReply", mock.Anything, user, "r1").Return(review, tt.err)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/reviews/r1/reply/approve", nil)
+			req = mux.SetURLVars(req, map[string]string{"id": "r1"})
+			ctx := context.WithValue(req.Context(), models.UserContextKey, user)
+			rr := httptest.NewRecorder()
+
+			Approve(ctx, slog.New(slog.NewTextHandler(io.Discard, nil)), rr, req, moderator)
+
+			assert.Equal(t, tt.wantStatus, rr.Code)
+			moderator.AssertExpectations(t)
+		})
+	}
+}
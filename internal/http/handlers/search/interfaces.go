@@ -0,0 +1,30 @@
+package searchhandler
+
+import (
+	"context"
+	"marketplace/internal/models"
+)
+
+const pkg = "searchHandler/"
+
+type SearchAdder interface {
+	AddSearch(ctx context.Context, requester *models.User, filter *models.PostsFilter) (*models.SavedSearch, error)
+}
+
+type SearchProvider interface {
+	Searches(ctx context.Context, requester *models.User) ([]*models.SavedSearch, error)
+	Search(ctx context.Context, requester *models.User, id string) (*models.SavedSearch, error)
+}
+
+type SearchUpdater interface {
+	UpdateSearch(ctx context.Context, requester *models.User, id string, filter *models.PostsFilter) (*models.SavedSearch, error)
+}
+
+type SearchRemover interface {
+	DeleteSearch(ctx context.Context, requester *models.User, id string) error
+}
+
+type MatchProvider interface {
+	Matches(ctx context.Context, requester *models.User, id string, limit int) ([]*models.SearchMatch, error)
+	MarkSeen(ctx context.Context, requester *models.User, id string, postIDs []string) error
+}
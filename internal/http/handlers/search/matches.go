@@ -0,0 +1,104 @@
+package searchhandler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"marketplace/internal/dto"
+	"marketplace/internal/models"
+	utils "marketplace/internal/utils/http_errors"
+	"marketplace/internal/utils/mapper"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+func Matches(ctx context.Context, log *slog.Logger, w http.ResponseWriter, r *http.Request, mp MatchProvider) {
+	op := pkg + "Matches"
+
+	log = log.With(slog.String("op", op))
+
+	requester, ok := ctx.Value(models.UserContextKey).(*models.User)
+	if !ok {
+		log.Error("failed to parse user from context")
+		utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+		return
+	}
+
+	limit := mapper.AtoiWithDefault(r.URL.Query().Get("limit"), 50)
+
+	matches, err := mp.Matches(ctx, requester, mux.Vars(r)["id"], limit)
+	if err != nil {
+		if errors.Is(err, models.ErrSearchNotFound) {
+			log.Warn("saved search not found", slog.String("error", err.Error()))
+			utils.WriteJSONError(w, http.StatusNotFound, models.ErrSearchNotFound.Error())
+			return
+		}
+		log.Error("failed to list matches", slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+		return
+	}
+
+	response := map[string]any{
+		"data": map[string]any{
+			"matches": mapper.DtoFromMatches(matches),
+		},
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Error("failed to write response", slog.String("error", err.Error()))
+	}
+}
+
+func MarkSeen(ctx context.Context, log *slog.Logger, w http.ResponseWriter, r *http.Request, mp MatchProvider) {
+	op := pkg + "MarkSeen"
+
+	log = log.With(slog.String("op", op))
+
+	requester, ok := ctx.Value(models.UserContextKey).(*models.User)
+	if !ok {
+		log.Error("failed to parse user from context")
+		utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+		return
+	}
+
+	var seenRequest dto.MarkSeenRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&seenRequest); err != nil && !errors.Is(err, io.EOF) {
+		log.Warn("failed to decode body", slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusBadRequest, models.ErrInvalidParams.Error())
+		return
+	}
+	defer r.Body.Close()
+
+	err := mp.MarkSeen(ctx, requester, mux.Vars(r)["id"], seenRequest.PostIDs)
+	if err != nil {
+		if errors.Is(err, models.ErrSearchNotFound) {
+			log.Warn("saved search not found", slog.String("error", err.Error()))
+			utils.WriteJSONError(w, http.StatusNotFound, models.ErrSearchNotFound.Error())
+			return
+		}
+		if errors.Is(err, models.ErrInvalidParams) {
+			log.Warn("invalid post ids received", slog.String("error", err.Error()))
+			utils.WriteJSONError(w, http.StatusBadRequest, models.ErrInvalidParams.Error())
+			return
+		}
+		log.Error("failed to mark matches as seen", slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+		return
+	}
+
+	response := map[string]any{
+		"response": map[string]any{
+			"seen": true,
+		},
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Error("failed to write response", slog.String("error", err.Error()))
+	}
+}
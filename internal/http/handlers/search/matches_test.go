@@ -0,0 +1,142 @@
+package searchhandler
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"marketplace/internal/models"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockMatchProvider struct {
+	mock.Mock
+}
+
+func (m *mockMatchProvider) Matches(ctx context.Context, requester *models.User, id string, limit int) ([]*models.SearchMatch, error) {
+	args := m.Called(ctx, requester, id, limit)
+	return args.Get(0).([]*models.SearchMatch), args.Error(1)
+}
+
+func (m *mockMatchProvider) MarkSeen(ctx context.Context, requester *models.User, id string, postIDs []string) error {
+	args := m.Called(ctx, requester, id, postIDs)
+	return args.Error(0)
+}
+
+func TestMatches_Success(t *testing.T) {
+	t.Parallel()
+
+	mp := new(mockMatchProvider)
+	user := &models.User{ID: "1"}
+
+	matches := []*models.SearchMatch{
+		{SearchID: "s1", Post: &models.PostWithDocument{ID: "p1", Header: "header"}, MatchedAt: time.Now()},
+	}
+
+	mp.On("Matches", mock.Anything, user, "s1", 50).Return(matches, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/me/searches/s1/matches", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "s1"})
+	ctx := context.WithValue(req.Context(), models.UserContextKey, user)
+	rr := httptest.NewRecorder()
+
+	Matches(ctx, slog.New(slog.NewTextHandler(io.Discard, nil)), rr, req, mp)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var resp map[string]map[string][]map[string]any
+	err := json.NewDecoder(rr.Body).Decode(&resp)
+	assert.NoError(t, err)
+	assert.Len(t, resp["data"]["matches"], 1)
+	assert.Equal(t, "p1", resp["data"]["matches"][0]["post_id"])
+
+	mp.AssertExpectations(t)
+}
+
+func TestMatches_NotFound(t *testing.T) {
+	t.Parallel()
+
+	mp := new(mockMatchProvider)
+	user := &models.User{ID: "1"}
+
+	mp.On("Matches", mock.Anything, user, "s1", 5).Return(([]*models.SearchMatch)(nil), models.ErrSearchNotFound)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/me/searches/s1/matches?limit=5", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "s1"})
+	ctx := context.WithValue(req.Context(), models.UserContextKey, user)
+	rr := httptest.NewRecorder()
+
+	Matches(ctx, slog.New(slog.NewTextHandler(io.Discard, nil)), rr, req, mp)
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	mp.AssertExpectations(t)
+}
+
+func TestMarkSeen_EmptyBody(t *testing.T) {
+	t.Parallel()
+
+	mp := new(mockMatchProvider)
+	user := &models.User{ID: "1"}
+
+	mp.On("MarkSeen", mock.Anything, user, "s1", ([]string)(nil)).Return(nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/me/searches/s1/matches/seen", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "s1"})
+	ctx := context.WithValue(req.Context(), models.UserContextKey, user)
+	rr := httptest.NewRecorder()
+
+	MarkSeen(ctx, slog.New(slog.NewTextHandler(io.Discard, nil)), rr, req, mp)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	mp.AssertExpectations(t)
+}
+
+func TestMarkSeen_SelectedPosts(t *testing.T) {
+	t.Parallel()
+
+	mp := new(mockMatchProvider)
+	user := &models.User{ID: "1"}
+
+	mp.On("MarkSeen", mock.Anything, user, "s1", []string{"p1", "p2"}).Return(nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/me/searches/s1/matches/seen", strings.NewReader(`{"post_ids": ["p1", "p2"]}`))
+	req = mux.SetURLVars(req, map[string]string{"id": "s1"})
+	ctx := context.WithValue(req.Context(), models.UserContextKey, user)
+	rr := httptest.NewRecorder()
+
+	MarkSeen(ctx, slog.New(slog.NewTextHandler(io.Discard, nil)), rr, req, mp)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	mp.AssertExpectations(t)
+}
+
+func TestMarkSeen_InvalidParams(t *testing.T) {
+	t.Parallel()
+
+	mp := new(mockMatchProvider)
+	user := &models.User{ID: "1"}
+
+	mp.On("MarkSeen", mock.Anything, user, "s1", []string{"bad"}).Return(models.ErrInvalidParams)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/me/searches/s1/matches/seen", strings.NewReader(`{"post_ids": ["bad"]}`))
+	req = mux.SetURLVars(req, map[string]string{"id": "s1"})
+	ctx := context.WithValue(req.Context(), models.UserContextKey, user)
+	rr := httptest.NewRecorder()
+
+	MarkSeen(ctx, slog.New(slog.NewTextHandler(io.Discard, nil)), rr, req, mp)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	mp.AssertExpectations(t)
+}
@@ -0,0 +1,124 @@
+package searchhandler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"marketplace/internal/models"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockSearchAdder struct {
+	mock.Mock
+}
+
+func (m *mockSearchAdder) AddSearch(ctx context.Context, requester *models.User, filter *models.PostsFilter) (*models.SavedSearch, error) {
+	args := m.Called(ctx, requester, filter)
+	return args.Get(0).(*models.SavedSearch), args.Error(1)
+}
+
+func TestAdd_Success(t *testing.T) {
+	t.Parallel()
+
+	adder := new(mockSearchAdder)
+	user := &models.User{ID: "1"}
+
+	filter := &models.PostsFilter{MinPrice: 100, MaxPrice: 200, SortBy: "price", SortOrder: "asc"}
+
+	adder.On("AddSearch", mock.Anything, user, filter).
+		Return(&models.SavedSearch{ID: "s1", UserID: "1", Filter: *filter, CreatedAt: time.Now()}, nil)
+
+	body := `{"min_price": 100, "max_price": 200, "sort_by": "price", "sort_order": "asc"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/me/searches", strings.NewReader(body))
+	ctx := context.WithValue(req.Context(), models.UserContextKey, user)
+	rr := httptest.NewRecorder()
+
+	Add(ctx, slog.New(slog.NewTextHandler(io.Discard, nil)), rr, req, adder)
+
+	assert.Equal(t, http.StatusCreated, rr.Code)
+
+	var resp map[string]map[string]any
+	err := json.NewDecoder(rr.Body).Decode(&resp)
+	assert.NoError(t, err)
+	assert.Equal(t, "s1", resp["search"]["id"])
+	assert.Equal(t, float64(100), resp["search"]["min_price"])
+
+	adder.AssertExpectations(t)
+}
+
+func TestAdd_InvalidJSON(t *testing.T) {
+	t.Parallel()
+
+	adder := new(mockSearchAdder)
+	user := &models.User{ID: "1"}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/me/searches", strings.NewReader(`{invalid json}`))
+	ctx := context.WithValue(req.Context(), models.UserContextKey, user)
+	rr := httptest.NewRecorder()
+
+	Add(ctx, slog.New(slog.NewTextHandler(io.Discard, nil)), rr, req, adder)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	adder.AssertExpectations(t)
+}
+
+func TestAdd_InvalidFilter(t *testing.T) {
+	t.Parallel()
+
+	adder := new(mockSearchAdder)
+	user := &models.User{ID: "1"}
+
+	adder.On("AddSearch", mock.Anything, user, mock.Anything).
+		Return((*models.SavedSearch)(nil), models.ErrInvalidFilter)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/me/searches", strings.NewReader(`{"sort_by": "name"}`))
+	ctx := context.WithValue(req.Context(), models.UserContextKey, user)
+	rr := httptest.NewRecorder()
+
+	Add(ctx, slog.New(slog.NewTextHandler(io.Discard, nil)), rr, req, adder)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	adder.AssertExpectations(t)
+}
+
+func TestAdd_InternalError(t *testing.T) {
+	t.Parallel()
+
+	adder := new(mockSearchAdder)
+	user := &models.User{ID: "1"}
+
+	adder.On("AddSearch", mock.Anything, user, mock.Anything).
+		Return((*models.SavedSearch)(nil), errors.New("db down"))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/me/searches", strings.NewReader(`{}`))
+	ctx := context.WithValue(req.Context(), models.UserContextKey, user)
+	rr := httptest.NewRecorder()
+
+	Add(ctx, slog.New(slog.NewTextHandler(io.Discard, nil)), rr, req, adder)
+
+	assert.Equal(t, http.StatusInternalServerError, rr.Code)
+
+	adder.AssertExpectations(t)
+}
+
+func TestAdd_NoUser(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/me/searches", strings.NewReader(`{}`))
+	rr := httptest.NewRecorder()
+
+	Add(req.Context(), slog.New(slog.NewTextHandler(io.Discard, nil)), rr, req, new(mockSearchAdder))
+
+	assert.Equal(t, http.StatusInternalServerError, rr.Code)
+}
@@ -0,0 +1,63 @@
+package searchhandler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"marketplace/internal/dto"
+	"marketplace/internal/models"
+	utils "marketplace/internal/utils/http_errors"
+	"marketplace/internal/utils/mapper"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+func Update(ctx context.Context, log *slog.Logger, w http.ResponseWriter, r *http.Request, su SearchUpdater) {
+	op := pkg + "Update"
+
+	log = log.With(slog.String("op", op))
+
+	requester, ok := ctx.Value(models.UserContextKey).(*models.User)
+	if !ok {
+		log.Error("failed to parse user from context")
+		utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+		return
+	}
+
+	var searchRequest dto.SearchRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&searchRequest); err != nil {
+		log.Warn("failed to decode body", slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusBadRequest, models.ErrInvalidParams.Error())
+		return
+	}
+	defer r.Body.Close()
+
+	search, err := su.UpdateSearch(ctx, requester, mux.Vars(r)["id"], mapper.FilterFromDto(&searchRequest))
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidFilter) {
+			log.Warn("invalid filter received", slog.String("error", err.Error()))
+			utils.WriteJSONError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if errors.Is(err, models.ErrSearchNotFound) {
+			log.Warn("saved search not found", slog.String("error", err.Error()))
+			utils.WriteJSONError(w, http.StatusNotFound, models.ErrSearchNotFound.Error())
+			return
+		}
+		log.Error("failed to update saved search", slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+		return
+	}
+
+	response := map[string]any{
+		"search": mapper.DtoFromSearch(search),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Error("failed to write response", slog.String("error", err.Error()))
+	}
+}
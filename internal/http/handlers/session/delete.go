@@ -6,7 +6,10 @@ import (
 	"errors"
 	"log/slog"
 	"marketplace/internal/models"
+	utils "marketplace/internal/utils/http_errors"
 	"net/http"
+
+	"github.com/gorilla/mux"
 )
 
 func Delete(ctx context.Context, log *slog.Logger, w http.ResponseWriter, r *http.Request, sd SessionDeleter) {
@@ -38,3 +41,50 @@ func Delete(ctx context.Context, log *slog.Logger, w http.ResponseWriter, r *htt
 		log.Error("failed to write response", slog.String("error", err.Error()))
 	}
 }
+
+func Revoke(ctx context.Context, log *slog.Logger, w http.ResponseWriter, r *http.Request, sr SessionRevoker) {
+	op := pkg + "Revoke"
+
+	log = log.With(slog.String("op", op))
+
+	requester, ok := ctx.Value(models.UserContextKey).(*models.User)
+	if !ok {
+		log.Error("failed to parse user from context")
+		utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+		return
+	}
+
+	if err := sr.RevokeSession(ctx, requester, mux.Vars(r)["id"]); err != nil {
+		if errors.Is(err, models.ErrSessionNotFound) {
+			log.Warn("session not found", slog.String("error", err.Error()))
+			utils.WriteJSONError(w, http.StatusNotFound, models.ErrSessionNotFound.Error())
+			return
+		}
+		log.Error("failed to revoke session", slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func RevokeAll(ctx context.Context, log *slog.Logger, w http.ResponseWriter, r *http.Request, sr SessionRevoker) {
+	op := pkg + "RevokeAll"
+
+	log = log.With(slog.String("op", op))
+
+	requester, ok := ctx.Value(models.UserContextKey).(*models.User)
+	if !ok {
+		log.Error("failed to parse user from context")
+		utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+		return
+	}
+
+	if err := sr.RevokeAllSessions(ctx, requester); err != nil {
+		log.Error("failed to revoke sessions", slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -11,6 +11,7 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/gorilla/mux"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -123,3 +124,83 @@ func TestDelete_UnexpectedError(t *testing.T) {
 
 	mockDeleter.AssertExpectations(t)
 }
+
+type mockSessionRevoker struct {
+	mock.Mock
+}
+
+func (m *mockSessionRevoker) RevokeSession(ctx context.Context, requester *models.User, id string) error {
+	return m.Called(ctx, requester, id).Error(0)
+}
+
+func (m *mockSessionRevoker) RevokeAllSessions(ctx context.Context, requester *models.User) error {
+	return m.Called(ctx, requester).Error(0)
+}
+
+func TestRevoke(t *testing.T) {
+	t.Parallel()
+
+	user := &models.User{ID: "u1", Login: "alice"}
+
+	tests := []struct {
+		name       string
+		serviceErr error
+		wantStatus int
+	}{
+		{name: "success", wantStatus: http.StatusNoContent},
+		{name: "not found", serviceErr: models.ErrSessionNotFound, wantStatus: http.StatusNotFound},
+		{name: "service error", serviceErr: errors.New("boom"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			revoker := new(mockSessionRevoker)
+			revoker.On("RevokeSession", mock.Anything, user, "s1").Return(tt.serviceErr)
+
+			ctx := context.WithValue(context.Background(), models.UserContextKey, user)
+			req := httptest.NewRequest(http.MethodDelete, "/api/me/sessions/s1", nil)
+			req = mux.SetURLVars(req, map[string]string{"id": "s1"})
+			w := httptest.NewRecorder()
+
+			Revoke(ctx, slog.New(slog.NewTextHandler(io.Discard, nil)), w, req, revoker)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			revoker.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRevokeAll(t *testing.T) {
+	t.Parallel()
+
+	user := &models.User{ID: "u1", Login: "alice"}
+
+	tests := []struct {
+		name       string
+		serviceErr error
+		wantStatus int
+	}{
+		{name: "success", wantStatus: http.StatusNoContent},
+		{name: "service error", serviceErr: errors.New("boom"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			revoker := new(mockSessionRevoker)
+			revoker.On("RevokeAllSessions", mock.Anything, user).Return(tt.serviceErr)
+
+			ctx := context.WithValue(context.Background(), models.UserContextKey, user)
+			req := httptest.NewRequest(http.MethodDelete, "/api/me/sessions", nil)
+			w := httptest.NewRecorder()
+
+			RevokeAll(ctx, slog.New(slog.NewTextHandler(io.Discard, nil)), w, req, revoker)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			revoker.AssertExpectations(t)
+		})
+	}
+}
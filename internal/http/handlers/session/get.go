@@ -0,0 +1,175 @@
+package sessionhandler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"marketplace/internal/models"
+	"marketplace/internal/utils/clientinfo"
+	utils "marketplace/internal/utils/http_errors"
+	"marketplace/internal/utils/mapper"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+func List(ctx context.Context, log *slog.Logger, w http.ResponseWriter, r *http.Request, sl SessionLister) {
+	op := pkg + "List"
+
+	log = log.With(slog.String("op", op))
+
+	requester, ok := ctx.Value(models.UserContextKey).(*models.User)
+	if !ok {
+		log.Error("failed to parse user from context")
+		utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+		return
+	}
+
+	token, _ := ctx.Value(models.TokenContextKey).(string)
+
+	sessions, err := sl.Sessions(ctx, requester, token)
+	if err != nil {
+		log.Error("failed to list sessions", slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+		return
+	}
+
+	response := map[string]any{
+		"data": map[string]any{
+			"sessions": mapper.DtoFromSessions(sessions),
+		},
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Error("failed to write response", slog.String("error", err.Error()))
+	}
+}
+
+// BeginExternal returns the identity provider page to send the user to. A
+// signed-in user links the provider's identity to their account instead of
+// logging in with it. API keys never start a flow, since a linked identity
+// signs in as the account.
+func BeginExternal(ctx context.Context, log *slog.Logger, w http.ResponseWriter, r *http.Request, ea ExternalAuthenticator) {
+	op := pkg + "BeginExternal"
+
+	log = log.With(slog.String("op", op))
+
+	if _, ok := ctx.Value(models.APIKeyContextKey).(*models.APIKey); ok {
+		log.Warn("external login requested with an api key")
+		utils.WriteJSONError(w, http.StatusForbidden, models.ErrAPIKeyNotAllowed.Error())
+		return
+	}
+
+	provider := mux.Vars(r)["provider"]
+
+	requester, _ := ctx.Value(models.UserContextKey).(*models.User)
+
+	start, err := ea.Begin(ctx, provider, requester)
+	if err != nil {
+		writeExternalError(log, w, err, "failed to begin external login")
+		return
+	}
+
+	// Lax, since the provider brings the browser back with a cross-site
+	// redirect.
+	http.SetCookie(w, &http.Cookie{
+		Name:     stateCookie,
+		Value:    start.Binding,
+		Path:     externalPath(provider),
+		Expires:  start.ExpiresAt,
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	response := map[string]any{
+		"data": map[string]any{
+			"authorization_url": start.AuthURL,
+		},
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Error("failed to write response", slog.String("error", err.Error()))
+	}
+}
+
+// ExternalCallback is where the identity provider redirects back to. It
+// answers like POST /api/auth, or with no content when an identity was
+// linked. The state cookie set by BeginExternal is used up either way.
+func ExternalCallback(ctx context.Context, log *slog.Logger, w http.ResponseWriter, r *http.Request, ea ExternalAuthenticator) {
+	op := pkg + "ExternalCallback"
+
+	log = log.With(slog.String("op", op))
+
+	provider := mux.Vars(r)["provider"]
+	query := r.URL.Query()
+
+	var binding string
+	if cookie, err := r.Cookie(stateCookie); err == nil {
+		binding = cookie.Value
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     stateCookie,
+		Path:     externalPath(provider),
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	if query.Get("error") != "" {
+		log.Warn("provider returned an error", slog.String("error", query.Get("error")))
+		utils.WriteJSONError(w, http.StatusUnauthorized, models.ErrExternalLoginFailed.Error())
+		return
+	}
+
+	if query.Get("state") == "" || query.Get("code") == "" {
+		log.Warn("invalid callback request")
+		utils.WriteJSONError(w, http.StatusBadRequest, models.ErrInvalidParams.Error())
+		return
+	}
+
+	result, err := ea.Callback(ctx, provider, query.Get("state"), binding, query.Get("code"), clientinfo.FromRequest(r))
+	if err != nil {
+		writeExternalError(log, w, err, "failed to complete external login")
+		return
+	}
+
+	switch {
+	case result.Login == nil:
+		w.WriteHeader(http.StatusNoContent)
+	case result.Login.Challenge != "":
+		writeChallenge(log, w, result.Login.Challenge)
+	default:
+		writeTokens(log, w, result.Login.Tokens)
+	}
+}
+
+// externalPath limits the state cookie to the routes of one provider.
+func externalPath(provider string) string {
+	return "/api/auth/oidc/" + provider
+}
+
+func writeExternalError(log *slog.Logger, w http.ResponseWriter, err error, msg string) {
+	switch {
+	case errors.Is(err, models.ErrProviderNotFound):
+		log.Warn(msg, slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, models.ErrInvalidOIDCState), errors.Is(err, models.ErrExternalLoginFailed):
+		log.Warn(msg, slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusUnauthorized, err.Error())
+	case errors.Is(err, models.ErrIdentityLinked):
+		log.Warn(msg, slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, models.ErrUserBanned):
+		log.Warn(msg, slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusForbidden, err.Error())
+	default:
+		log.Error(msg, slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+	}
+}
@@ -0,0 +1,287 @@
+package sessionhandler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"marketplace/internal/http/middleware"
+	"marketplace/internal/models"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockSessionLister struct {
+	mock.Mock
+}
+
+func (m *mockSessionLister) Sessions(ctx context.Context, requester *models.User, currentToken string) ([]*models.Session, error) {
+	args := m.Called(ctx, requester, currentToken)
+	sessions, _ := args.Get(0).([]*models.Session)
+	return sessions, args.Error(1)
+}
+
+type mockExternalAuthenticator struct {
+	mock.Mock
+}
+
+func (m *mockExternalAuthenticator) Begin(ctx context.Context, providerName string, requester *models.User) (*models.ExternalStart, error) {
+	args := m.Called(ctx, providerName, requester)
+	start, _ := args.Get(0).(*models.ExternalStart)
+	return start, args.Error(1)
+}
+
+func (m *mockExternalAuthenticator) Callback(ctx context.Context, providerName, state, binding, code string, client *models.ClientInfo) (*models.ExternalLogin, error) {
+	args := m.Called(ctx, providerName, state, binding, code, client)
+	result, _ := args.Get(0).(*models.ExternalLogin)
+	return result, args.Error(1)
+}
+
+func TestList(t *testing.T) {
+	t.Parallel()
+
+	user := &models.User{ID: "u1", Login: "alice"}
+	seen := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		sessions   []*models.Session
+		serviceErr error
+		wantStatus int
+	}{
+		{
+			name: "success",
+			sessions: []*models.Session{
+				{ID: "s1", UserID: "u1", UserAgent: "phone", IP: "10.0.0.1", CreatedAt: seen, LastSeenAt: seen, Current: true},
+				{ID: "s2", UserID: "u1", UserAgent: "laptop", IP: "10.0.0.2", CreatedAt: seen, LastSeenAt: seen},
+			},
+			wantStatus: http.StatusOK,
+		},
+		{name: "service error", serviceErr: errors.New("boom"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			lister := new(mockSessionLister)
+			lister.On("Sessions", mock.Anything, user, "token").Return(tt.sessions, tt.serviceErr)
+
+			ctx := context.WithValue(context.Background(), models.UserContextKey, user)
+			ctx = context.WithValue(ctx, models.TokenContextKey, "token")
+			req := httptest.NewRequest(http.MethodGet, "/api/me/sessions", nil)
+			w := httptest.NewRecorder()
+
+			List(ctx, slog.New(slog.NewTextHandler(io.Discard, nil)), w, req, lister)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantStatus == http.StatusOK {
+				var response struct {
+					Data struct {
+						Sessions []struct {
+							ID      string `json:"id"`
+							Current bool   `json:"current"`
+						} `json:"sessions"`
+					} `json:"data"`
+				}
+				assert.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+				assert.Len(t, response.Data.Sessions, 2)
+				assert.Equal(t, "s1", response.Data.Sessions[0].ID)
+				assert.True(t, response.Data.Sessions[0].Current)
+				assert.False(t, response.Data.Sessions[1].Current)
+			}
+			lister.AssertExpectations(t)
+		})
+	}
+}
+
+func TestBeginExternal(t *testing.T) {
+	t.Parallel()
+
+	user := &models.User{ID: "u1"}
+	start := &models.ExternalStart{AuthURL: "https://idp/authorize?state=s", Binding: "b1", ExpiresAt: time.Now().Add(10 * time.Minute)}
+
+	tests := []struct {
+		name       string
+		provider   string
+		requester  *models.User
+		serviceErr error
+		wantStatus int
+	}{
+		{name: "login", provider: "google", wantStatus: http.StatusOK},
+		{name: "link", provider: "google", requester: user, wantStatus: http.StatusOK},
+		{name: "unknown provider", provider: "github", serviceErr: models.ErrProviderNotFound, wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			authenticator := new(mockExternalAuthenticator)
+			if tt.serviceErr != nil {
+				authenticator.On("Begin", mock.Anything, tt.provider, tt.requester).Return(nil, tt.serviceErr)
+			} else {
+				authenticator.On("Begin", mock.Anything, tt.provider, tt.requester).Return(start, nil)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/api/auth/oidc/"+tt.provider, nil)
+			req = mux.SetURLVars(req, map[string]string{"provider": tt.provider})
+			ctx := req.Context()
+			if tt.requester != nil {
+				ctx = context.WithValue(ctx, models.UserContextKey, tt.requester)
+			}
+			w := httptest.NewRecorder()
+
+			BeginExternal(ctx, slog.New(slog.NewTextHandler(io.Discard, nil)), w, req, authenticator)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			authenticator.AssertExpectations(t)
+
+			if tt.serviceErr != nil {
+				assert.Empty(t, w.Result().Cookies())
+				return
+			}
+
+			var result map[string]map[string]string
+			assert.NoError(t, json.NewDecoder(w.Body).Decode(&result))
+			assert.Equal(t, "https://idp/authorize?state=s", result["data"]["authorization_url"])
+
+			cookies := w.Result().Cookies()
+			if assert.Len(t, cookies, 1) {
+				assert.Equal(t, stateCookie, cookies[0].Name)
+				assert.Equal(t, "b1", cookies[0].Value)
+				assert.Equal(t, "/api/auth/oidc/"+tt.provider, cookies[0].Path)
+				assert.True(t, cookies[0].HttpOnly)
+				assert.Equal(t, http.SameSiteLaxMode, cookies[0].SameSite)
+			}
+		})
+	}
+}
+
+func TestBeginExternal_APIKey(t *testing.T) {
+	t.Parallel()
+
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	owner := &models.User{ID: "u1"}
+
+	t.Run("authenticated with a key", func(t *testing.T) {
+		t.Parallel()
+
+		authenticator := new(mockExternalAuthenticator)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/auth/oidc/google", nil)
+		req.Header.Set("Authorization", "ApiKey mk_leaked")
+		req = mux.SetURLVars(req, map[string]string{"provider": "google"})
+		ctx := context.WithValue(req.Context(), models.UserContextKey, owner)
+		ctx = context.WithValue(ctx, models.APIKeyContextKey, &models.APIKey{ID: "k1", Scopes: []string{models.ScopePostsRead}})
+		w := httptest.NewRecorder()
+
+		BeginExternal(ctx, log, w, req, authenticator)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		authenticator.AssertNotCalled(t, "Begin", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("public route ignores the key", func(t *testing.T) {
+		t.Parallel()
+
+		authenticator := new(mockExternalAuthenticator)
+		authenticator.On("Begin", mock.Anything, "google", (*models.User)(nil)).Return(&models.ExternalStart{AuthURL: "https://idp/authorize?state=s", Binding: "b1"}, nil)
+
+		router := mux.NewRouter()
+		router.Use(middleware.AuthOptional(log, nil, nil))
+		router.HandleFunc("/api/auth/oidc/{provider}", func(w http.ResponseWriter, r *http.Request) {
+			BeginExternal(r.Context(), log, w, r, authenticator)
+		})
+
+		req := httptest.NewRequest(http.MethodGet, "/api/auth/oidc/google", nil)
+		req.Header.Set("Authorization", "ApiKey mk_leaked")
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		authenticator.AssertExpectations(t)
+	})
+}
+
+func TestExternalCallback(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		query      string
+		noCookie   bool
+		result     *models.ExternalLogin
+		serviceErr error
+		wantStatus int
+		wantToken  string
+	}{
+		{
+			name:       "logged in",
+			query:      "?state=s1&code=c1",
+			result:     &models.ExternalLogin{Login: &models.LoginResult{Tokens: &models.TokenPair{AccessToken: "a1", RefreshToken: "r1"}}},
+			wantStatus: http.StatusOK,
+			wantToken:  "a1",
+		},
+		{
+			name:       "second factor required",
+			query:      "?state=s1&code=c1",
+			result:     &models.ExternalLogin{Login: &models.LoginResult{Challenge: "challenge-1"}},
+			wantStatus: http.StatusOK,
+		},
+		{name: "linked", query: "?state=s1&code=c1", result: &models.ExternalLogin{LinkedUserID: "u1"}, wantStatus: http.StatusNoContent},
+		{name: "already linked", query: "?state=s1&code=c1", serviceErr: models.ErrIdentityLinked, wantStatus: http.StatusConflict},
+		{name: "expired state", query: "?state=s1&code=c1", serviceErr: models.ErrInvalidOIDCState, wantStatus: http.StatusUnauthorized},
+		{name: "no state cookie", query: "?state=s1&code=c1", noCookie: true, serviceErr: models.ErrInvalidOIDCState, wantStatus: http.StatusUnauthorized},
+		{name: "denied at provider", query: "?state=s1&error=access_denied", wantStatus: http.StatusUnauthorized},
+		{name: "missing code", query: "?state=s1", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			authenticator := new(mockExternalAuthenticator)
+			binding := "b1"
+			if tt.noCookie {
+				binding = ""
+			}
+			authenticator.On("Callback", mock.Anything, "google", "s1", binding, "c1", mock.Anything).Return(tt.result, tt.serviceErr)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/auth/oidc/google/callback"+tt.query, nil)
+			req = mux.SetURLVars(req, map[string]string{"provider": "google"})
+			if !tt.noCookie {
+				req.AddCookie(&http.Cookie{Name: stateCookie, Value: "b1"})
+			}
+			w := httptest.NewRecorder()
+
+			ExternalCallback(req.Context(), slog.New(slog.NewTextHandler(io.Discard, nil)), w, req, authenticator)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+
+			cookies := w.Result().Cookies()
+			if assert.Len(t, cookies, 1) {
+				assert.Equal(t, stateCookie, cookies[0].Name)
+				assert.Equal(t, -1, cookies[0].MaxAge)
+			}
+
+			if tt.wantStatus == http.StatusOK {
+				var result map[string]map[string]any
+				assert.NoError(t, json.NewDecoder(w.Body).Decode(&result))
+				if tt.wantToken != "" {
+					assert.Equal(t, tt.wantToken, result["response"]["token"])
+				} else {
+					assert.Equal(t, "challenge-1", result["response"]["challenge"])
+				}
+			}
+		})
+	}
+}
@@ -1,13 +1,42 @@
 package sessionhandler
 
-import "context"
+import (
+	"context"
+	"marketplace/internal/models"
+)
 
 const pkg = "sessionHandler/"
 
+// stateCookie carries the binding of an external login flow to the browser
+// that started it.
+const stateCookie = "oidc_state"
+
 type SessionAdder interface {
-	Login(ctx context.Context, login string, password string) (string, error)
+	Login(ctx context.Context, login string, password string, client *models.ClientInfo) (*models.LoginResult, error)
+}
+
+type TwoFactorCompleter interface {
+	CompleteTwoFactor(ctx context.Context, challengeToken string, code string, client *models.ClientInfo) (*models.TokenPair, error)
+}
+
+type ExternalAuthenticator interface {
+	Begin(ctx context.Context, providerName string, requester *models.User) (*models.ExternalStart, error)
+	Callback(ctx context.Context, providerName string, state string, binding string, code string, client *models.ClientInfo) (*models.ExternalLogin, error)
+}
+
+type SessionRefresher interface {
+	Refresh(ctx context.Context, refreshToken string, client *models.ClientInfo) (*models.TokenPair, error)
 }
 
 type SessionDeleter interface {
 	Logout(ctx context.Context, token string) error
 }
+
+type SessionLister interface {
+	Sessions(ctx context.Context, requester *models.User, currentToken string) ([]*models.Session, error)
+}
+
+type SessionRevoker interface {
+	RevokeSession(ctx context.Context, requester *models.User, id string) error
+	RevokeAllSessions(ctx context.Context, requester *models.User) error
+}
@@ -8,6 +8,7 @@ import (
 	"log/slog"
 	"marketplace/internal/dto"
 	"marketplace/internal/models"
+	"marketplace/internal/utils/clientinfo"
 	utils "marketplace/internal/utils/http_errors"
 	"net/http"
 )
@@ -34,11 +35,12 @@ func Add(ctx context.Context, log *slog.Logger, w http.ResponseWriter, r *http.R
 		return
 	}
 
-	token, err := sa.Login(ctx, sessionRequest.Login, sessionRequest.Password)
+	result, err := sa.Login(ctx, sessionRequest.Login, sessionRequest.Password, clientinfo.FromRequest(r))
 	if err != nil {
-		if errors.Is(err, models.ErrUserNotFound) {
-			log.Warn("failed to add session", slog.String("error", models.ErrUserNotFound.Error()))
-			utils.WriteJSONError(w, http.StatusBadRequest, models.ErrInvalidCredentials.Error())
+		var retry *models.RetryAfterError
+		if errors.As(err, &retry) {
+			log.Warn("failed to add session", slog.String("error", err.Error()))
+			utils.WriteRetryAfter(w, retry)
 			return
 		}
 		if errors.Is(err, models.ErrInvalidCredentials) {
@@ -46,16 +48,124 @@ func Add(ctx context.Context, log *slog.Logger, w http.ResponseWriter, r *http.R
 			utils.WriteJSONError(w, http.StatusBadRequest, models.ErrInvalidCredentials.Error())
 			return
 		}
+		if errors.Is(err, models.ErrUserBanned) {
+			log.Warn("failed to add session", slog.String("error", err.Error()))
+			utils.WriteJSONError(w, http.StatusForbidden, models.ErrUserBanned.Error())
+			return
+		}
 		log.Error("failed to add session", slog.String("error", err.Error()))
 		utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
 		return
 	}
 
+	if result.Challenge != "" {
+		writeChallenge(log, w, result.Challenge)
+		return
+	}
+
+	writeTokens(log, w, result.Tokens)
+}
+
+func CompleteTwoFactor(ctx context.Context, log *slog.Logger, w http.ResponseWriter, r *http.Request, tc TwoFactorCompleter) {
+	op := pkg + "CompleteTwoFactor"
+
+	log = log.With(slog.String("op", op))
+
+	var twoFactorRequest dto.TwoFactorRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&twoFactorRequest); err != nil || twoFactorRequest.Challenge == "" || twoFactorRequest.Code == "" {
+		log.Warn("invalid two-factor request")
+		utils.WriteJSONError(w, http.StatusBadRequest, models.ErrInvalidParams.Error())
+		return
+	}
+	defer r.Body.Close()
+
+	tokens, err := tc.CompleteTwoFactor(ctx, twoFactorRequest.Challenge, twoFactorRequest.Code, clientinfo.FromRequest(r))
+	if err != nil {
+		var retry *models.RetryAfterError
+		if errors.As(err, &retry) {
+			log.Warn("failed to complete two-factor login", slog.String("error", err.Error()))
+			utils.WriteRetryAfter(w, retry)
+			return
+		}
+		if errors.Is(err, models.ErrInvalidChallenge) || errors.Is(err, models.ErrInvalidTwoFactorCode) {
+			log.Warn("failed to complete two-factor login", slog.String("error", err.Error()))
+			utils.WriteJSONError(w, http.StatusUnauthorized, err.Error())
+			return
+		}
+		if errors.Is(err, models.ErrUserBanned) {
+			log.Warn("failed to complete two-factor login", slog.String("error", err.Error()))
+			utils.WriteJSONError(w, http.StatusForbidden, err.Error())
+			return
+		}
+		log.Error("failed to complete two-factor login", slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+		return
+	}
+
+	writeTokens(log, w, tokens)
+}
+
+func Refresh(ctx context.Context, log *slog.Logger, w http.ResponseWriter, r *http.Request, sr SessionRefresher) {
+	op := pkg + "Refresh"
+
+	log = log.With(slog.String("op", op))
+
+	var refreshRequest dto.RefreshRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&refreshRequest); err != nil || refreshRequest.RefreshToken == "" {
+		log.Warn("invalid refresh request")
+		utils.WriteJSONError(w, http.StatusBadRequest, models.ErrInvalidParams.Error())
+		return
+	}
+	defer r.Body.Close()
+
+	tokens, err := sr.Refresh(ctx, refreshRequest.RefreshToken, clientinfo.FromRequest(r))
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidCredentials) || errors.Is(err, models.ErrRefreshTokenReused) {
+			log.Warn("failed to refresh session", slog.String("error", err.Error()))
+			utils.WriteJSONError(w, http.StatusUnauthorized, models.ErrInvalidCredentials.Error())
+			return
+		}
+		if errors.Is(err, models.ErrUserBanned) {
+			log.Warn("failed to refresh session", slog.String("error", err.Error()))
+			utils.WriteJSONError(w, http.StatusForbidden, models.ErrUserBanned.Error())
+			return
+		}
+		log.Error("failed to refresh session", slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+		return
+	}
+
+	writeTokens(log, w, tokens)
+}
+
+// writeTokens keeps the access token under "token", where clients read the
+// session token from before refresh tokens were added.
+func writeTokens(log *slog.Logger, w http.ResponseWriter, tokens *models.TokenPair) {
+	w.Header().Set("Content-Type", "application/json")
+
+	response := map[string]any{
+		"response": map[string]any{
+			"token":         tokens.AccessToken,
+			"refresh_token": tokens.RefreshToken,
+		},
+	}
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Error("failed to write response", slog.String("error", err.Error()))
+	}
+}
+
+// writeChallenge answers a correct password of a user with two-factor
+// authentication: the session is opened by POST /api/auth/2fa.
+func writeChallenge(log *slog.Logger, w http.ResponseWriter, challenge string) {
 	w.Header().Set("Content-Type", "application/json")
 
 	response := map[string]any{
 		"response": map[string]any{
-			"token": token,
+			"two_factor_required": true,
+			"challenge":           challenge,
 		},
 	}
 
@@ -10,6 +10,7 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -19,9 +20,27 @@ type mockSessionAdder struct {
 	mock.Mock
 }
 
-func (m *mockSessionAdder) Login(ctx context.Context, login, password string) (string, error) {
-	args := m.Called(ctx, login, password)
-	return args.String(0), args.Error(1)
+func (m *mockSessionAdder) Login(ctx context.Context, login, password string, client *models.ClientInfo) (*models.LoginResult, error) {
+	args := m.Called(ctx, login, password, client)
+	return args.Get(0).(*models.LoginResult), args.Error(1)
+}
+
+type mockTwoFactorCompleter struct {
+	mock.Mock
+}
+
+func (m *mockTwoFactorCompleter) CompleteTwoFactor(ctx context.Context, challengeToken, code string, client *models.ClientInfo) (*models.TokenPair, error) {
+	args := m.Called(ctx, challengeToken, code, client)
+	return args.Get(0).(*models.TokenPair), args.Error(1)
+}
+
+type mockSessionRefresher struct {
+	mock.Mock
+}
+
+func (m *mockSessionRefresher) Refresh(ctx context.Context, refreshToken string, client *models.ClientInfo) (*models.TokenPair, error) {
+	args := m.Called(ctx, refreshToken, client)
+	return args.Get(0).(*models.TokenPair), args.Error(1)
 }
 
 type errReader struct{}
@@ -35,10 +54,13 @@ func TestSessionAdd_Success(t *testing.T) {
 
 	body := `{"login": "user1", "password": "secret"}`
 	req := httptest.NewRequest(http.MethodPost, "/api/auth", strings.NewReader(body))
+	req.Header.Set("User-Agent", "test-agent")
 	w := httptest.NewRecorder()
 
+	client := &models.ClientInfo{UserAgent: "test-agent", IP: "192.0.2.1"}
+
 	mockAdder := new(mockSessionAdder)
-	mockAdder.On("Login", mock.Anything, "user1", "secret").Return("mocked-token", nil)
+	mockAdder.On("Login", mock.Anything, "user1", "secret", client).Return(&models.LoginResult{Tokens: &models.TokenPair{AccessToken: "mocked-token", RefreshToken: "mocked-refresh"}}, nil)
 
 	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
 
@@ -53,6 +75,7 @@ func TestSessionAdd_Success(t *testing.T) {
 	err := json.NewDecoder(resp.Body).Decode(&result)
 	assert.NoError(t, err)
 	assert.Equal(t, "mocked-token", result["response"]["token"])
+	assert.Equal(t, "mocked-refresh", result["response"]["refresh_token"])
 
 	mockAdder.AssertExpectations(t)
 }
@@ -91,16 +114,16 @@ func TestSessionAdd_InvalidJSON(t *testing.T) {
 	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
 }
 
-func TestSessionAdd_UserNotFound(t *testing.T) {
+func TestSessionAdd_InvalidCredentials(t *testing.T) {
 	t.Parallel()
 
-	body := `{"login": "ghost", "password": "nopass"}`
+	body := `{"login": "user1", "password": "wrong"}`
 	req := httptest.NewRequest(http.MethodPost, "/api/auth", strings.NewReader(body))
 	w := httptest.NewRecorder()
 
 	mockAdder := new(mockSessionAdder)
-	mockAdder.On("Login", mock.Anything, "ghost", "nopass").
-		Return("", models.ErrUserNotFound)
+	mockAdder.On("Login", mock.Anything, "user1", "wrong", mock.Anything).
+		Return((*models.LoginResult)(nil), models.ErrInvalidCredentials)
 
 	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
 
@@ -111,16 +134,16 @@ func TestSessionAdd_UserNotFound(t *testing.T) {
 	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
 }
 
-func TestSessionAdd_InvalidCredentials(t *testing.T) {
+func TestSessionAdd_Banned(t *testing.T) {
 	t.Parallel()
 
-	body := `{"login": "user1", "password": "wrong"}`
+	body := `{"login": "user1", "password": "pass"}`
 	req := httptest.NewRequest(http.MethodPost, "/api/auth", strings.NewReader(body))
 	w := httptest.NewRecorder()
 
 	mockAdder := new(mockSessionAdder)
-	mockAdder.On("Login", mock.Anything, "user1", "wrong").
-		Return("", models.ErrInvalidCredentials)
+	mockAdder.On("Login", mock.Anything, "user1", "pass", mock.Anything).
+		Return((*models.LoginResult)(nil), models.ErrUserBanned)
 
 	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
 
@@ -128,5 +151,129 @@ func TestSessionAdd_InvalidCredentials(t *testing.T) {
 
 	resp := w.Result()
 	defer resp.Body.Close()
-	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+}
+
+func TestSessionRefresh(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		body       string
+		tokens     *models.TokenPair
+		serviceErr error
+		wantStatus int
+	}{
+		{name: "success", body: `{"refresh_token":"refresh-1"}`, tokens: &models.TokenPair{AccessToken: "a2", RefreshToken: "r2"}, wantStatus: http.StatusOK},
+		{name: "reused", body: `{"refresh_token":"refresh-1"}`, serviceErr: models.ErrRefreshTokenReused, wantStatus: http.StatusUnauthorized},
+		{name: "unknown", body: `{"refresh_token":"refresh-1"}`, serviceErr: models.ErrInvalidCredentials, wantStatus: http.StatusUnauthorized},
+		{name: "banned", body: `{"refresh_token":"refresh-1"}`, serviceErr: models.ErrUserBanned, wantStatus: http.StatusForbidden},
+		{name: "missing token", body: `{}`, wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			refresher := new(mockSessionRefresher)
+			refresher.On("Refresh", mock.Anything, "refresh-1", mock.Anything).Return(tt.tokens, tt.serviceErr)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/auth/refresh", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+
+			Refresh(req.Context(), slog.New(slog.NewTextHandler(io.Discard, nil)), w, req, refresher)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+
+			if tt.tokens != nil {
+				var result map[string]map[string]string
+				assert.NoError(t, json.NewDecoder(w.Body).Decode(&result))
+				assert.Equal(t, "a2", result["response"]["token"])
+				assert.Equal(t, "r2", result["response"]["refresh_token"])
+			}
+		})
+	}
+}
+
+func TestSessionAdd_Blocked(t *testing.T) {
+	t.Parallel()
+
+	body := `{"login": "user1", "password": "wrong"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/auth", strings.NewReader(body))
+	w := httptest.NewRecorder()
+
+	mockAdder := new(mockSessionAdder)
+	mockAdder.On("Login", mock.Anything, "user1", "wrong", mock.Anything).
+		Return((*models.LoginResult)(nil), &models.RetryAfterError{After: 1500 * time.Millisecond, Err: models.ErrLoginBlocked})
+
+	Add(req.Context(), slog.New(slog.NewTextHandler(io.Discard, nil)), w, req, mockAdder)
+
+	resp := w.Result()
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	assert.Equal(t, "2", resp.Header.Get("Retry-After"))
+}
+
+func TestSessionAdd_TwoFactorRequired(t *testing.T) {
+	t.Parallel()
+
+	body := `{"login": "user1", "password": "secret"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/auth", strings.NewReader(body))
+	w := httptest.NewRecorder()
+
+	mockAdder := new(mockSessionAdder)
+	mockAdder.On("Login", mock.Anything, "user1", "secret", mock.Anything).Return(&models.LoginResult{Challenge: "challenge-1"}, nil)
+
+	Add(req.Context(), slog.New(slog.NewTextHandler(io.Discard, nil)), w, req, mockAdder)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var result map[string]map[string]any
+	assert.NoError(t, json.NewDecoder(w.Body).Decode(&result))
+	assert.Equal(t, true, result["response"]["two_factor_required"])
+	assert.Equal(t, "challenge-1", result["response"]["challenge"])
+	assert.NotContains(t, result["response"], "token")
+}
+
+func TestCompleteTwoFactor(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		body       string
+		tokens     *models.TokenPair
+		serviceErr error
+		wantStatus int
+	}{
+		{name: "success", body: `{"challenge":"challenge-1","code":"123456"}`, tokens: &models.TokenPair{AccessToken: "a1", RefreshToken: "r1"}, wantStatus: http.StatusOK},
+		{name: "wrong code", body: `{"challenge":"challenge-1","code":"123456"}`, serviceErr: models.ErrInvalidTwoFactorCode, wantStatus: http.StatusUnauthorized},
+		{name: "expired challenge", body: `{"challenge":"challenge-1","code":"123456"}`, serviceErr: models.ErrInvalidChallenge, wantStatus: http.StatusUnauthorized},
+		{name: "blocked", body: `{"challenge":"challenge-1","code":"123456"}`, serviceErr: &models.RetryAfterError{After: time.Second, Err: models.ErrLoginBlocked}, wantStatus: http.StatusTooManyRequests},
+		{name: "banned", body: `{"challenge":"challenge-1","code":"123456"}`, serviceErr: models.ErrUserBanned, wantStatus: http.StatusForbidden},
+		{name: "missing code", body: `{"challenge":"challenge-1"}`, wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			completer := new(mockTwoFactorCompleter)
+			completer.On("CompleteTwoFactor", mock.Anything, "challenge-1", "123456", mock.Anything).Return(tt.tokens, tt.serviceErr)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/auth/2fa", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+
+			CompleteTwoFactor(req.Context(), slog.New(slog.NewTextHandler(io.Discard, nil)), w, req, completer)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+
+			if tt.tokens != nil {
+				var result map[string]map[string]string
+				assert.NoError(t, json.NewDecoder(w.Body).Decode(&result))
+				assert.Equal(t, "a1", result["response"]["token"])
+				assert.Equal(t, "r1", result["response"]["refresh_token"])
+			}
+		})
+	}
 }
@@ -0,0 +1,14 @@
+package twofactorhandler
+
+import (
+	"context"
+	"marketplace/internal/models"
+)
+
+const pkg = "twoFactorHandler/"
+
+type TwoFactorManager interface {
+	Enroll(ctx context.Context, requester *models.User) (*models.TOTPEnrollment, error)
+	Confirm(ctx context.Context, requester *models.User, code string, client *models.ClientInfo) ([]string, error)
+	Disable(ctx context.Context, requester *models.User, code string, client *models.ClientInfo) error
+}
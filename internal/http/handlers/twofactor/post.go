@@ -0,0 +1,134 @@
+package twofactorhandler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"marketplace/internal/dto"
+	"marketplace/internal/models"
+	"marketplace/internal/utils/clientinfo"
+	utils "marketplace/internal/utils/http_errors"
+	"net/http"
+)
+
+func Enroll(ctx context.Context, log *slog.Logger, w http.ResponseWriter, r *http.Request, tm TwoFactorManager) {
+	op := pkg + "Enroll"
+
+	log = log.With(slog.String("op", op))
+
+	requester, ok := ctx.Value(models.UserContextKey).(*models.User)
+	if !ok {
+		log.Error("failed to parse user from context")
+		utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+		return
+	}
+
+	enrollment, err := tm.Enroll(ctx, requester)
+	if err != nil {
+		writeTwoFactorError(log, w, err, "failed to enroll two-factor authentication")
+		return
+	}
+
+	writeJSON(log, w, http.StatusCreated, map[string]any{
+		"two_factor": enrollment,
+	})
+}
+
+func Confirm(ctx context.Context, log *slog.Logger, w http.ResponseWriter, r *http.Request, tm TwoFactorManager) {
+	op := pkg + "Confirm"
+
+	log = log.With(slog.String("op", op))
+
+	requester, ok := ctx.Value(models.UserContextKey).(*models.User)
+	if !ok {
+		log.Error("failed to parse user from context")
+		utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+		return
+	}
+
+	code, ok := decodeCode(log, w, r)
+	if !ok {
+		return
+	}
+
+	recoveryCodes, err := tm.Confirm(ctx, requester, code, clientinfo.FromRequest(r))
+	if err != nil {
+		writeTwoFactorError(log, w, err, "failed to confirm two-factor authentication")
+		return
+	}
+
+	writeJSON(log, w, http.StatusOK, map[string]any{
+		"data": map[string]any{
+			"recovery_codes": recoveryCodes,
+		},
+	})
+}
+
+func Disable(ctx context.Context, log *slog.Logger, w http.ResponseWriter, r *http.Request, tm TwoFactorManager) {
+	op := pkg + "Disable"
+
+	log = log.With(slog.String("op", op))
+
+	requester, ok := ctx.Value(models.UserContextKey).(*models.User)
+	if !ok {
+		log.Error("failed to parse user from context")
+		utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+		return
+	}
+
+	code, ok := decodeCode(log, w, r)
+	if !ok {
+		return
+	}
+
+	if err := tm.Disable(ctx, requester, code, clientinfo.FromRequest(r)); err != nil {
+		writeTwoFactorError(log, w, err, "failed to disable two-factor authentication")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func decodeCode(log *slog.Logger, w http.ResponseWriter, r *http.Request) (string, bool) {
+	var codeRequest dto.TwoFactorCodeRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&codeRequest); err != nil || codeRequest.Code == "" {
+		log.Warn("invalid two-factor code request")
+		utils.WriteJSONError(w, http.StatusBadRequest, models.ErrInvalidParams.Error())
+		return "", false
+	}
+	defer r.Body.Close()
+
+	return codeRequest.Code, true
+}
+
+func writeJSON(log *slog.Logger, w http.ResponseWriter, status int, response map[string]any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Error("failed to write response", slog.String("error", err.Error()))
+	}
+}
+
+func writeTwoFactorError(log *slog.Logger, w http.ResponseWriter, err error, msg string) {
+	var retry *models.RetryAfterError
+
+	switch {
+	case errors.As(err, &retry):
+		log.Warn(msg, slog.String("error", err.Error()))
+		utils.WriteRetryAfter(w, retry)
+	case errors.Is(err, models.ErrInvalidTwoFactorCode):
+		log.Warn(msg, slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, models.ErrTwoFactorNotEnrolled):
+		log.Warn(msg, slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, models.ErrTwoFactorEnabled):
+		log.Warn(msg, slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusConflict, err.Error())
+	default:
+		log.Error(msg, slog.String("error", err.Error()))
+		utils.WriteJSONError(w, http.StatusInternalServerError, models.ErrInternal.Error())
+	}
+}
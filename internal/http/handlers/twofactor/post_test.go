@@ -0,0 +1,153 @@
+package twofactorhandler
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"marketplace/internal/models"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockTwoFactorManager struct {
+	mock.Mock
+}
+
+func (m *mockTwoFactorManager) Enroll(ctx context.Context, requester *models.User) (*models.TOTPEnrollment, error) {
+	args := m.Called(ctx, requester)
+	enrollment, _ := args.Get(0).(*models.TOTPEnrollment)
+	return enrollment, args.Error(1)
+}
+
+func (m *mockTwoFactorManager) Confirm(ctx context.Context, requester *models.User, code string, client *models.ClientInfo) ([]string, error) {
+	args := m.Called(ctx, requester, code, client)
+	codes, _ := args.Get(0).([]string)
+	return codes, args.Error(1)
+}
+
+func (m *mockTwoFactorManager) Disable(ctx context.Context, requester *models.User, code string, client *models.ClientInfo) error {
+	return m.Called(ctx, requester, code, client).Error(0)
+}
+
+var user = &models.User{ID: "1", Login: "user1"}
+
+func TestEnroll(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		enrollment *models.TOTPEnrollment
+		serviceErr error
+		wantStatus int
+	}{
+		{name: "created", enrollment: &models.TOTPEnrollment{Secret: "SECRET", URI: "otpauth://totp/x"}, wantStatus: http.StatusCreated},
+		{name: "already enabled", serviceErr: models.ErrTwoFactorEnabled, wantStatus: http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			manager := new(mockTwoFactorManager)
+			manager.On("Enroll", mock.Anything, user).Return(tt.enrollment, tt.serviceErr)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/me/2fa", nil)
+			ctx := context.WithValue(req.Context(), models.UserContextKey, user)
+			w := httptest.NewRecorder()
+
+			Enroll(ctx, slog.New(slog.NewTextHandler(io.Discard, nil)), w, req, manager)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+
+			if tt.enrollment != nil {
+				var result map[string]models.TOTPEnrollment
+				assert.NoError(t, json.NewDecoder(w.Body).Decode(&result))
+				assert.Equal(t, *tt.enrollment, result["two_factor"])
+			}
+		})
+	}
+}
+
+func TestConfirm(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		body       string
+		codes      []string
+		serviceErr error
+		wantStatus int
+	}{
+		{name: "enabled", body: `{"code":"123456"}`, codes: []string{"aaaaa-bbbbb"}, wantStatus: http.StatusOK},
+		{name: "wrong code", body: `{"code":"123456"}`, serviceErr: models.ErrInvalidTwoFactorCode, wantStatus: http.StatusBadRequest},
+		{name: "not enrolled", body: `{"code":"123456"}`, serviceErr: models.ErrTwoFactorNotEnrolled, wantStatus: http.StatusNotFound},
+		{name: "missing code", body: `{}`, wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			manager := new(mockTwoFactorManager)
+			manager.On("Confirm", mock.Anything, user, "123456", &models.ClientInfo{IP: "192.0.2.1"}).Return(tt.codes, tt.serviceErr)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/me/2fa/confirm", strings.NewReader(tt.body))
+			ctx := context.WithValue(req.Context(), models.UserContextKey, user)
+			w := httptest.NewRecorder()
+
+			Confirm(ctx, slog.New(slog.NewTextHandler(io.Discard, nil)), w, req, manager)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+
+			if tt.codes != nil {
+				var result map[string]map[string][]string
+				assert.NoError(t, json.NewDecoder(w.Body).Decode(&result))
+				assert.Equal(t, tt.codes, result["data"]["recovery_codes"])
+			}
+		})
+	}
+}
+
+func TestDisable(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		body       string
+		serviceErr error
+		wantStatus int
+	}{
+		{name: "disabled", body: `{"code":"123456"}`, wantStatus: http.StatusNoContent},
+		{name: "wrong code", body: `{"code":"123456"}`, serviceErr: models.ErrInvalidTwoFactorCode, wantStatus: http.StatusBadRequest},
+		{name: "not enrolled", body: `{"code":"123456"}`, serviceErr: models.ErrTwoFactorNotEnrolled, wantStatus: http.StatusNotFound},
+		{name: "locked out", body: `{"code":"123456"}`, serviceErr: &models.RetryAfterError{After: 90 * time.Second, Err: models.ErrTooManyRequests}, wantStatus: http.StatusTooManyRequests},
+		{name: "invalid json", body: `{`, wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			manager := new(mockTwoFactorManager)
+			manager.On("Disable", mock.Anything, user, "123456", &models.ClientInfo{IP: "192.0.2.1"}).Return(tt.serviceErr)
+
+			req := httptest.NewRequest(http.MethodDelete, "/api/me/2fa", strings.NewReader(tt.body))
+			ctx := context.WithValue(req.Context(), models.UserContextKey, user)
+			w := httptest.NewRecorder()
+
+			Disable(ctx, slog.New(slog.NewTextHandler(io.Discard, nil)), w, req, manager)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantStatus == http.StatusTooManyRequests {
+				assert.Equal(t, "90", w.Header().Get("Retry-After"))
+			}
+		})
+	}
+}
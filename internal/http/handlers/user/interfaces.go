@@ -5,5 +5,5 @@ import "context"
 const pkg = "userHandler/"
 
 type AuthService interface {
-	Register(ctx context.Context, login string, password string) error
+	Register(ctx context.Context, login string, password string, email string) error
 }
@@ -34,13 +34,23 @@ func Add(ctx context.Context, log *slog.Logger, w http.ResponseWriter, r *http.R
 		return
 	}
 
-	err = auth.Register(ctx, userRequest.Login, userRequest.Password)
+	err = auth.Register(ctx, userRequest.Login, userRequest.Password, userRequest.Email)
 	if err != nil {
 		if errors.Is(err, models.ErrUserExists) {
 			log.Warn("failed to register user", slog.String("error", models.ErrUserExists.Error()))
 			utils.WriteJSONError(w, http.StatusConflict, models.ErrUserExists.Error())
 			return
 		}
+		if errors.Is(err, models.ErrEmailExists) {
+			log.Warn("failed to register user", slog.String("error", models.ErrEmailExists.Error()))
+			utils.WriteJSONError(w, http.StatusConflict, models.ErrEmailExists.Error())
+			return
+		}
+		if errors.Is(err, models.ErrInvalidEmail) {
+			log.Warn("failed to register user", slog.String("error", models.ErrInvalidEmail.Error()))
+			utils.WriteJSONError(w, http.StatusBadRequest, models.ErrInvalidEmail.Error())
+			return
+		}
 		if errors.Is(err, models.ErrInvalidParams) {
 			log.Warn("failed to register user", slog.String("error", models.ErrInvalidParams.Error()))
 			utils.WriteJSONError(w, http.StatusBadRequest, models.ErrInvalidParams.Error())
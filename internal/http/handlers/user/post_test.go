@@ -20,8 +20,8 @@ type mockAuth struct {
 	mock.Mock
 }
 
-func (m *mockAuth) Register(ctx context.Context, login string, password string) error {
-	args := m.Called(ctx, login, password)
+func (m *mockAuth) Register(ctx context.Context, login string, password string, email string) error {
+	args := m.Called(ctx, login, password, email)
 	return args.Error(0)
 }
 
@@ -33,7 +33,7 @@ func TestAdd_Success(t *testing.T) {
 	w := httptest.NewRecorder()
 
 	mockAdder := new(mockAuth)
-	mockAdder.On("Register", mock.Anything, "user1", "pass123").Return(nil)
+	mockAdder.On("Register", mock.Anything, "user1", "pass123", "").Return(nil)
 
 	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
 
@@ -70,7 +70,7 @@ func TestAdd_UserExists(t *testing.T) {
 	w := httptest.NewRecorder()
 
 	mockAdder := new(mockAuth)
-	mockAdder.On("Register", mock.Anything, "existing", "pass").Return(models.ErrUserExists)
+	mockAdder.On("Register", mock.Anything, "existing", "pass", "").Return(models.ErrUserExists)
 
 	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
 	Add(req.Context(), logger, w, req, mockAdder)
@@ -87,7 +87,7 @@ func TestAdd_InternalError(t *testing.T) {
 	w := httptest.NewRecorder()
 
 	mockAdder := new(mockAuth)
-	mockAdder.On("Register", mock.Anything, "fail", "pass").Return(errors.New("db down"))
+	mockAdder.On("Register", mock.Anything, "fail", "pass", "").Return(errors.New("db down"))
 
 	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
 	Add(req.Context(), logger, w, req, mockAdder)
@@ -104,7 +104,7 @@ func TestAdd_InvalidParams(t *testing.T) {
 	w := httptest.NewRecorder()
 
 	mockAdder := new(mockAuth)
-	mockAdder.On("Register", mock.Anything, "user1", "").
+	mockAdder.On("Register", mock.Anything, "user1", "", "").
 		Return(models.ErrInvalidParams)
 
 	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
@@ -117,3 +117,20 @@ func TestAdd_InvalidParams(t *testing.T) {
 	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
 	mockAdder.AssertExpectations(t)
 }
+
+func TestAdd_EmailTaken(t *testing.T) {
+	t.Parallel()
+
+	body := `{"login": "user1", "password": "pass", "email": "user@example.com"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/register", strings.NewReader(body))
+	w := httptest.NewRecorder()
+
+	mockAdder := new(mockAuth)
+	mockAdder.On("Register", mock.Anything, "user1", "pass", "user@example.com").Return(models.ErrEmailExists)
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	Add(req.Context(), logger, w, req, mockAdder)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	mockAdder.AssertExpectations(t)
+}
@@ -10,84 +10,140 @@ import (
 	"strings"
 )
 
-func AuthOptional(log *slog.Logger, userProvider UserProvider) func(http.Handler) http.Handler {
+const (
+	schemeBearer = "Bearer"
+	schemeAPIKey = "ApiKey"
+)
+
+// AuthOptional puts the user into the context when the request carries a
+// session token or, if apiKeys is set, an API key. Without apiKeys an API key
+// is left for the route's own middleware, so the request stays anonymous
+// unless the route accepts keys. A request that is already authenticated is
+// passed through as is.
+func AuthOptional(log *slog.Logger, userProvider UserProvider, apiKeys APIKeyAuthenticator) func(http.Handler) http.Handler {
 	log = log.With("op", "auth middleware")
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") == "" || r.Context().Value(models.UserContextKey) != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
 
-			var token string
-
-			authHeader := r.Header.Get("Authorization")
-			if authHeader != "" {
-				parts := strings.Split(authHeader, " ")
-				if len(parts) != 2 || parts[0] != "Bearer" {
-					log.Debug("invalid Authorization format")
-					http.Error(w, "invalid Authorization format", http.StatusUnauthorized)
-					return
-				}
-
-				token = parts[1]
+			if apiKeys == nil && authScheme(r) == schemeAPIKey {
+				next.ServeHTTP(w, r)
+				return
 			}
 
-			if token != "" {
-				user, err := userProvider.UserByToken(r.Context(), token)
-				if err != nil {
-					if errors.Is(err, models.ErrInvalidCredentials) {
-						log.Warn("token in not valid", slog.String("path", r.URL.Path))
-						utils.WriteJSONError(w, http.StatusUnauthorized, "invalid token")
-						return
-					}
-					utils.WriteJSONError(w, http.StatusInternalServerError, "internal server error")
-					return
-				}
-
-				ctx := context.WithValue(r.Context(), models.UserContextKey, user)
-				ctx = context.WithValue(ctx, models.TokenContextKey, token)
-
-				next.ServeHTTP(w, r.WithContext(ctx))
+			ctx, ok := authenticate(log, w, r, userProvider, apiKeys)
+			if !ok {
 				return
 			}
 
-			next.ServeHTTP(w, r)
+			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
 }
 
-func AuthRequired(log *slog.Logger, userProvider UserProvider) func(http.Handler) http.Handler {
+// AuthRequired rejects requests without a valid session token. API keys are
+// accepted only if apiKeys is set, so routes opt in to them explicitly.
+func AuthRequired(log *slog.Logger, userProvider UserProvider, apiKeys APIKeyAuthenticator) func(http.Handler) http.Handler {
 	log = log.With("op", "auth middleware")
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			authHeader := r.Header.Get("Authorization")
-			if authHeader == "" {
+			if r.Header.Get("Authorization") == "" {
 				log.Debug("missing authorization header")
 				utils.WriteJSONError(w, http.StatusUnauthorized, "missing authorization header")
 				return
 			}
 
-			parts := strings.Split(authHeader, " ")
-			if len(parts) != 2 || parts[0] != "Bearer" {
-				log.Debug("invalid Authorization format")
-				utils.WriteJSONError(w, http.StatusUnauthorized, "invalid authorization format")
+			ctx, ok := authenticate(log, w, r, userProvider, apiKeys)
+			if !ok {
 				return
 			}
 
-			token := parts[1]
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
 
-			user, err := userProvider.UserByToken(r.Context(), token)
-			if err != nil {
-				if errors.Is(err, models.ErrInvalidCredentials) {
-					log.Warn("failed to get user by token", slog.String("path", r.URL.Path))
-					utils.WriteJSONError(w, http.StatusUnauthorized, "invalid token")
-					return
-				}
-				utils.WriteJSONError(w, http.StatusInternalServerError, "internal server error")
-				return
+func authScheme(r *http.Request) string {
+	scheme, _, _ := strings.Cut(r.Header.Get("Authorization"), " ")
+	return scheme
+}
+
+// authenticate resolves the Authorization header into a context with the
+// user. On failure it writes the response itself and returns false.
+func authenticate(log *slog.Logger, w http.ResponseWriter, r *http.Request, userProvider UserProvider, apiKeys APIKeyAuthenticator) (context.Context, bool) {
+	parts := strings.Split(r.Header.Get("Authorization"), " ")
+	if len(parts) != 2 || parts[1] == "" {
+		log.Debug("invalid Authorization format")
+		utils.WriteJSONError(w, http.StatusUnauthorized, "invalid authorization format")
+		return nil, false
+	}
+
+	switch parts[0] {
+	case schemeBearer:
+		token := parts[1]
+
+		user, err := userProvider.UserByToken(r.Context(), token)
+		if err != nil {
+			if errors.Is(err, models.ErrInvalidCredentials) {
+				log.Warn("failed to get user by token", slog.String("path", r.URL.Path))
+				utils.WriteJSONError(w, http.StatusUnauthorized, "invalid token")
+				return nil, false
+			}
+			if errors.Is(err, models.ErrUserBanned) {
+				log.Warn("banned user tried to use token", slog.String("path", r.URL.Path))
+				utils.WriteJSONError(w, http.StatusForbidden, models.ErrUserBanned.Error())
+				return nil, false
 			}
+			utils.WriteJSONError(w, http.StatusInternalServerError, "internal server error")
+			return nil, false
+		}
 
-			ctx := context.WithValue(r.Context(), models.UserContextKey, user)
-			ctx = context.WithValue(ctx, models.TokenContextKey, token)
+		ctx := context.WithValue(r.Context(), models.UserContextKey, user)
+		ctx = context.WithValue(ctx, models.TokenContextKey, token)
+		ctx = context.WithValue(ctx, models.RoleContextKey, user.Role)
 
-			next.ServeHTTP(w, r.WithContext(ctx))
-		})
+		return ctx, true
+
+	case schemeAPIKey:
+		if apiKeys == nil {
+			log.Info("api key used on a route that does not accept it", slog.String("path", r.URL.Path))
+			utils.WriteJSONError(w, http.StatusForbidden, models.ErrAPIKeyNotAllowed.Error())
+			return nil, false
+		}
+
+		user, key, err := apiKeys.AuthenticateAPIKey(r.Context(), parts[1])
+		if err != nil {
+			if errors.Is(err, models.ErrInvalidCredentials) {
+				log.Warn("failed to get user by api key", slog.String("path", r.URL.Path))
+				utils.WriteJSONError(w, http.StatusUnauthorized, "invalid api key")
+				return nil, false
+			}
+			if errors.Is(err, models.ErrUserBanned) {
+				log.Warn("banned user used an api key", slog.String("path", r.URL.Path))
+				utils.WriteJSONError(w, http.StatusForbidden, models.ErrUserBanned.Error())
+				return nil, false
+			}
+			utils.WriteJSONError(w, http.StatusInternalServerError, "internal server error")
+			return nil, false
+		}
+
+		// API keys act with the rights of a plain user, whatever the role
+		// of their owner.
+		keyUser := *user
+		keyUser.Role = models.RoleUser
+
+		ctx := context.WithValue(r.Context(), models.UserContextKey, &keyUser)
+		ctx = context.WithValue(ctx, models.APIKeyContextKey, key)
+		ctx = context.WithValue(ctx, models.RoleContextKey, keyUser.Role)
+
+		return ctx, true
+
+	default:
+		log.Debug("unknown authorization scheme")
+		utils.WriteJSONError(w, http.StatusUnauthorized, "invalid authorization format")
+		return nil, false
 	}
 }
@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"errors"
+	"log/slog"
+	"marketplace/internal/models"
+	utils "marketplace/internal/utils/http_errors"
+	"net/http"
+)
+
+// RequireVerifiedEmail lets the request through only if the checker allows the
+// authenticated user to post. It must run after AuthRequired.
+func RequireVerifiedEmail(log *slog.Logger, checker EmailVerificationChecker) func(http.Handler) http.Handler {
+	log = log.With("op", "verified email middleware")
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, ok := r.Context().Value(models.UserContextKey).(*models.User)
+			if !ok {
+				log.Error("failed to parse user from context")
+				utils.WriteJSONError(w, http.StatusInternalServerError, "internal server error")
+				return
+			}
+
+			err := checker.CheckCanPost(r.Context(), user)
+			if err != nil {
+				if errors.Is(err, models.ErrEmailNotVerified) {
+					log.Info("email not verified", slog.String("path", r.URL.Path))
+					utils.WriteJSONError(w, http.StatusForbidden, models.ErrEmailNotVerified.Error())
+					return
+				}
+				utils.WriteJSONError(w, http.StatusInternalServerError, "internal server error")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
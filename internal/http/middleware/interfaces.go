@@ -8,3 +8,11 @@ import (
 type UserProvider interface {
 	UserByToken(ctx context.Context, token string) (*models.User, error)
 }
+
+type EmailVerificationChecker interface {
+	CheckCanPost(ctx context.Context, requester *models.User) error
+}
+
+type APIKeyAuthenticator interface {
+	AuthenticateAPIKey(ctx context.Context, key string) (*models.User, *models.APIKey, error)
+}
@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"log/slog"
+	"marketplace/internal/models"
+	utils "marketplace/internal/utils/http_errors"
+	"net/http"
+	"slices"
+)
+
+// RequireRole lets the request through only if the authenticated user has
+// one of the roles. It must run after AuthRequired.
+func RequireRole(log *slog.Logger, roles ...models.Role) func(http.Handler) http.Handler {
+	log = log.With("op", "role middleware")
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			role, ok := r.Context().Value(models.RoleContextKey).(models.Role)
+			if !ok {
+				log.Error("failed to parse role from context")
+				utils.WriteJSONError(w, http.StatusInternalServerError, "internal server error")
+				return
+			}
+
+			if !slices.Contains(roles, role) {
+				log.Info("role not allowed", slog.String("role", string(role)), slog.String("path", r.URL.Path))
+				utils.WriteJSONError(w, http.StatusForbidden, models.ErrForbidden.Error())
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
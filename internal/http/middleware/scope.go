@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"log/slog"
+	"marketplace/internal/models"
+	utils "marketplace/internal/utils/http_errors"
+	"net/http"
+)
+
+// RequireScope rejects requests made with an API key that lacks the scope.
+// Requests authenticated otherwise, or not at all, are let through, so it
+// goes after AuthOptional or AuthRequired.
+func RequireScope(log *slog.Logger, scope string) func(http.Handler) http.Handler {
+	log = log.With("op", "scope middleware")
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key, ok := r.Context().Value(models.APIKeyContextKey).(*models.APIKey)
+			if ok && !key.HasScope(scope) {
+				log.Info("api key lacks scope", slog.String("scope", scope), slog.String("key_id", key.ID))
+				utils.WriteJSONError(w, http.StatusForbidden, models.ErrInsufficientScope.Error())
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -5,9 +5,28 @@ import (
 	"errors"
 	"log/slog"
 	"marketplace/internal/config"
+	accounthandler "marketplace/internal/http/handlers/account"
+	adminhandler "marketplace/internal/http/handlers/admin"
+	apikeyhandler "marketplace/internal/http/handlers/apikey"
+	auctionhandler "marketplace/internal/http/handlers/auction"
+	banhandler "marketplace/internal/http/handlers/ban"
+	blockhandler "marketplace/internal/http/handlers/block"
+	conversationhandler "marketplace/internal/http/handlers/conversation"
+	emailhandler "marketplace/internal/http/handlers/email"
+	eventshandler "marketplace/internal/http/handlers/events"
+	feedhandler "marketplace/internal/http/handlers/feed"
 	healthhandler "marketplace/internal/http/handlers/health"
+	notificationhandler "marketplace/internal/http/handlers/notification"
+	offerhandler "marketplace/internal/http/handlers/offer"
+	orderhandler "marketplace/internal/http/handlers/order"
+	passwordhandler "marketplace/internal/http/handlers/password"
 	postshandler "marketplace/internal/http/handlers/posts"
+	profilehandler "marketplace/internal/http/handlers/profile"
+	reporthandler "marketplace/internal/http/handlers/report"
+	reviewhandler "marketplace/internal/http/handlers/review"
+	searchhandler "marketplace/internal/http/handlers/search"
 	sessionhandler "marketplace/internal/http/handlers/session"
+	twofactorhandler "marketplace/internal/http/handlers/twofactor"
 	userhandler "marketplace/internal/http/handlers/user"
 	"marketplace/internal/http/middleware"
 	"marketplace/internal/models"
@@ -18,19 +37,39 @@ import (
 	"github.com/gorilla/mux"
 )
 
-func StartServer(
-	ctx context.Context,
-	cfg *config.HTTPServer,
-	log *slog.Logger,
-	authService AuthService,
-	postService PostService,
-) error {
+// Services are the services the routes are served by.
+type Services struct {
+	Auth         AuthService
+	Post         PostService
+	Search       SearchService
+	Notification NotificationService
+	Events       EventService
+	Feed         FeedService
+	Conversation ConversationService
+	Offer        OfferService
+	Auction      AuctionService
+	Order        OrderService
+	Review       ReviewService
+	Profile      ProfileService
+	Password     PasswordService
+	Email        EmailService
+	TwoFactor    TwoFactorService
+	OIDC         OIDCService
+	APIKeys      APIKeyService
+	Admin        AdminService
+	Bans         BanService
+	Blocks       BlockService
+	Account      AccountService
+	Reports      ReportService
+}
+
+func StartServer(ctx context.Context, cfg *config.HTTPServer, log *slog.Logger, services *Services) error {
 	r := mux.NewRouter()
 
 	r.Use(middleware.Logger(log))
-	r.Use(middleware.AuthOptional(log, authService))
+	r.Use(middleware.AuthOptional(log, services.Auth, nil))
 
-	setupRoutes(r, log, authService, postService)
+	setupRoutes(ctx, r, log, cfg, services)
 
 	srv := &http.Server{
 		Addr:         cfg.Address,
@@ -72,52 +111,504 @@ func StartServer(
 
 }
 
-func setupRoutes(r *mux.Router, log *slog.Logger, auth AuthService, post PostService) {
+func setupRoutes(appCtx context.Context, r *mux.Router, log *slog.Logger, cfg *config.HTTPServer, services *Services) {
 
 	// POST user
 	r.HandleFunc("/api/register", func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
-		userhandler.Add(ctx, log, w, r, auth)
+		userhandler.Add(ctx, log, w, r, services.Auth)
 	}).Methods(http.MethodPost)
 
 	// POST session
 	r.HandleFunc("/api/auth", func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
-		sessionhandler.Add(ctx, log, w, r, auth)
+		sessionhandler.Add(ctx, log, w, r, services.Auth)
+	}).Methods(http.MethodPost)
+
+	// POST session refresh
+	r.HandleFunc("/api/auth/refresh", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		sessionhandler.Refresh(ctx, log, w, r, services.Auth)
+	}).Methods(http.MethodPost)
+
+	// POST session second factor
+	r.HandleFunc("/api/auth/2fa", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		sessionhandler.CompleteTwoFactor(ctx, log, w, r, services.Auth)
 	}).Methods(http.MethodPost)
 
+	// GET external login
+	r.HandleFunc("/api/auth/oidc/{provider}", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		sessionhandler.BeginExternal(ctx, log, w, r, services.OIDC)
+	}).Methods(http.MethodGet)
+
+	// GET external login callback
+	r.HandleFunc("/api/auth/oidc/{provider}/callback", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		sessionhandler.ExternalCallback(ctx, log, w, r, services.OIDC)
+	}).Methods(http.MethodGet)
+
 	// DELETE session
 	r.HandleFunc("/api/auth", func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
-		sessionhandler.Delete(ctx, log, w, r, auth)
+		sessionhandler.Delete(ctx, log, w, r, services.Auth)
 	}).Methods(http.MethodDelete)
 
+	// POST password reset request
+	r.HandleFunc("/api/password/reset", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		passwordhandler.Reset(ctx, log, w, r, services.Password)
+	}).Methods(http.MethodPost)
+
+	// POST password reset confirm
+	r.HandleFunc("/api/password/reset/confirm", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		passwordhandler.ConfirmReset(ctx, log, w, r, services.Password)
+	}).Methods(http.MethodPost)
+
+	// POST email verify
+	r.HandleFunc("/api/email/verify", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		emailhandler.Verify(ctx, log, w, r, services.Email)
+	}).Methods(http.MethodPost)
+
+	// Listing posts is public, but integrations may also call it with an API
+	// key. No other public route accepts keys.
+	keyOptional := middleware.AuthOptional(log, services.Auth, services.APIKeys)
+
 	// GET posts
-	r.HandleFunc("/api/posts", func(w http.ResponseWriter, r *http.Request) {
+	r.Handle("/api/posts", keyOptional(middleware.RequireScope(log, models.ScopePostsRead)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
-		postshandler.Get(ctx, log, w, r, post)
-	}).Methods(http.MethodGet)
+		postshandler.Get(ctx, log, w, r, services.Post)
+	})))).Methods(http.MethodGet)
 
 	// HEAD posts
-	r.HandleFunc("/api/posts", func(w http.ResponseWriter, r *http.Request) {
+	r.Handle("/api/posts", keyOptional(middleware.RequireScope(log, models.ScopePostsRead)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		postshandler.Head(ctx, log, w, r, services.Post)
+	})))).Methods(http.MethodHead)
+
+	// GET user profile
+	r.HandleFunc("/api/users/{login}", func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
-		postshandler.Head(ctx, log, w, r, post)
-	}).Methods(http.MethodHead)
+		profilehandler.Get(ctx, log, w, r, services.Profile)
+	}).Methods(http.MethodGet)
+
+	// GET user reviews
+	r.HandleFunc("/api/users/{login}/reviews", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		reviewhandler.Get(ctx, log, w, r, services.Review)
+	}).Methods(http.MethodGet)
+
+	// GET live posts feed
+	r.HandleFunc("/api/posts/live", func(w http.ResponseWriter, r *http.Request) {
+		// Browsers cannot send the Authorization header with the handshake,
+		// so the handler also takes a ticket instead.
+		ctx, cancel := context.WithCancel(r.Context())
+		defer cancel()
+		stop := context.AfterFunc(appCtx, cancel)
+		defer stop()
+
+		feedhandler.Get(ctx, log, w, r, services.Feed, cfg.WSPing, cfg.WSOrigins)
+	}).Methods(http.MethodGet)
 
 	// GET health
 	r.HandleFunc("/api/health", func(w http.ResponseWriter, r *http.Request) {
 		healthhandler.Get(w, r)
 	}).Methods(http.MethodGet)
 
-	requiredAuth := r.NewRoute().Subrouter()
-	requiredAuth.Use(middleware.AuthRequired(log, auth))
+	// Routes integrations may call with an API key, each checking its scope.
+	scopedAuth := r.NewRoute().Subrouter()
+	scopedAuth.Use(middleware.AuthRequired(log, services.Auth, services.APIKeys))
 
 	// POST posts
-	requiredAuth.HandleFunc("/api/posts", func(w http.ResponseWriter, r *http.Request) {
+	scopedAuth.Handle("/api/posts", middleware.RequireScope(log, models.ScopePostsWrite)(middleware.RequireVerifiedEmail(log, services.Email)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		postshandler.Add(ctx, log, w, r, services.Post)
+	})))).Methods(http.MethodPost)
+
+	// PATCH post
+	scopedAuth.Handle("/api/posts/{id}", middleware.RequireScope(log, models.ScopePostsWrite)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		postshandler.Update(ctx, log, w, r, services.Post)
+	}))).Methods(http.MethodPatch)
+
+	// DELETE post
+	scopedAuth.Handle("/api/posts/{id}", middleware.RequireScope(log, models.ScopePostsWrite)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		postshandler.Delete(ctx, log, w, r, services.Post)
+	}))).Methods(http.MethodDelete)
+
+	requiredAuth := r.NewRoute().Subrouter()
+	requiredAuth.Use(middleware.AuthRequired(log, services.Auth, nil))
+
+	// GET job status
+	requiredAuth.HandleFunc("/api/jobs/{id}", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		accounthandler.Job(ctx, log, w, r, services.Account)
+	}).Methods(http.MethodGet)
+
+	// POST live posts feed ticket
+	requiredAuth.HandleFunc("/api/posts/live/ticket", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		feedhandler.Ticket(ctx, log, w, r, services.Feed)
+	}).Methods(http.MethodPost)
+
+	// GET events
+	requiredAuth.HandleFunc("/api/me/events", func(w http.ResponseWriter, r *http.Request) {
+		// Shutdown waits for active requests, so streams have to end together
+		// with the application.
+		ctx, cancel := context.WithCancel(r.Context())
+		defer cancel()
+		stop := context.AfterFunc(appCtx, cancel)
+		defer stop()
+
+		eventshandler.Get(ctx, log, w, r, services.Events, cfg.SSEHeartbeat)
+	}).Methods(http.MethodGet)
+
+	// GET saved searches
+	requiredAuth.HandleFunc("/api/me/searches", func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
-		postshandler.Add(ctx, log, w, r, post)
+		searchhandler.Get(ctx, log, w, r, services.Search)
+	}).Methods(http.MethodGet)
+
+	// POST saved search
+	requiredAuth.HandleFunc("/api/me/searches", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		searchhandler.Add(ctx, log, w, r, services.Search)
+	}).Methods(http.MethodPost)
+
+	// GET saved search
+	requiredAuth.HandleFunc("/api/me/searches/{id}", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		searchhandler.GetByID(ctx, log, w, r, services.Search)
+	}).Methods(http.MethodGet)
+
+	// PUT saved search
+	requiredAuth.HandleFunc("/api/me/searches/{id}", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		searchhandler.Update(ctx, log, w, r, services.Search)
+	}).Methods(http.MethodPut)
+
+	// DELETE saved search
+	requiredAuth.HandleFunc("/api/me/searches/{id}", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		searchhandler.Delete(ctx, log, w, r, services.Search)
+	}).Methods(http.MethodDelete)
+
+	// GET saved search matches
+	requiredAuth.HandleFunc("/api/me/searches/{id}/matches", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		searchhandler.Matches(ctx, log, w, r, services.Search)
+	}).Methods(http.MethodGet)
+
+	// POST saved search matches seen
+	requiredAuth.HandleFunc("/api/me/searches/{id}/matches/seen", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		searchhandler.MarkSeen(ctx, log, w, r, services.Search)
+	}).Methods(http.MethodPost)
+
+	// GET notifications
+	requiredAuth.HandleFunc("/api/me/notifications", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		notificationhandler.Get(ctx, log, w, r, services.Notification)
+	}).Methods(http.MethodGet)
+
+	// POST notifications read
+	requiredAuth.HandleFunc("/api/me/notifications/read", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		notificationhandler.MarkAllRead(ctx, log, w, r, services.Notification)
+	}).Methods(http.MethodPost)
+
+	// POST notification read
+	requiredAuth.HandleFunc("/api/me/notifications/{id}/read", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		notificationhandler.MarkRead(ctx, log, w, r, services.Notification)
+	}).Methods(http.MethodPost)
+
+	// GET conversations
+	requiredAuth.HandleFunc("/api/conversations", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		conversationhandler.Get(ctx, log, w, r, services.Conversation)
+	}).Methods(http.MethodGet)
+
+	// POST conversation
+	requiredAuth.HandleFunc("/api/conversations", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		conversationhandler.Add(ctx, log, w, r, services.Conversation)
+	}).Methods(http.MethodPost)
+
+	// GET conversation messages
+	requiredAuth.HandleFunc("/api/conversations/{id}/messages", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		conversationhandler.Messages(ctx, log, w, r, services.Conversation)
+	}).Methods(http.MethodGet)
+
+	// POST conversation message
+	requiredAuth.HandleFunc("/api/conversations/{id}/messages", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		conversationhandler.SendMessage(ctx, log, w, r, services.Conversation)
 	}).Methods(http.MethodPost)
 
+	// GET post offers
+	requiredAuth.HandleFunc("/api/posts/{id}/offers", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		offerhandler.Get(ctx, log, w, r, services.Offer)
+	}).Methods(http.MethodGet)
+
+	// POST post offer
+	requiredAuth.HandleFunc("/api/posts/{id}/offers", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		offerhandler.Add(ctx, log, w, r, services.Offer)
+	}).Methods(http.MethodPost)
+
+	// POST offer accept
+	requiredAuth.HandleFunc("/api/posts/{id}/offers/{offerID}/accept", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		offerhandler.Accept(ctx, log, w, r, services.Offer)
+	}).Methods(http.MethodPost)
+
+	// POST offer reject
+	requiredAuth.HandleFunc("/api/posts/{id}/offers/{offerID}/reject", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		offerhandler.Reject(ctx, log, w, r, services.Offer)
+	}).Methods(http.MethodPost)
+
+	// POST offer counter
+	requiredAuth.HandleFunc("/api/posts/{id}/offers/{offerID}/counter", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		offerhandler.Counter(ctx, log, w, r, services.Offer)
+	}).Methods(http.MethodPost)
+
+	// POST post bid
+	requiredAuth.HandleFunc("/api/posts/{id}/bids", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		auctionhandler.Bid(ctx, log, w, r, services.Auction)
+	}).Methods(http.MethodPost)
+
+	// GET my offers
+	requiredAuth.HandleFunc("/api/me/offers", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		offerhandler.Mine(ctx, log, w, r, services.Offer)
+	}).Methods(http.MethodGet)
+
+	// POST post order
+	requiredAuth.HandleFunc("/api/posts/{id}/orders", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		orderhandler.Add(ctx, log, w, r, services.Order)
+	}).Methods(http.MethodPost)
+
+	// GET order
+	requiredAuth.HandleFunc("/api/orders/{id}", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		orderhandler.Get(ctx, log, w, r, services.Order)
+	}).Methods(http.MethodGet)
+
+	// POST pay order
+	requiredAuth.HandleFunc("/api/orders/{id}/pay", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		orderhandler.Pay(ctx, log, w, r, services.Order)
+	}).Methods(http.MethodPost)
+
+	// POST complete order
+	requiredAuth.HandleFunc("/api/orders/{id}/complete", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		orderhandler.Complete(ctx, log, w, r, services.Order)
+	}).Methods(http.MethodPost)
+
+	// POST cancel order
+	requiredAuth.HandleFunc("/api/orders/{id}/cancel", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		orderhandler.Cancel(ctx, log, w, r, services.Order)
+	}).Methods(http.MethodPost)
+
+	// GET my orders
+	requiredAuth.HandleFunc("/api/me/orders", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		orderhandler.Mine(ctx, log, w, r, services.Order)
+	}).Methods(http.MethodGet)
+
+	// GET my profile
+	requiredAuth.HandleFunc("/api/me", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		profilehandler.Me(ctx, log, w, r, services.Profile)
+	}).Methods(http.MethodGet)
+
+	// PATCH my profile
+	requiredAuth.HandleFunc("/api/me", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		profilehandler.Update(ctx, log, w, r, services.Profile)
+	}).Methods(http.MethodPatch)
+
+	// POST my password
+	requiredAuth.HandleFunc("/api/me/password", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		passwordhandler.Change(ctx, log, w, r, services.Password)
+	}).Methods(http.MethodPost)
+
+	// POST resend email verification
+	requiredAuth.HandleFunc("/api/me/email/verification", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		emailhandler.Resend(ctx, log, w, r, services.Email)
+	}).Methods(http.MethodPost)
+
+	// POST my two-factor enrollment
+	requiredAuth.HandleFunc("/api/me/2fa", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		twofactorhandler.Enroll(ctx, log, w, r, services.TwoFactor)
+	}).Methods(http.MethodPost)
+
+	// POST my two-factor confirmation
+	requiredAuth.HandleFunc("/api/me/2fa/confirm", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		twofactorhandler.Confirm(ctx, log, w, r, services.TwoFactor)
+	}).Methods(http.MethodPost)
+
+	// DELETE my two-factor
+	requiredAuth.HandleFunc("/api/me/2fa", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		twofactorhandler.Disable(ctx, log, w, r, services.TwoFactor)
+	}).Methods(http.MethodDelete)
+
+	// GET my sessions
+	requiredAuth.HandleFunc("/api/me/sessions", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		sessionhandler.List(ctx, log, w, r, services.Auth)
+	}).Methods(http.MethodGet)
+
+	// DELETE my sessions
+	requiredAuth.HandleFunc("/api/me/sessions", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		sessionhandler.RevokeAll(ctx, log, w, r, services.Auth)
+	}).Methods(http.MethodDelete)
+
+	// DELETE my session
+	requiredAuth.HandleFunc("/api/me/sessions/{id}", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		sessionhandler.Revoke(ctx, log, w, r, services.Auth)
+	}).Methods(http.MethodDelete)
+
+	// GET my api keys
+	requiredAuth.HandleFunc("/api/me/api-keys", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		apikeyhandler.Get(ctx, log, w, r, services.APIKeys)
+	}).Methods(http.MethodGet)
+
+	// POST my api key
+	requiredAuth.HandleFunc("/api/me/api-keys", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		apikeyhandler.Add(ctx, log, w, r, services.APIKeys)
+	}).Methods(http.MethodPost)
+
+	// DELETE my api key
+	requiredAuth.HandleFunc("/api/me/api-keys/{id}", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		apikeyhandler.Delete(ctx, log, w, r, services.APIKeys)
+	}).Methods(http.MethodDelete)
+
+	// PUT user ban
+	requiredAuth.HandleFunc("/api/users/{login}/ban", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		banhandler.Ban(ctx, log, w, r, services.Bans)
+	}).Methods(http.MethodPut)
+
+	// DELETE user ban
+	requiredAuth.HandleFunc("/api/users/{login}/ban", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		banhandler.Unban(ctx, log, w, r, services.Bans)
+	}).Methods(http.MethodDelete)
+
+	// PUT user block
+	requiredAuth.HandleFunc("/api/users/{login}/block", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		blockhandler.Block(ctx, log, w, r, services.Blocks)
+	}).Methods(http.MethodPut)
+
+	// DELETE user block
+	requiredAuth.HandleFunc("/api/users/{login}/block", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		blockhandler.Unblock(ctx, log, w, r, services.Blocks)
+	}).Methods(http.MethodDelete)
+
+	// GET personal data export
+	requiredAuth.HandleFunc("/api/me/export", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		accounthandler.Export(ctx, log, w, r, services.Account)
+	}).Methods(http.MethodGet)
+
+	// DELETE account
+	requiredAuth.HandleFunc("/api/me", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		accounthandler.Delete(ctx, log, w, r, services.Account)
+	}).Methods(http.MethodDelete)
+
+	// POST post report
+	requiredAuth.HandleFunc("/api/posts/{id}/reports", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		reporthandler.Add(ctx, log, w, r, services.Reports)
+	}).Methods(http.MethodPost)
+
+	// The report queue follows the moderator allowlist from config rather than
+	// roles, so it is registered before the admin-only subrouter.
+
+	// GET open reports
+	requiredAuth.HandleFunc("/api/admin/reports", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		reporthandler.Queue(ctx, log, w, r, services.Reports)
+	}).Methods(http.MethodGet)
+
+	// POST resolve report
+	requiredAuth.HandleFunc("/api/admin/reports/{id}/resolve", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		reporthandler.Resolve(ctx, log, w, r, services.Reports)
+	}).Methods(http.MethodPost)
+
+	// POST dismiss report
+	requiredAuth.HandleFunc("/api/admin/reports/{id}/dismiss", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		reporthandler.Dismiss(ctx, log, w, r, services.Reports)
+	}).Methods(http.MethodPost)
+
+	adminOnly := requiredAuth.PathPrefix("/api/admin").Subrouter()
+	adminOnly.Use(middleware.RequireRole(log, models.RoleAdmin))
+
+	// PUT user role
+	adminOnly.HandleFunc("/users/{login}/role", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		adminhandler.SetRole(ctx, log, w, r, services.Admin)
+	}).Methods(http.MethodPut)
+
+	// POST post review
+	requiredAuth.HandleFunc("/api/posts/{id}/reviews", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		reviewhandler.Add(ctx, log, w, r, services.Review)
+	}).Methods(http.MethodPost)
+
+	// PUT review reply
+	requiredAuth.HandleFunc("/api/reviews/{id}/reply", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		reviewhandler.Reply(ctx, log, w, r, services.Review)
+	}).Methods(http.MethodPut)
+
+	// POST approve review reply
+	requiredAuth.HandleFunc("/api/reviews/{id}/reply/approve", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		reviewhandler.Approve(ctx, log, w, r, services.Review)
+	}).Methods(http.MethodPost)
+
+	// POST reject review reply
+	requiredAuth.HandleFunc("/api/reviews/{id}/reply/reject", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		reviewhandler.Reject(ctx, log, w, r, services.Review)
+	}).Methods(http.MethodPost)
+
+	// GET pending review replies
+	requiredAuth.HandleFunc("/api/reviews/replies/pending", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		reviewhandler.Pending(ctx, log, w, r, services.Review)
+	}).Methods(http.MethodGet)
+
 	// Not allowed
 	r.MethodNotAllowedHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		utils.WriteJSONError(w, http.StatusMethodNotAllowed, models.ErrMethodNotAllowed.Error())
@@ -0,0 +1,38 @@
+package capturemailer
+
+import (
+	"context"
+	"marketplace/internal/models"
+	"sync"
+)
+
+// Mailer keeps sent mails in memory instead of delivering them, so that tests
+// can read the links they contain.
+type Mailer struct {
+	mu    sync.Mutex
+	mails []models.Mail
+}
+
+func New() *Mailer {
+	return &Mailer{}
+}
+
+func (m *Mailer) Send(ctx context.Context, mail *models.Mail) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.mails = append(m.mails, *mail)
+
+	return nil
+}
+
+// Mails returns the mails sent so far, oldest first.
+func (m *Mailer) Mails() []models.Mail {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	mails := make([]models.Mail, len(m.mails))
+	copy(mails, m.mails)
+
+	return mails
+}
@@ -0,0 +1,50 @@
+package filemailer
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"marketplace/internal/models"
+	"os"
+	"path/filepath"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+)
+
+const pkg = "fileMailer/"
+
+// Mailer writes every mail to a file in dir instead of sending it, so that
+// links from the mails can be followed on local runs.
+type Mailer struct {
+	log *slog.Logger
+	dir string
+}
+
+func New(log *slog.Logger, dir string) *Mailer {
+	return &Mailer{
+		log: log,
+		dir: dir,
+	}
+}
+
+func (m *Mailer) Send(ctx context.Context, mail *models.Mail) error {
+	op := pkg + "Send"
+
+	if err := os.MkdirAll(m.dir, 0o755); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	name := time.Now().UTC().Format("20060102T150405") + "_" + uuid.NewV4().String() + ".eml"
+	path := filepath.Join(m.dir, name)
+
+	content := fmt.Sprintf("To: %s\r\nSubject: %s\r\n\r\n%s", mail.To, mail.Subject, mail.Body)
+
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	m.log.Info("mail written", slog.String("op", op), slog.String("to", mail.To), slog.String("path", path))
+
+	return nil
+}
@@ -0,0 +1,35 @@
+package filemailer
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"marketplace/internal/models"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSend(t *testing.T) {
+	t.Parallel()
+
+	dir := filepath.Join(t.TempDir(), "mails")
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	m := New(logger, dir)
+
+	err := m.Send(context.Background(), &models.Mail{To: "user@example.com", Subject: "Hello", Body: "body text"})
+	assert.NoError(t, err)
+
+	entries, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	content, err := os.ReadFile(filepath.Join(dir, entries[0].Name()))
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), "To: user@example.com")
+	assert.Contains(t, string(content), "Subject: Hello")
+	assert.Contains(t, string(content), "body text")
+}
@@ -0,0 +1,57 @@
+package smtpmailer
+
+import (
+	"context"
+	"fmt"
+	"marketplace/internal/models"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+)
+
+const pkg = "smtpMailer/"
+
+type Mailer struct {
+	addr string
+	from string
+	auth smtp.Auth
+}
+
+func New(host string, port int, username string, password string, from string) *Mailer {
+	var auth smtp.Auth
+	if username != "" {
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+
+	return &Mailer{
+		addr: net.JoinHostPort(host, strconv.Itoa(port)),
+		from: from,
+		auth: auth,
+	}
+}
+
+func (m *Mailer) Send(ctx context.Context, mail *models.Mail) error {
+	op := pkg + "Send"
+
+	err := smtp.SendMail(m.addr, m.auth, m.from, []string{mail.To}, message(m.from, mail))
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func message(from string, mail *models.Mail) []byte {
+	var b strings.Builder
+
+	b.WriteString("From: " + from + "\r\n")
+	b.WriteString("To: " + mail.To + "\r\n")
+	b.WriteString("Subject: " + mail.Subject + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(mail.Body)
+
+	return []byte(b.String())
+}
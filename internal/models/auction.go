@@ -0,0 +1,63 @@
+package models
+
+import "time"
+
+// Auction holds the bidding state of a post sold in auction mode. Leader and
+// winner are kept as logins, since posts are cached and broadcast as JSON.
+type Auction struct {
+	StartPrice   int64      `json:"start_price"`
+	MinIncrement int64      `json:"min_increment"`
+	EndsAt       time.Time  `json:"ends_at"`
+	CurrentBid   int64      `json:"current_bid,omitempty"`
+	BidCount     int        `json:"bid_count,omitempty"`
+	LeaderID     string     `json:"-"`
+	LeaderLogin  string     `json:"leader_login,omitempty"`
+	ClosedAt     *time.Time `json:"closed_at,omitempty"`
+}
+
+// MinimumBid returns the smallest amount the next bid has to reach.
+func (a *Auction) MinimumBid() int64 {
+	if a.BidCount == 0 {
+		return a.StartPrice
+	}
+
+	return a.CurrentBid + a.MinIncrement
+}
+
+// IsOpen reports whether bids are still accepted at now.
+func (a *Auction) IsOpen(now time.Time) bool {
+	return a.ClosedAt == nil && now.Before(a.EndsAt)
+}
+
+// Extend pushes the end time back when a bid arrives within window of the
+// end, so that nobody can win by bidding in the last second. The end never
+// moves earlier, even when extension is shorter than window.
+func (a *Auction) Extend(now time.Time, window time.Duration, extension time.Duration) {
+	if a.EndsAt.Sub(now) >= window {
+		return
+	}
+
+	if next := now.Add(extension); next.After(a.EndsAt) {
+		a.EndsAt = next
+	}
+}
+
+type Bid struct {
+	ID          string
+	PostID      string
+	BidderID    string
+	BidderLogin string
+	Amount      int64
+	CreatedAt   time.Time
+}
+
+// AuctionResult describes an auction that the closer has just finished.
+// WinnerID is empty when nobody placed a bid.
+type AuctionResult struct {
+	PostID      string
+	SellerID    string
+	WinnerID    string
+	WinnerLogin string
+	Amount      int64
+	ClosedAt    time.Time
+}
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuctionExtend(t *testing.T) {
+	end := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		Name      string
+		BidAt     time.Duration
+		Window    time.Duration
+		Extension time.Duration
+		Want      time.Time
+	}{
+		{Name: "outside window", BidAt: -10 * time.Minute, Window: 5 * time.Minute, Extension: 2 * time.Minute, Want: end},
+		{Name: "last seconds", BidAt: -30 * time.Second, Window: 5 * time.Minute, Extension: 2 * time.Minute, Want: end.Add(90 * time.Second)},
+		{Name: "extension shorter than window", BidAt: -4 * time.Minute, Window: 5 * time.Minute, Extension: 2 * time.Minute, Want: end},
+		{Name: "extension equals window", BidAt: -time.Minute, Window: 2 * time.Minute, Extension: 2 * time.Minute, Want: end.Add(time.Minute)},
+	}
+
+	for _, test := range tests {
+		auction := &Auction{EndsAt: end}
+		auction.Extend(end.Add(test.BidAt), test.Window, test.Extension)
+		assert.Equal(t, test.Want, auction.EndsAt, test.Name)
+	}
+}
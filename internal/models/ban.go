@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+// Ban keeps a user out of the marketplace. A ban without an end is permanent;
+// one with an end is a suspension and lifts by itself.
+type Ban struct {
+	Reason   string
+	Until    *time.Time
+	BannedBy string
+	BannedAt time.Time
+}
+
+// Active reports whether the ban is in force at the moment.
+func (b *Ban) Active(now time.Time) bool {
+	return b.Until == nil || now.Before(*b.Until)
+}
+
+type AuditAction string
+
+const (
+	AuditBan     AuditAction = "ban"
+	AuditSuspend AuditAction = "suspend"
+	AuditUnban   AuditAction = "unban"
+)
+
+// AuditRecord tells who did what to a user account and why.
+type AuditRecord struct {
+	ID        string
+	ActorID   string
+	TargetID  string
+	Action    AuditAction
+	Reason    string
+	ExpiresAt *time.Time
+	CreatedAt time.Time
+}
@@ -5,4 +5,6 @@ type ContextKey int
 const (
 	UserContextKey ContextKey = iota
 	TokenContextKey
+	APIKeyContextKey
+	RoleContextKey
 )
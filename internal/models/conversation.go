@@ -0,0 +1,45 @@
+package models
+
+import "time"
+
+type Conversation struct {
+	ID            string
+	PostID        string
+	PostHeader    string
+	BuyerID       string
+	BuyerLogin    string
+	SellerID      string
+	SellerLogin   string
+	CreatedAt     time.Time
+	LastMessageAt time.Time
+	UnreadCount   int
+}
+
+func (c *Conversation) HasParticipant(userID string) bool {
+	return c.BuyerID == userID || c.SellerID == userID
+}
+
+// Peer returns the ID of the participant other than userID.
+func (c *Conversation) Peer(userID string) string {
+	if c.BuyerID == userID {
+		return c.SellerID
+	}
+	return c.BuyerID
+}
+
+type Message struct {
+	ID             string
+	ConversationID string
+	SenderID       string
+	SenderLogin    string
+	Text           string
+	CreatedAt      time.Time
+	ReadAt         *time.Time
+}
+
+// MessageCursor points at the oldest message of a page; the next page starts
+// right before it.
+type MessageCursor struct {
+	CreatedAt time.Time
+	ID        string
+}
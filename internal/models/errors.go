@@ -3,6 +3,7 @@ package models
 import (
 	"errors"
 	"fmt"
+	"time"
 )
 
 var (
@@ -12,7 +13,64 @@ var (
 	ErrPostNotFound           = errors.New("post not found")
 	ErrPostExists             = errors.New("post already exists")
 	ErrDocumentNotFound       = errors.New("document not found")
+	ErrSearchNotFound         = errors.New("saved search not found")
+	ErrNotificationNotFound   = errors.New("notification not found")
+	ErrConversationNotFound   = errors.New("conversation not found")
+	ErrOwnPost                = errors.New("not allowed on own post")
+	ErrOfferNotFound          = errors.New("offer not found")
+	ErrOfferExists            = errors.New("offer already pending")
+	ErrOfferClosed            = errors.New("offer is no longer pending")
+	ErrNotAuction             = errors.New("post is not an auction")
+	ErrAuctionEnded           = errors.New("auction has ended")
+	ErrBidTooLow              = errors.New("bid is too low")
+	ErrInvalidAuction         = errors.New("invalid auction")
+	ErrAuctionNotEnded        = errors.New("auction has not ended")
+	ErrOrderNotFound          = errors.New("order not found")
+	ErrPostReserved           = errors.New("post is already reserved")
+	ErrInvalidTransition      = errors.New("order status does not allow this action")
+	ErrPaymentDeclined        = errors.New("payment declined")
+	ErrReviewNotFound         = errors.New("review not found")
+	ErrReviewExists           = errors.New("post already reviewed")
+	ErrNotPurchased           = errors.New("post was not purchased")
+	ErrInvalidRating          = errors.New("invalid rating")
+	ErrNoPendingReply         = errors.New("review has no pending reply")
+	ErrInvalidCursor          = errors.New("invalid cursor")
 	ErrSessionNotFound        = errors.New("sessions not found")
+	ErrRefreshTokenReused     = errors.New("refresh token reused")
+	ErrResetTokenNotFound     = errors.New("reset token not found")
+	ErrInvalidResetToken      = errors.New("invalid or expired reset token")
+	ErrInvalidPassword        = errors.New("password does not meet requirements")
+	ErrEmailExists            = errors.New("email already in use")
+	ErrInvalidEmail           = errors.New("invalid email")
+	ErrNoEmail                = errors.New("user has no email")
+	ErrEmailNotVerified       = errors.New("email is not verified")
+	ErrEmailAlreadyVerified   = errors.New("email is already verified")
+	ErrVerifyTokenNotFound    = errors.New("verification token not found")
+	ErrInvalidVerifyToken     = errors.New("invalid or expired verification token")
+	ErrTooManyRequests        = errors.New("too many requests")
+	ErrLoginBlocked           = errors.New("too many failed login attempts")
+	ErrTwoFactorNotEnrolled   = errors.New("two-factor authentication is not set up")
+	ErrTwoFactorEnabled       = errors.New("two-factor authentication is already enabled")
+	ErrInvalidTwoFactorCode   = errors.New("invalid two-factor code")
+	ErrInvalidChallenge       = errors.New("invalid or expired two-factor challenge")
+	ErrProviderNotFound       = errors.New("identity provider not found")
+	ErrInvalidOIDCState       = errors.New("invalid or expired login state")
+	ErrExternalLoginFailed    = errors.New("external login failed")
+	ErrIdentityLinked         = errors.New("external identity is already linked")
+	ErrIdentityNotFound       = errors.New("external identity not found")
+	ErrAPIKeyNotFound         = errors.New("api key not found")
+	ErrAPIKeyLimit            = errors.New("too many api keys")
+	ErrInsufficientScope      = errors.New("api key lacks the required scope")
+	ErrAPIKeyNotAllowed       = errors.New("api keys are not accepted here")
+	ErrInvalidRole            = errors.New("invalid role")
+	ErrUserBanned             = errors.New("user is banned")
+	ErrJobNotFound            = errors.New("job not found")
+	ErrReportNotFound         = errors.New("report not found")
+	ErrReportExists           = errors.New("post already reported")
+	ErrReportClosed           = errors.New("report is already closed")
+	ErrInvalidReportReason    = errors.New("invalid report reason")
+	ErrJobQueueFull           = errors.New("too many jobs, try again later")
+	ErrActiveOrders           = errors.New("user has active orders")
 	ErrInvalidParams          = errors.New("invalid params")
 	ErrInvalidCredentials     = errors.New("invalid credentials")
 	ErrInvalidFilter          = errors.New("invalid filter received")
@@ -20,6 +78,9 @@ var (
 	ErrInvalidText            = errors.New("invalid text")
 	ErrInvalidPrice           = errors.New("invalid price")
 	ErrMethodNotAllowed       = errors.New("method not allowed")
+	ErrForbidden              = errors.New("forbidden")
+	ErrTooManyConnections     = errors.New("too many connections")
+	ErrInvalidFeedTicket      = errors.New("invalid or expired feed ticket")
 	ErrInternal               = errors.New("internal server error")
 )
 
@@ -35,3 +96,18 @@ func (e *UniqueConstraintError) Error() string {
 func (e *UniqueConstraintError) Unwrap() error {
 	return e.Err
 }
+
+// RetryAfterError is returned when a request is refused for a while. After is
+// how long the client should wait before trying again.
+type RetryAfterError struct {
+	After time.Duration
+	Err   error
+}
+
+func (e *RetryAfterError) Error() string {
+	return fmt.Sprintf("%v: retry after %s", e.Err, e.After)
+}
+
+func (e *RetryAfterError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type EventType string
+
+const (
+	EventPostCreated  EventType = "post_created"
+	EventPostDeleted  EventType = "post_deleted"
+	EventSessionEnded EventType = "session_ended"
+)
+
+type Event struct {
+	ID        string          `json:"id"`
+	UserID    string          `json:"user_id"`
+	Type      EventType       `json:"type"`
+	Payload   json.RawMessage `json:"payload"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+type PostEventPayload struct {
+	PostID string `json:"post_id"`
+	Header string `json:"header,omitempty"`
+}
+
+type SessionEventPayload struct {
+	Reason string `json:"reason"`
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// FeedTicket opens the live feed once for the user it was issued to. It is
+// passed in the query, since browsers cannot send the Authorization header
+// with a WebSocket handshake.
+type FeedTicket struct {
+	Ticket    string
+	ExpiresAt time.Time
+}
+
+// PostSubscription is a live stream of newly published posts that match the
+// subscriber's filter.
+type PostSubscription interface {
+	// Posts is closed when the subscriber falls too far behind.
+	Posts() <-chan *PostWithDocument
+	SetFilter(filter *PostsFilter) error
+	Close()
+}
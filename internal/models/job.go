@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// JobKind is the kind of work a background job does for a user.
+type JobKind string
+
+const (
+	JobExport   JobKind = "export"
+	JobDeletion JobKind = "deletion"
+)
+
+type JobStatus string
+
+const (
+	JobPending JobStatus = "pending"
+	JobRunning JobStatus = "running"
+	JobDone    JobStatus = "done"
+	JobFailed  JobStatus = "failed"
+)
+
+// Job is a long running request of a user, such as a data export, that is
+// carried out in the background. Path is the file the job produced.
+type Job struct {
+	ID         string     `json:"id"`
+	UserID     string     `json:"user_id"`
+	Kind       JobKind    `json:"kind"`
+	Status     JobStatus  `json:"status"`
+	Error      string     `json:"error,omitempty"`
+	Path       string     `json:"path,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// Active reports whether the job is still waiting or running.
+func (j *Job) Active() bool {
+	return j.Status == JobPending || j.Status == JobRunning
+}
@@ -0,0 +1,7 @@
+package models
+
+type Mail struct {
+	To      string
+	Subject string
+	Body    string
+}
@@ -0,0 +1,97 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type NotificationKind string
+
+const (
+	NotificationSearchMatch  NotificationKind = "search_match"
+	NotificationNewMessage   NotificationKind = "new_message"
+	NotificationPostExpiring NotificationKind = "post_expiring"
+	NotificationModeration   NotificationKind = "moderation"
+	NotificationOffer        NotificationKind = "offer"
+	NotificationAuctionEnded NotificationKind = "auction_ended"
+	NotificationOrder        NotificationKind = "order"
+	NotificationReview       NotificationKind = "review"
+)
+
+type Notification struct {
+	ID        string
+	UserID    string
+	Kind      NotificationKind
+	Payload   json.RawMessage
+	CreatedAt time.Time
+	ReadAt    *time.Time
+}
+
+// NotificationPayload is implemented by every typed notification payload, so
+// that a payload is always stored under the kind it belongs to.
+type NotificationPayload interface {
+	Kind() NotificationKind
+}
+
+type SearchMatchPayload struct {
+	SearchID string `json:"search_id"`
+	PostID   string `json:"post_id"`
+}
+
+func (SearchMatchPayload) Kind() NotificationKind { return NotificationSearchMatch }
+
+type NewMessagePayload struct {
+	ConversationID string `json:"conversation_id"`
+	MessageID      string `json:"message_id"`
+	SenderLogin    string `json:"sender_login"`
+}
+
+func (NewMessagePayload) Kind() NotificationKind { return NotificationNewMessage }
+
+type PostExpiringPayload struct {
+	PostID    string    `json:"post_id"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func (PostExpiringPayload) Kind() NotificationKind { return NotificationPostExpiring }
+
+type ModerationPayload struct {
+	Action string `json:"action"`
+	PostID string `json:"post_id,omitempty"`
+	Reason string `json:"reason,omitempty"`
+}
+
+func (ModerationPayload) Kind() NotificationKind { return NotificationModeration }
+
+type OfferPayload struct {
+	OfferID string      `json:"offer_id"`
+	PostID  string      `json:"post_id"`
+	Status  OfferStatus `json:"status"`
+	Amount  int64       `json:"amount"`
+}
+
+func (OfferPayload) Kind() NotificationKind { return NotificationOffer }
+
+type AuctionEndedPayload struct {
+	PostID      string `json:"post_id"`
+	WinnerLogin string `json:"winner_login,omitempty"`
+	Amount      int64  `json:"amount,omitempty"`
+}
+
+func (AuctionEndedPayload) Kind() NotificationKind { return NotificationAuctionEnded }
+
+type OrderPayload struct {
+	OrderID string      `json:"order_id"`
+	PostID  string      `json:"post_id"`
+	Status  OrderStatus `json:"status"`
+}
+
+func (OrderPayload) Kind() NotificationKind { return NotificationOrder }
+
+type ReviewPayload struct {
+	ReviewID string `json:"review_id"`
+	PostID   string `json:"post_id"`
+	Rating   int    `json:"rating"`
+}
+
+func (ReviewPayload) Kind() NotificationKind { return NotificationReview }
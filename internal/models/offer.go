@@ -0,0 +1,53 @@
+package models
+
+import "time"
+
+type OfferStatus string
+
+const (
+	OfferPending   OfferStatus = "pending"
+	OfferAccepted  OfferStatus = "accepted"
+	OfferRejected  OfferStatus = "rejected"
+	OfferCountered OfferStatus = "countered"
+	OfferExpired   OfferStatus = "expired"
+)
+
+// Offer is a price proposed by one side of a negotiation about a post. A
+// counter-offer is a new offer made by the other side that references the
+// offer it answers.
+type Offer struct {
+	ID          string
+	PostID      string
+	BuyerID     string
+	BuyerLogin  string
+	SellerID    string
+	SellerLogin string
+	ProposerID  string
+	PreviousID  string
+	Amount      int64
+	Status      OfferStatus
+	CreatedAt   time.Time
+	ExpiresAt   time.Time
+	RespondedAt *time.Time
+}
+
+// Recipient returns the ID of the side that has to answer the offer.
+func (o *Offer) Recipient() string {
+	if o.ProposerID == o.BuyerID {
+		return o.SellerID
+	}
+	return o.BuyerID
+}
+
+func (o *Offer) HasParticipant(userID string) bool {
+	return o.BuyerID == userID || o.SellerID == userID
+}
+
+// OfferRole selects the side of the negotiations listed in the offers inbox.
+type OfferRole string
+
+const (
+	OfferRoleAny    OfferRole = ""
+	OfferRoleBuyer  OfferRole = "buyer"
+	OfferRoleSeller OfferRole = "seller"
+)
@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+// ExternalIdentity links an account at an OpenID Connect provider, known by
+// its subject, to a user.
+type ExternalIdentity struct {
+	Provider  string
+	Subject   string
+	UserID    string
+	CreatedAt time.Time
+}
+
+// IDTokenClaims are the verified claims of an ID token that are used to find
+// or create the user.
+type IDTokenClaims struct {
+	Subject           string
+	Email             string
+	EmailVerified     bool
+	PreferredUsername string
+}
+
+// OIDCState is kept between redirecting to the provider and its callback.
+// UserID is set when a signed-in user links the identity to their account.
+type OIDCState struct {
+	Provider  string    `json:"provider"`
+	Verifier  string    `json:"verifier"`
+	Nonce     string    `json:"nonce"`
+	UserID    string    `json:"user_id,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ExternalStart is where to send the user to begin an OIDC flow. Binding ties
+// the flow to the browser that started it: it is kept there until ExpiresAt
+// and has to come back together with the callback.
+type ExternalStart struct {
+	AuthURL   string
+	Binding   string
+	ExpiresAt time.Time
+}
+
+// ExternalLogin is the outcome of an OIDC callback: either the identity was
+// linked to LinkedUserID, or the user was logged in.
+type ExternalLogin struct {
+	LinkedUserID string
+	Login        *LoginResult
+}
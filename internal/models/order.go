@@ -0,0 +1,66 @@
+package models
+
+import (
+	"slices"
+	"time"
+)
+
+type OrderStatus string
+
+const (
+	OrderPending   OrderStatus = "pending"
+	OrderConfirmed OrderStatus = "confirmed"
+	OrderCompleted OrderStatus = "completed"
+	OrderCancelled OrderStatus = "cancelled"
+)
+
+// orderTransitions lists the statuses every status may move to. Completed
+// and cancelled orders are final.
+var orderTransitions = map[OrderStatus][]OrderStatus{
+	OrderPending:   {OrderConfirmed, OrderCancelled},
+	OrderConfirmed: {OrderCompleted, OrderCancelled},
+}
+
+// CanTransitionTo reports whether an order in status s may be moved to next.
+func (s OrderStatus) CanTransitionTo(next OrderStatus) bool {
+	return slices.Contains(orderTransitions[s], next)
+}
+
+// Order reserves a post for a buyer. The reservation lasts until the order is
+// cancelled, either explicitly or because it was not paid before ExpiresAt.
+type Order struct {
+	ID          string
+	PostID      string
+	BuyerID     string
+	BuyerLogin  string
+	SellerID    string
+	SellerLogin string
+	Amount      int64
+	Status      OrderStatus
+	PaymentID   string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	ExpiresAt   time.Time
+}
+
+func (o *Order) HasParticipant(userID string) bool {
+	return o.BuyerID == userID || o.SellerID == userID
+}
+
+// Counterpart returns the other side of the deal for userID.
+func (o *Order) Counterpart(userID string) string {
+	if o.BuyerID == userID {
+		return o.SellerID
+	}
+
+	return o.BuyerID
+}
+
+// OrderRole selects the side of the deals listed in the orders inbox.
+type OrderRole string
+
+const (
+	OrderRoleAny    OrderRole = ""
+	OrderRoleBuyer  OrderRole = "buyer"
+	OrderRoleSeller OrderRole = "seller"
+)
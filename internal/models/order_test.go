@@ -0,0 +1,29 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrderStatusCanTransitionTo(t *testing.T) {
+	tests := []struct {
+		Name string
+		From OrderStatus
+		To   OrderStatus
+		Want bool
+	}{
+		{Name: "pay pending", From: OrderPending, To: OrderConfirmed, Want: true},
+		{Name: "cancel pending", From: OrderPending, To: OrderCancelled, Want: true},
+		{Name: "complete pending", From: OrderPending, To: OrderCompleted, Want: false},
+		{Name: "complete confirmed", From: OrderConfirmed, To: OrderCompleted, Want: true},
+		{Name: "cancel confirmed", From: OrderConfirmed, To: OrderCancelled, Want: true},
+		{Name: "confirm confirmed", From: OrderConfirmed, To: OrderConfirmed, Want: false},
+		{Name: "cancel completed", From: OrderCompleted, To: OrderCancelled, Want: false},
+		{Name: "reopen cancelled", From: OrderCancelled, To: OrderPending, Want: false},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.Want, test.From.CanTransitionTo(test.To), test.Name)
+	}
+}
@@ -6,15 +6,24 @@ type PostWithDocument struct {
 	ID               string    `json:"id,omitempty"`
 	OwnerID          string    `json:"-"`
 	OwnerLogin       string    `json:"owner_login,omitempty"`
+	OwnerRating      Rating    `json:"owner_rating,omitzero"`
 	Header           string    `json:"header"`
 	Text             string    `json:"text"`
 	PathToImage      string    `json:"image_path,omitempty"`
 	Price            int64     `json:"price"`
 	CreatedAt        time.Time `json:"-"`
 	RequesterIsOwner bool      `json:"is_owner,omitempty"`
+	Auction          *Auction  `json:"auction,omitempty"`
 	Document         *Document `json:"-"`
 }
 
+// PostUpdate holds the post fields to change; nil fields are kept.
+type PostUpdate struct {
+	Header *string
+	Text   *string
+	Price  *int64
+}
+
 type Document struct {
 	ID     string
 	PostID string
@@ -28,4 +37,19 @@ type PostsFilter struct {
 	MaxPrice  uint
 	SortBy    string
 	SortOrder string
+	// ExcludedOwnerIDs hides the posts of these users, such as the ones the
+	// requester has blocked.
+	ExcludedOwnerIDs []string
+}
+
+func (f *PostsFilter) Matches(post *PostWithDocument) bool {
+	if f.MinPrice > 0 && post.Price < int64(f.MinPrice) {
+		return false
+	}
+
+	if f.MaxPrice > 0 && post.Price > int64(f.MaxPrice) {
+		return false
+	}
+
+	return true
 }
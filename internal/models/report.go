@@ -0,0 +1,49 @@
+package models
+
+import (
+	"slices"
+	"time"
+)
+
+// ReportReason is the kind of abuse a post is reported for.
+type ReportReason string
+
+const (
+	ReportScam       ReportReason = "scam"
+	ReportProhibited ReportReason = "prohibited"
+	ReportSpam       ReportReason = "spam"
+	ReportOffensive  ReportReason = "offensive"
+	ReportOther      ReportReason = "other"
+)
+
+// ReportReasons lists every reason, in the order they are documented.
+var ReportReasons = []ReportReason{ReportScam, ReportProhibited, ReportSpam, ReportOffensive, ReportOther}
+
+func (r ReportReason) Valid() bool {
+	return slices.Contains(ReportReasons, r)
+}
+
+type ReportStatus string
+
+const (
+	ReportOpen      ReportStatus = "open"
+	ReportResolved  ReportStatus = "resolved"
+	ReportDismissed ReportStatus = "dismissed"
+)
+
+// Report is a user's complaint about a post. A moderator closes it by
+// resolving it, which keeps the post hidden, or by dismissing it.
+type Report struct {
+	ID            string
+	PostID        string
+	PostOwnerID   string
+	PostHeader    string
+	PostHidden    bool
+	ReporterID    string
+	ReporterLogin string
+	Reason        ReportReason
+	Text          string
+	Status        ReportStatus
+	CreatedAt     time.Time
+	ClosedAt      *time.Time
+}
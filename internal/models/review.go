@@ -0,0 +1,55 @@
+package models
+
+import (
+	"math"
+	"time"
+)
+
+type ReplyStatus string
+
+const (
+	ReplyNone     ReplyStatus = ""
+	ReplyPending  ReplyStatus = "pending"
+	ReplyApproved ReplyStatus = "approved"
+	ReplyRejected ReplyStatus = "rejected"
+)
+
+// Review is a buyer's rating of a seller for a single post. The seller's
+// reply becomes public only after a moderator approves it.
+type Review struct {
+	ID          string
+	PostID      string
+	SellerID    string
+	SellerLogin string
+	AuthorID    string
+	AuthorLogin string
+	Rating      int
+	Text        string
+	Reply       string
+	ReplyStatus ReplyStatus
+	CreatedAt   time.Time
+	RepliedAt   *time.Time
+}
+
+// PublicReply returns the reply text if it has been approved.
+func (r *Review) PublicReply() string {
+	if r.ReplyStatus != ReplyApproved {
+		return ""
+	}
+	return r.Reply
+}
+
+// Rating is the aggregate of all reviews left for a seller.
+type Rating struct {
+	Count int   `json:"count"`
+	Sum   int64 `json:"sum"`
+}
+
+// Average returns the mean rating rounded to one decimal place, or zero when
+// the seller has no reviews.
+func (r Rating) Average() float64 {
+	if r.Count == 0 {
+		return 0
+	}
+	return math.Round(float64(r.Sum)/float64(r.Count)*10) / 10
+}
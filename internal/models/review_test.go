@@ -0,0 +1,29 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRatingAverage(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, 0.0, Rating{}.Average())
+	assert.Equal(t, 5.0, Rating{Count: 1, Sum: 5}.Average())
+	assert.Equal(t, 4.3, Rating{Count: 3, Sum: 13}.Average())
+	assert.Equal(t, 3.5, Rating{Count: 2, Sum: 7}.Average())
+}
+
+func TestReviewPublicReply(t *testing.T) {
+	t.Parallel()
+
+	review := &Review{Reply: "thanks", ReplyStatus: ReplyPending}
+	assert.Empty(t, review.PublicReply())
+
+	review.ReplyStatus = ReplyRejected
+	assert.Empty(t, review.PublicReply())
+
+	review.ReplyStatus = ReplyApproved
+	assert.Equal(t, "thanks", review.PublicReply())
+}
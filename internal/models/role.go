@@ -0,0 +1,41 @@
+package models
+
+// Role is what a user may do beyond managing their own things. Moderators
+// can edit and remove anyone's posts and approve review replies; admins can
+// also grant roles.
+type Role string
+
+const (
+	RoleUser      Role = "user"
+	RoleModerator Role = "moderator"
+	RoleAdmin     Role = "admin"
+)
+
+func (r Role) Valid() bool {
+	switch r {
+	case RoleUser, RoleModerator, RoleAdmin:
+		return true
+	default:
+		return false
+	}
+}
+
+// Moderators is the allowlist of moderator logins from the config. Every
+// service that checks moderators shares one allowlist; whether a role counts
+// as well is up to the service.
+type Moderators map[string]struct{}
+
+func NewModerators(logins []string) Moderators {
+	moderators := make(Moderators, len(logins))
+	for _, login := range logins {
+		moderators[login] = struct{}{}
+	}
+
+	return moderators
+}
+
+// Allows reports whether the user's login is on the allowlist.
+func (m Moderators) Allows(user *User) bool {
+	_, ok := m[user.Login]
+	return ok
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestModeratorsAllows(t *testing.T) {
+	t.Parallel()
+
+	moderators := NewModerators([]string{"mod"})
+
+	tests := []struct {
+		name string
+		user *User
+		want bool
+	}{
+		{name: "allowlisted", user: &User{Login: "mod", Role: RoleUser}, want: true},
+		{name: "moderator role", user: &User{Login: "other", Role: RoleModerator}, want: false},
+		{name: "admin role", user: &User{Login: "other", Role: RoleAdmin}, want: false},
+		{name: "user", user: &User{Login: "other", Role: RoleUser}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, moderators.Allows(tt.user))
+		})
+	}
+}
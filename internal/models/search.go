@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+type SavedSearch struct {
+	ID        string
+	UserID    string
+	Filter    PostsFilter
+	CreatedAt time.Time
+}
+
+type SearchMatch struct {
+	SearchID  string
+	Post      *PostWithDocument
+	MatchedAt time.Time
+}
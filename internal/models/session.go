@@ -0,0 +1,53 @@
+package models
+
+import "time"
+
+// TokenPair is issued on login and on every refresh. The access token
+// authenticates requests and expires soon; the refresh token is exchanged
+// once for a new pair.
+type TokenPair struct {
+	AccessToken  string
+	RefreshToken string
+}
+
+// Session is a login on one device. It starts at login and collects every
+// token pair issued by refreshing it, so that all of them can be revoked
+// together.
+type Session struct {
+	ID         string    `json:"id"`
+	UserID     string    `json:"user_id"`
+	UserAgent  string    `json:"user_agent"`
+	IP         string    `json:"ip"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	Current    bool      `json:"-"`
+}
+
+// ClientInfo describes the device a request came from.
+type ClientInfo struct {
+	UserAgent string
+	IP        string
+}
+
+// AccessGrant is what an access token resolves to. It holds no user data:
+// the user is loaded by ID, so changes to the account apply to live sessions.
+// Version is the user's session version at issue time; the grant is void once
+// the version moves on. Signed access tokens also have an ID and expiry, by
+// which they are put on the denylist when revoked.
+type AccessGrant struct {
+	UserID    string    `json:"user_id"`
+	SessionID string    `json:"session_id"`
+	Version   int64     `json:"version"`
+	IssuedAt  time.Time `json:"issued_at"`
+	TokenID   string    `json:"token_id,omitempty"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// RefreshToken is the stored state of a refresh token. Used is set when the
+// token has already been exchanged, which means it is being replayed.
+type RefreshToken struct {
+	UserID    string `json:"user_id"`
+	SessionID string `json:"session_id"`
+	Version   int64  `json:"version"`
+	Used      bool   `json:"-"`
+}
@@ -0,0 +1,41 @@
+package models
+
+import "time"
+
+// TOTP is the authenticator app setup of a user. Secret is encrypted; the
+// setup takes effect once EnabledAt is set, after the user has entered a code
+// from the app. LastStep is the time step of the last accepted code, so that
+// a code cannot be used twice.
+type TOTP struct {
+	UserID    string
+	Secret    string
+	EnabledAt *time.Time
+	LastStep  int64
+	CreatedAt time.Time
+}
+
+func (t *TOTP) Enabled() bool {
+	return t.EnabledAt != nil
+}
+
+// TOTPEnrollment is shown to the user to add the secret to an authenticator
+// app.
+type TOTPEnrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+}
+
+// TwoFactorChallenge is the state of a login that has passed the password
+// check and waits for a second factor.
+type TwoFactorChallenge struct {
+	UserID    string    `json:"user_id"`
+	Login     string    `json:"login"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// LoginResult holds either the tokens of the new session or, for users with
+// two-factor authentication, the challenge to complete with a code.
+type LoginResult struct {
+	Tokens    *TokenPair
+	Challenge string
+}
@@ -1,7 +1,47 @@
 package models
 
+import "time"
+
 type User struct {
-	ID       string `json:"user_id"`
-	Login    string `json:"login"`
-	PassHash []byte
+	ID              string     `json:"user_id"`
+	Login           string     `json:"login"`
+	PassHash        []byte     `json:"-"`
+	Email           string     `json:"email,omitempty"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty"`
+	Role            Role       `json:"role,omitempty"`
+	Ban             *Ban       `json:"-"`
+}
+
+// EmailVerified reports whether the user has an email confirmed through a
+// verification link.
+func (u *User) EmailVerified() bool {
+	return u.Email != "" && u.EmailVerifiedAt != nil
+}
+
+// IsModerator reports whether the user may moderate content of others.
+// Admins are moderators too.
+func (u *User) IsModerator() bool {
+	return u.Role == RoleModerator || u.Role == RoleAdmin
+}
+
+// Banned reports whether the user is banned or suspended at the moment.
+func (u *User) Banned(now time.Time) bool {
+	return u.Ban != nil && u.Ban.Active(now)
+}
+
+// Profile is the public part of a user account.
+type Profile struct {
+	UserID       string
+	Login        string
+	DisplayName  string
+	Bio          string
+	AvatarPath   string
+	RegisteredAt time.Time
+	PostCount    int
+}
+
+// ProfileUpdate holds the profile fields to change; nil fields are kept.
+type ProfileUpdate struct {
+	DisplayName *string
+	Bio         *string
 }
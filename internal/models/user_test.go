@@ -0,0 +1,47 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserJSON_OmitsPassHash(t *testing.T) {
+	t.Parallel()
+
+	data, err := json.Marshal(&User{ID: "1", Login: "user1", PassHash: []byte("hashed")})
+
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"user_id":"1","login":"user1"}`, string(data))
+}
+
+func TestUserBanned(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	past := now.Add(-time.Hour)
+	future := now.Add(time.Hour)
+
+	tests := []struct {
+		name string
+		ban  *Ban
+		want bool
+	}{
+		{name: "not banned", want: false},
+		{name: "permanent ban", ban: &Ban{Reason: "spam"}, want: true},
+		{name: "running suspension", ban: &Ban{Reason: "spam", Until: &future}, want: true},
+		{name: "expired suspension", ban: &Ban{Reason: "spam", Until: &past}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			user := &User{ID: "1", Ban: tt.ban}
+
+			assert.Equal(t, tt.want, user.Banned(now))
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package oidcclient
+
+import (
+	"context"
+	"errors"
+	fakeoidc "marketplace/internal/oidc/fake"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const redirectURL = "http://localhost:8082/api/auth/oidc/fake/callback"
+
+func newIssuer(t *testing.T) *fakeoidc.Issuer {
+	t.Helper()
+
+	issuer, err := fakeoidc.New("marketplace", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(issuer.Close)
+
+	return issuer
+}
+
+func newClient(issuer *fakeoidc.Issuer, clientID string) *Client {
+	return New(issuer.Client(), Config{
+		Issuer:       issuer.URL(),
+		ClientID:     clientID,
+		ClientSecret: "secret",
+		RedirectURL:  redirectURL,
+	})
+}
+
+func TestClient_CodeFlow(t *testing.T) {
+	t.Parallel()
+
+	issuer := newIssuer(t)
+	issuer.SignInAs(fakeoidc.User{Subject: "sub-1", Email: "jane@example.com", EmailVerified: true, PreferredUsername: "jane"})
+
+	client := newClient(issuer, "marketplace")
+
+	authURL, err := client.AuthCodeURL(context.Background(), "state-1", "nonce-1", "verifier-verifier-verifier-verifier-verifier")
+	assert.NoError(t, err)
+
+	parsed, err := url.Parse(authURL)
+	assert.NoError(t, err)
+	assert.Equal(t, "S256", parsed.Query().Get("code_challenge_method"))
+	assert.Equal(t, CodeChallenge("verifier-verifier-verifier-verifier-verifier"), parsed.Query().Get("code_challenge"))
+	assert.Equal(t, "openid profile email", parsed.Query().Get("scope"))
+
+	code, state, err := issuer.Authorize(authURL)
+	assert.NoError(t, err)
+	assert.Equal(t, "state-1", state)
+
+	claims, err := client.Exchange(context.Background(), code, "verifier-verifier-verifier-verifier-verifier", "nonce-1")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "sub-1", claims.Subject)
+	assert.Equal(t, "jane@example.com", claims.Email)
+	assert.True(t, claims.EmailVerified)
+	assert.Equal(t, "jane", claims.PreferredUsername)
+}
+
+func TestClient_Exchange_Rejects(t *testing.T) {
+	t.Parallel()
+
+	const verifier = "verifier-verifier-verifier-verifier-verifier"
+
+	tests := []struct {
+		name     string
+		verifier string
+		nonce    string
+		wantErr  error
+	}{
+		{name: "wrong verifier", verifier: "another-verifier-another-verifier-another", nonce: "nonce-1"},
+		{name: "wrong nonce", verifier: verifier, nonce: "nonce-2", wantErr: ErrInvalidIDToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			issuer := newIssuer(t)
+			client := newClient(issuer, "marketplace")
+
+			authURL, err := client.AuthCodeURL(context.Background(), "state-1", "nonce-1", verifier)
+			assert.NoError(t, err)
+
+			code, _, err := issuer.Authorize(authURL)
+			assert.NoError(t, err)
+
+			claims, err := client.Exchange(context.Background(), code, tt.verifier, tt.nonce)
+
+			assert.Error(t, err)
+			assert.Nil(t, claims)
+			if tt.wantErr != nil {
+				assert.True(t, errors.Is(err, tt.wantErr))
+			}
+		})
+	}
+}
+
+func TestClient_Exchange_CodeUsedOnce(t *testing.T) {
+	t.Parallel()
+
+	const verifier = "verifier-verifier-verifier-verifier-verifier"
+
+	issuer := newIssuer(t)
+	client := newClient(issuer, "marketplace")
+
+	authURL, err := client.AuthCodeURL(context.Background(), "state-1", "nonce-1", verifier)
+	assert.NoError(t, err)
+
+	code, _, err := issuer.Authorize(authURL)
+	assert.NoError(t, err)
+
+	_, err = client.Exchange(context.Background(), code, verifier, "nonce-1")
+	assert.NoError(t, err)
+
+	_, err = client.Exchange(context.Background(), code, verifier, "nonce-1")
+	assert.Error(t, err)
+}
+
+func TestClient_UnknownClient(t *testing.T) {
+	t.Parallel()
+
+	issuer := newIssuer(t)
+	client := newClient(issuer, "someone-else")
+
+	authURL, err := client.AuthCodeURL(context.Background(), "state-1", "nonce-1", "verifier")
+	assert.NoError(t, err)
+
+	_, _, err = issuer.Authorize(authURL)
+	assert.Error(t, err)
+}
+
+func TestClient_DiscoveryIssuerMismatch(t *testing.T) {
+	t.Parallel()
+
+	issuer := newIssuer(t)
+	client := New(issuer.Client(), Config{Issuer: issuer.URL() + "/", ClientID: "marketplace"})
+
+	_, err := client.AuthCodeURL(context.Background(), "state-1", "nonce-1", "verifier")
+
+	assert.Error(t, err)
+}
@@ -0,0 +1,89 @@
+package fake
+
+import (
+	"context"
+	"fmt"
+	"marketplace/internal/models"
+	"sync"
+
+	uuid "github.com/satori/go.uuid"
+)
+
+const pkg = "fakePayments/"
+
+// DeclinedToken is a payment token the provider always declines, so that a
+// failed checkout can be reproduced.
+const DeclinedToken = "tok_declined"
+
+type payment struct {
+	orderID  string
+	amount   int64
+	refunded bool
+}
+
+// Provider is an in-memory payment gateway for local runs and tests. Every
+// non-empty token other than DeclinedToken is charged successfully.
+type Provider struct {
+	mu       sync.Mutex
+	payments map[string]*payment
+	byOrder  map[string]string
+}
+
+func New() *Provider {
+	return &Provider{
+		payments: make(map[string]*payment),
+		byOrder:  make(map[string]string),
+	}
+}
+
+// Charge takes amount for the order. Charging the same order again returns
+// the original payment, like a real gateway does for an idempotency key.
+func (p *Provider) Charge(ctx context.Context, orderID string, amount int64, token string) (string, error) {
+	if token == "" || token == DeclinedToken {
+		return "", models.ErrPaymentDeclined
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if id, ok := p.byOrder[orderID]; ok {
+		return id, nil
+	}
+
+	id := "fake_" + uuid.NewV4().String()
+
+	p.payments[id] = &payment{orderID: orderID, amount: amount}
+	p.byOrder[orderID] = id
+
+	return id, nil
+}
+
+func (p *Provider) Refund(ctx context.Context, paymentID string) error {
+	op := pkg + "Refund"
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	payment, ok := p.payments[paymentID]
+	if !ok {
+		return fmt.Errorf("%s: payment %s not found", op, paymentID)
+	}
+
+	if payment.refunded {
+		return fmt.Errorf("%s: payment %s already refunded", op, paymentID)
+	}
+
+	payment.refunded = true
+
+	return nil
+}
+
+// Refunded reports whether the payment has been refunded.
+func (p *Provider) Refunded(paymentID string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	payment, ok := p.payments[paymentID]
+
+	return ok && payment.refunded
+}
@@ -0,0 +1,52 @@
+package fake
+
+import (
+	"context"
+	"marketplace/internal/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCharge_Declined(t *testing.T) {
+	t.Parallel()
+
+	p := New()
+
+	_, err := p.Charge(context.Background(), "o1", 100, DeclinedToken)
+	assert.ErrorIs(t, err, models.ErrPaymentDeclined)
+
+	_, err = p.Charge(context.Background(), "o1", 100, "")
+	assert.ErrorIs(t, err, models.ErrPaymentDeclined)
+}
+
+func TestCharge_Idempotent(t *testing.T) {
+	t.Parallel()
+
+	p := New()
+
+	first, err := p.Charge(context.Background(), "o1", 100, "tok_ok")
+	assert.NoError(t, err)
+
+	second, err := p.Charge(context.Background(), "o1", 100, "tok_ok")
+	assert.NoError(t, err)
+	assert.Equal(t, first, second)
+
+	other, err := p.Charge(context.Background(), "o2", 100, "tok_ok")
+	assert.NoError(t, err)
+	assert.NotEqual(t, first, other)
+}
+
+func TestRefund(t *testing.T) {
+	t.Parallel()
+
+	p := New()
+
+	id, err := p.Charge(context.Background(), "o1", 100, "tok_ok")
+	assert.NoError(t, err)
+
+	assert.NoError(t, p.Refund(context.Background(), id))
+	assert.True(t, p.Refunded(id))
+	assert.Error(t, p.Refund(context.Background(), id))
+	assert.Error(t, p.Refund(context.Background(), "unknown"))
+}
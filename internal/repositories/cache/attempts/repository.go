@@ -0,0 +1,96 @@
+package cacheattemptsrepo
+
+import (
+	"context"
+	"fmt"
+	cacherepo "marketplace/internal/repositories/cache"
+	"time"
+)
+
+const (
+	pkg         = "cacheAttemptsRepo/"
+	failuresKey = "login_failures:"
+	blockedKey  = "login_blocked:"
+)
+
+// repository counts failed logins per key, such as a login or a client IP,
+// and keeps the time until which the key is blocked.
+type repository struct {
+	cache  cacherepo.AttemptCache
+	window time.Duration
+}
+
+func New(cache cacherepo.AttemptCache, window time.Duration) *repository {
+	return &repository{
+		cache:  cache,
+		window: window,
+	}
+}
+
+// RegisterFailure counts a failed attempt for the key and returns how many
+// were made in the current window, this one included.
+func (r *repository) RegisterFailure(ctx context.Context, key string) (int64, error) {
+	op := pkg + "RegisterFailure"
+
+	count, err := r.cache.Incr(ctx, failuresKey+key)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if count == 1 {
+		if err := r.cache.Expire(ctx, failuresKey+key, r.window); err != nil {
+			return 0, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	return count, nil
+}
+
+func (r *repository) Block(ctx context.Context, key string, until time.Time) error {
+	op := pkg + "Block"
+
+	ttl := time.Until(until)
+	if ttl <= 0 {
+		return nil
+	}
+
+	err := r.cache.Set(ctx, blockedKey+key, until.UTC().Format(time.RFC3339Nano), ttl)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// BlockedUntil returns the time the key is blocked until, or the zero time
+// when it is not blocked.
+func (r *repository) BlockedUntil(ctx context.Context, key string) (time.Time, error) {
+	op := pkg + "BlockedUntil"
+
+	raw, err := r.cache.Get(ctx, blockedKey+key)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if raw == "" {
+		return time.Time{}, nil
+	}
+
+	until, err := time.Parse(time.RFC3339Nano, raw)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return until, nil
+}
+
+// Reset forgets the failures of the key and lifts its block.
+func (r *repository) Reset(ctx context.Context, key string) error {
+	op := pkg + "Reset"
+
+	if err := r.cache.Del(ctx, failuresKey+key, blockedKey+key); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
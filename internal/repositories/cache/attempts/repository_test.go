@@ -0,0 +1,87 @@
+package cacheattemptsrepo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockCache struct {
+	mock.Mock
+}
+
+func (m *mockCache) Get(ctx context.Context, key string) (string, error) {
+	args := m.Called(ctx, key)
+	return args.Get(0).(string), args.Error(1)
+}
+
+func (m *mockCache) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+	args := m.Called(ctx, key, value, expiration)
+	return args.Error(0)
+}
+
+func (m *mockCache) Del(ctx context.Context, keys ...string) error {
+	args := m.Called(ctx, keys)
+	return args.Error(0)
+}
+
+func (m *mockCache) Incr(ctx context.Context, key string) (int64, error) {
+	args := m.Called(ctx, key)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *mockCache) Expire(ctx context.Context, key string, expiration time.Duration) error {
+	args := m.Called(ctx, key, expiration)
+	return args.Error(0)
+}
+
+func TestRegisterFailure(t *testing.T) {
+	t.Parallel()
+
+	mockCache := new(mockCache)
+
+	mockCache.On("Incr", mock.Anything, "login_failures:login:alice").Return(int64(1), nil).Once()
+	mockCache.On("Expire", mock.Anything, "login_failures:login:alice", time.Hour).Return(nil).Once()
+	mockCache.On("Incr", mock.Anything, "login_failures:login:alice").Return(int64(2), nil).Once()
+
+	repo := New(mockCache, time.Hour)
+
+	count, err := repo.RegisterFailure(context.Background(), "login:alice")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	count, err = repo.RegisterFailure(context.Background(), "login:alice")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	mockCache.AssertExpectations(t)
+}
+
+func TestBlockedUntil(t *testing.T) {
+	t.Parallel()
+
+	until := time.Now().Add(time.Minute).UTC().Truncate(time.Millisecond)
+
+	mockCache := new(mockCache)
+
+	mockCache.On("Set", mock.Anything, "login_blocked:ip:10.0.0.1", until.Format(time.RFC3339Nano), mock.AnythingOfType("time.Duration")).Return(nil)
+	mockCache.On("Get", mock.Anything, "login_blocked:ip:10.0.0.1").Return(until.Format(time.RFC3339Nano), nil)
+	mockCache.On("Get", mock.Anything, "login_blocked:ip:10.0.0.2").Return("", nil)
+
+	repo := New(mockCache, time.Hour)
+
+	assert.NoError(t, repo.Block(context.Background(), "ip:10.0.0.1", until))
+
+	blocked, err := repo.BlockedUntil(context.Background(), "ip:10.0.0.1")
+	assert.NoError(t, err)
+	assert.True(t, until.Equal(blocked))
+
+	blocked, err = repo.BlockedUntil(context.Background(), "ip:10.0.0.2")
+	assert.NoError(t, err)
+	assert.True(t, blocked.IsZero())
+
+	mockCache.AssertExpectations(t)
+}
@@ -0,0 +1,84 @@
+package cacheblockrepo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	cacherepo "marketplace/internal/repositories/cache"
+	"time"
+)
+
+const (
+	pkg        = "cacheBlockRepo/"
+	blockedKey = "blocked:"
+)
+
+// repository keeps the IDs of the users each user has blocked, so that post
+// listings do not read them from the database on every request. An empty list
+// is cached too, since most users block nobody.
+type repository struct {
+	cache cacherepo.Cache
+	ttl   time.Duration
+}
+
+func New(cache cacherepo.Cache, ttl time.Duration) *repository {
+	return &repository{
+		cache: cache,
+		ttl:   ttl,
+	}
+}
+
+// BlockedIDs returns the cached block list of the user, and false when it is
+// not cached.
+func (r *repository) BlockedIDs(ctx context.Context, blockerID string) ([]string, bool, error) {
+	op := pkg + "BlockedIDs"
+
+	idsJSON, err := r.cache.Get(ctx, blockedKey+blockerID)
+	if err != nil {
+		return nil, false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if idsJSON == "" {
+		return nil, false, nil
+	}
+
+	var ids []string
+	if err := json.Unmarshal([]byte(idsJSON), &ids); err != nil {
+		return nil, false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return ids, true, nil
+}
+
+func (r *repository) SaveBlockedIDs(ctx context.Context, blockerID string, ids []string) error {
+	op := pkg + "SaveBlockedIDs"
+
+	if ids == nil {
+		ids = []string{}
+	}
+
+	idsJSON, err := json.Marshal(ids)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	err = r.cache.Set(ctx, blockedKey+blockerID, string(idsJSON), r.ttl)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// DeleteBlockedIDs drops the cached block list of the user after a block or
+// an unblock.
+func (r *repository) DeleteBlockedIDs(ctx context.Context, blockerID string) error {
+	op := pkg + "DeleteBlockedIDs"
+
+	err := r.cache.Del(ctx, blockedKey+blockerID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,83 @@
+package cacheblockrepo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockCache struct {
+	mock.Mock
+}
+
+func (m *mockCache) Get(ctx context.Context, key string) (string, error) {
+	args := m.Called(ctx, key)
+	return args.Get(0).(string), args.Error(1)
+}
+
+func (m *mockCache) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+	args := m.Called(ctx, key, value, expiration)
+	return args.Error(0)
+}
+
+func (m *mockCache) Del(ctx context.Context, keys ...string) error {
+	args := m.Called(ctx, keys)
+	return args.Error(0)
+}
+
+func TestBlockedIDs(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		cached     string
+		wantIDs    []string
+		wantCached bool
+	}{
+		{name: "not cached", cached: "", wantIDs: nil, wantCached: false},
+		{name: "nobody blocked", cached: `[]`, wantIDs: []string{}, wantCached: true},
+		{name: "blocked users", cached: `["2","3"]`, wantIDs: []string{"2", "3"}, wantCached: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cache := new(mockCache)
+			cache.On("Get", mock.Anything, "blocked:1").Return(tt.cached, nil)
+
+			ids, cached, err := New(cache, time.Minute).BlockedIDs(context.Background(), "1")
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantIDs, ids)
+			assert.Equal(t, tt.wantCached, cached)
+		})
+	}
+}
+
+func TestSaveBlockedIDs_Empty(t *testing.T) {
+	t.Parallel()
+
+	cache := new(mockCache)
+	cache.On("Set", mock.Anything, "blocked:1", "[]", time.Minute).Return(nil)
+
+	err := New(cache, time.Minute).SaveBlockedIDs(context.Background(), "1", nil)
+
+	assert.NoError(t, err)
+	cache.AssertExpectations(t)
+}
+
+func TestDeleteBlockedIDs(t *testing.T) {
+	t.Parallel()
+
+	cache := new(mockCache)
+	cache.On("Del", mock.Anything, []string{"blocked:1"}).Return(nil)
+
+	err := New(cache, time.Minute).DeleteBlockedIDs(context.Background(), "1")
+
+	assert.NoError(t, err)
+	cache.AssertExpectations(t)
+}
@@ -0,0 +1,86 @@
+package cachechallengerepo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"marketplace/internal/models"
+	cacherepo "marketplace/internal/repositories/cache"
+	"marketplace/internal/utils/token"
+	"time"
+)
+
+const (
+	pkg          = "cacheChallengeRepo/"
+	challengeKey = "two_factor_challenge:"
+)
+
+// repository keeps the logins waiting for a second factor, keyed by the hash
+// of the challenge token.
+type repository struct {
+	cache cacherepo.OneTimeCache
+	ttl   time.Duration
+}
+
+func New(cache cacherepo.OneTimeCache, ttl time.Duration) *repository {
+	return &repository{
+		cache: cache,
+		ttl:   ttl,
+	}
+}
+
+func (r *repository) SaveChallenge(ctx context.Context, challengeToken string, challenge *models.TwoFactorChallenge) error {
+	op := pkg + "SaveChallenge"
+
+	challengeJSON, err := json.Marshal(challenge)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	err = r.cache.Set(ctx, challengeKey+token.Hash(challengeToken), string(challengeJSON), r.ttl)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// Challenge returns the challenge without using it up, so that a mistyped
+// code can be entered again.
+func (r *repository) Challenge(ctx context.Context, challengeToken string) (*models.TwoFactorChallenge, error) {
+	op := pkg + "Challenge"
+
+	challengeJSON, err := r.cache.Get(ctx, challengeKey+token.Hash(challengeToken))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return unmarshalChallenge(op, challengeJSON)
+}
+
+// ConsumeChallenge removes the challenge once it has been passed. Of two
+// concurrent calls only one gets the challenge.
+func (r *repository) ConsumeChallenge(ctx context.Context, challengeToken string) (*models.TwoFactorChallenge, error) {
+	op := pkg + "ConsumeChallenge"
+
+	challengeJSON, err := r.cache.GetDel(ctx, challengeKey+token.Hash(challengeToken))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return unmarshalChallenge(op, challengeJSON)
+}
+
+func unmarshalChallenge(op string, challengeJSON string) (*models.TwoFactorChallenge, error) {
+	if challengeJSON == "" {
+		return nil, models.ErrInvalidChallenge
+	}
+
+	var challenge models.TwoFactorChallenge
+
+	if err := json.Unmarshal([]byte(challengeJSON), &challenge); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &challenge, nil
+}
@@ -0,0 +1,75 @@
+package cachechallengerepo
+
+import (
+	"context"
+	"marketplace/internal/models"
+	"marketplace/internal/utils/token"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockCache struct {
+	mock.Mock
+}
+
+func (m *mockCache) Get(ctx context.Context, key string) (string, error) {
+	args := m.Called(ctx, key)
+	return args.Get(0).(string), args.Error(1)
+}
+
+func (m *mockCache) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+	args := m.Called(ctx, key, value, expiration)
+	return args.Error(0)
+}
+
+func (m *mockCache) Del(ctx context.Context, keys ...string) error {
+	args := m.Called(ctx, keys)
+	return args.Error(0)
+}
+
+func (m *mockCache) GetDel(ctx context.Context, key string) (string, error) {
+	args := m.Called(ctx, key)
+	return args.Get(0).(string), args.Error(1)
+}
+
+func TestSaveChallenge_StoresHash(t *testing.T) {
+	t.Parallel()
+
+	mockCache := new(mockCache)
+
+	mockCache.On("Set", mock.Anything, "two_factor_challenge:"+token.Hash("challenge"), `{"user_id":"u1","login":"alice","created_at":"2025-01-01T00:00:00Z"}`, 5*time.Minute).
+		Return(nil)
+
+	repo := New(mockCache, 5*time.Minute)
+
+	err := repo.SaveChallenge(context.Background(), "challenge", &models.TwoFactorChallenge{
+		UserID:    "u1",
+		Login:     "alice",
+		CreatedAt: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+	})
+	assert.NoError(t, err)
+	mockCache.AssertExpectations(t)
+}
+
+func TestConsumeChallenge(t *testing.T) {
+	t.Parallel()
+
+	mockCache := new(mockCache)
+
+	mockCache.On("GetDel", mock.Anything, "two_factor_challenge:"+token.Hash("challenge")).
+		Return(`{"user_id":"u1","login":"alice"}`, nil).Once()
+	mockCache.On("GetDel", mock.Anything, "two_factor_challenge:"+token.Hash("challenge")).
+		Return("", nil).Once()
+
+	repo := New(mockCache, 5*time.Minute)
+
+	challenge, err := repo.ConsumeChallenge(context.Background(), "challenge")
+	assert.NoError(t, err)
+	assert.Equal(t, "u1", challenge.UserID)
+
+	_, err = repo.ConsumeChallenge(context.Background(), "challenge")
+	assert.ErrorIs(t, err, models.ErrInvalidChallenge)
+}
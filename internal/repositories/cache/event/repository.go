@@ -0,0 +1,133 @@
+package cacheeventrepo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"marketplace/internal/models"
+	cacherepo "marketplace/internal/repositories/cache"
+	"strconv"
+	"time"
+)
+
+const pkg = "cacheEventRepo/"
+
+type repository struct {
+	cache       cacherepo.EventCache
+	historySize int64
+	historyTTL  time.Duration
+}
+
+func New(
+	cache cacherepo.EventCache,
+	historySize int64,
+	historyTTL time.Duration,
+) *repository {
+	return &repository{
+		cache:       cache,
+		historySize: historySize,
+		historyTTL:  historyTTL,
+	}
+}
+
+// SaveEvent assigns the next per-user sequence number to the event, keeps it in
+// the capped per-user history used for resuming and publishes it to every
+// replica subscribed to the user channel.
+func (r *repository) SaveEvent(ctx context.Context, event *models.Event) error {
+	op := pkg + "SaveEvent"
+
+	seq, err := r.cache.Incr(ctx, seqKey(event.UserID))
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	event.ID = strconv.FormatInt(seq, 10)
+
+	eventJSON, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := r.cache.ZAdd(ctx, historyKey(event.UserID), float64(seq), string(eventJSON)); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := r.cache.ZRemRangeByRank(ctx, historyKey(event.UserID), 0, -r.historySize-1); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := r.cache.Expire(ctx, historyKey(event.UserID), r.historyTTL); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := r.cache.Expire(ctx, seqKey(event.UserID), r.historyTTL); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := r.cache.Publish(ctx, channel(event.UserID), string(eventJSON)); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (r *repository) EventsAfter(ctx context.Context, userID string, afterID int64) ([]*models.Event, error) {
+	op := pkg + "EventsAfter"
+
+	rawEvents, err := r.cache.ZRangeByScore(ctx, historyKey(userID), "("+strconv.FormatInt(afterID, 10), "+inf")
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	events := make([]*models.Event, 0, len(rawEvents))
+
+	for _, rawEvent := range rawEvents {
+		var event models.Event
+		if err := json.Unmarshal([]byte(rawEvent), &event); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		events = append(events, &event)
+	}
+
+	return events, nil
+}
+
+func (r *repository) Subscribe(ctx context.Context, userID string) (<-chan *models.Event, func() error, error) {
+	op := pkg + "Subscribe"
+
+	messages, unsubscribe, err := r.cache.Subscribe(ctx, channel(userID))
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	events := make(chan *models.Event)
+
+	go func() {
+		defer close(events)
+		for message := range messages {
+			var event models.Event
+			if err := json.Unmarshal([]byte(message), &event); err != nil {
+				continue
+			}
+			select {
+			case events <- &event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return events, unsubscribe, nil
+}
+
+func seqKey(userID string) string {
+	return "events:" + userID + ":seq"
+}
+
+func historyKey(userID string) string {
+	return "events:" + userID + ":history"
+}
+
+func channel(userID string) string {
+	return "events:" + userID
+}
@@ -0,0 +1,137 @@
+package cacheeventrepo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"marketplace/internal/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockCache struct {
+	mock.Mock
+}
+
+func (m *mockCache) Incr(ctx context.Context, key string) (int64, error) {
+	args := m.Called(ctx, key)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *mockCache) Expire(ctx context.Context, key string, expiration time.Duration) error {
+	args := m.Called(ctx, key, expiration)
+	return args.Error(0)
+}
+
+func (m *mockCache) ZAdd(ctx context.Context, key string, score float64, member string) error {
+	args := m.Called(ctx, key, score, member)
+	return args.Error(0)
+}
+
+func (m *mockCache) ZRangeByScore(ctx context.Context, key string, min string, max string) ([]string, error) {
+	args := m.Called(ctx, key, min, max)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *mockCache) ZRemRangeByRank(ctx context.Context, key string, start int64, stop int64) error {
+	args := m.Called(ctx, key, start, stop)
+	return args.Error(0)
+}
+
+func (m *mockCache) Publish(ctx context.Context, channel string, message string) error {
+	args := m.Called(ctx, channel, message)
+	return args.Error(0)
+}
+
+func (m *mockCache) Subscribe(ctx context.Context, channel string) (<-chan string, func() error, error) {
+	args := m.Called(ctx, channel)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(1)
+	}
+	return args.Get(0).(chan string), func() error { return nil }, args.Error(1)
+}
+
+func TestSaveEvent_Success(t *testing.T) {
+	t.Parallel()
+
+	mockCache := new(mockCache)
+
+	event := &models.Event{UserID: "u1", Type: models.EventPostCreated, Payload: json.RawMessage(`{}`)}
+
+	mockCache.On("Incr", mock.Anything, "events:u1:seq").Return(int64(7), nil)
+	mockCache.On("ZAdd", mock.Anything, "events:u1:history", float64(7), mock.Anything).Return(nil)
+	mockCache.On("ZRemRangeByRank", mock.Anything, "events:u1:history", int64(0), int64(-11)).Return(nil)
+	mockCache.On("Expire", mock.Anything, "events:u1:history", time.Hour).Return(nil)
+	mockCache.On("Expire", mock.Anything, "events:u1:seq", time.Hour).Return(nil)
+	mockCache.On("Publish", mock.Anything, "events:u1", mock.Anything).Return(nil)
+
+	repo := New(mockCache, 10, time.Hour)
+
+	err := repo.SaveEvent(context.Background(), event)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "7", event.ID)
+	mockCache.AssertExpectations(t)
+}
+
+func TestSaveEvent_IncrFails(t *testing.T) {
+	t.Parallel()
+
+	mockCache := new(mockCache)
+
+	mockCache.On("Incr", mock.Anything, "events:u1:seq").Return(int64(0), errors.New("redis down"))
+
+	repo := New(mockCache, 10, time.Hour)
+
+	err := repo.SaveEvent(context.Background(), &models.Event{UserID: "u1"})
+
+	assert.Error(t, err)
+	mockCache.AssertExpectations(t)
+}
+
+func TestEventsAfter_Success(t *testing.T) {
+	t.Parallel()
+
+	mockCache := new(mockCache)
+
+	mockCache.On("ZRangeByScore", mock.Anything, "events:u1:history", "(3", "+inf").
+		Return([]string{`{"id":"4","user_id":"u1","type":"post_created","payload":{}}`}, nil)
+
+	repo := New(mockCache, 10, time.Hour)
+
+	events, err := repo.EventsAfter(context.Background(), "u1", 3)
+
+	assert.NoError(t, err)
+	assert.Len(t, events, 1)
+	assert.Equal(t, "4", events[0].ID)
+	assert.Equal(t, models.EventPostCreated, events[0].Type)
+	mockCache.AssertExpectations(t)
+}
+
+func TestSubscribe_DecodesMessages(t *testing.T) {
+	t.Parallel()
+
+	mockCache := new(mockCache)
+
+	messages := make(chan string, 2)
+	messages <- `broken`
+	messages <- `{"id":"1","user_id":"u1","type":"post_deleted","payload":{}}`
+	close(messages)
+
+	mockCache.On("Subscribe", mock.Anything, "events:u1").Return(messages, nil)
+
+	repo := New(mockCache, 10, time.Hour)
+
+	events, _, err := repo.Subscribe(context.Background(), "u1")
+	assert.NoError(t, err)
+
+	event := <-events
+	assert.Equal(t, "1", event.ID)
+	assert.Equal(t, models.EventPostDeleted, event.Type)
+
+	_, ok := <-events
+	assert.False(t, ok)
+}
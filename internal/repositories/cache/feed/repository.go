@@ -0,0 +1,67 @@
+package cachefeedrepo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"marketplace/internal/models"
+	cacherepo "marketplace/internal/repositories/cache"
+)
+
+const (
+	pkg          = "cacheFeedRepo/"
+	postsChannel = "feed:posts"
+)
+
+type repository struct {
+	cache cacherepo.PubSub
+}
+
+func New(cache cacherepo.PubSub) *repository {
+	return &repository{
+		cache: cache,
+	}
+}
+
+func (r *repository) PublishPost(ctx context.Context, post *models.PostWithDocument) error {
+	op := pkg + "PublishPost"
+
+	postJSON, err := json.Marshal(post)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := r.cache.Publish(ctx, postsChannel, string(postJSON)); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (r *repository) SubscribePosts(ctx context.Context) (<-chan *models.PostWithDocument, func() error, error) {
+	op := pkg + "SubscribePosts"
+
+	messages, unsubscribe, err := r.cache.Subscribe(ctx, postsChannel)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	posts := make(chan *models.PostWithDocument)
+
+	go func() {
+		defer close(posts)
+		for message := range messages {
+			var post models.PostWithDocument
+			if err := json.Unmarshal([]byte(message), &post); err != nil {
+				continue
+			}
+			select {
+			case posts <- &post:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return posts, unsubscribe, nil
+}
@@ -0,0 +1,83 @@
+package cachefeedrepo
+
+import (
+	"context"
+	"errors"
+	"marketplace/internal/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockPubSub struct {
+	mock.Mock
+}
+
+func (m *mockPubSub) Publish(ctx context.Context, channel string, message string) error {
+	args := m.Called(ctx, channel, message)
+	return args.Error(0)
+}
+
+func (m *mockPubSub) Subscribe(ctx context.Context, channel string) (<-chan string, func() error, error) {
+	args := m.Called(ctx, channel)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(1)
+	}
+	return args.Get(0).(chan string), func() error { return nil }, args.Error(1)
+}
+
+func TestPublishPost_Success(t *testing.T) {
+	t.Parallel()
+
+	mockPubSub := new(mockPubSub)
+
+	mockPubSub.On("Publish", mock.Anything, "feed:posts", `{"id":"p1","owner_login":"seller","header":"h","text":"t","price":100}`).
+		Return(nil)
+
+	repo := New(mockPubSub)
+
+	err := repo.PublishPost(context.Background(), &models.PostWithDocument{ID: "p1", OwnerLogin: "seller", Header: "h", Text: "t", Price: 100})
+
+	assert.NoError(t, err)
+	mockPubSub.AssertExpectations(t)
+}
+
+func TestPublishPost_Fail(t *testing.T) {
+	t.Parallel()
+
+	mockPubSub := new(mockPubSub)
+
+	mockPubSub.On("Publish", mock.Anything, "feed:posts", mock.Anything).Return(errors.New("redis down"))
+
+	repo := New(mockPubSub)
+
+	err := repo.PublishPost(context.Background(), &models.PostWithDocument{ID: "p1"})
+
+	assert.Error(t, err)
+}
+
+func TestSubscribePosts_DecodesMessages(t *testing.T) {
+	t.Parallel()
+
+	mockPubSub := new(mockPubSub)
+
+	messages := make(chan string, 2)
+	messages <- `broken`
+	messages <- `{"id":"p1","price":100}`
+	close(messages)
+
+	mockPubSub.On("Subscribe", mock.Anything, "feed:posts").Return(messages, nil)
+
+	repo := New(mockPubSub)
+
+	posts, _, err := repo.SubscribePosts(context.Background())
+	assert.NoError(t, err)
+
+	post := <-posts
+	assert.Equal(t, "p1", post.ID)
+	assert.Equal(t, int64(100), post.Price)
+
+	_, ok := <-posts
+	assert.False(t, ok)
+}
@@ -0,0 +1,56 @@
+package cachefeedticketrepo
+
+import (
+	"context"
+	"fmt"
+	"marketplace/internal/models"
+	cacherepo "marketplace/internal/repositories/cache"
+	"marketplace/internal/utils/token"
+	"time"
+)
+
+const (
+	pkg       = "cacheFeedTicketRepo/"
+	ticketKey = "feed_ticket:"
+)
+
+// repository keeps the user each live feed ticket was issued to, keyed by the
+// hash of the ticket.
+type repository struct {
+	cache cacherepo.OneTimeCache
+	ttl   time.Duration
+}
+
+func New(cache cacherepo.OneTimeCache, ttl time.Duration) *repository {
+	return &repository{
+		cache: cache,
+		ttl:   ttl,
+	}
+}
+
+func (r *repository) SaveTicket(ctx context.Context, ticket string, userID string) error {
+	op := pkg + "SaveTicket"
+
+	if err := r.cache.Set(ctx, ticketKey+token.Hash(ticket), userID, r.ttl); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// ConsumeTicket removes the ticket and returns the ID of its user. Of two
+// concurrent calls only one gets the user.
+func (r *repository) ConsumeTicket(ctx context.Context, ticket string) (string, error) {
+	op := pkg + "ConsumeTicket"
+
+	userID, err := r.cache.GetDel(ctx, ticketKey+token.Hash(ticket))
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	if userID == "" {
+		return "", models.ErrInvalidFeedTicket
+	}
+
+	return userID, nil
+}
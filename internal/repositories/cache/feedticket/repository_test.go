@@ -0,0 +1,76 @@
+package cachefeedticketrepo
+
+import (
+	"context"
+	"marketplace/internal/models"
+	"marketplace/internal/utils/token"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockCache struct {
+	mock.Mock
+}
+
+func (m *mockCache) Get(ctx context.Context, key string) (string, error) {
+	args := m.Called(ctx, key)
+	return args.Get(0).(string), args.Error(1)
+}
+
+func (m *mockCache) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+	args := m.Called(ctx, key, value, expiration)
+	return args.Error(0)
+}
+
+func (m *mockCache) Del(ctx context.Context, keys ...string) error {
+	args := m.Called(ctx, keys)
+	return args.Error(0)
+}
+
+func (m *mockCache) GetDel(ctx context.Context, key string) (string, error) {
+	args := m.Called(ctx, key)
+	return args.Get(0).(string), args.Error(1)
+}
+
+func TestSaveTicket_StoresHash(t *testing.T) {
+	t.Parallel()
+
+	mockCache := new(mockCache)
+
+	mockCache.On("Set", mock.Anything, "feed_ticket:"+token.Hash("ticket"), "u1", 30*time.Second).Return(nil)
+
+	err := New(mockCache, 30*time.Second).SaveTicket(context.Background(), "ticket", "u1")
+
+	assert.NoError(t, err)
+	mockCache.AssertExpectations(t)
+}
+
+func TestConsumeTicket(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		stored  string
+		wantErr error
+	}{
+		{name: "valid", stored: "u1"},
+		{name: "used or expired", wantErr: models.ErrInvalidFeedTicket},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockCache := new(mockCache)
+			mockCache.On("GetDel", mock.Anything, "feed_ticket:"+token.Hash("ticket")).Return(tt.stored, nil)
+
+			userID, err := New(mockCache, time.Minute).ConsumeTicket(context.Background(), "ticket")
+
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Equal(t, tt.stored, userID)
+		})
+	}
+}
@@ -10,3 +10,65 @@ type Cache interface {
 	Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error
 	Del(ctx context.Context, keys ...string) error
 }
+
+// SessionCache keeps sets of session keys per user and per token family, so
+// that sessions can be found and revoked together, and a session version
+// counter per user.
+type SessionCache interface {
+	OneTimeCache
+	CounterCache
+	SAdd(ctx context.Context, key string, members ...string) error
+	SRem(ctx context.Context, key string, members ...string) error
+	SMembers(ctx context.Context, key string) ([]string, error)
+}
+
+// OneTimeCache can read and remove a key in one step, for values that must be
+// used at most once.
+type OneTimeCache interface {
+	Cache
+	GetDel(ctx context.Context, key string) (string, error)
+}
+
+// CounterCache counts hits per key within a window that starts with the
+// first hit.
+type CounterCache interface {
+	Incr(ctx context.Context, key string) (int64, error)
+	Expire(ctx context.Context, key string, expiration time.Duration) error
+}
+
+// AttemptCache counts failed attempts and keeps the time a key is blocked
+// until.
+type AttemptCache interface {
+	Cache
+	CounterCache
+}
+
+// PostCache keeps pages of posts and a version counter that moves all of them
+// out of use at once.
+type PostCache interface {
+	Cache
+	CounterCache
+}
+
+// JobCache keeps jobs and the set of jobs that are not finished yet, so that
+// they can be picked up again after a restart.
+type JobCache interface {
+	Cache
+	SAdd(ctx context.Context, key string, members ...string) error
+	SRem(ctx context.Context, key string, members ...string) error
+	SMembers(ctx context.Context, key string) ([]string, error)
+}
+
+type EventCache interface {
+	Incr(ctx context.Context, key string) (int64, error)
+	Expire(ctx context.Context, key string, expiration time.Duration) error
+	ZAdd(ctx context.Context, key string, score float64, member string) error
+	ZRangeByScore(ctx context.Context, key string, min string, max string) ([]string, error)
+	ZRemRangeByRank(ctx context.Context, key string, start int64, stop int64) error
+	PubSub
+}
+
+type PubSub interface {
+	Publish(ctx context.Context, channel string, message string) error
+	Subscribe(ctx context.Context, channel string) (<-chan string, func() error, error)
+}
@@ -0,0 +1,138 @@
+package cachejobrepo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"marketplace/internal/models"
+	cacherepo "marketplace/internal/repositories/cache"
+	"slices"
+	"time"
+)
+
+const (
+	pkg           = "cacheJobRepo/"
+	jobKey        = "job:"
+	lastExportKey = "last_export:"
+	activeJobsKey = "active_jobs"
+)
+
+// repository keeps background jobs for a while after they are created, so
+// that their status can be polled. The latest export of each user is
+// remembered to serve its archive, and unfinished jobs are listed to resume
+// them after a restart.
+type repository struct {
+	cache cacherepo.JobCache
+	ttl   time.Duration
+}
+
+func New(cache cacherepo.JobCache, ttl time.Duration) *repository {
+	return &repository{
+		cache: cache,
+		ttl:   ttl,
+	}
+}
+
+func (r *repository) SaveJob(ctx context.Context, job *models.Job) error {
+	op := pkg + "SaveJob"
+
+	jobJSON, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	err = r.cache.Set(ctx, jobKey+job.ID, string(jobJSON), r.ttl)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if job.Kind == models.JobExport {
+		err = r.cache.Set(ctx, lastExportKey+job.UserID, job.ID, r.ttl)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	if job.Active() {
+		err = r.cache.SAdd(ctx, activeJobsKey, job.ID)
+	} else {
+		err = r.cache.SRem(ctx, activeJobsKey, job.ID)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (r *repository) Job(ctx context.Context, id string) (*models.Job, error) {
+	op := pkg + "Job"
+
+	jobJSON, err := r.cache.Get(ctx, jobKey+id)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if jobJSON == "" {
+		return nil, models.ErrJobNotFound
+	}
+
+	var job models.Job
+	if err := json.Unmarshal([]byte(jobJSON), &job); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &job, nil
+}
+
+// LatestExport returns the export the user requested last.
+func (r *repository) LatestExport(ctx context.Context, userID string) (*models.Job, error) {
+	op := pkg + "LatestExport"
+
+	id, err := r.cache.Get(ctx, lastExportKey+userID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if id == "" {
+		return nil, models.ErrJobNotFound
+	}
+
+	return r.Job(ctx, id)
+}
+
+// ActiveJobs returns the jobs that are still pending or running, oldest
+// first. Jobs that expired meanwhile are dropped from the list.
+func (r *repository) ActiveJobs(ctx context.Context) ([]*models.Job, error) {
+	op := pkg + "ActiveJobs"
+
+	ids, err := r.cache.SMembers(ctx, activeJobsKey)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	jobs := make([]*models.Job, 0, len(ids))
+
+	for _, id := range ids {
+		job, err := r.Job(ctx, id)
+		if err != nil && !errors.Is(err, models.ErrJobNotFound) {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		if job == nil || !job.Active() {
+			if err := r.cache.SRem(ctx, activeJobsKey, id); err != nil {
+				return nil, fmt.Errorf("%s: %w", op, err)
+			}
+			continue
+		}
+
+		jobs = append(jobs, job)
+	}
+
+	slices.SortFunc(jobs, func(a, b *models.Job) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+
+	return jobs, nil
+}
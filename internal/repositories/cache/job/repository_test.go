@@ -0,0 +1,147 @@
+package cachejobrepo
+
+import (
+	"context"
+	"marketplace/internal/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockCache struct {
+	mock.Mock
+}
+
+func (m *mockCache) Get(ctx context.Context, key string) (string, error) {
+	args := m.Called(ctx, key)
+	return args.Get(0).(string), args.Error(1)
+}
+
+func (m *mockCache) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+	args := m.Called(ctx, key, value, expiration)
+	return args.Error(0)
+}
+
+func (m *mockCache) Del(ctx context.Context, keys ...string) error {
+	args := m.Called(ctx, keys)
+	return args.Error(0)
+}
+
+func (m *mockCache) SAdd(ctx context.Context, key string, members ...string) error {
+	args := m.Called(ctx, key, members)
+	return args.Error(0)
+}
+
+func (m *mockCache) SRem(ctx context.Context, key string, members ...string) error {
+	args := m.Called(ctx, key, members)
+	return args.Error(0)
+}
+
+func (m *mockCache) SMembers(ctx context.Context, key string) ([]string, error) {
+	args := m.Called(ctx, key)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func TestSaveJob_ExportRemembered(t *testing.T) {
+	t.Parallel()
+
+	cache := new(mockCache)
+	cache.On("Set", mock.Anything, "job:job-1", mock.Anything, time.Hour).Return(nil)
+	cache.On("Set", mock.Anything, "last_export:user-1", "job-1", time.Hour).Return(nil)
+	cache.On("SAdd", mock.Anything, "active_jobs", []string{"job-1"}).Return(nil)
+
+	repo := New(cache, time.Hour)
+
+	err := repo.SaveJob(context.Background(), &models.Job{ID: "job-1", UserID: "user-1", Kind: models.JobExport, Status: models.JobPending})
+	assert.NoError(t, err)
+	cache.AssertExpectations(t)
+}
+
+func TestSaveJob_DeletionNotRemembered(t *testing.T) {
+	t.Parallel()
+
+	cache := new(mockCache)
+	cache.On("Set", mock.Anything, "job:job-1", mock.Anything, time.Hour).Return(nil)
+	cache.On("SAdd", mock.Anything, "active_jobs", []string{"job-1"}).Return(nil)
+
+	repo := New(cache, time.Hour)
+
+	err := repo.SaveJob(context.Background(), &models.Job{ID: "job-1", UserID: "user-1", Kind: models.JobDeletion, Status: models.JobPending})
+	assert.NoError(t, err)
+	cache.AssertNumberOfCalls(t, "Set", 1)
+}
+
+func TestLatestExport(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		id      string
+		jobJSON string
+		wantErr error
+	}{
+		{name: "found", id: "job-1", jobJSON: `{"id":"job-1","user_id":"user-1","kind":"export","status":"done","path":"exports/job-1.zip"}`},
+		{name: "never exported", wantErr: models.ErrJobNotFound},
+		{name: "job expired", id: "job-1", wantErr: models.ErrJobNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cache := new(mockCache)
+			cache.On("Get", mock.Anything, "last_export:user-1").Return(tt.id, nil)
+			cache.On("Get", mock.Anything, "job:job-1").Return(tt.jobJSON, nil)
+
+			repo := New(cache, time.Hour)
+
+			job, err := repo.LatestExport(context.Background(), "user-1")
+
+			assert.ErrorIs(t, err, tt.wantErr)
+			if tt.wantErr == nil {
+				assert.Equal(t, models.JobDone, job.Status)
+				assert.Equal(t, "exports/job-1.zip", job.Path)
+			}
+		})
+	}
+}
+
+func TestSaveJob_FinishedNotActive(t *testing.T) {
+	t.Parallel()
+
+	cache := new(mockCache)
+	cache.On("Set", mock.Anything, "job:job-1", mock.Anything, time.Hour).Return(nil)
+	cache.On("SRem", mock.Anything, "active_jobs", []string{"job-1"}).Return(nil)
+
+	repo := New(cache, time.Hour)
+
+	err := repo.SaveJob(context.Background(), &models.Job{ID: "job-1", UserID: "user-1", Kind: models.JobDeletion, Status: models.JobDone})
+	assert.NoError(t, err)
+	cache.AssertExpectations(t)
+	cache.AssertNotCalled(t, "SAdd", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestActiveJobs(t *testing.T) {
+	t.Parallel()
+
+	cache := new(mockCache)
+	cache.On("SMembers", mock.Anything, "active_jobs").Return([]string{"job-2", "job-1", "job-3", "job-4"}, nil)
+	cache.On("Get", mock.Anything, "job:job-1").Return(`{"id":"job-1","kind":"export","status":"running","created_at":"2026-01-01T10:00:00Z"}`, nil)
+	cache.On("Get", mock.Anything, "job:job-2").Return(`{"id":"job-2","kind":"deletion","status":"pending","created_at":"2026-01-01T11:00:00Z"}`, nil)
+	cache.On("Get", mock.Anything, "job:job-3").Return("", nil)
+	cache.On("Get", mock.Anything, "job:job-4").Return(`{"id":"job-4","kind":"export","status":"done"}`, nil)
+	cache.On("SRem", mock.Anything, "active_jobs", []string{"job-3"}).Return(nil)
+	cache.On("SRem", mock.Anything, "active_jobs", []string{"job-4"}).Return(nil)
+
+	repo := New(cache, time.Hour)
+
+	jobs, err := repo.ActiveJobs(context.Background())
+	assert.NoError(t, err)
+	if assert.Len(t, jobs, 2) {
+		assert.Equal(t, "job-1", jobs[0].ID)
+		assert.Equal(t, "job-2", jobs[1].ID)
+	}
+	cache.AssertExpectations(t)
+}
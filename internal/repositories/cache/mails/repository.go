@@ -0,0 +1,46 @@
+package cachemailsrepo
+
+import (
+	"context"
+	"fmt"
+	cacherepo "marketplace/internal/repositories/cache"
+	"time"
+)
+
+const (
+	pkg      = "cacheMailsRepo/"
+	mailsKey = "mails_sent:"
+)
+
+// repository counts mails sent per key, such as a user, a login or a client
+// IP, so that every kind of mail on request shares one limit.
+type repository struct {
+	cache  cacherepo.CounterCache
+	window time.Duration
+}
+
+func New(cache cacherepo.CounterCache, window time.Duration) *repository {
+	return &repository{
+		cache:  cache,
+		window: window,
+	}
+}
+
+// CountMail registers a mail for the key and returns how many were sent in
+// the current window, this one included.
+func (r *repository) CountMail(ctx context.Context, key string) (int64, error) {
+	op := pkg + "CountMail"
+
+	count, err := r.cache.Incr(ctx, mailsKey+key)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if count == 1 {
+		if err := r.cache.Expire(ctx, mailsKey+key, r.window); err != nil {
+			return 0, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	return count, nil
+}
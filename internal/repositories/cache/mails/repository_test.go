@@ -0,0 +1,60 @@
+package cachemailsrepo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockCache struct {
+	mock.Mock
+}
+
+func (m *mockCache) Incr(ctx context.Context, key string) (int64, error) {
+	args := m.Called(ctx, key)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *mockCache) Expire(ctx context.Context, key string, expiration time.Duration) error {
+	args := m.Called(ctx, key, expiration)
+	return args.Error(0)
+}
+
+func TestCountMail(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		count      int64
+		wantExpire bool
+	}{
+		{name: "first in window starts it", count: 1, wantExpire: true},
+		{name: "later in window keeps it", count: 2, wantExpire: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockCache := new(mockCache)
+
+			mockCache.On("Incr", mock.Anything, "mails_sent:verify:1").Return(tt.count, nil)
+			mockCache.On("Expire", mock.Anything, "mails_sent:verify:1", time.Hour).Return(nil)
+
+			repo := New(mockCache, time.Hour)
+
+			count, err := repo.CountMail(context.Background(), "verify:1")
+			assert.NoError(t, err)
+			assert.Equal(t, tt.count, count)
+
+			if tt.wantExpire {
+				mockCache.AssertCalled(t, "Expire", mock.Anything, "mails_sent:verify:1", time.Hour)
+			} else {
+				mockCache.AssertNotCalled(t, "Expire", mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package cacheoidcstaterepo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"marketplace/internal/models"
+	cacherepo "marketplace/internal/repositories/cache"
+	"marketplace/internal/utils/token"
+	"time"
+)
+
+const (
+	pkg      = "cacheOIDCStateRepo/"
+	stateKey = "oidc_state:"
+)
+
+// repository keeps the PKCE verifier and nonce of a login started at an
+// identity provider until its callback, keyed by the hash of the state.
+type repository struct {
+	cache cacherepo.OneTimeCache
+	ttl   time.Duration
+}
+
+func New(cache cacherepo.OneTimeCache, ttl time.Duration) *repository {
+	return &repository{
+		cache: cache,
+		ttl:   ttl,
+	}
+}
+
+func (r *repository) SaveState(ctx context.Context, state string, oidcState *models.OIDCState) error {
+	op := pkg + "SaveState"
+
+	stateJSON, err := json.Marshal(oidcState)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	err = r.cache.Set(ctx, stateKey+token.Hash(state), string(stateJSON), r.ttl)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// ConsumeState returns the state and removes it, so that a callback can not
+// be replayed.
+func (r *repository) ConsumeState(ctx context.Context, state string) (*models.OIDCState, error) {
+	op := pkg + "ConsumeState"
+
+	stateJSON, err := r.cache.GetDel(ctx, stateKey+token.Hash(state))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if stateJSON == "" {
+		return nil, models.ErrInvalidOIDCState
+	}
+
+	var oidcState models.OIDCState
+	if err := json.Unmarshal([]byte(stateJSON), &oidcState); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &oidcState, nil
+}
@@ -0,0 +1,77 @@
+package cacheoidcstaterepo
+
+import (
+	"context"
+	"marketplace/internal/models"
+	"marketplace/internal/utils/token"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockCache struct {
+	mock.Mock
+}
+
+func (m *mockCache) Get(ctx context.Context, key string) (string, error) {
+	args := m.Called(ctx, key)
+	return args.Get(0).(string), args.Error(1)
+}
+
+func (m *mockCache) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+	args := m.Called(ctx, key, value, expiration)
+	return args.Error(0)
+}
+
+func (m *mockCache) Del(ctx context.Context, keys ...string) error {
+	args := m.Called(ctx, keys)
+	return args.Error(0)
+}
+
+func (m *mockCache) GetDel(ctx context.Context, key string) (string, error) {
+	args := m.Called(ctx, key)
+	return args.Get(0).(string), args.Error(1)
+}
+
+func TestSaveState_StoresHash(t *testing.T) {
+	t.Parallel()
+
+	mockCache := new(mockCache)
+
+	mockCache.On("Set", mock.Anything, "oidc_state:"+token.Hash("state"), `{"provider":"google","verifier":"v","nonce":"n","created_at":"2025-01-01T00:00:00Z"}`, 10*time.Minute).
+		Return(nil)
+
+	repo := New(mockCache, 10*time.Minute)
+
+	err := repo.SaveState(context.Background(), "state", &models.OIDCState{
+		Provider:  "google",
+		Verifier:  "v",
+		Nonce:     "n",
+		CreatedAt: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+	})
+	assert.NoError(t, err)
+	mockCache.AssertExpectations(t)
+}
+
+func TestConsumeState(t *testing.T) {
+	t.Parallel()
+
+	mockCache := new(mockCache)
+
+	mockCache.On("GetDel", mock.Anything, "oidc_state:"+token.Hash("state")).
+		Return(`{"provider":"google","verifier":"v","nonce":"n","user_id":"u1"}`, nil).Once()
+	mockCache.On("GetDel", mock.Anything, "oidc_state:"+token.Hash("state")).
+		Return("", nil).Once()
+
+	repo := New(mockCache, 10*time.Minute)
+
+	state, err := repo.ConsumeState(context.Background(), "state")
+	assert.NoError(t, err)
+	assert.Equal(t, "u1", state.UserID)
+	assert.Equal(t, "v", state.Verifier)
+
+	_, err = repo.ConsumeState(context.Background(), "state")
+	assert.ErrorIs(t, err, models.ErrInvalidOIDCState)
+}
@@ -4,17 +4,21 @@ import (
 	"context"
 	"fmt"
 	cacherepo "marketplace/internal/repositories/cache"
+	"strconv"
 	"time"
 )
 
-const pkg = "cachePostRepo/"
+const (
+	pkg            = "cachePostRepo/"
+	listVersionKey = "posts_version"
+)
 
 type repository struct {
-	cache   cacherepo.Cache
+	cache   cacherepo.PostCache
 	postTTL time.Duration
 }
 
-func New(cache cacherepo.Cache, postTTL time.Duration) *repository {
+func New(cache cacherepo.PostCache, postTTL time.Duration) *repository {
 	return &repository{
 		cache:   cache,
 		postTTL: postTTL,
@@ -53,3 +57,37 @@ func (r *repository) Del(ctx context.Context, keys ...string) error {
 
 	return nil
 }
+
+// ListVersion returns the current version of the cached pages of posts, which
+// is part of their keys.
+func (r *repository) ListVersion(ctx context.Context) (int64, error) {
+	op := pkg + "ListVersion"
+
+	version, err := r.cache.Get(ctx, listVersionKey)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if version == "" {
+		return 0, nil
+	}
+
+	n, err := strconv.ParseInt(version, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return n, nil
+}
+
+// InvalidateLists moves every cached page of posts out of use, for changes
+// that may show up on any page. The old pages expire on their own.
+func (r *repository) InvalidateLists(ctx context.Context) error {
+	op := pkg + "InvalidateLists"
+
+	if _, err := r.cache.Incr(ctx, listVersionKey); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
@@ -24,6 +24,16 @@ func (m *mockCache) Set(ctx context.Context, key string, value interface{}, expi
 	return args.Error(0)
 }
 
+func (m *mockCache) Incr(ctx context.Context, key string) (int64, error) {
+	args := m.Called(ctx, key)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *mockCache) Expire(ctx context.Context, key string, expiration time.Duration) error {
+	args := m.Called(ctx, key, expiration)
+	return args.Error(0)
+}
+
 func (m *mockCache) Del(ctx context.Context, keys ...string) error {
 	args := m.Called(ctx, keys)
 	return args.Error(0)
@@ -63,6 +73,43 @@ func TestGet_Fail(t *testing.T) {
 	mockCache.AssertExpectations(t)
 }
 
+func TestListVersion(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		cached string
+		want   int64
+	}{
+		{name: "never invalidated", cached: "", want: 0},
+		{name: "invalidated", cached: "3", want: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockCache := new(mockCache)
+			mockCache.On("Get", mock.Anything, "posts_version").Return(tt.cached, nil)
+
+			version, err := New(mockCache, time.Minute).ListVersion(context.Background())
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, version)
+		})
+	}
+}
+
+func TestInvalidateLists(t *testing.T) {
+	t.Parallel()
+
+	mockCache := new(mockCache)
+	mockCache.On("Incr", mock.Anything, "posts_version").Return(int64(4), nil)
+
+	err := New(mockCache, time.Minute).InvalidateLists(context.Background())
+	assert.NoError(t, err)
+	mockCache.AssertExpectations(t)
+}
+
 func TestSet_Success(t *testing.T) {
 	t.Parallel()
 
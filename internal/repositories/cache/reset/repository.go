@@ -0,0 +1,56 @@
+package cacheresetrepo
+
+import (
+	"context"
+	"fmt"
+	"marketplace/internal/models"
+	cacherepo "marketplace/internal/repositories/cache"
+	"time"
+)
+
+const (
+	pkg           = "cacheResetRepo/"
+	resetTokenKey = "password_reset:"
+)
+
+type repository struct {
+	cache    cacherepo.OneTimeCache
+	tokenTTL time.Duration
+}
+
+func New(cache cacherepo.OneTimeCache, tokenTTL time.Duration) *repository {
+	return &repository{
+		cache:    cache,
+		tokenTTL: tokenTTL,
+	}
+}
+
+// SaveResetToken stores the hash of a reset token for the user. The token
+// itself is never stored.
+func (r *repository) SaveResetToken(ctx context.Context, tokenHash string, userID string) error {
+	op := pkg + "SaveResetToken"
+
+	err := r.cache.Set(ctx, resetTokenKey+tokenHash, userID, r.tokenTTL)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// ConsumeResetToken returns the user the token was issued for and removes it,
+// so that a token can be used only once.
+func (r *repository) ConsumeResetToken(ctx context.Context, tokenHash string) (string, error) {
+	op := pkg + "ConsumeResetToken"
+
+	userID, err := r.cache.GetDel(ctx, resetTokenKey+tokenHash)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	if userID == "" {
+		return "", models.ErrResetTokenNotFound
+	}
+
+	return userID, nil
+}
@@ -0,0 +1,86 @@
+package cacheresetrepo
+
+import (
+	"context"
+	"errors"
+	"marketplace/internal/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockCache struct {
+	mock.Mock
+}
+
+func (m *mockCache) Get(ctx context.Context, key string) (string, error) {
+	args := m.Called(ctx, key)
+	return args.Get(0).(string), args.Error(1)
+}
+
+func (m *mockCache) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+	args := m.Called(ctx, key, value, expiration)
+	return args.Error(0)
+}
+
+func (m *mockCache) Del(ctx context.Context, keys ...string) error {
+	args := m.Called(ctx, keys)
+	return args.Error(0)
+}
+
+func (m *mockCache) GetDel(ctx context.Context, key string) (string, error) {
+	args := m.Called(ctx, key)
+	return args.Get(0).(string), args.Error(1)
+}
+
+func TestSaveResetToken(t *testing.T) {
+	t.Parallel()
+
+	mockCache := new(mockCache)
+
+	mockCache.On("Set", mock.Anything, "password_reset:hash", "1", time.Hour).
+		Return(nil)
+
+	repo := New(mockCache, time.Hour)
+
+	err := repo.SaveResetToken(context.Background(), "hash", "1")
+	assert.NoError(t, err)
+	mockCache.AssertExpectations(t)
+}
+
+func TestConsumeResetToken(t *testing.T) {
+	t.Parallel()
+
+	someErr := errors.New("some error")
+
+	tests := []struct {
+		name       string
+		cached     string
+		cacheErr   error
+		wantUserID string
+		wantErr    error
+	}{
+		{name: "valid", cached: "1", wantUserID: "1"},
+		{name: "used or expired", cached: "", wantErr: models.ErrResetTokenNotFound},
+		{name: "cache error", cacheErr: someErr, wantErr: someErr},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockCache := new(mockCache)
+
+			mockCache.On("GetDel", mock.Anything, "password_reset:hash").
+				Return(tt.cached, tt.cacheErr)
+
+			repo := New(mockCache, time.Hour)
+
+			userID, err := repo.ConsumeResetToken(context.Background(), "hash")
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Equal(t, tt.wantUserID, userID)
+		})
+	}
+}
@@ -2,62 +2,411 @@ package cachesessionrepo
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"marketplace/internal/models"
 	cacherepo "marketplace/internal/repositories/cache"
+	"marketplace/internal/utils/token"
+	"strconv"
+	"strings"
 	"time"
 )
 
-const pkg = "cacheSessionRepo/"
+const (
+	pkg               = "cacheSessionRepo/"
+	accessKey         = "access:"
+	refreshKey        = "refresh:"
+	refreshUsedKey    = "refresh_used:"
+	sessionKey        = "session:"
+	sessionKeysKey    = "session_keys:"
+	signedTokensKey   = "signed_tokens:"
+	userSessionsKey   = "user_sessions:"
+	sessionVersionKey = "session_version:"
+	deniedTokenKey    = "denied_token:"
+)
 
+// repository keeps sessions in the cache. Tokens are stored only as hashes,
+// so the keys in a cache dump cannot be used to authenticate:
+//
+//	access:<hash>            AccessGrant JSON of an opaque token, lives accessTTL
+//	refresh:<hash>           RefreshToken JSON, lives refreshTTL
+//	refresh_used:<hash>      RefreshToken JSON of an exchanged token
+//	session:<id>             Session JSON
+//	session_keys:<id>        keys of every token issued in the session
+//	signed_tokens:<id>       <jti>:<expiry> of every signed token of the session
+//	user_sessions:<user>     session IDs of the user
+//	session_version:<user>   session version of the user
+//	denied_token:<id>        marks a revoked signed token until it expires
 type repository struct {
-	cache      cacherepo.Cache
-	sessionTTL time.Duration
+	cache      cacherepo.SessionCache
+	accessTTL  time.Duration
+	refreshTTL time.Duration
 }
 
 func New(
-	cache cacherepo.Cache,
-	sessionTTL time.Duration,
+	cache cacherepo.SessionCache,
+	accessTTL time.Duration,
+	refreshTTL time.Duration,
 ) *repository {
 	return &repository{
 		cache:      cache,
-		sessionTTL: sessionTTL,
+		accessTTL:  accessTTL,
+		refreshTTL: refreshTTL,
 	}
 }
 
-func (r *repository) SaveSession(ctx context.Context, token string, userJSON string) error {
+// SaveSession stores the session record and a token pair issued in it. The
+// grant of an opaque access token is stored to look the token up by. A signed
+// token carries its grant, so only its ID and expiry are kept, to deny it by
+// when the session is revoked. The record, the sets and the version live as
+// long as the newest refresh token.
+func (r *repository) SaveSession(ctx context.Context, session *models.Session, tokens *models.TokenPair, grant *models.AccessGrant) error {
 	op := pkg + "SaveSession"
 
-	err := r.cache.Set(ctx, token, userJSON, r.sessionTTL)
+	sessionJSON, err := json.Marshal(session)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	refreshJSON, err := json.Marshal(models.RefreshToken{UserID: session.UserID, SessionID: session.ID, Version: grant.Version})
 	if err != nil {
 		return fmt.Errorf("%s: %w", op, err)
 	}
 
+	refreshHash := token.Hash(tokens.RefreshToken)
+
+	values := []struct {
+		key   string
+		value string
+		ttl   time.Duration
+	}{
+		{key: refreshKey + refreshHash, value: string(refreshJSON), ttl: r.refreshTTL},
+		{key: sessionKey + session.ID, value: string(sessionJSON), ttl: r.refreshTTL},
+	}
+
+	for _, v := range values {
+		if err := r.cache.Set(ctx, v.key, v.value, v.ttl); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	keysKey := sessionKeysKey + session.ID
+	keys := []string{refreshKey + refreshHash}
+
+	if grant.TokenID == "" {
+		grantJSON, err := json.Marshal(grant)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		accessHash := token.Hash(tokens.AccessToken)
+
+		if err := r.cache.Set(ctx, accessKey+accessHash, string(grantJSON), r.accessTTL); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		keys = append(keys, accessKey+accessHash)
+	} else {
+		member := grant.TokenID + ":" + strconv.FormatInt(grant.ExpiresAt.Unix(), 10)
+
+		if err := r.cache.SAdd(ctx, signedTokensKey+session.ID, member); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	err = r.cache.SAdd(ctx, keysKey, keys...)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	err = r.cache.SAdd(ctx, userSessionsKey+session.UserID, session.ID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	// The version must not expire before the tokens issued under it, or it
+	// would start over from zero and void the live ones.
+	for _, key := range []string{keysKey, signedTokensKey + session.ID, userSessionsKey + session.UserID, sessionVersionKey + session.UserID} {
+		if err := r.cache.Expire(ctx, key, r.refreshTTL); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
 	return nil
 }
 
-func (r *repository) DeleteSession(ctx context.Context, token string) error {
-	op := pkg + "DeleteSession"
+// SessionVersion returns the current session version of the user, zero until
+// it is first bumped.
+func (r *repository) SessionVersion(ctx context.Context, userID string) (int64, error) {
+	op := pkg + "SessionVersion"
+
+	raw, err := r.cache.Get(ctx, sessionVersionKey+userID)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
 
-	err := r.cache.Del(ctx, token)
+	if raw == "" {
+		return 0, nil
+	}
+
+	version, err := strconv.ParseInt(raw, 10, 64)
 	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return version, nil
+}
+
+// InvalidateUserSessions bumps the session version of the user, which voids
+// every token issued before, and removes the stored sessions.
+func (r *repository) InvalidateUserSessions(ctx context.Context, userID string) error {
+	op := pkg + "InvalidateUserSessions"
+
+	if _, err := r.cache.Incr(ctx, sessionVersionKey+userID); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := r.cache.Expire(ctx, sessionVersionKey+userID, r.refreshTTL); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := r.DeleteUserSessions(ctx, userID, ""); err != nil {
 		return fmt.Errorf("%s: %w", op, err)
 	}
 
 	return nil
 }
 
-func (r *repository) UserByToken(ctx context.Context, token string) (string, error) {
-	op := pkg + "UserByToken"
+// ConsumeRefreshToken exchanges a refresh token exactly once. A token that
+// was already exchanged is returned with Used set; concurrent exchanges of the
+// same token see it as used, all but one of them.
+func (r *repository) ConsumeRefreshToken(ctx context.Context, refreshToken string) (*models.RefreshToken, error) {
+	op := pkg + "ConsumeRefreshToken"
+
+	refreshHash := token.Hash(refreshToken)
+
+	refreshJSON, err := r.cache.GetDel(ctx, refreshKey+refreshHash)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if refreshJSON != "" {
+		err = r.cache.Set(ctx, refreshUsedKey+refreshHash, refreshJSON, r.refreshTTL)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		return unmarshalRefreshToken(op, refreshJSON, false)
+	}
+
+	usedJSON, err := r.cache.Get(ctx, refreshUsedKey+refreshHash)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if usedJSON == "" {
+		return nil, models.ErrSessionNotFound
+	}
+
+	return unmarshalRefreshToken(op, usedJSON, true)
+}
+
+func unmarshalRefreshToken(op string, refreshJSON string, used bool) (*models.RefreshToken, error) {
+	var refresh models.RefreshToken
+
+	if err := json.Unmarshal([]byte(refreshJSON), &refresh); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	refresh.Used = used
+
+	return &refresh, nil
+}
+
+func (r *repository) SessionByID(ctx context.Context, id string) (*models.Session, error) {
+	op := pkg + "SessionByID"
 
-	userJSON, err := r.cache.Get(ctx, token)
+	sessionJSON, err := r.cache.Get(ctx, sessionKey+id)
 	if err != nil {
-		return "", fmt.Errorf("%s: %w", op, err)
+		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
-	if userJSON == "" {
-		return "", models.ErrSessionNotFound
+	if sessionJSON == "" {
+		return nil, models.ErrSessionNotFound
+	}
+
+	var session models.Session
+
+	if err := json.Unmarshal([]byte(sessionJSON), &session); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &session, nil
+}
+
+// SessionsByUser returns the live sessions of the user. Sessions that have
+// expired are dropped from the user's set on the way.
+func (r *repository) SessionsByUser(ctx context.Context, userID string) ([]*models.Session, error) {
+	op := pkg + "SessionsByUser"
+
+	ids, err := r.cache.SMembers(ctx, userSessionsKey+userID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	sessions := make([]*models.Session, 0, len(ids))
+	expired := make([]string, 0)
+
+	for _, id := range ids {
+		session, err := r.SessionByID(ctx, id)
+		if err != nil {
+			if errors.Is(err, models.ErrSessionNotFound) {
+				expired = append(expired, id)
+				continue
+			}
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		sessions = append(sessions, session)
+	}
+
+	if len(expired) > 0 {
+		if err := r.cache.SRem(ctx, userSessionsKey+userID, expired...); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	return sessions, nil
+}
+
+// DeleteSessionByID revokes every token issued in the session.
+func (r *repository) DeleteSessionByID(ctx context.Context, userID string, id string) error {
+	op := pkg + "DeleteSessionByID"
+
+	if err := r.deleteSession(ctx, id); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	err := r.cache.SRem(ctx, userSessionsKey+userID, id)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// DeleteUserSessions removes every session of the user except exceptID,
+// which may be empty to remove them all.
+func (r *repository) DeleteUserSessions(ctx context.Context, userID string, exceptID string) error {
+	op := pkg + "DeleteUserSessions"
+
+	ids, err := r.cache.SMembers(ctx, userSessionsKey+userID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	revoked := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if id == exceptID {
+			continue
+		}
+
+		if err := r.deleteSession(ctx, id); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		revoked = append(revoked, id)
+	}
+
+	if len(revoked) == 0 {
+		return nil
+	}
+
+	err = r.cache.SRem(ctx, userSessionsKey+userID, revoked...)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (r *repository) deleteSession(ctx context.Context, id string) error {
+	keysKey := sessionKeysKey + id
+
+	keys, err := r.cache.SMembers(ctx, keysKey)
+	if err != nil {
+		return err
+	}
+
+	if err := r.denySignedTokens(ctx, id); err != nil {
+		return err
+	}
+
+	return r.cache.Del(ctx, append(keys, keysKey, signedTokensKey+id, sessionKey+id)...)
+}
+
+// denySignedTokens puts the signed access tokens of a session on the denylist
+// until they expire, as they are verified without looking up the session.
+func (r *repository) denySignedTokens(ctx context.Context, id string) error {
+	members, err := r.cache.SMembers(ctx, signedTokensKey+id)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+
+	for _, member := range members {
+		tokenID, rawExpiry, _ := strings.Cut(member, ":")
+
+		expiry, err := strconv.ParseInt(rawExpiry, 10, 64)
+		if err != nil {
+			return err
+		}
+
+		left := time.Unix(expiry, 0).Sub(now)
+		if left <= 0 {
+			continue
+		}
+
+		if err := r.cache.Set(ctx, deniedTokenKey+tokenID, "1", left); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// TokenDenied reports whether the signed access token with the ID has been
+// revoked.
+func (r *repository) TokenDenied(ctx context.Context, tokenID string) (bool, error) {
+	op := pkg + "TokenDenied"
+
+	denied, err := r.cache.Get(ctx, deniedTokenKey+tokenID)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return denied != "", nil
+}
+
+func (r *repository) AccessGrant(ctx context.Context, accessToken string) (*models.AccessGrant, error) {
+	op := pkg + "AccessGrant"
+
+	grantJSON, err := r.cache.Get(ctx, accessKey+token.Hash(accessToken))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if grantJSON == "" {
+		return nil, models.ErrSessionNotFound
+	}
+
+	var grant models.AccessGrant
+
+	if err := json.Unmarshal([]byte(grantJSON), &grant); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
-	return userJSON, nil
+	return &grant, nil
 }
@@ -5,6 +5,9 @@ import (
 	"encoding/json"
 	"errors"
 	"marketplace/internal/models"
+	"marketplace/internal/utils/token"
+	"strconv"
+	"sync"
 	"testing"
 	"time"
 
@@ -31,123 +34,474 @@ func (m *mockCache) Del(ctx context.Context, keys ...string) error {
 	return args.Error(0)
 }
 
-func TestSaveSession_Success(t *testing.T) {
+func (m *mockCache) SAdd(ctx context.Context, key string, members ...string) error {
+	args := m.Called(ctx, key, members)
+	return args.Error(0)
+}
+
+func (m *mockCache) SRem(ctx context.Context, key string, members ...string) error {
+	args := m.Called(ctx, key, members)
+	return args.Error(0)
+}
+
+func (m *mockCache) SMembers(ctx context.Context, key string) ([]string, error) {
+	args := m.Called(ctx, key)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *mockCache) Expire(ctx context.Context, key string, expiration time.Duration) error {
+	args := m.Called(ctx, key, expiration)
+	return args.Error(0)
+}
+
+func (m *mockCache) GetDel(ctx context.Context, key string) (string, error) {
+	args := m.Called(ctx, key)
+	return args.Get(0).(string), args.Error(1)
+}
+
+func (m *mockCache) Incr(ctx context.Context, key string) (int64, error) {
+	args := m.Called(ctx, key)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// memoryCache is a SessionCache over maps for tests that follow a session
+// through several calls. Expiration is ignored.
+type memoryCache struct {
+	mu     sync.Mutex
+	values map[string]string
+	sets   map[string]map[string]struct{}
+}
+
+func newMemoryCache() *memoryCache {
+	return &memoryCache{values: make(map[string]string), sets: make(map[string]map[string]struct{})}
+}
+
+func (m *memoryCache) Get(ctx context.Context, key string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.values[key], nil
+}
+
+func (m *memoryCache) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.values[key] = value.(string)
+
+	return nil
+}
+
+func (m *memoryCache) Del(ctx context.Context, keys ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, key := range keys {
+		delete(m.values, key)
+		delete(m.sets, key)
+	}
+
+	return nil
+}
+
+func (m *memoryCache) GetDel(ctx context.Context, key string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	value := m.values[key]
+	delete(m.values, key)
+
+	return value, nil
+}
+
+func (m *memoryCache) SAdd(ctx context.Context, key string, members ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.sets[key] == nil {
+		m.sets[key] = make(map[string]struct{})
+	}
+	for _, member := range members {
+		m.sets[key][member] = struct{}{}
+	}
+
+	return nil
+}
+
+func (m *memoryCache) SRem(ctx context.Context, key string, members ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, member := range members {
+		delete(m.sets[key], member)
+	}
+
+	return nil
+}
+
+func (m *memoryCache) SMembers(ctx context.Context, key string) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	members := make([]string, 0, len(m.sets[key]))
+	for member := range m.sets[key] {
+		members = append(members, member)
+	}
+
+	return members, nil
+}
+
+func (m *memoryCache) Incr(ctx context.Context, key string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	value, _ := strconv.ParseInt(m.values[key], 10, 64)
+	value++
+	m.values[key] = strconv.FormatInt(value, 10)
+
+	return value, nil
+}
+
+func (m *memoryCache) Expire(ctx context.Context, key string, expiration time.Duration) error {
+	return nil
+}
+
+func saveTokens(t *testing.T, repo *repository, userID string, sessionID string, suffix string) *models.TokenPair {
+	version, err := repo.SessionVersion(context.Background(), userID)
+	assert.NoError(t, err)
+
+	tokens := &models.TokenPair{
+		AccessToken:  "access-" + suffix,
+		RefreshToken: "refresh-" + suffix,
+	}
+
+	session := &models.Session{
+		ID:         sessionID,
+		UserID:     userID,
+		UserAgent:  "agent-" + suffix,
+		IP:         "127.0.0.1",
+		CreatedAt:  time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		LastSeenAt: time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC),
+	}
+
+	grant := &models.AccessGrant{UserID: userID, SessionID: sessionID, Version: version, IssuedAt: session.LastSeenAt}
+
+	err = repo.SaveSession(context.Background(), session, tokens, grant)
+	assert.NoError(t, err)
+
+	return tokens
+}
+
+func TestAccessGrant_Success(t *testing.T) {
 	t.Parallel()
 
 	mockCache := new(mockCache)
 
-	mockCache.On("Set", mock.Anything, "token123", "user-data", time.Minute).
-		Return(nil)
+	grant := &models.AccessGrant{
+		UserID:    "test",
+		SessionID: "s1",
+		Version:   2,
+		IssuedAt:  time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	grantJSON, _ := json.Marshal(grant)
 
-	repo := New(mockCache, time.Minute)
+	mockCache.On("Get", mock.Anything, "access:"+token.Hash("token123")).
+		Return(string(grantJSON), nil)
 
-	err := repo.SaveSession(context.Background(), "token123", "user-data")
+	repo := New(mockCache, time.Minute, time.Hour)
+
+	actualGrant, err := repo.AccessGrant(context.Background(), "token123")
 	assert.NoError(t, err)
+	assert.Equal(t, grant, actualGrant)
 	mockCache.AssertExpectations(t)
 }
 
-func TestSaveSession_Fail(t *testing.T) {
+func TestAccessGrant_Failed(t *testing.T) {
 	t.Parallel()
 
 	mockCache := new(mockCache)
-
 	someErr := errors.New("some error")
 
-	mockCache.On("Set", mock.Anything, "token123", "user-data", time.Minute).
-		Return(someErr)
+	mockCache.On("Get", mock.Anything, "access:"+token.Hash("token123")).
+		Return("", someErr)
 
-	repo := New(mockCache, time.Minute)
+	repo := New(mockCache, time.Minute, time.Hour)
 
-	err := repo.SaveSession(context.Background(), "token123", "user-data")
+	actualGrant, err := repo.AccessGrant(context.Background(), "token123")
 	assert.ErrorIs(t, err, someErr)
+	assert.Nil(t, actualGrant)
 	mockCache.AssertExpectations(t)
 }
 
-func TestDeleteSession_Success(t *testing.T) {
+func TestAccessGrant_SessionNotFound(t *testing.T) {
 	t.Parallel()
 
 	mockCache := new(mockCache)
 
-	mockCache.On("Del", mock.Anything, []string{"token123"}).
-		Return(nil)
+	mockCache.On("Get", mock.Anything, "access:"+token.Hash("token123")).
+		Return("", nil)
 
-	repo := New(mockCache, time.Minute)
+	repo := New(mockCache, time.Minute, time.Hour)
 
-	err := repo.DeleteSession(context.Background(), "token123")
-	assert.NoError(t, err)
+	actualGrant, err := repo.AccessGrant(context.Background(), "token123")
+	assert.ErrorIs(t, err, models.ErrSessionNotFound)
+	assert.Nil(t, actualGrant)
 	mockCache.AssertExpectations(t)
 }
 
-func TestDeleteSession_Failed(t *testing.T) {
+func TestSaveSession_Fail(t *testing.T) {
 	t.Parallel()
 
 	mockCache := new(mockCache)
 
 	someErr := errors.New("some error")
 
-	mockCache.On("Del", mock.Anything, []string{"token123"}).
+	mockCache.On("Set", mock.Anything, "refresh:"+token.Hash("refresh"), mock.Anything, time.Hour).
 		Return(someErr)
 
-	repo := New(mockCache, time.Minute)
+	repo := New(mockCache, time.Minute, time.Hour)
 
-	err := repo.DeleteSession(context.Background(), "token123")
+	err := repo.SaveSession(context.Background(), &models.Session{ID: "s1", UserID: "1"},
+		&models.TokenPair{AccessToken: "access", RefreshToken: "refresh"}, &models.AccessGrant{UserID: "1", SessionID: "s1"})
 	assert.ErrorIs(t, err, someErr)
-	mockCache.AssertExpectations(t)
 }
 
-func TestUserByToken_Success(t *testing.T) {
+func TestSaveSession_StoresOnlyHashes(t *testing.T) {
 	t.Parallel()
 
-	mockCache := new(mockCache)
+	cache := newMemoryCache()
+	repo := New(cache, time.Minute, time.Hour)
+
+	saveTokens(t, repo, "1", "s1", "a")
 
-	user := &models.User{
-		ID:       "test",
-		Login:    "user1",
-		PassHash: []byte("hashed"),
+	for key, value := range cache.values {
+		assert.NotContains(t, key, "access-a")
+		assert.NotContains(t, key, "refresh-a")
+		assert.NotContains(t, value, "access-a")
+		assert.NotContains(t, value, "refresh-a")
+	}
+	for key, members := range cache.sets {
+		assert.NotContains(t, key, "access-a")
+		for member := range members {
+			assert.NotContains(t, member, "access-a")
+			assert.NotContains(t, member, "refresh-a")
+		}
 	}
 
-	userJSON, _ := json.Marshal(user)
+	grant, err := repo.AccessGrant(context.Background(), "access-a")
+	assert.NoError(t, err)
+	assert.Equal(t, "1", grant.UserID)
+	assert.Equal(t, "s1", grant.SessionID)
+}
 
-	mockCache.On("Get", mock.Anything, "token123").
-		Return(string(userJSON), nil)
+func TestConsumeRefreshToken_Rotation(t *testing.T) {
+	t.Parallel()
+
+	repo := New(newMemoryCache(), time.Minute, time.Hour)
 
-	repo := New(mockCache, time.Minute)
+	saveTokens(t, repo, "1", "s1", "a")
 
-	actualUser, err := repo.UserByToken(context.Background(), "token123")
+	refresh, err := repo.ConsumeRefreshToken(context.Background(), "refresh-a")
 	assert.NoError(t, err)
-	assert.Equal(t, string(userJSON), actualUser)
-	mockCache.AssertExpectations(t)
+	assert.Equal(t, &models.RefreshToken{UserID: "1", SessionID: "s1"}, refresh)
+
+	reused, err := repo.ConsumeRefreshToken(context.Background(), "refresh-a")
+	assert.NoError(t, err)
+	assert.Equal(t, &models.RefreshToken{UserID: "1", SessionID: "s1", Used: true}, reused)
+
+	_, err = repo.ConsumeRefreshToken(context.Background(), "unknown")
+	assert.ErrorIs(t, err, models.ErrSessionNotFound)
+}
+
+func TestSessionsByUser(t *testing.T) {
+	t.Parallel()
+
+	cache := newMemoryCache()
+	repo := New(cache, time.Minute, time.Hour)
+
+	saveTokens(t, repo, "1", "s1", "a")
+	saveTokens(t, repo, "1", "s2", "b")
+	saveTokens(t, repo, "2", "s3", "c")
+
+	// s2 expired in the cache but is still in the user's set.
+	delete(cache.values, "session:s2")
+
+	sessions, err := repo.SessionsByUser(context.Background(), "1")
+	assert.NoError(t, err)
+	assert.Len(t, sessions, 1)
+	assert.Equal(t, "s1", sessions[0].ID)
+	assert.Equal(t, "agent-a", sessions[0].UserAgent)
+	assert.Equal(t, time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC), sessions[0].LastSeenAt)
+
+	assert.NotContains(t, cache.sets["user_sessions:1"], "s2")
 }
 
-func TestUserByToken_Failed(t *testing.T) {
+func TestDeleteSessionByID(t *testing.T) {
+	t.Parallel()
+
+	repo := New(newMemoryCache(), time.Minute, time.Hour)
+
+	saveTokens(t, repo, "1", "s1", "a")
+	saveTokens(t, repo, "1", "s1", "b")
+	saveTokens(t, repo, "1", "s2", "c")
+
+	err := repo.DeleteSessionByID(context.Background(), "1", "s1")
+	assert.NoError(t, err)
+
+	for _, accessToken := range []string{"access-a", "access-b"} {
+		_, err := repo.AccessGrant(context.Background(), accessToken)
+		assert.ErrorIs(t, err, models.ErrSessionNotFound)
+	}
+
+	_, err = repo.ConsumeRefreshToken(context.Background(), "refresh-b")
+	assert.ErrorIs(t, err, models.ErrSessionNotFound)
+
+	_, err = repo.SessionByID(context.Background(), "s1")
+	assert.ErrorIs(t, err, models.ErrSessionNotFound)
+
+	_, err = repo.AccessGrant(context.Background(), "access-c")
+	assert.NoError(t, err)
+}
+
+func TestDeleteSessionByID_DeniesSignedTokens(t *testing.T) {
+	t.Parallel()
+
+	repo := New(newMemoryCache(), time.Minute, time.Hour)
+
+	session := &models.Session{ID: "s1", UserID: "1"}
+	now := time.Now()
+
+	grants := map[string]*models.AccessGrant{
+		"live":    {UserID: "1", SessionID: "s1", TokenID: "live", ExpiresAt: now.Add(time.Minute)},
+		"expired": {UserID: "1", SessionID: "s1", TokenID: "expired", ExpiresAt: now.Add(-time.Minute)},
+	}
+	for id, grant := range grants {
+		err := repo.SaveSession(context.Background(), session, &models.TokenPair{AccessToken: "access-" + id, RefreshToken: "refresh-" + id}, grant)
+		assert.NoError(t, err)
+	}
+
+	// Signed tokens carry their grant and are never looked up.
+	_, err := repo.AccessGrant(context.Background(), "access-live")
+	assert.ErrorIs(t, err, models.ErrSessionNotFound)
+
+	denied, err := repo.TokenDenied(context.Background(), "live")
+	assert.NoError(t, err)
+	assert.False(t, denied)
+
+	err = repo.DeleteSessionByID(context.Background(), "1", "s1")
+	assert.NoError(t, err)
+
+	denied, err = repo.TokenDenied(context.Background(), "live")
+	assert.NoError(t, err)
+	assert.True(t, denied)
+
+	denied, err = repo.TokenDenied(context.Background(), "expired")
+	assert.NoError(t, err)
+	assert.False(t, denied)
+}
+
+func TestDeleteSessionByID_Failed(t *testing.T) {
 	t.Parallel()
 
 	mockCache := new(mockCache)
+
 	someErr := errors.New("some error")
 
-	mockCache.On("Get", mock.Anything, "token123").
-		Return("", someErr)
+	mockCache.On("SMembers", mock.Anything, "session_keys:s1").
+		Return([]string(nil), someErr)
 
-	repo := New(mockCache, time.Minute)
+	repo := New(mockCache, time.Minute, time.Hour)
 
-	actualUser, err := repo.UserByToken(context.Background(), "token123")
+	err := repo.DeleteSessionByID(context.Background(), "1", "s1")
 	assert.ErrorIs(t, err, someErr)
-	assert.Empty(t, actualUser)
-	mockCache.AssertExpectations(t)
 }
 
-func TestUserByToken_SessionNotFound(t *testing.T) {
+func TestDeleteUserSessions_KeepsCurrent(t *testing.T) {
 	t.Parallel()
 
-	mockCache := new(mockCache)
+	repo := New(newMemoryCache(), time.Minute, time.Hour)
 
-	mockCache.On("Get", mock.Anything, "token123").
-		Return("", nil)
+	saveTokens(t, repo, "1", "current", "a")
+	saveTokens(t, repo, "1", "other", "b")
+
+	err := repo.DeleteUserSessions(context.Background(), "1", "current")
+	assert.NoError(t, err)
+
+	_, err = repo.AccessGrant(context.Background(), "access-a")
+	assert.NoError(t, err)
 
-	repo := New(mockCache, time.Minute)
+	_, err = repo.ConsumeRefreshToken(context.Background(), "refresh-a")
+	assert.NoError(t, err)
 
-	actualUser, err := repo.UserByToken(context.Background(), "token123")
+	_, err = repo.AccessGrant(context.Background(), "access-b")
 	assert.ErrorIs(t, err, models.ErrSessionNotFound)
-	assert.Empty(t, actualUser)
-	mockCache.AssertExpectations(t)
+}
+
+func TestDeleteUserSessions_All(t *testing.T) {
+	t.Parallel()
+
+	repo := New(newMemoryCache(), time.Minute, time.Hour)
+
+	saveTokens(t, repo, "1", "s1", "a")
+	saveTokens(t, repo, "1", "s2", "b")
+
+	err := repo.DeleteUserSessions(context.Background(), "1", "")
+	assert.NoError(t, err)
+
+	for _, accessToken := range []string{"access-a", "access-b"} {
+		_, err := repo.AccessGrant(context.Background(), accessToken)
+		assert.ErrorIs(t, err, models.ErrSessionNotFound)
+	}
+}
+
+func TestInvalidateUserSessions(t *testing.T) {
+	t.Parallel()
+
+	repo := New(newMemoryCache(), time.Minute, time.Hour)
+
+	saveTokens(t, repo, "1", "s1", "a")
+	saveTokens(t, repo, "2", "s2", "b")
+
+	err := repo.InvalidateUserSessions(context.Background(), "1")
+	assert.NoError(t, err)
+
+	version, err := repo.SessionVersion(context.Background(), "1")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), version)
+
+	_, err = repo.AccessGrant(context.Background(), "access-a")
+	assert.ErrorIs(t, err, models.ErrSessionNotFound)
+
+	sessions, err := repo.SessionsByUser(context.Background(), "1")
+	assert.NoError(t, err)
+	assert.Empty(t, sessions)
+
+	saveTokens(t, repo, "1", "s3", "c")
+
+	grant, err := repo.AccessGrant(context.Background(), "access-c")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), grant.Version)
+
+	version, err = repo.SessionVersion(context.Background(), "2")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), version)
+}
+
+func TestInvalidateUserSessions_DeniesSignedTokens(t *testing.T) {
+	t.Parallel()
+
+	repo := New(newMemoryCache(), time.Minute, time.Hour)
+
+	grant := &models.AccessGrant{UserID: "1", SessionID: "s1", TokenID: "live", ExpiresAt: time.Now().Add(time.Minute)}
+
+	err := repo.SaveSession(context.Background(), &models.Session{ID: "s1", UserID: "1"}, &models.TokenPair{AccessToken: "access-a", RefreshToken: "refresh-a"}, grant)
+	assert.NoError(t, err)
+
+	err = repo.InvalidateUserSessions(context.Background(), "1")
+	assert.NoError(t, err)
+
+	denied, err := repo.TokenDenied(context.Background(), "live")
+	assert.NoError(t, err)
+	assert.True(t, denied)
 }
@@ -0,0 +1,55 @@
+package cacheverifyrepo
+
+import (
+	"context"
+	"fmt"
+	"marketplace/internal/models"
+	cacherepo "marketplace/internal/repositories/cache"
+	"time"
+)
+
+const (
+	pkg            = "cacheVerifyRepo/"
+	verifyTokenKey = "email_verify:"
+)
+
+type repository struct {
+	cache    cacherepo.OneTimeCache
+	tokenTTL time.Duration
+}
+
+func New(cache cacherepo.OneTimeCache, tokenTTL time.Duration) *repository {
+	return &repository{
+		cache:    cache,
+		tokenTTL: tokenTTL,
+	}
+}
+
+// SaveVerifyToken stores the hash of an email verification token for the
+// user. The token itself is never stored.
+func (r *repository) SaveVerifyToken(ctx context.Context, tokenHash string, userID string) error {
+	op := pkg + "SaveVerifyToken"
+
+	err := r.cache.Set(ctx, verifyTokenKey+tokenHash, userID, r.tokenTTL)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// ConsumeVerifyToken returns the user the token was issued for and removes it.
+func (r *repository) ConsumeVerifyToken(ctx context.Context, tokenHash string) (string, error) {
+	op := pkg + "ConsumeVerifyToken"
+
+	userID, err := r.cache.GetDel(ctx, verifyTokenKey+tokenHash)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	if userID == "" {
+		return "", models.ErrVerifyTokenNotFound
+	}
+
+	return userID, nil
+}
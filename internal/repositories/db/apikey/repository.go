@@ -0,0 +1,102 @@
+package apikeyrepo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"marketplace/internal/entities"
+	"marketplace/internal/models"
+	"marketplace/internal/utils/mapper"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+const pkg = "apiKeyRepo/"
+
+const selectAPIKey = `SELECT id, user_id, name, prefix, scopes, expires_at, last_used_at, created_at FROM api_keys`
+
+type repository struct {
+	db *sqlx.DB
+}
+
+func New(db *sqlx.DB) *repository {
+	return &repository{
+		db: db,
+	}
+}
+
+func (r *repository) AddAPIKey(ctx context.Context, key *models.APIKey, keyHash string) error {
+	op := pkg + "AddAPIKey"
+
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO api_keys(id, user_id, name, key_hash, prefix, scopes, expires_at, created_at)
+		VALUES($1, $2, $3, $4, $5, $6, $7, $8)`,
+		key.ID, key.UserID, key.Name, keyHash, key.Prefix, pq.Array(key.Scopes), key.ExpiresAt, key.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (r *repository) APIKeysByUser(ctx context.Context, userID string) ([]*models.APIKey, error) {
+	op := pkg + "APIKeysByUser"
+
+	var rawKeys []*entities.APIKey
+
+	err := r.db.SelectContext(ctx, &rawKeys, selectAPIKey+` WHERE user_id = $1 ORDER BY created_at DESC`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return mapper.APIKeysByEntities(rawKeys), nil
+}
+
+func (r *repository) APIKeyByHash(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	op := pkg + "APIKeyByHash"
+
+	var rawKey entities.APIKey
+
+	err := r.db.GetContext(ctx, &rawKey, selectAPIKey+` WHERE key_hash = $1`, keyHash)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, models.ErrAPIKeyNotFound
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return mapper.APIKeyByEntity(&rawKey), nil
+}
+
+func (r *repository) DeleteAPIKey(ctx context.Context, userID string, id string) error {
+	op := pkg + "DeleteAPIKey"
+
+	res, err := r.db.ExecContext(ctx, `DELETE FROM api_keys WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if affected == 0 {
+		return models.ErrAPIKeyNotFound
+	}
+
+	return nil
+}
+
+func (r *repository) TouchAPIKey(ctx context.Context, id string, usedAt time.Time) error {
+	op := pkg + "TouchAPIKey"
+
+	_, err := r.db.ExecContext(ctx, `UPDATE api_keys SET last_used_at = $2 WHERE id = $1`, id, usedAt)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,79 @@
+package apikeyrepo
+
+import (
+	"context"
+	"database/sql"
+	"marketplace/internal/models"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func newRepo(t *testing.T) (*repository, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	return New(sqlx.NewDb(db, "sqlmock")), mock
+}
+
+func TestAddAPIKey(t *testing.T) {
+	t.Parallel()
+
+	repo, mock := newRepo(t)
+
+	now := time.Now()
+	key := &models.APIKey{ID: "k1", UserID: "u1", Name: "inventory", Prefix: "mpk_abcdefgh", Scopes: []string{models.ScopePostsWrite}, CreatedAt: now}
+
+	mock.ExpectExec("INSERT INTO api_keys").
+		WithArgs("k1", "u1", "inventory", "hash", "mpk_abcdefgh", pq.Array([]string{"posts:write"}), key.ExpiresAt, now).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	assert.NoError(t, repo.AddAPIKey(context.Background(), key, "hash"))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAPIKeyByHash(t *testing.T) {
+	t.Parallel()
+
+	repo, mock := newRepo(t)
+
+	now := time.Now()
+
+	mock.ExpectQuery("SELECT id, user_id, name, prefix, scopes, expires_at, last_used_at, created_at FROM api_keys WHERE key_hash").
+		WithArgs("hash").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "name", "prefix", "scopes", "expires_at", "last_used_at", "created_at"}).
+			AddRow("k1", "u1", "inventory", "mpk_abcdefgh", "{posts:read,posts:write}", nil, now, now))
+
+	key, err := repo.APIKeyByHash(context.Background(), "hash")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"posts:read", "posts:write"}, key.Scopes)
+	assert.Nil(t, key.ExpiresAt)
+	assert.NotNil(t, key.LastUsedAt)
+
+	mock.ExpectQuery("SELECT id, user_id, name, prefix, scopes, expires_at, last_used_at, created_at FROM api_keys WHERE key_hash").
+		WithArgs("unknown").
+		WillReturnError(sql.ErrNoRows)
+
+	_, err = repo.APIKeyByHash(context.Background(), "unknown")
+	assert.ErrorIs(t, err, models.ErrAPIKeyNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteAPIKey_OtherUser(t *testing.T) {
+	t.Parallel()
+
+	repo, mock := newRepo(t)
+
+	mock.ExpectExec("DELETE FROM api_keys WHERE id = \\$1 AND user_id = \\$2").
+		WithArgs("k1", "u2").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err := repo.DeleteAPIKey(context.Background(), "u2", "k1")
+	assert.ErrorIs(t, err, models.ErrAPIKeyNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,131 @@
+package auctionrepo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"marketplace/internal/entities"
+	"marketplace/internal/models"
+	"marketplace/internal/utils/mapper"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const pkg = "auctionRepo/"
+
+type repository struct {
+	db *sqlx.DB
+}
+
+func New(db *sqlx.DB) *repository {
+	return &repository{
+		db: db,
+	}
+}
+
+// PlaceBid records bid under a row lock on the auction, so that concurrent
+// bids are checked against the latest state one after another. The end time
+// is moved back when the bid comes within window of it.
+func (r *repository) PlaceBid(ctx context.Context, bid *models.Bid, window time.Duration, extension time.Duration) (*models.Auction, error) {
+	op := pkg + "PlaceBid"
+
+	tx, err := r.db.BeginTxx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	rawAuction := entities.Auction{}
+
+	err = tx.GetContext(ctx, &rawAuction,
+		`SELECT
+			a.post_id AS post_id,
+			a.start_price AS start_price,
+			a.min_increment AS min_increment,
+			a.ends_at AS ends_at,
+			a.current_bid AS current_bid,
+			a.bid_count AS bid_count,
+			a.leader_id AS leader_id,
+			a.closed_at AS closed_at
+		FROM auctions a
+		WHERE a.post_id = $1
+		FOR UPDATE`, bid.PostID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, models.ErrNotAuction
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	auction := mapper.AuctionByEntity(&rawAuction)
+
+	if !auction.IsOpen(bid.CreatedAt) {
+		return nil, models.ErrAuctionEnded
+	}
+
+	if bid.Amount < auction.MinimumBid() {
+		return nil, models.ErrBidTooLow
+	}
+
+	auction.Extend(bid.CreatedAt, window, extension)
+	auction.CurrentBid = bid.Amount
+	auction.BidCount++
+	auction.LeaderID = bid.BidderID
+	auction.LeaderLogin = bid.BidderLogin
+
+	_, err = tx.ExecContext(ctx,
+		`UPDATE auctions SET current_bid = $1, bid_count = $2, leader_id = $3, ends_at = $4 WHERE post_id = $5`,
+		auction.CurrentBid, auction.BidCount, auction.LeaderID, auction.EndsAt, bid.PostID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO bids(id, post_id, bidder_id, amount, created_at) VALUES($1, $2, $3, $4, $5)`,
+		bid.ID, bid.PostID, bid.BidderID, bid.Amount, bid.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return auction, nil
+}
+
+// CloseAuctions finishes every open auction whose end time has passed, makes
+// the leader the winner and returns the results.
+func (r *repository) CloseAuctions(ctx context.Context, now time.Time) ([]*models.AuctionResult, error) {
+	op := pkg + "CloseAuctions"
+
+	rawResults := make([]*entities.AuctionResult, 0)
+
+	err := r.db.SelectContext(ctx, &rawResults,
+		`WITH closed AS (
+			UPDATE auctions SET closed_at = $1, winner_id = leader_id
+			WHERE closed_at IS NULL AND ends_at <= $1
+			RETURNING post_id, winner_id, current_bid, closed_at
+		)
+		SELECT
+			c.post_id AS post_id,
+			p.owner_id AS seller_id,
+			c.winner_id AS winner_id,
+			w.login AS winner_login,
+			c.current_bid AS amount,
+			c.closed_at AS closed_at
+		FROM closed c
+		INNER JOIN posts p ON p.id = c.post_id
+		LEFT JOIN users w ON w.id = c.winner_id`, now)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return mapper.AuctionResultsByEntities(rawResults), nil
+}
@@ -0,0 +1,176 @@
+package auctionrepo
+
+import (
+	"context"
+	"database/sql"
+	"marketplace/internal/models"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+var auctionColumns = []string{"post_id", "start_price", "min_increment", "ends_at", "current_bid", "bid_count", "leader_id", "closed_at"}
+
+func TestPlaceBid_FirstBid(t *testing.T) {
+	t.Parallel()
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	repo := New(sqlxDB)
+
+	now := time.Now()
+	endsAt := now.Add(time.Hour)
+	bid := &models.Bid{ID: "b1", PostID: "p1", BidderID: "u1", BidderLogin: "bidder", Amount: 100, CreatedAt: now}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT (.+) FROM auctions a WHERE a.post_id = (.+) FOR UPDATE").
+		WithArgs("p1").
+		WillReturnRows(sqlmock.NewRows(auctionColumns).AddRow("p1", 100, 10, endsAt, 0, 0, nil, nil))
+	mock.ExpectExec("UPDATE auctions SET current_bid").
+		WithArgs(int64(100), 1, "u1", endsAt, "p1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO bids").
+		WithArgs("b1", "p1", "u1", int64(100), now).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	auction, err := repo.PlaceBid(context.Background(), bid, 5*time.Minute, 5*time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(100), auction.CurrentBid)
+	assert.Equal(t, 1, auction.BidCount)
+	assert.Equal(t, "bidder", auction.LeaderLogin)
+	assert.Equal(t, endsAt, auction.EndsAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPlaceBid_ExtendsNearEnd(t *testing.T) {
+	t.Parallel()
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	repo := New(sqlxDB)
+
+	now := time.Now()
+	bid := &models.Bid{ID: "b2", PostID: "p1", BidderID: "u2", Amount: 120, CreatedAt: now}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT (.+) FROM auctions a").
+		WithArgs("p1").
+		WillReturnRows(sqlmock.NewRows(auctionColumns).AddRow("p1", 100, 10, now.Add(30*time.Second), 110, 1, "u1", nil))
+	mock.ExpectExec("UPDATE auctions SET current_bid").
+		WithArgs(int64(120), 2, "u2", now.Add(5*time.Minute), "p1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO bids").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	auction, err := repo.PlaceBid(context.Background(), bid, 2*time.Minute, 5*time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, now.Add(5*time.Minute), auction.EndsAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPlaceBid_TooLow(t *testing.T) {
+	t.Parallel()
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	repo := New(sqlxDB)
+
+	now := time.Now()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT (.+) FROM auctions a").
+		WithArgs("p1").
+		WillReturnRows(sqlmock.NewRows(auctionColumns).AddRow("p1", 100, 10, now.Add(time.Hour), 110, 1, "u1", nil))
+	mock.ExpectRollback()
+
+	_, err := repo.PlaceBid(context.Background(), &models.Bid{PostID: "p1", Amount: 115, CreatedAt: now}, time.Minute, time.Minute)
+	assert.ErrorIs(t, err, models.ErrBidTooLow)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPlaceBid_Ended(t *testing.T) {
+	t.Parallel()
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	repo := New(sqlxDB)
+
+	now := time.Now()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT (.+) FROM auctions a").
+		WithArgs("p1").
+		WillReturnRows(sqlmock.NewRows(auctionColumns).AddRow("p1", 100, 10, now.Add(-time.Second), 0, 0, nil, nil))
+	mock.ExpectRollback()
+
+	_, err := repo.PlaceBid(context.Background(), &models.Bid{PostID: "p1", Amount: 500, CreatedAt: now}, time.Minute, time.Minute)
+	assert.ErrorIs(t, err, models.ErrAuctionEnded)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPlaceBid_NotAuction(t *testing.T) {
+	t.Parallel()
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	repo := New(sqlxDB)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT (.+) FROM auctions a").
+		WithArgs("p1").
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectRollback()
+
+	_, err := repo.PlaceBid(context.Background(), &models.Bid{PostID: "p1", CreatedAt: time.Now()}, time.Minute, time.Minute)
+	assert.ErrorIs(t, err, models.ErrNotAuction)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCloseAuctions_Success(t *testing.T) {
+	t.Parallel()
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	repo := New(sqlxDB)
+
+	now := time.Now()
+
+	rows := sqlmock.NewRows([]string{"post_id", "seller_id", "winner_id", "winner_login", "amount", "closed_at"}).
+		AddRow("p1", "s1", "u1", "bidder", 150, now).
+		AddRow("p2", "s2", nil, nil, 0, now)
+
+	mock.ExpectQuery("WITH closed AS").
+		WithArgs(now).
+		WillReturnRows(rows)
+
+	results, err := repo.CloseAuctions(context.Background(), now)
+	assert.NoError(t, err)
+	assert.Equal(t, []*models.AuctionResult{
+		{PostID: "p1", SellerID: "s1", WinnerID: "u1", WinnerLogin: "bidder", Amount: 150, ClosedAt: now},
+		{PostID: "p2", SellerID: "s2", ClosedAt: now},
+	}, results)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,80 @@
+package banrepo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"marketplace/internal/models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const pkg = "banRepo/"
+
+type repository struct {
+	db *sqlx.DB
+}
+
+func New(db *sqlx.DB) *repository {
+	return &repository{
+		db: db,
+	}
+}
+
+// SetBan bans the user, replacing a ban they already have, and writes the
+// audit record in the same transaction.
+func (r *repository) SetBan(ctx context.Context, userID string, ban *models.Ban, record *models.AuditRecord) error {
+	op := pkg + "SetBan"
+
+	return r.withRecord(ctx, op, record,
+		`UPDATE users SET banned_at = $2, banned_until = $3, ban_reason = $4, banned_by = $5 WHERE id = $1`,
+		userID, ban.BannedAt, ban.Until, ban.Reason, ban.BannedBy)
+}
+
+// ClearBan lifts the ban of the user and writes the audit record in the same
+// transaction.
+func (r *repository) ClearBan(ctx context.Context, userID string, record *models.AuditRecord) error {
+	op := pkg + "ClearBan"
+
+	return r.withRecord(ctx, op, record,
+		`UPDATE users SET banned_at = NULL, banned_until = NULL, ban_reason = NULL, banned_by = NULL WHERE id = $1`,
+		userID)
+}
+
+func (r *repository) withRecord(ctx context.Context, op string, record *models.AuditRecord, query string, args ...any) error {
+	tx, err := r.db.BeginTxx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	res, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if affected == 0 {
+		return models.ErrUserNotFound
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO audit_records(id, actor_id, target_id, action, reason, expires_at, created_at) VALUES($1, $2, $3, $4, $5, $6, $7)`,
+		record.ID, record.ActorID, record.TargetID, string(record.Action), record.Reason, record.ExpiresAt, record.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
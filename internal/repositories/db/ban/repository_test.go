@@ -0,0 +1,106 @@
+package banrepo
+
+import (
+	"context"
+	"errors"
+	"marketplace/internal/models"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func newRepo(t *testing.T) (*repository, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	return New(sqlx.NewDb(db, "sqlmock")), mock
+}
+
+func TestSetBan(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	until := now.Add(24 * time.Hour)
+
+	ban := &models.Ban{Reason: "spam", Until: &until, BannedBy: "a1", BannedAt: now}
+	record := &models.AuditRecord{ID: "r1", ActorID: "a1", TargetID: "u1", Action: models.AuditSuspend, Reason: "spam", ExpiresAt: &until, CreatedAt: now}
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		repo, mock := newRepo(t)
+
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE users SET banned_at").
+			WithArgs("u1", now, &until, "spam", "a1").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("INSERT INTO audit_records").
+			WithArgs("r1", "a1", "u1", "suspend", "spam", &until, now).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		assert.NoError(t, repo.SetBan(context.Background(), "u1", ban, record))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("user not found", func(t *testing.T) {
+		t.Parallel()
+
+		repo, mock := newRepo(t)
+
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE users SET banned_at").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectRollback()
+
+		err := repo.SetBan(context.Background(), "u1", ban, record)
+
+		assert.ErrorIs(t, err, models.ErrUserNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("audit fails", func(t *testing.T) {
+		t.Parallel()
+
+		repo, mock := newRepo(t)
+
+		someErr := errors.New("some error")
+
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE users SET banned_at").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("INSERT INTO audit_records").
+			WillReturnError(someErr)
+		mock.ExpectRollback()
+
+		err := repo.SetBan(context.Background(), "u1", ban, record)
+
+		assert.ErrorIs(t, err, someErr)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestClearBan(t *testing.T) {
+	t.Parallel()
+
+	repo, mock := newRepo(t)
+
+	now := time.Now()
+	record := &models.AuditRecord{ID: "r1", ActorID: "a1", TargetID: "u1", Action: models.AuditUnban, Reason: "appeal", CreatedAt: now}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE users SET banned_at = NULL").
+		WithArgs("u1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO audit_records").
+		WithArgs("r1", "a1", "u1", "unban", "appeal", nil, now).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, repo.ClearBan(context.Background(), "u1", record))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
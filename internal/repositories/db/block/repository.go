@@ -0,0 +1,67 @@
+package blockrepo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const pkg = "blockRepo/"
+
+type repository struct {
+	db *sqlx.DB
+}
+
+func New(db *sqlx.DB) *repository {
+	return &repository{
+		db: db,
+	}
+}
+
+// AddBlock blocks a user for the blocker. Blocking the same user twice keeps
+// the first block.
+func (r *repository) AddBlock(ctx context.Context, blockerID string, blockedID string, createdAt time.Time) error {
+	op := pkg + "AddBlock"
+
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO user_blocks(blocker_id, blocked_id, created_at) VALUES($1, $2, $3)
+		ON CONFLICT (blocker_id, blocked_id) DO NOTHING`,
+		blockerID, blockedID, createdAt)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (r *repository) DeleteBlock(ctx context.Context, blockerID string, blockedID string) error {
+	op := pkg + "DeleteBlock"
+
+	_, err := r.db.ExecContext(ctx,
+		`DELETE FROM user_blocks WHERE blocker_id = $1 AND blocked_id = $2`,
+		blockerID, blockedID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// BlockedIDs returns the IDs of the users the blocker has blocked, in a
+// stable order.
+func (r *repository) BlockedIDs(ctx context.Context, blockerID string) ([]string, error) {
+	op := pkg + "BlockedIDs"
+
+	ids := make([]string, 0)
+
+	err := r.db.SelectContext(ctx, &ids,
+		`SELECT blocked_id FROM user_blocks WHERE blocker_id = $1 ORDER BY blocked_id`,
+		blockerID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return ids, nil
+}
@@ -0,0 +1,68 @@
+package blockrepo
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func newRepo(t *testing.T) (*repository, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	return New(sqlx.NewDb(db, "sqlmock")), mock
+}
+
+func TestAddBlock(t *testing.T) {
+	t.Parallel()
+
+	repo, mock := newRepo(t)
+
+	now := time.Now()
+
+	mock.ExpectExec("INSERT INTO user_blocks(.+)ON CONFLICT").
+		WithArgs("u1", "u2", now).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	assert.NoError(t, repo.AddBlock(context.Background(), "u1", "u2", now))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteBlock(t *testing.T) {
+	t.Parallel()
+
+	repo, mock := newRepo(t)
+
+	someErr := errors.New("some error")
+
+	mock.ExpectExec("DELETE FROM user_blocks").
+		WithArgs("u1", "u2").
+		WillReturnError(someErr)
+
+	err := repo.DeleteBlock(context.Background(), "u1", "u2")
+
+	assert.ErrorIs(t, err, someErr)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBlockedIDs(t *testing.T) {
+	t.Parallel()
+
+	repo, mock := newRepo(t)
+
+	mock.ExpectQuery("SELECT blocked_id FROM user_blocks").
+		WithArgs("u1").
+		WillReturnRows(sqlmock.NewRows([]string{"blocked_id"}).AddRow("u2").AddRow("u3"))
+
+	ids, err := repo.BlockedIDs(context.Background(), "u1")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"u2", "u3"}, ids)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,224 @@
+package conversationrepo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"marketplace/internal/entities"
+	"marketplace/internal/models"
+	"marketplace/internal/utils/mapper"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+const pkg = "conversationRepo/"
+
+const conversationColumns = `
+			c.id AS id,
+			c.post_id AS post_id,
+			p.header AS post_header,
+			c.buyer_id AS buyer_id,
+			b.login AS buyer_login,
+			c.seller_id AS seller_id,
+			s.login AS seller_login,
+			c.created_at AS created_at,
+			c.last_message_at AS last_message_at`
+
+type repository struct {
+	db *sqlx.DB
+}
+
+func New(db *sqlx.DB) *repository {
+	return &repository{
+		db: db,
+	}
+}
+
+func (r *repository) AddConversation(ctx context.Context, conversation *models.Conversation) error {
+	op := pkg + "AddConversation"
+
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO conversations(id, post_id, buyer_id, seller_id, created_at, last_message_at) VALUES($1, $2, $3, $4, $5, $6)`,
+		conversation.ID, conversation.PostID, conversation.BuyerID, conversation.SellerID, conversation.CreatedAt, conversation.LastMessageAt)
+	if err != nil {
+		if pgErr, ok := err.(*pq.Error); ok {
+			if pgErr.Code == "23505" {
+				return &models.UniqueConstraintError{
+					Constraint: pgErr.Constraint,
+					Err:        models.ErrUNIQUEConstraintFailed,
+				}
+			}
+		}
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (r *repository) ConversationByID(ctx context.Context, id string) (*models.Conversation, error) {
+	op := pkg + "ConversationByID"
+
+	var rawConversation entities.Conversation
+
+	err := r.db.GetContext(ctx, &rawConversation,
+		`SELECT`+conversationColumns+`
+		FROM conversations c
+		JOIN posts p ON p.id = c.post_id
+		JOIN users b ON b.id = c.buyer_id
+		JOIN users s ON s.id = c.seller_id
+		WHERE c.id = $1`, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, models.ErrConversationNotFound
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return mapper.ConversationByEntity(&rawConversation), nil
+}
+
+func (r *repository) ConversationByPostAndBuyer(ctx context.Context, postID string, buyerID string) (*models.Conversation, error) {
+	op := pkg + "ConversationByPostAndBuyer"
+
+	var rawConversation entities.Conversation
+
+	err := r.db.GetContext(ctx, &rawConversation,
+		`SELECT`+conversationColumns+`
+		FROM conversations c
+		JOIN posts p ON p.id = c.post_id
+		JOIN users b ON b.id = c.buyer_id
+		JOIN users s ON s.id = c.seller_id
+		WHERE c.post_id = $1 AND c.buyer_id = $2`, postID, buyerID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, models.ErrConversationNotFound
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return mapper.ConversationByEntity(&rawConversation), nil
+}
+
+// ConversationsByUser returns the conversations the user takes part in, most
+// recently active first, with the number of messages the user has not read.
+func (r *repository) ConversationsByUser(ctx context.Context, userID string, limit int, offset int) ([]*models.Conversation, error) {
+	op := pkg + "ConversationsByUser"
+
+	rawConversations := make([]*entities.Conversation, 0)
+
+	err := r.db.SelectContext(ctx, &rawConversations,
+		`SELECT`+conversationColumns+`,
+			(SELECT COUNT(*) FROM messages m
+				WHERE m.conversation_id = c.id AND m.sender_id <> $1 AND m.read_at IS NULL) AS unread_count
+		FROM conversations c
+		JOIN posts p ON p.id = c.post_id
+		JOIN users b ON b.id = c.buyer_id
+		JOIN users s ON s.id = c.seller_id
+		WHERE c.buyer_id = $1 OR c.seller_id = $1
+		ORDER BY c.last_message_at DESC, c.id ASC
+		LIMIT $2 OFFSET $3`, userID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return mapper.ConversationsByEntities(rawConversations), nil
+}
+
+func (r *repository) AddMessage(ctx context.Context, message *models.Message) error {
+	op := pkg + "AddMessage"
+
+	tx, err := r.db.BeginTxx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO messages(id, conversation_id, sender_id, text, created_at) VALUES($1, $2, $3, $4, $5)`,
+		message.ID, message.ConversationID, message.SenderID, message.Text, message.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`UPDATE conversations SET last_message_at = $1 WHERE id = $2`,
+		message.CreatedAt, message.ConversationID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// Messages returns up to limit messages of the conversation, newest first,
+// that were sent before the cursor. A nil cursor starts from the newest one.
+func (r *repository) Messages(ctx context.Context, conversationID string, before *models.MessageCursor, limit int) ([]*models.Message, error) {
+	op := pkg + "Messages"
+
+	rawMessages := make([]*entities.Message, 0)
+
+	var (
+		beforeAt any
+		beforeID string
+	)
+
+	if before != nil {
+		beforeAt = before.CreatedAt
+		beforeID = before.ID
+	}
+
+	err := r.db.SelectContext(ctx, &rawMessages,
+		`SELECT
+			m.id AS id,
+			m.conversation_id AS conversation_id,
+			m.sender_id AS sender_id,
+			u.login AS sender_login,
+			m.text AS text,
+			m.created_at AS created_at,
+			m.read_at AS read_at
+		FROM messages m
+		JOIN users u ON u.id = m.sender_id
+		WHERE m.conversation_id = $1
+			AND ($2::TIMESTAMP IS NULL OR (m.created_at, m.id) < ($2::TIMESTAMP, $3::UUID))
+		ORDER BY m.created_at DESC, m.id DESC
+		LIMIT $4`, conversationID, beforeAt, nullableUUID(beforeID), limit)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return mapper.MessagesByEntities(rawMessages), nil
+}
+
+// MarkRead marks the messages the reader has received in the conversation as
+// read.
+func (r *repository) MarkRead(ctx context.Context, conversationID string, readerID string, readAt time.Time) error {
+	op := pkg + "MarkRead"
+
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE messages SET read_at = $1 WHERE conversation_id = $2 AND sender_id <> $3 AND read_at IS NULL`,
+		readAt, conversationID, readerID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func nullableUUID(id string) any {
+	if id == "" {
+		return nil
+	}
+	return id
+}
@@ -0,0 +1,229 @@
+package conversationrepo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"marketplace/internal/models"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddConversation_Success(t *testing.T) {
+	t.Parallel()
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	repo := New(sqlxDB)
+
+	now := time.Now()
+	conversation := &models.Conversation{ID: "c1", PostID: "p1", BuyerID: "b1", SellerID: "s1", CreatedAt: now, LastMessageAt: now}
+
+	mock.ExpectExec("INSERT INTO conversations").
+		WithArgs("c1", "p1", "b1", "s1", now, now).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	err := repo.AddConversation(context.Background(), conversation)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAddConversation_UniqueViolation(t *testing.T) {
+	t.Parallel()
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	repo := New(sqlxDB)
+
+	mock.ExpectExec("INSERT INTO conversations").
+		WillReturnError(&pq.Error{Code: "23505", Constraint: "conversations_post_id_buyer_id_key"})
+
+	err := repo.AddConversation(context.Background(), &models.Conversation{ID: "c1"})
+
+	var uce *models.UniqueConstraintError
+	assert.True(t, errors.As(err, &uce))
+	assert.Equal(t, "conversations_post_id_buyer_id_key", uce.Constraint)
+}
+
+func TestConversationByID_NotFound(t *testing.T) {
+	t.Parallel()
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	repo := New(sqlxDB)
+
+	mock.ExpectQuery("SELECT (.+) FROM conversations c").
+		WithArgs("c1").
+		WillReturnError(sql.ErrNoRows)
+
+	_, err := repo.ConversationByID(context.Background(), "c1")
+	assert.ErrorIs(t, err, models.ErrConversationNotFound)
+}
+
+func TestConversationsByUser_Success(t *testing.T) {
+	t.Parallel()
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	repo := New(sqlxDB)
+
+	now := time.Now()
+
+	rows := sqlmock.NewRows([]string{"id", "post_id", "post_header", "buyer_id", "buyer_login", "seller_id", "seller_login", "created_at", "last_message_at", "unread_count"}).
+		AddRow("c1", "p1", "bike", "b1", "buyer", "s1", "seller", now, now, 2)
+
+	mock.ExpectQuery("SELECT (.+) FROM conversations c").
+		WithArgs("b1", 10, 0).
+		WillReturnRows(rows)
+
+	conversations, err := repo.ConversationsByUser(context.Background(), "b1", 10, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []*models.Conversation{{
+		ID:            "c1",
+		PostID:        "p1",
+		PostHeader:    "bike",
+		BuyerID:       "b1",
+		BuyerLogin:    "buyer",
+		SellerID:      "s1",
+		SellerLogin:   "seller",
+		CreatedAt:     now,
+		LastMessageAt: now,
+		UnreadCount:   2,
+	}}, conversations)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAddMessage_Success(t *testing.T) {
+	t.Parallel()
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	repo := New(sqlxDB)
+
+	now := time.Now()
+	message := &models.Message{ID: "m1", ConversationID: "c1", SenderID: "b1", Text: "hello", CreatedAt: now}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO messages").
+		WithArgs("m1", "c1", "b1", "hello", now).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("UPDATE conversations SET last_message_at").
+		WithArgs(now, "c1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err := repo.AddMessage(context.Background(), message)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAddMessage_InsertFails(t *testing.T) {
+	t.Parallel()
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	repo := New(sqlxDB)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO messages").
+		WillReturnError(errors.New("db error"))
+	mock.ExpectRollback()
+
+	err := repo.AddMessage(context.Background(), &models.Message{ID: "m1"})
+	assert.Error(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMessages_WithCursor(t *testing.T) {
+	t.Parallel()
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	repo := New(sqlxDB)
+
+	now := time.Now()
+	before := &models.MessageCursor{CreatedAt: now, ID: "m9"}
+
+	rows := sqlmock.NewRows([]string{"id", "conversation_id", "sender_id", "sender_login", "text", "created_at", "read_at"}).
+		AddRow("m1", "c1", "b1", "buyer", "hello", now.Add(-time.Minute), now)
+
+	mock.ExpectQuery("SELECT (.+) FROM messages m").
+		WithArgs("c1", now, "m9", 20).
+		WillReturnRows(rows)
+
+	messages, err := repo.Messages(context.Background(), "c1", before, 20)
+	assert.NoError(t, err)
+	assert.Len(t, messages, 1)
+	assert.Equal(t, "buyer", messages[0].SenderLogin)
+	assert.NotNil(t, messages[0].ReadAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMessages_FirstPage(t *testing.T) {
+	t.Parallel()
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	repo := New(sqlxDB)
+
+	rows := sqlmock.NewRows([]string{"id", "conversation_id", "sender_id", "sender_login", "text", "created_at", "read_at"})
+
+	mock.ExpectQuery("SELECT (.+) FROM messages m").
+		WithArgs("c1", nil, nil, 20).
+		WillReturnRows(rows)
+
+	messages, err := repo.Messages(context.Background(), "c1", nil, 20)
+	assert.NoError(t, err)
+	assert.Empty(t, messages)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMarkRead_Success(t *testing.T) {
+	t.Parallel()
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	repo := New(sqlxDB)
+
+	now := time.Now()
+
+	mock.ExpectExec("UPDATE messages SET read_at").
+		WithArgs(now, "c1", "s1").
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	err := repo.MarkRead(context.Background(), "c1", "s1", now)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,105 @@
+package identityrepo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"marketplace/internal/entities"
+	"marketplace/internal/models"
+	"marketplace/internal/utils/mapper"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+const pkg = "identityRepo/"
+
+type repository struct {
+	db *sqlx.DB
+}
+
+func New(db *sqlx.DB) *repository {
+	return &repository{
+		db: db,
+	}
+}
+
+func (r *repository) IdentityBySubject(ctx context.Context, provider string, subject string) (*models.ExternalIdentity, error) {
+	op := pkg + "IdentityBySubject"
+
+	var rawIdentity entities.ExternalIdentity
+
+	err := r.db.GetContext(ctx, &rawIdentity,
+		`SELECT provider, subject, user_id, created_at FROM user_identities WHERE provider = $1 AND subject = $2`,
+		provider, subject)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, models.ErrIdentityNotFound
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return mapper.IdentityByEntity(&rawIdentity), nil
+}
+
+// AddIdentity links an identity to an existing user. A user has at most one
+// identity per provider.
+func (r *repository) AddIdentity(ctx context.Context, identity *models.ExternalIdentity) error {
+	op := pkg + "AddIdentity"
+
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO user_identities(provider, subject, user_id, created_at) VALUES($1, $2, $3, $4)`,
+		identity.Provider, identity.Subject, identity.UserID, identity.CreatedAt)
+	if err != nil {
+		return wrapError(op, err)
+	}
+
+	return nil
+}
+
+// AddUserWithIdentity creates a user that signs in only through the provider,
+// together with its identity.
+func (r *repository) AddUserWithIdentity(ctx context.Context, user *models.User, identity *models.ExternalIdentity) error {
+	op := pkg + "AddUserWithIdentity"
+
+	tx, err := r.db.BeginTxx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO users(id, login, pass_hash, email) VALUES($1, $2, $3, NULLIF($4, ''))`,
+		user.ID, user.Login, user.PassHash, user.Email)
+	if err != nil {
+		return wrapError(op, err)
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO user_identities(provider, subject, user_id, created_at) VALUES($1, $2, $3, $4)`,
+		identity.Provider, identity.Subject, user.ID, identity.CreatedAt)
+	if err != nil {
+		return wrapError(op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func wrapError(op string, err error) error {
+	if pgErr, ok := err.(*pq.Error); ok && pgErr.Code == "23505" {
+		return &models.UniqueConstraintError{
+			Constraint: pgErr.Constraint,
+			Err:        models.ErrUNIQUEConstraintFailed,
+		}
+	}
+
+	return fmt.Errorf("%s: %w", op, err)
+}
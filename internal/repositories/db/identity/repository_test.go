@@ -0,0 +1,112 @@
+package identityrepo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"marketplace/internal/models"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func newRepo(t *testing.T) (*repository, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	return New(sqlx.NewDb(db, "sqlmock")), mock
+}
+
+func TestIdentityBySubject(t *testing.T) {
+	t.Parallel()
+
+	repo, mock := newRepo(t)
+
+	now := time.Now()
+
+	mock.ExpectQuery("SELECT provider, subject, user_id, created_at FROM user_identities").
+		WithArgs("google", "sub-1").
+		WillReturnRows(sqlmock.NewRows([]string{"provider", "subject", "user_id", "created_at"}).
+			AddRow("google", "sub-1", "u1", now))
+
+	identity, err := repo.IdentityBySubject(context.Background(), "google", "sub-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "u1", identity.UserID)
+
+	mock.ExpectQuery("SELECT provider, subject, user_id, created_at FROM user_identities").
+		WithArgs("google", "sub-2").
+		WillReturnError(sql.ErrNoRows)
+
+	_, err = repo.IdentityBySubject(context.Background(), "google", "sub-2")
+	assert.ErrorIs(t, err, models.ErrIdentityNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAddIdentity_AlreadyLinked(t *testing.T) {
+	t.Parallel()
+
+	repo, mock := newRepo(t)
+
+	now := time.Now()
+
+	mock.ExpectExec("INSERT INTO user_identities").
+		WithArgs("google", "sub-1", "u1", now).
+		WillReturnError(&pq.Error{Code: "23505", Constraint: "user_identities_pkey"})
+
+	err := repo.AddIdentity(context.Background(), &models.ExternalIdentity{Provider: "google", Subject: "sub-1", UserID: "u1", CreatedAt: now})
+
+	var uce *models.UniqueConstraintError
+	assert.True(t, errors.As(err, &uce))
+	assert.Equal(t, "user_identities_pkey", uce.Constraint)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAddUserWithIdentity(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	user := &models.User{ID: "u1", Login: "jane", PassHash: []byte{}}
+	identity := &models.ExternalIdentity{Provider: "google", Subject: "sub-1", CreatedAt: now}
+
+	t.Run("created", func(t *testing.T) {
+		t.Parallel()
+
+		repo, mock := newRepo(t)
+
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO users").
+			WithArgs("u1", "jane", []byte{}, "").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("INSERT INTO user_identities").
+			WithArgs("google", "sub-1", "u1", now).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		assert.NoError(t, repo.AddUserWithIdentity(context.Background(), user, identity))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("login taken", func(t *testing.T) {
+		t.Parallel()
+
+		repo, mock := newRepo(t)
+
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO users").
+			WithArgs("u1", "jane", []byte{}, "").
+			WillReturnError(&pq.Error{Code: "23505", Constraint: "users_login_key"})
+		mock.ExpectRollback()
+
+		err := repo.AddUserWithIdentity(context.Background(), user, identity)
+
+		var uce *models.UniqueConstraintError
+		assert.True(t, errors.As(err, &uce))
+		assert.Equal(t, "users_login_key", uce.Constraint)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,110 @@
+package notificationrepo
+
+import (
+	"context"
+	"fmt"
+	"marketplace/internal/entities"
+	"marketplace/internal/models"
+	"marketplace/internal/utils/mapper"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const pkg = "notificationRepo/"
+
+type repository struct {
+	db *sqlx.DB
+}
+
+func New(db *sqlx.DB) *repository {
+	return &repository{
+		db: db,
+	}
+}
+
+func (r *repository) AddNotification(ctx context.Context, notification *models.Notification) error {
+	op := pkg + "AddNotification"
+
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO notifications(id, user_id, kind, payload, created_at) VALUES($1, $2, $3, $4, $5)`,
+		notification.ID, notification.UserID, notification.Kind, []byte(notification.Payload), notification.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (r *repository) NotificationsByUser(ctx context.Context, userID string, limit int, offset int, unreadOnly bool) ([]*models.Notification, error) {
+	op := pkg + "NotificationsByUser"
+
+	rawNotifications := make([]*entities.Notification, 0)
+
+	err := r.db.SelectContext(ctx, &rawNotifications,
+		`SELECT
+			n.id AS id,
+			n.user_id AS user_id,
+			n.kind AS kind,
+			n.payload AS payload,
+			n.created_at AS created_at,
+			n.read_at AS read_at
+		FROM notifications n
+		WHERE n.user_id = $1 AND ($2 = FALSE OR n.read_at IS NULL)
+		ORDER BY n.created_at DESC, n.id ASC
+		LIMIT $3 OFFSET $4`, userID, unreadOnly, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return mapper.NotificationsByEntities(rawNotifications), nil
+}
+
+func (r *repository) UnreadCount(ctx context.Context, userID string) (int, error) {
+	op := pkg + "UnreadCount"
+
+	var count int
+
+	err := r.db.GetContext(ctx, &count,
+		`SELECT COUNT(*) FROM notifications WHERE user_id = $1 AND read_at IS NULL`, userID)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return count, nil
+}
+
+func (r *repository) MarkRead(ctx context.Context, userID string, id string, readAt time.Time) error {
+	op := pkg + "MarkRead"
+
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE notifications SET read_at = COALESCE(read_at, $1) WHERE id = $2 AND user_id = $3`,
+		readAt, id, userID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if affected == 0 {
+		return models.ErrNotificationNotFound
+	}
+
+	return nil
+}
+
+func (r *repository) MarkAllRead(ctx context.Context, userID string, readAt time.Time) error {
+	op := pkg + "MarkAllRead"
+
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE notifications SET read_at = $1 WHERE user_id = $2 AND read_at IS NULL`,
+		readAt, userID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
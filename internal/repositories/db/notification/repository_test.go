@@ -0,0 +1,131 @@
+package notificationrepo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"marketplace/internal/models"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddNotification_Success(t *testing.T) {
+	t.Parallel()
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	repo := New(sqlxDB)
+
+	notification := &models.Notification{
+		ID:        "n1",
+		UserID:    "1",
+		Kind:      models.NotificationSearchMatch,
+		Payload:   json.RawMessage(`{"search_id":"s1","post_id":"p1"}`),
+		CreatedAt: time.Now(),
+	}
+
+	mock.ExpectExec("INSERT INTO notifications").
+		WithArgs(notification.ID, notification.UserID, notification.Kind, []byte(notification.Payload), notification.CreatedAt).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	err := repo.AddNotification(context.Background(), notification)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestNotificationsByUser_Success(t *testing.T) {
+	t.Parallel()
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	repo := New(sqlxDB)
+
+	now := time.Now()
+
+	rows := sqlmock.NewRows([]string{"id", "user_id", "kind", "payload", "created_at", "read_at"}).
+		AddRow("n1", "1", "search_match", []byte(`{}`), now, nil).
+		AddRow("n2", "1", "moderation", []byte(`{}`), now, now)
+
+	mock.ExpectQuery("SELECT (.+) FROM notifications n").
+		WithArgs("1", false, 10, 0).
+		WillReturnRows(rows)
+
+	notifications, err := repo.NotificationsByUser(context.Background(), "1", 10, 0, false)
+	assert.NoError(t, err)
+	assert.Len(t, notifications, 2)
+	assert.Nil(t, notifications[0].ReadAt)
+	assert.Equal(t, now, *notifications[1].ReadAt)
+	assert.Equal(t, models.NotificationModeration, notifications[1].Kind)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUnreadCount_Success(t *testing.T) {
+	t.Parallel()
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	repo := New(sqlxDB)
+
+	mock.ExpectQuery("SELECT COUNT").
+		WithArgs("1").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(5))
+
+	count, err := repo.UnreadCount(context.Background(), "1")
+	assert.NoError(t, err)
+	assert.Equal(t, 5, count)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMarkRead_NotFound(t *testing.T) {
+	t.Parallel()
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	repo := New(sqlxDB)
+
+	now := time.Now()
+
+	mock.ExpectExec("UPDATE notifications SET read_at").
+		WithArgs(now, "n1", "1").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err := repo.MarkRead(context.Background(), "1", "n1", now)
+	assert.ErrorIs(t, err, models.ErrNotificationNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMarkAllRead_Fails(t *testing.T) {
+	t.Parallel()
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	repo := New(sqlxDB)
+
+	someErr := errors.New("some error")
+
+	mock.ExpectExec("UPDATE notifications SET read_at").
+		WillReturnError(someErr)
+
+	err := repo.MarkAllRead(context.Background(), "1", time.Now())
+	assert.ErrorIs(t, err, someErr)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,240 @@
+package offerrepo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"marketplace/internal/entities"
+	"marketplace/internal/models"
+	"marketplace/internal/utils/mapper"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+const pkg = "offerRepo/"
+
+const offerSelect = `SELECT
+			o.id AS id,
+			o.post_id AS post_id,
+			o.buyer_id AS buyer_id,
+			b.login AS buyer_login,
+			o.seller_id AS seller_id,
+			s.login AS seller_login,
+			o.proposer_id AS proposer_id,
+			o.previous_id AS previous_id,
+			o.amount AS amount,
+			o.status AS status,
+			o.created_at AS created_at,
+			o.expires_at AS expires_at,
+			o.responded_at AS responded_at
+		FROM offers o
+		JOIN users b ON b.id = o.buyer_id
+		JOIN users s ON s.id = o.seller_id`
+
+type repository struct {
+	db *sqlx.DB
+}
+
+func New(db *sqlx.DB) *repository {
+	return &repository{
+		db: db,
+	}
+}
+
+func (r *repository) AddOffer(ctx context.Context, offer *models.Offer) error {
+	op := pkg + "AddOffer"
+
+	err := insertOffer(ctx, r.db, offer)
+	if err != nil {
+		if pgErr, ok := err.(*pq.Error); ok {
+			if pgErr.Code == "23505" {
+				return &models.UniqueConstraintError{
+					Constraint: pgErr.Constraint,
+					Err:        models.ErrUNIQUEConstraintFailed,
+				}
+			}
+		}
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (r *repository) OfferByID(ctx context.Context, id string) (*models.Offer, error) {
+	op := pkg + "OfferByID"
+
+	var rawOffer entities.Offer
+
+	err := r.db.GetContext(ctx, &rawOffer, offerSelect+`
+		WHERE o.id = $1`, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, models.ErrOfferNotFound
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return mapper.OfferByEntity(&rawOffer), nil
+}
+
+// OffersByPost returns the offers made on the post, newest first. When buyerID
+// is set, only the negotiation with that buyer is returned.
+func (r *repository) OffersByPost(ctx context.Context, postID string, buyerID string) ([]*models.Offer, error) {
+	op := pkg + "OffersByPost"
+
+	rawOffers := make([]*entities.Offer, 0)
+
+	err := r.db.SelectContext(ctx, &rawOffers, offerSelect+`
+		WHERE o.post_id = $1 AND ($2 = '' OR o.buyer_id::TEXT = $2)
+		ORDER BY o.created_at DESC, o.id ASC`, postID, buyerID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return mapper.OffersByEntities(rawOffers), nil
+}
+
+// OffersByUser returns the offers the user takes part in as a buyer, as a
+// seller, or on either side when role is empty.
+func (r *repository) OffersByUser(ctx context.Context, userID string, role models.OfferRole, limit int, offset int) ([]*models.Offer, error) {
+	op := pkg + "OffersByUser"
+
+	rawOffers := make([]*entities.Offer, 0)
+
+	err := r.db.SelectContext(ctx, &rawOffers, offerSelect+`
+		WHERE ($2 <> 'seller' AND o.buyer_id = $1) OR ($2 <> 'buyer' AND o.seller_id = $1)
+		ORDER BY o.created_at DESC, o.id ASC
+		LIMIT $3 OFFSET $4`, userID, string(role), limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return mapper.OffersByEntities(rawOffers), nil
+}
+
+// UpdateOfferStatus closes a pending offer that has not expired yet. It
+// returns models.ErrOfferClosed when the offer has already been answered or
+// has expired.
+func (r *repository) UpdateOfferStatus(ctx context.Context, id string, status models.OfferStatus, respondedAt time.Time) error {
+	op := pkg + "UpdateOfferStatus"
+
+	err := closeOffer(ctx, r.db, id, status, respondedAt)
+	if err != nil {
+		if errors.Is(err, models.ErrOfferClosed) {
+			return err
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// CounterOffer closes the answered offer as countered and stores the counter
+// offer in one transaction.
+func (r *repository) CounterOffer(ctx context.Context, previousID string, counter *models.Offer) error {
+	op := pkg + "CounterOffer"
+
+	tx, err := r.db.BeginTxx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	err = closeOffer(ctx, tx, previousID, models.OfferCountered, counter.CreatedAt)
+	if err != nil {
+		if errors.Is(err, models.ErrOfferClosed) {
+			return err
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	err = insertOffer(ctx, tx, counter)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// ExpireOffers marks pending offers whose time is up as expired and returns
+// them.
+func (r *repository) ExpireOffers(ctx context.Context, now time.Time) ([]*models.Offer, error) {
+	op := pkg + "ExpireOffers"
+
+	rawOffers := make([]*entities.Offer, 0)
+
+	err := r.db.SelectContext(ctx, &rawOffers,
+		`WITH expired AS (
+			UPDATE offers SET status = 'expired', responded_at = $1
+			WHERE status = 'pending' AND expires_at <= $1
+			RETURNING *
+		)
+		SELECT
+			o.id AS id,
+			o.post_id AS post_id,
+			o.buyer_id AS buyer_id,
+			b.login AS buyer_login,
+			o.seller_id AS seller_id,
+			s.login AS seller_login,
+			o.proposer_id AS proposer_id,
+			o.previous_id AS previous_id,
+			o.amount AS amount,
+			o.status AS status,
+			o.created_at AS created_at,
+			o.expires_at AS expires_at,
+			o.responded_at AS responded_at
+		FROM expired o
+		JOIN users b ON b.id = o.buyer_id
+		JOIN users s ON s.id = o.seller_id`, now)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return mapper.OffersByEntities(rawOffers), nil
+}
+
+func insertOffer(ctx context.Context, db sqlx.ExecerContext, offer *models.Offer) error {
+	var previousID any
+	if offer.PreviousID != "" {
+		previousID = offer.PreviousID
+	}
+
+	_, err := db.ExecContext(ctx,
+		`INSERT INTO offers(id, post_id, buyer_id, seller_id, proposer_id, previous_id, amount, status, created_at, expires_at)
+		VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`,
+		offer.ID, offer.PostID, offer.BuyerID, offer.SellerID, offer.ProposerID, previousID, offer.Amount, offer.Status, offer.CreatedAt, offer.ExpiresAt)
+
+	return err
+}
+
+func closeOffer(ctx context.Context, db sqlx.ExecerContext, id string, status models.OfferStatus, respondedAt time.Time) error {
+	res, err := db.ExecContext(ctx,
+		`UPDATE offers SET status = $1, responded_at = $2 WHERE id = $3 AND status = 'pending' AND expires_at > $2`,
+		status, respondedAt, id)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return models.ErrOfferClosed
+	}
+
+	return nil
+}
@@ -0,0 +1,228 @@
+package offerrepo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"marketplace/internal/models"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+var offerColumns = []string{"id", "post_id", "buyer_id", "buyer_login", "seller_id", "seller_login", "proposer_id", "previous_id", "amount", "status", "created_at", "expires_at", "responded_at"}
+
+func TestAddOffer_Success(t *testing.T) {
+	t.Parallel()
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	repo := New(sqlxDB)
+
+	now := time.Now()
+	offer := &models.Offer{ID: "o1", PostID: "p1", BuyerID: "b1", SellerID: "s1", ProposerID: "b1", Amount: 900, Status: models.OfferPending, CreatedAt: now, ExpiresAt: now.Add(time.Hour)}
+
+	mock.ExpectExec("INSERT INTO offers").
+		WithArgs("o1", "p1", "b1", "s1", "b1", nil, int64(900), models.OfferPending, now, now.Add(time.Hour)).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	err := repo.AddOffer(context.Background(), offer)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAddOffer_UniqueViolation(t *testing.T) {
+	t.Parallel()
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	repo := New(sqlxDB)
+
+	mock.ExpectExec("INSERT INTO offers").
+		WillReturnError(&pq.Error{Code: "23505", Constraint: "offers_pending_post_id_buyer_id_idx"})
+
+	err := repo.AddOffer(context.Background(), &models.Offer{ID: "o1"})
+
+	var uce *models.UniqueConstraintError
+	assert.True(t, errors.As(err, &uce))
+}
+
+func TestOfferByID_Success(t *testing.T) {
+	t.Parallel()
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	repo := New(sqlxDB)
+
+	now := time.Now()
+
+	rows := sqlmock.NewRows(offerColumns).
+		AddRow("o2", "p1", "b1", "buyer", "s1", "seller", "s1", "o1", 950, "pending", now, now.Add(time.Hour), nil)
+
+	mock.ExpectQuery("SELECT (.+) FROM offers o").
+		WithArgs("o2").
+		WillReturnRows(rows)
+
+	offer, err := repo.OfferByID(context.Background(), "o2")
+	assert.NoError(t, err)
+	assert.Equal(t, &models.Offer{
+		ID:          "o2",
+		PostID:      "p1",
+		BuyerID:     "b1",
+		BuyerLogin:  "buyer",
+		SellerID:    "s1",
+		SellerLogin: "seller",
+		ProposerID:  "s1",
+		PreviousID:  "o1",
+		Amount:      950,
+		Status:      models.OfferPending,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(time.Hour),
+	}, offer)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOfferByID_NotFound(t *testing.T) {
+	t.Parallel()
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	repo := New(sqlxDB)
+
+	mock.ExpectQuery("SELECT (.+) FROM offers o").
+		WithArgs("o1").
+		WillReturnError(sql.ErrNoRows)
+
+	_, err := repo.OfferByID(context.Background(), "o1")
+	assert.ErrorIs(t, err, models.ErrOfferNotFound)
+}
+
+func TestOffersByUser_Success(t *testing.T) {
+	t.Parallel()
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	repo := New(sqlxDB)
+
+	mock.ExpectQuery("SELECT (.+) FROM offers o").
+		WithArgs("s1", "seller", 20, 0).
+		WillReturnRows(sqlmock.NewRows(offerColumns))
+
+	offers, err := repo.OffersByUser(context.Background(), "s1", models.OfferRoleSeller, 20, 0)
+	assert.NoError(t, err)
+	assert.Empty(t, offers)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateOfferStatus_Closed(t *testing.T) {
+	t.Parallel()
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	repo := New(sqlxDB)
+
+	now := time.Now()
+
+	mock.ExpectExec("UPDATE offers SET status").
+		WithArgs(models.OfferAccepted, now, "o1").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err := repo.UpdateOfferStatus(context.Background(), "o1", models.OfferAccepted, now)
+	assert.ErrorIs(t, err, models.ErrOfferClosed)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCounterOffer_Success(t *testing.T) {
+	t.Parallel()
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	repo := New(sqlxDB)
+
+	now := time.Now()
+	counter := &models.Offer{ID: "o2", PostID: "p1", BuyerID: "b1", SellerID: "s1", ProposerID: "s1", PreviousID: "o1", Amount: 950, Status: models.OfferPending, CreatedAt: now, ExpiresAt: now.Add(time.Hour)}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE offers SET status").
+		WithArgs(models.OfferCountered, now, "o1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO offers").
+		WithArgs("o2", "p1", "b1", "s1", "s1", "o1", int64(950), models.OfferPending, now, now.Add(time.Hour)).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	err := repo.CounterOffer(context.Background(), "o1", counter)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCounterOffer_PreviousClosed(t *testing.T) {
+	t.Parallel()
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	repo := New(sqlxDB)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE offers SET status").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	err := repo.CounterOffer(context.Background(), "o1", &models.Offer{ID: "o2", CreatedAt: time.Now()})
+	assert.ErrorIs(t, err, models.ErrOfferClosed)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestExpireOffers_Success(t *testing.T) {
+	t.Parallel()
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	repo := New(sqlxDB)
+
+	now := time.Now()
+
+	rows := sqlmock.NewRows(offerColumns).
+		AddRow("o1", "p1", "b1", "buyer", "s1", "seller", "b1", nil, 900, "expired", now.Add(-time.Hour), now, now)
+
+	mock.ExpectQuery("WITH expired AS").
+		WithArgs(now).
+		WillReturnRows(rows)
+
+	offers, err := repo.ExpireOffers(context.Background(), now)
+	assert.NoError(t, err)
+	assert.Len(t, offers, 1)
+	assert.Equal(t, models.OfferExpired, offers[0].Status)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,204 @@
+package orderrepo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"marketplace/internal/entities"
+	"marketplace/internal/models"
+	"marketplace/internal/utils/mapper"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+const pkg = "orderRepo/"
+
+const orderSelect = `SELECT
+			o.id AS id,
+			o.post_id AS post_id,
+			o.buyer_id AS buyer_id,
+			b.login AS buyer_login,
+			o.seller_id AS seller_id,
+			s.login AS seller_login,
+			o.amount AS amount,
+			o.status AS status,
+			o.payment_id AS payment_id,
+			o.created_at AS created_at,
+			o.updated_at AS updated_at,
+			o.expires_at AS expires_at
+		FROM orders o
+		JOIN users b ON b.id = o.buyer_id
+		JOIN users s ON s.id = o.seller_id`
+
+type repository struct {
+	db *sqlx.DB
+}
+
+func New(db *sqlx.DB) *repository {
+	return &repository{
+		db: db,
+	}
+}
+
+// AddOrder stores a new order. A post can only be reserved by one order that
+// has not been cancelled, a second one fails with a unique constraint error.
+func (r *repository) AddOrder(ctx context.Context, order *models.Order) error {
+	op := pkg + "AddOrder"
+
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO orders(id, post_id, buyer_id, seller_id, amount, status, created_at, updated_at, expires_at)
+		VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+		order.ID, order.PostID, order.BuyerID, order.SellerID, order.Amount, order.Status, order.CreatedAt, order.UpdatedAt, order.ExpiresAt)
+	if err != nil {
+		if pgErr, ok := err.(*pq.Error); ok {
+			if pgErr.Code == "23505" {
+				return &models.UniqueConstraintError{
+					Constraint: pgErr.Constraint,
+					Err:        models.ErrUNIQUEConstraintFailed,
+				}
+			}
+		}
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (r *repository) OrderByID(ctx context.Context, id string) (*models.Order, error) {
+	op := pkg + "OrderByID"
+
+	var rawOrder entities.Order
+
+	err := r.db.GetContext(ctx, &rawOrder, orderSelect+`
+		WHERE o.id = $1`, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, models.ErrOrderNotFound
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return mapper.OrderByEntity(&rawOrder), nil
+}
+
+// OrdersByUser returns the orders the user takes part in as a buyer, as a
+// seller, or on either side when role is empty.
+func (r *repository) OrdersByUser(ctx context.Context, userID string, role models.OrderRole, limit int, offset int) ([]*models.Order, error) {
+	op := pkg + "OrdersByUser"
+
+	rawOrders := make([]*entities.Order, 0)
+
+	err := r.db.SelectContext(ctx, &rawOrders, orderSelect+`
+		WHERE ($2 <> 'seller' AND o.buyer_id = $1) OR ($2 <> 'buyer' AND o.seller_id = $1)
+		ORDER BY o.created_at DESC, o.id ASC
+		LIMIT $3 OFFSET $4`, userID, string(role), limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return mapper.OrdersByEntities(rawOrders), nil
+}
+
+// ActiveOrdersByUser returns the pending and confirmed orders the user takes
+// part in on either side.
+func (r *repository) ActiveOrdersByUser(ctx context.Context, userID string) ([]*models.Order, error) {
+	op := pkg + "ActiveOrdersByUser"
+
+	rawOrders := make([]*entities.Order, 0)
+
+	err := r.db.SelectContext(ctx, &rawOrders, orderSelect+`
+		WHERE (o.buyer_id = $1 OR o.seller_id = $1) AND o.status IN ($2, $3)
+		ORDER BY o.created_at ASC, o.id ASC`, userID, models.OrderPending, models.OrderConfirmed)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return mapper.OrdersByEntities(rawOrders), nil
+}
+
+// HasCompletedOrder reports whether the buyer has a completed order for the
+// post.
+func (r *repository) HasCompletedOrder(ctx context.Context, postID string, buyerID string) (bool, error) {
+	op := pkg + "HasCompletedOrder"
+
+	var exists bool
+
+	err := r.db.GetContext(ctx, &exists,
+		`SELECT EXISTS(SELECT 1 FROM orders WHERE post_id = $1 AND buyer_id = $2 AND status = $3)`,
+		postID, buyerID, models.OrderCompleted)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return exists, nil
+}
+
+// UpdateOrderStatus stores the new status and payment of order, provided it is
+// still in status from. Otherwise someone else has moved the order first and
+// models.ErrInvalidTransition is returned.
+func (r *repository) UpdateOrderStatus(ctx context.Context, order *models.Order, from models.OrderStatus) error {
+	op := pkg + "UpdateOrderStatus"
+
+	var paymentID any
+	if order.PaymentID != "" {
+		paymentID = order.PaymentID
+	}
+
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE orders SET status = $1, payment_id = $2, updated_at = $3 WHERE id = $4 AND status = $5`,
+		order.Status, paymentID, order.UpdatedAt, order.ID, from)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if affected == 0 {
+		return models.ErrInvalidTransition
+	}
+
+	return nil
+}
+
+// ExpireOrders cancels pending orders that were not paid in time, releasing
+// their posts, and returns them.
+func (r *repository) ExpireOrders(ctx context.Context, now time.Time) ([]*models.Order, error) {
+	op := pkg + "ExpireOrders"
+
+	rawOrders := make([]*entities.Order, 0)
+
+	err := r.db.SelectContext(ctx, &rawOrders,
+		`WITH expired AS (
+			UPDATE orders SET status = 'cancelled', updated_at = $1
+			WHERE status = 'pending' AND expires_at <= $1
+			RETURNING *
+		)
+		SELECT
+			o.id AS id,
+			o.post_id AS post_id,
+			o.buyer_id AS buyer_id,
+			b.login AS buyer_login,
+			o.seller_id AS seller_id,
+			s.login AS seller_login,
+			o.amount AS amount,
+			o.status AS status,
+			o.payment_id AS payment_id,
+			o.created_at AS created_at,
+			o.updated_at AS updated_at,
+			o.expires_at AS expires_at
+		FROM expired o
+		JOIN users b ON b.id = o.buyer_id
+		JOIN users s ON s.id = o.seller_id`, now)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return mapper.OrdersByEntities(rawOrders), nil
+}
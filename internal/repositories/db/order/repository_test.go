@@ -0,0 +1,247 @@
+package orderrepo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"marketplace/internal/models"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+var orderColumns = []string{"id", "post_id", "buyer_id", "buyer_login", "seller_id", "seller_login", "amount", "status", "payment_id", "created_at", "updated_at", "expires_at"}
+
+func TestAddOrder_Success(t *testing.T) {
+	t.Parallel()
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	repo := New(sqlxDB)
+
+	now := time.Now()
+	order := &models.Order{ID: "o1", PostID: "p1", BuyerID: "b1", SellerID: "s1", Amount: 500, Status: models.OrderPending, CreatedAt: now, UpdatedAt: now, ExpiresAt: now.Add(time.Hour)}
+
+	mock.ExpectExec("INSERT INTO orders").
+		WithArgs("o1", "p1", "b1", "s1", int64(500), models.OrderPending, now, now, now.Add(time.Hour)).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	err := repo.AddOrder(context.Background(), order)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAddOrder_Reserved(t *testing.T) {
+	t.Parallel()
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	repo := New(sqlxDB)
+
+	mock.ExpectExec("INSERT INTO orders").
+		WillReturnError(&pq.Error{Code: "23505", Constraint: "orders_reserved_post_id_idx"})
+
+	err := repo.AddOrder(context.Background(), &models.Order{ID: "o1"})
+
+	var uce *models.UniqueConstraintError
+	assert.True(t, errors.As(err, &uce))
+	assert.Equal(t, "orders_reserved_post_id_idx", uce.Constraint)
+}
+
+func TestOrderByID_Success(t *testing.T) {
+	t.Parallel()
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	repo := New(sqlxDB)
+
+	now := time.Now()
+
+	mock.ExpectQuery("SELECT (.+) FROM orders o").
+		WithArgs("o1").
+		WillReturnRows(sqlmock.NewRows(orderColumns).
+			AddRow("o1", "p1", "b1", "buyer", "s1", "seller", 500, "confirmed", "pay_1", now, now, now.Add(time.Hour)))
+
+	order, err := repo.OrderByID(context.Background(), "o1")
+	assert.NoError(t, err)
+	assert.Equal(t, &models.Order{
+		ID:          "o1",
+		PostID:      "p1",
+		BuyerID:     "b1",
+		BuyerLogin:  "buyer",
+		SellerID:    "s1",
+		SellerLogin: "seller",
+		Amount:      500,
+		Status:      models.OrderConfirmed,
+		PaymentID:   "pay_1",
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		ExpiresAt:   now.Add(time.Hour),
+	}, order)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOrderByID_NotFound(t *testing.T) {
+	t.Parallel()
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	repo := New(sqlxDB)
+
+	mock.ExpectQuery("SELECT (.+) FROM orders o").
+		WithArgs("o1").
+		WillReturnError(sql.ErrNoRows)
+
+	_, err := repo.OrderByID(context.Background(), "o1")
+	assert.ErrorIs(t, err, models.ErrOrderNotFound)
+}
+
+func TestOrdersByUser_Success(t *testing.T) {
+	t.Parallel()
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	repo := New(sqlxDB)
+
+	mock.ExpectQuery("SELECT (.+) FROM orders o").
+		WithArgs("b1", "buyer", 20, 0).
+		WillReturnRows(sqlmock.NewRows(orderColumns))
+
+	orders, err := repo.OrdersByUser(context.Background(), "b1", models.OrderRoleBuyer, 20, 0)
+	assert.NoError(t, err)
+	assert.Empty(t, orders)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestActiveOrdersByUser(t *testing.T) {
+	t.Parallel()
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	repo := New(sqlxDB)
+
+	now := time.Now()
+
+	mock.ExpectQuery("SELECT (.+) FROM orders o").
+		WithArgs("s1", models.OrderPending, models.OrderConfirmed).
+		WillReturnRows(sqlmock.NewRows(orderColumns).
+			AddRow("o1", "p1", "b1", "buyer", "s1", "seller", 500, "confirmed", "pay_1", now, now, now.Add(time.Hour)))
+
+	orders, err := repo.ActiveOrdersByUser(context.Background(), "s1")
+	assert.NoError(t, err)
+	if assert.Len(t, orders, 1) {
+		assert.Equal(t, "o1", orders[0].ID)
+		assert.Equal(t, models.OrderConfirmed, orders[0].Status)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestHasCompletedOrder(t *testing.T) {
+	t.Parallel()
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	repo := New(sqlxDB)
+
+	mock.ExpectQuery("SELECT EXISTS").
+		WithArgs("p1", "b1", models.OrderCompleted).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+	exists, err := repo.HasCompletedOrder(context.Background(), "p1", "b1")
+	assert.NoError(t, err)
+	assert.True(t, exists)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateOrderStatus_Success(t *testing.T) {
+	t.Parallel()
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	repo := New(sqlxDB)
+
+	now := time.Now()
+	order := &models.Order{ID: "o1", Status: models.OrderConfirmed, PaymentID: "pay_1", UpdatedAt: now}
+
+	mock.ExpectExec("UPDATE orders SET status").
+		WithArgs(models.OrderConfirmed, "pay_1", now, "o1", models.OrderPending).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := repo.UpdateOrderStatus(context.Background(), order, models.OrderPending)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateOrderStatus_Moved(t *testing.T) {
+	t.Parallel()
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	repo := New(sqlxDB)
+
+	now := time.Now()
+	order := &models.Order{ID: "o1", Status: models.OrderCancelled, UpdatedAt: now}
+
+	mock.ExpectExec("UPDATE orders SET status").
+		WithArgs(models.OrderCancelled, nil, now, "o1", models.OrderPending).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err := repo.UpdateOrderStatus(context.Background(), order, models.OrderPending)
+	assert.ErrorIs(t, err, models.ErrInvalidTransition)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestExpireOrders_Success(t *testing.T) {
+	t.Parallel()
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	repo := New(sqlxDB)
+
+	now := time.Now()
+
+	mock.ExpectQuery("WITH expired AS").
+		WithArgs(now).
+		WillReturnRows(sqlmock.NewRows(orderColumns).
+			AddRow("o1", "p1", "b1", "buyer", "s1", "seller", 500, "cancelled", nil, now.Add(-time.Hour), now, now))
+
+	orders, err := repo.ExpireOrders(context.Background(), now)
+	assert.NoError(t, err)
+	assert.Len(t, orders, 1)
+	assert.Equal(t, models.OrderCancelled, orders[0].Status)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -16,6 +16,38 @@ import (
 
 const pkg = "postRepo/"
 
+const postByIDSelect = `SELECT
+			p.id AS id,
+			p.owner_id AS owner_id,
+			u.login AS owner_login,
+			p.header AS header,
+			p.text AS text,
+			p.price AS price,
+			d.id AS document_id,
+			d.name AS document_name,
+			d.mime AS document_mime,
+			d.path AS document_path,
+			p.created_at AS created_at,
+			a.start_price AS auction_start_price,
+			a.min_increment AS auction_min_increment,
+			a.ends_at AS auction_ends_at,
+			a.current_bid AS auction_current_bid,
+			a.bid_count AS auction_bid_count,
+			a.leader_id AS auction_leader_id,
+			l.login AS auction_leader_login,
+			a.closed_at AS auction_closed_at,
+			rt.review_count AS owner_review_count,
+			rt.rating_sum AS owner_rating_sum
+		FROM posts p
+		INNER JOIN users u ON u.id = p.owner_id
+		INNER JOIN documents d ON d.post_id = p.id
+		LEFT JOIN auctions a ON a.post_id = p.id
+		LEFT JOIN users l ON l.id = a.leader_id
+		LEFT JOIN LATERAL (
+			SELECT COUNT(*) AS review_count, COALESCE(SUM(rating), 0) AS rating_sum
+			FROM reviews WHERE seller_id = p.owner_id
+		) rt ON TRUE`
+
 type repository struct {
 	db *sqlx.DB
 }
@@ -70,6 +102,15 @@ func (r *repository) AddPost(ctx context.Context, post *models.PostWithDocument)
 		return fmt.Errorf("%s: %w", op, err)
 	}
 
+	if post.Auction != nil {
+		_, err = tx.ExecContext(ctx,
+			`INSERT INTO auctions(post_id, start_price, min_increment, ends_at) VALUES($1, $2, $3, $4)`,
+			post.ID, post.Auction.StartPrice, post.Auction.MinIncrement, post.Auction.EndsAt)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
 	err = tx.Commit()
 	if err != nil {
 		return fmt.Errorf("%s: %w", op, err)
@@ -78,6 +119,9 @@ func (r *repository) AddPost(ctx context.Context, post *models.PostWithDocument)
 	return nil
 }
 
+// FilteredPosts returns a page of posts. Posts of banned users are left out
+// until the ban is lifted or runs out, and posts hidden after reports until a
+// moderator dismisses them.
 func (r *repository) FilteredPosts(ctx context.Context, limit int, offset int, filter *models.PostsFilter) ([]*models.PostWithDocument, error) {
 	op := pkg + "FilteredPosts"
 
@@ -95,10 +139,28 @@ func (r *repository) FilteredPosts(ctx context.Context, limit int, offset int, f
 	d.name AS document_name,
 	d.mime AS document_mime,
 	d.path AS document_path,
-	p.created_at AS created_at
+	p.created_at AS created_at,
+	a.start_price AS auction_start_price,
+	a.min_increment AS auction_min_increment,
+	a.ends_at AS auction_ends_at,
+	a.current_bid AS auction_current_bid,
+	a.bid_count AS auction_bid_count,
+	a.leader_id AS auction_leader_id,
+	l.login AS auction_leader_login,
+	a.closed_at AS auction_closed_at,
+	rt.review_count AS owner_review_count,
+	rt.rating_sum AS owner_rating_sum
 	FROM posts p
 	INNER JOIN users u ON u.id = p.owner_id
+		AND (u.banned_at IS NULL OR u.banned_until <= NOW())
+		AND p.hidden_at IS NULL
 	INNER JOIN documents d ON d.post_id = p.id
+	LEFT JOIN auctions a ON a.post_id = p.id
+	LEFT JOIN users l ON l.id = a.leader_id
+	LEFT JOIN LATERAL (
+		SELECT COUNT(*) AS review_count, COALESCE(SUM(rating), 0) AS rating_sum
+		FROM reviews WHERE seller_id = p.owner_id
+	) rt ON TRUE
 	`
 
 	tail, args, err := buildFilteredQueryTail(limit, offset, filter)
@@ -119,6 +181,90 @@ func (r *repository) FilteredPosts(ctx context.Context, limit int, offset int, f
 	return mapper.PostsByEntities(rawPosts), nil
 }
 
+func (r *repository) PostByID(ctx context.Context, id string) (*models.PostWithDocument, error) {
+	return r.postByID(ctx, pkg+"PostByID", postByIDSelect+`
+		WHERE p.id = $1`, id)
+}
+
+// ListedPostByID returns the post only while buyers can see it in the
+// listings: it is not hidden after reports and its owner is not banned.
+func (r *repository) ListedPostByID(ctx context.Context, id string) (*models.PostWithDocument, error) {
+	return r.postByID(ctx, pkg+"ListedPostByID", postByIDSelect+`
+		WHERE p.id = $1
+			AND p.hidden_at IS NULL
+			AND (u.banned_at IS NULL OR u.banned_until <= NOW())`, id)
+}
+
+func (r *repository) postByID(ctx context.Context, op string, query string, id string) (*models.PostWithDocument, error) {
+	rawPost := entities.PostWithDocument{}
+
+	err := r.db.GetContext(ctx, &rawPost, query, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, models.ErrPostNotFound
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return mapper.PostByEntity(&rawPost), nil
+}
+
+// PostsByOwner returns all posts of the user with their documents, oldest
+// first.
+func (r *repository) PostsByOwner(ctx context.Context, ownerID string) ([]*models.PostWithDocument, error) {
+	op := pkg + "PostsByOwner"
+
+	rawPosts := make([]*entities.PostWithDocument, 0)
+
+	err := r.db.SelectContext(ctx, &rawPosts,
+		`SELECT
+			p.id AS id,
+			p.owner_id AS owner_id,
+			u.login AS owner_login,
+			p.header AS header,
+			p.text AS text,
+			p.price AS price,
+			d.id AS document_id,
+			d.name AS document_name,
+			d.mime AS document_mime,
+			d.path AS document_path,
+			p.created_at AS created_at
+		FROM posts p
+		INNER JOIN users u ON u.id = p.owner_id
+		INNER JOIN documents d ON d.post_id = p.id
+		WHERE p.owner_id = $1
+		ORDER BY p.created_at ASC, p.id ASC`, ownerID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return mapper.PostsByEntities(rawPosts), nil
+}
+
+// UpdatePost stores the header, text and price of the post. The image and the
+// auction terms are not changed.
+func (r *repository) UpdatePost(ctx context.Context, post *models.PostWithDocument) error {
+	op := pkg + "UpdatePost"
+
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE posts SET header = $1, text = $2, price = $3 WHERE id = $4`,
+		post.Header, post.Text, post.Price, post.ID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if affected == 0 {
+		return models.ErrPostNotFound
+	}
+
+	return nil
+}
+
 func (r *repository) DeletePost(ctx context.Context, id string) error {
 	op := pkg + "DeletePost"
 
@@ -152,6 +298,12 @@ func buildFilteredQueryTail(limit int, offset int, filter *models.PostsFilter) (
 			argIdx++
 		}
 
+		if len(filter.ExcludedOwnerIDs) > 0 {
+			where = append(where, fmt.Sprintf("p.owner_id <> ALL($%d::uuid[])", argIdx))
+			args = append(args, pq.Array(filter.ExcludedOwnerIDs))
+			argIdx++
+		}
+
 		if len(where) > 0 {
 			sb.WriteString("WHERE " + strings.Join(where, " AND ") + "\n")
 		}
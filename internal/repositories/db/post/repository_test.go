@@ -61,6 +61,48 @@ func TestAddPost_Success(t *testing.T) {
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestAddPost_WithAuction(t *testing.T) {
+	t.Parallel()
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	repo := New(sqlxDB)
+
+	post := &models.PostWithDocument{
+		ID:      uuid.NewV4().String(),
+		OwnerID: "1",
+		Header:  "header",
+		Text:    "text",
+		Price:   1000,
+		Auction: &models.Auction{
+			StartPrice:   1000,
+			MinIncrement: 50,
+			EndsAt:       time.Now().Add(24 * time.Hour),
+		},
+		Document: &models.Document{
+			Name: "1.jpg",
+			Mime: "image/jpeg",
+		},
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO posts").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO documents").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO auctions").
+		WithArgs(post.ID, int64(1000), int64(50), post.Auction.EndsAt).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	err := repo.AddPost(context.Background(), post)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestAddUser_PostsUniqueViolation(t *testing.T) {
 	t.Parallel()
 
@@ -328,9 +370,12 @@ func TestFilteredPosts_Success(t *testing.T) {
 	d\.name AS document_name,
 	d\.mime AS document_mime,
 	d\.path AS document_path,
-	p\.created_at AS created_at
+	p\.created_at AS created_at,
+	(.+)
 	FROM posts p
 	INNER JOIN users u ON u\.id = p\.owner_id
+		AND \(u\.banned_at IS NULL OR u\.banned_until <= NOW\(\)\)
+		AND p\.hidden_at IS NULL
 	INNER JOIN documents d ON d\.post_id = p\.id.*`).
 		WithArgs(100, 150, 10, 0).
 		WillReturnRows(rows)
@@ -375,9 +420,12 @@ func TestFilteredPosts_NoRows(t *testing.T) {
 	d\.name AS document_name,
 	d\.mime AS document_mime,
 	d\.path AS document_path,
-	p\.created_at AS created_at
+	p\.created_at AS created_at,
+	(.+)
 	FROM posts p
 	INNER JOIN users u ON u\.id = p\.owner_id
+		AND \(u\.banned_at IS NULL OR u\.banned_until <= NOW\(\)\)
+		AND p\.hidden_at IS NULL
 	INNER JOIN documents d ON d\.post_id = p\.id.*`).
 		WithArgs(100, 150, 10, 0).
 		WillReturnError(sql.ErrNoRows)
@@ -420,9 +468,12 @@ func TestFilteredPosts_OtherErr(t *testing.T) {
 	d\.name AS document_name,
 	d\.mime AS document_mime,
 	d\.path AS document_path,
-	p\.created_at AS created_at
+	p\.created_at AS created_at,
+	(.+)
 	FROM posts p
 	INNER JOIN users u ON u\.id = p\.owner_id
+		AND \(u\.banned_at IS NULL OR u\.banned_until <= NOW\(\)\)
+		AND p\.hidden_at IS NULL
 	INNER JOIN documents d ON d\.post_id = p\.id.*`).
 		WithArgs(100, 150, 10, 0).
 		WillReturnError(someErr)
@@ -435,6 +486,192 @@ func TestFilteredPosts_OtherErr(t *testing.T) {
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestPostByID_Success(t *testing.T) {
+	t.Parallel()
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	repo := New(sqlxDB)
+
+	now := time.Now()
+
+	rows := sqlmock.NewRows([]string{"id", "owner_id", "owner_login", "header", "text", "price", "document_id", "document_name", "document_mime", "document_path", "created_at"}).
+		AddRow("1", "2", "login", "header", "text", 100, "11", "1.jpg", "image/jpeg", "/static/1.jpg", now)
+
+	mock.ExpectQuery("SELECT (.+) FROM posts p (.+) WHERE p.id = ").
+		WithArgs("1").
+		WillReturnRows(rows)
+
+	post, err := repo.PostByID(context.Background(), "1")
+	assert.NoError(t, err)
+	assert.Equal(t, "2", post.OwnerID)
+	assert.Equal(t, "/static/1.jpg", post.Document.Path)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostsByOwner_Success(t *testing.T) {
+	t.Parallel()
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	repo := New(sqlxDB)
+
+	now := time.Now()
+
+	rows := sqlmock.NewRows([]string{"id", "owner_id", "owner_login", "header", "text", "price", "document_id", "document_name", "document_mime", "document_path", "created_at"}).
+		AddRow("1", "2", "login", "header", "text", 100, "11", "1.jpg", "image/jpeg", "/static/1.jpg", now).
+		AddRow("3", "2", "login", "header 2", "text", 200, "13", "3.png", "image/png", "/static/3.png", now)
+
+	mock.ExpectQuery("SELECT (.+) FROM posts p (.+) WHERE p.owner_id = ").
+		WithArgs("2").
+		WillReturnRows(rows)
+
+	posts, err := repo.PostsByOwner(context.Background(), "2")
+	assert.NoError(t, err)
+	assert.Len(t, posts, 2)
+	assert.Equal(t, "/static/3.png", posts[1].Document.Path)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostByID_Auction(t *testing.T) {
+	t.Parallel()
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	repo := New(sqlxDB)
+
+	now := time.Now()
+
+	rows := sqlmock.NewRows([]string{"id", "owner_id", "owner_login", "header", "text", "price", "document_id", "document_name", "document_mime", "document_path", "created_at",
+		"auction_start_price", "auction_min_increment", "auction_ends_at", "auction_current_bid", "auction_bid_count", "auction_leader_id", "auction_leader_login", "auction_closed_at"}).
+		AddRow("1", "2", "login", "header", "text", 100, "11", "1.jpg", "image/jpeg", "/static/1.jpg", now,
+			100, 10, now.Add(time.Hour), 120, 2, "3", "bidder", nil)
+
+	mock.ExpectQuery("SELECT (.+) FROM posts p (.+) WHERE p.id = ").
+		WithArgs("1").
+		WillReturnRows(rows)
+
+	post, err := repo.PostByID(context.Background(), "1")
+	assert.NoError(t, err)
+	assert.Equal(t, &models.Auction{
+		StartPrice:   100,
+		MinIncrement: 10,
+		EndsAt:       now.Add(time.Hour),
+		CurrentBid:   120,
+		BidCount:     2,
+		LeaderID:     "3",
+		LeaderLogin:  "bidder",
+	}, post.Auction)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostByID_OwnerRating(t *testing.T) {
+	t.Parallel()
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	repo := New(sqlxDB)
+
+	rows := sqlmock.NewRows([]string{"id", "owner_id", "owner_login", "header", "text", "price", "document_id", "document_name", "document_mime", "document_path", "created_at",
+		"owner_review_count", "owner_rating_sum"}).
+		AddRow("1", "2", "login", "header", "text", 100, "11", "1.jpg", "image/jpeg", "/static/1.jpg", time.Now(), 3, 13)
+
+	mock.ExpectQuery("SELECT (.+) FROM posts p (.+) LEFT JOIN LATERAL (.+) FROM reviews WHERE seller_id = p.owner_id (.+) WHERE p.id = ").
+		WithArgs("1").
+		WillReturnRows(rows)
+
+	post, err := repo.PostByID(context.Background(), "1")
+	assert.NoError(t, err)
+	assert.Equal(t, models.Rating{Count: 3, Sum: 13}, post.OwnerRating)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostByID_NotFound(t *testing.T) {
+	t.Parallel()
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	repo := New(sqlxDB)
+
+	mock.ExpectQuery("SELECT (.+) FROM posts p").
+		WithArgs("1").
+		WillReturnError(sql.ErrNoRows)
+
+	post, err := repo.PostByID(context.Background(), "1")
+	assert.ErrorIs(t, err, models.ErrPostNotFound)
+	assert.Nil(t, post)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestListedPostByID(t *testing.T) {
+	t.Parallel()
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	repo := New(sqlxDB)
+
+	mock.ExpectQuery(`SELECT (.+) FROM posts p (.+) WHERE p\.id = \$1
+			AND p\.hidden_at IS NULL
+			AND \(u\.banned_at IS NULL OR u\.banned_until <= NOW\(\)\)`).
+		WithArgs("1").
+		WillReturnError(sql.ErrNoRows)
+
+	post, err := repo.ListedPostByID(context.Background(), "1")
+	assert.ErrorIs(t, err, models.ErrPostNotFound)
+	assert.Nil(t, post)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdatePost(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		affected int64
+		want     error
+	}{
+		{name: "success", affected: 1, want: nil},
+		{name: "not found", affected: 0, want: models.ErrPostNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			db, mock, _ := sqlmock.New()
+			defer db.Close()
+
+			repo := New(sqlx.NewDb(db, "sqlmock"))
+
+			mock.ExpectExec("UPDATE posts SET header = (.+) WHERE id = ").
+				WithArgs("new header", "new text", int64(150), "1").
+				WillReturnResult(sqlmock.NewResult(0, tt.affected))
+
+			err := repo.UpdatePost(context.Background(), &models.PostWithDocument{ID: "1", Header: "new header", Text: "new text", Price: 150})
+			assert.ErrorIs(t, err, tt.want)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
 func TestDeletePost_Success(t *testing.T) {
 	t.Parallel()
 
@@ -520,6 +757,19 @@ ORDER BY price ASC, created_at DESC, p.id ASC
 LIMIT $3 OFFSET $4`,
 			wantArgs: []any{uint(100), uint(500), 20, 40},
 		},
+		{
+			name:   "excluded owners",
+			limit:  10,
+			offset: 0,
+			filter: &models.PostsFilter{
+				MaxPrice:         500,
+				ExcludedOwnerIDs: []string{"u2", "u3"},
+			},
+			wantSQL: `WHERE price <= $1 AND p.owner_id <> ALL($2::uuid[])
+ORDER BY created_at DESC, p.id ASC
+LIMIT $3 OFFSET $4`,
+			wantArgs: []any{uint(500), pq.Array([]string{"u2", "u3"}), 10, 0},
+		},
 		{
 			name:   "sort by created_at desc",
 			limit:  15,
@@ -0,0 +1,204 @@
+package reportrepo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"marketplace/internal/entities"
+	"marketplace/internal/models"
+	"marketplace/internal/utils/mapper"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+const pkg = "reportRepo/"
+
+const reportSelect = `SELECT
+			r.id AS id,
+			r.post_id AS post_id,
+			p.owner_id AS post_owner_id,
+			p.header AS post_header,
+			p.hidden_at IS NOT NULL AS post_hidden,
+			r.reporter_id AS reporter_id,
+			u.login AS reporter_login,
+			r.reason AS reason,
+			r.text AS text,
+			r.status AS status,
+			r.created_at AS created_at,
+			r.closed_at AS closed_at
+		FROM post_reports r
+		JOIN posts p ON p.id = r.post_id
+		JOIN users u ON u.id = r.reporter_id`
+
+type repository struct {
+	db *sqlx.DB
+}
+
+func New(db *sqlx.DB) *repository {
+	return &repository{
+		db: db,
+	}
+}
+
+// AddReport stores the report and returns how many open reports the post has
+// now, this one included.
+func (r *repository) AddReport(ctx context.Context, report *models.Report) (int, error) {
+	op := pkg + "AddReport"
+
+	tx, err := r.db.BeginTxx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO post_reports(id, post_id, reporter_id, reason, text, status, created_at) VALUES($1, $2, $3, $4, $5, $6, $7)`,
+		report.ID, report.PostID, report.ReporterID, string(report.Reason), report.Text, string(report.Status), report.CreatedAt)
+	if err != nil {
+		if pgErr, ok := err.(*pq.Error); ok {
+			if pgErr.Code == "23505" {
+				return 0, &models.UniqueConstraintError{
+					Constraint: pgErr.Constraint,
+					Err:        models.ErrUNIQUEConstraintFailed,
+				}
+			}
+		}
+
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	var count int
+
+	err = tx.GetContext(ctx, &count,
+		`SELECT COUNT(*) FROM post_reports WHERE post_id = $1 AND status = $2`,
+		report.PostID, string(models.ReportOpen))
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return count, nil
+}
+
+// HidePost takes the post out of the listings and reports whether it was
+// visible until now. Hiding it again keeps the original time.
+func (r *repository) HidePost(ctx context.Context, postID string) (bool, error) {
+	op := pkg + "HidePost"
+
+	res, err := r.db.ExecContext(ctx, `UPDATE posts SET hidden_at = NOW() WHERE id = $1 AND hidden_at IS NULL`, postID)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return affected > 0, nil
+}
+
+// OpenReports returns the moderation queue, oldest first.
+func (r *repository) OpenReports(ctx context.Context, limit int, offset int) ([]*models.Report, error) {
+	op := pkg + "OpenReports"
+
+	rawReports := make([]*entities.Report, 0)
+
+	err := r.db.SelectContext(ctx, &rawReports, reportSelect+`
+		WHERE r.status = $1
+		ORDER BY r.created_at ASC, r.id ASC
+		LIMIT $2 OFFSET $3`, string(models.ReportOpen), limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return mapper.ReportsByEntities(rawReports), nil
+}
+
+func (r *repository) ReportByID(ctx context.Context, id string) (*models.Report, error) {
+	op := pkg + "ReportByID"
+
+	rawReport := entities.Report{}
+
+	err := r.db.GetContext(ctx, &rawReport, reportSelect+`
+		WHERE r.id = $1`, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, models.ErrReportNotFound
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return mapper.ReportByEntity(&rawReport), nil
+}
+
+// CloseReports closes every open report of the post with the status and
+// reports whether the post is hidden afterwards. A resolved post stays
+// hidden; a dismissed one is shown again unless an earlier report of it was
+// resolved, so that new reports cannot bring a removed post back. It returns
+// models.ErrReportNotFound when the post has no open reports left.
+func (r *repository) CloseReports(ctx context.Context, postID string, status models.ReportStatus, moderatorID string, closedAt time.Time) (bool, error) {
+	op := pkg + "CloseReports"
+
+	tx, err := r.db.BeginTxx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	res, err := tx.ExecContext(ctx,
+		`UPDATE post_reports SET status = $1, closed_at = $2, closed_by = $3
+		WHERE post_id = $4 AND status = $5`,
+		string(status), closedAt, moderatorID, postID, string(models.ReportOpen))
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if affected == 0 {
+		return false, models.ErrReportNotFound
+	}
+
+	var hidden bool
+
+	if status == models.ReportResolved {
+		err = tx.GetContext(ctx, &hidden,
+			`UPDATE posts SET hidden_at = COALESCE(hidden_at, $1) WHERE id = $2
+			RETURNING hidden_at IS NOT NULL`, closedAt, postID)
+	} else {
+		err = tx.GetContext(ctx, &hidden,
+			`UPDATE posts SET hidden_at = CASE
+				WHEN EXISTS(SELECT 1 FROM post_reports WHERE post_id = $1 AND status = $2) THEN hidden_at
+				ELSE NULL
+			END
+			WHERE id = $1
+			RETURNING hidden_at IS NOT NULL`, postID, string(models.ReportResolved))
+	}
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return hidden, nil
+}
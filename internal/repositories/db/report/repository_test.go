@@ -0,0 +1,183 @@
+package reportrepo
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"marketplace/internal/models"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func newRepo(t *testing.T) (*repository, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	return New(sqlx.NewDb(db, "sqlmock")), mock
+}
+
+func TestAddReport(t *testing.T) {
+	t.Parallel()
+
+	repo, mock := newRepo(t)
+
+	now := time.Now()
+	report := &models.Report{ID: "r1", PostID: "p1", ReporterID: "u1", Reason: models.ReportScam, Text: "fake", Status: models.ReportOpen, CreatedAt: now}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO post_reports").
+		WithArgs("r1", "p1", "u1", "scam", "fake", "open", now).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("SELECT COUNT(.+) FROM post_reports WHERE post_id").
+		WithArgs("p1", "open").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+	mock.ExpectCommit()
+
+	count, err := repo.AddReport(context.Background(), report)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, count)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAddReport_Duplicate(t *testing.T) {
+	t.Parallel()
+
+	repo, mock := newRepo(t)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO post_reports").
+		WillReturnError(&pq.Error{Code: "23505", Constraint: "post_reports_post_id_reporter_id_key"})
+	mock.ExpectRollback()
+
+	_, err := repo.AddReport(context.Background(), &models.Report{ID: "r1", PostID: "p1", ReporterID: "u1"})
+
+	var uce *models.UniqueConstraintError
+	assert.True(t, errors.As(err, &uce))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestHidePost(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		affected int64
+		want     bool
+	}{
+		{name: "visible post", affected: 1, want: true},
+		{name: "already hidden", affected: 0, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			repo, mock := newRepo(t)
+
+			mock.ExpectExec("UPDATE posts SET hidden_at = NOW\\(\\) WHERE id = (.+) AND hidden_at IS NULL").
+				WithArgs("p1").
+				WillReturnResult(sqlmock.NewResult(0, tt.affected))
+
+			hidden, err := repo.HidePost(context.Background(), "p1")
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, hidden)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestReportByID_NotFound(t *testing.T) {
+	t.Parallel()
+
+	repo, mock := newRepo(t)
+
+	mock.ExpectQuery("SELECT (.+) FROM post_reports r (.+) WHERE r.id = ").
+		WithArgs("r1").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	_, err := repo.ReportByID(context.Background(), "r1")
+
+	assert.ErrorIs(t, err, models.ErrReportNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOpenReports(t *testing.T) {
+	t.Parallel()
+
+	repo, mock := newRepo(t)
+
+	now := time.Now()
+
+	rows := sqlmock.NewRows([]string{"id", "post_id", "post_owner_id", "post_header", "post_hidden", "reporter_id", "reporter_login", "reason", "text", "status", "created_at", "closed_at"}).
+		AddRow("r1", "p1", "u2", "bike", true, "u1", "alice", "scam", "fake", "open", now, nil)
+
+	mock.ExpectQuery("SELECT (.+) FROM post_reports r (.+) WHERE r.status = (.+) LIMIT").
+		WithArgs("open", 10, 0).
+		WillReturnRows(rows)
+
+	reports, err := repo.OpenReports(context.Background(), 10, 0)
+
+	assert.NoError(t, err)
+	assert.Len(t, reports, 1)
+	assert.True(t, reports[0].PostHidden)
+	assert.Equal(t, models.ReportScam, reports[0].Reason)
+	assert.Nil(t, reports[0].ClosedAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCloseReports(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+
+	tests := []struct {
+		name       string
+		status     models.ReportStatus
+		affected   int64
+		postSQL    string
+		postArgs   []driver.Value
+		hidden     bool
+		wantHidden bool
+		wantErr    error
+	}{
+		{name: "resolve keeps post hidden", status: models.ReportResolved, affected: 2, postSQL: "UPDATE posts SET hidden_at = COALESCE", postArgs: []driver.Value{now, "p1"}, hidden: true, wantHidden: true},
+		{name: "dismiss shows post again", status: models.ReportDismissed, affected: 1, postSQL: "UPDATE posts SET hidden_at = CASE", postArgs: []driver.Value{"p1", "resolved"}},
+		{name: "dismiss keeps resolved post hidden", status: models.ReportDismissed, affected: 1, postSQL: "UPDATE posts SET hidden_at = CASE", postArgs: []driver.Value{"p1", "resolved"}, hidden: true, wantHidden: true},
+		{name: "nothing open", status: models.ReportDismissed, affected: 0, wantErr: models.ErrReportNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			repo, mock := newRepo(t)
+
+			mock.ExpectBegin()
+			mock.ExpectExec("UPDATE post_reports SET status").
+				WithArgs(string(tt.status), now, "m1", "p1", "open").
+				WillReturnResult(sqlmock.NewResult(0, tt.affected))
+			if tt.wantErr == nil {
+				mock.ExpectQuery(tt.postSQL).
+					WithArgs(tt.postArgs...).
+					WillReturnRows(sqlmock.NewRows([]string{"hidden"}).AddRow(tt.hidden))
+				mock.ExpectCommit()
+			} else {
+				mock.ExpectRollback()
+			}
+
+			hidden, err := repo.CloseReports(context.Background(), "p1", tt.status, "m1", now)
+
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Equal(t, tt.wantHidden, hidden)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package reviewrepo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"marketplace/internal/entities"
+	"marketplace/internal/models"
+	"marketplace/internal/utils/mapper"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+const pkg = "reviewRepo/"
+
+const reviewSelect = `SELECT
+			r.id AS id,
+			r.post_id AS post_id,
+			r.seller_id AS seller_id,
+			s.login AS seller_login,
+			r.author_id AS author_id,
+			a.login AS author_login,
+			r.rating AS rating,
+			r.text AS text,
+			r.reply AS reply,
+			r.reply_status AS reply_status,
+			r.created_at AS created_at,
+			r.replied_at AS replied_at
+		FROM reviews r
+		JOIN users s ON s.id = r.seller_id
+		JOIN users a ON a.id = r.author_id`
+
+type repository struct {
+	db *sqlx.DB
+}
+
+func New(db *sqlx.DB) *repository {
+	return &repository{
+		db: db,
+	}
+}
+
+func (r *repository) AddReview(ctx context.Context, review *models.Review) error {
+	op := pkg + "AddReview"
+
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO reviews(id, post_id, seller_id, author_id, rating, text, created_at) VALUES($1, $2, $3, $4, $5, $6, $7)`,
+		review.ID, review.PostID, review.SellerID, review.AuthorID, review.Rating, review.Text, review.CreatedAt)
+	if err != nil {
+		if pgErr, ok := err.(*pq.Error); ok {
+			if pgErr.Code == "23505" {
+				return &models.UniqueConstraintError{
+					Constraint: pgErr.Constraint,
+					Err:        models.ErrUNIQUEConstraintFailed,
+				}
+			}
+		}
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (r *repository) ReviewByID(ctx context.Context, id string) (*models.Review, error) {
+	op := pkg + "ReviewByID"
+
+	var rawReview entities.Review
+
+	err := r.db.GetContext(ctx, &rawReview, reviewSelect+`
+		WHERE r.id = $1`, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, models.ErrReviewNotFound
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return mapper.ReviewByEntity(&rawReview), nil
+}
+
+func (r *repository) ReviewsBySeller(ctx context.Context, sellerID string, limit int, offset int) ([]*models.Review, error) {
+	op := pkg + "ReviewsBySeller"
+
+	rawReviews := make([]*entities.Review, 0)
+
+	err := r.db.SelectContext(ctx, &rawReviews, reviewSelect+`
+		WHERE r.seller_id = $1
+		ORDER BY r.created_at DESC, r.id ASC
+		LIMIT $2 OFFSET $3`, sellerID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return mapper.ReviewsByEntities(rawReviews), nil
+}
+
+func (r *repository) RatingBySeller(ctx context.Context, sellerID string) (models.Rating, error) {
+	op := pkg + "RatingBySeller"
+
+	var rawRating entities.Rating
+
+	err := r.db.GetContext(ctx, &rawRating,
+		`SELECT COUNT(*) AS review_count, COALESCE(SUM(rating), 0) AS rating_sum
+		FROM reviews WHERE seller_id = $1`, sellerID)
+	if err != nil {
+		return models.Rating{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return mapper.RatingByEntity(&rawRating), nil
+}
+
+// PendingReplies returns reviews whose reply waits for moderation, oldest
+// reply first.
+func (r *repository) PendingReplies(ctx context.Context, limit int, offset int) ([]*models.Review, error) {
+	op := pkg + "PendingReplies"
+
+	rawReviews := make([]*entities.Review, 0)
+
+	err := r.db.SelectContext(ctx, &rawReviews, reviewSelect+`
+		WHERE r.reply_status = 'pending'
+		ORDER BY r.replied_at ASC, r.id ASC
+		LIMIT $1 OFFSET $2`, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return mapper.ReviewsByEntities(rawReviews), nil
+}
+
+// UpdateReply stores the seller's reply and sends it to moderation again.
+func (r *repository) UpdateReply(ctx context.Context, id string, reply string, repliedAt time.Time) error {
+	op := pkg + "UpdateReply"
+
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE reviews SET reply = $1, reply_status = 'pending', replied_at = $2 WHERE id = $3`,
+		reply, repliedAt, id)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if affected == 0 {
+		return models.ErrReviewNotFound
+	}
+
+	return nil
+}
+
+// UpdateReplyStatus records the moderation decision on a pending reply. It
+// returns models.ErrNoPendingReply when the reply has already been moderated.
+func (r *repository) UpdateReplyStatus(ctx context.Context, id string, status models.ReplyStatus) error {
+	op := pkg + "UpdateReplyStatus"
+
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE reviews SET reply_status = $1 WHERE id = $2 AND reply_status = 'pending'`,
+		status, id)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if affected == 0 {
+		return models.ErrNoPendingReply
+	}
+
+	return nil
+}
@@ -0,0 +1,204 @@
+package reviewrepo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"marketplace/internal/models"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+var reviewColumns = []string{"id", "post_id", "seller_id", "seller_login", "author_id", "author_login", "rating", "text", "reply", "reply_status", "created_at", "replied_at"}
+
+func TestAddReview_Success(t *testing.T) {
+	t.Parallel()
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	repo := New(sqlxDB)
+
+	now := time.Now()
+	review := &models.Review{ID: "r1", PostID: "p1", SellerID: "s1", AuthorID: "a1", Rating: 5, Text: "great", CreatedAt: now}
+
+	mock.ExpectExec("INSERT INTO reviews").
+		WithArgs("r1", "p1", "s1", "a1", 5, "great", now).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	err := repo.AddReview(context.Background(), review)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAddReview_UniqueViolation(t *testing.T) {
+	t.Parallel()
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	repo := New(sqlxDB)
+
+	mock.ExpectExec("INSERT INTO reviews").
+		WillReturnError(&pq.Error{Code: "23505", Constraint: "reviews_post_id_author_id_key"})
+
+	err := repo.AddReview(context.Background(), &models.Review{ID: "r1"})
+
+	var uce *models.UniqueConstraintError
+	assert.True(t, errors.As(err, &uce))
+	assert.Equal(t, "reviews_post_id_author_id_key", uce.Constraint)
+}
+
+func TestReviewByID(t *testing.T) {
+	t.Parallel()
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	repo := New(sqlxDB)
+
+	now := time.Now()
+
+	mock.ExpectQuery("SELECT (.+) FROM reviews r (.+) WHERE r.id = ").
+		WithArgs("r1").
+		WillReturnRows(sqlmock.NewRows(reviewColumns).
+			AddRow("r1", nil, "s1", "seller", "a1", "author", 4, "ok", "thanks", "pending", now, now))
+
+	review, err := repo.ReviewByID(context.Background(), "r1")
+	assert.NoError(t, err)
+	assert.Equal(t, "", review.PostID)
+	assert.Equal(t, models.ReplyPending, review.ReplyStatus)
+	assert.Equal(t, &now, review.RepliedAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReviewByID_NotFound(t *testing.T) {
+	t.Parallel()
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	repo := New(sqlxDB)
+
+	mock.ExpectQuery("SELECT (.+) FROM reviews r").
+		WithArgs("r1").
+		WillReturnError(sql.ErrNoRows)
+
+	review, err := repo.ReviewByID(context.Background(), "r1")
+	assert.ErrorIs(t, err, models.ErrReviewNotFound)
+	assert.Nil(t, review)
+}
+
+func TestReviewsBySeller(t *testing.T) {
+	t.Parallel()
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	repo := New(sqlxDB)
+
+	now := time.Now()
+
+	mock.ExpectQuery("SELECT (.+) FROM reviews r (.+) WHERE r.seller_id = (.+) LIMIT (.+) OFFSET").
+		WithArgs("s1", 20, 0).
+		WillReturnRows(sqlmock.NewRows(reviewColumns).
+			AddRow("r2", "p2", "s1", "seller", "a2", "second", 3, "", "", "", now, nil).
+			AddRow("r1", "p1", "s1", "seller", "a1", "first", 5, "great", "", "", now.Add(-time.Hour), nil))
+
+	reviews, err := repo.ReviewsBySeller(context.Background(), "s1", 20, 0)
+	assert.NoError(t, err)
+	assert.Len(t, reviews, 2)
+	assert.Equal(t, "r2", reviews[0].ID)
+	assert.Nil(t, reviews[0].RepliedAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRatingBySeller(t *testing.T) {
+	t.Parallel()
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	repo := New(sqlxDB)
+
+	mock.ExpectQuery("SELECT COUNT(.+) FROM reviews WHERE seller_id = ").
+		WithArgs("s1").
+		WillReturnRows(sqlmock.NewRows([]string{"review_count", "rating_sum"}).AddRow(3, 13))
+
+	rating, err := repo.RatingBySeller(context.Background(), "s1")
+	assert.NoError(t, err)
+	assert.Equal(t, models.Rating{Count: 3, Sum: 13}, rating)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateReply_NotFound(t *testing.T) {
+	t.Parallel()
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	repo := New(sqlxDB)
+
+	now := time.Now()
+
+	mock.ExpectExec("UPDATE reviews SET reply = (.+), reply_status = 'pending'").
+		WithArgs("thanks", now, "r1").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err := repo.UpdateReply(context.Background(), "r1", "thanks", now)
+	assert.ErrorIs(t, err, models.ErrReviewNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateReplyStatus(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		affected int64
+		wantErr  error
+	}{
+		{name: "pending", affected: 1, wantErr: nil},
+		{name: "already moderated", affected: 0, wantErr: models.ErrNoPendingReply},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			db, mock, _ := sqlmock.New()
+			defer db.Close()
+
+			sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+			repo := New(sqlxDB)
+
+			mock.ExpectExec("UPDATE reviews SET reply_status = (.+) WHERE id = (.+) AND reply_status = 'pending'").
+				WithArgs(models.ReplyApproved, "r1").
+				WillReturnResult(sqlmock.NewResult(0, tt.affected))
+
+			err := repo.UpdateReplyStatus(context.Background(), "r1", models.ReplyApproved)
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
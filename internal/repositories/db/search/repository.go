@@ -0,0 +1,233 @@
+package searchrepo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"marketplace/internal/entities"
+	"marketplace/internal/models"
+	"marketplace/internal/utils/mapper"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+const pkg = "searchRepo/"
+
+type repository struct {
+	db *sqlx.DB
+}
+
+func New(db *sqlx.DB) *repository {
+	return &repository{
+		db: db,
+	}
+}
+
+func (r *repository) AddSearch(ctx context.Context, search *models.SavedSearch) error {
+	op := pkg + "AddSearch"
+
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO saved_searches(id, user_id, min_price, max_price, sort_by, sort_order, created_at) VALUES($1, $2, $3, $4, $5, $6, $7)`,
+		search.ID, search.UserID, search.Filter.MinPrice, search.Filter.MaxPrice, search.Filter.SortBy, search.Filter.SortOrder, search.CreatedAt)
+	if err != nil {
+		if pgErr, ok := err.(*pq.Error); ok {
+			if pgErr.Code == "23505" {
+				return &models.UniqueConstraintError{
+					Constraint: pgErr.Constraint,
+					Err:        models.ErrUNIQUEConstraintFailed,
+				}
+			}
+		}
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (r *repository) SearchByID(ctx context.Context, id string) (*models.SavedSearch, error) {
+	op := pkg + "SearchByID"
+
+	rawSearch := entities.SavedSearch{}
+
+	err := r.db.GetContext(ctx, &rawSearch,
+		`SELECT
+			s.id AS id,
+			s.user_id AS user_id,
+			s.min_price AS min_price,
+			s.max_price AS max_price,
+			s.sort_by AS sort_by,
+			s.sort_order AS sort_order,
+			s.created_at AS created_at
+		FROM saved_searches s
+		WHERE s.id = $1`, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, models.ErrSearchNotFound
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return mapper.SearchByEntity(&rawSearch), nil
+}
+
+func (r *repository) SearchesByUser(ctx context.Context, userID string) ([]*models.SavedSearch, error) {
+	op := pkg + "SearchesByUser"
+
+	rawSearches := make([]*entities.SavedSearch, 0)
+
+	err := r.db.SelectContext(ctx, &rawSearches,
+		`SELECT
+			s.id AS id,
+			s.user_id AS user_id,
+			s.min_price AS min_price,
+			s.max_price AS max_price,
+			s.sort_by AS sort_by,
+			s.sort_order AS sort_order,
+			s.created_at AS created_at
+		FROM saved_searches s
+		WHERE s.user_id = $1
+		ORDER BY s.created_at DESC, s.id ASC`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return mapper.SearchesByEntities(rawSearches), nil
+}
+
+// SearchesMatchingPost returns saved searches of other users whose price range
+// includes the price of the post.
+func (r *repository) SearchesMatchingPost(ctx context.Context, post *models.PostWithDocument) ([]*models.SavedSearch, error) {
+	op := pkg + "SearchesMatchingPost"
+
+	rawSearches := make([]*entities.SavedSearch, 0)
+
+	err := r.db.SelectContext(ctx, &rawSearches,
+		`SELECT
+			s.id AS id,
+			s.user_id AS user_id,
+			s.min_price AS min_price,
+			s.max_price AS max_price,
+			s.sort_by AS sort_by,
+			s.sort_order AS sort_order,
+			s.created_at AS created_at
+		FROM saved_searches s
+		WHERE s.user_id <> $1
+		AND (s.min_price = 0 OR s.min_price <= $2)
+		AND (s.max_price = 0 OR s.max_price >= $2)`, post.OwnerID, post.Price)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return mapper.SearchesByEntities(rawSearches), nil
+}
+
+func (r *repository) UpdateSearch(ctx context.Context, search *models.SavedSearch) error {
+	op := pkg + "UpdateSearch"
+
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE saved_searches SET min_price = $1, max_price = $2, sort_by = $3, sort_order = $4 WHERE id = $5`,
+		search.Filter.MinPrice, search.Filter.MaxPrice, search.Filter.SortBy, search.Filter.SortOrder, search.ID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if affected == 0 {
+		return models.ErrSearchNotFound
+	}
+
+	return nil
+}
+
+func (r *repository) DeleteSearch(ctx context.Context, id string) error {
+	op := pkg + "DeleteSearch"
+
+	_, err := r.db.ExecContext(ctx,
+		`DELETE FROM saved_searches WHERE id = $1`,
+		id)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (r *repository) AddMatches(ctx context.Context, postID string, searchIDs []string, matchedAt time.Time) error {
+	op := pkg + "AddMatches"
+
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO saved_search_matches(search_id, post_id, matched_at)
+		SELECT unnest($1::uuid[]), $2, $3
+		ON CONFLICT DO NOTHING`,
+		pq.Array(searchIDs), postID, matchedAt)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (r *repository) UnseenMatches(ctx context.Context, searchID string, limit int) ([]*models.SearchMatch, error) {
+	op := pkg + "UnseenMatches"
+
+	rawMatches := make([]*entities.SearchMatch, 0)
+
+	err := r.db.SelectContext(ctx, &rawMatches,
+		`SELECT
+			m.search_id AS search_id,
+			m.matched_at AS matched_at,
+			p.id AS id,
+			p.owner_id AS owner_id,
+			u.login AS owner_login,
+			p.header AS header,
+			p.text AS text,
+			p.price AS price,
+			d.id AS document_id,
+			d.name AS document_name,
+			d.mime AS document_mime,
+			d.path AS document_path,
+			p.created_at AS created_at
+		FROM saved_search_matches m
+		INNER JOIN posts p ON p.id = m.post_id
+		INNER JOIN users u ON u.id = p.owner_id
+		INNER JOIN documents d ON d.post_id = p.id
+		WHERE m.search_id = $1 AND m.seen_at IS NULL
+		ORDER BY m.matched_at DESC, p.id ASC
+		LIMIT $2`, searchID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return mapper.MatchesByEntities(rawMatches), nil
+}
+
+// MarkMatchesSeen marks the given matches of the search as seen. If postIDs is
+// empty, every unseen match of the search is marked.
+func (r *repository) MarkMatchesSeen(ctx context.Context, searchID string, postIDs []string, seenAt time.Time) error {
+	op := pkg + "MarkMatchesSeen"
+
+	var err error
+
+	if len(postIDs) == 0 {
+		_, err = r.db.ExecContext(ctx,
+			`UPDATE saved_search_matches SET seen_at = $1 WHERE search_id = $2 AND seen_at IS NULL`,
+			seenAt, searchID)
+	} else {
+		_, err = r.db.ExecContext(ctx,
+			`UPDATE saved_search_matches SET seen_at = $1 WHERE search_id = $2 AND post_id = ANY($3::uuid[]) AND seen_at IS NULL`,
+			seenAt, searchID, pq.Array(postIDs))
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
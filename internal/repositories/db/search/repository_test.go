@@ -0,0 +1,220 @@
+package searchrepo
+
+import (
+	"context"
+	"errors"
+	"marketplace/internal/models"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddSearch_Success(t *testing.T) {
+	t.Parallel()
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	repo := New(sqlxDB)
+
+	search := &models.SavedSearch{
+		ID:        "s1",
+		UserID:    "1",
+		Filter:    models.PostsFilter{MinPrice: 100, MaxPrice: 200, SortBy: "price", SortOrder: "asc"},
+		CreatedAt: time.Now(),
+	}
+
+	mock.ExpectExec("INSERT INTO saved_searches").
+		WithArgs(search.ID, search.UserID, search.Filter.MinPrice, search.Filter.MaxPrice, search.Filter.SortBy, search.Filter.SortOrder, search.CreatedAt).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	err := repo.AddSearch(context.Background(), search)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSearchByID_Success(t *testing.T) {
+	t.Parallel()
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	repo := New(sqlxDB)
+
+	now := time.Now()
+
+	rows := sqlmock.NewRows([]string{"id", "user_id", "min_price", "max_price", "sort_by", "sort_order", "created_at"}).
+		AddRow("s1", "1", 100, 200, "price", "asc", now)
+
+	mock.ExpectQuery("SELECT (.+) FROM saved_searches s").
+		WithArgs("s1").
+		WillReturnRows(rows)
+
+	search, err := repo.SearchByID(context.Background(), "s1")
+	assert.NoError(t, err)
+	assert.Equal(t, &models.SavedSearch{
+		ID:        "s1",
+		UserID:    "1",
+		Filter:    models.PostsFilter{MinPrice: 100, MaxPrice: 200, SortBy: "price", SortOrder: "asc"},
+		CreatedAt: now,
+	}, search)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSearchByID_NotFound(t *testing.T) {
+	t.Parallel()
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	repo := New(sqlxDB)
+
+	mock.ExpectQuery("SELECT (.+) FROM saved_searches s").
+		WithArgs("s1").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	search, err := repo.SearchByID(context.Background(), "s1")
+	assert.ErrorIs(t, err, models.ErrSearchNotFound)
+	assert.Nil(t, search)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSearchesMatchingPost_Success(t *testing.T) {
+	t.Parallel()
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	repo := New(sqlxDB)
+
+	post := &models.PostWithDocument{ID: "p1", OwnerID: "2", Price: 150}
+
+	rows := sqlmock.NewRows([]string{"id", "user_id", "min_price", "max_price", "sort_by", "sort_order", "created_at"}).
+		AddRow("s1", "1", 100, 0, "", "", time.Now())
+
+	mock.ExpectQuery("SELECT (.+) FROM saved_searches s WHERE s.user_id <> (.+)").
+		WithArgs(post.OwnerID, post.Price).
+		WillReturnRows(rows)
+
+	searches, err := repo.SearchesMatchingPost(context.Background(), post)
+	assert.NoError(t, err)
+	assert.Len(t, searches, 1)
+	assert.Equal(t, uint(100), searches[0].Filter.MinPrice)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateSearch_NotFound(t *testing.T) {
+	t.Parallel()
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	repo := New(sqlxDB)
+
+	search := &models.SavedSearch{ID: "s1", Filter: models.PostsFilter{MaxPrice: 10}}
+
+	mock.ExpectExec("UPDATE saved_searches").
+		WithArgs(search.Filter.MinPrice, search.Filter.MaxPrice, search.Filter.SortBy, search.Filter.SortOrder, search.ID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err := repo.UpdateSearch(context.Background(), search)
+	assert.ErrorIs(t, err, models.ErrSearchNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAddMatches_Success(t *testing.T) {
+	t.Parallel()
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	repo := New(sqlxDB)
+
+	now := time.Now()
+
+	mock.ExpectExec("INSERT INTO saved_search_matches").
+		WithArgs(pq.Array([]string{"s1", "s2"}), "p1", now).
+		WillReturnResult(sqlmock.NewResult(2, 2))
+
+	err := repo.AddMatches(context.Background(), "p1", []string{"s1", "s2"}, now)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAddMatches_Fails(t *testing.T) {
+	t.Parallel()
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	repo := New(sqlxDB)
+
+	someErr := errors.New("some error")
+
+	mock.ExpectExec("INSERT INTO saved_search_matches").
+		WillReturnError(someErr)
+
+	err := repo.AddMatches(context.Background(), "p1", []string{"s1"}, time.Now())
+	assert.ErrorIs(t, err, someErr)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMarkMatchesSeen_All(t *testing.T) {
+	t.Parallel()
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	repo := New(sqlxDB)
+
+	now := time.Now()
+
+	mock.ExpectExec("UPDATE saved_search_matches SET seen_at").
+		WithArgs(now, "s1").
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	err := repo.MarkMatchesSeen(context.Background(), "s1", nil, now)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMarkMatchesSeen_Selected(t *testing.T) {
+	t.Parallel()
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	repo := New(sqlxDB)
+
+	now := time.Now()
+
+	mock.ExpectExec("UPDATE saved_search_matches SET seen_at").
+		WithArgs(now, "s1", pq.Array([]string{"p1"})).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := repo.MarkMatchesSeen(context.Background(), "s1", []string{"p1"}, now)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,183 @@
+package twofactorrepo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"marketplace/internal/entities"
+	"marketplace/internal/models"
+	"marketplace/internal/utils/mapper"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const pkg = "twoFactorRepo/"
+
+type repository struct {
+	db *sqlx.DB
+}
+
+func New(db *sqlx.DB) *repository {
+	return &repository{
+		db: db,
+	}
+}
+
+// SaveTOTP stores a new pending setup, replacing a pending one. An enabled
+// setup is never replaced.
+func (r *repository) SaveTOTP(ctx context.Context, totp *models.TOTP) error {
+	op := pkg + "SaveTOTP"
+
+	res, err := r.db.ExecContext(ctx,
+		`INSERT INTO user_totp(user_id, secret, created_at) VALUES($1, $2, $3)
+		ON CONFLICT (user_id) DO UPDATE SET secret = EXCLUDED.secret, created_at = EXCLUDED.created_at, last_step = 0
+		WHERE user_totp.enabled_at IS NULL`,
+		totp.UserID, totp.Secret, totp.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return expectOne(op, res, models.ErrTwoFactorEnabled)
+}
+
+func (r *repository) TOTPByUser(ctx context.Context, userID string) (*models.TOTP, error) {
+	op := pkg + "TOTPByUser"
+
+	var rawTOTP entities.TOTP
+
+	err := r.db.GetContext(ctx, &rawTOTP,
+		`SELECT user_id, secret, enabled_at, last_step, created_at FROM user_totp WHERE user_id = $1`, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, models.ErrTwoFactorNotEnrolled
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return mapper.TOTPByEntity(&rawTOTP), nil
+}
+
+// EnableTOTP turns on a pending setup and replaces the recovery codes of the
+// user. step is the time step of the code that confirmed the setup.
+func (r *repository) EnableTOTP(ctx context.Context, userID string, step int64, enabledAt time.Time, codeHashes []string) error {
+	op := pkg + "EnableTOTP"
+
+	tx, err := r.db.BeginTxx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	res, err := tx.ExecContext(ctx,
+		`UPDATE user_totp SET enabled_at = $2, last_step = $3 WHERE user_id = $1 AND enabled_at IS NULL`,
+		userID, enabledAt, step)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := expectOne(op, res, models.ErrTwoFactorEnabled); err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM user_recovery_codes WHERE user_id = $1`, userID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	for _, codeHash := range codeHashes {
+		_, err = tx.ExecContext(ctx,
+			`INSERT INTO user_recovery_codes(user_id, code_hash) VALUES($1, $2)`, userID, codeHash)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// DeleteTOTP turns two-factor authentication off and drops the recovery
+// codes.
+func (r *repository) DeleteTOTP(ctx context.Context, userID string) error {
+	op := pkg + "DeleteTOTP"
+
+	tx, err := r.db.BeginTxx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM user_recovery_codes WHERE user_id = $1`, userID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	res, err := tx.ExecContext(ctx, `DELETE FROM user_totp WHERE user_id = $1`, userID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := expectOne(op, res, models.ErrTwoFactorNotEnrolled); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// UseTOTPStep records that the code of the step was accepted. A step that is
+// not newer than the last accepted one is refused, so a code seen by someone
+// else cannot be replayed.
+func (r *repository) UseTOTPStep(ctx context.Context, userID string, step int64) error {
+	op := pkg + "UseTOTPStep"
+
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE user_totp SET last_step = $2 WHERE user_id = $1 AND enabled_at IS NOT NULL AND last_step < $2`,
+		userID, step)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return expectOne(op, res, models.ErrInvalidTwoFactorCode)
+}
+
+// UseRecoveryCode marks an unused recovery code as used.
+func (r *repository) UseRecoveryCode(ctx context.Context, userID string, codeHash string, usedAt time.Time) error {
+	op := pkg + "UseRecoveryCode"
+
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE user_recovery_codes SET used_at = $3 WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL`,
+		userID, codeHash, usedAt)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return expectOne(op, res, models.ErrInvalidTwoFactorCode)
+}
+
+func expectOne(op string, res sql.Result, notFound error) error {
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if affected == 0 {
+		return notFound
+	}
+
+	return nil
+}
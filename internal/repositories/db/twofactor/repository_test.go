@@ -0,0 +1,138 @@
+package twofactorrepo
+
+import (
+	"context"
+	"database/sql"
+	"marketplace/internal/models"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func newRepo(t *testing.T) (*repository, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	return New(sqlx.NewDb(db, "sqlmock")), mock
+}
+
+func TestSaveTOTP_EnabledNotReplaced(t *testing.T) {
+	t.Parallel()
+
+	repo, mock := newRepo(t)
+
+	now := time.Now()
+
+	mock.ExpectExec("INSERT INTO user_totp").
+		WithArgs("u1", "sealed", now).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err := repo.SaveTOTP(context.Background(), &models.TOTP{UserID: "u1", Secret: "sealed", CreatedAt: now})
+	assert.ErrorIs(t, err, models.ErrTwoFactorEnabled)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTOTPByUser(t *testing.T) {
+	t.Parallel()
+
+	repo, mock := newRepo(t)
+
+	now := time.Now()
+
+	mock.ExpectQuery("SELECT user_id, secret, enabled_at, last_step, created_at FROM user_totp").
+		WithArgs("u1").
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "secret", "enabled_at", "last_step", "created_at"}).
+			AddRow("u1", "sealed", now, int64(42), now))
+
+	totp, err := repo.TOTPByUser(context.Background(), "u1")
+	assert.NoError(t, err)
+	assert.True(t, totp.Enabled())
+	assert.Equal(t, int64(42), totp.LastStep)
+
+	mock.ExpectQuery("SELECT user_id, secret, enabled_at, last_step, created_at FROM user_totp").
+		WithArgs("u2").
+		WillReturnError(sql.ErrNoRows)
+
+	_, err = repo.TOTPByUser(context.Background(), "u2")
+	assert.ErrorIs(t, err, models.ErrTwoFactorNotEnrolled)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestEnableTOTP(t *testing.T) {
+	t.Parallel()
+
+	repo, mock := newRepo(t)
+
+	now := time.Now()
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE user_totp SET enabled_at").
+		WithArgs("u1", now, int64(7)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM user_recovery_codes").
+		WithArgs("u1").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO user_recovery_codes").
+		WithArgs("u1", "h1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO user_recovery_codes").
+		WithArgs("u1", "h2").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err := repo.EnableTOTP(context.Background(), "u1", 7, now, []string{"h1", "h2"})
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestEnableTOTP_AlreadyEnabled(t *testing.T) {
+	t.Parallel()
+
+	repo, mock := newRepo(t)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE user_totp SET enabled_at").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	err := repo.EnableTOTP(context.Background(), "u1", 7, time.Now(), []string{"h1"})
+	assert.ErrorIs(t, err, models.ErrTwoFactorEnabled)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUseTOTPStep_Replay(t *testing.T) {
+	t.Parallel()
+
+	repo, mock := newRepo(t)
+
+	mock.ExpectExec("UPDATE user_totp SET last_step").
+		WithArgs("u1", int64(7)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err := repo.UseTOTPStep(context.Background(), "u1", 7)
+	assert.ErrorIs(t, err, models.ErrInvalidTwoFactorCode)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUseRecoveryCode(t *testing.T) {
+	t.Parallel()
+
+	repo, mock := newRepo(t)
+
+	now := time.Now()
+
+	mock.ExpectExec("UPDATE user_recovery_codes SET used_at").
+		WithArgs("u1", "h1", now).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE user_recovery_codes SET used_at").
+		WithArgs("u1", "h1", now).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	assert.NoError(t, repo.UseRecoveryCode(context.Background(), "u1", "h1", now))
+	assert.ErrorIs(t, repo.UseRecoveryCode(context.Background(), "u1", "h1", now), models.ErrInvalidTwoFactorCode)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -28,7 +28,7 @@ func New(db *sqlx.DB) *repository {
 func (r *repository) AddUser(ctx context.Context, user *models.User) error {
 	op := pkg + "AddUser"
 
-	_, err := r.db.ExecContext(ctx, `INSERT INTO users(id, login, pass_hash) VALUES($1, $2, $3)`, user.ID, user.Login, user.PassHash)
+	_, err := r.db.ExecContext(ctx, `INSERT INTO users(id, login, pass_hash, email) VALUES($1, $2, $3, NULLIF($4, ''))`, user.ID, user.Login, user.PassHash, user.Email)
 	if err != nil {
 		if pgErr, ok := err.(*pq.Error); ok {
 			if pgErr.Code == "23505" {
@@ -53,7 +53,14 @@ func (r *repository) UserByID(ctx context.Context, id string) (*models.User, err
 		`SELECT
 			u.id AS id,
 			u.login AS login,
-			u.pass_hash AS pass_hash
+			u.pass_hash AS pass_hash,
+			u.email AS email,
+			u.email_verified_at AS email_verified_at,
+			u.role AS role,
+			u.banned_at AS banned_at,
+			u.banned_until AS banned_until,
+			u.ban_reason AS ban_reason,
+			u.banned_by AS banned_by
 		FROM users u
 		WHERE u.id = $1`, id)
 	if err != nil {
@@ -75,7 +82,14 @@ func (r *repository) UserByLogin(ctx context.Context, login string) (*models.Use
 		`SELECT
 			u.id AS id,
 			u.login AS login,
-			u.pass_hash AS pass_hash
+			u.pass_hash AS pass_hash,
+			u.email AS email,
+			u.email_verified_at AS email_verified_at,
+			u.role AS role,
+			u.banned_at AS banned_at,
+			u.banned_until AS banned_until,
+			u.ban_reason AS ban_reason,
+			u.banned_by AS banned_by
 		FROM users u
 		WHERE u.login = $1`, login)
 	if err != nil {
@@ -87,3 +101,206 @@ func (r *repository) UserByLogin(ctx context.Context, login string) (*models.Use
 
 	return mapper.UserByEntity(&rawUser), nil
 }
+
+func (r *repository) UpdatePassword(ctx context.Context, userID string, passHash []byte) error {
+	op := pkg + "UpdatePassword"
+
+	res, err := r.db.ExecContext(ctx, `UPDATE users SET pass_hash = $1 WHERE id = $2`, passHash, userID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if affected == 0 {
+		return models.ErrUserNotFound
+	}
+
+	return nil
+}
+
+// MarkEmailVerified records that the user confirmed their current email.
+// Confirming it again keeps the original time.
+func (r *repository) MarkEmailVerified(ctx context.Context, userID string) error {
+	op := pkg + "MarkEmailVerified"
+
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE users SET email_verified_at = COALESCE(email_verified_at, NOW())
+		WHERE id = $1 AND email IS NOT NULL`, userID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if affected == 0 {
+		return models.ErrUserNotFound
+	}
+
+	return nil
+}
+
+// SetRole changes the role of the user with the login.
+func (r *repository) SetRole(ctx context.Context, login string, role models.Role) error {
+	op := pkg + "SetRole"
+
+	res, err := r.db.ExecContext(ctx, `UPDATE users SET role = $1 WHERE login = $2`, string(role), login)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if affected == 0 {
+		return models.ErrUserNotFound
+	}
+
+	return nil
+}
+
+// DeleteUser removes the user together with their posts. Everything else the
+// user owns is removed by the foreign keys. Those would also drop orders of
+// the other side, so while the user has a pending or confirmed order
+// models.ErrActiveOrders is returned instead. The user and their posts are
+// locked first, which keeps new orders on them out until the delete is done.
+func (r *repository) DeleteUser(ctx context.Context, userID string) error {
+	op := pkg + "DeleteUser"
+
+	tx, err := r.db.BeginTxx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	var lockedID string
+
+	err = tx.GetContext(ctx, &lockedID, `SELECT id FROM users WHERE id = $1 FOR UPDATE`, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.ErrUserNotFound
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	_, err = tx.ExecContext(ctx, `SELECT id FROM posts WHERE owner_id = $1 FOR UPDATE`, userID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	var hasActiveOrders bool
+
+	err = tx.GetContext(ctx, &hasActiveOrders,
+		`SELECT EXISTS(SELECT 1 FROM orders WHERE (buyer_id = $1 OR seller_id = $1) AND status IN ($2, $3))`,
+		userID, models.OrderPending, models.OrderConfirmed)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if hasActiveOrders {
+		return models.ErrActiveOrders
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM posts WHERE owner_id = $1`, userID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	res, err := tx.ExecContext(ctx, `DELETE FROM users WHERE id = $1`, userID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if affected == 0 {
+		return models.ErrUserNotFound
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+const profileSelect = `SELECT
+			u.id AS id,
+			u.login AS login,
+			u.display_name AS display_name,
+			u.bio AS bio,
+			u.avatar_path AS avatar_path,
+			u.registered_at AS registered_at,
+			(SELECT COUNT(*) FROM posts p WHERE p.owner_id = u.id) AS post_count
+		FROM users u`
+
+func (r *repository) ProfileByLogin(ctx context.Context, login string) (*models.Profile, error) {
+	op := pkg + "ProfileByLogin"
+
+	rawProfile := entities.Profile{}
+
+	err := r.db.GetContext(ctx, &rawProfile, profileSelect+`
+		WHERE u.login = $1`, login)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, models.ErrUserNotFound
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return mapper.ProfileByEntity(&rawProfile), nil
+}
+
+func (r *repository) ProfileByID(ctx context.Context, id string) (*models.Profile, error) {
+	op := pkg + "ProfileByID"
+
+	rawProfile := entities.Profile{}
+
+	err := r.db.GetContext(ctx, &rawProfile, profileSelect+`
+		WHERE u.id = $1`, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, models.ErrUserNotFound
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return mapper.ProfileByEntity(&rawProfile), nil
+}
+
+func (r *repository) UpdateProfile(ctx context.Context, profile *models.Profile) error {
+	op := pkg + "UpdateProfile"
+
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE users SET display_name = $1, bio = $2, avatar_path = $3 WHERE id = $4`,
+		profile.DisplayName, profile.Bio, profile.AvatarPath, profile.UserID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if affected == 0 {
+		return models.ErrUserNotFound
+	}
+
+	return nil
+}
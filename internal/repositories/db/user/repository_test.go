@@ -6,6 +6,7 @@ import (
 	"errors"
 	"marketplace/internal/models"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/jmoiron/sqlx"
@@ -30,7 +31,7 @@ func TestAddUser_Success(t *testing.T) {
 	}
 
 	mock.ExpectExec("INSERT INTO users").
-		WithArgs(user.ID, user.Login, user.PassHash).
+		WithArgs(user.ID, user.Login, user.PassHash, user.Email).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
 	err := repo.AddUser(context.Background(), &user)
@@ -57,7 +58,7 @@ func TestAddUser_UniqueViolation(t *testing.T) {
 	pqErr := &pq.Error{Code: "23505", Constraint: "users_login_key"}
 
 	mock.ExpectExec("INSERT INTO users").
-		WithArgs(user.ID, user.Login, user.PassHash).
+		WithArgs(user.ID, user.Login, user.PassHash, user.Email).
 		WillReturnError(pqErr)
 
 	err := repo.AddUser(context.Background(), &user)
@@ -91,7 +92,7 @@ func TestAddUser_OtherErr(t *testing.T) {
 	someErr := errors.New("some error")
 
 	mock.ExpectExec("INSERT INTO users").
-		WithArgs(user.ID, user.Login, user.PassHash).
+		WithArgs(user.ID, user.Login, user.PassHash, user.Email).
 		WillReturnError(someErr)
 
 	err := repo.AddUser(context.Background(), &user)
@@ -263,3 +264,279 @@ func TestUserByLogin_OtherErr(t *testing.T) {
 	assert.ErrorIs(t, err, someErr)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+func TestProfileByLogin_Success(t *testing.T) {
+	t.Parallel()
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	repo := New(sqlxDB)
+
+	registeredAt := time.Now()
+
+	rows := sqlmock.NewRows([]string{"id", "login", "display_name", "bio", "avatar_path", "registered_at", "post_count"}).
+		AddRow("1", "test", "Test User", "hello", "/static/a.jpg", registeredAt, 3)
+
+	mock.ExpectQuery("SELECT(.|\n)*COUNT(.|\n)*FROM posts p WHERE p.owner_id = u.id(.|\n)*FROM users u WHERE u.login").
+		WithArgs("test").
+		WillReturnRows(rows)
+
+	profile, err := repo.ProfileByLogin(context.Background(), "test")
+
+	assert.NoError(t, err)
+	assert.Equal(t, &models.Profile{
+		UserID:       "1",
+		Login:        "test",
+		DisplayName:  "Test User",
+		Bio:          "hello",
+		AvatarPath:   "/static/a.jpg",
+		RegisteredAt: registeredAt,
+		PostCount:    3,
+	}, profile)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestProfileByID_NotFound(t *testing.T) {
+	t.Parallel()
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	repo := New(sqlxDB)
+
+	mock.ExpectQuery("SELECT(.|\n)*FROM users u WHERE u.id").
+		WithArgs("1").
+		WillReturnError(sql.ErrNoRows)
+
+	_, err := repo.ProfileByID(context.Background(), "1")
+
+	assert.ErrorIs(t, err, models.ErrUserNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateProfile_Success(t *testing.T) {
+	t.Parallel()
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	repo := New(sqlxDB)
+
+	mock.ExpectExec("UPDATE users SET display_name").
+		WithArgs("Test User", "hello", "/static/a.jpg", "1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := repo.UpdateProfile(context.Background(), &models.Profile{UserID: "1", DisplayName: "Test User", Bio: "hello", AvatarPath: "/static/a.jpg"})
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdatePassword_NotFound(t *testing.T) {
+	t.Parallel()
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	repo := New(sqlxDB)
+
+	mock.ExpectExec("UPDATE users SET pass_hash").
+		WithArgs([]byte("hashed"), "1").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err := repo.UpdatePassword(context.Background(), "1", []byte("hashed"))
+
+	assert.ErrorIs(t, err, models.ErrUserNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteUser(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		found        bool
+		activeOrders bool
+		affected     int64
+		wantErr      error
+	}{
+		{name: "success", found: true, affected: 1},
+		{name: "not found", wantErr: models.ErrUserNotFound},
+		{name: "active orders", found: true, activeOrders: true, wantErr: models.ErrActiveOrders},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			db, mock, _ := sqlmock.New()
+			defer db.Close()
+
+			repo := New(sqlx.NewDb(db, "sqlmock"))
+
+			mock.ExpectBegin()
+
+			lockRows := sqlmock.NewRows([]string{"id"})
+			if tt.found {
+				lockRows.AddRow("1")
+			}
+			mock.ExpectQuery("SELECT id FROM users WHERE id = \\$1 FOR UPDATE").
+				WithArgs("1").
+				WillReturnRows(lockRows)
+
+			if tt.found {
+				mock.ExpectExec("SELECT id FROM posts WHERE owner_id = \\$1 FOR UPDATE").
+					WithArgs("1").
+					WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectQuery("SELECT EXISTS").
+					WithArgs("1", models.OrderPending, models.OrderConfirmed).
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(tt.activeOrders))
+			}
+
+			if tt.found && !tt.activeOrders {
+				mock.ExpectExec("DELETE FROM posts WHERE owner_id").
+					WithArgs("1").
+					WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectExec("DELETE FROM users WHERE id").
+					WithArgs("1").
+					WillReturnResult(sqlmock.NewResult(0, tt.affected))
+			}
+
+			if tt.wantErr == nil {
+				mock.ExpectCommit()
+			} else {
+				mock.ExpectRollback()
+			}
+
+			err := repo.DeleteUser(context.Background(), "1")
+
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestUserByLogin_WithVerifiedEmail(t *testing.T) {
+	t.Parallel()
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	repo := New(sqlxDB)
+
+	verifiedAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	rows := sqlmock.NewRows([]string{"id", "login", "pass_hash", "email", "email_verified_at", "role"}).
+		AddRow("1", "test", []byte("hashed"), "user@example.com", verifiedAt, "moderator")
+
+	mock.ExpectQuery("SELECT").
+		WithArgs("test").
+		WillReturnRows(rows)
+
+	user, err := repo.UserByLogin(context.Background(), "test")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "user@example.com", user.Email)
+	assert.Equal(t, verifiedAt, *user.EmailVerifiedAt)
+	assert.True(t, user.EmailVerified())
+	assert.Equal(t, models.RoleModerator, user.Role)
+	assert.Nil(t, user.Ban)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserByID_Suspended(t *testing.T) {
+	t.Parallel()
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	repo := New(sqlxDB)
+
+	bannedAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	bannedUntil := bannedAt.Add(24 * time.Hour)
+
+	rows := sqlmock.NewRows([]string{"id", "login", "pass_hash", "role", "banned_at", "banned_until", "ban_reason", "banned_by"}).
+		AddRow("1", "test", []byte("hashed"), "user", bannedAt, bannedUntil, "spam", "2")
+
+	mock.ExpectQuery("SELECT").
+		WithArgs("1").
+		WillReturnRows(rows)
+
+	user, err := repo.UserByID(context.Background(), "1")
+
+	assert.NoError(t, err)
+	assert.Equal(t, &models.Ban{Reason: "spam", Until: &bannedUntil, BannedBy: "2", BannedAt: bannedAt}, user.Ban)
+	assert.True(t, user.Banned(bannedAt))
+	assert.False(t, user.Banned(bannedUntil))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSetRole(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		affected int64
+		wantErr  error
+	}{
+		{name: "success", affected: 1},
+		{name: "not found", affected: 0, wantErr: models.ErrUserNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			db, mock, _ := sqlmock.New()
+			defer db.Close()
+
+			repo := New(sqlx.NewDb(db, "sqlmock"))
+
+			mock.ExpectExec("UPDATE users SET role").
+				WithArgs("admin", "test").
+				WillReturnResult(sqlmock.NewResult(0, tt.affected))
+
+			err := repo.SetRole(context.Background(), "test", models.RoleAdmin)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestMarkEmailVerified_NotFound(t *testing.T) {
+	t.Parallel()
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+
+	repo := New(sqlxDB)
+
+	mock.ExpectExec("UPDATE users SET email_verified_at").
+		WithArgs("1").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err := repo.MarkEmailVerified(context.Background(), "1")
+
+	assert.ErrorIs(t, err, models.ErrUserNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
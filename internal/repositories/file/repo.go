@@ -37,6 +37,7 @@ func (r *repository) SaveFile(doc *models.Document, reader io.Reader) (string, e
 
 	_, err = io.Copy(out, reader)
 	if err != nil {
+		_ = os.Remove(fullPath)
 		return "", fmt.Errorf("%s: %w", op, err)
 	}
 
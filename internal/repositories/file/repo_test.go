@@ -115,4 +115,7 @@ func TestSaveFile_CopyFails(t *testing.T) {
 
 	assert.Error(t, err)
 	assert.Empty(t, path)
+
+	_, err = os.Stat(filepath.Join(tmpDir, "fail.txt"))
+	assert.True(t, os.IsNotExist(err))
 }
@@ -0,0 +1,52 @@
+package accountservice
+
+import (
+	"context"
+	"io"
+	"marketplace/internal/models"
+)
+
+type UserProvider interface {
+	UserByID(ctx context.Context, id string) (*models.User, error)
+}
+
+type ProfileProvider interface {
+	ProfileByID(ctx context.Context, id string) (*models.Profile, error)
+}
+
+type PostProvider interface {
+	PostsByOwner(ctx context.Context, ownerID string) ([]*models.PostWithDocument, error)
+}
+
+type UserRemover interface {
+	DeleteUser(ctx context.Context, userID string) error
+}
+
+type FileStorage interface {
+	SaveFile(doc *models.Document, reader io.Reader) (string, error)
+	LoadFile(doc *models.Document) (io.ReadCloser, error)
+	DeleteFile(doc *models.Document) error
+}
+
+type JobStorer interface {
+	SaveJob(ctx context.Context, job *models.Job) error
+	Job(ctx context.Context, id string) (*models.Job, error)
+	LatestExport(ctx context.Context, userID string) (*models.Job, error)
+	ActiveJobs(ctx context.Context) ([]*models.Job, error)
+}
+
+type SessionStorer interface {
+	InvalidateUserSessions(ctx context.Context, userID string) error
+}
+
+// OrderCanceller cancels the orders a user takes part in, refunding and
+// telling the other side.
+type OrderCanceller interface {
+	CancelUserOrders(ctx context.Context, userID string) error
+}
+
+// PostCache drops the cached post listings, which would still show the posts
+// of a deleted user.
+type PostCache interface {
+	InvalidateLists(ctx context.Context) error
+}
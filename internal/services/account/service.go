@@ -0,0 +1,216 @@
+package accountservice
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"marketplace/internal/models"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+)
+
+const pkg = "accountService/"
+
+// AccountService exports and deletes the personal data of users. Both run as
+// background jobs, since an export of a user with many posts can be large.
+type AccountService struct {
+	log             *slog.Logger
+	userProvider    UserProvider
+	profileProvider ProfileProvider
+	postProvider    PostProvider
+	userRemover     UserRemover
+	fileStorage     FileStorage
+	exportStorage   FileStorage
+	jobs            JobStorer
+	sessions        SessionStorer
+	orders          OrderCanceller
+	postCache       PostCache
+	queue           chan *models.Job
+	exportCooldown  time.Duration
+}
+
+func New(
+	log *slog.Logger,
+	userProvider UserProvider,
+	profileProvider ProfileProvider,
+	postProvider PostProvider,
+	userRemover UserRemover,
+	fileStorage FileStorage,
+	exportStorage FileStorage,
+	jobs JobStorer,
+	sessions SessionStorer,
+	orders OrderCanceller,
+	postCache PostCache,
+	queueSize int,
+	exportCooldown time.Duration,
+) *AccountService {
+	return &AccountService{
+		log:             log,
+		userProvider:    userProvider,
+		profileProvider: profileProvider,
+		postProvider:    postProvider,
+		userRemover:     userRemover,
+		fileStorage:     fileStorage,
+		exportStorage:   exportStorage,
+		jobs:            jobs,
+		sessions:        sessions,
+		orders:          orders,
+		postCache:       postCache,
+		queue:           make(chan *models.Job, queueSize),
+		exportCooldown:  exportCooldown,
+	}
+}
+
+// Export returns the archive of the requester's latest export once it is
+// ready. Until then it returns the job building it, starting one when there
+// is no export yet or the last one failed.
+//
+// With refresh a new export replaces a finished one, so that it has the data
+// as it is now. That is allowed once per export cooldown, counted from the
+// start of the previous export.
+func (s *AccountService) Export(ctx context.Context, requester *models.User, refresh bool) (*models.Job, io.ReadCloser, error) {
+	op := pkg + "Export"
+
+	log := s.log.With(slog.String("op", op), slog.String("user_id", requester.ID))
+
+	log.Debug("attempting to get export")
+
+	latest, err := s.jobs.LatestExport(ctx, requester.ID)
+	if err != nil && !errors.Is(err, models.ErrJobNotFound) {
+		log.Error("failed to get latest export", slog.String("error", err.Error()))
+		return nil, nil, models.ErrInternal
+	}
+
+	if latest != nil && latest.Active() {
+		log.Debug("export is in progress", slog.String("job_id", latest.ID))
+		return latest, nil, nil
+	}
+
+	if refresh && latest != nil && latest.Status == models.JobDone {
+		if time.Since(latest.CreatedAt) < s.exportCooldown {
+			log.Warn("export refreshed too soon", slog.String("job_id", latest.ID))
+			return nil, nil, models.ErrTooManyRequests
+		}
+
+		job, err := s.enqueue(ctx, log, requester.ID, models.JobExport)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		// The new job is the latest export now, so the old archive cannot be
+		// reached any more.
+		deleteFile(log, s.exportStorage, &models.Document{Path: latest.Path})
+
+		log.Debug("export refresh started successfully", slog.String("job_id", job.ID))
+
+		return job, nil, nil
+	}
+
+	if latest != nil && latest.Status == models.JobDone {
+		archive, err := s.exportStorage.LoadFile(&models.Document{Path: latest.Path})
+		if err == nil {
+			log.Debug("export found successfully", slog.String("job_id", latest.ID))
+			return latest, archive, nil
+		}
+		log.Warn("failed to load export, starting a new one", slog.String("error", err.Error()))
+	}
+
+	job, err := s.enqueue(ctx, log, requester.ID, models.JobExport)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	log.Debug("export started successfully", slog.String("job_id", job.ID))
+
+	return job, nil, nil
+}
+
+// DeleteAccount starts removing the requester's account. The requester is
+// logged out everywhere at once; the job then cancels their open orders and
+// deletes their posts, files and the account itself.
+func (s *AccountService) DeleteAccount(ctx context.Context, requester *models.User) (*models.Job, error) {
+	op := pkg + "DeleteAccount"
+
+	log := s.log.With(slog.String("op", op), slog.String("user_id", requester.ID))
+
+	log.Debug("attempting to delete account")
+
+	job, err := s.enqueue(ctx, log, requester.ID, models.JobDeletion)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.sessions.InvalidateUserSessions(ctx, requester.ID); err != nil {
+		log.Warn("failed to revoke sessions, the job will retry", slog.String("error", err.Error()))
+	}
+
+	log.Info("account deletion started", slog.String("job_id", job.ID))
+
+	return job, nil
+}
+
+// Job returns the status of a job of the requester. Jobs of other users are
+// reported as not found.
+func (s *AccountService) Job(ctx context.Context, requester *models.User, id string) (*models.Job, error) {
+	op := pkg + "Job"
+
+	log := s.log.With(slog.String("op", op))
+
+	log.Debug("attempting to get job")
+
+	if _, err := uuid.FromString(id); err != nil {
+		log.Warn("invalid job id received", slog.String("job_id", id))
+		return nil, models.ErrJobNotFound
+	}
+
+	job, err := s.jobs.Job(ctx, id)
+	if err != nil {
+		if errors.Is(err, models.ErrJobNotFound) {
+			log.Warn("job not found", slog.String("job_id", id))
+			return nil, models.ErrJobNotFound
+		}
+		log.Error("failed to get job", slog.String("error", err.Error()))
+		return nil, models.ErrInternal
+	}
+
+	if job.UserID != requester.ID {
+		log.Warn("job of another user requested", slog.String("job_id", id))
+		return nil, models.ErrJobNotFound
+	}
+
+	return job, nil
+}
+
+// enqueue saves a new job and hands it to the worker. It never blocks: when
+// the queue is full the job is marked failed and models.ErrJobQueueFull is
+// returned.
+func (s *AccountService) enqueue(ctx context.Context, log *slog.Logger, userID string, kind models.JobKind) (*models.Job, error) {
+	job := &models.Job{
+		ID:        uuid.NewV4().String(),
+		UserID:    userID,
+		Kind:      kind,
+		Status:    models.JobPending,
+		CreatedAt: time.Now(),
+	}
+
+	if err := s.jobs.SaveJob(ctx, job); err != nil {
+		log.Error("failed to save job", slog.String("error", err.Error()))
+		return nil, models.ErrInternal
+	}
+
+	// The worker gets its own copy, so the job returned here is not changed
+	// while the caller writes it out.
+	queued := *job
+
+	select {
+	case s.queue <- &queued:
+	default:
+		log.Warn("job queue is full", slog.String("job_id", job.ID))
+		s.finish(ctx, log, job, models.ErrJobQueueFull)
+		return nil, models.ErrJobQueueFull
+	}
+
+	return job, nil
+}
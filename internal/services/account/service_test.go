@@ -0,0 +1,558 @@
+package accountservice
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"marketplace/internal/dto"
+	"marketplace/internal/models"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockUserProvider struct {
+	mock.Mock
+}
+
+func (m *mockUserProvider) UserByID(ctx context.Context, id string) (*models.User, error) {
+	args := m.Called(ctx, id)
+	user, _ := args.Get(0).(*models.User)
+	return user, args.Error(1)
+}
+
+type mockProfileProvider struct {
+	mock.Mock
+}
+
+func (m *mockProfileProvider) ProfileByID(ctx context.Context, id string) (*models.Profile, error) {
+	args := m.Called(ctx, id)
+	profile, _ := args.Get(0).(*models.Profile)
+	return profile, args.Error(1)
+}
+
+type mockPostProvider struct {
+	mock.Mock
+}
+
+func (m *mockPostProvider) PostsByOwner(ctx context.Context, ownerID string) ([]*models.PostWithDocument, error) {
+	args := m.Called(ctx, ownerID)
+	posts, _ := args.Get(0).([]*models.PostWithDocument)
+	return posts, args.Error(1)
+}
+
+type mockUserRemover struct {
+	mock.Mock
+}
+
+func (m *mockUserRemover) DeleteUser(ctx context.Context, userID string) error {
+	return m.Called(ctx, userID).Error(0)
+}
+
+type mockSessionStorer struct {
+	mock.Mock
+}
+
+func (m *mockSessionStorer) InvalidateUserSessions(ctx context.Context, userID string) error {
+	return m.Called(ctx, userID).Error(0)
+}
+
+type mockOrderCanceller struct {
+	mock.Mock
+}
+
+func (m *mockOrderCanceller) CancelUserOrders(ctx context.Context, userID string) error {
+	return m.Called(ctx, userID).Error(0)
+}
+
+type mockPostCache struct {
+	mock.Mock
+}
+
+func (m *mockPostCache) InvalidateLists(ctx context.Context) error {
+	return m.Called(ctx).Error(0)
+}
+
+// memStorage keeps files in memory by path.
+type memStorage struct {
+	mu    sync.Mutex
+	files map[string][]byte
+}
+
+func newMemStorage(files map[string][]byte) *memStorage {
+	if files == nil {
+		files = make(map[string][]byte)
+	}
+	return &memStorage{files: files}
+}
+
+func (s *memStorage) SaveFile(doc *models.Document, reader io.Reader) (string, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return "", err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	doc.Path = "exports/" + doc.ID + ".zip"
+	s.files[doc.Path] = data
+
+	return doc.Path, nil
+}
+
+func (s *memStorage) LoadFile(doc *models.Document) (io.ReadCloser, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, ok := s.files[doc.Path]
+	if !ok {
+		return nil, models.ErrDocumentNotFound
+	}
+
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (s *memStorage) DeleteFile(doc *models.Document) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.files[doc.Path]; !ok {
+		return models.ErrDocumentNotFound
+	}
+
+	delete(s.files, doc.Path)
+
+	return nil
+}
+
+// memJobs keeps jobs in memory like the cache repository does.
+type memJobs struct {
+	mu         sync.Mutex
+	jobs       map[string]models.Job
+	lastExport map[string]string
+}
+
+func newMemJobs(jobs ...*models.Job) *memJobs {
+	m := &memJobs{jobs: make(map[string]models.Job), lastExport: make(map[string]string)}
+	for _, job := range jobs {
+		_ = m.SaveJob(context.Background(), job)
+	}
+	return m
+}
+
+func (m *memJobs) SaveJob(_ context.Context, job *models.Job) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.jobs[job.ID] = *job
+	if job.Kind == models.JobExport {
+		m.lastExport[job.UserID] = job.ID
+	}
+
+	return nil
+}
+
+func (m *memJobs) Job(_ context.Context, id string) (*models.Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	job, ok := m.jobs[id]
+	if !ok {
+		return nil, models.ErrJobNotFound
+	}
+
+	return &job, nil
+}
+
+func (m *memJobs) LatestExport(ctx context.Context, userID string) (*models.Job, error) {
+	m.mu.Lock()
+	id, ok := m.lastExport[userID]
+	m.mu.Unlock()
+
+	if !ok {
+		return nil, models.ErrJobNotFound
+	}
+
+	return m.Job(ctx, id)
+}
+
+func (m *memJobs) ActiveJobs(_ context.Context) ([]*models.Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	jobs := make([]*models.Job, 0)
+	for _, job := range m.jobs {
+		if job.Active() {
+			jobs = append(jobs, &job)
+		}
+	}
+
+	return jobs, nil
+}
+
+var requester = &models.User{ID: "u1", Login: "alice", Email: "alice@example.com"}
+
+type deps struct {
+	users    *mockUserProvider
+	profiles *mockProfileProvider
+	posts    *mockPostProvider
+	remover  *mockUserRemover
+	files    *memStorage
+	exports  *memStorage
+	jobs     *memJobs
+	sessions *mockSessionStorer
+	orders   *mockOrderCanceller
+	cache    *mockPostCache
+}
+
+func newDeps() *deps {
+	return &deps{
+		users:    new(mockUserProvider),
+		profiles: new(mockProfileProvider),
+		posts:    new(mockPostProvider),
+		remover:  new(mockUserRemover),
+		files:    newMemStorage(nil),
+		exports:  newMemStorage(nil),
+		jobs:     newMemJobs(),
+		sessions: new(mockSessionStorer),
+		orders:   new(mockOrderCanceller),
+		cache:    new(mockPostCache),
+	}
+}
+
+func newService(d *deps, queueSize int) *AccountService {
+	return New(slog.New(slog.NewTextHandler(io.Discard, nil)), d.users, d.profiles, d.posts, d.remover, d.files, d.exports, d.jobs, d.sessions, d.orders, d.cache, queueSize, time.Hour)
+}
+
+func TestExport_BuildsArchive(t *testing.T) {
+	t.Parallel()
+
+	d := newDeps()
+	d.files = newMemStorage(map[string][]byte{
+		"images/p1.jpg":     []byte("image-1"),
+		"images/avatar.jpg": []byte("avatar"),
+	})
+
+	d.users.On("UserByID", mock.Anything, "u1").Return(requester, nil)
+	d.profiles.On("ProfileByID", mock.Anything, "u1").Return(&models.Profile{UserID: "u1", Login: "alice", Bio: "hi", AvatarPath: "images/avatar.jpg"}, nil)
+	d.posts.On("PostsByOwner", mock.Anything, "u1").Return([]*models.PostWithDocument{
+		{ID: "p1", OwnerID: "u1", Header: "bike", Price: 100, Document: &models.Document{Name: "bike.jpg", Path: "images/p1.jpg"}},
+		{ID: "p2", OwnerID: "u1", Header: "lost", Price: 50, Document: &models.Document{Name: "lost.png", Path: "images/p2.png"}},
+	}, nil)
+
+	service := newService(d, 1)
+
+	job, archive, err := service.Export(context.Background(), requester, false)
+	assert.NoError(t, err)
+	assert.Nil(t, archive)
+	assert.Equal(t, models.JobPending, job.Status)
+
+	service.process(context.Background(), <-service.queue)
+
+	done, archive, err := service.Export(context.Background(), requester, false)
+	assert.NoError(t, err)
+	assert.NotNil(t, archive)
+	assert.Equal(t, job.ID, done.ID)
+	assert.Equal(t, models.JobDone, done.Status)
+
+	data, err := io.ReadAll(archive)
+	assert.NoError(t, err)
+
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	assert.NoError(t, err)
+
+	entries := make(map[string][]byte)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		assert.NoError(t, err)
+		entries[f.Name], _ = io.ReadAll(rc)
+		_ = rc.Close()
+	}
+
+	var profile dto.ExportProfile
+	assert.NoError(t, json.Unmarshal(entries["profile.json"], &profile))
+	assert.Equal(t, "alice@example.com", profile.Email)
+	assert.Equal(t, "avatar.jpg", profile.Avatar)
+
+	var posts []*dto.ExportPost
+	assert.NoError(t, json.Unmarshal(entries["posts.json"], &posts))
+	assert.Len(t, posts, 2)
+	assert.Equal(t, "images/p1.jpg", posts[0].Image)
+
+	assert.Equal(t, []byte("image-1"), entries["images/p1.jpg"])
+	assert.Equal(t, []byte("avatar"), entries["avatar.jpg"])
+	assert.NotContains(t, entries, "images/p2.png")
+}
+
+func TestExport_InProgress(t *testing.T) {
+	t.Parallel()
+
+	d := newDeps()
+	d.jobs = newMemJobs(&models.Job{ID: "job-1", UserID: "u1", Kind: models.JobExport, Status: models.JobRunning})
+
+	service := newService(d, 1)
+
+	job, archive, err := service.Export(context.Background(), requester, false)
+
+	assert.NoError(t, err)
+	assert.Nil(t, archive)
+	assert.Equal(t, "job-1", job.ID)
+	assert.Empty(t, service.queue)
+}
+
+func TestExport_RetriesFailed(t *testing.T) {
+	t.Parallel()
+
+	d := newDeps()
+	d.jobs = newMemJobs(&models.Job{ID: "job-1", UserID: "u1", Kind: models.JobExport, Status: models.JobFailed})
+
+	service := newService(d, 1)
+
+	job, _, err := service.Export(context.Background(), requester, false)
+
+	assert.NoError(t, err)
+	assert.NotEqual(t, "job-1", job.ID)
+	assert.Len(t, service.queue, 1)
+}
+
+func TestExport_Refresh(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		age        time.Duration
+		wantErr    error
+		wantQueued bool
+	}{
+		{name: "after cooldown", age: 2 * time.Hour, wantQueued: true},
+		{name: "too soon", age: time.Minute, wantErr: models.ErrTooManyRequests},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			d := newDeps()
+			d.exports = newMemStorage(map[string][]byte{"exports/job-1.zip": []byte("zip")})
+			d.jobs = newMemJobs(&models.Job{ID: "job-1", UserID: "u1", Kind: models.JobExport, Status: models.JobDone, Path: "exports/job-1.zip", CreatedAt: time.Now().Add(-tt.age)})
+
+			service := newService(d, 1)
+
+			job, archive, err := service.Export(context.Background(), requester, true)
+
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Nil(t, archive)
+			if !tt.wantQueued {
+				assert.Empty(t, service.queue)
+				assert.Contains(t, d.exports.files, "exports/job-1.zip")
+				return
+			}
+
+			assert.NotEqual(t, "job-1", job.ID)
+			assert.Len(t, service.queue, 1)
+			assert.Empty(t, d.exports.files)
+
+			latest, err := d.jobs.LatestExport(context.Background(), "u1")
+			assert.NoError(t, err)
+			assert.Equal(t, job.ID, latest.ID)
+		})
+	}
+}
+
+func TestExport_QueueFull(t *testing.T) {
+	t.Parallel()
+
+	d := newDeps()
+
+	_, _, err := newService(d, 0).Export(context.Background(), requester, false)
+
+	assert.ErrorIs(t, err, models.ErrJobQueueFull)
+
+	job, err := d.jobs.LatestExport(context.Background(), "u1")
+	assert.NoError(t, err)
+	assert.Equal(t, models.JobFailed, job.Status)
+}
+
+func TestDeleteAccount(t *testing.T) {
+	t.Parallel()
+
+	d := newDeps()
+	d.files = newMemStorage(map[string][]byte{
+		"images/p1.jpg":     []byte("image-1"),
+		"images/avatar.jpg": []byte("avatar"),
+		"images/other.jpg":  []byte("other"),
+	})
+	d.exports = newMemStorage(map[string][]byte{"exports/job-1.zip": []byte("zip")})
+	d.jobs = newMemJobs(&models.Job{ID: "job-1", UserID: "u1", Kind: models.JobExport, Status: models.JobDone, Path: "exports/job-1.zip"})
+
+	d.sessions.On("InvalidateUserSessions", mock.Anything, "u1").Return(nil).Twice()
+	d.profiles.On("ProfileByID", mock.Anything, "u1").Return(&models.Profile{UserID: "u1", AvatarPath: "images/avatar.jpg"}, nil)
+	d.posts.On("PostsByOwner", mock.Anything, "u1").Return([]*models.PostWithDocument{
+		{ID: "p1", OwnerID: "u1", Document: &models.Document{Path: "images/p1.jpg"}},
+	}, nil)
+	d.orders.On("CancelUserOrders", mock.Anything, "u1").Return(nil)
+	d.remover.On("DeleteUser", mock.Anything, "u1").Return(nil)
+	d.cache.On("InvalidateLists", mock.Anything).Return(nil)
+
+	service := newService(d, 1)
+
+	job, err := service.DeleteAccount(context.Background(), requester)
+	assert.NoError(t, err)
+	assert.Equal(t, models.JobDeletion, job.Kind)
+
+	service.process(context.Background(), <-service.queue)
+
+	status, err := service.Job(context.Background(), requester, job.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, models.JobDone, status.Status)
+
+	assert.Equal(t, map[string][]byte{"images/other.jpg": []byte("other")}, d.files.files)
+	assert.Empty(t, d.exports.files)
+	d.sessions.AssertExpectations(t)
+	d.orders.AssertExpectations(t)
+	d.remover.AssertExpectations(t)
+	d.cache.AssertExpectations(t)
+}
+
+func TestDeleteAccount_OrderedMeanwhile(t *testing.T) {
+	t.Parallel()
+
+	d := newDeps()
+
+	d.sessions.On("InvalidateUserSessions", mock.Anything, "u1").Return(nil)
+	d.profiles.On("ProfileByID", mock.Anything, "u1").Return(&models.Profile{UserID: "u1"}, nil)
+	d.posts.On("PostsByOwner", mock.Anything, "u1").Return([]*models.PostWithDocument{}, nil)
+	d.orders.On("CancelUserOrders", mock.Anything, "u1").Return(nil).Twice()
+	// A buyer ordered a post after the first cancel, so the first delete is
+	// refused and the orders are cancelled again.
+	d.remover.On("DeleteUser", mock.Anything, "u1").Return(models.ErrActiveOrders).Once()
+	d.remover.On("DeleteUser", mock.Anything, "u1").Return(nil).Once()
+	d.cache.On("InvalidateLists", mock.Anything).Return(nil)
+
+	service := newService(d, 1)
+
+	job, err := service.DeleteAccount(context.Background(), requester)
+	assert.NoError(t, err)
+
+	service.process(context.Background(), <-service.queue)
+
+	status, err := service.Job(context.Background(), requester, job.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, models.JobDone, status.Status)
+	d.orders.AssertExpectations(t)
+	d.remover.AssertExpectations(t)
+}
+
+func TestDeleteAccount_ActiveOrdersLeft(t *testing.T) {
+	t.Parallel()
+
+	d := newDeps()
+
+	d.sessions.On("InvalidateUserSessions", mock.Anything, "u1").Return(nil)
+	d.profiles.On("ProfileByID", mock.Anything, "u1").Return(&models.Profile{UserID: "u1"}, nil)
+	d.posts.On("PostsByOwner", mock.Anything, "u1").Return([]*models.PostWithDocument{}, nil)
+	d.orders.On("CancelUserOrders", mock.Anything, "u1").Return(nil)
+	d.remover.On("DeleteUser", mock.Anything, "u1").Return(models.ErrActiveOrders)
+
+	service := newService(d, 1)
+
+	job, err := service.DeleteAccount(context.Background(), requester)
+	assert.NoError(t, err)
+
+	service.process(context.Background(), <-service.queue)
+
+	status, err := service.Job(context.Background(), requester, job.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, models.JobFailed, status.Status)
+	d.remover.AssertNumberOfCalls(t, "DeleteUser", deleteAttempts)
+	d.cache.AssertNotCalled(t, "InvalidateLists", mock.Anything)
+}
+
+func TestResume(t *testing.T) {
+	t.Parallel()
+
+	d := newDeps()
+	d.jobs = newMemJobs(
+		&models.Job{ID: "job-1", UserID: "u1", Kind: models.JobDeletion, Status: models.JobRunning},
+		&models.Job{ID: "job-2", UserID: "u2", Kind: models.JobExport, Status: models.JobDone},
+	)
+
+	d.sessions.On("InvalidateUserSessions", mock.Anything, "u1").Return(nil)
+	d.profiles.On("ProfileByID", mock.Anything, "u1").Return(&models.Profile{UserID: "u1"}, nil)
+	d.posts.On("PostsByOwner", mock.Anything, "u1").Return([]*models.PostWithDocument{}, nil)
+	d.orders.On("CancelUserOrders", mock.Anything, "u1").Return(nil)
+	d.remover.On("DeleteUser", mock.Anything, "u1").Return(nil)
+	d.cache.On("InvalidateLists", mock.Anything).Return(nil)
+
+	service := newService(d, 1)
+
+	service.Resume(context.Background())
+
+	if !assert.Len(t, service.queue, 1) {
+		return
+	}
+
+	service.process(context.Background(), <-service.queue)
+
+	status, err := d.jobs.Job(context.Background(), "job-1")
+	assert.NoError(t, err)
+	assert.Equal(t, models.JobDone, status.Status)
+	d.remover.AssertExpectations(t)
+}
+
+func TestResume_QueueFull(t *testing.T) {
+	t.Parallel()
+
+	d := newDeps()
+	d.jobs = newMemJobs(&models.Job{ID: "job-1", UserID: "u1", Kind: models.JobExport, Status: models.JobPending})
+
+	service := newService(d, 0)
+
+	service.Resume(context.Background())
+
+	status, err := d.jobs.Job(context.Background(), "job-1")
+	assert.NoError(t, err)
+	assert.Equal(t, models.JobFailed, status.Status)
+	assert.Equal(t, models.ErrJobQueueFull.Error(), status.Error)
+}
+
+func TestJob(t *testing.T) {
+	t.Parallel()
+
+	const id = "6ba7b810-9dad-11d1-80b4-00c04fd430c8"
+
+	tests := []struct {
+		name      string
+		requester *models.User
+		id        string
+		wantErr   error
+	}{
+		{name: "owner", requester: requester, id: id},
+		{name: "other user", requester: &models.User{ID: "u2"}, id: id, wantErr: models.ErrJobNotFound},
+		{name: "invalid id", requester: requester, id: "job", wantErr: models.ErrJobNotFound},
+		{name: "unknown", requester: requester, id: "6ba7b811-9dad-11d1-80b4-00c04fd430c8", wantErr: models.ErrJobNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			d := newDeps()
+			d.jobs = newMemJobs(&models.Job{ID: id, UserID: "u1", Kind: models.JobDeletion, Status: models.JobRunning})
+
+			job, err := newService(d, 1).Job(context.Background(), tt.requester, tt.id)
+
+			assert.ErrorIs(t, err, tt.wantErr)
+			if tt.wantErr == nil {
+				assert.Equal(t, models.JobRunning, job.Status)
+			}
+		})
+	}
+}
@@ -0,0 +1,297 @@
+package accountservice
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"marketplace/internal/models"
+	"marketplace/internal/utils/mapper"
+	"time"
+)
+
+const (
+	exportName = "export.zip"
+	// deleteAttempts bounds how often a deletion cancels orders again when
+	// another one was placed or paid in the meantime.
+	deleteAttempts = 3
+)
+
+// Resume queues the jobs that were still pending or running when the last
+// process stopped, so that an interrupted deletion is finished and an export
+// does not block new ones until it expires. It must be called before Run and
+// before requests are served, or a job could be queued twice.
+func (s *AccountService) Resume(ctx context.Context) {
+	op := pkg + "Resume"
+
+	log := s.log.With(slog.String("op", op))
+
+	jobs, err := s.jobs.ActiveJobs(ctx)
+	if err != nil {
+		log.Error("failed to get unfinished jobs", slog.String("error", err.Error()))
+		return
+	}
+
+	for _, job := range jobs {
+		select {
+		case s.queue <- job:
+		default:
+			log.Warn("job queue is full", slog.String("job_id", job.ID))
+			s.finish(ctx, log, job, models.ErrJobQueueFull)
+		}
+	}
+
+	if len(jobs) > 0 {
+		log.Info("unfinished jobs resumed", slog.Int("count", len(jobs)))
+	}
+}
+
+// Run processes queued jobs one at a time until ctx is cancelled.
+func (s *AccountService) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case job := <-s.queue:
+			s.process(ctx, job)
+		}
+	}
+}
+
+func (s *AccountService) process(ctx context.Context, job *models.Job) {
+	op := pkg + "process"
+
+	log := s.log.With(slog.String("op", op), slog.String("job_id", job.ID), slog.String("kind", string(job.Kind)))
+
+	log.Debug("attempting to process job")
+
+	job.Status = models.JobRunning
+	if err := s.jobs.SaveJob(ctx, job); err != nil {
+		log.Warn("failed to mark job running", slog.String("error", err.Error()))
+	}
+
+	var err error
+	switch job.Kind {
+	case models.JobExport:
+		err = s.export(ctx, log, job)
+	case models.JobDeletion:
+		err = s.deleteAccount(ctx, log, job)
+	}
+
+	if err != nil {
+		log.Error("job failed", slog.String("error", err.Error()))
+		s.finish(ctx, log, job, models.ErrInternal)
+		return
+	}
+
+	s.finish(ctx, log, job, nil)
+
+	log.Debug("job processed successfully")
+}
+
+// finish records the outcome of the job. jobErr is shown to the user, so it
+// must not carry internal details.
+func (s *AccountService) finish(ctx context.Context, log *slog.Logger, job *models.Job, jobErr error) {
+	now := time.Now()
+	job.FinishedAt = &now
+	job.Status = models.JobDone
+
+	if jobErr != nil {
+		job.Status = models.JobFailed
+		job.Error = jobErr.Error()
+	}
+
+	if err := s.jobs.SaveJob(ctx, job); err != nil {
+		log.Error("failed to save job", slog.String("error", err.Error()))
+	}
+}
+
+// export writes the archive straight into the export storage, so that it is
+// never held in memory as a whole.
+func (s *AccountService) export(ctx context.Context, log *slog.Logger, job *models.Job) error {
+	user, err := s.userProvider.UserByID(ctx, job.UserID)
+	if err != nil {
+		return err
+	}
+
+	profile, err := s.profileProvider.ProfileByID(ctx, job.UserID)
+	if err != nil {
+		return err
+	}
+
+	posts, err := s.postProvider.PostsByOwner(ctx, job.UserID)
+	if err != nil {
+		return err
+	}
+
+	pr, pw := io.Pipe()
+
+	go func() {
+		_ = pw.CloseWithError(s.writeArchive(log, pw, user, profile, posts))
+	}()
+
+	doc := &models.Document{ID: job.ID, Name: exportName}
+
+	_, err = s.exportStorage.SaveFile(doc, pr)
+	_ = pr.CloseWithError(err)
+	if err != nil {
+		return err
+	}
+
+	job.Path = doc.Path
+
+	return nil
+}
+
+// writeArchive writes profile.json, posts.json and the original images. An
+// image that cannot be read is left out rather than failing the export.
+func (s *AccountService) writeArchive(log *slog.Logger, w io.Writer, user *models.User, profile *models.Profile, posts []*models.PostWithDocument) error {
+	zw := zip.NewWriter(w)
+
+	exportProfile := mapper.DtoFromExportProfile(user, profile)
+	if err := writeJSON(zw, "profile.json", exportProfile); err != nil {
+		return err
+	}
+
+	exportPosts := mapper.DtoFromExportPosts(posts)
+	if err := writeJSON(zw, "posts.json", exportPosts); err != nil {
+		return err
+	}
+
+	if exportProfile.Avatar != "" {
+		if err := s.writeFile(log, zw, exportProfile.Avatar, &models.Document{Path: profile.AvatarPath}); err != nil {
+			return err
+		}
+	}
+
+	for i, post := range posts {
+		if err := s.writeFile(log, zw, exportPosts[i].Image, post.Document); err != nil {
+			return err
+		}
+	}
+
+	return zw.Close()
+}
+
+func (s *AccountService) writeFile(log *slog.Logger, zw *zip.Writer, name string, doc *models.Document) error {
+	file, err := s.fileStorage.LoadFile(doc)
+	if err != nil {
+		log.Warn("failed to load file, skipped", slog.String("name", name), slog.String("error", err.Error()))
+		return nil
+	}
+	defer file.Close()
+
+	entry, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(entry, file)
+	return err
+}
+
+func writeJSON(zw *zip.Writer, name string, v any) error {
+	entry, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(entry)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(v)
+}
+
+// deleteAccount cancels the user's open orders and removes the user with
+// their posts, then the files left on disk. Sessions are revoked again in
+// case it failed when the job was requested.
+func (s *AccountService) deleteAccount(ctx context.Context, log *slog.Logger, job *models.Job) error {
+	if err := s.sessions.InvalidateUserSessions(ctx, job.UserID); err != nil {
+		return err
+	}
+
+	profile, err := s.profileProvider.ProfileByID(ctx, job.UserID)
+	if err != nil {
+		if errors.Is(err, models.ErrUserNotFound) {
+			log.Info("account already deleted")
+			return nil
+		}
+		return err
+	}
+
+	posts, err := s.postProvider.PostsByOwner(ctx, job.UserID)
+	if err != nil {
+		return err
+	}
+
+	latestExport, err := s.jobs.LatestExport(ctx, job.UserID)
+	if err != nil && !errors.Is(err, models.ErrJobNotFound) {
+		log.Warn("failed to get latest export", slog.String("error", err.Error()))
+	}
+
+	err = s.removeUser(ctx, job.UserID)
+	if err != nil {
+		if errors.Is(err, models.ErrUserNotFound) {
+			log.Info("account already deleted")
+			return nil
+		}
+		return err
+	}
+
+	s.invalidatePosts(ctx, log)
+
+	for _, post := range posts {
+		deleteFile(log, s.fileStorage, post.Document)
+	}
+
+	if profile.AvatarPath != "" {
+		deleteFile(log, s.fileStorage, &models.Document{Path: profile.AvatarPath})
+	}
+
+	if latestExport != nil && latestExport.Path != "" {
+		deleteFile(log, s.exportStorage, &models.Document{Path: latestExport.Path})
+	}
+
+	log.Info("account deleted successfully", slog.Int("posts", len(posts)))
+
+	return nil
+}
+
+// removeUser deletes the user once their pending and confirmed orders are
+// cancelled, since deleting the user would drop them for the other side too.
+// A buyer may order one of the posts, or pay, while that runs; then the
+// orders are cancelled again.
+func (s *AccountService) removeUser(ctx context.Context, userID string) error {
+	var err error
+
+	for range deleteAttempts {
+		if err = s.orders.CancelUserOrders(ctx, userID); err != nil && !errors.Is(err, models.ErrInvalidTransition) {
+			return err
+		}
+
+		if err == nil {
+			err = s.userRemover.DeleteUser(ctx, userID)
+			if !errors.Is(err, models.ErrActiveOrders) {
+				return err
+			}
+		}
+	}
+
+	return err
+}
+
+// invalidatePosts drops the cached listings after the user's posts were
+// deleted. They expire on their own if this fails.
+func (s *AccountService) invalidatePosts(ctx context.Context, log *slog.Logger) {
+	if err := s.postCache.InvalidateLists(ctx); err != nil {
+		log.Warn("failed to invalidate cached posts", slog.String("error", err.Error()))
+	}
+}
+
+func deleteFile(log *slog.Logger, storage FileStorage, doc *models.Document) {
+	if err := storage.DeleteFile(doc); err != nil && !errors.Is(err, models.ErrDocumentNotFound) {
+		log.Warn("failed to delete file", slog.String("path", doc.Path), slog.String("error", err.Error()))
+	}
+}
@@ -0,0 +1,19 @@
+package apikeyservice
+
+import (
+	"context"
+	"marketplace/internal/models"
+	"time"
+)
+
+type APIKeyStorer interface {
+	AddAPIKey(ctx context.Context, key *models.APIKey, keyHash string) error
+	APIKeysByUser(ctx context.Context, userID string) ([]*models.APIKey, error)
+	APIKeyByHash(ctx context.Context, keyHash string) (*models.APIKey, error)
+	DeleteAPIKey(ctx context.Context, userID string, id string) error
+	TouchAPIKey(ctx context.Context, id string, usedAt time.Time) error
+}
+
+type UserProvider interface {
+	UserByID(ctx context.Context, id string) (*models.User, error)
+}
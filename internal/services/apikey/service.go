@@ -0,0 +1,223 @@
+package apikeyservice
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"marketplace/internal/models"
+	"marketplace/internal/utils/token"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	uuid "github.com/satori/go.uuid"
+)
+
+const pkg = "apiKeyService/"
+
+const (
+	// keyPrefix marks API keys, so that leaked ones are easy to search for.
+	keyPrefix = "mpk_"
+	// shownPrefix is how many leading characters of a key are kept to tell
+	// keys apart in the list.
+	shownPrefix   = len(keyPrefix) + 8
+	maxNameLength = 64
+	// lastUsedResolution limits how often last use is written for a key that
+	// is used in a burst of requests.
+	lastUsedResolution = time.Minute
+)
+
+type APIKeyService struct {
+	log        *slog.Logger
+	keys       APIKeyStorer
+	users      UserProvider
+	maxPerUser int
+}
+
+func New(
+	log *slog.Logger,
+	keys APIKeyStorer,
+	users UserProvider,
+	maxPerUser int,
+) *APIKeyService {
+	return &APIKeyService{
+		log:        log,
+		keys:       keys,
+		users:      users,
+		maxPerUser: maxPerUser,
+	}
+}
+
+// CreateAPIKey issues a key for the requester. The key itself is returned
+// only here; afterwards it can not be shown again.
+func (s *APIKeyService) CreateAPIKey(ctx context.Context, requester *models.User, name string, scopes []string, expiresAt *time.Time) (*models.APIKey, string, error) {
+	op := pkg + "CreateAPIKey"
+
+	log := s.log.With(slog.String("op", op), slog.String("user_id", requester.ID))
+
+	log.Debug("attempting to create api key")
+
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > maxNameLength {
+		log.Warn("invalid api key name")
+		return nil, "", models.ErrInvalidParams
+	}
+
+	if len(scopes) == 0 {
+		log.Warn("no scopes requested")
+		return nil, "", models.ErrInvalidParams
+	}
+	for _, scope := range scopes {
+		if !slices.Contains(models.Scopes, scope) {
+			log.Warn("unknown scope requested", slog.String("scope", scope))
+			return nil, "", models.ErrInvalidParams
+		}
+	}
+
+	now := time.Now().UTC()
+
+	if expiresAt != nil {
+		if !expiresAt.After(now) {
+			log.Warn("expiry in the past")
+			return nil, "", models.ErrInvalidParams
+		}
+		utc := expiresAt.UTC()
+		expiresAt = &utc
+	}
+
+	existing, err := s.keys.APIKeysByUser(ctx, requester.ID)
+	if err != nil {
+		log.Error("failed to list api keys", slog.String("error", err.Error()))
+		return nil, "", models.ErrInternal
+	}
+	if len(existing) >= s.maxPerUser {
+		log.Warn("api key limit reached", slog.Int("count", len(existing)))
+		return nil, "", models.ErrAPIKeyLimit
+	}
+
+	secret, err := token.New()
+	if err != nil {
+		log.Error("failed to generate api key", slog.String("error", err.Error()))
+		return nil, "", models.ErrInternal
+	}
+	plain := keyPrefix + secret
+
+	key := &models.APIKey{
+		ID:        uuid.NewV4().String(),
+		UserID:    requester.ID,
+		Name:      name,
+		Prefix:    plain[:shownPrefix],
+		Scopes:    slices.Compact(slices.Sorted(slices.Values(scopes))),
+		ExpiresAt: expiresAt,
+		CreatedAt: now,
+	}
+
+	if err := s.keys.AddAPIKey(ctx, key, token.Hash(plain)); err != nil {
+		log.Error("failed to add api key", slog.String("error", err.Error()))
+		return nil, "", models.ErrInternal
+	}
+
+	log.Debug("api key created successfully", slog.String("api_key_id", key.ID))
+
+	return key, plain, nil
+}
+
+func (s *APIKeyService) APIKeys(ctx context.Context, requester *models.User) ([]*models.APIKey, error) {
+	op := pkg + "APIKeys"
+
+	log := s.log.With(slog.String("op", op), slog.String("user_id", requester.ID))
+
+	log.Debug("attempting to list api keys")
+
+	keys, err := s.keys.APIKeysByUser(ctx, requester.ID)
+	if err != nil {
+		log.Error("failed to list api keys", slog.String("error", err.Error()))
+		return nil, models.ErrInternal
+	}
+
+	return keys, nil
+}
+
+func (s *APIKeyService) RevokeAPIKey(ctx context.Context, requester *models.User, id string) error {
+	op := pkg + "RevokeAPIKey"
+
+	log := s.log.With(slog.String("op", op), slog.String("user_id", requester.ID), slog.String("api_key_id", id))
+
+	log.Debug("attempting to revoke api key")
+
+	if _, err := uuid.FromString(id); err != nil {
+		log.Warn("invalid api key id")
+		return models.ErrAPIKeyNotFound
+	}
+
+	if err := s.keys.DeleteAPIKey(ctx, requester.ID, id); err != nil {
+		if errors.Is(err, models.ErrAPIKeyNotFound) {
+			log.Warn("api key not found")
+			return models.ErrAPIKeyNotFound
+		}
+		log.Error("failed to delete api key", slog.String("error", err.Error()))
+		return models.ErrInternal
+	}
+
+	log.Debug("api key revoked successfully")
+
+	return nil
+}
+
+// AuthenticateAPIKey returns the owner of the key and the key itself, whose
+// scopes limit what the request may do. Unknown and expired keys are
+// answered alike; keys of a banned owner stop working while the ban lasts.
+func (s *APIKeyService) AuthenticateAPIKey(ctx context.Context, plain string) (*models.User, *models.APIKey, error) {
+	op := pkg + "AuthenticateAPIKey"
+
+	log := s.log.With(slog.String("op", op))
+
+	if !strings.HasPrefix(plain, keyPrefix) {
+		log.Debug("not an api key")
+		return nil, nil, models.ErrInvalidCredentials
+	}
+
+	key, err := s.keys.APIKeyByHash(ctx, token.Hash(plain))
+	if err != nil {
+		if errors.Is(err, models.ErrAPIKeyNotFound) {
+			log.Warn("api key not found")
+			return nil, nil, models.ErrInvalidCredentials
+		}
+		log.Error("failed to get api key", slog.String("error", err.Error()))
+		return nil, nil, models.ErrInternal
+	}
+
+	log = log.With(slog.String("api_key_id", key.ID), slog.String("user_id", key.UserID))
+
+	now := time.Now().UTC()
+
+	if key.Expired(now) {
+		log.Info("api key expired")
+		return nil, nil, models.ErrInvalidCredentials
+	}
+
+	user, err := s.users.UserByID(ctx, key.UserID)
+	if err != nil {
+		if errors.Is(err, models.ErrUserNotFound) {
+			log.Warn("api key owner not found")
+			return nil, nil, models.ErrInvalidCredentials
+		}
+		log.Error("failed to get user", slog.String("error", err.Error()))
+		return nil, nil, models.ErrInternal
+	}
+
+	if user.Banned(now) {
+		log.Warn("api key of a banned user used")
+		return nil, nil, models.ErrUserBanned
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= lastUsedResolution {
+		if err := s.keys.TouchAPIKey(ctx, key.ID, now); err != nil {
+			log.Warn("failed to record api key use", slog.String("error", err.Error()))
+		}
+		key.LastUsedAt = &now
+	}
+
+	return user, key, nil
+}
@@ -0,0 +1,208 @@
+package apikeyservice
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"marketplace/internal/models"
+	"marketplace/internal/utils/token"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockAPIKeyStorer struct {
+	mock.Mock
+}
+
+func (m *mockAPIKeyStorer) AddAPIKey(ctx context.Context, key *models.APIKey, keyHash string) error {
+	return m.Called(ctx, key, keyHash).Error(0)
+}
+
+func (m *mockAPIKeyStorer) APIKeysByUser(ctx context.Context, userID string) ([]*models.APIKey, error) {
+	args := m.Called(ctx, userID)
+	keys, _ := args.Get(0).([]*models.APIKey)
+	return keys, args.Error(1)
+}
+
+func (m *mockAPIKeyStorer) APIKeyByHash(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	args := m.Called(ctx, keyHash)
+	key, _ := args.Get(0).(*models.APIKey)
+	return key, args.Error(1)
+}
+
+func (m *mockAPIKeyStorer) DeleteAPIKey(ctx context.Context, userID string, id string) error {
+	return m.Called(ctx, userID, id).Error(0)
+}
+
+func (m *mockAPIKeyStorer) TouchAPIKey(ctx context.Context, id string, usedAt time.Time) error {
+	return m.Called(ctx, id, usedAt).Error(0)
+}
+
+type mockUserProvider struct {
+	mock.Mock
+}
+
+func (m *mockUserProvider) UserByID(ctx context.Context, id string) (*models.User, error) {
+	args := m.Called(ctx, id)
+	user, _ := args.Get(0).(*models.User)
+	return user, args.Error(1)
+}
+
+var requester = &models.User{ID: "u1", Login: "alice"}
+
+func newService(keys *mockAPIKeyStorer, users *mockUserProvider) *APIKeyService {
+	return New(slog.New(slog.NewTextHandler(io.Discard, nil)), keys, users, 2)
+}
+
+func TestCreateAPIKey(t *testing.T) {
+	t.Parallel()
+
+	keys := new(mockAPIKeyStorer)
+	service := newService(keys, nil)
+
+	var storedHash string
+	keys.On("APIKeysByUser", mock.Anything, "u1").Return([]*models.APIKey{}, nil)
+	keys.On("AddAPIKey", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		storedHash = args.String(2)
+	}).Return(nil)
+
+	key, plain, err := service.CreateAPIKey(context.Background(), requester, " inventory ", []string{"posts:write", "posts:read", "posts:write"}, nil)
+
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(plain, "mpk_"))
+	assert.Equal(t, token.Hash(plain), storedHash)
+	assert.Equal(t, plain[:12], key.Prefix)
+	assert.Equal(t, "inventory", key.Name)
+	assert.Equal(t, []string{"posts:read", "posts:write"}, key.Scopes)
+}
+
+func TestCreateAPIKey_Rejects(t *testing.T) {
+	t.Parallel()
+
+	past := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name      string
+		keyName   string
+		scopes    []string
+		expiresAt *time.Time
+		existing  int
+		wantErr   error
+	}{
+		{name: "no name", keyName: " ", scopes: []string{"posts:read"}, wantErr: models.ErrInvalidParams},
+		{name: "no scopes", keyName: "k", wantErr: models.ErrInvalidParams},
+		{name: "unknown scope", keyName: "k", scopes: []string{"admin"}, wantErr: models.ErrInvalidParams},
+		{name: "expired", keyName: "k", scopes: []string{"posts:read"}, expiresAt: &past, wantErr: models.ErrInvalidParams},
+		{name: "limit", keyName: "k", scopes: []string{"posts:read"}, existing: 2, wantErr: models.ErrAPIKeyLimit},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			keys := new(mockAPIKeyStorer)
+			service := newService(keys, nil)
+
+			keys.On("APIKeysByUser", mock.Anything, "u1").Return(make([]*models.APIKey, tt.existing), nil)
+
+			_, _, err := service.CreateAPIKey(context.Background(), requester, tt.keyName, tt.scopes, tt.expiresAt)
+
+			assert.ErrorIs(t, err, tt.wantErr)
+			keys.AssertNotCalled(t, "AddAPIKey", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestAuthenticateAPIKey(t *testing.T) {
+	t.Parallel()
+
+	const plain = "mpk_secret"
+
+	past := time.Now().Add(-time.Hour)
+	recent := time.Now().Add(-time.Second)
+	later := time.Now().Add(time.Hour)
+
+	tests := []struct {
+		name      string
+		key       *models.APIKey
+		owner     *models.User
+		lookupErr error
+		wantErr   error
+		wantTouch bool
+	}{
+		{name: "first use", key: &models.APIKey{ID: "k1", UserID: "u1"}, wantTouch: true},
+		{name: "used a moment ago", key: &models.APIKey{ID: "k1", UserID: "u1", LastUsedAt: &recent}},
+		{name: "used long ago", key: &models.APIKey{ID: "k1", UserID: "u1", LastUsedAt: &past}, wantTouch: true},
+		{name: "expired", key: &models.APIKey{ID: "k1", UserID: "u1", ExpiresAt: &past}, wantErr: models.ErrInvalidCredentials},
+		{name: "unknown", lookupErr: models.ErrAPIKeyNotFound, wantErr: models.ErrInvalidCredentials},
+		{name: "owner banned", key: &models.APIKey{ID: "k1", UserID: "u1"}, owner: &models.User{ID: "u1", Ban: &models.Ban{Reason: "scam"}}, wantErr: models.ErrUserBanned},
+		{name: "owner suspended", key: &models.APIKey{ID: "k1", UserID: "u1"}, owner: &models.User{ID: "u1", Ban: &models.Ban{Reason: "spam", Until: &later}}, wantErr: models.ErrUserBanned},
+		{name: "suspension over", key: &models.APIKey{ID: "k1", UserID: "u1", LastUsedAt: &recent}, owner: &models.User{ID: "u1", Ban: &models.Ban{Reason: "spam", Until: &past}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			keys := new(mockAPIKeyStorer)
+			users := new(mockUserProvider)
+			service := newService(keys, users)
+
+			keys.On("APIKeyByHash", mock.Anything, token.Hash(plain)).Return(tt.key, tt.lookupErr)
+			keys.On("TouchAPIKey", mock.Anything, "k1", mock.Anything).Return(nil)
+			owner := requester
+			if tt.owner != nil {
+				owner = tt.owner
+			}
+			users.On("UserByID", mock.Anything, "u1").Return(owner, nil)
+
+			user, key, err := service.AuthenticateAPIKey(context.Background(), plain)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Nil(t, user)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, owner, user)
+			assert.Equal(t, "k1", key.ID)
+			if tt.wantTouch {
+				keys.AssertCalled(t, "TouchAPIKey", mock.Anything, "k1", mock.Anything)
+			} else {
+				keys.AssertNotCalled(t, "TouchAPIKey", mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestAuthenticateAPIKey_NotAKey(t *testing.T) {
+	t.Parallel()
+
+	keys := new(mockAPIKeyStorer)
+	service := newService(keys, nil)
+
+	_, _, err := service.AuthenticateAPIKey(context.Background(), "session-token")
+
+	assert.ErrorIs(t, err, models.ErrInvalidCredentials)
+	keys.AssertNotCalled(t, "APIKeyByHash", mock.Anything, mock.Anything)
+}
+
+func TestRevokeAPIKey(t *testing.T) {
+	t.Parallel()
+
+	keys := new(mockAPIKeyStorer)
+	service := newService(keys, nil)
+
+	id := "6f1c1c1e-8d1a-4c55-9a51-2f5a2a0b4a11"
+
+	keys.On("DeleteAPIKey", mock.Anything, "u1", id).Return(models.ErrAPIKeyNotFound)
+
+	assert.ErrorIs(t, service.RevokeAPIKey(context.Background(), requester, id), models.ErrAPIKeyNotFound)
+	assert.ErrorIs(t, service.RevokeAPIKey(context.Background(), requester, "not-a-uuid"), models.ErrAPIKeyNotFound)
+	keys.AssertNumberOfCalls(t, "DeleteAPIKey", 1)
+}
@@ -0,0 +1,82 @@
+package auctionservice
+
+import (
+	"context"
+	"log/slog"
+	"marketplace/internal/models"
+	"time"
+)
+
+// Closer periodically finishes auctions whose end time has passed, records
+// the winner and tells the seller and the winner about the result.
+type Closer struct {
+	log           *slog.Logger
+	auctionCloser AuctionCloser
+	notifier      Notifier
+	interval      time.Duration
+}
+
+func NewCloser(
+	log *slog.Logger,
+	auctionCloser AuctionCloser,
+	notifier Notifier,
+	interval time.Duration,
+) *Closer {
+	return &Closer{
+		log:           log,
+		auctionCloser: auctionCloser,
+		notifier:      notifier,
+		interval:      interval,
+	}
+}
+
+// Run closes auctions every interval until ctx is cancelled.
+func (c *Closer) Run(ctx context.Context) {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.close(ctx)
+		}
+	}
+}
+
+func (c *Closer) close(ctx context.Context) {
+	op := pkg + "close"
+
+	log := c.log.With(slog.String("op", op))
+
+	results, err := c.auctionCloser.CloseAuctions(ctx, time.Now())
+	if err != nil {
+		log.Error("failed to close auctions", slog.String("error", err.Error()))
+		return
+	}
+
+	for _, result := range results {
+		payload := models.AuctionEndedPayload{
+			PostID:      result.PostID,
+			WinnerLogin: result.WinnerLogin,
+			Amount:      result.Amount,
+		}
+
+		c.notify(ctx, log, result.SellerID, payload)
+
+		if result.WinnerID != "" {
+			c.notify(ctx, log, result.WinnerID, payload)
+		}
+	}
+
+	if len(results) > 0 {
+		log.Debug("auctions closed", slog.Int("count", len(results)))
+	}
+}
+
+func (c *Closer) notify(ctx context.Context, log *slog.Logger, userID string, payload models.AuctionEndedPayload) {
+	if err := c.notifier.Notify(ctx, userID, models.NotificationAuctionEnded, payload); err != nil {
+		log.Warn("failed to notify about auction end", slog.String("error", err.Error()))
+	}
+}
@@ -0,0 +1,23 @@
+package auctionservice
+
+import (
+	"context"
+	"marketplace/internal/models"
+	"time"
+)
+
+type BidPlacer interface {
+	PlaceBid(ctx context.Context, bid *models.Bid, window time.Duration, extension time.Duration) (*models.Auction, error)
+}
+
+type AuctionCloser interface {
+	CloseAuctions(ctx context.Context, now time.Time) ([]*models.AuctionResult, error)
+}
+
+type PostProvider interface {
+	ListedPostByID(ctx context.Context, id string) (*models.PostWithDocument, error)
+}
+
+type Notifier interface {
+	Notify(ctx context.Context, userID string, kind models.NotificationKind, payload models.NotificationPayload) error
+}
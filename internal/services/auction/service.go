@@ -0,0 +1,106 @@
+package auctionservice
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"marketplace/internal/models"
+	"marketplace/internal/utils/validator"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+)
+
+const pkg = "auctionService/"
+
+type AuctionService struct {
+	log              *slog.Logger
+	bidPlacer        BidPlacer
+	postProvider     PostProvider
+	snipingWindow    time.Duration
+	snipingExtension time.Duration
+}
+
+func New(
+	log *slog.Logger,
+	bidPlacer BidPlacer,
+	postProvider PostProvider,
+	snipingWindow time.Duration,
+	snipingExtension time.Duration,
+) *AuctionService {
+	return &AuctionService{
+		log:              log,
+		bidPlacer:        bidPlacer,
+		postProvider:     postProvider,
+		snipingWindow:    snipingWindow,
+		snipingExtension: snipingExtension,
+	}
+}
+
+func (as *AuctionService) PlaceBid(ctx context.Context, requester *models.User, postID string, amount int64) (*models.Bid, *models.Auction, error) {
+	op := pkg + "PlaceBid"
+
+	log := as.log.With(slog.String("op", op))
+
+	log.Debug("attempting to place bid")
+
+	if err := validator.ValidateBidAmount(amount); err != nil {
+		log.Warn("invalid bid amount received", slog.String("error", err.Error()))
+		return nil, nil, err
+	}
+
+	if _, err := uuid.FromString(postID); err != nil {
+		log.Warn("invalid post id received", slog.String("post_id", postID))
+		return nil, nil, models.ErrPostNotFound
+	}
+
+	post, err := as.postProvider.ListedPostByID(ctx, postID)
+	if err != nil {
+		if errors.Is(err, models.ErrPostNotFound) {
+			log.Warn("post not found", slog.String("post_id", postID))
+			return nil, nil, models.ErrPostNotFound
+		}
+		log.Error("failed to get post", slog.String("error", err.Error()))
+		return nil, nil, models.ErrInternal
+	}
+
+	if post.Auction == nil {
+		log.Warn("bid on a post without auction", slog.String("post_id", postID))
+		return nil, nil, models.ErrNotAuction
+	}
+
+	if post.OwnerID == requester.ID {
+		log.Warn("bid on own post", slog.String("post_id", postID))
+		return nil, nil, models.ErrOwnPost
+	}
+
+	bid := &models.Bid{
+		ID:          uuid.NewV4().String(),
+		PostID:      postID,
+		BidderID:    requester.ID,
+		BidderLogin: requester.Login,
+		Amount:      amount,
+		CreatedAt:   time.Now(),
+	}
+
+	auction, err := as.bidPlacer.PlaceBid(ctx, bid, as.snipingWindow, as.snipingExtension)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrNotAuction):
+			log.Warn("auction not found", slog.String("post_id", postID))
+			return nil, nil, models.ErrNotAuction
+		case errors.Is(err, models.ErrAuctionEnded):
+			log.Warn("bid after auction end", slog.String("post_id", postID))
+			return nil, nil, models.ErrAuctionEnded
+		case errors.Is(err, models.ErrBidTooLow):
+			log.Warn("bid is too low", slog.String("post_id", postID), slog.Int64("amount", amount))
+			return nil, nil, models.ErrBidTooLow
+		}
+		log.Error("failed to place bid", slog.String("error", err.Error()))
+		return nil, nil, models.ErrInternal
+	}
+
+	log.Debug("bid placed successfully", slog.String("bid_id", bid.ID))
+
+	return bid, auction, nil
+}
@@ -0,0 +1,164 @@
+package auctionservice
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"marketplace/internal/models"
+	"testing"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockBidPlacer struct {
+	mock.Mock
+}
+
+func (m *mockBidPlacer) PlaceBid(ctx context.Context, bid *models.Bid, window time.Duration, extension time.Duration) (*models.Auction, error) {
+	args := m.Called(ctx, bid, window, extension)
+	return args.Get(0).(*models.Auction), args.Error(1)
+}
+
+type mockAuctionCloser struct {
+	mock.Mock
+}
+
+func (m *mockAuctionCloser) CloseAuctions(ctx context.Context, now time.Time) ([]*models.AuctionResult, error) {
+	args := m.Called(ctx, now)
+	return args.Get(0).([]*models.AuctionResult), args.Error(1)
+}
+
+type mockPostProvider struct {
+	mock.Mock
+}
+
+func (m *mockPostProvider) ListedPostByID(ctx context.Context, id string) (*models.PostWithDocument, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*models.PostWithDocument), args.Error(1)
+}
+
+type mockNotifier struct {
+	mock.Mock
+}
+
+func (m *mockNotifier) Notify(ctx context.Context, userID string, kind models.NotificationKind, payload models.NotificationPayload) error {
+	args := m.Called(ctx, userID, kind, payload)
+	return args.Error(0)
+}
+
+func TestPlaceBid_Success(t *testing.T) {
+	t.Parallel()
+
+	placer := new(mockBidPlacer)
+	posts := new(mockPostProvider)
+	service := New(slog.Default(), placer, posts, 2*time.Minute, 5*time.Minute)
+
+	postID := uuid.NewV4().String()
+	requester := &models.User{ID: "bidder", Login: "bidder_login"}
+	auction := &models.Auction{StartPrice: 100, MinIncrement: 10, CurrentBid: 150, BidCount: 1, LeaderLogin: "bidder_login"}
+
+	posts.On("ListedPostByID", mock.Anything, postID).Return(&models.PostWithDocument{ID: postID, OwnerID: "seller", Auction: &models.Auction{}}, nil)
+	placer.On("PlaceBid", mock.Anything, mock.MatchedBy(func(bid *models.Bid) bool {
+		return bid.PostID == postID && bid.BidderID == "bidder" && bid.BidderLogin == "bidder_login" && bid.Amount == 150
+	}), 2*time.Minute, 5*time.Minute).Return(auction, nil)
+
+	bid, got, err := service.PlaceBid(context.Background(), requester, postID, 150)
+
+	assert.NoError(t, err)
+	assert.NotEmpty(t, bid.ID)
+	assert.Equal(t, auction, got)
+	placer.AssertExpectations(t)
+}
+
+func TestPlaceBid_Rejected(t *testing.T) {
+	t.Parallel()
+
+	postID := uuid.NewV4().String()
+
+	tests := []struct {
+		name    string
+		post    *models.PostWithDocument
+		postErr error
+		amount  int64
+		bidErr  error
+		want    error
+	}{
+		{name: "invalid amount", amount: 0, want: models.ErrInvalidPrice},
+		{name: "post not found", postErr: models.ErrPostNotFound, amount: 100, want: models.ErrPostNotFound},
+		{name: "not auction", post: &models.PostWithDocument{OwnerID: "seller"}, amount: 100, want: models.ErrNotAuction},
+		{name: "own post", post: &models.PostWithDocument{OwnerID: "bidder", Auction: &models.Auction{}}, amount: 100, want: models.ErrOwnPost},
+		{name: "too low", post: &models.PostWithDocument{OwnerID: "seller", Auction: &models.Auction{}}, amount: 100, bidErr: models.ErrBidTooLow, want: models.ErrBidTooLow},
+		{name: "ended", post: &models.PostWithDocument{OwnerID: "seller", Auction: &models.Auction{}}, amount: 100, bidErr: models.ErrAuctionEnded, want: models.ErrAuctionEnded},
+		{name: "db error", post: &models.PostWithDocument{OwnerID: "seller", Auction: &models.Auction{}}, amount: 100, bidErr: errors.New("db error"), want: models.ErrInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			placer := new(mockBidPlacer)
+			posts := new(mockPostProvider)
+			service := New(slog.Default(), placer, posts, time.Minute, time.Minute)
+
+			posts.On("ListedPostByID", mock.Anything, postID).Return(tt.post, tt.postErr)
+			placer.On("PlaceBid", mock.Anything, mock.Anything, time.Minute, time.Minute).Return((*models.Auction)(nil), tt.bidErr)
+
+			_, _, err := service.PlaceBid(context.Background(), &models.User{ID: "bidder"}, postID, tt.amount)
+
+			assert.ErrorIs(t, err, tt.want)
+			if tt.bidErr == nil {
+				placer.AssertNotCalled(t, "PlaceBid", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestPlaceBid_InvalidPostID(t *testing.T) {
+	t.Parallel()
+
+	posts := new(mockPostProvider)
+	service := New(slog.Default(), nil, posts, time.Minute, time.Minute)
+
+	_, _, err := service.PlaceBid(context.Background(), &models.User{ID: "bidder"}, "not-a-uuid", 100)
+
+	assert.ErrorIs(t, err, models.ErrPostNotFound)
+	posts.AssertNotCalled(t, "ListedPostByID", mock.Anything, mock.Anything)
+}
+
+func TestCloser_NotifiesSellerAndWinner(t *testing.T) {
+	t.Parallel()
+
+	closer := new(mockAuctionCloser)
+	notifier := new(mockNotifier)
+
+	results := []*models.AuctionResult{
+		{PostID: "p1", SellerID: "seller", WinnerID: "winner", WinnerLogin: "winner_login", Amount: 150},
+		{PostID: "p2", SellerID: "lonely_seller"},
+	}
+
+	closer.On("CloseAuctions", mock.Anything, mock.Anything).Return(results, nil)
+	notifier.On("Notify", mock.Anything, "seller", models.NotificationAuctionEnded, models.AuctionEndedPayload{PostID: "p1", WinnerLogin: "winner_login", Amount: 150}).Return(nil)
+	notifier.On("Notify", mock.Anything, "winner", models.NotificationAuctionEnded, models.AuctionEndedPayload{PostID: "p1", WinnerLogin: "winner_login", Amount: 150}).Return(errors.New("ignored"))
+	notifier.On("Notify", mock.Anything, "lonely_seller", models.NotificationAuctionEnded, models.AuctionEndedPayload{PostID: "p2"}).Return(nil)
+
+	NewCloser(slog.Default(), closer, notifier, time.Minute).close(context.Background())
+
+	notifier.AssertExpectations(t)
+	notifier.AssertNumberOfCalls(t, "Notify", 3)
+}
+
+func TestCloser_CloseFails(t *testing.T) {
+	t.Parallel()
+
+	closer := new(mockAuctionCloser)
+	notifier := new(mockNotifier)
+
+	closer.On("CloseAuctions", mock.Anything, mock.Anything).Return([]*models.AuctionResult(nil), errors.New("db error"))
+
+	NewCloser(slog.Default(), closer, notifier, time.Minute).close(context.Background())
+
+	notifier.AssertNotCalled(t, "Notify", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
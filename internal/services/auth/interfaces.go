@@ -10,11 +10,48 @@ type UserAdder interface {
 }
 
 type UserProvider interface {
+	UserByID(ctx context.Context, id string) (*models.User, error)
 	UserByLogin(ctx context.Context, login string) (*models.User, error)
 }
 
 type SessionStorer interface {
-	SaveSession(ctx context.Context, token string, userJSON string) error
-	DeleteSession(ctx context.Context, token string) error
-	UserByToken(ctx context.Context, token string) (string, error)
+	SaveSession(ctx context.Context, session *models.Session, tokens *models.TokenPair, grant *models.AccessGrant) error
+	SessionVersion(ctx context.Context, userID string) (int64, error)
+	InvalidateUserSessions(ctx context.Context, userID string) error
+	ConsumeRefreshToken(ctx context.Context, refreshToken string) (*models.RefreshToken, error)
+	SessionByID(ctx context.Context, id string) (*models.Session, error)
+	SessionsByUser(ctx context.Context, userID string) ([]*models.Session, error)
+	DeleteSessionByID(ctx context.Context, userID string, id string) error
+}
+
+// AccessTokens makes the access token for a grant and resolves it back,
+// reporting unknown or revoked tokens as models.ErrSessionNotFound.
+type AccessTokens interface {
+	NewAccessToken(ctx context.Context, grant *models.AccessGrant) (string, error)
+	AccessGrant(ctx context.Context, accessToken string) (*models.AccessGrant, error)
+}
+
+type EventPublisher interface {
+	Publish(ctx context.Context, userID string, eventType models.EventType, payload any) error
+}
+
+type LoginLimiter interface {
+	Check(ctx context.Context, login string, ip string) error
+	Fail(ctx context.Context, login string, ip string) error
+	Succeed(ctx context.Context, login string) error
+}
+
+type TwoFactorVerifier interface {
+	Enabled(ctx context.Context, userID string) (bool, error)
+	Verify(ctx context.Context, userID string, code string) error
+}
+
+type ChallengeStorer interface {
+	SaveChallenge(ctx context.Context, challengeToken string, challenge *models.TwoFactorChallenge) error
+	Challenge(ctx context.Context, challengeToken string) (*models.TwoFactorChallenge, error)
+	ConsumeChallenge(ctx context.Context, challengeToken string) (*models.TwoFactorChallenge, error)
+}
+
+type VerificationSender interface {
+	SendVerification(ctx context.Context, user *models.User) error
 }
@@ -2,11 +2,14 @@ package authservice
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"log/slog"
 	"marketplace/internal/models"
+	"marketplace/internal/utils/token"
 	"marketplace/internal/utils/validator"
+	"sort"
+	"strings"
+	"time"
 
 	uuid "github.com/satori/go.uuid"
 	"golang.org/x/crypto/bcrypt"
@@ -14,11 +17,22 @@ import (
 
 const pkg = "authService/"
 
+// dummyHash is compared against when the login is unknown, so that it takes
+// as long to reject as a wrong password.
+var dummyHash, _ = bcrypt.GenerateFromPassword([]byte("dummy password"), bcrypt.DefaultCost)
+
 type AuthService struct {
 	log           *slog.Logger
 	userAdder     UserAdder
 	userProvider  UserProvider
 	sessionStorer SessionStorer
+	accessTokens  AccessTokens
+	events        EventPublisher
+	verification  VerificationSender
+	limiter       LoginLimiter
+	twoFactor     TwoFactorVerifier
+	challenges    ChallengeStorer
+	users         *userCache
 }
 
 func New(
@@ -26,16 +40,32 @@ func New(
 	userAdder UserAdder,
 	userProvider UserProvider,
 	sessionStorer SessionStorer,
+	accessTokens AccessTokens,
+	events EventPublisher,
+	verification VerificationSender,
+	limiter LoginLimiter,
+	twoFactor TwoFactorVerifier,
+	challenges ChallengeStorer,
+	userTTL time.Duration,
 ) *AuthService {
 	return &AuthService{
 		log:           log,
 		userAdder:     userAdder,
 		userProvider:  userProvider,
 		sessionStorer: sessionStorer,
+		accessTokens:  accessTokens,
+		events:        events,
+		verification:  verification,
+		limiter:       limiter,
+		twoFactor:     twoFactor,
+		challenges:    challenges,
+		users:         newUserCache(userTTL),
 	}
 }
 
-func (a *AuthService) Register(ctx context.Context, login string, password string) error {
+// Register creates a user. The email is optional; when given, a verification
+// link is mailed to it.
+func (a *AuthService) Register(ctx context.Context, login string, password string, email string) error {
 	op := pkg + "Register"
 
 	log := a.log.With(slog.String("op", op))
@@ -47,6 +77,12 @@ func (a *AuthService) Register(ctx context.Context, login string, password strin
 		return models.ErrInvalidParams
 	}
 
+	email = strings.ToLower(strings.TrimSpace(email))
+	if email != "" && !validator.IsValidEmail(email) {
+		log.Warn("invalid email format")
+		return models.ErrInvalidEmail
+	}
+
 	passHash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
 		log.Error("failed to generate password hash", slog.String("error", err.Error()))
@@ -57,6 +93,7 @@ func (a *AuthService) Register(ctx context.Context, login string, password strin
 		ID:       uuid.NewV4().String(),
 		Login:    login,
 		PassHash: passHash,
+		Email:    email,
 	}
 
 	err = a.userAdder.AddUser(ctx, user)
@@ -65,17 +102,32 @@ func (a *AuthService) Register(ctx context.Context, login string, password strin
 			log.Warn("user already exists", slog.String("login", user.Login))
 			return models.ErrUserExists
 		}
+		if errors.Is(err, models.ErrEmailExists) {
+			log.Warn("email already in use")
+			return models.ErrEmailExists
+		}
 
 		log.Error("failed to add user", slog.String("error", err.Error()))
 		return models.ErrInternal
 	}
 
+	if user.Email != "" {
+		if err := a.verification.SendVerification(ctx, user); err != nil {
+			log.Warn("failed to send verification mail", slog.String("error", err.Error()))
+		}
+	}
+
 	log.Debug("user registered successfully")
 
 	return nil
 }
 
-func (a *AuthService) Login(ctx context.Context, login string, password string) (string, error) {
+// Login checks the password and opens a session. Unknown logins and wrong
+// passwords are answered alike and take the same time, and repeated failures
+// block the login and the client IP for a while. Users with two-factor
+// authentication get a challenge instead, to complete with CompleteTwoFactor.
+// Banned users are told so only after the right password.
+func (a *AuthService) Login(ctx context.Context, login string, password string, client *models.ClientInfo) (*models.LoginResult, error) {
 	op := pkg + "Login"
 
 	log := a.log.With(
@@ -84,41 +136,348 @@ func (a *AuthService) Login(ctx context.Context, login string, password string)
 
 	log.Debug("attempting to login user")
 
+	if err := a.limiter.Check(ctx, login, client.IP); err != nil {
+		var retry *models.RetryAfterError
+		if errors.As(err, &retry) {
+			log.Warn("login blocked", slog.String("ip", client.IP))
+			return nil, err
+		}
+		log.Error("failed to check login attempts", slog.String("error", err.Error()))
+		return nil, models.ErrInternal
+	}
+
 	user, err := a.userProvider.UserByLogin(ctx, login)
+	if err != nil && !errors.Is(err, models.ErrUserNotFound) {
+		log.Error("failed to get user", slog.String("error", err.Error()))
+		return nil, models.ErrInternal
+	}
+
+	passHash := dummyHash
+	if user != nil {
+		passHash = user.PassHash
+	}
+
+	if err := bcrypt.CompareHashAndPassword(passHash, []byte(password)); err != nil || user == nil {
+		log.Info("invalid credentials", slog.Bool("user_found", user != nil))
+
+		if err := a.limiter.Fail(ctx, login, client.IP); err != nil {
+			log.Error("failed to register failed login", slog.String("error", err.Error()))
+			return nil, models.ErrInternal
+		}
+
+		return nil, models.ErrInvalidCredentials
+	}
+
+	result, err := a.startSession(ctx, log, user, client)
 	if err != nil {
-		if errors.Is(err, models.ErrUserNotFound) {
-			log.Info("user not found", slog.String("error", models.ErrUserNotFound.Error()))
-			return "", models.ErrUserNotFound
+		return nil, err
+	}
+
+	log.Debug("user logged in successfully")
+
+	return result, nil
+}
+
+// LoginUser logs in a user that was authenticated elsewhere, such as by an
+// OpenID Connect provider. Two-factor authentication still applies.
+func (a *AuthService) LoginUser(ctx context.Context, user *models.User, client *models.ClientInfo) (*models.LoginResult, error) {
+	op := pkg + "LoginUser"
+
+	log := a.log.With(slog.String("op", op), slog.String("user_id", user.ID))
+
+	log.Debug("attempting to login authenticated user")
+
+	result, err := a.startSession(ctx, log, user, client)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Debug("user logged in successfully")
+
+	return result, nil
+}
+
+// startSession opens a session for a user whose first factor has been
+// checked, or returns a challenge when the user has a second one. Banned users
+// are turned away.
+func (a *AuthService) startSession(ctx context.Context, log *slog.Logger, user *models.User, client *models.ClientInfo) (*models.LoginResult, error) {
+	if user.Banned(time.Now()) {
+		log.Warn("banned user tried to login", slog.String("user_id", user.ID))
+		return nil, models.ErrUserBanned
+	}
+
+	twoFactor, err := a.twoFactor.Enabled(ctx, user.ID)
+	if err != nil {
+		log.Error("failed to check two-factor authentication", slog.String("error", err.Error()))
+		return nil, models.ErrInternal
+	}
+
+	// Failed logins are only forgotten after the second factor, or the
+	// password alone would reset the count of wrong codes.
+	if twoFactor {
+		challengeToken, err := token.New()
+		if err != nil {
+			log.Error("failed to generate challenge", slog.String("error", err.Error()))
+			return nil, models.ErrInternal
+		}
+
+		err = a.challenges.SaveChallenge(ctx, challengeToken, &models.TwoFactorChallenge{
+			UserID:    user.ID,
+			Login:     user.Login,
+			CreatedAt: time.Now().UTC(),
+		})
+		if err != nil {
+			log.Error("failed to save challenge", slog.String("error", err.Error()))
+			return nil, models.ErrInternal
+		}
+
+		log.Debug("first factor accepted, waiting for second factor")
+
+		return &models.LoginResult{Challenge: challengeToken}, nil
+	}
+
+	tokens, err := a.openSession(ctx, log, user, client)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.LoginResult{Tokens: tokens}, nil
+}
+
+// CompleteTwoFactor finishes a login started by Login with a code from the
+// authenticator app or a recovery code. A wrong code leaves the challenge in
+// place and counts as a failed login.
+func (a *AuthService) CompleteTwoFactor(ctx context.Context, challengeToken string, code string, client *models.ClientInfo) (*models.TokenPair, error) {
+	op := pkg + "CompleteTwoFactor"
+
+	log := a.log.With(slog.String("op", op))
+
+	log.Debug("attempting to complete two-factor login")
+
+	challenge, err := a.challenges.Challenge(ctx, challengeToken)
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidChallenge) {
+			log.Warn("challenge not found")
+			return nil, models.ErrInvalidChallenge
+		}
+		log.Error("failed to get challenge", slog.String("error", err.Error()))
+		return nil, models.ErrInternal
+	}
+
+	log = log.With(slog.String("user_id", challenge.UserID))
+
+	if err := a.limiter.Check(ctx, challenge.Login, client.IP); err != nil {
+		var retry *models.RetryAfterError
+		if errors.As(err, &retry) {
+			log.Warn("login blocked", slog.String("ip", client.IP))
+			return nil, err
+		}
+		log.Error("failed to check login attempts", slog.String("error", err.Error()))
+		return nil, models.ErrInternal
+	}
+
+	if err := a.twoFactor.Verify(ctx, challenge.UserID, code); err != nil {
+		switch {
+		case errors.Is(err, models.ErrInvalidTwoFactorCode):
+			log.Info("invalid two-factor code")
+
+			if err := a.limiter.Fail(ctx, challenge.Login, client.IP); err != nil {
+				log.Error("failed to register failed login", slog.String("error", err.Error()))
+				return nil, models.ErrInternal
+			}
+
+			return nil, models.ErrInvalidTwoFactorCode
+		case errors.Is(err, models.ErrTwoFactorNotEnrolled):
+			log.Warn("two-factor authentication was turned off")
+			return nil, models.ErrInvalidChallenge
+		default:
+			log.Error("failed to verify two-factor code", slog.String("error", err.Error()))
+			return nil, models.ErrInternal
 		}
+	}
 
+	if _, err := a.challenges.ConsumeChallenge(ctx, challengeToken); err != nil {
+		if errors.Is(err, models.ErrInvalidChallenge) {
+			log.Warn("challenge already used")
+			return nil, models.ErrInvalidChallenge
+		}
+		log.Error("failed to consume challenge", slog.String("error", err.Error()))
+		return nil, models.ErrInternal
+	}
+
+	user, err := a.userProvider.UserByID(ctx, challenge.UserID)
+	if err != nil {
+		if errors.Is(err, models.ErrUserNotFound) {
+			log.Warn("user not found")
+			return nil, models.ErrInvalidChallenge
+		}
 		log.Error("failed to get user", slog.String("error", err.Error()))
-		return "", models.ErrInternal
+		return nil, models.ErrInternal
+	}
+
+	if user.Banned(time.Now()) {
+		log.Warn("banned user tried to login")
+		return nil, models.ErrUserBanned
+	}
+
+	tokens, err := a.openSession(ctx, log, user, client)
+	if err != nil {
+		return nil, err
 	}
 
-	if err := bcrypt.CompareHashAndPassword(user.PassHash, []byte(password)); err != nil {
-		log.Info("invalid credentials", slog.String("error", err.Error()))
-		return "", models.ErrInvalidCredentials
+	log.Debug("two-factor login completed successfully")
+
+	return tokens, nil
+}
+
+// openSession forgets the failed logins of the user and starts a session on
+// the client's device.
+func (a *AuthService) openSession(ctx context.Context, log *slog.Logger, user *models.User, client *models.ClientInfo) (*models.TokenPair, error) {
+	if err := a.limiter.Succeed(ctx, user.Login); err != nil {
+		log.Warn("failed to reset failed logins", slog.String("error", err.Error()))
+	}
+
+	now := time.Now().UTC()
+
+	session := &models.Session{
+		ID:         uuid.NewV4().String(),
+		UserID:     user.ID,
+		UserAgent:  client.UserAgent,
+		IP:         client.IP,
+		CreatedAt:  now,
+		LastSeenAt: now,
 	}
 
-	token := uuid.NewV4().String()
+	return a.issueTokens(ctx, log, session)
+}
+
+// Refresh exchanges a refresh token for a new token pair in the same session.
+// A refresh token can be exchanged once; presenting it again means it has
+// leaked, so the whole session is revoked and the user has to log in again.
+func (a *AuthService) Refresh(ctx context.Context, refreshToken string, client *models.ClientInfo) (*models.TokenPair, error) {
+	op := pkg + "Refresh"
+
+	log := a.log.With(slog.String("op", op))
 
-	userJSON, err := json.Marshal(user)
+	log.Debug("attempting to refresh session")
+
+	refresh, err := a.sessionStorer.ConsumeRefreshToken(ctx, refreshToken)
 	if err != nil {
-		log.Error("failed to marshal user", slog.String("error", err.Error()))
-		return "", models.ErrInternal
+		if errors.Is(err, models.ErrSessionNotFound) {
+			log.Warn("refresh token not found")
+			return nil, models.ErrInvalidCredentials
+		}
+		log.Error("failed to consume refresh token", slog.String("error", err.Error()))
+		return nil, models.ErrInternal
+	}
+
+	log = log.With(slog.String("user_id", refresh.UserID), slog.String("session_id", refresh.SessionID))
+
+	if refresh.Used {
+		log.Warn("refresh token reused, revoking session")
+
+		if err := a.sessionStorer.DeleteSessionByID(ctx, refresh.UserID, refresh.SessionID); err != nil {
+			log.Error("failed to revoke session", slog.String("error", err.Error()))
+			return nil, models.ErrInternal
+		}
+
+		a.publishSessionEnded(ctx, log, refresh.UserID, "refresh_token_reused")
+
+		return nil, models.ErrRefreshTokenReused
 	}
 
-	err = a.sessionStorer.SaveSession(ctx, token, string(userJSON))
+	version, err := a.sessionStorer.SessionVersion(ctx, refresh.UserID)
 	if err != nil {
-		log.Error("failed to store token", slog.String("error", err.Error()))
-		return "", models.ErrInternal
+		log.Error("failed to get session version", slog.String("error", err.Error()))
+		return nil, models.ErrInternal
 	}
 
-	log.Debug("user logged in successfully")
+	if refresh.Version != version {
+		log.Warn("refresh token issued before sessions were invalidated")
+		return nil, models.ErrInvalidCredentials
+	}
 
-	return token, nil
+	user, err := a.user(ctx, log, refresh.UserID, version)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.Banned(time.Now()) {
+		log.Warn("banned user tried to refresh session")
+		return nil, models.ErrUserBanned
+	}
+
+	session, err := a.sessionStorer.SessionByID(ctx, refresh.SessionID)
+	if err != nil {
+		if errors.Is(err, models.ErrSessionNotFound) {
+			log.Warn("session not found")
+			return nil, models.ErrInvalidCredentials
+		}
+		log.Error("failed to get session", slog.String("error", err.Error()))
+		return nil, models.ErrInternal
+	}
+
+	session.UserAgent = client.UserAgent
+	session.IP = client.IP
+	session.LastSeenAt = time.Now().UTC()
+
+	tokens, err := a.issueTokens(ctx, log, session)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Debug("session refreshed successfully")
+
+	return tokens, nil
+}
+
+// issueTokens stores a new token pair in the session. The tokens refer to the
+// user by ID only and carry the current session version of the user.
+func (a *AuthService) issueTokens(ctx context.Context, log *slog.Logger, session *models.Session) (*models.TokenPair, error) {
+	refreshToken, err := token.New()
+	if err != nil {
+		log.Error("failed to generate refresh token", slog.String("error", err.Error()))
+		return nil, models.ErrInternal
+	}
+
+	version, err := a.sessionStorer.SessionVersion(ctx, session.UserID)
+	if err != nil {
+		log.Error("failed to get session version", slog.String("error", err.Error()))
+		return nil, models.ErrInternal
+	}
+
+	grant := &models.AccessGrant{
+		UserID:    session.UserID,
+		SessionID: session.ID,
+		Version:   version,
+		IssuedAt:  session.LastSeenAt,
+	}
+
+	accessToken, err := a.accessTokens.NewAccessToken(ctx, grant)
+	if err != nil {
+		log.Error("failed to generate access token", slog.String("error", err.Error()))
+		return nil, models.ErrInternal
+	}
+
+	tokens := &models.TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+	}
+
+	err = a.sessionStorer.SaveSession(ctx, session, tokens, grant)
+	if err != nil {
+		log.Error("failed to store token", slog.String("error", err.Error()))
+		return nil, models.ErrInternal
+	}
+
+	return tokens, nil
 }
 
+// UserByToken resolves the user of an access token, by the configured access
+// token strategy. The user is loaded from the user provider, not from the
+// session, so password changes and other account updates apply to live
+// sessions within the user cache TTL. Tokens of revoked or invalidated
+// sessions are rejected by the strategy.
 func (a *AuthService) UserByToken(ctx context.Context, token string) (*models.User, error) {
 	op := pkg + "UserByToken"
 
@@ -128,27 +487,51 @@ func (a *AuthService) UserByToken(ctx context.Context, token string) (*models.Us
 
 	log.Debug("attempting to get user by token")
 
-	userJSON, err := a.sessionStorer.UserByToken(ctx, token)
+	grant, err := a.accessTokens.AccessGrant(ctx, token)
 	if err != nil {
 		if errors.Is(err, models.ErrSessionNotFound) {
-			log.Warn("failed to get user by token", slog.String("token", token), slog.String("error", err.Error()))
+			log.Warn("failed to get user by token", slog.String("error", err.Error()))
 			return nil, models.ErrInvalidCredentials
 		}
-		log.Error("failed to get user by token", slog.String("token", token), slog.String("error", err.Error()))
+		log.Error("failed to get user by token", slog.String("error", err.Error()))
 		return nil, models.ErrInternal
 	}
 
-	var user models.User
+	log = log.With(slog.String("user_id", grant.UserID))
 
-	err = json.Unmarshal([]byte(userJSON), &user)
+	user, err := a.user(ctx, log, grant.UserID, grant.Version)
 	if err != nil {
-		log.Error("failed to unmarshal user from json", slog.String("token", token), slog.String("error", err.Error()))
-		return nil, models.ErrInternal
+		return nil, err
+	}
+
+	if user.Banned(time.Now()) {
+		log.Warn("banned user tried to use access token")
+		return nil, models.ErrUserBanned
 	}
 
 	log.Debug("user was founded successfully")
 
-	return &user, nil
+	return user, nil
+}
+
+func (a *AuthService) user(ctx context.Context, log *slog.Logger, id string, version int64) (*models.User, error) {
+	if user, ok := a.users.get(id, version); ok {
+		return user, nil
+	}
+
+	user, err := a.userProvider.UserByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, models.ErrUserNotFound) {
+			log.Warn("user not found")
+			return nil, models.ErrInvalidCredentials
+		}
+		log.Error("failed to get user", slog.String("error", err.Error()))
+		return nil, models.ErrInternal
+	}
+
+	a.users.put(user, version)
+
+	return user, nil
 }
 
 func (a *AuthService) Logout(ctx context.Context, token string) error {
@@ -158,18 +541,127 @@ func (a *AuthService) Logout(ctx context.Context, token string) error {
 
 	log.Debug("attempting to logout user")
 
-	err := a.sessionStorer.DeleteSession(ctx, token)
+	grant, err := a.accessTokens.AccessGrant(ctx, token)
 	if err != nil {
 		if errors.Is(err, models.ErrSessionNotFound) {
-			log.Warn("session not found", slog.String("session", token))
+			log.Warn("session not found")
 
 			return nil
 		}
+		log.Error("failed to get session", slog.String("error", err.Error()))
+		return models.ErrInternal
+	}
+
+	err = a.sessionStorer.DeleteSessionByID(ctx, grant.UserID, grant.SessionID)
+	if err != nil {
 		log.Error("failed to delete session", slog.String("error", err.Error()))
 		return models.ErrInternal
 	}
 
+	a.publishSessionEnded(ctx, log, grant.UserID, "logout")
+
 	log.Debug("user logged out successfully")
 
 	return nil
 }
+
+// Sessions returns the live sessions of the requester, the most recently used
+// first. The session of currentToken is marked as current.
+func (a *AuthService) Sessions(ctx context.Context, requester *models.User, currentToken string) ([]*models.Session, error) {
+	op := pkg + "Sessions"
+
+	log := a.log.With(slog.String("op", op), slog.String("user_id", requester.ID))
+
+	log.Debug("attempting to list sessions")
+
+	sessions, err := a.sessionStorer.SessionsByUser(ctx, requester.ID)
+	if err != nil {
+		log.Error("failed to list sessions", slog.String("error", err.Error()))
+		return nil, models.ErrInternal
+	}
+
+	current, err := a.accessTokens.AccessGrant(ctx, currentToken)
+	if err != nil && !errors.Is(err, models.ErrSessionNotFound) {
+		log.Error("failed to get current session", slog.String("error", err.Error()))
+		return nil, models.ErrInternal
+	}
+
+	for _, session := range sessions {
+		session.Current = current != nil && session.ID == current.SessionID
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].LastSeenAt.After(sessions[j].LastSeenAt)
+	})
+
+	log.Debug("sessions listed successfully")
+
+	return sessions, nil
+}
+
+// RevokeSession ends one session of the requester, which may be the current
+// one.
+func (a *AuthService) RevokeSession(ctx context.Context, requester *models.User, id string) error {
+	op := pkg + "RevokeSession"
+
+	log := a.log.With(slog.String("op", op), slog.String("user_id", requester.ID), slog.String("session_id", id))
+
+	log.Debug("attempting to revoke session")
+
+	session, err := a.sessionStorer.SessionByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, models.ErrSessionNotFound) {
+			log.Warn("session not found")
+			return models.ErrSessionNotFound
+		}
+		log.Error("failed to get session", slog.String("error", err.Error()))
+		return models.ErrInternal
+	}
+
+	if session.UserID != requester.ID {
+		log.Warn("session belongs to another user")
+		return models.ErrSessionNotFound
+	}
+
+	if err := a.sessionStorer.DeleteSessionByID(ctx, requester.ID, id); err != nil {
+		log.Error("failed to revoke session", slog.String("error", err.Error()))
+		return models.ErrInternal
+	}
+
+	a.publishSessionEnded(ctx, log, requester.ID, "revoked")
+
+	log.Debug("session revoked successfully")
+
+	return nil
+}
+
+// RevokeAllSessions logs the requester out everywhere, the current session
+// included. Tokens issued before are void even if a copy of them survived in
+// the cache.
+func (a *AuthService) RevokeAllSessions(ctx context.Context, requester *models.User) error {
+	op := pkg + "RevokeAllSessions"
+
+	log := a.log.With(slog.String("op", op), slog.String("user_id", requester.ID))
+
+	log.Debug("attempting to revoke all sessions")
+
+	if err := a.sessionStorer.InvalidateUserSessions(ctx, requester.ID); err != nil {
+		log.Error("failed to revoke sessions", slog.String("error", err.Error()))
+		return models.ErrInternal
+	}
+
+	a.users.forget(requester.ID)
+
+	a.publishSessionEnded(ctx, log, requester.ID, "revoked")
+
+	log.Debug("all sessions revoked successfully")
+
+	return nil
+}
+
+func (a *AuthService) publishSessionEnded(ctx context.Context, log *slog.Logger, userID string, reason string) {
+	err := a.events.Publish(ctx, userID, models.EventSessionEnded, models.SessionEventPayload{Reason: reason})
+	if err != nil {
+		log.Warn("failed to publish session ended event", slog.String("error", err.Error()))
+	}
+}
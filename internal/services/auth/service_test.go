@@ -2,11 +2,12 @@ package authservice
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"log/slog"
 	"marketplace/internal/models"
+	opaquetokens "marketplace/internal/tokens/opaque"
 	"testing"
+	"time"
 
 	uuid "github.com/satori/go.uuid"
 	"github.com/stretchr/testify/assert"
@@ -41,21 +42,138 @@ type mockSessionStorer struct {
 	mock.Mock
 }
 
-func (m *mockSessionStorer) SaveSession(ctx context.Context, token string, userJSON string) error {
-	args := m.Called(ctx, token, userJSON)
+func (m *mockSessionStorer) SaveSession(ctx context.Context, session *models.Session, tokens *models.TokenPair, grant *models.AccessGrant) error {
+	args := m.Called(ctx, session, tokens, grant)
 	return args.Error(0)
 }
 
-func (m *mockSessionStorer) DeleteSession(ctx context.Context, token string) error {
-	args := m.Called(ctx, token)
+// grantVersion matches the grant SaveSession is called with by its version.
+func grantVersion(version int64) any {
+	return mock.MatchedBy(func(grant *models.AccessGrant) bool {
+		return grant.Version == version
+	})
+}
+
+func (m *mockSessionStorer) SessionVersion(ctx context.Context, userID string) (int64, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *mockSessionStorer) InvalidateUserSessions(ctx context.Context, userID string) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func (m *mockSessionStorer) SessionByID(ctx context.Context, id string) (*models.Session, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*models.Session), args.Error(1)
+}
+
+func (m *mockSessionStorer) SessionsByUser(ctx context.Context, userID string) ([]*models.Session, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]*models.Session), args.Error(1)
+}
+
+func (m *mockSessionStorer) ConsumeRefreshToken(ctx context.Context, refreshToken string) (*models.RefreshToken, error) {
+	args := m.Called(ctx, refreshToken)
+	return args.Get(0).(*models.RefreshToken), args.Error(1)
+}
+
+func (m *mockSessionStorer) DeleteSessionByID(ctx context.Context, userID string, id string) error {
+	args := m.Called(ctx, userID, id)
+	return args.Error(0)
+}
+
+func (m *mockSessionStorer) AccessGrant(ctx context.Context, accessToken string) (*models.AccessGrant, error) {
+	args := m.Called(ctx, accessToken)
+	grant, _ := args.Get(0).(*models.AccessGrant)
+	return grant, args.Error(1)
+}
+
+type mockLoginLimiter struct {
+	mock.Mock
+}
+
+func (m *mockLoginLimiter) Check(ctx context.Context, login string, ip string) error {
+	return m.Called(ctx, login, ip).Error(0)
+}
+
+func (m *mockLoginLimiter) Fail(ctx context.Context, login string, ip string) error {
+	return m.Called(ctx, login, ip).Error(0)
+}
+
+func (m *mockLoginLimiter) Succeed(ctx context.Context, login string) error {
+	return m.Called(ctx, login).Error(0)
+}
+
+type mockTwoFactorVerifier struct {
+	mock.Mock
+}
+
+func (m *mockTwoFactorVerifier) Enabled(ctx context.Context, userID string) (bool, error) {
+	args := m.Called(ctx, userID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *mockTwoFactorVerifier) Verify(ctx context.Context, userID string, code string) error {
+	return m.Called(ctx, userID, code).Error(0)
+}
+
+type mockChallengeStorer struct {
+	mock.Mock
+}
+
+func (m *mockChallengeStorer) SaveChallenge(ctx context.Context, challengeToken string, challenge *models.TwoFactorChallenge) error {
+	return m.Called(ctx, challengeToken, challenge).Error(0)
+}
+
+func (m *mockChallengeStorer) Challenge(ctx context.Context, challengeToken string) (*models.TwoFactorChallenge, error) {
+	args := m.Called(ctx, challengeToken)
+	challenge, _ := args.Get(0).(*models.TwoFactorChallenge)
+	return challenge, args.Error(1)
+}
+
+func (m *mockChallengeStorer) ConsumeChallenge(ctx context.Context, challengeToken string) (*models.TwoFactorChallenge, error) {
+	args := m.Called(ctx, challengeToken)
+	challenge, _ := args.Get(0).(*models.TwoFactorChallenge)
+	return challenge, args.Error(1)
+}
+
+type mockVerificationSender struct {
+	mock.Mock
+}
+
+func (m *mockVerificationSender) SendVerification(ctx context.Context, user *models.User) error {
+	args := m.Called(ctx, user)
 	return args.Error(0)
 }
 
-func (m *mockSessionStorer) UserByToken(ctx context.Context, token string) (string, error) {
-	args := m.Called(ctx, token)
+type mockAccessTokens struct {
+	mock.Mock
+}
+
+func (m *mockAccessTokens) NewAccessToken(ctx context.Context, grant *models.AccessGrant) (string, error) {
+	args := m.Called(ctx, grant)
 	return args.String(0), args.Error(1)
 }
 
+func (m *mockAccessTokens) AccessGrant(ctx context.Context, accessToken string) (*models.AccessGrant, error) {
+	args := m.Called(ctx, accessToken)
+	grant, _ := args.Get(0).(*models.AccessGrant)
+	return grant, args.Error(1)
+}
+
+type mockEventPublisher struct {
+	mock.Mock
+}
+
+func (m *mockEventPublisher) Publish(ctx context.Context, userID string, eventType models.EventType, payload any) error {
+	args := m.Called(ctx, userID, eventType, payload)
+	return args.Error(0)
+}
+
+var client = &models.ClientInfo{UserAgent: "test-agent", IP: "10.0.0.1"}
+
 func TestRegister_Success(t *testing.T) {
 	t.Parallel()
 
@@ -66,6 +184,13 @@ func TestRegister_Success(t *testing.T) {
 		mockUserAdder,
 		nil,
 		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		time.Minute,
 	)
 
 	login := "user1"
@@ -76,13 +201,71 @@ func TestRegister_Success(t *testing.T) {
 		return u.Login == login && err == nil
 	})).Return(nil)
 
-	err := service.Register(context.Background(), login, pass)
+	err := service.Register(context.Background(), login, pass, "")
 
 	assert.NoError(t, err)
 
 	mockUserAdder.AssertExpectations(t)
 }
 
+func TestRegister_WithEmailSendsVerification(t *testing.T) {
+	t.Parallel()
+
+	mockUserAdder := new(mockUserAdder)
+	mockVerification := new(mockVerificationSender)
+
+	service := New(
+		slog.Default(),
+		mockUserAdder,
+		nil,
+		nil,
+		nil,
+		nil,
+		mockVerification,
+		nil,
+		nil,
+		nil,
+		time.Minute,
+	)
+
+	isNewUser := mock.MatchedBy(func(u *models.User) bool {
+		return u.Login == "user1" && u.Email == "user1@example.com"
+	})
+
+	mockUserAdder.On("AddUser", mock.Anything, isNewUser).Return(nil)
+	mockVerification.On("SendVerification", mock.Anything, isNewUser).Return(errors.New("smtp down"))
+
+	err := service.Register(context.Background(), "user1", "validPass123!", " User1@Example.com ")
+
+	assert.NoError(t, err)
+	mockVerification.AssertExpectations(t)
+}
+
+func TestRegister_InvalidEmail(t *testing.T) {
+	t.Parallel()
+
+	mockUserAdder := new(mockUserAdder)
+
+	service := New(
+		slog.Default(),
+		mockUserAdder,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		time.Minute,
+	)
+
+	err := service.Register(context.Background(), "user1", "validPass123!", "not-an-email")
+
+	assert.ErrorIs(t, err, models.ErrInvalidEmail)
+	mockUserAdder.AssertNotCalled(t, "AddUser", mock.Anything, mock.Anything)
+}
+
 func TestRegister_InvalidParams(t *testing.T) {
 	t.Parallel()
 
@@ -93,12 +276,19 @@ func TestRegister_InvalidParams(t *testing.T) {
 		mockUserAdder,
 		nil,
 		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		time.Minute,
 	)
 
 	login := "123"
 	pass := "invalid"
 
-	err := service.Register(context.Background(), login, pass)
+	err := service.Register(context.Background(), login, pass, "")
 
 	assert.ErrorIs(t, err, models.ErrInvalidParams)
 }
@@ -113,6 +303,13 @@ func TestRegister_UserExists(t *testing.T) {
 		mockUserAdder,
 		nil,
 		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		time.Minute,
 	)
 
 	login := "user1"
@@ -123,7 +320,7 @@ func TestRegister_UserExists(t *testing.T) {
 		return u.Login == login && err == nil
 	})).Return(models.ErrUserExists)
 
-	err := service.Register(context.Background(), login, pass)
+	err := service.Register(context.Background(), login, pass, "")
 
 	assert.ErrorIs(t, err, models.ErrUserExists)
 
@@ -140,6 +337,13 @@ func TestRegister_OtherErr(t *testing.T) {
 		mockUserAdder,
 		nil,
 		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		time.Minute,
 	)
 
 	login := "user1"
@@ -150,7 +354,7 @@ func TestRegister_OtherErr(t *testing.T) {
 		return u.Login == login && err == nil
 	})).Return(errors.New("some error"))
 
-	err := service.Register(context.Background(), login, pass)
+	err := service.Register(context.Background(), login, pass, "")
 
 	assert.ErrorIs(t, err, models.ErrInternal)
 
@@ -166,6 +370,9 @@ func hash(t *testing.T, password string) []byte {
 func TestLogin_Success(t *testing.T) {
 	t.Parallel()
 
+	limiter := new(mockLoginLimiter)
+	twoFactor := new(mockTwoFactorVerifier)
+
 	mockUserProvider := new(mockUserProvider)
 	mockSessionStorer := new(mockSessionStorer)
 
@@ -174,6 +381,13 @@ func TestLogin_Success(t *testing.T) {
 		nil,
 		mockUserProvider,
 		mockSessionStorer,
+		opaquetokens.New(mockSessionStorer),
+		nil,
+		nil,
+		limiter,
+		twoFactor,
+		nil,
+		time.Minute,
 	)
 
 	user := &models.User{
@@ -186,23 +400,39 @@ func TestLogin_Success(t *testing.T) {
 	pass := "validPass123!"
 
 	mockUserProvider.On("UserByLogin", mock.Anything, login).Return(user, nil)
+	twoFactor.On("Enabled", mock.Anything, user.ID).Return(false, nil)
+	mockSessionStorer.On("SessionVersion", mock.Anything, user.ID).Return(int64(2), nil)
 
 	mockSessionStorer.On("SaveSession",
 		mock.Anything,
-		mock.AnythingOfType("string"),
-		mock.AnythingOfType("string")).Return(nil)
+		mock.MatchedBy(func(session *models.Session) bool {
+			return session.ID != "" && session.UserID == user.ID &&
+				session.UserAgent == "test-agent" && session.IP == "10.0.0.1" && !session.CreatedAt.IsZero()
+		}),
+		mock.MatchedBy(func(tokens *models.TokenPair) bool {
+			return tokens.AccessToken != "" && tokens.RefreshToken != "" && tokens.AccessToken != tokens.RefreshToken
+		}),
+		grantVersion(2)).Return(nil)
 
-	token, err := service.Login(context.Background(), login, pass)
+	limiter.On("Check", mock.Anything, login, "10.0.0.1").Return(nil)
+	limiter.On("Succeed", mock.Anything, login).Return(nil)
+
+	result, err := service.Login(context.Background(), login, pass, client)
 
 	assert.NoError(t, err)
-	assert.NotEmpty(t, token)
+	assert.Empty(t, result.Challenge)
+	assert.NotEmpty(t, result.Tokens.AccessToken)
+	assert.NotEmpty(t, result.Tokens.RefreshToken)
 
 	mockUserProvider.AssertExpectations(t)
+	limiter.AssertExpectations(t)
 }
 
 func TestLogin_UserNotFound(t *testing.T) {
 	t.Parallel()
 
+	limiter := new(mockLoginLimiter)
+
 	mockUserProvider := new(mockUserProvider)
 
 	service := New(
@@ -210,6 +440,13 @@ func TestLogin_UserNotFound(t *testing.T) {
 		nil,
 		mockUserProvider,
 		nil,
+		nil,
+		nil,
+		nil,
+		limiter,
+		nil,
+		nil,
+		time.Minute,
 	)
 
 	login := "user1"
@@ -217,17 +454,23 @@ func TestLogin_UserNotFound(t *testing.T) {
 
 	mockUserProvider.On("UserByLogin", mock.Anything, login).Return((*models.User)(nil), models.ErrUserNotFound)
 
-	token, err := service.Login(context.Background(), login, pass)
+	limiter.On("Check", mock.Anything, login, "10.0.0.1").Return(nil)
+	limiter.On("Fail", mock.Anything, login, "10.0.0.1").Return(nil)
+
+	token, err := service.Login(context.Background(), login, pass, client)
 
-	assert.ErrorIs(t, err, models.ErrUserNotFound)
+	assert.ErrorIs(t, err, models.ErrInvalidCredentials)
 	assert.Empty(t, token)
 
 	mockUserProvider.AssertExpectations(t)
+	limiter.AssertExpectations(t)
 }
 
 func TestLogin_FailedToGetUser(t *testing.T) {
 	t.Parallel()
 
+	limiter := new(mockLoginLimiter)
+
 	mockUserProvider := new(mockUserProvider)
 
 	service := New(
@@ -235,6 +478,13 @@ func TestLogin_FailedToGetUser(t *testing.T) {
 		nil,
 		mockUserProvider,
 		nil,
+		nil,
+		nil,
+		nil,
+		limiter,
+		nil,
+		nil,
+		time.Minute,
 	)
 
 	login := "user1"
@@ -242,17 +492,22 @@ func TestLogin_FailedToGetUser(t *testing.T) {
 
 	mockUserProvider.On("UserByLogin", mock.Anything, login).Return((*models.User)(nil), errors.New("some error"))
 
-	token, err := service.Login(context.Background(), login, pass)
+	limiter.On("Check", mock.Anything, login, "10.0.0.1").Return(nil)
+
+	token, err := service.Login(context.Background(), login, pass, client)
 
 	assert.ErrorIs(t, err, models.ErrInternal)
 	assert.Empty(t, token)
 
 	mockUserProvider.AssertExpectations(t)
+	limiter.AssertExpectations(t)
 }
 
 func TestLogin_InvalidCredentials(t *testing.T) {
 	t.Parallel()
 
+	limiter := new(mockLoginLimiter)
+
 	mockUserProvider := new(mockUserProvider)
 
 	service := New(
@@ -260,6 +515,13 @@ func TestLogin_InvalidCredentials(t *testing.T) {
 		nil,
 		mockUserProvider,
 		nil,
+		nil,
+		nil,
+		nil,
+		limiter,
+		nil,
+		nil,
+		time.Minute,
 	)
 
 	user := &models.User{
@@ -273,166 +535,830 @@ func TestLogin_InvalidCredentials(t *testing.T) {
 
 	mockUserProvider.On("UserByLogin", mock.Anything, login).Return(user, nil)
 
-	token, err := service.Login(context.Background(), login, pass)
+	limiter.On("Check", mock.Anything, login, "10.0.0.1").Return(nil)
+	limiter.On("Fail", mock.Anything, login, "10.0.0.1").Return(nil)
+
+	token, err := service.Login(context.Background(), login, pass, client)
 
 	assert.ErrorIs(t, err, models.ErrInvalidCredentials)
 	assert.Empty(t, token)
 
 	mockUserProvider.AssertExpectations(t)
+	limiter.AssertExpectations(t)
 }
 
-func TestLogin_SaveSessionFails(t *testing.T) {
+func TestLogin_Banned(t *testing.T) {
 	t.Parallel()
 
-	mockUserProvider := new(mockUserProvider)
-	mockSessionStorer := new(mockSessionStorer)
-
-	service := New(
-		slog.Default(),
-		nil,
-		mockUserProvider,
-		mockSessionStorer,
-	)
+	until := time.Now().Add(time.Hour)
 
-	user := &models.User{
-		ID:       "1",
-		Login:    "user1",
-		PassHash: hash(t, "validPass123!"),
+	tests := []struct {
+		name string
+		ban  *models.Ban
+	}{
+		{name: "permanent ban", ban: &models.Ban{Reason: "spam"}},
+		{name: "suspension", ban: &models.Ban{Reason: "spam", Until: &until}},
 	}
 
-	login := "user1"
-	pass := "validPass123!"
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
 
-	mockUserProvider.On("UserByLogin", mock.Anything, login).Return(user, nil)
+			limiter := new(mockLoginLimiter)
 
-	mockSessionStorer.On("SaveSession", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("string")).Return(errors.New("some error"))
+			mockUserProvider := new(mockUserProvider)
 
-	token, err := service.Login(context.Background(), login, pass)
+			// No session storer: a banned user must not get one.
+			service := New(
+				slog.Default(),
+				nil,
+				mockUserProvider,
+				nil,
+				nil,
+				nil,
+				nil,
+				limiter,
+				nil,
+				nil,
+				time.Minute,
+			)
 
-	assert.ErrorIs(t, err, models.ErrInternal)
-	assert.Empty(t, token)
+			pass := "validPass123!"
 
-	mockUserProvider.AssertExpectations(t)
+			user := &models.User{
+				ID:       "1",
+				Login:    "user1",
+				PassHash: hash(t, pass),
+				Ban:      tt.ban,
+			}
+
+			mockUserProvider.On("UserByLogin", mock.Anything, user.Login).Return(user, nil)
+
+			limiter.On("Check", mock.Anything, user.Login, "10.0.0.1").Return(nil)
+
+			result, err := service.Login(context.Background(), user.Login, pass, client)
+
+			assert.ErrorIs(t, err, models.ErrUserBanned)
+			assert.Nil(t, result)
+
+			limiter.AssertExpectations(t)
+		})
+	}
 }
 
-func TestUserByToken_Success(t *testing.T) {
+func TestLogin_Blocked(t *testing.T) {
 	t.Parallel()
 
-	mockSessionStorer := new(mockSessionStorer)
+	mockUserProvider := new(mockUserProvider)
+	limiter := new(mockLoginLimiter)
 
 	service := New(
 		slog.Default(),
 		nil,
+		mockUserProvider,
 		nil,
-		mockSessionStorer,
+		nil,
+		nil,
+		nil,
+		limiter,
+		nil,
+		nil,
+		time.Minute,
 	)
 
-	expUser := &models.User{
-		ID:       "1",
-		Login:    "user1",
-		PassHash: hash(t, "validPass123!"),
-	}
-
-	expUserJSON, _ := json.Marshal(expUser)
-
-	token := uuid.NewV4().String()
-
-	mockSessionStorer.On("UserByToken", mock.Anything, token).Return(string(expUserJSON), nil)
-
-	actualUser, err := service.UserByToken(context.Background(), token)
+	limiter.On("Check", mock.Anything, "user1", "10.0.0.1").
+		Return(&models.RetryAfterError{After: time.Minute, Err: models.ErrLoginBlocked})
 
-	assert.NoError(t, err)
-	assert.Equal(t, *expUser, *actualUser)
+	token, err := service.Login(context.Background(), "user1", "validPass123!", client)
 
-	mockSessionStorer.AssertExpectations(t)
+	var retry *models.RetryAfterError
+	assert.ErrorAs(t, err, &retry)
+	assert.Equal(t, time.Minute, retry.After)
+	assert.Empty(t, token)
+	mockUserProvider.AssertNotCalled(t, "UserByLogin", mock.Anything, mock.Anything)
 }
 
-func TestUserByToken_SessionNotFound(t *testing.T) {
+func TestLogin_UnknownUserTakesAsLongAsWrongPassword(t *testing.T) {
 	t.Parallel()
 
-	mockSessionStorer := new(mockSessionStorer)
+	mockUserProvider := new(mockUserProvider)
+	limiter := new(mockLoginLimiter)
 
 	service := New(
 		slog.Default(),
 		nil,
+		mockUserProvider,
 		nil,
-		mockSessionStorer,
+		nil,
+		nil,
+		nil,
+		limiter,
+		nil,
+		nil,
+		time.Minute,
 	)
 
-	token := uuid.NewV4().String()
+	user := &models.User{ID: "1", Login: "user1", PassHash: hash(t, "validPass123!")}
 
-	mockSessionStorer.On("UserByToken", mock.Anything, token).Return("", models.ErrSessionNotFound)
+	mockUserProvider.On("UserByLogin", mock.Anything, "user1").Return(user, nil)
+	mockUserProvider.On("UserByLogin", mock.Anything, "ghost").Return((*models.User)(nil), models.ErrUserNotFound)
+	limiter.On("Check", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	limiter.On("Fail", mock.Anything, mock.Anything, mock.Anything).Return(nil)
 
-	actualUser, err := service.UserByToken(context.Background(), token)
+	measure := func(login string) time.Duration {
+		start := time.Now()
+		_, err := service.Login(context.Background(), login, "wrongPass123!", client)
+		assert.ErrorIs(t, err, models.ErrInvalidCredentials)
+		return time.Since(start)
+	}
 
-	assert.ErrorIs(t, err, models.ErrInvalidCredentials)
-	assert.Empty(t, actualUser)
+	wrongPassword := measure("user1")
+	unknownUser := measure("ghost")
 
-	mockSessionStorer.AssertExpectations(t)
+	// Both run one bcrypt comparison at the same cost; without it an
+	// unknown login would return in microseconds.
+	assert.Greater(t, unknownUser, wrongPassword/4)
 }
 
-func TestUserByToken_SessionFails(t *testing.T) {
+func TestLogin_TwoFactorChallenge(t *testing.T) {
 	t.Parallel()
 
+	mockUserProvider := new(mockUserProvider)
 	mockSessionStorer := new(mockSessionStorer)
+	limiter := new(mockLoginLimiter)
+	twoFactor := new(mockTwoFactorVerifier)
+	challenges := new(mockChallengeStorer)
 
 	service := New(
 		slog.Default(),
 		nil,
-		nil,
+		mockUserProvider,
 		mockSessionStorer,
+		opaquetokens.New(mockSessionStorer),
+		nil,
+		nil,
+		limiter,
+		twoFactor,
+		challenges,
+		time.Minute,
 	)
 
-	token := uuid.NewV4().String()
-
-	mockSessionStorer.On("UserByToken", mock.Anything, token).Return("", errors.New("some error"))
+	user := &models.User{ID: "1", Login: "user1", PassHash: hash(t, "validPass123!")}
 
-	actualUser, err := service.UserByToken(context.Background(), token)
+	limiter.On("Check", mock.Anything, "user1", "10.0.0.1").Return(nil)
+	mockUserProvider.On("UserByLogin", mock.Anything, "user1").Return(user, nil)
+	twoFactor.On("Enabled", mock.Anything, "1").Return(true, nil)
+	challenges.On("SaveChallenge", mock.Anything, mock.AnythingOfType("string"), mock.MatchedBy(func(challenge *models.TwoFactorChallenge) bool {
+		return challenge.UserID == "1" && challenge.Login == "user1"
+	})).Return(nil)
 
-	assert.ErrorIs(t, err, models.ErrInternal)
-	assert.Empty(t, actualUser)
+	result, err := service.Login(context.Background(), "user1", "validPass123!", client)
 
-	mockSessionStorer.AssertExpectations(t)
+	assert.NoError(t, err)
+	assert.Nil(t, result.Tokens)
+	assert.NotEmpty(t, result.Challenge)
+	challenges.AssertExpectations(t)
+	limiter.AssertNotCalled(t, "Succeed", mock.Anything, mock.Anything)
+	mockSessionStorer.AssertNotCalled(t, "SaveSession", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
 }
 
-func TestLogout_Success(t *testing.T) {
+func TestLoginUser(t *testing.T) {
 	t.Parallel()
 
-	mockSessionStorer := new(mockSessionStorer)
+	user := &models.User{ID: "1", Login: "user1"}
 
-	service := New(
-		slog.Default(),
-		nil,
-		nil,
-		mockSessionStorer,
-	)
+	tests := []struct {
+		name      string
+		twoFactor bool
+	}{
+		{name: "session opened"},
+		{name: "second factor required", twoFactor: true},
+	}
 
-	token := uuid.NewV4().String()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockSessionStorer := new(mockSessionStorer)
+			limiter := new(mockLoginLimiter)
+			twoFactor := new(mockTwoFactorVerifier)
+			challenges := new(mockChallengeStorer)
+
+			service := New(slog.Default(), nil, nil, mockSessionStorer, opaquetokens.New(mockSessionStorer), nil, nil, limiter, twoFactor, challenges, time.Minute)
+
+			twoFactor.On("Enabled", mock.Anything, "1").Return(tt.twoFactor, nil)
+			challenges.On("SaveChallenge", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+			limiter.On("Succeed", mock.Anything, "user1").Return(nil)
+			mockSessionStorer.On("SessionVersion", mock.Anything, "1").Return(int64(0), nil)
+			mockSessionStorer.On("SaveSession", mock.Anything, mock.Anything, mock.Anything, grantVersion(0)).Return(nil)
+
+			result, err := service.LoginUser(context.Background(), user, client)
+
+			assert.NoError(t, err)
+			if tt.twoFactor {
+				assert.NotEmpty(t, result.Challenge)
+				assert.Nil(t, result.Tokens)
+				mockSessionStorer.AssertNotCalled(t, "SaveSession", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+				return
+			}
+			assert.NotEmpty(t, result.Tokens.AccessToken)
+		})
+	}
+}
 
-	mockSessionStorer.On("DeleteSession", mock.Anything, token).Return(nil)
+func TestCompleteTwoFactor(t *testing.T) {
+	t.Parallel()
 
-	err := service.Logout(context.Background(), token)
+	challenge := &models.TwoFactorChallenge{UserID: "1", Login: "user1"}
 
-	assert.NoError(t, err)
+	tests := []struct {
+		name      string
+		verifyErr error
+		wantErr   error
+	}{
+		{name: "success"},
+		{name: "wrong code", verifyErr: models.ErrInvalidTwoFactorCode, wantErr: models.ErrInvalidTwoFactorCode},
+		{name: "turned off", verifyErr: models.ErrTwoFactorNotEnrolled, wantErr: models.ErrInvalidChallenge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockUserProvider := new(mockUserProvider)
+			mockSessionStorer := new(mockSessionStorer)
+			limiter := new(mockLoginLimiter)
+			twoFactor := new(mockTwoFactorVerifier)
+			challenges := new(mockChallengeStorer)
+
+			service := New(
+				slog.Default(),
+				nil,
+				mockUserProvider,
+				mockSessionStorer,
+				opaquetokens.New(mockSessionStorer),
+				nil,
+				nil,
+				limiter,
+				twoFactor,
+				challenges,
+				time.Minute,
+			)
+
+			challenges.On("Challenge", mock.Anything, "challenge-1").Return(challenge, nil)
+			challenges.On("ConsumeChallenge", mock.Anything, "challenge-1").Return(challenge, nil)
+			limiter.On("Check", mock.Anything, "user1", "10.0.0.1").Return(nil)
+			limiter.On("Fail", mock.Anything, "user1", "10.0.0.1").Return(nil)
+			limiter.On("Succeed", mock.Anything, "user1").Return(nil)
+			twoFactor.On("Verify", mock.Anything, "1", "123456").Return(tt.verifyErr)
+			mockUserProvider.On("UserByID", mock.Anything, "1").Return(&models.User{ID: "1", Login: "user1"}, nil)
+			mockSessionStorer.On("SessionVersion", mock.Anything, "1").Return(int64(0), nil)
+			mockSessionStorer.On("SaveSession", mock.Anything, mock.Anything, mock.Anything, grantVersion(0)).Return(nil)
+
+			tokens, err := service.CompleteTwoFactor(context.Background(), "challenge-1", "123456", client)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Nil(t, tokens)
+				if tt.verifyErr == models.ErrInvalidTwoFactorCode {
+					limiter.AssertCalled(t, "Fail", mock.Anything, "user1", "10.0.0.1")
+				}
+				challenges.AssertNotCalled(t, "ConsumeChallenge", mock.Anything, mock.Anything)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.NotEmpty(t, tokens.AccessToken)
+			limiter.AssertCalled(t, "Succeed", mock.Anything, "user1")
+			limiter.AssertNotCalled(t, "Fail", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
 
-	mockSessionStorer.AssertExpectations(t)
 }
 
-func TestLogout_SessionNotFound(t *testing.T) {
+func TestCompleteTwoFactor_UnknownChallenge(t *testing.T) {
 	t.Parallel()
 
-	mockSessionStorer := new(mockSessionStorer)
+	challenges := new(mockChallengeStorer)
 
-	service := New(
-		slog.Default(),
+	service := New(slog.Default(), nil, nil, nil, nil, nil, nil, nil, nil, challenges, time.Minute)
+
+	challenges.On("Challenge", mock.Anything, "unknown").Return(nil, models.ErrInvalidChallenge)
+
+	_, err := service.CompleteTwoFactor(context.Background(), "unknown", "123456", client)
+
+	assert.ErrorIs(t, err, models.ErrInvalidChallenge)
+}
+
+func TestLoginUser_SavesGrantOfAccessToken(t *testing.T) {
+	t.Parallel()
+
+	limiter := new(mockLoginLimiter)
+	twoFactor := new(mockTwoFactorVerifier)
+	mockSessionStorer := new(mockSessionStorer)
+	accessTokens := new(mockAccessTokens)
+
+	service := New(slog.Default(), nil, nil, mockSessionStorer, accessTokens, nil, nil, limiter, twoFactor, nil, time.Minute)
+
+	user := &models.User{ID: "1", Login: "user1"}
+
+	limiter.On("Succeed", mock.Anything, user.Login).Return(nil)
+	twoFactor.On("Enabled", mock.Anything, user.ID).Return(false, nil)
+	mockSessionStorer.On("SessionVersion", mock.Anything, user.ID).Return(int64(4), nil)
+	accessTokens.On("NewAccessToken", mock.Anything, mock.MatchedBy(func(grant *models.AccessGrant) bool {
+		return grant.UserID == "1" && grant.SessionID != "" && grant.Version == 4
+	})).Run(func(args mock.Arguments) {
+		args.Get(1).(*models.AccessGrant).TokenID = "jti-1"
+	}).Return("signed-token", nil)
+	mockSessionStorer.On("SaveSession", mock.Anything, mock.Anything, mock.MatchedBy(func(tokens *models.TokenPair) bool {
+		return tokens.AccessToken == "signed-token"
+	}), mock.MatchedBy(func(grant *models.AccessGrant) bool {
+		return grant.TokenID == "jti-1"
+	})).Return(nil)
+
+	result, err := service.LoginUser(context.Background(), user, client)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "signed-token", result.Tokens.AccessToken)
+	mockSessionStorer.AssertExpectations(t)
+}
+
+func TestLogin_SaveSessionFails(t *testing.T) {
+	t.Parallel()
+
+	limiter := new(mockLoginLimiter)
+	twoFactor := new(mockTwoFactorVerifier)
+
+	mockUserProvider := new(mockUserProvider)
+	mockSessionStorer := new(mockSessionStorer)
+
+	service := New(
+		slog.Default(),
+		nil,
+		mockUserProvider,
+		mockSessionStorer,
+		opaquetokens.New(mockSessionStorer),
+		nil,
+		nil,
+		limiter,
+		twoFactor,
+		nil,
+		time.Minute,
+	)
+
+	user := &models.User{
+		ID:       "1",
+		Login:    "user1",
+		PassHash: hash(t, "validPass123!"),
+	}
+
+	login := "user1"
+	pass := "validPass123!"
+
+	mockUserProvider.On("UserByLogin", mock.Anything, login).Return(user, nil)
+	twoFactor.On("Enabled", mock.Anything, user.ID).Return(false, nil)
+	mockSessionStorer.On("SessionVersion", mock.Anything, user.ID).Return(int64(0), nil)
+	mockSessionStorer.On("SaveSession", mock.Anything, mock.Anything, mock.Anything, grantVersion(0)).Return(errors.New("some error"))
+
+	limiter.On("Check", mock.Anything, login, "10.0.0.1").Return(nil)
+	limiter.On("Succeed", mock.Anything, login).Return(nil)
+
+	token, err := service.Login(context.Background(), login, pass, client)
+
+	assert.ErrorIs(t, err, models.ErrInternal)
+	assert.Empty(t, token)
+
+	mockUserProvider.AssertExpectations(t)
+	limiter.AssertExpectations(t)
+}
+
+func TestRefresh_RotatesInSession(t *testing.T) {
+	t.Parallel()
+
+	createdAt := time.Now().Add(-time.Hour)
+
+	mockUserProvider := new(mockUserProvider)
+	mockSessionStorer := new(mockSessionStorer)
+
+	service := New(
+		slog.Default(),
+		nil,
+		mockUserProvider,
+		mockSessionStorer,
+		opaquetokens.New(mockSessionStorer),
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		time.Minute,
+	)
+
+	mockSessionStorer.On("ConsumeRefreshToken", mock.Anything, "refresh-1").
+		Return(&models.RefreshToken{UserID: "1", SessionID: "session-1", Version: 1}, nil)
+	mockSessionStorer.On("SessionVersion", mock.Anything, "1").Return(int64(1), nil)
+	mockSessionStorer.On("SessionByID", mock.Anything, "session-1").
+		Return(&models.Session{ID: "session-1", UserID: "1", UserAgent: "old-agent", CreatedAt: createdAt, LastSeenAt: createdAt}, nil)
+	mockUserProvider.On("UserByID", mock.Anything, "1").
+		Return(&models.User{ID: "1", Login: "user1"}, nil)
+	mockSessionStorer.On("SaveSession", mock.Anything, mock.MatchedBy(func(session *models.Session) bool {
+		return session.ID == "session-1" && session.UserAgent == "test-agent" &&
+			session.CreatedAt.Equal(createdAt) && session.LastSeenAt.After(createdAt)
+	}), mock.MatchedBy(func(tokens *models.TokenPair) bool {
+		return tokens.RefreshToken != "refresh-1"
+	}), grantVersion(1)).Return(nil)
+
+	tokens, err := service.Refresh(context.Background(), "refresh-1", client)
+
+	assert.NoError(t, err)
+	assert.NotEmpty(t, tokens.AccessToken)
+	assert.NotEqual(t, "refresh-1", tokens.RefreshToken)
+	mockSessionStorer.AssertExpectations(t)
+}
+
+func TestRefresh_ReuseRevokesFamily(t *testing.T) {
+	t.Parallel()
+
+	mockSessionStorer := new(mockSessionStorer)
+	mockEventPublisher := new(mockEventPublisher)
+
+	service := New(
+		slog.Default(),
+		nil,
+		nil,
+		mockSessionStorer,
+		opaquetokens.New(mockSessionStorer),
+		mockEventPublisher,
+		nil,
+		nil,
+		nil,
+		nil,
+		time.Minute,
+	)
+
+	mockSessionStorer.On("ConsumeRefreshToken", mock.Anything, "refresh-1").
+		Return(&models.RefreshToken{UserID: "1", SessionID: "session-1", Used: true}, nil)
+	mockSessionStorer.On("DeleteSessionByID", mock.Anything, "1", "session-1").Return(nil)
+	mockEventPublisher.On("Publish", mock.Anything, "1", models.EventSessionEnded, models.SessionEventPayload{Reason: "refresh_token_reused"}).Return(nil)
+
+	tokens, err := service.Refresh(context.Background(), "refresh-1", client)
+
+	assert.ErrorIs(t, err, models.ErrRefreshTokenReused)
+	assert.Nil(t, tokens)
+	mockSessionStorer.AssertExpectations(t)
+	mockEventPublisher.AssertExpectations(t)
+	mockSessionStorer.AssertNotCalled(t, "SaveSession", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRefresh_UnknownToken(t *testing.T) {
+	t.Parallel()
+
+	mockSessionStorer := new(mockSessionStorer)
+
+	service := New(
+		slog.Default(),
+		nil,
+		nil,
+		mockSessionStorer,
+		opaquetokens.New(mockSessionStorer),
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		time.Minute,
+	)
+
+	mockSessionStorer.On("ConsumeRefreshToken", mock.Anything, "unknown").
+		Return((*models.RefreshToken)(nil), models.ErrSessionNotFound)
+
+	_, err := service.Refresh(context.Background(), "unknown", client)
+
+	assert.ErrorIs(t, err, models.ErrInvalidCredentials)
+}
+
+func TestRefresh_VersionChanged(t *testing.T) {
+	t.Parallel()
+
+	mockSessionStorer := new(mockSessionStorer)
+
+	service := New(
+		slog.Default(),
+		nil,
+		nil,
+		mockSessionStorer,
+		opaquetokens.New(mockSessionStorer),
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		time.Minute,
+	)
+
+	mockSessionStorer.On("ConsumeRefreshToken", mock.Anything, "refresh-1").
+		Return(&models.RefreshToken{UserID: "1", SessionID: "session-1"}, nil)
+	mockSessionStorer.On("SessionVersion", mock.Anything, "1").Return(int64(1), nil)
+
+	tokens, err := service.Refresh(context.Background(), "refresh-1", client)
+
+	assert.ErrorIs(t, err, models.ErrInvalidCredentials)
+	assert.Nil(t, tokens)
+	mockSessionStorer.AssertNotCalled(t, "SaveSession", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRefresh_Banned(t *testing.T) {
+	t.Parallel()
+
+	mockUserProvider := new(mockUserProvider)
+	mockSessionStorer := new(mockSessionStorer)
+
+	service := New(
+		slog.Default(),
+		nil,
+		mockUserProvider,
+		mockSessionStorer,
+		opaquetokens.New(mockSessionStorer),
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		time.Minute,
+	)
+
+	mockSessionStorer.On("ConsumeRefreshToken", mock.Anything, "refresh-1").
+		Return(&models.RefreshToken{UserID: "1", SessionID: "session-1", Version: 1}, nil)
+	mockSessionStorer.On("SessionVersion", mock.Anything, "1").Return(int64(1), nil)
+	mockUserProvider.On("UserByID", mock.Anything, "1").
+		Return(&models.User{ID: "1", Login: "user1", Ban: &models.Ban{Reason: "spam"}}, nil)
+
+	tokens, err := service.Refresh(context.Background(), "refresh-1", client)
+
+	assert.ErrorIs(t, err, models.ErrUserBanned)
+	assert.Nil(t, tokens)
+	mockSessionStorer.AssertNotCalled(t, "SaveSession", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestUserByToken_Success(t *testing.T) {
+	t.Parallel()
+
+	mockUserProvider := new(mockUserProvider)
+	mockSessionStorer := new(mockSessionStorer)
+
+	service := New(
+		slog.Default(),
+		nil,
+		mockUserProvider,
+		mockSessionStorer,
+		opaquetokens.New(mockSessionStorer),
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		time.Minute,
+	)
+
+	expUser := &models.User{
+		ID:    "1",
+		Login: "user1",
+	}
+
+	token := uuid.NewV4().String()
+
+	mockSessionStorer.On("AccessGrant", mock.Anything, token).Return(&models.AccessGrant{UserID: "1", SessionID: "s1", Version: 3}, nil)
+	mockSessionStorer.On("SessionVersion", mock.Anything, "1").Return(int64(3), nil)
+	mockUserProvider.On("UserByID", mock.Anything, "1").Return(expUser, nil).Once()
+
+	for range 2 {
+		actualUser, err := service.UserByToken(context.Background(), token)
+
+		assert.NoError(t, err)
+		assert.Equal(t, expUser, actualUser)
+	}
+
+	mockSessionStorer.AssertExpectations(t)
+	mockUserProvider.AssertExpectations(t)
+}
+
+func TestUserByToken_VersionChanged(t *testing.T) {
+	t.Parallel()
+
+	mockUserProvider := new(mockUserProvider)
+	mockSessionStorer := new(mockSessionStorer)
+
+	service := New(
+		slog.Default(),
+		nil,
+		mockUserProvider,
+		mockSessionStorer,
+		opaquetokens.New(mockSessionStorer),
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		time.Minute,
+	)
+
+	token := uuid.NewV4().String()
+
+	mockSessionStorer.On("AccessGrant", mock.Anything, token).Return(&models.AccessGrant{UserID: "1", SessionID: "s1", Version: 0}, nil)
+	mockSessionStorer.On("SessionVersion", mock.Anything, "1").Return(int64(1), nil)
+
+	actualUser, err := service.UserByToken(context.Background(), token)
+
+	assert.ErrorIs(t, err, models.ErrInvalidCredentials)
+	assert.Nil(t, actualUser)
+	mockUserProvider.AssertNotCalled(t, "UserByID", mock.Anything, mock.Anything)
+}
+
+func TestUserByToken_UserDeleted(t *testing.T) {
+	t.Parallel()
+
+	mockUserProvider := new(mockUserProvider)
+	mockSessionStorer := new(mockSessionStorer)
+
+	service := New(
+		slog.Default(),
+		nil,
+		mockUserProvider,
+		mockSessionStorer,
+		opaquetokens.New(mockSessionStorer),
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		time.Minute,
+	)
+
+	token := uuid.NewV4().String()
+
+	mockSessionStorer.On("AccessGrant", mock.Anything, token).Return(&models.AccessGrant{UserID: "1", SessionID: "s1"}, nil)
+	mockSessionStorer.On("SessionVersion", mock.Anything, "1").Return(int64(0), nil)
+	mockUserProvider.On("UserByID", mock.Anything, "1").Return((*models.User)(nil), models.ErrUserNotFound)
+
+	actualUser, err := service.UserByToken(context.Background(), token)
+
+	assert.ErrorIs(t, err, models.ErrInvalidCredentials)
+	assert.Nil(t, actualUser)
+}
+
+func TestUserByToken_Banned(t *testing.T) {
+	t.Parallel()
+
+	mockUserProvider := new(mockUserProvider)
+	mockSessionStorer := new(mockSessionStorer)
+
+	service := New(
+		slog.Default(),
+		nil,
+		mockUserProvider,
+		mockSessionStorer,
+		opaquetokens.New(mockSessionStorer),
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		time.Minute,
+	)
+
+	until := time.Now().Add(time.Hour)
+	token := uuid.NewV4().String()
+
+	mockSessionStorer.On("AccessGrant", mock.Anything, token).Return(&models.AccessGrant{UserID: "1", SessionID: "s1"}, nil)
+	mockSessionStorer.On("SessionVersion", mock.Anything, "1").Return(int64(0), nil)
+	mockUserProvider.On("UserByID", mock.Anything, "1").
+		Return(&models.User{ID: "1", Login: "user1", Ban: &models.Ban{Reason: "spam", Until: &until}}, nil)
+
+	actualUser, err := service.UserByToken(context.Background(), token)
+
+	assert.ErrorIs(t, err, models.ErrUserBanned)
+	assert.Nil(t, actualUser)
+}
+
+func TestUserByToken_SessionNotFound(t *testing.T) {
+	t.Parallel()
+
+	mockSessionStorer := new(mockSessionStorer)
+
+	service := New(
+		slog.Default(),
+		nil,
+		nil,
+		mockSessionStorer,
+		opaquetokens.New(mockSessionStorer),
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		time.Minute,
+	)
+
+	token := uuid.NewV4().String()
+
+	mockSessionStorer.On("AccessGrant", mock.Anything, token).Return(nil, models.ErrSessionNotFound)
+
+	actualUser, err := service.UserByToken(context.Background(), token)
+
+	assert.ErrorIs(t, err, models.ErrInvalidCredentials)
+	assert.Empty(t, actualUser)
+
+	mockSessionStorer.AssertExpectations(t)
+}
+
+func TestUserByToken_SessionFails(t *testing.T) {
+	t.Parallel()
+
+	mockSessionStorer := new(mockSessionStorer)
+
+	service := New(
+		slog.Default(),
+		nil,
+		nil,
+		mockSessionStorer,
+		opaquetokens.New(mockSessionStorer),
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		time.Minute,
+	)
+
+	token := uuid.NewV4().String()
+
+	mockSessionStorer.On("AccessGrant", mock.Anything, token).Return(nil, errors.New("some error"))
+
+	actualUser, err := service.UserByToken(context.Background(), token)
+
+	assert.ErrorIs(t, err, models.ErrInternal)
+	assert.Empty(t, actualUser)
+
+	mockSessionStorer.AssertExpectations(t)
+}
+
+func TestLogout_Success(t *testing.T) {
+	t.Parallel()
+
+	mockSessionStorer := new(mockSessionStorer)
+	mockEventPublisher := new(mockEventPublisher)
+
+	service := New(
+		slog.Default(),
+		nil,
+		nil,
+		mockSessionStorer,
+		opaquetokens.New(mockSessionStorer),
+		mockEventPublisher,
+		nil,
+		nil,
+		nil,
+		nil,
+		time.Minute,
+	)
+
+	token := uuid.NewV4().String()
+
+	mockSessionStorer.On("AccessGrant", mock.Anything, token).Return(&models.AccessGrant{UserID: "1", SessionID: "s1"}, nil)
+	mockSessionStorer.On("SessionVersion", mock.Anything, "1").Return(int64(0), nil)
+	mockSessionStorer.On("DeleteSessionByID", mock.Anything, "1", "s1").Return(nil)
+	mockEventPublisher.On("Publish", mock.Anything, "1", models.EventSessionEnded, models.SessionEventPayload{Reason: "logout"}).Return(nil)
+
+	err := service.Logout(context.Background(), token)
+
+	assert.NoError(t, err)
+
+	mockSessionStorer.AssertExpectations(t)
+	mockEventPublisher.AssertExpectations(t)
+}
+
+func TestLogout_SessionNotFound(t *testing.T) {
+	t.Parallel()
+
+	mockSessionStorer := new(mockSessionStorer)
+
+	service := New(
+		slog.Default(),
 		nil,
 		nil,
 		mockSessionStorer,
+		opaquetokens.New(mockSessionStorer),
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		time.Minute,
 	)
 
 	token := uuid.NewV4().String()
 
-	mockSessionStorer.On("DeleteSession", mock.Anything, token).Return(models.ErrSessionNotFound)
+	mockSessionStorer.On("AccessGrant", mock.Anything, token).Return(nil, models.ErrSessionNotFound)
 
 	err := service.Logout(context.Background(), token)
 
@@ -451,11 +1377,20 @@ func TestLogout_SessionFails(t *testing.T) {
 		nil,
 		nil,
 		mockSessionStorer,
+		opaquetokens.New(mockSessionStorer),
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		time.Minute,
 	)
 
 	token := uuid.NewV4().String()
 
-	mockSessionStorer.On("DeleteSession", mock.Anything, token).Return(errors.New("some error"))
+	mockSessionStorer.On("AccessGrant", mock.Anything, token).Return(&models.AccessGrant{UserID: "1", SessionID: "s1"}, nil)
+	mockSessionStorer.On("SessionVersion", mock.Anything, "1").Return(int64(0), nil)
+	mockSessionStorer.On("DeleteSessionByID", mock.Anything, "1", "s1").Return(errors.New("some error"))
 
 	err := service.Logout(context.Background(), token)
 
@@ -463,3 +1398,156 @@ func TestLogout_SessionFails(t *testing.T) {
 
 	mockSessionStorer.AssertExpectations(t)
 }
+
+func TestLogout_PublishFailsIgnored(t *testing.T) {
+	t.Parallel()
+
+	mockSessionStorer := new(mockSessionStorer)
+	mockEventPublisher := new(mockEventPublisher)
+
+	service := New(
+		slog.Default(),
+		nil,
+		nil,
+		mockSessionStorer,
+		opaquetokens.New(mockSessionStorer),
+		mockEventPublisher,
+		nil,
+		nil,
+		nil,
+		nil,
+		time.Minute,
+	)
+
+	token := uuid.NewV4().String()
+
+	mockSessionStorer.On("AccessGrant", mock.Anything, token).Return(&models.AccessGrant{UserID: "1", SessionID: "s1"}, nil)
+	mockSessionStorer.On("SessionVersion", mock.Anything, "1").Return(int64(0), nil)
+	mockSessionStorer.On("DeleteSessionByID", mock.Anything, "1", "s1").Return(nil)
+	mockEventPublisher.On("Publish", mock.Anything, "1", models.EventSessionEnded, mock.Anything).Return(errors.New("some error"))
+
+	err := service.Logout(context.Background(), token)
+
+	assert.NoError(t, err)
+
+	mockSessionStorer.AssertExpectations(t)
+	mockEventPublisher.AssertExpectations(t)
+}
+
+func TestSessions_MarksCurrentAndSorts(t *testing.T) {
+	t.Parallel()
+
+	mockSessionStorer := new(mockSessionStorer)
+
+	service := New(
+		slog.Default(),
+		nil,
+		nil,
+		mockSessionStorer,
+		opaquetokens.New(mockSessionStorer),
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		time.Minute,
+	)
+
+	now := time.Now()
+
+	mockSessionStorer.On("SessionsByUser", mock.Anything, "1").Return([]*models.Session{
+		{ID: "old", UserID: "1", LastSeenAt: now.Add(-time.Hour)},
+		{ID: "current", UserID: "1", LastSeenAt: now.Add(-2 * time.Hour)},
+		{ID: "new", UserID: "1", LastSeenAt: now},
+	}, nil)
+	mockSessionStorer.On("AccessGrant", mock.Anything, "access").Return(&models.AccessGrant{UserID: "1", SessionID: "current"}, nil)
+	mockSessionStorer.On("SessionVersion", mock.Anything, "1").Return(int64(0), nil)
+
+	sessions, err := service.Sessions(context.Background(), &models.User{ID: "1"}, "access")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"new", "old", "current"}, []string{sessions[0].ID, sessions[1].ID, sessions[2].ID})
+	assert.True(t, sessions[2].Current)
+	assert.False(t, sessions[0].Current)
+}
+
+func TestRevokeSession(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		session    *models.Session
+		sessionErr error
+		wantErr    error
+	}{
+		{name: "own session", session: &models.Session{ID: "s1", UserID: "1"}},
+		{name: "other user's session", session: &models.Session{ID: "s1", UserID: "2"}, wantErr: models.ErrSessionNotFound},
+		{name: "unknown session", session: (*models.Session)(nil), sessionErr: models.ErrSessionNotFound, wantErr: models.ErrSessionNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockSessionStorer := new(mockSessionStorer)
+			mockEventPublisher := new(mockEventPublisher)
+
+			service := New(
+				slog.Default(),
+				nil,
+				nil,
+				mockSessionStorer,
+				opaquetokens.New(mockSessionStorer),
+				mockEventPublisher,
+				nil,
+				nil,
+				nil,
+				nil,
+				time.Minute,
+			)
+
+			mockSessionStorer.On("SessionByID", mock.Anything, "s1").Return(tt.session, tt.sessionErr)
+			mockSessionStorer.On("DeleteSessionByID", mock.Anything, "1", "s1").Return(nil)
+			mockEventPublisher.On("Publish", mock.Anything, "1", models.EventSessionEnded, mock.Anything).Return(nil)
+
+			err := service.RevokeSession(context.Background(), &models.User{ID: "1"}, "s1")
+
+			assert.ErrorIs(t, err, tt.wantErr)
+			if tt.wantErr == nil {
+				mockSessionStorer.AssertCalled(t, "DeleteSessionByID", mock.Anything, "1", "s1")
+			} else {
+				mockSessionStorer.AssertNotCalled(t, "DeleteSessionByID", mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestRevokeAllSessions(t *testing.T) {
+	t.Parallel()
+
+	mockSessionStorer := new(mockSessionStorer)
+	mockEventPublisher := new(mockEventPublisher)
+
+	service := New(
+		slog.Default(),
+		nil,
+		nil,
+		mockSessionStorer,
+		opaquetokens.New(mockSessionStorer),
+		mockEventPublisher,
+		nil,
+		nil,
+		nil,
+		nil,
+		time.Minute,
+	)
+
+	mockSessionStorer.On("InvalidateUserSessions", mock.Anything, "1").Return(nil)
+	mockEventPublisher.On("Publish", mock.Anything, "1", models.EventSessionEnded, models.SessionEventPayload{Reason: "revoked"}).Return(nil)
+
+	err := service.RevokeAllSessions(context.Background(), &models.User{ID: "1"})
+
+	assert.NoError(t, err)
+	mockSessionStorer.AssertExpectations(t)
+	mockEventPublisher.AssertExpectations(t)
+}
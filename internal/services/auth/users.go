@@ -0,0 +1,71 @@
+package authservice
+
+import (
+	"marketplace/internal/models"
+	"sync"
+	"time"
+)
+
+// userCache keeps users resolved by token for a short time, so that every
+// request does not hit the database. An entry is only used for the session
+// version it was loaded under, so invalidating sessions also drops it.
+type userCache struct {
+	ttl time.Duration
+
+	mu    sync.Mutex
+	users map[string]cachedUser
+	swept time.Time
+}
+
+type cachedUser struct {
+	user      models.User
+	version   int64
+	expiresAt time.Time
+}
+
+func newUserCache(ttl time.Duration) *userCache {
+	return &userCache{ttl: ttl, users: make(map[string]cachedUser)}
+}
+
+func (c *userCache) get(id string, version int64) (*models.User, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.users[id]
+	if !ok || entry.version != version || time.Now().After(entry.expiresAt) {
+		return nil, false
+	}
+
+	user := entry.user
+
+	return &user, true
+}
+
+func (c *userCache) put(user *models.User, version int64) {
+	if c.ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+
+	if now.Sub(c.swept) > c.ttl {
+		for id, entry := range c.users {
+			if now.After(entry.expiresAt) {
+				delete(c.users, id)
+			}
+		}
+		c.swept = now
+	}
+
+	c.users[user.ID] = cachedUser{user: *user, version: version, expiresAt: now.Add(c.ttl)}
+}
+
+func (c *userCache) forget(id string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.users, id)
+}
@@ -0,0 +1,27 @@
+package banservice
+
+import (
+	"context"
+	"marketplace/internal/models"
+)
+
+type UserProvider interface {
+	UserByLogin(ctx context.Context, login string) (*models.User, error)
+}
+
+// BanStorer changes the ban of a user together with the audit record of the
+// change, so neither is written without the other.
+type BanStorer interface {
+	SetBan(ctx context.Context, userID string, ban *models.Ban, record *models.AuditRecord) error
+	ClearBan(ctx context.Context, userID string, record *models.AuditRecord) error
+}
+
+type SessionRevoker interface {
+	RevokeAllSessions(ctx context.Context, requester *models.User) error
+}
+
+// PostCache drops the cached post listings, which would still show or hide
+// the posts of a user whose ban changed.
+type PostCache interface {
+	InvalidateLists(ctx context.Context) error
+}
@@ -0,0 +1,213 @@
+package banservice
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"marketplace/internal/models"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	uuid "github.com/satori/go.uuid"
+)
+
+const pkg = "banService/"
+
+const maxReasonLength = 500
+
+type BanService struct {
+	log      *slog.Logger
+	users    UserProvider
+	bans     BanStorer
+	sessions SessionRevoker
+	posts    PostCache
+	admins   map[string]struct{}
+}
+
+func New(
+	log *slog.Logger,
+	users UserProvider,
+	bans BanStorer,
+	sessions SessionRevoker,
+	posts PostCache,
+	admins []string,
+) *BanService {
+	adminSet := make(map[string]struct{}, len(admins))
+	for _, login := range admins {
+		adminSet[login] = struct{}{}
+	}
+
+	return &BanService{
+		log:      log,
+		users:    users,
+		bans:     bans,
+		sessions: sessions,
+		posts:    posts,
+		admins:   adminSet,
+	}
+}
+
+// Ban bans the user with the login, for good when until is nil and as a
+// suspension otherwise. The user is logged out everywhere and their posts
+// leave the listings at once. Only the admins from the config may ban.
+func (s *BanService) Ban(ctx context.Context, requester *models.User, login string, reason string, until *time.Time) (*models.Ban, error) {
+	op := pkg + "Ban"
+
+	log := s.log.With(slog.String("op", op), slog.String("login", login))
+
+	log.Debug("attempting to ban user")
+
+	if !s.isAdmin(requester) {
+		log.Warn("requester is not an admin", slog.String("requester", requester.Login))
+		return nil, models.ErrForbidden
+	}
+
+	reason, ok := validReason(reason)
+	if !ok {
+		log.Warn("invalid ban reason")
+		return nil, models.ErrInvalidParams
+	}
+
+	now := time.Now().UTC()
+
+	action := models.AuditBan
+	if until != nil {
+		if !until.After(now) {
+			log.Warn("suspension end in the past")
+			return nil, models.ErrInvalidParams
+		}
+		utc := until.UTC()
+		until = &utc
+		action = models.AuditSuspend
+	}
+
+	user, err := s.user(ctx, log, login)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.ID == requester.ID {
+		log.Warn("admin tried to ban themselves")
+		return nil, models.ErrInvalidParams
+	}
+
+	ban := &models.Ban{
+		Reason:   reason,
+		Until:    until,
+		BannedBy: requester.ID,
+		BannedAt: now,
+	}
+
+	record := &models.AuditRecord{
+		ID:        uuid.NewV4().String(),
+		ActorID:   requester.ID,
+		TargetID:  user.ID,
+		Action:    action,
+		Reason:    reason,
+		ExpiresAt: until,
+		CreatedAt: now,
+	}
+
+	if err := s.bans.SetBan(ctx, user.ID, ban, record); err != nil {
+		if errors.Is(err, models.ErrUserNotFound) {
+			log.Warn("user not found")
+			return nil, models.ErrUserNotFound
+		}
+		log.Error("failed to ban user", slog.String("error", err.Error()))
+		return nil, models.ErrInternal
+	}
+
+	s.invalidatePosts(ctx, log)
+
+	if err := s.sessions.RevokeAllSessions(ctx, user); err != nil {
+		log.Error("failed to revoke sessions of banned user", slog.String("error", err.Error()))
+		return nil, models.ErrInternal
+	}
+
+	log.Info("user banned", slog.String("action", string(action)), slog.String("by", requester.Login))
+
+	return ban, nil
+}
+
+// Unban lifts the ban of the user with the login. Only the admins from the
+// config may unban.
+func (s *BanService) Unban(ctx context.Context, requester *models.User, login string, reason string) error {
+	op := pkg + "Unban"
+
+	log := s.log.With(slog.String("op", op), slog.String("login", login))
+
+	log.Debug("attempting to unban user")
+
+	if !s.isAdmin(requester) {
+		log.Warn("requester is not an admin", slog.String("requester", requester.Login))
+		return models.ErrForbidden
+	}
+
+	reason, ok := validReason(reason)
+	if !ok {
+		log.Warn("invalid unban reason")
+		return models.ErrInvalidParams
+	}
+
+	user, err := s.user(ctx, log, login)
+	if err != nil {
+		return err
+	}
+
+	record := &models.AuditRecord{
+		ID:        uuid.NewV4().String(),
+		ActorID:   requester.ID,
+		TargetID:  user.ID,
+		Action:    models.AuditUnban,
+		Reason:    reason,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	if err := s.bans.ClearBan(ctx, user.ID, record); err != nil {
+		if errors.Is(err, models.ErrUserNotFound) {
+			log.Warn("user not found")
+			return models.ErrUserNotFound
+		}
+		log.Error("failed to unban user", slog.String("error", err.Error()))
+		return models.ErrInternal
+	}
+
+	s.invalidatePosts(ctx, log)
+
+	log.Info("user unbanned", slog.String("by", requester.Login))
+
+	return nil
+}
+
+func (s *BanService) user(ctx context.Context, log *slog.Logger, login string) (*models.User, error) {
+	user, err := s.users.UserByLogin(ctx, login)
+	if err != nil {
+		if errors.Is(err, models.ErrUserNotFound) {
+			log.Warn("user not found")
+			return nil, models.ErrUserNotFound
+		}
+		log.Error("failed to get user", slog.String("error", err.Error()))
+		return nil, models.ErrInternal
+	}
+
+	return user, nil
+}
+
+// invalidatePosts drops the cached listings after a ban changed. They expire
+// on their own if this fails.
+func (s *BanService) invalidatePosts(ctx context.Context, log *slog.Logger) {
+	if err := s.posts.InvalidateLists(ctx); err != nil {
+		log.Warn("failed to invalidate cached posts", slog.String("error", err.Error()))
+	}
+}
+
+func (s *BanService) isAdmin(user *models.User) bool {
+	_, ok := s.admins[user.Login]
+	return ok
+}
+
+func validReason(reason string) (string, bool) {
+	reason = strings.TrimSpace(reason)
+	return reason, reason != "" && utf8.RuneCountInString(reason) <= maxReasonLength
+}
@@ -0,0 +1,206 @@
+package banservice
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"marketplace/internal/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockUserProvider struct {
+	mock.Mock
+}
+
+func (m *mockUserProvider) UserByLogin(ctx context.Context, login string) (*models.User, error) {
+	args := m.Called(ctx, login)
+	user, _ := args.Get(0).(*models.User)
+	return user, args.Error(1)
+}
+
+type mockBanStorer struct {
+	mock.Mock
+}
+
+func (m *mockBanStorer) SetBan(ctx context.Context, userID string, ban *models.Ban, record *models.AuditRecord) error {
+	return m.Called(ctx, userID, ban, record).Error(0)
+}
+
+func (m *mockBanStorer) ClearBan(ctx context.Context, userID string, record *models.AuditRecord) error {
+	return m.Called(ctx, userID, record).Error(0)
+}
+
+type mockSessionRevoker struct {
+	mock.Mock
+}
+
+func (m *mockSessionRevoker) RevokeAllSessions(ctx context.Context, requester *models.User) error {
+	return m.Called(ctx, requester).Error(0)
+}
+
+type mockPostCache struct {
+	mock.Mock
+}
+
+func (m *mockPostCache) InvalidateLists(ctx context.Context) error {
+	return m.Called(ctx).Error(0)
+}
+
+var (
+	admin  = &models.User{ID: "a1", Login: "root"}
+	target = &models.User{ID: "u1", Login: "spammer"}
+)
+
+func newService(users *mockUserProvider, bans *mockBanStorer, sessions *mockSessionRevoker, posts *mockPostCache) *BanService {
+	return New(slog.New(slog.NewTextHandler(io.Discard, nil)), users, bans, sessions, posts, []string{"root"})
+}
+
+func TestBan_Permanent(t *testing.T) {
+	t.Parallel()
+
+	users := new(mockUserProvider)
+	bans := new(mockBanStorer)
+	sessions := new(mockSessionRevoker)
+
+	users.On("UserByLogin", mock.Anything, "spammer").Return(target, nil)
+	bans.On("SetBan", mock.Anything, "u1",
+		mock.MatchedBy(func(ban *models.Ban) bool {
+			return ban.Reason == "spam" && ban.Until == nil && ban.BannedBy == "a1"
+		}),
+		mock.MatchedBy(func(record *models.AuditRecord) bool {
+			return record.ActorID == "a1" && record.TargetID == "u1" && record.Action == models.AuditBan && record.Reason == "spam"
+		})).Return(nil)
+	sessions.On("RevokeAllSessions", mock.Anything, target).Return(nil)
+	posts := new(mockPostCache)
+	posts.On("InvalidateLists", mock.Anything).Return(nil)
+
+	ban, err := newService(users, bans, sessions, posts).Ban(context.Background(), admin, "spammer", "  spam ", nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "spam", ban.Reason)
+	bans.AssertExpectations(t)
+	sessions.AssertExpectations(t)
+	posts.AssertExpectations(t)
+}
+
+func TestBan_Suspension(t *testing.T) {
+	t.Parallel()
+
+	users := new(mockUserProvider)
+	bans := new(mockBanStorer)
+	sessions := new(mockSessionRevoker)
+
+	until := time.Now().Add(24 * time.Hour)
+
+	users.On("UserByLogin", mock.Anything, "spammer").Return(target, nil)
+	bans.On("SetBan", mock.Anything, "u1",
+		mock.MatchedBy(func(ban *models.Ban) bool { return ban.Until != nil && ban.Until.Equal(until) }),
+		mock.MatchedBy(func(record *models.AuditRecord) bool {
+			return record.Action == models.AuditSuspend && record.ExpiresAt != nil && record.ExpiresAt.Equal(until)
+		})).Return(nil)
+	sessions.On("RevokeAllSessions", mock.Anything, target).Return(nil)
+
+	posts := new(mockPostCache)
+	posts.On("InvalidateLists", mock.Anything).Return(nil)
+
+	_, err := newService(users, bans, sessions, posts).Ban(context.Background(), admin, "spammer", "spam", &until)
+
+	assert.NoError(t, err)
+	bans.AssertExpectations(t)
+	sessions.AssertExpectations(t)
+}
+
+func TestBan_Rejects(t *testing.T) {
+	t.Parallel()
+
+	past := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name      string
+		requester *models.User
+		login     string
+		reason    string
+		until     *time.Time
+		wantErr   error
+	}{
+		{name: "not an admin", requester: target, login: "spammer", reason: "spam", wantErr: models.ErrForbidden},
+		{name: "admin by role only", requester: &models.User{ID: "a2", Login: "boss", Role: models.RoleAdmin}, login: "spammer", reason: "spam", wantErr: models.ErrForbidden},
+		{name: "empty reason", requester: admin, login: "spammer", reason: "  ", wantErr: models.ErrInvalidParams},
+		{name: "end in the past", requester: admin, login: "spammer", reason: "spam", until: &past, wantErr: models.ErrInvalidParams},
+		{name: "own account", requester: admin, login: "root", reason: "spam", wantErr: models.ErrInvalidParams},
+		{name: "unknown user", requester: admin, login: "ghost", reason: "spam", wantErr: models.ErrUserNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			users := new(mockUserProvider)
+			bans := new(mockBanStorer)
+			sessions := new(mockSessionRevoker)
+
+			users.On("UserByLogin", mock.Anything, "root").Return(admin, nil).Maybe()
+			users.On("UserByLogin", mock.Anything, "ghost").Return(nil, models.ErrUserNotFound).Maybe()
+
+			_, err := newService(users, bans, sessions, nil).Ban(context.Background(), tt.requester, tt.login, tt.reason, tt.until)
+
+			assert.ErrorIs(t, err, tt.wantErr)
+			bans.AssertNotCalled(t, "SetBan", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			sessions.AssertNotCalled(t, "RevokeAllSessions", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestBan_RevokeFails(t *testing.T) {
+	t.Parallel()
+
+	users := new(mockUserProvider)
+	bans := new(mockBanStorer)
+	sessions := new(mockSessionRevoker)
+
+	users.On("UserByLogin", mock.Anything, "spammer").Return(target, nil)
+	bans.On("SetBan", mock.Anything, "u1", mock.Anything, mock.Anything).Return(nil)
+	sessions.On("RevokeAllSessions", mock.Anything, target).Return(errors.New("redis down"))
+	posts := new(mockPostCache)
+	posts.On("InvalidateLists", mock.Anything).Return(nil)
+
+	_, err := newService(users, bans, sessions, posts).Ban(context.Background(), admin, "spammer", "spam", nil)
+
+	assert.ErrorIs(t, err, models.ErrInternal)
+}
+
+func TestUnban(t *testing.T) {
+	t.Parallel()
+
+	users := new(mockUserProvider)
+	bans := new(mockBanStorer)
+
+	users.On("UserByLogin", mock.Anything, "spammer").Return(target, nil)
+	bans.On("ClearBan", mock.Anything, "u1", mock.MatchedBy(func(record *models.AuditRecord) bool {
+		return record.ActorID == "a1" && record.Action == models.AuditUnban && record.Reason == "appeal accepted"
+	})).Return(nil)
+	posts := new(mockPostCache)
+	posts.On("InvalidateLists", mock.Anything).Return(nil)
+
+	err := newService(users, bans, nil, posts).Unban(context.Background(), admin, "spammer", "appeal accepted")
+
+	assert.NoError(t, err)
+	bans.AssertExpectations(t)
+	posts.AssertExpectations(t)
+}
+
+func TestUnban_NotAdmin(t *testing.T) {
+	t.Parallel()
+
+	bans := new(mockBanStorer)
+
+	err := newService(nil, bans, nil, nil).Unban(context.Background(), target, "spammer", "appeal accepted")
+
+	assert.ErrorIs(t, err, models.ErrForbidden)
+	bans.AssertNotCalled(t, "ClearBan", mock.Anything, mock.Anything, mock.Anything)
+}
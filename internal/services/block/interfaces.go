@@ -0,0 +1,21 @@
+package blockservice
+
+import (
+	"context"
+	"marketplace/internal/models"
+	"time"
+)
+
+type UserProvider interface {
+	UserByLogin(ctx context.Context, login string) (*models.User, error)
+}
+
+type BlockStorer interface {
+	AddBlock(ctx context.Context, blockerID string, blockedID string, createdAt time.Time) error
+	DeleteBlock(ctx context.Context, blockerID string, blockedID string) error
+}
+
+// BlockCache keeps the block lists that post listings read.
+type BlockCache interface {
+	DeleteBlockedIDs(ctx context.Context, blockerID string) error
+}
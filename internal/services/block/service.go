@@ -0,0 +1,110 @@
+package blockservice
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"marketplace/internal/models"
+	"time"
+)
+
+const pkg = "blockService/"
+
+type BlockService struct {
+	log    *slog.Logger
+	users  UserProvider
+	blocks BlockStorer
+	cache  BlockCache
+}
+
+func New(
+	log *slog.Logger,
+	users UserProvider,
+	blocks BlockStorer,
+	cache BlockCache,
+) *BlockService {
+	return &BlockService{
+		log:    log,
+		users:  users,
+		blocks: blocks,
+		cache:  cache,
+	}
+}
+
+// Block hides the posts of the user with the login from the requester.
+// Blocking a user again changes nothing, so a failed request can be retried
+// until the cached block list is dropped too.
+func (s *BlockService) Block(ctx context.Context, requester *models.User, login string) error {
+	op := pkg + "Block"
+
+	log := s.log.With(slog.String("op", op), slog.String("user_id", requester.ID))
+
+	log.Debug("attempting to block user")
+
+	blocked, err := s.user(ctx, log, login)
+	if err != nil {
+		return err
+	}
+
+	if blocked.ID == requester.ID {
+		log.Warn("user tried to block themselves")
+		return models.ErrInvalidParams
+	}
+
+	if err := s.blocks.AddBlock(ctx, requester.ID, blocked.ID, time.Now().UTC()); err != nil {
+		log.Error("failed to block user", slog.String("error", err.Error()))
+		return models.ErrInternal
+	}
+
+	if err := s.cache.DeleteBlockedIDs(ctx, requester.ID); err != nil {
+		log.Error("failed to drop cached block list", slog.String("error", err.Error()))
+		return models.ErrInternal
+	}
+
+	log.Debug("user blocked successfully", slog.String("blocked_id", blocked.ID))
+
+	return nil
+}
+
+// Unblock shows the posts of the user with the login to the requester again.
+// Unblocking a user that is not blocked changes nothing.
+func (s *BlockService) Unblock(ctx context.Context, requester *models.User, login string) error {
+	op := pkg + "Unblock"
+
+	log := s.log.With(slog.String("op", op), slog.String("user_id", requester.ID))
+
+	log.Debug("attempting to unblock user")
+
+	blocked, err := s.user(ctx, log, login)
+	if err != nil {
+		return err
+	}
+
+	if err := s.blocks.DeleteBlock(ctx, requester.ID, blocked.ID); err != nil {
+		log.Error("failed to unblock user", slog.String("error", err.Error()))
+		return models.ErrInternal
+	}
+
+	if err := s.cache.DeleteBlockedIDs(ctx, requester.ID); err != nil {
+		log.Error("failed to drop cached block list", slog.String("error", err.Error()))
+		return models.ErrInternal
+	}
+
+	log.Debug("user unblocked successfully", slog.String("blocked_id", blocked.ID))
+
+	return nil
+}
+
+func (s *BlockService) user(ctx context.Context, log *slog.Logger, login string) (*models.User, error) {
+	user, err := s.users.UserByLogin(ctx, login)
+	if err != nil {
+		if errors.Is(err, models.ErrUserNotFound) {
+			log.Warn("user not found", slog.String("login", login))
+			return nil, models.ErrUserNotFound
+		}
+		log.Error("failed to get user", slog.String("error", err.Error()))
+		return nil, models.ErrInternal
+	}
+
+	return user, nil
+}
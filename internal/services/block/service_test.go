@@ -0,0 +1,118 @@
+package blockservice
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"marketplace/internal/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockUserProvider struct {
+	mock.Mock
+}
+
+func (m *mockUserProvider) UserByLogin(ctx context.Context, login string) (*models.User, error) {
+	args := m.Called(ctx, login)
+	user, _ := args.Get(0).(*models.User)
+	return user, args.Error(1)
+}
+
+type mockBlockStorer struct {
+	mock.Mock
+}
+
+func (m *mockBlockStorer) AddBlock(ctx context.Context, blockerID string, blockedID string, createdAt time.Time) error {
+	return m.Called(ctx, blockerID, blockedID, createdAt).Error(0)
+}
+
+func (m *mockBlockStorer) DeleteBlock(ctx context.Context, blockerID string, blockedID string) error {
+	return m.Called(ctx, blockerID, blockedID).Error(0)
+}
+
+type mockBlockCache struct {
+	mock.Mock
+}
+
+func (m *mockBlockCache) DeleteBlockedIDs(ctx context.Context, blockerID string) error {
+	return m.Called(ctx, blockerID).Error(0)
+}
+
+var requester = &models.User{ID: "u1", Login: "alice"}
+
+func newService(users *mockUserProvider, blocks *mockBlockStorer, cache *mockBlockCache) *BlockService {
+	return New(slog.New(slog.NewTextHandler(io.Discard, nil)), users, blocks, cache)
+}
+
+func TestBlock(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		login    string
+		user     *models.User
+		userErr  error
+		storeErr error
+		cacheErr error
+		wantCall bool
+		wantDrop bool
+		wantErr  error
+	}{
+		{name: "success", login: "bob", user: &models.User{ID: "u2", Login: "bob"}, wantCall: true, wantDrop: true},
+		{name: "self", login: "alice", user: requester, wantErr: models.ErrInvalidParams},
+		{name: "unknown user", login: "ghost", userErr: models.ErrUserNotFound, wantErr: models.ErrUserNotFound},
+		{name: "store fails", login: "bob", user: &models.User{ID: "u2", Login: "bob"}, storeErr: errors.New("db down"), wantCall: true, wantErr: models.ErrInternal},
+		{name: "cache fails", login: "bob", user: &models.User{ID: "u2", Login: "bob"}, cacheErr: errors.New("redis down"), wantCall: true, wantDrop: true, wantErr: models.ErrInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			users := new(mockUserProvider)
+			blocks := new(mockBlockStorer)
+			cache := new(mockBlockCache)
+
+			users.On("UserByLogin", mock.Anything, tt.login).Return(tt.user, tt.userErr)
+			if tt.wantCall {
+				blocks.On("AddBlock", mock.Anything, "u1", "u2", mock.Anything).Return(tt.storeErr)
+			}
+			if tt.wantDrop {
+				cache.On("DeleteBlockedIDs", mock.Anything, "u1").Return(tt.cacheErr)
+			}
+
+			err := newService(users, blocks, cache).Block(context.Background(), requester, tt.login)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			blocks.AssertExpectations(t)
+			cache.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUnblock(t *testing.T) {
+	t.Parallel()
+
+	users := new(mockUserProvider)
+	blocks := new(mockBlockStorer)
+	cache := new(mockBlockCache)
+
+	users.On("UserByLogin", mock.Anything, "bob").Return(&models.User{ID: "u2", Login: "bob"}, nil)
+	blocks.On("DeleteBlock", mock.Anything, "u1", "u2").Return(nil)
+	cache.On("DeleteBlockedIDs", mock.Anything, "u1").Return(nil)
+
+	err := newService(users, blocks, cache).Unblock(context.Background(), requester, "bob")
+
+	assert.NoError(t, err)
+	blocks.AssertExpectations(t)
+	cache.AssertExpectations(t)
+}
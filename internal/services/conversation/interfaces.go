@@ -0,0 +1,31 @@
+package conversationservice
+
+import (
+	"context"
+	"marketplace/internal/models"
+	"time"
+)
+
+type ConversationAdder interface {
+	AddConversation(ctx context.Context, conversation *models.Conversation) error
+}
+
+type ConversationProvider interface {
+	ConversationByID(ctx context.Context, id string) (*models.Conversation, error)
+	ConversationByPostAndBuyer(ctx context.Context, postID string, buyerID string) (*models.Conversation, error)
+	ConversationsByUser(ctx context.Context, userID string, limit int, offset int) ([]*models.Conversation, error)
+}
+
+type MessageStorer interface {
+	AddMessage(ctx context.Context, message *models.Message) error
+	Messages(ctx context.Context, conversationID string, before *models.MessageCursor, limit int) ([]*models.Message, error)
+	MarkRead(ctx context.Context, conversationID string, readerID string, readAt time.Time) error
+}
+
+type PostProvider interface {
+	PostByID(ctx context.Context, id string) (*models.PostWithDocument, error)
+}
+
+type Notifier interface {
+	Notify(ctx context.Context, userID string, kind models.NotificationKind, payload models.NotificationPayload) error
+}
@@ -0,0 +1,282 @@
+package conversationservice
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"marketplace/internal/models"
+	"marketplace/internal/utils/mapper"
+	"marketplace/internal/utils/validator"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+)
+
+const pkg = "conversationService/"
+
+type ConversationService struct {
+	log                  *slog.Logger
+	conversationAdder    ConversationAdder
+	conversationProvider ConversationProvider
+	messageStorer        MessageStorer
+	postProvider         PostProvider
+	notifier             Notifier
+}
+
+func New(
+	log *slog.Logger,
+	conversationAdder ConversationAdder,
+	conversationProvider ConversationProvider,
+	messageStorer MessageStorer,
+	postProvider PostProvider,
+	notifier Notifier,
+) *ConversationService {
+	return &ConversationService{
+		log:                  log,
+		conversationAdder:    conversationAdder,
+		conversationProvider: conversationProvider,
+		messageStorer:        messageStorer,
+		postProvider:         postProvider,
+		notifier:             notifier,
+	}
+}
+
+// StartConversation opens a conversation of the requester with the seller of
+// the post and sends the first message. When the requester has already asked
+// about the post, the message goes to the existing conversation.
+func (cs *ConversationService) StartConversation(ctx context.Context, requester *models.User, postID string, text string) (*models.Conversation, error) {
+	op := pkg + "StartConversation"
+
+	log := cs.log.With(slog.String("op", op))
+
+	log.Debug("attempting to start conversation")
+
+	if err := validator.ValidateMessage(text); err != nil {
+		log.Warn("invalid message received", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	if _, err := uuid.FromString(postID); err != nil {
+		log.Warn("invalid post id received", slog.String("post_id", postID))
+		return nil, models.ErrPostNotFound
+	}
+
+	post, err := cs.postProvider.PostByID(ctx, postID)
+	if err != nil {
+		if errors.Is(err, models.ErrPostNotFound) {
+			log.Warn("post not found", slog.String("post_id", postID))
+			return nil, models.ErrPostNotFound
+		}
+		log.Error("failed to get post", slog.String("error", err.Error()))
+		return nil, models.ErrInternal
+	}
+
+	if post.OwnerID == requester.ID {
+		log.Warn("seller tried to open conversation about own post", slog.String("post_id", postID))
+		return nil, models.ErrOwnPost
+	}
+
+	conversation, err := cs.conversationProvider.ConversationByPostAndBuyer(ctx, postID, requester.ID)
+	if err != nil && !errors.Is(err, models.ErrConversationNotFound) {
+		log.Error("failed to get conversation", slog.String("error", err.Error()))
+		return nil, models.ErrInternal
+	}
+
+	if conversation == nil {
+		now := time.Now()
+
+		conversation = &models.Conversation{
+			ID:            uuid.NewV4().String(),
+			PostID:        post.ID,
+			PostHeader:    post.Header,
+			BuyerID:       requester.ID,
+			BuyerLogin:    requester.Login,
+			SellerID:      post.OwnerID,
+			SellerLogin:   post.OwnerLogin,
+			CreatedAt:     now,
+			LastMessageAt: now,
+		}
+
+		err = cs.conversationAdder.AddConversation(ctx, conversation)
+		if err != nil {
+			var uce *models.UniqueConstraintError
+			if !errors.As(err, &uce) {
+				log.Error("failed to add conversation", slog.String("error", err.Error()))
+				return nil, models.ErrInternal
+			}
+
+			// A concurrent request has opened the conversation first.
+			conversation, err = cs.conversationProvider.ConversationByPostAndBuyer(ctx, postID, requester.ID)
+			if err != nil {
+				log.Error("failed to get conversation", slog.String("error", err.Error()))
+				return nil, models.ErrInternal
+			}
+		}
+	}
+
+	message, err := cs.addMessage(ctx, log, requester, conversation, text)
+	if err != nil {
+		return nil, err
+	}
+
+	conversation.LastMessageAt = message.CreatedAt
+
+	log.Debug("conversation started successfully", slog.String("conversation_id", conversation.ID))
+
+	return conversation, nil
+}
+
+func (cs *ConversationService) Conversations(ctx context.Context, requester *models.User, limit int, offset int) ([]*models.Conversation, error) {
+	op := pkg + "Conversations"
+
+	log := cs.log.With(slog.String("op", op))
+
+	log.Debug("attempting to get conversations")
+
+	conversations, err := cs.conversationProvider.ConversationsByUser(ctx, requester.ID, limit, offset)
+	if err != nil {
+		log.Error("failed to get conversations", slog.String("error", err.Error()))
+		return nil, models.ErrInternal
+	}
+
+	log.Debug("conversations found successfully", slog.Int("count", len(conversations)))
+
+	return conversations, nil
+}
+
+// Messages returns a page of the conversation history, newest first, and the
+// cursor of the next page, which is empty on the last page. Messages received
+// by the requester are marked as read.
+func (cs *ConversationService) Messages(ctx context.Context, requester *models.User, conversationID string, cursor string, limit int) ([]*models.Message, string, error) {
+	op := pkg + "Messages"
+
+	log := cs.log.With(slog.String("op", op))
+
+	log.Debug("attempting to get messages")
+
+	var before *models.MessageCursor
+
+	if cursor != "" {
+		var err error
+
+		before, err = mapper.StringToCursor(cursor)
+		if err != nil {
+			log.Warn("invalid cursor received", slog.String("cursor", cursor))
+			return nil, "", models.ErrInvalidCursor
+		}
+
+		if _, err := uuid.FromString(before.ID); err != nil {
+			log.Warn("invalid cursor received", slog.String("cursor", cursor))
+			return nil, "", models.ErrInvalidCursor
+		}
+	}
+
+	conversation, err := cs.participantConversation(ctx, log, requester, conversationID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	messages, err := cs.messageStorer.Messages(ctx, conversation.ID, before, limit)
+	if err != nil {
+		log.Error("failed to get messages", slog.String("error", err.Error()))
+		return nil, "", models.ErrInternal
+	}
+
+	err = cs.messageStorer.MarkRead(ctx, conversation.ID, requester.ID, time.Now())
+	if err != nil {
+		log.Error("failed to mark messages as read", slog.String("error", err.Error()))
+		return nil, "", models.ErrInternal
+	}
+
+	var next string
+
+	if len(messages) == limit && limit > 0 {
+		last := messages[len(messages)-1]
+		next = mapper.CursorToString(&models.MessageCursor{CreatedAt: last.CreatedAt, ID: last.ID})
+	}
+
+	log.Debug("messages found successfully", slog.Int("count", len(messages)))
+
+	return messages, next, nil
+}
+
+func (cs *ConversationService) SendMessage(ctx context.Context, requester *models.User, conversationID string, text string) (*models.Message, error) {
+	op := pkg + "SendMessage"
+
+	log := cs.log.With(slog.String("op", op))
+
+	log.Debug("attempting to send message")
+
+	if err := validator.ValidateMessage(text); err != nil {
+		log.Warn("invalid message received", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	conversation, err := cs.participantConversation(ctx, log, requester, conversationID)
+	if err != nil {
+		return nil, err
+	}
+
+	message, err := cs.addMessage(ctx, log, requester, conversation, text)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Debug("message sent successfully", slog.String("message_id", message.ID))
+
+	return message, nil
+}
+
+func (cs *ConversationService) addMessage(ctx context.Context, log *slog.Logger, requester *models.User, conversation *models.Conversation, text string) (*models.Message, error) {
+	message := &models.Message{
+		ID:             uuid.NewV4().String(),
+		ConversationID: conversation.ID,
+		SenderID:       requester.ID,
+		SenderLogin:    requester.Login,
+		Text:           text,
+		CreatedAt:      time.Now(),
+	}
+
+	err := cs.messageStorer.AddMessage(ctx, message)
+	if err != nil {
+		log.Error("failed to add message", slog.String("error", err.Error()))
+		return nil, models.ErrInternal
+	}
+
+	err = cs.notifier.Notify(ctx, conversation.Peer(requester.ID), models.NotificationNewMessage, models.NewMessagePayload{
+		ConversationID: conversation.ID,
+		MessageID:      message.ID,
+		SenderLogin:    requester.Login,
+	})
+	if err != nil {
+		log.Warn("failed to notify recipient", slog.String("error", err.Error()))
+	}
+
+	return message, nil
+}
+
+// participantConversation hides conversations of other users behind
+// ErrConversationNotFound, so their existence is not revealed.
+func (cs *ConversationService) participantConversation(ctx context.Context, log *slog.Logger, requester *models.User, id string) (*models.Conversation, error) {
+	if _, err := uuid.FromString(id); err != nil {
+		log.Warn("invalid conversation id received", slog.String("conversation_id", id))
+		return nil, models.ErrConversationNotFound
+	}
+
+	conversation, err := cs.conversationProvider.ConversationByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, models.ErrConversationNotFound) {
+			log.Warn("conversation not found", slog.String("conversation_id", id))
+			return nil, models.ErrConversationNotFound
+		}
+		log.Error("failed to get conversation", slog.String("error", err.Error()))
+		return nil, models.ErrInternal
+	}
+
+	if !conversation.HasParticipant(requester.ID) {
+		log.Warn("conversation belongs to other users", slog.String("conversation_id", id))
+		return nil, models.ErrConversationNotFound
+	}
+
+	return conversation, nil
+}
@@ -0,0 +1,293 @@
+package conversationservice
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"marketplace/internal/models"
+	"marketplace/internal/utils/mapper"
+	"testing"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockConversationAdder struct {
+	mock.Mock
+}
+
+func (m *mockConversationAdder) AddConversation(ctx context.Context, conversation *models.Conversation) error {
+	args := m.Called(ctx, conversation)
+	return args.Error(0)
+}
+
+type mockConversationProvider struct {
+	mock.Mock
+}
+
+func (m *mockConversationProvider) ConversationByID(ctx context.Context, id string) (*models.Conversation, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*models.Conversation), args.Error(1)
+}
+
+func (m *mockConversationProvider) ConversationByPostAndBuyer(ctx context.Context, postID string, buyerID string) (*models.Conversation, error) {
+	args := m.Called(ctx, postID, buyerID)
+	return args.Get(0).(*models.Conversation), args.Error(1)
+}
+
+func (m *mockConversationProvider) ConversationsByUser(ctx context.Context, userID string, limit int, offset int) ([]*models.Conversation, error) {
+	args := m.Called(ctx, userID, limit, offset)
+	return args.Get(0).([]*models.Conversation), args.Error(1)
+}
+
+type mockMessageStorer struct {
+	mock.Mock
+}
+
+func (m *mockMessageStorer) AddMessage(ctx context.Context, message *models.Message) error {
+	args := m.Called(ctx, message)
+	return args.Error(0)
+}
+
+func (m *mockMessageStorer) Messages(ctx context.Context, conversationID string, before *models.MessageCursor, limit int) ([]*models.Message, error) {
+	args := m.Called(ctx, conversationID, before, limit)
+	return args.Get(0).([]*models.Message), args.Error(1)
+}
+
+func (m *mockMessageStorer) MarkRead(ctx context.Context, conversationID string, readerID string, readAt time.Time) error {
+	args := m.Called(ctx, conversationID, readerID, readAt)
+	return args.Error(0)
+}
+
+type mockPostProvider struct {
+	mock.Mock
+}
+
+func (m *mockPostProvider) PostByID(ctx context.Context, id string) (*models.PostWithDocument, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*models.PostWithDocument), args.Error(1)
+}
+
+type mockNotifier struct {
+	mock.Mock
+}
+
+func (m *mockNotifier) Notify(ctx context.Context, userID string, kind models.NotificationKind, payload models.NotificationPayload) error {
+	args := m.Called(ctx, userID, kind, payload)
+	return args.Error(0)
+}
+
+func TestStartConversation_New(t *testing.T) {
+	t.Parallel()
+
+	adder := new(mockConversationAdder)
+	provider := new(mockConversationProvider)
+	messages := new(mockMessageStorer)
+	posts := new(mockPostProvider)
+	notifier := new(mockNotifier)
+
+	service := New(slog.Default(), adder, provider, messages, posts, notifier)
+
+	buyer := &models.User{ID: "buyer", Login: "buyer"}
+	postID := uuid.NewV4().String()
+
+	posts.On("PostByID", mock.Anything, postID).Return(&models.PostWithDocument{ID: postID, OwnerID: "seller", OwnerLogin: "seller", Header: "bike"}, nil)
+	provider.On("ConversationByPostAndBuyer", mock.Anything, postID, "buyer").Return((*models.Conversation)(nil), models.ErrConversationNotFound)
+	adder.On("AddConversation", mock.Anything, mock.MatchedBy(func(c *models.Conversation) bool {
+		return c.PostID == postID && c.BuyerID == "buyer" && c.SellerID == "seller"
+	})).Return(nil)
+	messages.On("AddMessage", mock.Anything, mock.MatchedBy(func(m *models.Message) bool {
+		return m.SenderID == "buyer" && m.Text == "still available?"
+	})).Return(nil)
+	notifier.On("Notify", mock.Anything, "seller", models.NotificationNewMessage, mock.AnythingOfType("models.NewMessagePayload")).Return(nil)
+
+	conversation, err := service.StartConversation(context.Background(), buyer, postID, "still available?")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "bike", conversation.PostHeader)
+	assert.Equal(t, "seller", conversation.SellerLogin)
+	adder.AssertExpectations(t)
+	messages.AssertExpectations(t)
+	notifier.AssertExpectations(t)
+}
+
+func TestStartConversation_Existing(t *testing.T) {
+	t.Parallel()
+
+	provider := new(mockConversationProvider)
+	messages := new(mockMessageStorer)
+	posts := new(mockPostProvider)
+	notifier := new(mockNotifier)
+
+	service := New(slog.Default(), nil, provider, messages, posts, notifier)
+
+	buyer := &models.User{ID: "buyer"}
+	postID := uuid.NewV4().String()
+	existing := &models.Conversation{ID: "c1", PostID: postID, BuyerID: "buyer", SellerID: "seller"}
+
+	posts.On("PostByID", mock.Anything, postID).Return(&models.PostWithDocument{ID: postID, OwnerID: "seller"}, nil)
+	provider.On("ConversationByPostAndBuyer", mock.Anything, postID, "buyer").Return(existing, nil)
+	messages.On("AddMessage", mock.Anything, mock.MatchedBy(func(m *models.Message) bool {
+		return m.ConversationID == "c1"
+	})).Return(nil)
+	notifier.On("Notify", mock.Anything, "seller", models.NotificationNewMessage, mock.Anything).Return(errors.New("ignored"))
+
+	conversation, err := service.StartConversation(context.Background(), buyer, postID, "hello again")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "c1", conversation.ID)
+	messages.AssertExpectations(t)
+}
+
+func TestStartConversation_OwnPost(t *testing.T) {
+	t.Parallel()
+
+	posts := new(mockPostProvider)
+
+	service := New(slog.Default(), nil, nil, nil, posts, nil)
+
+	postID := uuid.NewV4().String()
+
+	posts.On("PostByID", mock.Anything, postID).Return(&models.PostWithDocument{ID: postID, OwnerID: "seller"}, nil)
+
+	_, err := service.StartConversation(context.Background(), &models.User{ID: "seller"}, postID, "hello")
+
+	assert.ErrorIs(t, err, models.ErrOwnPost)
+}
+
+func TestStartConversation_PostNotFound(t *testing.T) {
+	t.Parallel()
+
+	posts := new(mockPostProvider)
+
+	service := New(slog.Default(), nil, nil, nil, posts, nil)
+
+	postID := uuid.NewV4().String()
+
+	posts.On("PostByID", mock.Anything, postID).Return((*models.PostWithDocument)(nil), models.ErrPostNotFound)
+
+	_, err := service.StartConversation(context.Background(), &models.User{ID: "buyer"}, postID, "hello")
+
+	assert.ErrorIs(t, err, models.ErrPostNotFound)
+
+	_, err = service.StartConversation(context.Background(), &models.User{ID: "buyer"}, "not-a-uuid", "hello")
+
+	assert.ErrorIs(t, err, models.ErrPostNotFound)
+}
+
+func TestStartConversation_InvalidText(t *testing.T) {
+	t.Parallel()
+
+	service := New(slog.Default(), nil, nil, nil, nil, nil)
+
+	_, err := service.StartConversation(context.Background(), &models.User{ID: "buyer"}, uuid.NewV4().String(), "  ")
+
+	assert.ErrorIs(t, err, models.ErrInvalidText)
+}
+
+func TestMessages_NotParticipant(t *testing.T) {
+	t.Parallel()
+
+	provider := new(mockConversationProvider)
+
+	service := New(slog.Default(), nil, provider, nil, nil, nil)
+
+	id := uuid.NewV4().String()
+
+	provider.On("ConversationByID", mock.Anything, id).Return(&models.Conversation{ID: id, BuyerID: "buyer", SellerID: "seller"}, nil)
+
+	_, _, err := service.Messages(context.Background(), &models.User{ID: "stranger"}, id, "", 10)
+
+	assert.ErrorIs(t, err, models.ErrConversationNotFound)
+}
+
+func TestMessages_PaginatesAndMarksRead(t *testing.T) {
+	t.Parallel()
+
+	provider := new(mockConversationProvider)
+	messages := new(mockMessageStorer)
+
+	service := New(slog.Default(), nil, provider, messages, nil, nil)
+
+	id := uuid.NewV4().String()
+	lastID := uuid.NewV4().String()
+	now := time.Now().UTC()
+
+	provider.On("ConversationByID", mock.Anything, id).Return(&models.Conversation{ID: id, BuyerID: "buyer", SellerID: "seller"}, nil)
+	messages.On("Messages", mock.Anything, id, (*models.MessageCursor)(nil), 2).Return([]*models.Message{
+		{ID: uuid.NewV4().String(), CreatedAt: now},
+		{ID: lastID, CreatedAt: now.Add(-time.Minute)},
+	}, nil)
+	messages.On("MarkRead", mock.Anything, id, "seller", mock.Anything).Return(nil)
+
+	page, next, err := service.Messages(context.Background(), &models.User{ID: "seller"}, id, "", 2)
+
+	assert.NoError(t, err)
+	assert.Len(t, page, 2)
+
+	cursor, err := mapper.StringToCursor(next)
+	assert.NoError(t, err)
+	assert.Equal(t, lastID, cursor.ID)
+	assert.True(t, now.Add(-time.Minute).Equal(cursor.CreatedAt))
+
+	messages.On("Messages", mock.Anything, id, cursor, 2).Return([]*models.Message{{ID: uuid.NewV4().String()}}, nil)
+
+	page, next, err = service.Messages(context.Background(), &models.User{ID: "seller"}, id, next, 2)
+
+	assert.NoError(t, err)
+	assert.Len(t, page, 1)
+	assert.Empty(t, next)
+	messages.AssertExpectations(t)
+}
+
+func TestMessages_InvalidCursor(t *testing.T) {
+	t.Parallel()
+
+	service := New(slog.Default(), nil, nil, nil, nil, nil)
+
+	_, _, err := service.Messages(context.Background(), &models.User{ID: "buyer"}, uuid.NewV4().String(), "%%%", 10)
+
+	assert.ErrorIs(t, err, models.ErrInvalidCursor)
+}
+
+func TestSendMessage_Success(t *testing.T) {
+	t.Parallel()
+
+	provider := new(mockConversationProvider)
+	messages := new(mockMessageStorer)
+	notifier := new(mockNotifier)
+
+	service := New(slog.Default(), nil, provider, messages, nil, notifier)
+
+	id := uuid.NewV4().String()
+
+	provider.On("ConversationByID", mock.Anything, id).Return(&models.Conversation{ID: id, BuyerID: "buyer", SellerID: "seller"}, nil)
+	messages.On("AddMessage", mock.Anything, mock.Anything).Return(nil)
+	notifier.On("Notify", mock.Anything, "buyer", models.NotificationNewMessage, mock.Anything).Return(nil)
+
+	message, err := service.SendMessage(context.Background(), &models.User{ID: "seller", Login: "seller"}, id, "yes, it is")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "seller", message.SenderID)
+	notifier.AssertExpectations(t)
+}
+
+func TestSendMessage_StoreFails(t *testing.T) {
+	t.Parallel()
+
+	provider := new(mockConversationProvider)
+	messages := new(mockMessageStorer)
+
+	service := New(slog.Default(), nil, provider, messages, nil, nil)
+
+	id := uuid.NewV4().String()
+
+	provider.On("ConversationByID", mock.Anything, id).Return(&models.Conversation{ID: id, BuyerID: "buyer", SellerID: "seller"}, nil)
+	messages.On("AddMessage", mock.Anything, mock.Anything).Return(errors.New("db error"))
+
+	_, err := service.SendMessage(context.Background(), &models.User{ID: "buyer"}, id, "hello")
+
+	assert.ErrorIs(t, err, models.ErrInternal)
+}
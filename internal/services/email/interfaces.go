@@ -0,0 +1,28 @@
+package emailservice
+
+import (
+	"context"
+	"marketplace/internal/models"
+)
+
+type UserProvider interface {
+	UserByID(ctx context.Context, id string) (*models.User, error)
+}
+
+type EmailVerifier interface {
+	MarkEmailVerified(ctx context.Context, userID string) error
+}
+
+type VerifyTokenStorer interface {
+	SaveVerifyToken(ctx context.Context, tokenHash string, userID string) error
+	ConsumeVerifyToken(ctx context.Context, tokenHash string) (string, error)
+}
+
+// MailCounter counts mails sent on request per key within a window.
+type MailCounter interface {
+	CountMail(ctx context.Context, key string) (int64, error)
+}
+
+type Mailer interface {
+	Send(ctx context.Context, mail *models.Mail) error
+}
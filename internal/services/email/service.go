@@ -0,0 +1,192 @@
+package emailservice
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"marketplace/internal/models"
+	"marketplace/internal/utils/token"
+	"net/url"
+)
+
+const pkg = "emailService/"
+
+type EmailService struct {
+	log             *slog.Logger
+	userProvider    UserProvider
+	emailVerifier   EmailVerifier
+	verifyTokens    VerifyTokenStorer
+	mails           MailCounter
+	mailer          Mailer
+	verifyURL       string
+	resendLimit     int64
+	requireForPosts bool
+}
+
+func New(
+	log *slog.Logger,
+	userProvider UserProvider,
+	emailVerifier EmailVerifier,
+	verifyTokens VerifyTokenStorer,
+	mails MailCounter,
+	mailer Mailer,
+	verifyURL string,
+	resendLimit int64,
+	requireForPosts bool,
+) *EmailService {
+	return &EmailService{
+		log:             log,
+		userProvider:    userProvider,
+		emailVerifier:   emailVerifier,
+		verifyTokens:    verifyTokens,
+		mails:           mails,
+		mailer:          mailer,
+		verifyURL:       verifyURL,
+		resendLimit:     resendLimit,
+		requireForPosts: requireForPosts,
+	}
+}
+
+// SendVerification mails a verification link to the email of the user.
+func (es *EmailService) SendVerification(ctx context.Context, user *models.User) error {
+	op := pkg + "SendVerification"
+
+	log := es.log.With(slog.String("op", op), slog.String("user_id", user.ID))
+
+	log.Debug("attempting to send verification mail")
+
+	if user.Email == "" {
+		log.Warn("user has no email")
+		return models.ErrNoEmail
+	}
+
+	verifyToken, err := token.New()
+	if err != nil {
+		log.Error("failed to generate verification token", slog.String("error", err.Error()))
+		return models.ErrInternal
+	}
+
+	if err := es.verifyTokens.SaveVerifyToken(ctx, token.Hash(verifyToken), user.ID); err != nil {
+		log.Error("failed to save verification token", slog.String("error", err.Error()))
+		return models.ErrInternal
+	}
+
+	mail := &models.Mail{
+		To:      user.Email,
+		Subject: "Confirm your email",
+		Body:    "To confirm your email, follow the link:\n\n" + es.verifyURL + "?token=" + url.QueryEscape(verifyToken) + "\n\nIf you did not register, ignore this mail.\n",
+	}
+
+	if err := es.mailer.Send(ctx, mail); err != nil {
+		log.Error("failed to send verification mail", slog.String("error", err.Error()))
+		return models.ErrInternal
+	}
+
+	log.Debug("verification mail sent successfully")
+
+	return nil
+}
+
+// ResendVerification sends a new verification link, at most resendLimit
+// times per window.
+func (es *EmailService) ResendVerification(ctx context.Context, requester *models.User) error {
+	op := pkg + "ResendVerification"
+
+	log := es.log.With(slog.String("op", op), slog.String("user_id", requester.ID))
+
+	log.Debug("attempting to resend verification mail")
+
+	user, err := es.userProvider.UserByID(ctx, requester.ID)
+	if err != nil {
+		if errors.Is(err, models.ErrUserNotFound) {
+			log.Warn("user not found")
+			return models.ErrUserNotFound
+		}
+		log.Error("failed to get user", slog.String("error", err.Error()))
+		return models.ErrInternal
+	}
+
+	if user.Email == "" {
+		log.Warn("user has no email")
+		return models.ErrNoEmail
+	}
+
+	if user.EmailVerified() {
+		log.Warn("email already verified")
+		return models.ErrEmailAlreadyVerified
+	}
+
+	count, err := es.mails.CountMail(ctx, "verify:"+user.ID)
+	if err != nil {
+		log.Error("failed to count resends", slog.String("error", err.Error()))
+		return models.ErrInternal
+	}
+
+	if count > es.resendLimit {
+		log.Warn("resend limit reached", slog.Int64("count", count))
+		return models.ErrTooManyRequests
+	}
+
+	return es.SendVerification(ctx, user)
+}
+
+func (es *EmailService) Verify(ctx context.Context, verifyToken string) error {
+	op := pkg + "Verify"
+
+	log := es.log.With(slog.String("op", op))
+
+	log.Debug("attempting to verify email")
+
+	userID, err := es.verifyTokens.ConsumeVerifyToken(ctx, token.Hash(verifyToken))
+	if err != nil {
+		if errors.Is(err, models.ErrVerifyTokenNotFound) {
+			log.Warn("verification token not found")
+			return models.ErrInvalidVerifyToken
+		}
+		log.Error("failed to consume verification token", slog.String("error", err.Error()))
+		return models.ErrInternal
+	}
+
+	if err := es.emailVerifier.MarkEmailVerified(ctx, userID); err != nil {
+		if errors.Is(err, models.ErrUserNotFound) {
+			log.Warn("user not found", slog.String("user_id", userID))
+			return models.ErrInvalidVerifyToken
+		}
+		log.Error("failed to mark email verified", slog.String("error", err.Error()))
+		return models.ErrInternal
+	}
+
+	log.Debug("email verified successfully")
+
+	return nil
+}
+
+// CheckCanPost returns ErrEmailNotVerified when posting requires a verified
+// email and the requester has none. The user is read from the database, as the
+// session copy does not see a verification made after login.
+func (es *EmailService) CheckCanPost(ctx context.Context, requester *models.User) error {
+	if !es.requireForPosts {
+		return nil
+	}
+
+	op := pkg + "CheckCanPost"
+
+	log := es.log.With(slog.String("op", op), slog.String("user_id", requester.ID))
+
+	user, err := es.userProvider.UserByID(ctx, requester.ID)
+	if err != nil {
+		if errors.Is(err, models.ErrUserNotFound) {
+			log.Warn("user not found")
+			return models.ErrUserNotFound
+		}
+		log.Error("failed to get user", slog.String("error", err.Error()))
+		return models.ErrInternal
+	}
+
+	if !user.EmailVerified() {
+		log.Info("email is not verified")
+		return models.ErrEmailNotVerified
+	}
+
+	return nil
+}
@@ -0,0 +1,202 @@
+package emailservice
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	capturemailer "marketplace/internal/mailer/capture"
+	"marketplace/internal/models"
+	"marketplace/internal/utils/token"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockUserProvider struct {
+	mock.Mock
+}
+
+func (m *mockUserProvider) UserByID(ctx context.Context, id string) (*models.User, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+type mockEmailVerifier struct {
+	mock.Mock
+}
+
+func (m *mockEmailVerifier) MarkEmailVerified(ctx context.Context, userID string) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+// memoryTokens is a VerifyTokenStorer and a MailCounter backed by maps, so
+// that a token taken from a captured mail can be consumed like it would be in
+// Redis.
+type memoryTokens struct {
+	mu     sync.Mutex
+	tokens map[string]string
+	counts map[string]int64
+}
+
+func newMemoryTokens() *memoryTokens {
+	return &memoryTokens{tokens: make(map[string]string), counts: make(map[string]int64)}
+}
+
+func (m *memoryTokens) SaveVerifyToken(ctx context.Context, tokenHash string, userID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.tokens[tokenHash] = userID
+
+	return nil
+}
+
+func (m *memoryTokens) ConsumeVerifyToken(ctx context.Context, tokenHash string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	userID, ok := m.tokens[tokenHash]
+	if !ok {
+		return "", models.ErrVerifyTokenNotFound
+	}
+
+	delete(m.tokens, tokenHash)
+
+	return userID, nil
+}
+
+func (m *memoryTokens) CountMail(ctx context.Context, key string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.counts[key]++
+
+	return m.counts[key], nil
+}
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func tokenFromMail(t *testing.T, mail models.Mail) string {
+	start := strings.Index(mail.Body, "https://example.com/verify?token=")
+	assert.NotEqual(t, -1, start)
+
+	link, err := url.Parse(strings.Fields(mail.Body[start:])[0])
+	assert.NoError(t, err)
+
+	return link.Query().Get("token")
+}
+
+func TestSendVerificationAndVerify(t *testing.T) {
+	t.Parallel()
+
+	verifier := new(mockEmailVerifier)
+	tokens := newMemoryTokens()
+	mailer := capturemailer.New()
+
+	verifier.On("MarkEmailVerified", mock.Anything, "1").Return(nil)
+
+	service := New(testLogger(), nil, verifier, tokens, tokens, mailer, "https://example.com/verify", 3, false)
+
+	err := service.SendVerification(context.Background(), &models.User{ID: "1", Email: "user@example.com"})
+	assert.NoError(t, err)
+
+	mails := mailer.Mails()
+	assert.Len(t, mails, 1)
+	assert.Equal(t, "user@example.com", mails[0].To)
+
+	verifyToken := tokenFromMail(t, mails[0])
+	assert.Contains(t, tokens.tokens, token.Hash(verifyToken))
+
+	err = service.Verify(context.Background(), verifyToken)
+	assert.NoError(t, err)
+	verifier.AssertExpectations(t)
+
+	err = service.Verify(context.Background(), verifyToken)
+	assert.ErrorIs(t, err, models.ErrInvalidVerifyToken)
+}
+
+func TestResendVerification(t *testing.T) {
+	t.Parallel()
+
+	verifiedAt := time.Now()
+
+	tests := []struct {
+		name      string
+		user      *models.User
+		calls     int
+		wantErr   error
+		wantMails int
+	}{
+		{name: "sends", user: &models.User{ID: "1", Email: "user@example.com"}, calls: 1, wantMails: 1},
+		{name: "limit reached", user: &models.User{ID: "1", Email: "user@example.com"}, calls: 3, wantErr: models.ErrTooManyRequests, wantMails: 2},
+		{name: "no email", user: &models.User{ID: "1"}, calls: 1, wantErr: models.ErrNoEmail},
+		{name: "already verified", user: &models.User{ID: "1", Email: "user@example.com", EmailVerifiedAt: &verifiedAt}, calls: 1, wantErr: models.ErrEmailAlreadyVerified},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			users := new(mockUserProvider)
+			mailer := capturemailer.New()
+
+			users.On("UserByID", mock.Anything, "1").Return(tt.user, nil)
+
+			tokens := newMemoryTokens()
+
+			service := New(testLogger(), users, nil, tokens, tokens, mailer, "https://example.com/verify", 2, false)
+
+			var err error
+			for range tt.calls {
+				err = service.ResendVerification(context.Background(), &models.User{ID: "1"})
+			}
+
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Len(t, mailer.Mails(), tt.wantMails)
+		})
+	}
+}
+
+func TestCheckCanPost(t *testing.T) {
+	t.Parallel()
+
+	verifiedAt := time.Now()
+
+	tests := []struct {
+		name     string
+		required bool
+		user     *models.User
+		wantErr  error
+	}{
+		{name: "not required", required: false, user: &models.User{ID: "1"}},
+		{name: "verified", required: true, user: &models.User{ID: "1", Email: "user@example.com", EmailVerifiedAt: &verifiedAt}},
+		{name: "unverified", required: true, user: &models.User{ID: "1", Email: "user@example.com"}, wantErr: models.ErrEmailNotVerified},
+		{name: "no email", required: true, user: &models.User{ID: "1"}, wantErr: models.ErrEmailNotVerified},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			users := new(mockUserProvider)
+			users.On("UserByID", mock.Anything, "1").Return(tt.user, nil)
+
+			service := New(testLogger(), users, nil, nil, nil, nil, "", 3, tt.required)
+
+			err := service.CheckCanPost(context.Background(), &models.User{ID: "1"})
+
+			assert.ErrorIs(t, err, tt.wantErr)
+			if !tt.required {
+				users.AssertNotCalled(t, "UserByID", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
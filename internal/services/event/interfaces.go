@@ -0,0 +1,12 @@
+package eventservice
+
+import (
+	"context"
+	"marketplace/internal/models"
+)
+
+type EventStorer interface {
+	SaveEvent(ctx context.Context, event *models.Event) error
+	EventsAfter(ctx context.Context, userID string, afterID int64) ([]*models.Event, error)
+	Subscribe(ctx context.Context, userID string) (<-chan *models.Event, func() error, error)
+}
@@ -0,0 +1,137 @@
+package eventservice
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"marketplace/internal/models"
+	"strconv"
+	"time"
+)
+
+const pkg = "eventService/"
+
+type EventService struct {
+	log         *slog.Logger
+	eventStorer EventStorer
+}
+
+func New(
+	log *slog.Logger,
+	eventStorer EventStorer,
+) *EventService {
+	return &EventService{
+		log:         log,
+		eventStorer: eventStorer,
+	}
+}
+
+func (es *EventService) Publish(ctx context.Context, userID string, eventType models.EventType, payload any) error {
+	op := pkg + "Publish"
+
+	log := es.log.With(slog.String("op", op))
+
+	log.Debug("attempting to publish event", slog.String("type", string(eventType)))
+
+	payloadJSON, err := json.Marshal(payload)
+	if err != nil {
+		log.Error("failed to marshal payload", slog.String("error", err.Error()))
+		return models.ErrInternal
+	}
+
+	event := &models.Event{
+		UserID:    userID,
+		Type:      eventType,
+		Payload:   payloadJSON,
+		CreatedAt: time.Now(),
+	}
+
+	err = es.eventStorer.SaveEvent(ctx, event)
+	if err != nil {
+		log.Error("failed to save event", slog.String("error", err.Error()))
+		return models.ErrInternal
+	}
+
+	log.Debug("event published successfully", slog.String("event_id", event.ID))
+
+	return nil
+}
+
+// Subscribe streams the events of the user until ctx is cancelled. When
+// lastEventID is set, events missed since then are replayed first.
+func (es *EventService) Subscribe(ctx context.Context, userID string, lastEventID string) (<-chan *models.Event, error) {
+	op := pkg + "Subscribe"
+
+	log := es.log.With(slog.String("op", op))
+
+	log.Debug("attempting to subscribe to events")
+
+	live, unsubscribe, err := es.eventStorer.Subscribe(ctx, userID)
+	if err != nil {
+		log.Error("failed to subscribe to events", slog.String("error", err.Error()))
+		return nil, models.ErrInternal
+	}
+
+	var replay []*models.Event
+
+	if afterID, err := strconv.ParseInt(lastEventID, 10, 64); err == nil && afterID >= 0 {
+		replay, err = es.eventStorer.EventsAfter(ctx, userID, afterID)
+		if err != nil {
+			_ = unsubscribe()
+			log.Error("failed to get missed events", slog.String("error", err.Error()))
+			return nil, models.ErrInternal
+		}
+	}
+
+	out := make(chan *models.Event)
+
+	go func() {
+		defer close(out)
+		defer func() {
+			_ = unsubscribe()
+		}()
+
+		var replayedID int64
+
+		for _, event := range replay {
+			select {
+			case out <- event:
+			case <-ctx.Done():
+				return
+			}
+			replayedID = eventSeq(event)
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-live:
+				if !ok {
+					return
+				}
+
+				// Events published between subscribing and reading the history
+				// arrive twice.
+				if eventSeq(event) <= replayedID {
+					continue
+				}
+
+				select {
+				case out <- event:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	log.Debug("subscribed to events successfully", slog.Int("replayed", len(replay)))
+
+	return out, nil
+}
+
+func eventSeq(event *models.Event) int64 {
+	seq, _ := strconv.ParseInt(event.ID, 10, 64)
+	return seq
+}
@@ -0,0 +1,132 @@
+package eventservice
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"marketplace/internal/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockEventStorer struct {
+	mock.Mock
+}
+
+func (m *mockEventStorer) SaveEvent(ctx context.Context, event *models.Event) error {
+	args := m.Called(ctx, event)
+	return args.Error(0)
+}
+
+func (m *mockEventStorer) EventsAfter(ctx context.Context, userID string, afterID int64) ([]*models.Event, error) {
+	args := m.Called(ctx, userID, afterID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Event), args.Error(1)
+}
+
+func (m *mockEventStorer) Subscribe(ctx context.Context, userID string) (<-chan *models.Event, func() error, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(1)
+	}
+	return args.Get(0).(chan *models.Event), func() error { return nil }, args.Error(1)
+}
+
+func TestPublish_Success(t *testing.T) {
+	t.Parallel()
+
+	storer := new(mockEventStorer)
+
+	storer.On("SaveEvent", mock.Anything, mock.MatchedBy(func(e *models.Event) bool {
+		return e.UserID == "u1" && e.Type == models.EventPostCreated && string(e.Payload) == `{"post_id":"p1","header":"h"}`
+	})).Return(nil)
+
+	service := New(slog.Default(), storer)
+
+	err := service.Publish(context.Background(), "u1", models.EventPostCreated, models.PostEventPayload{PostID: "p1", Header: "h"})
+
+	assert.NoError(t, err)
+	storer.AssertExpectations(t)
+}
+
+func TestPublish_SaveFails(t *testing.T) {
+	t.Parallel()
+
+	storer := new(mockEventStorer)
+
+	storer.On("SaveEvent", mock.Anything, mock.Anything).Return(errors.New("redis down"))
+
+	service := New(slog.Default(), storer)
+
+	err := service.Publish(context.Background(), "u1", models.EventPostDeleted, models.PostEventPayload{PostID: "p1"})
+
+	assert.ErrorIs(t, err, models.ErrInternal)
+}
+
+func TestSubscribe_ReplaysAndSkipsDuplicates(t *testing.T) {
+	t.Parallel()
+
+	storer := new(mockEventStorer)
+
+	live := make(chan *models.Event, 2)
+	live <- &models.Event{ID: "5"}
+	live <- &models.Event{ID: "6"}
+	close(live)
+
+	storer.On("Subscribe", mock.Anything, "u1").Return(live, nil)
+	storer.On("EventsAfter", mock.Anything, "u1", int64(3)).
+		Return([]*models.Event{{ID: "4"}, {ID: "5"}}, nil)
+
+	service := New(slog.Default(), storer)
+
+	events, err := service.Subscribe(context.Background(), "u1", "3")
+	assert.NoError(t, err)
+
+	var ids []string
+	for event := range events {
+		ids = append(ids, event.ID)
+	}
+
+	assert.Equal(t, []string{"4", "5", "6"}, ids)
+	storer.AssertExpectations(t)
+}
+
+func TestSubscribe_WithoutLastEventID(t *testing.T) {
+	t.Parallel()
+
+	storer := new(mockEventStorer)
+
+	live := make(chan *models.Event, 1)
+	live <- &models.Event{ID: "1"}
+	close(live)
+
+	storer.On("Subscribe", mock.Anything, "u1").Return(live, nil)
+
+	service := New(slog.Default(), storer)
+
+	events, err := service.Subscribe(context.Background(), "u1", "")
+	assert.NoError(t, err)
+
+	event := <-events
+	assert.Equal(t, "1", event.ID)
+
+	storer.AssertNotCalled(t, "EventsAfter", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestSubscribe_Fails(t *testing.T) {
+	t.Parallel()
+
+	storer := new(mockEventStorer)
+
+	storer.On("Subscribe", mock.Anything, "u1").Return(nil, errors.New("redis down"))
+
+	service := New(slog.Default(), storer)
+
+	_, err := service.Subscribe(context.Background(), "u1", "")
+
+	assert.ErrorIs(t, err, models.ErrInternal)
+}
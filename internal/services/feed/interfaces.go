@@ -0,0 +1,20 @@
+package feedservice
+
+import (
+	"context"
+	"marketplace/internal/models"
+)
+
+type PostBroker interface {
+	PublishPost(ctx context.Context, post *models.PostWithDocument) error
+	SubscribePosts(ctx context.Context) (<-chan *models.PostWithDocument, func() error, error)
+}
+
+type TicketStorer interface {
+	SaveTicket(ctx context.Context, ticket string, userID string) error
+	ConsumeTicket(ctx context.Context, ticket string) (string, error)
+}
+
+type UserProvider interface {
+	UserByID(ctx context.Context, id string) (*models.User, error)
+}
@@ -0,0 +1,228 @@
+package feedservice
+
+import (
+	"context"
+	"log/slog"
+	"marketplace/internal/models"
+	"marketplace/internal/utils/validator"
+	"sync"
+	"time"
+)
+
+const (
+	pkg = "feedService/"
+
+	resubscribeDelay = time.Second
+)
+
+// FeedService delivers newly published posts to live subscribers. Posts are
+// published to the broker, and every replica fans out what it receives from
+// the broker to its own subscribers, so a post reaches clients connected to
+// any replica.
+type FeedService struct {
+	log             *slog.Logger
+	broker          PostBroker
+	tickets         TicketStorer
+	users           UserProvider
+	queue           chan *models.PostWithDocument
+	clientBuffer    int
+	maxConnsPerUser int
+	ticketTTL       time.Duration
+
+	mu            sync.Mutex
+	subscriptions map[*subscription]struct{}
+	conns         map[string]int
+}
+
+func New(
+	log *slog.Logger,
+	broker PostBroker,
+	tickets TicketStorer,
+	users UserProvider,
+	queueSize int,
+	clientBuffer int,
+	maxConnsPerUser int,
+	ticketTTL time.Duration,
+) *FeedService {
+	return &FeedService{
+		log:             log,
+		broker:          broker,
+		tickets:         tickets,
+		users:           users,
+		queue:           make(chan *models.PostWithDocument, queueSize),
+		clientBuffer:    clientBuffer,
+		maxConnsPerUser: maxConnsPerUser,
+		ticketTTL:       ticketTTL,
+		subscriptions:   make(map[*subscription]struct{}),
+		conns:           make(map[string]int),
+	}
+}
+
+// PostAdded enqueues the post for publishing. It never blocks: when the queue
+// is full the post is dropped and a warning is logged.
+func (fs *FeedService) PostAdded(_ context.Context, post *models.PostWithDocument) {
+	select {
+	case fs.queue <- post:
+	default:
+		fs.log.Warn("feed queue is full, post skipped", slog.String("op", pkg+"PostAdded"), slog.String("post_id", post.ID))
+	}
+}
+
+// Run publishes queued posts and fans out posts received from the broker until
+// ctx is cancelled.
+func (fs *FeedService) Run(ctx context.Context) {
+	op := pkg + "Run"
+
+	log := fs.log.With(slog.String("op", op))
+
+	for {
+		posts, unsubscribe, err := fs.broker.SubscribePosts(ctx)
+		if err != nil {
+			log.Error("failed to subscribe to posts", slog.String("error", err.Error()))
+		} else {
+			fs.serve(ctx, posts)
+			_ = unsubscribe()
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(resubscribeDelay):
+		}
+	}
+}
+
+func (fs *FeedService) serve(ctx context.Context, posts <-chan *models.PostWithDocument) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case post := <-fs.queue:
+			if err := fs.broker.PublishPost(ctx, post); err != nil {
+				fs.log.Error("failed to publish post", slog.String("op", pkg+"serve"), slog.String("post_id", post.ID), slog.String("error", err.Error()))
+			}
+		case post, ok := <-posts:
+			if !ok {
+				return
+			}
+			fs.broadcast(post)
+		}
+	}
+}
+
+// Subscribe registers a live subscription of the requester. The number of
+// simultaneous subscriptions per user is limited on each replica.
+func (fs *FeedService) Subscribe(ctx context.Context, requester *models.User, filter *models.PostsFilter) (models.PostSubscription, error) {
+	op := pkg + "Subscribe"
+
+	log := fs.log.With(slog.String("op", op))
+
+	log.Debug("attempting to subscribe to feed")
+
+	if err := validator.ValidatePostsFilter(filter); err != nil {
+		log.Warn("invalid filter received", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	if fs.conns[requester.ID] >= fs.maxConnsPerUser {
+		log.Warn("too many feed connections", slog.String("user_id", requester.ID))
+		return nil, models.ErrTooManyConnections
+	}
+
+	sub := &subscription{
+		feed:      fs,
+		userID:    requester.ID,
+		userLogin: requester.Login,
+		filter:    *filter,
+		posts:     make(chan *models.PostWithDocument, fs.clientBuffer),
+	}
+
+	fs.subscriptions[sub] = struct{}{}
+	fs.conns[requester.ID]++
+
+	log.Debug("subscribed to feed successfully")
+
+	return sub, nil
+}
+
+// broadcast hands the post to every matching subscription. A subscription
+// whose buffer is full is dropped, so a slow client never holds up others.
+func (fs *FeedService) broadcast(post *models.PostWithDocument) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	for sub := range fs.subscriptions {
+		if !sub.matches(post) {
+			continue
+		}
+
+		delivered := *post
+		delivered.RequesterIsOwner = post.OwnerLogin == sub.userLogin
+
+		select {
+		case sub.posts <- &delivered:
+		default:
+			fs.log.Warn("feed subscriber is too slow, dropped", slog.String("op", pkg+"broadcast"), slog.String("user_id", sub.userID))
+			delete(fs.subscriptions, sub)
+			close(sub.posts)
+		}
+	}
+}
+
+func (fs *FeedService) release(sub *subscription) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	if _, ok := fs.subscriptions[sub]; ok {
+		delete(fs.subscriptions, sub)
+		close(sub.posts)
+	}
+
+	fs.conns[sub.userID]--
+	if fs.conns[sub.userID] <= 0 {
+		delete(fs.conns, sub.userID)
+	}
+}
+
+type subscription struct {
+	feed      *FeedService
+	userID    string
+	userLogin string
+	posts     chan *models.PostWithDocument
+	closeOnce sync.Once
+
+	mu     sync.Mutex
+	filter models.PostsFilter
+}
+
+func (s *subscription) Posts() <-chan *models.PostWithDocument {
+	return s.posts
+}
+
+func (s *subscription) SetFilter(filter *models.PostsFilter) error {
+	if err := validator.ValidatePostsFilter(filter); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.filter = *filter
+	s.mu.Unlock()
+
+	return nil
+}
+
+func (s *subscription) Close() {
+	s.closeOnce.Do(func() {
+		s.feed.release(s)
+	})
+}
+
+func (s *subscription) matches(post *models.PostWithDocument) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.filter.Matches(post)
+}
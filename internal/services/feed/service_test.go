@@ -0,0 +1,245 @@
+package feedservice
+
+import (
+	"context"
+	"log/slog"
+	"marketplace/internal/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockPostBroker struct {
+	mock.Mock
+}
+
+func (m *mockPostBroker) PublishPost(ctx context.Context, post *models.PostWithDocument) error {
+	args := m.Called(ctx, post)
+	return args.Error(0)
+}
+
+func (m *mockPostBroker) SubscribePosts(ctx context.Context) (<-chan *models.PostWithDocument, func() error, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(1)
+	}
+	return args.Get(0).(chan *models.PostWithDocument), func() error { return nil }, args.Error(1)
+}
+
+func TestSubscribe_InvalidFilter(t *testing.T) {
+	t.Parallel()
+
+	service := New(slog.Default(), nil, nil, nil, 1, 1, 1, time.Minute)
+
+	_, err := service.Subscribe(context.Background(), &models.User{ID: "u1"}, &models.PostsFilter{MinPrice: 10, MaxPrice: 5})
+
+	assert.ErrorIs(t, err, models.ErrInvalidFilter)
+}
+
+func TestSubscribe_ConnectionLimit(t *testing.T) {
+	t.Parallel()
+
+	service := New(slog.Default(), nil, nil, nil, 1, 1, 2, time.Minute)
+	user := &models.User{ID: "u1"}
+
+	first, err := service.Subscribe(context.Background(), user, &models.PostsFilter{})
+	assert.NoError(t, err)
+
+	_, err = service.Subscribe(context.Background(), user, &models.PostsFilter{})
+	assert.NoError(t, err)
+
+	_, err = service.Subscribe(context.Background(), user, &models.PostsFilter{})
+	assert.ErrorIs(t, err, models.ErrTooManyConnections)
+
+	_, err = service.Subscribe(context.Background(), &models.User{ID: "u2"}, &models.PostsFilter{})
+	assert.NoError(t, err)
+
+	first.Close()
+	first.Close()
+
+	_, err = service.Subscribe(context.Background(), user, &models.PostsFilter{})
+	assert.NoError(t, err)
+}
+
+func TestBroadcast_FiltersByPrice(t *testing.T) {
+	t.Parallel()
+
+	service := New(slog.Default(), nil, nil, nil, 1, 4, 5, time.Minute)
+
+	cheap, err := service.Subscribe(context.Background(), &models.User{ID: "u1", Login: "buyer"}, &models.PostsFilter{MaxPrice: 100})
+	assert.NoError(t, err)
+
+	expensive, err := service.Subscribe(context.Background(), &models.User{ID: "u2", Login: "seller"}, &models.PostsFilter{MinPrice: 1000})
+	assert.NoError(t, err)
+
+	service.broadcast(&models.PostWithDocument{ID: "p1", OwnerLogin: "seller", Price: 50, RequesterIsOwner: true})
+	service.broadcast(&models.PostWithDocument{ID: "p2", OwnerLogin: "seller", Price: 5000, RequesterIsOwner: true})
+
+	post := <-cheap.Posts()
+	assert.Equal(t, "p1", post.ID)
+	assert.False(t, post.RequesterIsOwner)
+	assert.Len(t, cheap.Posts(), 0)
+
+	post = <-expensive.Posts()
+	assert.Equal(t, "p2", post.ID)
+	assert.True(t, post.RequesterIsOwner)
+	assert.Len(t, expensive.Posts(), 0)
+}
+
+func TestBroadcast_SetFilter(t *testing.T) {
+	t.Parallel()
+
+	service := New(slog.Default(), nil, nil, nil, 1, 4, 5, time.Minute)
+
+	sub, err := service.Subscribe(context.Background(), &models.User{ID: "u1"}, &models.PostsFilter{MaxPrice: 100})
+	assert.NoError(t, err)
+
+	assert.ErrorIs(t, sub.SetFilter(&models.PostsFilter{MinPrice: 10, MaxPrice: 5}), models.ErrInvalidFilter)
+	assert.NoError(t, sub.SetFilter(&models.PostsFilter{MinPrice: 1000}))
+
+	service.broadcast(&models.PostWithDocument{ID: "p1", Price: 50})
+	service.broadcast(&models.PostWithDocument{ID: "p2", Price: 5000})
+
+	post := <-sub.Posts()
+	assert.Equal(t, "p2", post.ID)
+}
+
+func TestBroadcast_DropsSlowSubscriber(t *testing.T) {
+	t.Parallel()
+
+	service := New(slog.Default(), nil, nil, nil, 1, 1, 1, time.Minute)
+
+	slow, err := service.Subscribe(context.Background(), &models.User{ID: "u1"}, &models.PostsFilter{})
+	assert.NoError(t, err)
+
+	service.broadcast(&models.PostWithDocument{ID: "p1"})
+	service.broadcast(&models.PostWithDocument{ID: "p2"})
+
+	post, ok := <-slow.Posts()
+	assert.True(t, ok)
+	assert.Equal(t, "p1", post.ID)
+
+	_, ok = <-slow.Posts()
+	assert.False(t, ok)
+
+	slow.Close()
+
+	_, err = service.Subscribe(context.Background(), &models.User{ID: "u1"}, &models.PostsFilter{})
+	assert.NoError(t, err)
+}
+
+func TestRun_PublishesAndFansOut(t *testing.T) {
+	t.Parallel()
+
+	broker := new(mockPostBroker)
+	incoming := make(chan *models.PostWithDocument, 1)
+
+	post := &models.PostWithDocument{ID: "p1", Price: 10}
+
+	broker.On("SubscribePosts", mock.Anything).Return(incoming, nil)
+	broker.On("PublishPost", mock.Anything, post).Run(func(args mock.Arguments) {
+		incoming <- args.Get(1).(*models.PostWithDocument)
+	}).Return(nil)
+
+	service := New(slog.Default(), broker, nil, nil, 1, 1, 1, time.Minute)
+
+	sub, err := service.Subscribe(context.Background(), &models.User{ID: "u1"}, &models.PostsFilter{})
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go service.Run(ctx)
+
+	service.PostAdded(ctx, post)
+
+	select {
+	case got := <-sub.Posts():
+		assert.Equal(t, "p1", got.ID)
+	case <-time.After(time.Second):
+		t.Fatal("post was not delivered")
+	}
+}
+
+// memoryTickets keeps tickets like the cache does, so that a ticket can be
+// issued and redeemed.
+type memoryTickets struct {
+	tickets map[string]string
+}
+
+func (m *memoryTickets) SaveTicket(_ context.Context, ticket string, userID string) error {
+	m.tickets[ticket] = userID
+	return nil
+}
+
+func (m *memoryTickets) ConsumeTicket(_ context.Context, ticket string) (string, error) {
+	userID, ok := m.tickets[ticket]
+	if !ok {
+		return "", models.ErrInvalidFeedTicket
+	}
+	delete(m.tickets, ticket)
+	return userID, nil
+}
+
+type mockUserProvider struct {
+	mock.Mock
+}
+
+func (m *mockUserProvider) UserByID(ctx context.Context, id string) (*models.User, error) {
+	args := m.Called(ctx, id)
+	user, _ := args.Get(0).(*models.User)
+	return user, args.Error(1)
+}
+
+func TestTicket_SingleUse(t *testing.T) {
+	t.Parallel()
+
+	user := &models.User{ID: "u1"}
+	users := new(mockUserProvider)
+	users.On("UserByID", mock.Anything, "u1").Return(user, nil)
+
+	service := New(slog.Default(), nil, &memoryTickets{tickets: map[string]string{}}, users, 1, 1, 1, time.Minute)
+
+	ticket, err := service.IssueTicket(context.Background(), user)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, ticket.Ticket)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), ticket.ExpiresAt, time.Second)
+
+	got, err := service.UserByTicket(context.Background(), ticket.Ticket)
+	assert.NoError(t, err)
+	assert.Equal(t, user, got)
+
+	_, err = service.UserByTicket(context.Background(), ticket.Ticket)
+	assert.ErrorIs(t, err, models.ErrInvalidFeedTicket)
+}
+
+func TestUserByTicket_Rejects(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		user    *models.User
+		userErr error
+		wantErr error
+	}{
+		{name: "banned", user: &models.User{ID: "u1", Ban: &models.Ban{Reason: "spam"}}, wantErr: models.ErrUserBanned},
+		{name: "deleted", userErr: models.ErrUserNotFound, wantErr: models.ErrInvalidFeedTicket},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			users := new(mockUserProvider)
+			users.On("UserByID", mock.Anything, "u1").Return(tt.user, tt.userErr)
+
+			service := New(slog.Default(), nil, &memoryTickets{tickets: map[string]string{"ticket": "u1"}}, users, 1, 1, 1, time.Minute)
+
+			_, err := service.UserByTicket(context.Background(), "ticket")
+
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
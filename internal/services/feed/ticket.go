@@ -0,0 +1,74 @@
+package feedservice
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"marketplace/internal/models"
+	"marketplace/internal/utils/token"
+	"time"
+)
+
+// IssueTicket returns a short-lived ticket that opens the live feed once for
+// the requester.
+func (fs *FeedService) IssueTicket(ctx context.Context, requester *models.User) (*models.FeedTicket, error) {
+	op := pkg + "IssueTicket"
+
+	log := fs.log.With(slog.String("op", op), slog.String("user_id", requester.ID))
+
+	log.Debug("attempting to issue feed ticket")
+
+	ticket, err := token.New()
+	if err != nil {
+		log.Error("failed to generate ticket", slog.String("error", err.Error()))
+		return nil, models.ErrInternal
+	}
+
+	if err := fs.tickets.SaveTicket(ctx, ticket, requester.ID); err != nil {
+		log.Error("failed to save ticket", slog.String("error", err.Error()))
+		return nil, models.ErrInternal
+	}
+
+	log.Debug("feed ticket issued successfully")
+
+	return &models.FeedTicket{Ticket: ticket, ExpiresAt: time.Now().UTC().Add(fs.ticketTTL)}, nil
+}
+
+// UserByTicket uses up the ticket and returns the user it was issued to.
+// Banned users are turned away even with a ticket issued before the ban.
+func (fs *FeedService) UserByTicket(ctx context.Context, ticket string) (*models.User, error) {
+	op := pkg + "UserByTicket"
+
+	log := fs.log.With(slog.String("op", op))
+
+	log.Debug("attempting to redeem feed ticket")
+
+	userID, err := fs.tickets.ConsumeTicket(ctx, ticket)
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidFeedTicket) {
+			log.Warn("feed ticket not found")
+			return nil, models.ErrInvalidFeedTicket
+		}
+		log.Error("failed to consume ticket", slog.String("error", err.Error()))
+		return nil, models.ErrInternal
+	}
+
+	user, err := fs.users.UserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, models.ErrUserNotFound) {
+			log.Warn("ticket user not found", slog.String("user_id", userID))
+			return nil, models.ErrInvalidFeedTicket
+		}
+		log.Error("failed to get user", slog.String("error", err.Error()))
+		return nil, models.ErrInternal
+	}
+
+	if user.Banned(time.Now()) {
+		log.Warn("banned user redeemed feed ticket", slog.String("user_id", userID))
+		return nil, models.ErrUserBanned
+	}
+
+	log.Debug("feed ticket redeemed successfully", slog.String("user_id", userID))
+
+	return user, nil
+}
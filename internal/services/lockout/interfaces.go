@@ -0,0 +1,13 @@
+package lockoutservice
+
+import (
+	"context"
+	"time"
+)
+
+type AttemptStorer interface {
+	RegisterFailure(ctx context.Context, key string) (int64, error)
+	Block(ctx context.Context, key string, until time.Time) error
+	BlockedUntil(ctx context.Context, key string) (time.Time, error)
+	Reset(ctx context.Context, key string) error
+}
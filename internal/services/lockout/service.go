@@ -0,0 +1,159 @@
+package lockoutservice
+
+import (
+	"context"
+	"log/slog"
+	"marketplace/internal/models"
+	"time"
+)
+
+const (
+	pkg = "lockoutService/"
+
+	loginKey = "login:"
+	ipKey    = "ip:"
+)
+
+// Policy says how failed logins slow a key down. The first FreeAttempts
+// failures cost nothing; each one after that blocks the key for BaseDelay,
+// doubled with every failure up to MaxDelay. At LockoutThreshold failures the
+// key is locked for LockoutDuration. Failures are counted within a window
+// set in the attempt storer.
+type Policy struct {
+	FreeAttempts     int64
+	BaseDelay        time.Duration
+	MaxDelay         time.Duration
+	LockoutThreshold int64
+	LockoutDuration  time.Duration
+}
+
+// delay returns how long the key is blocked after its failures-th failure.
+func (p Policy) delay(failures int64) time.Duration {
+	if p.LockoutThreshold > 0 && failures >= p.LockoutThreshold {
+		return p.LockoutDuration
+	}
+
+	if failures <= p.FreeAttempts {
+		return 0
+	}
+
+	delay := p.BaseDelay
+	for i := p.FreeAttempts + 1; i < failures && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+
+	return min(delay, p.MaxDelay)
+}
+
+// LockoutService slows down password guessing. Failed logins are counted per
+// login, against guessing one account, and per client IP, against trying
+// many accounts from one place.
+type LockoutService struct {
+	log         *slog.Logger
+	attempts    AttemptStorer
+	loginPolicy Policy
+	ipPolicy    Policy
+}
+
+func New(log *slog.Logger, attempts AttemptStorer, loginPolicy Policy, ipPolicy Policy) *LockoutService {
+	return &LockoutService{
+		log:         log,
+		attempts:    attempts,
+		loginPolicy: loginPolicy,
+		ipPolicy:    ipPolicy,
+	}
+}
+
+// Check returns a *models.RetryAfterError when logging in as login from ip is
+// blocked at the moment.
+func (ls *LockoutService) Check(ctx context.Context, login string, ip string) error {
+	op := pkg + "Check"
+
+	log := ls.log.With(slog.String("op", op))
+
+	log.Debug("attempting to check login attempts")
+
+	var blockedUntil time.Time
+
+	for _, limit := range ls.limits(login, ip) {
+		until, err := ls.attempts.BlockedUntil(ctx, limit.key)
+		if err != nil {
+			log.Error("failed to get block", slog.String("error", err.Error()))
+			return models.ErrInternal
+		}
+
+		if until.After(blockedUntil) {
+			blockedUntil = until
+		}
+	}
+
+	if after := time.Until(blockedUntil); after > 0 {
+		log.Warn("login attempts blocked", slog.Duration("retry_after", after))
+		return &models.RetryAfterError{After: after, Err: models.ErrLoginBlocked}
+	}
+
+	return nil
+}
+
+// Fail registers a failed login and blocks the login or the IP once their
+// policy says so.
+func (ls *LockoutService) Fail(ctx context.Context, login string, ip string) error {
+	op := pkg + "Fail"
+
+	log := ls.log.With(slog.String("op", op))
+
+	log.Debug("attempting to register failed login")
+
+	for _, limit := range ls.limits(login, ip) {
+		failures, err := ls.attempts.RegisterFailure(ctx, limit.key)
+		if err != nil {
+			log.Error("failed to register failure", slog.String("error", err.Error()))
+			return models.ErrInternal
+		}
+
+		delay := limit.policy.delay(failures)
+		if delay <= 0 {
+			continue
+		}
+
+		if err := ls.attempts.Block(ctx, limit.key, time.Now().Add(delay)); err != nil {
+			log.Error("failed to block", slog.String("error", err.Error()))
+			return models.ErrInternal
+		}
+
+		if limit.policy.LockoutThreshold > 0 && failures >= limit.policy.LockoutThreshold {
+			log.Warn("login attempts locked out", slog.Int64("failures", failures))
+		}
+	}
+
+	return nil
+}
+
+// Succeed forgets the failures of the login. The failures of the IP stay, so
+// that one account of its own does not let an attacker start over.
+func (ls *LockoutService) Succeed(ctx context.Context, login string) error {
+	op := pkg + "Succeed"
+
+	log := ls.log.With(slog.String("op", op))
+
+	if err := ls.attempts.Reset(ctx, loginKey+login); err != nil {
+		log.Error("failed to reset failures", slog.String("error", err.Error()))
+		return models.ErrInternal
+	}
+
+	return nil
+}
+
+type limit struct {
+	key    string
+	policy Policy
+}
+
+func (ls *LockoutService) limits(login string, ip string) []limit {
+	limits := []limit{{key: loginKey + login, policy: ls.loginPolicy}}
+	if ip != "" {
+		limits = append(limits, limit{key: ipKey + ip, policy: ls.ipPolicy})
+	}
+
+	return limits
+}
@@ -0,0 +1,188 @@
+package lockoutservice
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"marketplace/internal/models"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockAttemptStorer struct {
+	mock.Mock
+}
+
+func (m *mockAttemptStorer) RegisterFailure(ctx context.Context, key string) (int64, error) {
+	args := m.Called(ctx, key)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *mockAttemptStorer) Block(ctx context.Context, key string, until time.Time) error {
+	return m.Called(ctx, key, until).Error(0)
+}
+
+func (m *mockAttemptStorer) BlockedUntil(ctx context.Context, key string) (time.Time, error) {
+	args := m.Called(ctx, key)
+	return args.Get(0).(time.Time), args.Error(1)
+}
+
+func (m *mockAttemptStorer) Reset(ctx context.Context, key string) error {
+	return m.Called(ctx, key).Error(0)
+}
+
+// memoryAttempts is an AttemptStorer over maps for tests that follow a run of
+// attempts. The failure window is ignored.
+type memoryAttempts struct {
+	mu       sync.Mutex
+	failures map[string]int64
+	blocked  map[string]time.Time
+}
+
+func newMemoryAttempts() *memoryAttempts {
+	return &memoryAttempts{failures: make(map[string]int64), blocked: make(map[string]time.Time)}
+}
+
+func (m *memoryAttempts) RegisterFailure(ctx context.Context, key string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.failures[key]++
+
+	return m.failures[key], nil
+}
+
+func (m *memoryAttempts) Block(ctx context.Context, key string, until time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.blocked[key] = until
+
+	return nil
+}
+
+func (m *memoryAttempts) BlockedUntil(ctx context.Context, key string) (time.Time, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.blocked[key], nil
+}
+
+func (m *memoryAttempts) Reset(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.failures, key)
+	delete(m.blocked, key)
+
+	return nil
+}
+
+var (
+	loginPolicy = Policy{FreeAttempts: 3, BaseDelay: time.Second, MaxDelay: 10 * time.Second, LockoutThreshold: 10, LockoutDuration: 15 * time.Minute}
+	ipPolicy    = Policy{FreeAttempts: 20, BaseDelay: time.Second, MaxDelay: 10 * time.Second, LockoutThreshold: 100, LockoutDuration: 15 * time.Minute}
+)
+
+func TestPolicyDelay(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		failures int64
+		want     time.Duration
+	}{
+		{failures: 1, want: 0},
+		{failures: 3, want: 0},
+		{failures: 4, want: time.Second},
+		{failures: 5, want: 2 * time.Second},
+		{failures: 6, want: 4 * time.Second},
+		{failures: 7, want: 8 * time.Second},
+		{failures: 8, want: 10 * time.Second},
+		{failures: 9, want: 10 * time.Second},
+		{failures: 10, want: 15 * time.Minute},
+		{failures: 50, want: 15 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, loginPolicy.delay(tt.failures), "failures: %d", tt.failures)
+	}
+}
+
+func TestFail_BlocksAfterFreeAttempts(t *testing.T) {
+	t.Parallel()
+
+	attempts := newMemoryAttempts()
+	service := New(slog.New(slog.NewTextHandler(io.Discard, nil)), attempts, loginPolicy, ipPolicy)
+	ctx := context.Background()
+
+	for range 3 {
+		assert.NoError(t, service.Fail(ctx, "alice", "10.0.0.1"))
+		assert.NoError(t, service.Check(ctx, "alice", "10.0.0.1"))
+	}
+
+	assert.NoError(t, service.Fail(ctx, "alice", "10.0.0.1"))
+
+	err := service.Check(ctx, "alice", "10.0.0.1")
+
+	var retry *models.RetryAfterError
+	assert.ErrorAs(t, err, &retry)
+	assert.ErrorIs(t, err, models.ErrLoginBlocked)
+	assert.InDelta(t, time.Second, retry.After, float64(100*time.Millisecond))
+
+	// Another login from the same IP is not blocked yet.
+	assert.NoError(t, service.Check(ctx, "bob", "10.0.0.1"))
+}
+
+func TestFail_LocksOutIP(t *testing.T) {
+	t.Parallel()
+
+	attempts := newMemoryAttempts()
+	service := New(slog.New(slog.NewTextHandler(io.Discard, nil)), attempts, loginPolicy, Policy{LockoutThreshold: 3, LockoutDuration: time.Hour})
+	ctx := context.Background()
+
+	for _, login := range []string{"alice", "bob", "carol"} {
+		assert.NoError(t, service.Fail(ctx, login, "10.0.0.1"))
+	}
+
+	err := service.Check(ctx, "dave", "10.0.0.1")
+
+	var retry *models.RetryAfterError
+	assert.ErrorAs(t, err, &retry)
+	assert.Greater(t, retry.After, 59*time.Minute)
+
+	assert.NoError(t, service.Check(ctx, "dave", "10.0.0.2"))
+}
+
+func TestSucceed_ResetsLoginOnly(t *testing.T) {
+	t.Parallel()
+
+	attempts := newMemoryAttempts()
+	service := New(slog.New(slog.NewTextHandler(io.Discard, nil)), attempts, loginPolicy, ipPolicy)
+	ctx := context.Background()
+
+	for range 2 {
+		assert.NoError(t, service.Fail(ctx, "alice", "10.0.0.1"))
+	}
+
+	assert.NoError(t, service.Succeed(ctx, "alice"))
+
+	assert.Zero(t, attempts.failures["login:alice"])
+	assert.Equal(t, int64(2), attempts.failures["ip:10.0.0.1"])
+}
+
+func TestCheck_StoreFails(t *testing.T) {
+	t.Parallel()
+
+	attempts := new(mockAttemptStorer)
+	attempts.On("BlockedUntil", mock.Anything, "login:alice").Return(time.Time{}, errors.New("redis down"))
+
+	service := New(slog.New(slog.NewTextHandler(io.Discard, nil)), attempts, loginPolicy, ipPolicy)
+
+	err := service.Check(context.Background(), "alice", "10.0.0.1")
+
+	assert.ErrorIs(t, err, models.ErrInternal)
+}
@@ -0,0 +1,21 @@
+package notificationservice
+
+import (
+	"context"
+	"marketplace/internal/models"
+	"time"
+)
+
+type NotificationAdder interface {
+	AddNotification(ctx context.Context, notification *models.Notification) error
+}
+
+type NotificationProvider interface {
+	NotificationsByUser(ctx context.Context, userID string, limit int, offset int, unreadOnly bool) ([]*models.Notification, error)
+	UnreadCount(ctx context.Context, userID string) (int, error)
+}
+
+type NotificationUpdater interface {
+	MarkRead(ctx context.Context, userID string, id string, readAt time.Time) error
+	MarkAllRead(ctx context.Context, userID string, readAt time.Time) error
+}
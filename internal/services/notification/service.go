@@ -0,0 +1,142 @@
+package notificationservice
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"marketplace/internal/models"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+)
+
+const pkg = "notificationService/"
+
+type NotificationService struct {
+	log                  *slog.Logger
+	notificationAdder    NotificationAdder
+	notificationProvider NotificationProvider
+	notificationUpdater  NotificationUpdater
+}
+
+func New(
+	log *slog.Logger,
+	notificationAdder NotificationAdder,
+	notificationProvider NotificationProvider,
+	notificationUpdater NotificationUpdater,
+) *NotificationService {
+	return &NotificationService{
+		log:                  log,
+		notificationAdder:    notificationAdder,
+		notificationProvider: notificationProvider,
+		notificationUpdater:  notificationUpdater,
+	}
+}
+
+func (ns *NotificationService) Notify(ctx context.Context, userID string, kind models.NotificationKind, payload models.NotificationPayload) error {
+	op := pkg + "Notify"
+
+	log := ns.log.With(slog.String("op", op))
+
+	log.Debug("attempting to notify user", slog.String("kind", string(kind)))
+
+	if payload == nil || payload.Kind() != kind {
+		log.Error("payload does not match notification kind", slog.String("kind", string(kind)))
+		return models.ErrInvalidParams
+	}
+
+	payloadJSON, err := json.Marshal(payload)
+	if err != nil {
+		log.Error("failed to marshal payload", slog.String("error", err.Error()))
+		return models.ErrInternal
+	}
+
+	notification := &models.Notification{
+		ID:        uuid.NewV4().String(),
+		UserID:    userID,
+		Kind:      kind,
+		Payload:   payloadJSON,
+		CreatedAt: time.Now(),
+	}
+
+	err = ns.notificationAdder.AddNotification(ctx, notification)
+	if err != nil {
+		log.Error("failed to add notification", slog.String("error", err.Error()))
+		return models.ErrInternal
+	}
+
+	log.Debug("user notified successfully", slog.String("notification_id", notification.ID))
+
+	return nil
+}
+
+func (ns *NotificationService) Notifications(ctx context.Context, requester *models.User, limit int, offset int, unreadOnly bool) ([]*models.Notification, int, error) {
+	op := pkg + "Notifications"
+
+	log := ns.log.With(slog.String("op", op))
+
+	log.Debug("attempting to get notifications")
+
+	notifications, err := ns.notificationProvider.NotificationsByUser(ctx, requester.ID, limit, offset, unreadOnly)
+	if err != nil {
+		log.Error("failed to get notifications", slog.String("error", err.Error()))
+		return nil, 0, models.ErrInternal
+	}
+
+	unread, err := ns.notificationProvider.UnreadCount(ctx, requester.ID)
+	if err != nil {
+		log.Error("failed to count unread notifications", slog.String("error", err.Error()))
+		return nil, 0, models.ErrInternal
+	}
+
+	log.Debug("notifications found successfully", slog.Int("count", len(notifications)), slog.Int("unread", unread))
+
+	return notifications, unread, nil
+}
+
+func (ns *NotificationService) MarkRead(ctx context.Context, requester *models.User, id string) error {
+	op := pkg + "MarkRead"
+
+	log := ns.log.With(slog.String("op", op))
+
+	log.Debug("attempting to mark notification as read")
+
+	if _, err := uuid.FromString(id); err != nil {
+		log.Warn("invalid notification id received", slog.String("notification_id", id))
+		return models.ErrNotificationNotFound
+	}
+
+	err := ns.notificationUpdater.MarkRead(ctx, requester.ID, id, time.Now())
+	if err != nil {
+		if errors.Is(err, models.ErrNotificationNotFound) {
+			log.Warn("notification not found", slog.String("notification_id", id))
+			return models.ErrNotificationNotFound
+		}
+
+		log.Error("failed to mark notification as read", slog.String("error", err.Error()))
+		return models.ErrInternal
+	}
+
+	log.Debug("notification marked as read successfully")
+
+	return nil
+}
+
+func (ns *NotificationService) MarkAllRead(ctx context.Context, requester *models.User) error {
+	op := pkg + "MarkAllRead"
+
+	log := ns.log.With(slog.String("op", op))
+
+	log.Debug("attempting to mark all notifications as read")
+
+	err := ns.notificationUpdater.MarkAllRead(ctx, requester.ID, time.Now())
+	if err != nil {
+		log.Error("failed to mark notifications as read", slog.String("error", err.Error()))
+		return models.ErrInternal
+	}
+
+	log.Debug("notifications marked as read successfully")
+
+	return nil
+}
@@ -0,0 +1,219 @@
+package notificationservice
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"marketplace/internal/models"
+	"testing"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockNotificationAdder struct {
+	mock.Mock
+}
+
+func (m *mockNotificationAdder) AddNotification(ctx context.Context, notification *models.Notification) error {
+	args := m.Called(ctx, notification)
+	return args.Error(0)
+}
+
+type mockNotificationProvider struct {
+	mock.Mock
+}
+
+func (m *mockNotificationProvider) NotificationsByUser(ctx context.Context, userID string, limit int, offset int, unreadOnly bool) ([]*models.Notification, error) {
+	args := m.Called(ctx, userID, limit, offset, unreadOnly)
+	return args.Get(0).([]*models.Notification), args.Error(1)
+}
+
+func (m *mockNotificationProvider) UnreadCount(ctx context.Context, userID string) (int, error) {
+	args := m.Called(ctx, userID)
+	return args.Int(0), args.Error(1)
+}
+
+type mockNotificationUpdater struct {
+	mock.Mock
+}
+
+func (m *mockNotificationUpdater) MarkRead(ctx context.Context, userID string, id string, readAt time.Time) error {
+	args := m.Called(ctx, userID, id, readAt)
+	return args.Error(0)
+}
+
+func (m *mockNotificationUpdater) MarkAllRead(ctx context.Context, userID string, readAt time.Time) error {
+	args := m.Called(ctx, userID, readAt)
+	return args.Error(0)
+}
+
+func TestNotify_Success(t *testing.T) {
+	t.Parallel()
+
+	mockNotificationAdder := new(mockNotificationAdder)
+
+	service := New(slog.Default(), mockNotificationAdder, nil, nil)
+
+	payload := models.SearchMatchPayload{SearchID: "s1", PostID: "p1"}
+
+	mockNotificationAdder.On("AddNotification", mock.Anything, mock.MatchedBy(func(n *models.Notification) bool {
+		var stored models.SearchMatchPayload
+		err := json.Unmarshal(n.Payload, &stored)
+		return err == nil && stored == payload && n.UserID == "1" && n.Kind == models.NotificationSearchMatch
+	})).Return(nil)
+
+	err := service.Notify(context.Background(), "1", models.NotificationSearchMatch, payload)
+
+	assert.NoError(t, err)
+
+	mockNotificationAdder.AssertExpectations(t)
+}
+
+func TestNotify_KindMismatch(t *testing.T) {
+	t.Parallel()
+
+	mockNotificationAdder := new(mockNotificationAdder)
+
+	service := New(slog.Default(), mockNotificationAdder, nil, nil)
+
+	err := service.Notify(context.Background(), "1", models.NotificationNewMessage, models.SearchMatchPayload{})
+
+	assert.ErrorIs(t, err, models.ErrInvalidParams)
+
+	mockNotificationAdder.AssertExpectations(t)
+}
+
+func TestNotify_AddFails(t *testing.T) {
+	t.Parallel()
+
+	mockNotificationAdder := new(mockNotificationAdder)
+
+	service := New(slog.Default(), mockNotificationAdder, nil, nil)
+
+	mockNotificationAdder.On("AddNotification", mock.Anything, mock.Anything).Return(errors.New("some error"))
+
+	err := service.Notify(context.Background(), "1", models.NotificationModeration, models.ModerationPayload{Action: "hidden"})
+
+	assert.ErrorIs(t, err, models.ErrInternal)
+
+	mockNotificationAdder.AssertExpectations(t)
+}
+
+func TestNotifications_Success(t *testing.T) {
+	t.Parallel()
+
+	mockNotificationProvider := new(mockNotificationProvider)
+
+	service := New(slog.Default(), nil, mockNotificationProvider, nil)
+
+	requester := &models.User{ID: "1"}
+
+	expNotifications := []*models.Notification{
+		{ID: "n1", UserID: "1", Kind: models.NotificationSearchMatch},
+	}
+
+	mockNotificationProvider.On("NotificationsByUser", mock.Anything, "1", 20, 0, true).Return(expNotifications, nil)
+	mockNotificationProvider.On("UnreadCount", mock.Anything, "1").Return(3, nil)
+
+	notifications, unread, err := service.Notifications(context.Background(), requester, 20, 0, true)
+
+	assert.NoError(t, err)
+	assert.Equal(t, expNotifications, notifications)
+	assert.Equal(t, 3, unread)
+
+	mockNotificationProvider.AssertExpectations(t)
+}
+
+func TestNotifications_CountFails(t *testing.T) {
+	t.Parallel()
+
+	mockNotificationProvider := new(mockNotificationProvider)
+
+	service := New(slog.Default(), nil, mockNotificationProvider, nil)
+
+	requester := &models.User{ID: "1"}
+
+	mockNotificationProvider.On("NotificationsByUser", mock.Anything, "1", 20, 0, false).Return([]*models.Notification{}, nil)
+	mockNotificationProvider.On("UnreadCount", mock.Anything, "1").Return(0, errors.New("some error"))
+
+	notifications, _, err := service.Notifications(context.Background(), requester, 20, 0, false)
+
+	assert.ErrorIs(t, err, models.ErrInternal)
+	assert.Empty(t, notifications)
+
+	mockNotificationProvider.AssertExpectations(t)
+}
+
+func TestMarkRead_Success(t *testing.T) {
+	t.Parallel()
+
+	mockNotificationUpdater := new(mockNotificationUpdater)
+
+	service := New(slog.Default(), nil, nil, mockNotificationUpdater)
+
+	requester := &models.User{ID: "1"}
+
+	id := uuid.NewV4().String()
+
+	mockNotificationUpdater.On("MarkRead", mock.Anything, "1", id, mock.AnythingOfType("time.Time")).Return(nil)
+
+	err := service.MarkRead(context.Background(), requester, id)
+
+	assert.NoError(t, err)
+
+	mockNotificationUpdater.AssertExpectations(t)
+}
+
+func TestMarkRead_NotFound(t *testing.T) {
+	t.Parallel()
+
+	mockNotificationUpdater := new(mockNotificationUpdater)
+
+	service := New(slog.Default(), nil, nil, mockNotificationUpdater)
+
+	requester := &models.User{ID: "1"}
+
+	id := uuid.NewV4().String()
+
+	mockNotificationUpdater.On("MarkRead", mock.Anything, "1", id, mock.AnythingOfType("time.Time")).Return(models.ErrNotificationNotFound)
+
+	err := service.MarkRead(context.Background(), requester, id)
+
+	assert.ErrorIs(t, err, models.ErrNotificationNotFound)
+
+	mockNotificationUpdater.AssertExpectations(t)
+}
+
+func TestMarkRead_InvalidID(t *testing.T) {
+	t.Parallel()
+
+	mockNotificationUpdater := new(mockNotificationUpdater)
+
+	service := New(slog.Default(), nil, nil, mockNotificationUpdater)
+
+	err := service.MarkRead(context.Background(), &models.User{ID: "1"}, "bad")
+
+	assert.ErrorIs(t, err, models.ErrNotificationNotFound)
+
+	mockNotificationUpdater.AssertExpectations(t)
+}
+
+func TestMarkAllRead_Success(t *testing.T) {
+	t.Parallel()
+
+	mockNotificationUpdater := new(mockNotificationUpdater)
+
+	service := New(slog.Default(), nil, nil, mockNotificationUpdater)
+
+	mockNotificationUpdater.On("MarkAllRead", mock.Anything, "1", mock.AnythingOfType("time.Time")).Return(nil)
+
+	err := service.MarkAllRead(context.Background(), &models.User{ID: "1"})
+
+	assert.NoError(t, err)
+
+	mockNotificationUpdater.AssertExpectations(t)
+}
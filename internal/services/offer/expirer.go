@@ -0,0 +1,66 @@
+package offerservice
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// Expirer periodically closes pending offers whose time is up and tells both
+// sides about it.
+type Expirer struct {
+	log          *slog.Logger
+	offerUpdater OfferUpdater
+	notifier     Notifier
+	interval     time.Duration
+}
+
+func NewExpirer(
+	log *slog.Logger,
+	offerUpdater OfferUpdater,
+	notifier Notifier,
+	interval time.Duration,
+) *Expirer {
+	return &Expirer{
+		log:          log,
+		offerUpdater: offerUpdater,
+		notifier:     notifier,
+		interval:     interval,
+	}
+}
+
+// Run expires offers every interval until ctx is cancelled.
+func (e *Expirer) Run(ctx context.Context) {
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			e.expire(ctx)
+		}
+	}
+}
+
+func (e *Expirer) expire(ctx context.Context) {
+	op := pkg + "expire"
+
+	log := e.log.With(slog.String("op", op))
+
+	offers, err := e.offerUpdater.ExpireOffers(ctx, time.Now())
+	if err != nil {
+		log.Error("failed to expire offers", slog.String("error", err.Error()))
+		return
+	}
+
+	for _, offer := range offers {
+		notifyOffer(ctx, log, e.notifier, offer.BuyerID, offer)
+		notifyOffer(ctx, log, e.notifier, offer.SellerID, offer)
+	}
+
+	if len(offers) > 0 {
+		log.Debug("offers expired", slog.Int("count", len(offers)))
+	}
+}
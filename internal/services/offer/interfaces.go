@@ -0,0 +1,32 @@
+package offerservice
+
+import (
+	"context"
+	"marketplace/internal/models"
+	"time"
+)
+
+type OfferAdder interface {
+	AddOffer(ctx context.Context, offer *models.Offer) error
+}
+
+type OfferProvider interface {
+	OfferByID(ctx context.Context, id string) (*models.Offer, error)
+	OffersByPost(ctx context.Context, postID string, buyerID string) ([]*models.Offer, error)
+	OffersByUser(ctx context.Context, userID string, role models.OfferRole, limit int, offset int) ([]*models.Offer, error)
+}
+
+type OfferUpdater interface {
+	UpdateOfferStatus(ctx context.Context, id string, status models.OfferStatus, respondedAt time.Time) error
+	CounterOffer(ctx context.Context, previousID string, counter *models.Offer) error
+	ExpireOffers(ctx context.Context, now time.Time) ([]*models.Offer, error)
+}
+
+type PostProvider interface {
+	PostByID(ctx context.Context, id string) (*models.PostWithDocument, error)
+	ListedPostByID(ctx context.Context, id string) (*models.PostWithDocument, error)
+}
+
+type Notifier interface {
+	Notify(ctx context.Context, userID string, kind models.NotificationKind, payload models.NotificationPayload) error
+}
@@ -0,0 +1,321 @@
+package offerservice
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"marketplace/internal/models"
+	"marketplace/internal/utils/validator"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+)
+
+const pkg = "offerService/"
+
+type OfferService struct {
+	log           *slog.Logger
+	offerAdder    OfferAdder
+	offerProvider OfferProvider
+	offerUpdater  OfferUpdater
+	postProvider  PostProvider
+	notifier      Notifier
+	offerTTL      time.Duration
+}
+
+func New(
+	log *slog.Logger,
+	offerAdder OfferAdder,
+	offerProvider OfferProvider,
+	offerUpdater OfferUpdater,
+	postProvider PostProvider,
+	notifier Notifier,
+	offerTTL time.Duration,
+) *OfferService {
+	return &OfferService{
+		log:           log,
+		offerAdder:    offerAdder,
+		offerProvider: offerProvider,
+		offerUpdater:  offerUpdater,
+		postProvider:  postProvider,
+		notifier:      notifier,
+		offerTTL:      offerTTL,
+	}
+}
+
+func (of *OfferService) MakeOffer(ctx context.Context, requester *models.User, postID string, amount int64) (*models.Offer, error) {
+	op := pkg + "MakeOffer"
+
+	log := of.log.With(slog.String("op", op))
+
+	log.Debug("attempting to make offer")
+
+	if err := validator.ValidateOfferAmount(amount); err != nil {
+		log.Warn("invalid offer amount received", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	post, err := of.post(ctx, log, of.postProvider.ListedPostByID, postID)
+	if err != nil {
+		return nil, err
+	}
+
+	if post.OwnerID == requester.ID {
+		log.Warn("seller tried to make offer on own post", slog.String("post_id", postID))
+		return nil, models.ErrOwnPost
+	}
+
+	now := time.Now()
+
+	offer := &models.Offer{
+		ID:          uuid.NewV4().String(),
+		PostID:      post.ID,
+		BuyerID:     requester.ID,
+		BuyerLogin:  requester.Login,
+		SellerID:    post.OwnerID,
+		SellerLogin: post.OwnerLogin,
+		ProposerID:  requester.ID,
+		Amount:      amount,
+		Status:      models.OfferPending,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(of.offerTTL),
+	}
+
+	err = of.offerAdder.AddOffer(ctx, offer)
+	if err != nil {
+		var uce *models.UniqueConstraintError
+		if errors.As(err, &uce) {
+			log.Warn("buyer already has a pending offer", slog.String("post_id", postID))
+			return nil, models.ErrOfferExists
+		}
+		log.Error("failed to add offer", slog.String("error", err.Error()))
+		return nil, models.ErrInternal
+	}
+
+	of.notify(ctx, log, offer.SellerID, offer)
+
+	log.Debug("offer made successfully", slog.String("offer_id", offer.ID))
+
+	return offer, nil
+}
+
+// Offers returns all offers on the post to its seller, and only the
+// requester's own negotiation to anyone else.
+func (of *OfferService) Offers(ctx context.Context, requester *models.User, postID string) ([]*models.Offer, error) {
+	op := pkg + "Offers"
+
+	log := of.log.With(slog.String("op", op))
+
+	log.Debug("attempting to get post offers")
+
+	post, err := of.post(ctx, log, of.postProvider.PostByID, postID)
+	if err != nil {
+		return nil, err
+	}
+
+	buyerID := requester.ID
+	if post.OwnerID == requester.ID {
+		buyerID = ""
+	}
+
+	offers, err := of.offerProvider.OffersByPost(ctx, post.ID, buyerID)
+	if err != nil {
+		log.Error("failed to get offers", slog.String("error", err.Error()))
+		return nil, models.ErrInternal
+	}
+
+	log.Debug("offers found successfully", slog.Int("count", len(offers)))
+
+	return offers, nil
+}
+
+func (of *OfferService) MyOffers(ctx context.Context, requester *models.User, role models.OfferRole, limit int, offset int) ([]*models.Offer, error) {
+	op := pkg + "MyOffers"
+
+	log := of.log.With(slog.String("op", op))
+
+	log.Debug("attempting to get offers inbox")
+
+	switch role {
+	case models.OfferRoleAny, models.OfferRoleBuyer, models.OfferRoleSeller:
+	default:
+		log.Warn("invalid offer role received", slog.String("role", string(role)))
+		return nil, models.ErrInvalidParams
+	}
+
+	offers, err := of.offerProvider.OffersByUser(ctx, requester.ID, role, limit, offset)
+	if err != nil {
+		log.Error("failed to get offers", slog.String("error", err.Error()))
+		return nil, models.ErrInternal
+	}
+
+	log.Debug("offers found successfully", slog.Int("count", len(offers)))
+
+	return offers, nil
+}
+
+func (of *OfferService) AcceptOffer(ctx context.Context, requester *models.User, postID string, offerID string) (*models.Offer, error) {
+	return of.closeOffer(ctx, pkg+"AcceptOffer", requester, postID, offerID, models.OfferAccepted)
+}
+
+func (of *OfferService) RejectOffer(ctx context.Context, requester *models.User, postID string, offerID string) (*models.Offer, error) {
+	return of.closeOffer(ctx, pkg+"RejectOffer", requester, postID, offerID, models.OfferRejected)
+}
+
+// CounterOffer answers the offer with another price. The counter-offer goes
+// back to the other side with a fresh expiry.
+func (of *OfferService) CounterOffer(ctx context.Context, requester *models.User, postID string, offerID string, amount int64) (*models.Offer, error) {
+	op := pkg + "CounterOffer"
+
+	log := of.log.With(slog.String("op", op))
+
+	log.Debug("attempting to counter offer")
+
+	if err := validator.ValidateOfferAmount(amount); err != nil {
+		log.Warn("invalid offer amount received", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	offer, err := of.receivedOffer(ctx, log, requester, postID, offerID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	counter := &models.Offer{
+		ID:          uuid.NewV4().String(),
+		PostID:      offer.PostID,
+		BuyerID:     offer.BuyerID,
+		BuyerLogin:  offer.BuyerLogin,
+		SellerID:    offer.SellerID,
+		SellerLogin: offer.SellerLogin,
+		ProposerID:  requester.ID,
+		PreviousID:  offer.ID,
+		Amount:      amount,
+		Status:      models.OfferPending,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(of.offerTTL),
+	}
+
+	err = of.offerUpdater.CounterOffer(ctx, offer.ID, counter)
+	if err != nil {
+		if errors.Is(err, models.ErrOfferClosed) {
+			log.Warn("offer is no longer pending", slog.String("offer_id", offerID))
+			return nil, models.ErrOfferClosed
+		}
+		log.Error("failed to counter offer", slog.String("error", err.Error()))
+		return nil, models.ErrInternal
+	}
+
+	of.notify(ctx, log, counter.Recipient(), counter)
+
+	log.Debug("offer countered successfully", slog.String("offer_id", counter.ID))
+
+	return counter, nil
+}
+
+func (of *OfferService) closeOffer(ctx context.Context, op string, requester *models.User, postID string, offerID string, status models.OfferStatus) (*models.Offer, error) {
+	log := of.log.With(slog.String("op", op))
+
+	log.Debug("attempting to answer offer", slog.String("status", string(status)))
+
+	offer, err := of.receivedOffer(ctx, log, requester, postID, offerID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	err = of.offerUpdater.UpdateOfferStatus(ctx, offer.ID, status, now)
+	if err != nil {
+		if errors.Is(err, models.ErrOfferClosed) {
+			log.Warn("offer is no longer pending", slog.String("offer_id", offerID))
+			return nil, models.ErrOfferClosed
+		}
+		log.Error("failed to update offer", slog.String("error", err.Error()))
+		return nil, models.ErrInternal
+	}
+
+	offer.Status = status
+	offer.RespondedAt = &now
+
+	of.notify(ctx, log, offer.ProposerID, offer)
+
+	log.Debug("offer answered successfully", slog.String("offer_id", offer.ID))
+
+	return offer, nil
+}
+
+// receivedOffer returns the pending offer of the post that the requester is
+// expected to answer. Offers of other negotiations are reported as not found.
+func (of *OfferService) receivedOffer(ctx context.Context, log *slog.Logger, requester *models.User, postID string, offerID string) (*models.Offer, error) {
+	if _, err := uuid.FromString(offerID); err != nil {
+		log.Warn("invalid offer id received", slog.String("offer_id", offerID))
+		return nil, models.ErrOfferNotFound
+	}
+
+	offer, err := of.offerProvider.OfferByID(ctx, offerID)
+	if err != nil {
+		if errors.Is(err, models.ErrOfferNotFound) {
+			log.Warn("offer not found", slog.String("offer_id", offerID))
+			return nil, models.ErrOfferNotFound
+		}
+		log.Error("failed to get offer", slog.String("error", err.Error()))
+		return nil, models.ErrInternal
+	}
+
+	if offer.PostID != postID || !offer.HasParticipant(requester.ID) {
+		log.Warn("offer belongs to another negotiation", slog.String("offer_id", offerID))
+		return nil, models.ErrOfferNotFound
+	}
+
+	if offer.Recipient() != requester.ID {
+		log.Warn("proposer tried to answer own offer", slog.String("offer_id", offerID))
+		return nil, models.ErrForbidden
+	}
+
+	if offer.Status != models.OfferPending || !time.Now().Before(offer.ExpiresAt) {
+		log.Warn("offer is no longer pending", slog.String("offer_id", offerID))
+		return nil, models.ErrOfferClosed
+	}
+
+	return offer, nil
+}
+
+// post looks the post up with lookup, the listed lookup for buyers and the
+// plain one for the owner.
+func (of *OfferService) post(ctx context.Context, log *slog.Logger, lookup func(context.Context, string) (*models.PostWithDocument, error), postID string) (*models.PostWithDocument, error) {
+	if _, err := uuid.FromString(postID); err != nil {
+		log.Warn("invalid post id received", slog.String("post_id", postID))
+		return nil, models.ErrPostNotFound
+	}
+
+	post, err := lookup(ctx, postID)
+	if err != nil {
+		if errors.Is(err, models.ErrPostNotFound) {
+			log.Warn("post not found", slog.String("post_id", postID))
+			return nil, models.ErrPostNotFound
+		}
+		log.Error("failed to get post", slog.String("error", err.Error()))
+		return nil, models.ErrInternal
+	}
+
+	return post, nil
+}
+
+func (of *OfferService) notify(ctx context.Context, log *slog.Logger, userID string, offer *models.Offer) {
+	notifyOffer(ctx, log, of.notifier, userID, offer)
+}
+
+func notifyOffer(ctx context.Context, log *slog.Logger, notifier Notifier, userID string, offer *models.Offer) {
+	err := notifier.Notify(ctx, userID, models.NotificationOffer, models.OfferPayload{
+		OfferID: offer.ID,
+		PostID:  offer.PostID,
+		Status:  offer.Status,
+		Amount:  offer.Amount,
+	})
+	if err != nil {
+		log.Warn("failed to notify about offer", slog.String("error", err.Error()))
+	}
+}
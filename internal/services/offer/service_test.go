@@ -0,0 +1,370 @@
+package offerservice
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"marketplace/internal/models"
+	"testing"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockOfferAdder struct {
+	mock.Mock
+}
+
+func (m *mockOfferAdder) AddOffer(ctx context.Context, offer *models.Offer) error {
+	args := m.Called(ctx, offer)
+	return args.Error(0)
+}
+
+type mockOfferProvider struct {
+	mock.Mock
+}
+
+func (m *mockOfferProvider) OfferByID(ctx context.Context, id string) (*models.Offer, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*models.Offer), args.Error(1)
+}
+
+func (m *mockOfferProvider) OffersByPost(ctx context.Context, postID string, buyerID string) ([]*models.Offer, error) {
+	args := m.Called(ctx, postID, buyerID)
+	return args.Get(0).([]*models.Offer), args.Error(1)
+}
+
+func (m *mockOfferProvider) OffersByUser(ctx context.Context, userID string, role models.OfferRole, limit int, offset int) ([]*models.Offer, error) {
+	args := m.Called(ctx, userID, role, limit, offset)
+	return args.Get(0).([]*models.Offer), args.Error(1)
+}
+
+type mockOfferUpdater struct {
+	mock.Mock
+}
+
+func (m *mockOfferUpdater) UpdateOfferStatus(ctx context.Context, id string, status models.OfferStatus, respondedAt time.Time) error {
+	args := m.Called(ctx, id, status, respondedAt)
+	return args.Error(0)
+}
+
+func (m *mockOfferUpdater) CounterOffer(ctx context.Context, previousID string, counter *models.Offer) error {
+	args := m.Called(ctx, previousID, counter)
+	return args.Error(0)
+}
+
+func (m *mockOfferUpdater) ExpireOffers(ctx context.Context, now time.Time) ([]*models.Offer, error) {
+	args := m.Called(ctx, now)
+	return args.Get(0).([]*models.Offer), args.Error(1)
+}
+
+type mockPostProvider struct {
+	mock.Mock
+}
+
+func (m *mockPostProvider) PostByID(ctx context.Context, id string) (*models.PostWithDocument, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*models.PostWithDocument), args.Error(1)
+}
+
+func (m *mockPostProvider) ListedPostByID(ctx context.Context, id string) (*models.PostWithDocument, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*models.PostWithDocument), args.Error(1)
+}
+
+type mockNotifier struct {
+	mock.Mock
+}
+
+func (m *mockNotifier) Notify(ctx context.Context, userID string, kind models.NotificationKind, payload models.NotificationPayload) error {
+	args := m.Called(ctx, userID, kind, payload)
+	return args.Error(0)
+}
+
+func pendingOffer(postID string) *models.Offer {
+	return &models.Offer{
+		ID:         uuid.NewV4().String(),
+		PostID:     postID,
+		BuyerID:    "buyer",
+		SellerID:   "seller",
+		ProposerID: "buyer",
+		Amount:     900,
+		Status:     models.OfferPending,
+		ExpiresAt:  time.Now().Add(time.Hour),
+	}
+}
+
+func TestMakeOffer_Success(t *testing.T) {
+	t.Parallel()
+
+	adder := new(mockOfferAdder)
+	posts := new(mockPostProvider)
+	notifier := new(mockNotifier)
+
+	service := New(slog.Default(), adder, nil, nil, posts, notifier, time.Hour)
+
+	postID := uuid.NewV4().String()
+
+	posts.On("ListedPostByID", mock.Anything, postID).Return(&models.PostWithDocument{ID: postID, OwnerID: "seller", OwnerLogin: "seller"}, nil)
+	adder.On("AddOffer", mock.Anything, mock.MatchedBy(func(o *models.Offer) bool {
+		return o.BuyerID == "buyer" && o.SellerID == "seller" && o.ProposerID == "buyer" &&
+			o.Amount == 900 && o.Status == models.OfferPending && o.ExpiresAt.Sub(o.CreatedAt) == time.Hour
+	})).Return(nil)
+	notifier.On("Notify", mock.Anything, "seller", models.NotificationOffer, mock.AnythingOfType("models.OfferPayload")).Return(nil)
+
+	offer, err := service.MakeOffer(context.Background(), &models.User{ID: "buyer", Login: "buyer"}, postID, 900)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(900), offer.Amount)
+	adder.AssertExpectations(t)
+	notifier.AssertExpectations(t)
+}
+
+func TestMakeOffer_InvalidAmount(t *testing.T) {
+	t.Parallel()
+
+	service := New(slog.Default(), nil, nil, nil, nil, nil, time.Hour)
+
+	_, err := service.MakeOffer(context.Background(), &models.User{ID: "buyer"}, uuid.NewV4().String(), 0)
+
+	assert.ErrorIs(t, err, models.ErrInvalidPrice)
+}
+
+func TestMakeOffer_OwnPost(t *testing.T) {
+	t.Parallel()
+
+	posts := new(mockPostProvider)
+
+	service := New(slog.Default(), nil, nil, nil, posts, nil, time.Hour)
+
+	postID := uuid.NewV4().String()
+
+	posts.On("ListedPostByID", mock.Anything, postID).Return(&models.PostWithDocument{ID: postID, OwnerID: "seller"}, nil)
+
+	_, err := service.MakeOffer(context.Background(), &models.User{ID: "seller"}, postID, 900)
+
+	assert.ErrorIs(t, err, models.ErrOwnPost)
+}
+
+func TestMakeOffer_PostNotListed(t *testing.T) {
+	t.Parallel()
+
+	adder := new(mockOfferAdder)
+	posts := new(mockPostProvider)
+
+	service := New(slog.Default(), adder, nil, nil, posts, nil, time.Hour)
+
+	postID := uuid.NewV4().String()
+
+	// Hidden posts and posts of banned sellers are not found for buyers.
+	posts.On("ListedPostByID", mock.Anything, postID).Return((*models.PostWithDocument)(nil), models.ErrPostNotFound)
+
+	_, err := service.MakeOffer(context.Background(), &models.User{ID: "buyer"}, postID, 900)
+
+	assert.ErrorIs(t, err, models.ErrPostNotFound)
+	adder.AssertNotCalled(t, "AddOffer", mock.Anything, mock.Anything)
+}
+
+func TestMakeOffer_AlreadyPending(t *testing.T) {
+	t.Parallel()
+
+	adder := new(mockOfferAdder)
+	posts := new(mockPostProvider)
+
+	service := New(slog.Default(), adder, nil, nil, posts, nil, time.Hour)
+
+	postID := uuid.NewV4().String()
+
+	posts.On("ListedPostByID", mock.Anything, postID).Return(&models.PostWithDocument{ID: postID, OwnerID: "seller"}, nil)
+	adder.On("AddOffer", mock.Anything, mock.Anything).Return(&models.UniqueConstraintError{Err: models.ErrUNIQUEConstraintFailed})
+
+	_, err := service.MakeOffer(context.Background(), &models.User{ID: "buyer"}, postID, 900)
+
+	assert.ErrorIs(t, err, models.ErrOfferExists)
+}
+
+func TestOffers_SellerSeesAll(t *testing.T) {
+	t.Parallel()
+
+	provider := new(mockOfferProvider)
+	posts := new(mockPostProvider)
+
+	service := New(slog.Default(), nil, provider, nil, posts, nil, time.Hour)
+
+	postID := uuid.NewV4().String()
+
+	posts.On("PostByID", mock.Anything, postID).Return(&models.PostWithDocument{ID: postID, OwnerID: "seller"}, nil)
+	provider.On("OffersByPost", mock.Anything, postID, "").Return([]*models.Offer{}, nil)
+	provider.On("OffersByPost", mock.Anything, postID, "buyer").Return([]*models.Offer{}, nil)
+
+	_, err := service.Offers(context.Background(), &models.User{ID: "seller"}, postID)
+	assert.NoError(t, err)
+
+	_, err = service.Offers(context.Background(), &models.User{ID: "buyer"}, postID)
+	assert.NoError(t, err)
+
+	provider.AssertExpectations(t)
+}
+
+func TestMyOffers_InvalidRole(t *testing.T) {
+	t.Parallel()
+
+	service := New(slog.Default(), nil, nil, nil, nil, nil, time.Hour)
+
+	_, err := service.MyOffers(context.Background(), &models.User{ID: "buyer"}, "admin", 10, 0)
+
+	assert.ErrorIs(t, err, models.ErrInvalidParams)
+}
+
+func TestAcceptOffer_Success(t *testing.T) {
+	t.Parallel()
+
+	provider := new(mockOfferProvider)
+	updater := new(mockOfferUpdater)
+	notifier := new(mockNotifier)
+
+	service := New(slog.Default(), nil, provider, updater, nil, notifier, time.Hour)
+
+	postID := uuid.NewV4().String()
+	offer := pendingOffer(postID)
+
+	provider.On("OfferByID", mock.Anything, offer.ID).Return(offer, nil)
+	updater.On("UpdateOfferStatus", mock.Anything, offer.ID, models.OfferAccepted, mock.Anything).Return(nil)
+	notifier.On("Notify", mock.Anything, "buyer", models.NotificationOffer, mock.Anything).Return(nil)
+
+	accepted, err := service.AcceptOffer(context.Background(), &models.User{ID: "seller"}, postID, offer.ID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, models.OfferAccepted, accepted.Status)
+	assert.NotNil(t, accepted.RespondedAt)
+	updater.AssertExpectations(t)
+	notifier.AssertExpectations(t)
+}
+
+func TestAcceptOffer_ProposerCannotAnswer(t *testing.T) {
+	t.Parallel()
+
+	provider := new(mockOfferProvider)
+
+	service := New(slog.Default(), nil, provider, nil, nil, nil, time.Hour)
+
+	postID := uuid.NewV4().String()
+	offer := pendingOffer(postID)
+
+	provider.On("OfferByID", mock.Anything, offer.ID).Return(offer, nil)
+
+	_, err := service.AcceptOffer(context.Background(), &models.User{ID: "buyer"}, postID, offer.ID)
+
+	assert.ErrorIs(t, err, models.ErrForbidden)
+}
+
+func TestAcceptOffer_OtherNegotiation(t *testing.T) {
+	t.Parallel()
+
+	provider := new(mockOfferProvider)
+
+	service := New(slog.Default(), nil, provider, nil, nil, nil, time.Hour)
+
+	postID := uuid.NewV4().String()
+	offer := pendingOffer(postID)
+
+	provider.On("OfferByID", mock.Anything, offer.ID).Return(offer, nil)
+
+	_, err := service.AcceptOffer(context.Background(), &models.User{ID: "stranger"}, postID, offer.ID)
+	assert.ErrorIs(t, err, models.ErrOfferNotFound)
+
+	_, err = service.AcceptOffer(context.Background(), &models.User{ID: "seller"}, uuid.NewV4().String(), offer.ID)
+	assert.ErrorIs(t, err, models.ErrOfferNotFound)
+}
+
+func TestAcceptOffer_Expired(t *testing.T) {
+	t.Parallel()
+
+	provider := new(mockOfferProvider)
+
+	service := New(slog.Default(), nil, provider, nil, nil, nil, time.Hour)
+
+	postID := uuid.NewV4().String()
+	offer := pendingOffer(postID)
+	offer.ExpiresAt = time.Now().Add(-time.Minute)
+
+	provider.On("OfferByID", mock.Anything, offer.ID).Return(offer, nil)
+
+	_, err := service.AcceptOffer(context.Background(), &models.User{ID: "seller"}, postID, offer.ID)
+
+	assert.ErrorIs(t, err, models.ErrOfferClosed)
+}
+
+func TestRejectOffer_RaceLost(t *testing.T) {
+	t.Parallel()
+
+	provider := new(mockOfferProvider)
+	updater := new(mockOfferUpdater)
+
+	service := New(slog.Default(), nil, provider, updater, nil, nil, time.Hour)
+
+	postID := uuid.NewV4().String()
+	offer := pendingOffer(postID)
+
+	provider.On("OfferByID", mock.Anything, offer.ID).Return(offer, nil)
+	updater.On("UpdateOfferStatus", mock.Anything, offer.ID, models.OfferRejected, mock.Anything).Return(models.ErrOfferClosed)
+
+	_, err := service.RejectOffer(context.Background(), &models.User{ID: "seller"}, postID, offer.ID)
+
+	assert.ErrorIs(t, err, models.ErrOfferClosed)
+}
+
+func TestCounterOffer_Success(t *testing.T) {
+	t.Parallel()
+
+	provider := new(mockOfferProvider)
+	updater := new(mockOfferUpdater)
+	notifier := new(mockNotifier)
+
+	service := New(slog.Default(), nil, provider, updater, nil, notifier, time.Hour)
+
+	postID := uuid.NewV4().String()
+	offer := pendingOffer(postID)
+
+	provider.On("OfferByID", mock.Anything, offer.ID).Return(offer, nil)
+	updater.On("CounterOffer", mock.Anything, offer.ID, mock.MatchedBy(func(c *models.Offer) bool {
+		return c.PreviousID == offer.ID && c.ProposerID == "seller" && c.Amount == 950 && c.Status == models.OfferPending
+	})).Return(nil)
+	notifier.On("Notify", mock.Anything, "buyer", models.NotificationOffer, mock.Anything).Return(nil)
+
+	counter, err := service.CounterOffer(context.Background(), &models.User{ID: "seller"}, postID, offer.ID, 950)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "buyer", counter.Recipient())
+	updater.AssertExpectations(t)
+	notifier.AssertExpectations(t)
+}
+
+func TestCounterOffer_InvalidAmount(t *testing.T) {
+	t.Parallel()
+
+	service := New(slog.Default(), nil, nil, nil, nil, nil, time.Hour)
+
+	_, err := service.CounterOffer(context.Background(), &models.User{ID: "seller"}, "", "", 0)
+
+	assert.ErrorIs(t, err, models.ErrInvalidPrice)
+}
+
+func TestExpirer_NotifiesBothSides(t *testing.T) {
+	t.Parallel()
+
+	updater := new(mockOfferUpdater)
+	notifier := new(mockNotifier)
+
+	expired := &models.Offer{ID: "o1", BuyerID: "buyer", SellerID: "seller", Status: models.OfferExpired}
+
+	updater.On("ExpireOffers", mock.Anything, mock.Anything).Return([]*models.Offer{expired}, nil)
+	notifier.On("Notify", mock.Anything, "buyer", models.NotificationOffer, mock.Anything).Return(nil)
+	notifier.On("Notify", mock.Anything, "seller", models.NotificationOffer, mock.Anything).Return(errors.New("ignored"))
+
+	NewExpirer(slog.Default(), updater, notifier, time.Minute).expire(context.Background())
+
+	notifier.AssertExpectations(t)
+}
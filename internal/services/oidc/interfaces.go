@@ -0,0 +1,30 @@
+package oidcservice
+
+import (
+	"context"
+	"marketplace/internal/models"
+)
+
+type Provider interface {
+	AuthCodeURL(ctx context.Context, state string, nonce string, verifier string) (string, error)
+	Exchange(ctx context.Context, code string, verifier string, nonce string) (*models.IDTokenClaims, error)
+}
+
+type IdentityStorer interface {
+	IdentityBySubject(ctx context.Context, provider string, subject string) (*models.ExternalIdentity, error)
+	AddIdentity(ctx context.Context, identity *models.ExternalIdentity) error
+	AddUserWithIdentity(ctx context.Context, user *models.User, identity *models.ExternalIdentity) error
+}
+
+type StateStorer interface {
+	SaveState(ctx context.Context, state string, oidcState *models.OIDCState) error
+	ConsumeState(ctx context.Context, state string) (*models.OIDCState, error)
+}
+
+type UserProvider interface {
+	UserByID(ctx context.Context, id string) (*models.User, error)
+}
+
+type SessionOpener interface {
+	LoginUser(ctx context.Context, user *models.User, client *models.ClientInfo) (*models.LoginResult, error)
+}
@@ -0,0 +1,332 @@
+package oidcservice
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"errors"
+	"log/slog"
+	"marketplace/internal/models"
+	"marketplace/internal/utils/token"
+	"math/big"
+	"strings"
+	"time"
+	"unicode"
+
+	uuid "github.com/satori/go.uuid"
+)
+
+const pkg = "oidcService/"
+
+const (
+	loginConstraint = "users_login_key"
+
+	// loginAttempts is how many logins are tried for a new user before
+	// giving up, the first from the claims and the rest with a random suffix.
+	loginAttempts  = 5
+	maxLoginLength = 24
+)
+
+// errIdentityTaken means a concurrent first login of the same identity has
+// created its user.
+var errIdentityTaken = errors.New("identity taken")
+
+type OIDCService struct {
+	log        *slog.Logger
+	providers  map[string]Provider
+	identities IdentityStorer
+	states     StateStorer
+	users      UserProvider
+	sessions   SessionOpener
+	stateTTL   time.Duration
+}
+
+func New(
+	log *slog.Logger,
+	providers map[string]Provider,
+	identities IdentityStorer,
+	states StateStorer,
+	users UserProvider,
+	sessions SessionOpener,
+	stateTTL time.Duration,
+) *OIDCService {
+	return &OIDCService{
+		log:        log,
+		providers:  providers,
+		identities: identities,
+		states:     states,
+		users:      users,
+		sessions:   sessions,
+		stateTTL:   stateTTL,
+	}
+}
+
+// Begin returns the provider page to send the user to, with the binding the
+// browser has to present on the callback. When requester is set, the
+// identity the provider returns is linked to the requester instead of being
+// logged in with.
+func (s *OIDCService) Begin(ctx context.Context, providerName string, requester *models.User) (*models.ExternalStart, error) {
+	op := pkg + "Begin"
+
+	log := s.log.With(slog.String("op", op), slog.String("provider", providerName))
+
+	log.Debug("attempting to begin external login")
+
+	provider, ok := s.providers[providerName]
+	if !ok {
+		log.Warn("unknown provider")
+		return nil, models.ErrProviderNotFound
+	}
+
+	var secrets [3]string
+	for i := range secrets {
+		secret, err := token.New()
+		if err != nil {
+			log.Error("failed to generate state", slog.String("error", err.Error()))
+			return nil, models.ErrInternal
+		}
+		secrets[i] = secret
+	}
+	state, nonce, verifier := secrets[0], secrets[1], secrets[2]
+
+	authURL, err := provider.AuthCodeURL(ctx, state, nonce, verifier)
+	if err != nil {
+		log.Error("failed to build authorization url", slog.String("error", err.Error()))
+		return nil, models.ErrInternal
+	}
+
+	now := time.Now().UTC()
+
+	oidcState := &models.OIDCState{
+		Provider:  providerName,
+		Verifier:  verifier,
+		Nonce:     nonce,
+		CreatedAt: now,
+	}
+	if requester != nil {
+		oidcState.UserID = requester.ID
+	}
+
+	if err := s.states.SaveState(ctx, state, oidcState); err != nil {
+		log.Error("failed to save state", slog.String("error", err.Error()))
+		return nil, models.ErrInternal
+	}
+
+	log.Debug("external login started", slog.Bool("link", requester != nil))
+
+	return &models.ExternalStart{
+		AuthURL:   authURL,
+		Binding:   token.Hash(state),
+		ExpiresAt: now.Add(s.stateTTL),
+	}, nil
+}
+
+// Callback finishes the flow started by Begin. The identity is linked to the
+// user that started it, or logged in with; on its first login a user is
+// created for it. Existing accounts are never matched by email, as the
+// provider may let anyone claim any address.
+//
+// The binding from Begin has to match the state, so that a callback link
+// made by someone else cannot sign the browser in as them or link their
+// identity to its account.
+func (s *OIDCService) Callback(ctx context.Context, providerName string, state string, binding string, code string, client *models.ClientInfo) (*models.ExternalLogin, error) {
+	op := pkg + "Callback"
+
+	log := s.log.With(slog.String("op", op), slog.String("provider", providerName))
+
+	log.Debug("attempting to complete external login")
+
+	provider, ok := s.providers[providerName]
+	if !ok {
+		log.Warn("unknown provider")
+		return nil, models.ErrProviderNotFound
+	}
+
+	if subtle.ConstantTimeCompare([]byte(binding), []byte(token.Hash(state))) != 1 {
+		log.Warn("state not bound to this browser")
+		return nil, models.ErrInvalidOIDCState
+	}
+
+	oidcState, err := s.states.ConsumeState(ctx, state)
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidOIDCState) {
+			log.Warn("state not found")
+			return nil, models.ErrInvalidOIDCState
+		}
+		log.Error("failed to consume state", slog.String("error", err.Error()))
+		return nil, models.ErrInternal
+	}
+
+	if oidcState.Provider != providerName {
+		log.Warn("state issued for another provider", slog.String("state_provider", oidcState.Provider))
+		return nil, models.ErrInvalidOIDCState
+	}
+
+	claims, err := provider.Exchange(ctx, code, oidcState.Verifier, oidcState.Nonce)
+	if err != nil {
+		log.Warn("failed to exchange code", slog.String("error", err.Error()))
+		return nil, models.ErrExternalLoginFailed
+	}
+
+	if oidcState.UserID != "" {
+		if err := s.link(ctx, log, providerName, claims.Subject, oidcState.UserID); err != nil {
+			return nil, err
+		}
+
+		log.Debug("identity linked successfully", slog.String("user_id", oidcState.UserID))
+
+		return &models.ExternalLogin{LinkedUserID: oidcState.UserID}, nil
+	}
+
+	user, err := s.userFor(ctx, log, providerName, claims)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.sessions.LoginUser(ctx, user, client)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Debug("external login completed successfully", slog.String("user_id", user.ID))
+
+	return &models.ExternalLogin{Login: result}, nil
+}
+
+func (s *OIDCService) link(ctx context.Context, log *slog.Logger, providerName string, subject string, userID string) error {
+	identity, err := s.identities.IdentityBySubject(ctx, providerName, subject)
+	if err == nil {
+		if identity.UserID != userID {
+			log.Warn("identity belongs to another user")
+			return models.ErrIdentityLinked
+		}
+		return nil
+	}
+	if !errors.Is(err, models.ErrIdentityNotFound) {
+		log.Error("failed to get identity", slog.String("error", err.Error()))
+		return models.ErrInternal
+	}
+
+	err = s.identities.AddIdentity(ctx, &models.ExternalIdentity{
+		Provider:  providerName,
+		Subject:   subject,
+		UserID:    userID,
+		CreatedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		var uce *models.UniqueConstraintError
+		if errors.As(err, &uce) {
+			log.Warn("identity or provider already linked", slog.String("constraint", uce.Constraint))
+			return models.ErrIdentityLinked
+		}
+		log.Error("failed to add identity", slog.String("error", err.Error()))
+		return models.ErrInternal
+	}
+
+	return nil
+}
+
+// userFor returns the user the identity is linked to, creating one on the
+// first login.
+func (s *OIDCService) userFor(ctx context.Context, log *slog.Logger, providerName string, claims *models.IDTokenClaims) (*models.User, error) {
+	identity, err := s.identities.IdentityBySubject(ctx, providerName, claims.Subject)
+	if err != nil && !errors.Is(err, models.ErrIdentityNotFound) {
+		log.Error("failed to get identity", slog.String("error", err.Error()))
+		return nil, models.ErrInternal
+	}
+
+	if identity == nil {
+		user, err := s.addUser(ctx, log, providerName, claims)
+		if err == nil || !errors.Is(err, errIdentityTaken) {
+			return user, err
+		}
+
+		identity, err = s.identities.IdentityBySubject(ctx, providerName, claims.Subject)
+		if err != nil {
+			log.Error("failed to get identity", slog.String("error", err.Error()))
+			return nil, models.ErrInternal
+		}
+	}
+
+	user, err := s.users.UserByID(ctx, identity.UserID)
+	if err != nil {
+		log.Error("failed to get user", slog.String("error", err.Error()))
+		return nil, models.ErrInternal
+	}
+
+	return user, nil
+}
+
+// addUser creates a user without a password for the identity. The login is
+// taken from the claims and made unique with a random suffix when taken.
+func (s *OIDCService) addUser(ctx context.Context, log *slog.Logger, providerName string, claims *models.IDTokenClaims) (*models.User, error) {
+	base := baseLogin(claims)
+
+	for attempt := 0; attempt < loginAttempts; attempt++ {
+		login := base
+		if attempt > 0 {
+			suffix, err := rand.Int(rand.Reader, big.NewInt(1_000_000))
+			if err != nil {
+				log.Error("failed to generate login", slog.String("error", err.Error()))
+				return nil, models.ErrInternal
+			}
+			login = base + suffix.String()
+		}
+
+		user := &models.User{
+			ID:       uuid.NewV4().String(),
+			Login:    login,
+			PassHash: []byte{},
+		}
+
+		err := s.identities.AddUserWithIdentity(ctx, user, &models.ExternalIdentity{
+			Provider:  providerName,
+			Subject:   claims.Subject,
+			UserID:    user.ID,
+			CreatedAt: time.Now().UTC(),
+		})
+		if err == nil {
+			log.Info("user created for external identity", slog.String("user_id", user.ID))
+			return user, nil
+		}
+
+		var uce *models.UniqueConstraintError
+		if !errors.As(err, &uce) {
+			log.Error("failed to add user", slog.String("error", err.Error()))
+			return nil, models.ErrInternal
+		}
+		if uce.Constraint != loginConstraint {
+			log.Warn("identity added concurrently", slog.String("constraint", uce.Constraint))
+			return nil, errIdentityTaken
+		}
+	}
+
+	log.Error("failed to find a free login", slog.String("login", base))
+
+	return nil, models.ErrInternal
+}
+
+// baseLogin makes a valid login out of the preferred username or the local
+// part of the email.
+func baseLogin(claims *models.IDTokenClaims) string {
+	source := claims.PreferredUsername
+	if source == "" {
+		source, _, _ = strings.Cut(claims.Email, "@")
+	}
+
+	var login strings.Builder
+	for _, r := range source {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			login.WriteRune(r)
+		}
+		if login.Len() == maxLoginLength {
+			break
+		}
+	}
+
+	if login.Len() < 4 {
+		return "user" + login.String()
+	}
+
+	return login.String()
+}
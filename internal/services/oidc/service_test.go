@@ -0,0 +1,360 @@
+package oidcservice
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"marketplace/internal/models"
+	oidcclient "marketplace/internal/oidc/client"
+	fakeoidc "marketplace/internal/oidc/fake"
+	"marketplace/internal/utils/token"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockProvider struct {
+	mock.Mock
+}
+
+func (m *mockProvider) AuthCodeURL(ctx context.Context, state, nonce, verifier string) (string, error) {
+	args := m.Called(ctx, state, nonce, verifier)
+	return args.String(0), args.Error(1)
+}
+
+func (m *mockProvider) Exchange(ctx context.Context, code, verifier, nonce string) (*models.IDTokenClaims, error) {
+	args := m.Called(ctx, code, verifier, nonce)
+	claims, _ := args.Get(0).(*models.IDTokenClaims)
+	return claims, args.Error(1)
+}
+
+type mockIdentityStorer struct {
+	mock.Mock
+}
+
+func (m *mockIdentityStorer) IdentityBySubject(ctx context.Context, provider, subject string) (*models.ExternalIdentity, error) {
+	args := m.Called(ctx, provider, subject)
+	identity, _ := args.Get(0).(*models.ExternalIdentity)
+	return identity, args.Error(1)
+}
+
+func (m *mockIdentityStorer) AddIdentity(ctx context.Context, identity *models.ExternalIdentity) error {
+	return m.Called(ctx, identity).Error(0)
+}
+
+func (m *mockIdentityStorer) AddUserWithIdentity(ctx context.Context, user *models.User, identity *models.ExternalIdentity) error {
+	return m.Called(ctx, user, identity).Error(0)
+}
+
+// memoryStates keeps states like the cache does, so that a flow can run from
+// Begin to Callback.
+type memoryStates struct {
+	states map[string]*models.OIDCState
+}
+
+func (m *memoryStates) SaveState(ctx context.Context, state string, oidcState *models.OIDCState) error {
+	m.states[state] = oidcState
+	return nil
+}
+
+func (m *memoryStates) ConsumeState(ctx context.Context, state string) (*models.OIDCState, error) {
+	oidcState, ok := m.states[state]
+	if !ok {
+		return nil, models.ErrInvalidOIDCState
+	}
+	delete(m.states, state)
+	return oidcState, nil
+}
+
+type mockUserProvider struct {
+	mock.Mock
+}
+
+func (m *mockUserProvider) UserByID(ctx context.Context, id string) (*models.User, error) {
+	args := m.Called(ctx, id)
+	user, _ := args.Get(0).(*models.User)
+	return user, args.Error(1)
+}
+
+type mockSessionOpener struct {
+	mock.Mock
+}
+
+func (m *mockSessionOpener) LoginUser(ctx context.Context, user *models.User, client *models.ClientInfo) (*models.LoginResult, error) {
+	args := m.Called(ctx, user, client)
+	result, _ := args.Get(0).(*models.LoginResult)
+	return result, args.Error(1)
+}
+
+var client = &models.ClientInfo{UserAgent: "test-agent", IP: "10.0.0.1"}
+
+var tokens = &models.LoginResult{Tokens: &models.TokenPair{AccessToken: "a1", RefreshToken: "r1"}}
+
+func newLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func TestCodeFlow_FakeIssuer(t *testing.T) {
+	t.Parallel()
+
+	issuer, err := fakeoidc.New("marketplace", "secret")
+	assert.NoError(t, err)
+	defer issuer.Close()
+
+	issuer.SignInAs(fakeoidc.User{Subject: "sub-1", PreferredUsername: "jane.doe"})
+
+	provider := oidcclient.New(issuer.Client(), oidcclient.Config{
+		Issuer:       issuer.URL(),
+		ClientID:     "marketplace",
+		ClientSecret: "secret",
+		RedirectURL:  "http://localhost:8082/api/auth/oidc/fake/callback",
+	})
+
+	identities := new(mockIdentityStorer)
+	sessions := new(mockSessionOpener)
+
+	service := New(newLogger(), map[string]Provider{"fake": provider}, identities, &memoryStates{states: map[string]*models.OIDCState{}}, nil, sessions, time.Minute)
+
+	identities.On("IdentityBySubject", mock.Anything, "fake", "sub-1").Return(nil, models.ErrIdentityNotFound)
+	identities.On("AddUserWithIdentity", mock.Anything, mock.MatchedBy(func(user *models.User) bool {
+		return user.Login == "janedoe" && user.PassHash != nil && len(user.PassHash) == 0
+	}), mock.MatchedBy(func(identity *models.ExternalIdentity) bool {
+		return identity.Provider == "fake" && identity.Subject == "sub-1"
+	})).Return(nil)
+	sessions.On("LoginUser", mock.Anything, mock.Anything, client).Return(tokens, nil)
+
+	start, err := service.Begin(context.Background(), "fake", nil)
+	assert.NoError(t, err)
+
+	code, state, err := issuer.Authorize(start.AuthURL)
+	assert.NoError(t, err)
+
+	result, err := service.Callback(context.Background(), "fake", state, start.Binding, code, client)
+
+	assert.NoError(t, err)
+	assert.Equal(t, tokens, result.Login)
+	identities.AssertExpectations(t)
+
+	_, err = service.Callback(context.Background(), "fake", state, start.Binding, code, client)
+	assert.ErrorIs(t, err, models.ErrInvalidOIDCState)
+}
+
+func TestBegin_UnknownProvider(t *testing.T) {
+	t.Parallel()
+
+	service := New(newLogger(), map[string]Provider{}, nil, nil, nil, nil, time.Minute)
+
+	_, err := service.Begin(context.Background(), "github", nil)
+
+	assert.ErrorIs(t, err, models.ErrProviderNotFound)
+}
+
+func TestBegin_RemembersRequester(t *testing.T) {
+	t.Parallel()
+
+	provider := new(mockProvider)
+	states := &memoryStates{states: map[string]*models.OIDCState{}}
+
+	service := New(newLogger(), map[string]Provider{"fake": provider}, nil, states, nil, nil, time.Minute)
+
+	provider.On("AuthCodeURL", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return("https://idp/authorize", nil)
+
+	start, err := service.Begin(context.Background(), "fake", &models.User{ID: "u1"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "https://idp/authorize", start.AuthURL)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), start.ExpiresAt, time.Second)
+	assert.Len(t, states.states, 1)
+	for state, oidcState := range states.states {
+		assert.Equal(t, "u1", oidcState.UserID)
+		assert.Equal(t, token.Hash(state), start.Binding)
+		provider.AssertCalled(t, "AuthCodeURL", mock.Anything, state, oidcState.Nonce, oidcState.Verifier)
+	}
+}
+
+func TestCallback(t *testing.T) {
+	t.Parallel()
+
+	claims := &models.IDTokenClaims{Subject: "sub-1", Email: "jane@example.com"}
+	user := &models.User{ID: "u1", Login: "jane"}
+
+	tests := []struct {
+		name      string
+		state     *models.OIDCState
+		identity  *models.ExternalIdentity
+		addErr    error
+		wantLink  string
+		wantLogin bool
+		wantErr   error
+	}{
+		{
+			name:      "linked identity logs in",
+			state:     &models.OIDCState{Provider: "fake"},
+			identity:  &models.ExternalIdentity{Provider: "fake", Subject: "sub-1", UserID: "u1"},
+			wantLogin: true,
+		},
+		{
+			name:     "link to requester",
+			state:    &models.OIDCState{Provider: "fake", UserID: "u1"},
+			wantLink: "u1",
+		},
+		{
+			name:     "link again is a no-op",
+			state:    &models.OIDCState{Provider: "fake", UserID: "u1"},
+			identity: &models.ExternalIdentity{Provider: "fake", Subject: "sub-1", UserID: "u1"},
+			wantLink: "u1",
+		},
+		{
+			name:     "identity of another user",
+			state:    &models.OIDCState{Provider: "fake", UserID: "u2"},
+			identity: &models.ExternalIdentity{Provider: "fake", Subject: "sub-1", UserID: "u1"},
+			wantErr:  models.ErrIdentityLinked,
+		},
+		{
+			name:    "requester already linked at provider",
+			state:   &models.OIDCState{Provider: "fake", UserID: "u1"},
+			addErr:  &models.UniqueConstraintError{Constraint: "user_identities_user_id_provider_key", Err: models.ErrUNIQUEConstraintFailed},
+			wantErr: models.ErrIdentityLinked,
+		},
+		{
+			name:    "state of another provider",
+			state:   &models.OIDCState{Provider: "other"},
+			wantErr: models.ErrInvalidOIDCState,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			provider := new(mockProvider)
+			identities := new(mockIdentityStorer)
+			users := new(mockUserProvider)
+			sessions := new(mockSessionOpener)
+			states := &memoryStates{states: map[string]*models.OIDCState{"state-1": tt.state}}
+
+			service := New(newLogger(), map[string]Provider{"fake": provider}, identities, states, users, sessions, time.Minute)
+
+			provider.On("Exchange", mock.Anything, "code-1", mock.Anything, mock.Anything).Return(claims, nil)
+			if tt.identity != nil {
+				identities.On("IdentityBySubject", mock.Anything, "fake", "sub-1").Return(tt.identity, nil)
+			} else {
+				identities.On("IdentityBySubject", mock.Anything, "fake", "sub-1").Return(nil, models.ErrIdentityNotFound)
+			}
+			identities.On("AddIdentity", mock.Anything, mock.Anything).Return(tt.addErr)
+			users.On("UserByID", mock.Anything, "u1").Return(user, nil)
+			sessions.On("LoginUser", mock.Anything, user, client).Return(tokens, nil)
+
+			result, err := service.Callback(context.Background(), "fake", "state-1", token.Hash("state-1"), "code-1", client)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				sessions.AssertNotCalled(t, "LoginUser", mock.Anything, mock.Anything, mock.Anything)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantLink, result.LinkedUserID)
+			if tt.wantLogin {
+				assert.Equal(t, tokens, result.Login)
+			} else {
+				assert.Nil(t, result.Login)
+				sessions.AssertNotCalled(t, "LoginUser", mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestCallback_Unbound(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		binding string
+	}{
+		{name: "no cookie"},
+		{name: "flow of another browser", binding: token.Hash("state-2")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			provider := new(mockProvider)
+			states := &memoryStates{states: map[string]*models.OIDCState{"state-1": {Provider: "fake", UserID: "u1"}}}
+
+			service := New(newLogger(), map[string]Provider{"fake": provider}, nil, states, nil, nil, time.Minute)
+
+			_, err := service.Callback(context.Background(), "fake", "state-1", tt.binding, "code-1", client)
+
+			assert.ErrorIs(t, err, models.ErrInvalidOIDCState)
+			assert.Contains(t, states.states, "state-1")
+			provider.AssertNotCalled(t, "Exchange", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestCallback_ExchangeFails(t *testing.T) {
+	t.Parallel()
+
+	provider := new(mockProvider)
+	states := &memoryStates{states: map[string]*models.OIDCState{"state-1": {Provider: "fake"}}}
+
+	service := New(newLogger(), map[string]Provider{"fake": provider}, nil, states, nil, nil, time.Minute)
+
+	provider.On("Exchange", mock.Anything, "code-1", mock.Anything, mock.Anything).Return(nil, oidcclient.ErrInvalidIDToken)
+
+	_, err := service.Callback(context.Background(), "fake", "state-1", token.Hash("state-1"), "code-1", client)
+
+	assert.ErrorIs(t, err, models.ErrExternalLoginFailed)
+}
+
+func TestCallback_LoginTaken(t *testing.T) {
+	t.Parallel()
+
+	provider := new(mockProvider)
+	identities := new(mockIdentityStorer)
+	sessions := new(mockSessionOpener)
+	states := &memoryStates{states: map[string]*models.OIDCState{"state-1": {Provider: "fake"}}}
+
+	service := New(newLogger(), map[string]Provider{"fake": provider}, identities, states, nil, sessions, time.Minute)
+
+	provider.On("Exchange", mock.Anything, "code-1", mock.Anything, mock.Anything).
+		Return(&models.IDTokenClaims{Subject: "sub-1", PreferredUsername: "jane"}, nil)
+	identities.On("IdentityBySubject", mock.Anything, "fake", "sub-1").Return(nil, models.ErrIdentityNotFound)
+	identities.On("AddUserWithIdentity", mock.Anything, mock.MatchedBy(func(user *models.User) bool {
+		return user.Login == "jane"
+	}), mock.Anything).Return(&models.UniqueConstraintError{Constraint: "users_login_key", Err: models.ErrUNIQUEConstraintFailed}).Once()
+	identities.On("AddUserWithIdentity", mock.Anything, mock.MatchedBy(func(user *models.User) bool {
+		return user.Login != "jane" && strings.HasPrefix(user.Login, "jane")
+	}), mock.Anything).Return(nil).Once()
+	sessions.On("LoginUser", mock.Anything, mock.Anything, client).Return(tokens, nil)
+
+	result, err := service.Callback(context.Background(), "fake", "state-1", token.Hash("state-1"), "code-1", client)
+
+	assert.NoError(t, err)
+	assert.Equal(t, tokens, result.Login)
+	identities.AssertExpectations(t)
+}
+
+func TestBaseLogin(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		claims models.IDTokenClaims
+		want   string
+	}{
+		{claims: models.IDTokenClaims{PreferredUsername: "jane.doe"}, want: "janedoe"},
+		{claims: models.IDTokenClaims{Email: "john_smith@example.com"}, want: "johnsmith"},
+		{claims: models.IDTokenClaims{PreferredUsername: "Øle"}, want: "userle"},
+		{claims: models.IDTokenClaims{}, want: "user"},
+		{claims: models.IDTokenClaims{PreferredUsername: strings.Repeat("a", 40)}, want: strings.Repeat("a", 24)},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, baseLogin(&tt.claims))
+	}
+}
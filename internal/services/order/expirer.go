@@ -0,0 +1,66 @@
+package orderservice
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// Expirer periodically cancels orders that were not paid in time, so that
+// their posts can be ordered again.
+type Expirer struct {
+	log          *slog.Logger
+	orderUpdater OrderUpdater
+	notifier     Notifier
+	interval     time.Duration
+}
+
+func NewExpirer(
+	log *slog.Logger,
+	orderUpdater OrderUpdater,
+	notifier Notifier,
+	interval time.Duration,
+) *Expirer {
+	return &Expirer{
+		log:          log,
+		orderUpdater: orderUpdater,
+		notifier:     notifier,
+		interval:     interval,
+	}
+}
+
+// Run expires orders every interval until ctx is cancelled.
+func (e *Expirer) Run(ctx context.Context) {
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			e.expire(ctx)
+		}
+	}
+}
+
+func (e *Expirer) expire(ctx context.Context) {
+	op := pkg + "expire"
+
+	log := e.log.With(slog.String("op", op))
+
+	orders, err := e.orderUpdater.ExpireOrders(ctx, time.Now())
+	if err != nil {
+		log.Error("failed to expire orders", slog.String("error", err.Error()))
+		return
+	}
+
+	for _, order := range orders {
+		notifyOrder(ctx, log, e.notifier, order.BuyerID, order)
+		notifyOrder(ctx, log, e.notifier, order.SellerID, order)
+	}
+
+	if len(orders) > 0 {
+		log.Debug("orders expired", slog.Int("count", len(orders)))
+	}
+}
@@ -0,0 +1,41 @@
+package orderservice
+
+import (
+	"context"
+	"marketplace/internal/models"
+	"time"
+)
+
+type OrderAdder interface {
+	AddOrder(ctx context.Context, order *models.Order) error
+}
+
+type OrderProvider interface {
+	OrderByID(ctx context.Context, id string) (*models.Order, error)
+	OrdersByUser(ctx context.Context, userID string, role models.OrderRole, limit int, offset int) ([]*models.Order, error)
+	ActiveOrdersByUser(ctx context.Context, userID string) ([]*models.Order, error)
+}
+
+type OrderUpdater interface {
+	UpdateOrderStatus(ctx context.Context, order *models.Order, from models.OrderStatus) error
+	ExpireOrders(ctx context.Context, now time.Time) ([]*models.Order, error)
+}
+
+type PostProvider interface {
+	ListedPostByID(ctx context.Context, id string) (*models.PostWithDocument, error)
+}
+
+type OfferProvider interface {
+	OffersByPost(ctx context.Context, postID string, buyerID string) ([]*models.Offer, error)
+}
+
+// PaymentProvider charges buyers and gives the money back when a paid order
+// is cancelled. Charge must be idempotent per order.
+type PaymentProvider interface {
+	Charge(ctx context.Context, orderID string, amount int64, token string) (string, error)
+	Refund(ctx context.Context, paymentID string) error
+}
+
+type Notifier interface {
+	Notify(ctx context.Context, userID string, kind models.NotificationKind, payload models.NotificationPayload) error
+}
@@ -0,0 +1,395 @@
+package orderservice
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"marketplace/internal/models"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+)
+
+const pkg = "orderService/"
+
+type OrderService struct {
+	log            *slog.Logger
+	orderAdder     OrderAdder
+	orderProvider  OrderProvider
+	orderUpdater   OrderUpdater
+	postProvider   PostProvider
+	offerProvider  OfferProvider
+	payments       PaymentProvider
+	notifier       Notifier
+	reservationTTL time.Duration
+}
+
+func New(
+	log *slog.Logger,
+	orderAdder OrderAdder,
+	orderProvider OrderProvider,
+	orderUpdater OrderUpdater,
+	postProvider PostProvider,
+	offerProvider OfferProvider,
+	payments PaymentProvider,
+	notifier Notifier,
+	reservationTTL time.Duration,
+) *OrderService {
+	return &OrderService{
+		log:            log,
+		orderAdder:     orderAdder,
+		orderProvider:  orderProvider,
+		orderUpdater:   orderUpdater,
+		postProvider:   postProvider,
+		offerProvider:  offerProvider,
+		payments:       payments,
+		notifier:       notifier,
+		reservationTTL: reservationTTL,
+	}
+}
+
+// CreateOrder reserves the post for the requester. The price is the post
+// price, the amount of an accepted offer, or the winning bid for auctions.
+// Hidden posts and posts of banned sellers are not found.
+func (ors *OrderService) CreateOrder(ctx context.Context, requester *models.User, postID string) (*models.Order, error) {
+	op := pkg + "CreateOrder"
+
+	log := ors.log.With(slog.String("op", op))
+
+	log.Debug("attempting to create order")
+
+	if _, err := uuid.FromString(postID); err != nil {
+		log.Warn("invalid post id received", slog.String("post_id", postID))
+		return nil, models.ErrPostNotFound
+	}
+
+	post, err := ors.postProvider.ListedPostByID(ctx, postID)
+	if err != nil {
+		if errors.Is(err, models.ErrPostNotFound) {
+			log.Warn("post not found", slog.String("post_id", postID))
+			return nil, models.ErrPostNotFound
+		}
+		log.Error("failed to get post", slog.String("error", err.Error()))
+		return nil, models.ErrInternal
+	}
+
+	if post.OwnerID == requester.ID {
+		log.Warn("order on own post", slog.String("post_id", postID))
+		return nil, models.ErrOwnPost
+	}
+
+	amount, err := ors.price(ctx, log, requester, post)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	order := &models.Order{
+		ID:          uuid.NewV4().String(),
+		PostID:      post.ID,
+		BuyerID:     requester.ID,
+		BuyerLogin:  requester.Login,
+		SellerID:    post.OwnerID,
+		SellerLogin: post.OwnerLogin,
+		Amount:      amount,
+		Status:      models.OrderPending,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		ExpiresAt:   now.Add(ors.reservationTTL),
+	}
+
+	err = ors.orderAdder.AddOrder(ctx, order)
+	if err != nil {
+		var uce *models.UniqueConstraintError
+		if errors.As(err, &uce) {
+			log.Warn("post is already reserved", slog.String("post_id", postID))
+			return nil, models.ErrPostReserved
+		}
+
+		log.Error("failed to add order", slog.String("error", err.Error()))
+		return nil, models.ErrInternal
+	}
+
+	ors.notify(ctx, log, order.SellerID, order)
+
+	log.Debug("order created successfully", slog.String("order_id", order.ID))
+
+	return order, nil
+}
+
+func (ors *OrderService) Order(ctx context.Context, requester *models.User, id string) (*models.Order, error) {
+	op := pkg + "Order"
+
+	log := ors.log.With(slog.String("op", op))
+
+	log.Debug("attempting to get order")
+
+	return ors.order(ctx, log, requester, id)
+}
+
+func (ors *OrderService) MyOrders(ctx context.Context, requester *models.User, role models.OrderRole, limit int, offset int) ([]*models.Order, error) {
+	op := pkg + "MyOrders"
+
+	log := ors.log.With(slog.String("op", op))
+
+	log.Debug("attempting to list orders")
+
+	switch role {
+	case models.OrderRoleAny, models.OrderRoleBuyer, models.OrderRoleSeller:
+	default:
+		log.Warn("invalid order role received", slog.String("role", string(role)))
+		return nil, models.ErrInvalidParams
+	}
+
+	orders, err := ors.orderProvider.OrdersByUser(ctx, requester.ID, role, limit, offset)
+	if err != nil {
+		log.Error("failed to list orders", slog.String("error", err.Error()))
+		return nil, models.ErrInternal
+	}
+
+	return orders, nil
+}
+
+// PayOrder charges the buyer and confirms a pending order. If the order has
+// moved on while the payment was in flight, the payment is refunded.
+func (ors *OrderService) PayOrder(ctx context.Context, requester *models.User, id string, paymentToken string) (*models.Order, error) {
+	op := pkg + "PayOrder"
+
+	log := ors.log.With(slog.String("op", op))
+
+	log.Debug("attempting to pay order")
+
+	order, err := ors.order(ctx, log, requester, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if order.BuyerID != requester.ID {
+		log.Warn("only the buyer can pay the order", slog.String("order_id", id))
+		return nil, models.ErrForbidden
+	}
+
+	if order.Status != models.OrderPending || !time.Now().Before(order.ExpiresAt) {
+		log.Warn("order can not be paid", slog.String("order_id", id), slog.String("status", string(order.Status)))
+		return nil, models.ErrInvalidTransition
+	}
+
+	paymentID, err := ors.payments.Charge(ctx, order.ID, order.Amount, paymentToken)
+	if err != nil {
+		if errors.Is(err, models.ErrPaymentDeclined) {
+			log.Warn("payment declined", slog.String("order_id", id))
+			return nil, models.ErrPaymentDeclined
+		}
+		log.Error("failed to charge payment", slog.String("error", err.Error()))
+		return nil, models.ErrInternal
+	}
+
+	order.PaymentID = paymentID
+
+	if err := ors.transition(ctx, log, order, models.OrderConfirmed); err != nil {
+		ors.refund(ctx, log, paymentID)
+		return nil, err
+	}
+
+	ors.notify(ctx, log, order.SellerID, order)
+
+	log.Debug("order paid successfully", slog.String("order_id", id))
+
+	return order, nil
+}
+
+// CompleteOrder is called by the buyer once the item has been received.
+func (ors *OrderService) CompleteOrder(ctx context.Context, requester *models.User, id string) (*models.Order, error) {
+	op := pkg + "CompleteOrder"
+
+	log := ors.log.With(slog.String("op", op))
+
+	log.Debug("attempting to complete order")
+
+	order, err := ors.order(ctx, log, requester, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if order.BuyerID != requester.ID {
+		log.Warn("only the buyer can complete the order", slog.String("order_id", id))
+		return nil, models.ErrForbidden
+	}
+
+	if err := ors.transition(ctx, log, order, models.OrderCompleted); err != nil {
+		return nil, err
+	}
+
+	ors.notify(ctx, log, order.SellerID, order)
+
+	log.Debug("order completed successfully", slog.String("order_id", id))
+
+	return order, nil
+}
+
+// CancelOrder releases the reservation. Either side may cancel until the
+// order is completed, a paid order is refunded.
+func (ors *OrderService) CancelOrder(ctx context.Context, requester *models.User, id string) (*models.Order, error) {
+	op := pkg + "CancelOrder"
+
+	log := ors.log.With(slog.String("op", op))
+
+	log.Debug("attempting to cancel order")
+
+	order, err := ors.order(ctx, log, requester, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ors.transition(ctx, log, order, models.OrderCancelled); err != nil {
+		return nil, err
+	}
+
+	if order.PaymentID != "" {
+		ors.refund(ctx, log, order.PaymentID)
+	}
+
+	ors.notify(ctx, log, order.Counterpart(requester.ID), order)
+
+	log.Debug("order cancelled successfully", slog.String("order_id", id))
+
+	return order, nil
+}
+
+// CancelUserOrders cancels every pending or confirmed order the user takes
+// part in before their account is deleted. Paid orders are refunded and the
+// other side is told, as with CancelOrder.
+func (ors *OrderService) CancelUserOrders(ctx context.Context, userID string) error {
+	op := pkg + "CancelUserOrders"
+
+	log := ors.log.With(slog.String("op", op), slog.String("user_id", userID))
+
+	log.Debug("attempting to cancel orders of user")
+
+	orders, err := ors.orderProvider.ActiveOrdersByUser(ctx, userID)
+	if err != nil {
+		log.Error("failed to get active orders", slog.String("error", err.Error()))
+		return models.ErrInternal
+	}
+
+	for _, order := range orders {
+		if err := ors.transition(ctx, log, order, models.OrderCancelled); err != nil {
+			return err
+		}
+
+		if order.PaymentID != "" {
+			ors.refund(ctx, log, order.PaymentID)
+		}
+
+		ors.notify(ctx, log, order.Counterpart(userID), order)
+	}
+
+	log.Debug("orders of user cancelled successfully", slog.Int("count", len(orders)))
+
+	return nil
+}
+
+func (ors *OrderService) price(ctx context.Context, log *slog.Logger, requester *models.User, post *models.PostWithDocument) (int64, error) {
+	if post.Auction != nil {
+		if post.Auction.ClosedAt == nil {
+			log.Warn("order on running auction", slog.String("post_id", post.ID))
+			return 0, models.ErrAuctionNotEnded
+		}
+
+		if post.Auction.LeaderID != requester.ID {
+			log.Warn("order on auction won by someone else", slog.String("post_id", post.ID))
+			return 0, models.ErrForbidden
+		}
+
+		return post.Auction.CurrentBid, nil
+	}
+
+	offers, err := ors.offerProvider.OffersByPost(ctx, post.ID, requester.ID)
+	if err != nil {
+		log.Error("failed to get offers", slog.String("error", err.Error()))
+		return 0, models.ErrInternal
+	}
+
+	for _, offer := range offers {
+		if offer.Status == models.OfferAccepted {
+			return offer.Amount, nil
+		}
+	}
+
+	return post.Price, nil
+}
+
+func (ors *OrderService) order(ctx context.Context, log *slog.Logger, requester *models.User, id string) (*models.Order, error) {
+	if _, err := uuid.FromString(id); err != nil {
+		log.Warn("invalid order id received", slog.String("order_id", id))
+		return nil, models.ErrOrderNotFound
+	}
+
+	order, err := ors.orderProvider.OrderByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, models.ErrOrderNotFound) {
+			log.Warn("order not found", slog.String("order_id", id))
+			return nil, models.ErrOrderNotFound
+		}
+		log.Error("failed to get order", slog.String("error", err.Error()))
+		return nil, models.ErrInternal
+	}
+
+	if !order.HasParticipant(requester.ID) {
+		log.Warn("requester is not a participant", slog.String("order_id", id))
+		return nil, models.ErrOrderNotFound
+	}
+
+	return order, nil
+}
+
+// transition moves order to status to, failing when the state machine does
+// not allow it or when the stored order has been moved concurrently.
+func (ors *OrderService) transition(ctx context.Context, log *slog.Logger, order *models.Order, to models.OrderStatus) error {
+	from := order.Status
+
+	if !from.CanTransitionTo(to) {
+		log.Warn("invalid order transition", slog.String("from", string(from)), slog.String("to", string(to)))
+		return models.ErrInvalidTransition
+	}
+
+	order.Status = to
+	order.UpdatedAt = time.Now()
+
+	err := ors.orderUpdater.UpdateOrderStatus(ctx, order, from)
+	if err != nil {
+		order.Status = from
+
+		if errors.Is(err, models.ErrInvalidTransition) {
+			log.Warn("order changed concurrently", slog.String("order_id", order.ID))
+			return models.ErrInvalidTransition
+		}
+
+		log.Error("failed to update order", slog.String("error", err.Error()))
+		return models.ErrInternal
+	}
+
+	return nil
+}
+
+func (ors *OrderService) refund(ctx context.Context, log *slog.Logger, paymentID string) {
+	if err := ors.payments.Refund(ctx, paymentID); err != nil {
+		log.Error("failed to refund payment", slog.String("payment_id", paymentID), slog.String("error", err.Error()))
+	}
+}
+
+func (ors *OrderService) notify(ctx context.Context, log *slog.Logger, userID string, order *models.Order) {
+	notifyOrder(ctx, log, ors.notifier, userID, order)
+}
+
+func notifyOrder(ctx context.Context, log *slog.Logger, notifier Notifier, userID string, order *models.Order) {
+	err := notifier.Notify(ctx, userID, models.NotificationOrder, models.OrderPayload{
+		OrderID: order.ID,
+		PostID:  order.PostID,
+		Status:  order.Status,
+	})
+	if err != nil {
+		log.Warn("failed to notify about order", slog.String("error", err.Error()))
+	}
+}
@@ -0,0 +1,467 @@
+package orderservice
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"marketplace/internal/models"
+	"marketplace/internal/payments/fake"
+	"testing"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockOrderAdder struct {
+	mock.Mock
+}
+
+func (m *mockOrderAdder) AddOrder(ctx context.Context, order *models.Order) error {
+	args := m.Called(ctx, order)
+	return args.Error(0)
+}
+
+type mockOrderProvider struct {
+	mock.Mock
+}
+
+func (m *mockOrderProvider) OrderByID(ctx context.Context, id string) (*models.Order, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*models.Order), args.Error(1)
+}
+
+func (m *mockOrderProvider) OrdersByUser(ctx context.Context, userID string, role models.OrderRole, limit int, offset int) ([]*models.Order, error) {
+	args := m.Called(ctx, userID, role, limit, offset)
+	return args.Get(0).([]*models.Order), args.Error(1)
+}
+
+func (m *mockOrderProvider) ActiveOrdersByUser(ctx context.Context, userID string) ([]*models.Order, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]*models.Order), args.Error(1)
+}
+
+type mockOrderUpdater struct {
+	mock.Mock
+}
+
+func (m *mockOrderUpdater) UpdateOrderStatus(ctx context.Context, order *models.Order, from models.OrderStatus) error {
+	args := m.Called(ctx, order, from)
+	return args.Error(0)
+}
+
+func (m *mockOrderUpdater) ExpireOrders(ctx context.Context, now time.Time) ([]*models.Order, error) {
+	args := m.Called(ctx, now)
+	return args.Get(0).([]*models.Order), args.Error(1)
+}
+
+type mockPostProvider struct {
+	mock.Mock
+}
+
+func (m *mockPostProvider) ListedPostByID(ctx context.Context, id string) (*models.PostWithDocument, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*models.PostWithDocument), args.Error(1)
+}
+
+type mockOfferProvider struct {
+	mock.Mock
+}
+
+func (m *mockOfferProvider) OffersByPost(ctx context.Context, postID string, buyerID string) ([]*models.Offer, error) {
+	args := m.Called(ctx, postID, buyerID)
+	return args.Get(0).([]*models.Offer), args.Error(1)
+}
+
+type mockNotifier struct {
+	mock.Mock
+}
+
+func (m *mockNotifier) Notify(ctx context.Context, userID string, kind models.NotificationKind, payload models.NotificationPayload) error {
+	args := m.Called(ctx, userID, kind, payload)
+	return args.Error(0)
+}
+
+// memoryOrders keeps orders in a map and applies status updates the way the
+// database does, so that a whole checkout can run against it.
+type memoryOrders struct {
+	orders map[string]*models.Order
+}
+
+func newMemoryOrders() *memoryOrders {
+	return &memoryOrders{orders: make(map[string]*models.Order)}
+}
+
+func (m *memoryOrders) AddOrder(ctx context.Context, order *models.Order) error {
+	for _, stored := range m.orders {
+		if stored.PostID == order.PostID && stored.Status != models.OrderCancelled {
+			return &models.UniqueConstraintError{Constraint: "orders_reserved_post_id_idx", Err: models.ErrUNIQUEConstraintFailed}
+		}
+	}
+
+	stored := *order
+	m.orders[order.ID] = &stored
+
+	return nil
+}
+
+func (m *memoryOrders) OrderByID(ctx context.Context, id string) (*models.Order, error) {
+	stored, ok := m.orders[id]
+	if !ok {
+		return nil, models.ErrOrderNotFound
+	}
+
+	order := *stored
+
+	return &order, nil
+}
+
+func (m *memoryOrders) OrdersByUser(ctx context.Context, userID string, role models.OrderRole, limit int, offset int) ([]*models.Order, error) {
+	return nil, nil
+}
+
+func (m *memoryOrders) ActiveOrdersByUser(ctx context.Context, userID string) ([]*models.Order, error) {
+	orders := make([]*models.Order, 0)
+	for _, stored := range m.orders {
+		if stored.HasParticipant(userID) && (stored.Status == models.OrderPending || stored.Status == models.OrderConfirmed) {
+			order := *stored
+			orders = append(orders, &order)
+		}
+	}
+
+	return orders, nil
+}
+
+func (m *memoryOrders) UpdateOrderStatus(ctx context.Context, order *models.Order, from models.OrderStatus) error {
+	stored, ok := m.orders[order.ID]
+	if !ok || stored.Status != from {
+		return models.ErrInvalidTransition
+	}
+
+	stored.Status = order.Status
+	stored.PaymentID = order.PaymentID
+	stored.UpdatedAt = order.UpdatedAt
+
+	return nil
+}
+
+func (m *memoryOrders) ExpireOrders(ctx context.Context, now time.Time) ([]*models.Order, error) {
+	return nil, nil
+}
+
+func newPost(ownerID string) *models.PostWithDocument {
+	return &models.PostWithDocument{ID: uuid.NewV4().String(), OwnerID: ownerID, OwnerLogin: ownerID + "_login", Price: 1000}
+}
+
+func TestCheckout_EndToEnd(t *testing.T) {
+	t.Parallel()
+
+	orders := newMemoryOrders()
+	posts := new(mockPostProvider)
+	offers := new(mockOfferProvider)
+	notifier := new(mockNotifier)
+	payments := fake.New()
+
+	service := New(slog.Default(), orders, orders, orders, posts, offers, payments, notifier, time.Hour)
+
+	buyer := &models.User{ID: "buyer", Login: "buyer_login"}
+	other := &models.User{ID: "other", Login: "other_login"}
+	post := newPost("seller")
+
+	posts.On("ListedPostByID", mock.Anything, post.ID).Return(post, nil)
+	offers.On("OffersByPost", mock.Anything, post.ID, mock.Anything).Return([]*models.Offer{}, nil)
+	notifier.On("Notify", mock.Anything, "seller", models.NotificationOrder, mock.Anything).Return(nil)
+
+	order, err := service.CreateOrder(context.Background(), buyer, post.ID)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, models.OrderPending, order.Status)
+	assert.Equal(t, int64(1000), order.Amount)
+
+	_, err = service.CreateOrder(context.Background(), other, post.ID)
+	assert.ErrorIs(t, err, models.ErrPostReserved)
+
+	_, err = service.PayOrder(context.Background(), buyer, order.ID, fake.DeclinedToken)
+	assert.ErrorIs(t, err, models.ErrPaymentDeclined)
+
+	paid, err := service.PayOrder(context.Background(), buyer, order.ID, "tok_visa")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, models.OrderConfirmed, paid.Status)
+	assert.NotEmpty(t, paid.PaymentID)
+
+	_, err = service.PayOrder(context.Background(), buyer, order.ID, "tok_visa")
+	assert.ErrorIs(t, err, models.ErrInvalidTransition)
+
+	completed, err := service.CompleteOrder(context.Background(), buyer, order.ID)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, models.OrderCompleted, completed.Status)
+
+	_, err = service.CancelOrder(context.Background(), buyer, order.ID)
+	assert.ErrorIs(t, err, models.ErrInvalidTransition)
+	assert.False(t, payments.Refunded(paid.PaymentID))
+}
+
+func TestCancelOrder_RefundsPaidOrder(t *testing.T) {
+	t.Parallel()
+
+	orders := newMemoryOrders()
+	posts := new(mockPostProvider)
+	offers := new(mockOfferProvider)
+	notifier := new(mockNotifier)
+	payments := fake.New()
+
+	service := New(slog.Default(), orders, orders, orders, posts, offers, payments, notifier, time.Hour)
+
+	buyer := &models.User{ID: "buyer"}
+	seller := &models.User{ID: "seller"}
+	post := newPost("seller")
+
+	posts.On("ListedPostByID", mock.Anything, post.ID).Return(post, nil)
+	offers.On("OffersByPost", mock.Anything, post.ID, "buyer").Return([]*models.Offer{}, nil)
+	notifier.On("Notify", mock.Anything, mock.Anything, models.NotificationOrder, mock.Anything).Return(nil)
+
+	order, err := service.CreateOrder(context.Background(), buyer, post.ID)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	paid, err := service.PayOrder(context.Background(), buyer, order.ID, "tok_visa")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	cancelled, err := service.CancelOrder(context.Background(), seller, order.ID)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, models.OrderCancelled, cancelled.Status)
+	assert.True(t, payments.Refunded(paid.PaymentID))
+	notifier.AssertCalled(t, "Notify", mock.Anything, "buyer", models.NotificationOrder, models.OrderPayload{OrderID: order.ID, PostID: post.ID, Status: models.OrderCancelled})
+
+	_, err = service.CreateOrder(context.Background(), buyer, post.ID)
+	assert.NoError(t, err)
+}
+
+func TestCancelUserOrders(t *testing.T) {
+	t.Parallel()
+
+	orders := newMemoryOrders()
+	posts := new(mockPostProvider)
+	offers := new(mockOfferProvider)
+	notifier := new(mockNotifier)
+	payments := fake.New()
+
+	service := New(slog.Default(), orders, orders, orders, posts, offers, payments, notifier, time.Hour)
+
+	buyer := &models.User{ID: "buyer"}
+	pendingPost := newPost("seller")
+	paidPost := newPost("seller")
+	completedPost := newPost("seller")
+
+	for _, post := range []*models.PostWithDocument{pendingPost, paidPost, completedPost} {
+		posts.On("ListedPostByID", mock.Anything, post.ID).Return(post, nil)
+		offers.On("OffersByPost", mock.Anything, post.ID, "buyer").Return([]*models.Offer{}, nil)
+	}
+	notifier.On("Notify", mock.Anything, mock.Anything, models.NotificationOrder, mock.Anything).Return(nil)
+
+	pending, err := service.CreateOrder(context.Background(), buyer, pendingPost.ID)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	paid, err := service.CreateOrder(context.Background(), buyer, paidPost.ID)
+	if !assert.NoError(t, err) {
+		return
+	}
+	paid, err = service.PayOrder(context.Background(), buyer, paid.ID, "tok_visa")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	completed, err := service.CreateOrder(context.Background(), buyer, completedPost.ID)
+	if !assert.NoError(t, err) {
+		return
+	}
+	_, err = service.PayOrder(context.Background(), buyer, completed.ID, "tok_visa")
+	if !assert.NoError(t, err) {
+		return
+	}
+	completed, err = service.CompleteOrder(context.Background(), buyer, completed.ID)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	err = service.CancelUserOrders(context.Background(), "seller")
+	assert.NoError(t, err)
+
+	assert.Equal(t, models.OrderCancelled, orders.orders[pending.ID].Status)
+	assert.Equal(t, models.OrderCancelled, orders.orders[paid.ID].Status)
+	assert.Equal(t, models.OrderCompleted, orders.orders[completed.ID].Status)
+	assert.True(t, payments.Refunded(paid.PaymentID))
+	notifier.AssertCalled(t, "Notify", mock.Anything, "buyer", models.NotificationOrder, models.OrderPayload{OrderID: pending.ID, PostID: pendingPost.ID, Status: models.OrderCancelled})
+	notifier.AssertCalled(t, "Notify", mock.Anything, "buyer", models.NotificationOrder, models.OrderPayload{OrderID: paid.ID, PostID: paidPost.ID, Status: models.OrderCancelled})
+}
+
+func TestPayOrder_RefundsWhenOrderMoved(t *testing.T) {
+	t.Parallel()
+
+	provider := new(mockOrderProvider)
+	updater := new(mockOrderUpdater)
+	payments := fake.New()
+
+	service := New(slog.Default(), nil, provider, updater, nil, nil, payments, nil, time.Hour)
+
+	id := uuid.NewV4().String()
+
+	provider.On("OrderByID", mock.Anything, id).Return(&models.Order{ID: id, BuyerID: "buyer", SellerID: "seller", Status: models.OrderPending, ExpiresAt: time.Now().Add(time.Minute)}, nil)
+	updater.On("UpdateOrderStatus", mock.Anything, mock.Anything, models.OrderPending).Return(models.ErrInvalidTransition)
+
+	_, err := service.PayOrder(context.Background(), &models.User{ID: "buyer"}, id, "tok_visa")
+	assert.ErrorIs(t, err, models.ErrInvalidTransition)
+
+	paymentID, _ := payments.Charge(context.Background(), id, 0, "tok_visa")
+	assert.True(t, payments.Refunded(paymentID))
+}
+
+func TestPayOrder_Rejected(t *testing.T) {
+	t.Parallel()
+
+	id := uuid.NewV4().String()
+
+	tests := []struct {
+		name  string
+		order *models.Order
+		user  string
+		want  error
+	}{
+		{name: "not participant", order: &models.Order{ID: id, BuyerID: "buyer", SellerID: "seller", Status: models.OrderPending}, user: "stranger", want: models.ErrOrderNotFound},
+		{name: "seller", order: &models.Order{ID: id, BuyerID: "buyer", SellerID: "seller", Status: models.OrderPending}, user: "seller", want: models.ErrForbidden},
+		{name: "expired", order: &models.Order{ID: id, BuyerID: "buyer", SellerID: "seller", Status: models.OrderPending, ExpiresAt: time.Now().Add(-time.Second)}, user: "buyer", want: models.ErrInvalidTransition},
+		{name: "cancelled", order: &models.Order{ID: id, BuyerID: "buyer", SellerID: "seller", Status: models.OrderCancelled, ExpiresAt: time.Now().Add(time.Hour)}, user: "buyer", want: models.ErrInvalidTransition},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			provider := new(mockOrderProvider)
+			service := New(slog.Default(), nil, provider, nil, nil, nil, fake.New(), nil, time.Hour)
+
+			provider.On("OrderByID", mock.Anything, id).Return(tt.order, nil)
+
+			_, err := service.PayOrder(context.Background(), &models.User{ID: tt.user}, id, "tok_visa")
+			assert.ErrorIs(t, err, tt.want)
+		})
+	}
+}
+
+func TestCreateOrder_Price(t *testing.T) {
+	t.Parallel()
+
+	closedAt := time.Now()
+
+	tests := []struct {
+		name       string
+		auction    *models.Auction
+		offers     []*models.Offer
+		wantAmount int64
+		wantErr    error
+	}{
+		{name: "list price", offers: []*models.Offer{{Status: models.OfferRejected, Amount: 500}}, wantAmount: 1000},
+		{name: "accepted offer", offers: []*models.Offer{{Status: models.OfferPending, Amount: 700}, {Status: models.OfferAccepted, Amount: 800}}, wantAmount: 800},
+		{name: "won auction", auction: &models.Auction{CurrentBid: 1500, LeaderID: "buyer", ClosedAt: &closedAt}, wantAmount: 1500},
+		{name: "running auction", auction: &models.Auction{CurrentBid: 1500, LeaderID: "buyer"}, wantErr: models.ErrAuctionNotEnded},
+		{name: "lost auction", auction: &models.Auction{CurrentBid: 1500, LeaderID: "other", ClosedAt: &closedAt}, wantErr: models.ErrForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			adder := new(mockOrderAdder)
+			posts := new(mockPostProvider)
+			offers := new(mockOfferProvider)
+			notifier := new(mockNotifier)
+
+			service := New(slog.Default(), adder, nil, nil, posts, offers, nil, notifier, time.Hour)
+
+			post := newPost("seller")
+			post.Auction = tt.auction
+
+			posts.On("ListedPostByID", mock.Anything, post.ID).Return(post, nil)
+			offers.On("OffersByPost", mock.Anything, post.ID, "buyer").Return(tt.offers, nil)
+			adder.On("AddOrder", mock.Anything, mock.Anything).Return(nil)
+			notifier.On("Notify", mock.Anything, "seller", models.NotificationOrder, mock.Anything).Return(nil)
+
+			order, err := service.CreateOrder(context.Background(), &models.User{ID: "buyer"}, post.ID)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				adder.AssertNotCalled(t, "AddOrder", mock.Anything, mock.Anything)
+				return
+			}
+
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.wantAmount, order.Amount)
+			}
+		})
+	}
+}
+
+func TestCreateOrder_OwnPost(t *testing.T) {
+	t.Parallel()
+
+	posts := new(mockPostProvider)
+	service := New(slog.Default(), nil, nil, nil, posts, nil, nil, nil, time.Hour)
+
+	post := newPost("seller")
+	posts.On("ListedPostByID", mock.Anything, post.ID).Return(post, nil)
+
+	_, err := service.CreateOrder(context.Background(), &models.User{ID: "seller"}, post.ID)
+	assert.ErrorIs(t, err, models.ErrOwnPost)
+}
+
+func TestCompleteOrder_SellerForbidden(t *testing.T) {
+	t.Parallel()
+
+	provider := new(mockOrderProvider)
+	service := New(slog.Default(), nil, provider, nil, nil, nil, nil, nil, time.Hour)
+
+	id := uuid.NewV4().String()
+	provider.On("OrderByID", mock.Anything, id).Return(&models.Order{ID: id, BuyerID: "buyer", SellerID: "seller", Status: models.OrderConfirmed}, nil)
+
+	_, err := service.CompleteOrder(context.Background(), &models.User{ID: "seller"}, id)
+	assert.ErrorIs(t, err, models.ErrForbidden)
+}
+
+func TestMyOrders_InvalidRole(t *testing.T) {
+	t.Parallel()
+
+	service := New(slog.Default(), nil, nil, nil, nil, nil, nil, nil, time.Hour)
+
+	_, err := service.MyOrders(context.Background(), &models.User{ID: "buyer"}, models.OrderRole("admin"), 20, 0)
+	assert.ErrorIs(t, err, models.ErrInvalidParams)
+}
+
+func TestExpirer_NotifiesBothSides(t *testing.T) {
+	t.Parallel()
+
+	updater := new(mockOrderUpdater)
+	notifier := new(mockNotifier)
+
+	expired := &models.Order{ID: "o1", BuyerID: "buyer", SellerID: "seller", Status: models.OrderCancelled}
+
+	updater.On("ExpireOrders", mock.Anything, mock.Anything).Return([]*models.Order{expired}, nil)
+	notifier.On("Notify", mock.Anything, "buyer", models.NotificationOrder, mock.Anything).Return(nil)
+	notifier.On("Notify", mock.Anything, "seller", models.NotificationOrder, mock.Anything).Return(errors.New("ignored"))
+
+	NewExpirer(slog.Default(), updater, notifier, time.Minute).expire(context.Background())
+
+	notifier.AssertExpectations(t)
+}
@@ -0,0 +1,45 @@
+package passwordservice
+
+import (
+	"context"
+	"marketplace/internal/models"
+)
+
+type UserProvider interface {
+	UserByLogin(ctx context.Context, login string) (*models.User, error)
+}
+
+type PasswordUpdater interface {
+	UpdatePassword(ctx context.Context, userID string, passHash []byte) error
+}
+
+type SessionRevoker interface {
+	DeleteUserSessions(ctx context.Context, userID string, exceptID string) error
+	InvalidateUserSessions(ctx context.Context, userID string) error
+}
+
+// AccessTokens resolves an access token to its grant, by the configured access
+// token strategy.
+type AccessTokens interface {
+	AccessGrant(ctx context.Context, accessToken string) (*models.AccessGrant, error)
+}
+
+type LoginLimiter interface {
+	Check(ctx context.Context, login string, ip string) error
+	Fail(ctx context.Context, login string, ip string) error
+	Succeed(ctx context.Context, login string) error
+}
+
+type ResetTokenStorer interface {
+	SaveResetToken(ctx context.Context, tokenHash string, userID string) error
+	ConsumeResetToken(ctx context.Context, tokenHash string) (string, error)
+}
+
+// MailCounter counts mails sent on request per key within a window.
+type MailCounter interface {
+	CountMail(ctx context.Context, key string) (int64, error)
+}
+
+type Mailer interface {
+	Send(ctx context.Context, mail *models.Mail) error
+}
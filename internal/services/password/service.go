@@ -0,0 +1,285 @@
+package passwordservice
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"marketplace/internal/models"
+	"marketplace/internal/utils/token"
+	"marketplace/internal/utils/validator"
+	"net/url"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const pkg = "passwordService/"
+
+type PasswordService struct {
+	log             *slog.Logger
+	userProvider    UserProvider
+	passwordUpdater PasswordUpdater
+	sessionRevoker  SessionRevoker
+	accessTokens    AccessTokens
+	limiter         LoginLimiter
+	resetTokens     ResetTokenStorer
+	mails           MailCounter
+	mailer          Mailer
+	resetURL        string
+	mailLimit       int64
+	queue           chan *models.Mail
+}
+
+func New(
+	log *slog.Logger,
+	userProvider UserProvider,
+	passwordUpdater PasswordUpdater,
+	sessionRevoker SessionRevoker,
+	accessTokens AccessTokens,
+	limiter LoginLimiter,
+	resetTokens ResetTokenStorer,
+	mails MailCounter,
+	mailer Mailer,
+	resetURL string,
+	mailLimit int64,
+	queueSize int,
+) *PasswordService {
+	return &PasswordService{
+		log:             log,
+		userProvider:    userProvider,
+		passwordUpdater: passwordUpdater,
+		sessionRevoker:  sessionRevoker,
+		accessTokens:    accessTokens,
+		limiter:         limiter,
+		resetTokens:     resetTokens,
+		mails:           mails,
+		mailer:          mailer,
+		resetURL:        resetURL,
+		mailLimit:       mailLimit,
+		queue:           make(chan *models.Mail, queueSize),
+	}
+}
+
+// ChangePassword sets a new password after checking the current one and ends
+// every session of the user except the one the request came with. Wrong
+// current passwords count as failed logins, so a stolen session cannot be
+// used to guess the password past the login lockout.
+func (ps *PasswordService) ChangePassword(ctx context.Context, requester *models.User, sessionToken string, current string, newPassword string, client *models.ClientInfo) error {
+	op := pkg + "ChangePassword"
+
+	log := ps.log.With(slog.String("op", op), slog.String("user_id", requester.ID))
+
+	log.Debug("attempting to change password")
+
+	if !validator.IsValidPassword(newPassword) {
+		log.Warn("new password does not meet requirements")
+		return models.ErrInvalidPassword
+	}
+
+	if err := ps.limiter.Check(ctx, requester.Login, client.IP); err != nil {
+		var retry *models.RetryAfterError
+		if errors.As(err, &retry) {
+			log.Warn("password check blocked", slog.String("ip", client.IP))
+			return err
+		}
+		log.Error("failed to check login attempts", slog.String("error", err.Error()))
+		return models.ErrInternal
+	}
+
+	user, err := ps.userProvider.UserByLogin(ctx, requester.Login)
+	if err != nil {
+		if errors.Is(err, models.ErrUserNotFound) {
+			log.Warn("user not found")
+			return models.ErrUserNotFound
+		}
+		log.Error("failed to get user", slog.String("error", err.Error()))
+		return models.ErrInternal
+	}
+
+	if err := bcrypt.CompareHashAndPassword(user.PassHash, []byte(current)); err != nil {
+		log.Info("invalid current password")
+
+		if err := ps.limiter.Fail(ctx, user.Login, client.IP); err != nil {
+			log.Error("failed to register failed password check", slog.String("error", err.Error()))
+			return models.ErrInternal
+		}
+
+		return models.ErrInvalidCredentials
+	}
+
+	if err := ps.limiter.Succeed(ctx, user.Login); err != nil {
+		log.Error("failed to reset login attempts", slog.String("error", err.Error()))
+		return models.ErrInternal
+	}
+
+	if err := ps.setPassword(ctx, log, user.ID, newPassword); err != nil {
+		return err
+	}
+
+	var currentID string
+
+	grant, err := ps.accessTokens.AccessGrant(ctx, sessionToken)
+	switch {
+	case err == nil:
+		currentID = grant.SessionID
+	case !errors.Is(err, models.ErrSessionNotFound):
+		log.Error("failed to get current session", slog.String("error", err.Error()))
+		return models.ErrInternal
+	}
+
+	if err := ps.sessionRevoker.DeleteUserSessions(ctx, user.ID, currentID); err != nil {
+		log.Error("failed to revoke other sessions", slog.String("error", err.Error()))
+		return models.ErrInternal
+	}
+
+	log.Debug("password changed successfully")
+
+	return nil
+}
+
+// RequestReset mails a single-use reset link to the verified email of the
+// user. It reports success for unknown logins and users without a verified
+// email too, so that the endpoint cannot be used to find out which logins
+// exist. Requests count against the mail limit per login and per client IP
+// before the login is looked up, so the limit reveals nothing either. The mail
+// is queued for Run and may be dropped when the queue is full.
+func (ps *PasswordService) RequestReset(ctx context.Context, login string, client *models.ClientInfo) error {
+	op := pkg + "RequestReset"
+
+	log := ps.log.With(slog.String("op", op))
+
+	log.Debug("attempting to request password reset")
+
+	for _, key := range []string{"reset_ip:" + client.IP, "reset_login:" + login} {
+		count, err := ps.mails.CountMail(ctx, key)
+		if err != nil {
+			log.Error("failed to count reset requests", slog.String("error", err.Error()))
+			return models.ErrInternal
+		}
+
+		if count > ps.mailLimit {
+			log.Warn("reset request limit reached", slog.String("key", key), slog.Int64("count", count))
+			return models.ErrTooManyRequests
+		}
+	}
+
+	user, err := ps.userProvider.UserByLogin(ctx, login)
+	if err != nil {
+		if errors.Is(err, models.ErrUserNotFound) {
+			log.Info("reset requested for unknown login")
+			return nil
+		}
+		log.Error("failed to get user", slog.String("error", err.Error()))
+		return models.ErrInternal
+	}
+
+	if !user.EmailVerified() {
+		log.Info("reset requested for user without verified email", slog.String("user_id", user.ID))
+		return nil
+	}
+
+	resetToken, err := token.New()
+	if err != nil {
+		log.Error("failed to generate reset token", slog.String("error", err.Error()))
+		return models.ErrInternal
+	}
+
+	if err := ps.resetTokens.SaveResetToken(ctx, token.Hash(resetToken), user.ID); err != nil {
+		log.Error("failed to save reset token", slog.String("error", err.Error()))
+		return models.ErrInternal
+	}
+
+	mail := &models.Mail{
+		To:      user.Email,
+		Subject: "Password reset",
+		Body:    "To set a new password, follow the link:\n\n" + ps.resetURL + "?token=" + url.QueryEscape(resetToken) + "\n\nIf you did not request a password reset, ignore this mail.\n",
+	}
+
+	// The mail goes out in the background, so that the response takes as long
+	// as for logins without a verified email.
+	select {
+	case ps.queue <- mail:
+	default:
+		log.Warn("reset mail queue is full, mail skipped", slog.String("user_id", user.ID))
+	}
+
+	log.Debug("password reset requested successfully")
+
+	return nil
+}
+
+// Run sends queued reset mails until ctx is cancelled.
+func (ps *PasswordService) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case mail := <-ps.queue:
+			if err := ps.mailer.Send(ctx, mail); err != nil {
+				ps.log.Error("failed to send reset mail", slog.String("op", pkg+"Run"), slog.String("error", err.Error()))
+			}
+		}
+	}
+}
+
+// ResetPassword sets a new password using a token from the reset mail and
+// ends every session of the user.
+func (ps *PasswordService) ResetPassword(ctx context.Context, resetToken string, newPassword string) error {
+	op := pkg + "ResetPassword"
+
+	log := ps.log.With(slog.String("op", op))
+
+	log.Debug("attempting to reset password")
+
+	if !validator.IsValidPassword(newPassword) {
+		log.Warn("new password does not meet requirements")
+		return models.ErrInvalidPassword
+	}
+
+	userID, err := ps.resetTokens.ConsumeResetToken(ctx, token.Hash(resetToken))
+	if err != nil {
+		if errors.Is(err, models.ErrResetTokenNotFound) {
+			log.Warn("reset token not found")
+			return models.ErrInvalidResetToken
+		}
+		log.Error("failed to consume reset token", slog.String("error", err.Error()))
+		return models.ErrInternal
+	}
+
+	log = log.With(slog.String("user_id", userID))
+
+	if err := ps.setPassword(ctx, log, userID, newPassword); err != nil {
+		if errors.Is(err, models.ErrUserNotFound) {
+			return models.ErrInvalidResetToken
+		}
+		return err
+	}
+
+	if err := ps.sessionRevoker.InvalidateUserSessions(ctx, userID); err != nil {
+		log.Error("failed to revoke sessions", slog.String("error", err.Error()))
+		return models.ErrInternal
+	}
+
+	log.Debug("password reset successfully")
+
+	return nil
+}
+
+func (ps *PasswordService) setPassword(ctx context.Context, log *slog.Logger, userID string, password string) error {
+	passHash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		log.Error("failed to generate password hash", slog.String("error", err.Error()))
+		return models.ErrInternal
+	}
+
+	err = ps.passwordUpdater.UpdatePassword(ctx, userID, passHash)
+	if err != nil {
+		if errors.Is(err, models.ErrUserNotFound) {
+			log.Warn("user not found")
+			return models.ErrUserNotFound
+		}
+		log.Error("failed to update password", slog.String("error", err.Error()))
+		return models.ErrInternal
+	}
+
+	return nil
+}
@@ -0,0 +1,413 @@
+package passwordservice
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log/slog"
+	"marketplace/internal/models"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type mockUserProvider struct {
+	mock.Mock
+}
+
+func (m *mockUserProvider) UserByLogin(ctx context.Context, login string) (*models.User, error) {
+	args := m.Called(ctx, login)
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+type mockPasswordUpdater struct {
+	mock.Mock
+}
+
+func (m *mockPasswordUpdater) UpdatePassword(ctx context.Context, userID string, passHash []byte) error {
+	args := m.Called(ctx, userID, passHash)
+	return args.Error(0)
+}
+
+type mockSessionRevoker struct {
+	mock.Mock
+}
+
+func (m *mockSessionRevoker) InvalidateUserSessions(ctx context.Context, userID string) error {
+	return m.Called(ctx, userID).Error(0)
+}
+
+func (m *mockSessionRevoker) DeleteUserSessions(ctx context.Context, userID string, exceptID string) error {
+	args := m.Called(ctx, userID, exceptID)
+	return args.Error(0)
+}
+
+type mockAccessTokens struct {
+	mock.Mock
+}
+
+func (m *mockAccessTokens) AccessGrant(ctx context.Context, accessToken string) (*models.AccessGrant, error) {
+	args := m.Called(ctx, accessToken)
+	grant, _ := args.Get(0).(*models.AccessGrant)
+	return grant, args.Error(1)
+}
+
+type mockLoginLimiter struct {
+	mock.Mock
+}
+
+func (m *mockLoginLimiter) Check(ctx context.Context, login string, ip string) error {
+	return m.Called(ctx, login, ip).Error(0)
+}
+
+func (m *mockLoginLimiter) Fail(ctx context.Context, login string, ip string) error {
+	return m.Called(ctx, login, ip).Error(0)
+}
+
+func (m *mockLoginLimiter) Succeed(ctx context.Context, login string) error {
+	return m.Called(ctx, login).Error(0)
+}
+
+type mockResetTokens struct {
+	mock.Mock
+}
+
+func (m *mockResetTokens) SaveResetToken(ctx context.Context, tokenHash string, userID string) error {
+	args := m.Called(ctx, tokenHash, userID)
+	return args.Error(0)
+}
+
+func (m *mockResetTokens) ConsumeResetToken(ctx context.Context, tokenHash string) (string, error) {
+	args := m.Called(ctx, tokenHash)
+	return args.String(0), args.Error(1)
+}
+
+type mockMailCounter struct {
+	mock.Mock
+}
+
+func (m *mockMailCounter) CountMail(ctx context.Context, key string) (int64, error) {
+	args := m.Called(ctx, key)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+type mockMailer struct {
+	mock.Mock
+}
+
+func (m *mockMailer) Send(ctx context.Context, mail *models.Mail) error {
+	args := m.Called(ctx, mail)
+	return args.Error(0)
+}
+
+const (
+	oldPassword = "OldPass1!"
+	newPassword = "NewPass1!"
+)
+
+var testClient = &models.ClientInfo{IP: "10.0.0.1", UserAgent: "test"}
+
+func underLimit() *mockMailCounter {
+	mails := new(mockMailCounter)
+	mails.On("CountMail", mock.Anything, mock.Anything).Return(int64(1), nil)
+
+	return mails
+}
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func userWithPassword(t *testing.T, password string) *models.User {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	assert.NoError(t, err)
+
+	return &models.User{ID: "1", Login: "user1", PassHash: hash}
+}
+
+func matchesPassword(password string) any {
+	return mock.MatchedBy(func(hash []byte) bool {
+		return bcrypt.CompareHashAndPassword(hash, []byte(password)) == nil
+	})
+}
+
+func TestChangePassword_Success(t *testing.T) {
+	t.Parallel()
+
+	users := new(mockUserProvider)
+	updater := new(mockPasswordUpdater)
+	sessions := new(mockSessionRevoker)
+
+	users.On("UserByLogin", mock.Anything, "user1").Return(userWithPassword(t, oldPassword), nil)
+	updater.On("UpdatePassword", mock.Anything, "1", matchesPassword(newPassword)).Return(nil)
+	sessions.On("DeleteUserSessions", mock.Anything, "1", "current").Return(nil)
+
+	tokens := new(mockAccessTokens)
+	tokens.On("AccessGrant", mock.Anything, "current-token").Return(&models.AccessGrant{UserID: "1", SessionID: "current"}, nil)
+
+	limiter := new(mockLoginLimiter)
+	limiter.On("Check", mock.Anything, "user1", "10.0.0.1").Return(nil)
+	limiter.On("Succeed", mock.Anything, "user1").Return(nil)
+
+	service := New(testLogger(), users, updater, sessions, tokens, limiter, nil, nil, nil, "", 0, 1)
+
+	err := service.ChangePassword(context.Background(), &models.User{ID: "1", Login: "user1"}, "current-token", oldPassword, newPassword, testClient)
+
+	assert.NoError(t, err)
+	updater.AssertExpectations(t)
+	sessions.AssertExpectations(t)
+	limiter.AssertExpectations(t)
+}
+
+func TestChangePassword_WrongCurrent(t *testing.T) {
+	t.Parallel()
+
+	users := new(mockUserProvider)
+	updater := new(mockPasswordUpdater)
+
+	users.On("UserByLogin", mock.Anything, "user1").Return(userWithPassword(t, oldPassword), nil)
+
+	limiter := new(mockLoginLimiter)
+	limiter.On("Check", mock.Anything, "user1", "10.0.0.1").Return(nil)
+	limiter.On("Fail", mock.Anything, "user1", "10.0.0.1").Return(nil)
+
+	service := New(testLogger(), users, updater, nil, nil, limiter, nil, nil, nil, "", 0, 1)
+
+	err := service.ChangePassword(context.Background(), &models.User{ID: "1", Login: "user1"}, "current-token", "Wrong1!pass", newPassword, testClient)
+
+	assert.ErrorIs(t, err, models.ErrInvalidCredentials)
+	updater.AssertNotCalled(t, "UpdatePassword", mock.Anything, mock.Anything, mock.Anything)
+	limiter.AssertExpectations(t)
+}
+
+func TestChangePassword_Blocked(t *testing.T) {
+	t.Parallel()
+
+	users := new(mockUserProvider)
+
+	limiter := new(mockLoginLimiter)
+	limiter.On("Check", mock.Anything, "user1", "10.0.0.1").Return(&models.RetryAfterError{After: time.Minute, Err: models.ErrLoginBlocked})
+
+	service := New(testLogger(), users, nil, nil, nil, limiter, nil, nil, nil, "", 0, 1)
+
+	err := service.ChangePassword(context.Background(), &models.User{ID: "1", Login: "user1"}, "current-token", oldPassword, newPassword, testClient)
+
+	var retry *models.RetryAfterError
+	assert.ErrorAs(t, err, &retry)
+	users.AssertNotCalled(t, "UserByLogin", mock.Anything, mock.Anything)
+}
+
+func TestChangePassword_WeakPassword(t *testing.T) {
+	t.Parallel()
+
+	users := new(mockUserProvider)
+
+	service := New(testLogger(), users, nil, nil, nil, nil, nil, nil, nil, "", 0, 1)
+
+	err := service.ChangePassword(context.Background(), &models.User{ID: "1", Login: "user1"}, "current-token", oldPassword, "weak", testClient)
+
+	assert.ErrorIs(t, err, models.ErrInvalidPassword)
+	users.AssertNotCalled(t, "UserByLogin", mock.Anything, mock.Anything)
+}
+
+func TestRequestReset_SendsLinkAndStoresHash(t *testing.T) {
+	t.Parallel()
+
+	users := new(mockUserProvider)
+	tokens := new(mockResetTokens)
+	mailer := new(mockMailer)
+
+	verifiedAt := time.Now()
+
+	users.On("UserByLogin", mock.Anything, "user1").Return(&models.User{ID: "1", Login: "user1", Email: "user1@example.com", EmailVerifiedAt: &verifiedAt}, nil)
+	tokens.On("SaveResetToken", mock.Anything, mock.Anything, "1").Return(nil)
+
+	service := New(testLogger(), users, nil, nil, nil, nil, tokens, underLimit(), mailer, "https://example.com/reset", 3, 1)
+
+	err := service.RequestReset(context.Background(), "user1", testClient)
+	assert.NoError(t, err)
+
+	// The mail is only queued, the request does not wait for the mailer.
+	mailer.AssertNotCalled(t, "Send", mock.Anything, mock.Anything)
+
+	mail := <-service.queue
+	assert.Equal(t, "user1@example.com", mail.To)
+
+	start := strings.Index(mail.Body, "https://example.com/reset?token=")
+	assert.NotEqual(t, -1, start)
+
+	link, err := url.Parse(strings.Fields(mail.Body[start:])[0])
+	assert.NoError(t, err)
+
+	token := link.Query().Get("token")
+	assert.NotEmpty(t, token)
+
+	sum := sha256.Sum256([]byte(token))
+	storedHash := tokens.Calls[0].Arguments.String(1)
+	assert.Equal(t, hex.EncodeToString(sum[:]), storedHash)
+	assert.NotContains(t, storedHash, token)
+}
+
+func TestRun_SendsQueuedMail(t *testing.T) {
+	t.Parallel()
+
+	mailer := new(mockMailer)
+
+	sent := make(chan *models.Mail, 1)
+	mailer.On("Send", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		sent <- args.Get(1).(*models.Mail)
+	}).Return(nil)
+
+	service := New(testLogger(), nil, nil, nil, nil, nil, nil, nil, mailer, "", 3, 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go service.Run(ctx)
+
+	mail := &models.Mail{To: "user1@example.com"}
+	service.queue <- mail
+
+	select {
+	case got := <-sent:
+		assert.Equal(t, mail, got)
+	case <-time.After(time.Second):
+		t.Fatal("queued mail was not sent")
+	}
+}
+
+func TestRequestReset_UnknownLogin(t *testing.T) {
+	t.Parallel()
+
+	users := new(mockUserProvider)
+	mailer := new(mockMailer)
+
+	users.On("UserByLogin", mock.Anything, "ghost").Return((*models.User)(nil), models.ErrUserNotFound)
+
+	service := New(testLogger(), users, nil, nil, nil, nil, nil, underLimit(), mailer, "", 3, 1)
+
+	err := service.RequestReset(context.Background(), "ghost", testClient)
+
+	assert.NoError(t, err)
+	assert.Empty(t, service.queue)
+}
+
+func TestRequestReset_NoVerifiedEmail(t *testing.T) {
+	t.Parallel()
+
+	users := new(mockUserProvider)
+	mailer := new(mockMailer)
+
+	users.On("UserByLogin", mock.Anything, "user1").Return(&models.User{ID: "1", Login: "user1", Email: "user1@example.com"}, nil)
+
+	service := New(testLogger(), users, nil, nil, nil, nil, nil, underLimit(), mailer, "", 3, 1)
+
+	err := service.RequestReset(context.Background(), "user1", testClient)
+
+	assert.NoError(t, err)
+	assert.Empty(t, service.queue)
+}
+
+func TestRequestReset_LimitReached(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		ipCount    int64
+		loginCount int64
+	}{
+		{name: "per login", ipCount: 1, loginCount: 4},
+		{name: "per ip", ipCount: 4, loginCount: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			users := new(mockUserProvider)
+			mails := new(mockMailCounter)
+			mailer := new(mockMailer)
+
+			mails.On("CountMail", mock.Anything, "reset_ip:10.0.0.1").Return(tt.ipCount, nil).Maybe()
+			mails.On("CountMail", mock.Anything, "reset_login:user1").Return(tt.loginCount, nil).Maybe()
+
+			service := New(testLogger(), users, nil, nil, nil, nil, nil, mails, mailer, "", 3, 1)
+
+			err := service.RequestReset(context.Background(), "user1", testClient)
+
+			assert.ErrorIs(t, err, models.ErrTooManyRequests)
+			users.AssertNotCalled(t, "UserByLogin", mock.Anything, mock.Anything)
+			mailer.AssertNotCalled(t, "Send", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestRequestReset_CountFails(t *testing.T) {
+	t.Parallel()
+
+	users := new(mockUserProvider)
+	mails := new(mockMailCounter)
+
+	mails.On("CountMail", mock.Anything, mock.Anything).Return(int64(0), errors.New("redis down"))
+
+	service := New(testLogger(), users, nil, nil, nil, nil, nil, mails, nil, "", 3, 1)
+
+	err := service.RequestReset(context.Background(), "user1", testClient)
+
+	assert.ErrorIs(t, err, models.ErrInternal)
+	users.AssertNotCalled(t, "UserByLogin", mock.Anything, mock.Anything)
+}
+
+func TestResetPassword(t *testing.T) {
+	t.Parallel()
+
+	someErr := errors.New("some error")
+
+	tests := []struct {
+		name       string
+		password   string
+		consumeID  string
+		consumeErr error
+		wantErr    error
+	}{
+		{name: "success", password: newPassword, consumeID: "1"},
+		{name: "weak password", password: "weak", wantErr: models.ErrInvalidPassword},
+		{name: "used or expired token", password: newPassword, consumeErr: models.ErrResetTokenNotFound, wantErr: models.ErrInvalidResetToken},
+		{name: "cache error", password: newPassword, consumeErr: someErr, wantErr: models.ErrInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			tokens := new(mockResetTokens)
+			updater := new(mockPasswordUpdater)
+			sessions := new(mockSessionRevoker)
+
+			sum := sha256.Sum256([]byte("raw-token"))
+
+			tokens.On("ConsumeResetToken", mock.Anything, hex.EncodeToString(sum[:])).Return(tt.consumeID, tt.consumeErr)
+			updater.On("UpdatePassword", mock.Anything, "1", matchesPassword(newPassword)).Return(nil)
+			sessions.On("InvalidateUserSessions", mock.Anything, "1").Return(nil)
+
+			service := New(testLogger(), nil, updater, sessions, nil, nil, tokens, nil, nil, "", 0, 1)
+
+			err := service.ResetPassword(context.Background(), "raw-token", tt.password)
+
+			assert.ErrorIs(t, err, tt.wantErr)
+			if tt.wantErr == nil {
+				updater.AssertExpectations(t)
+				sessions.AssertExpectations(t)
+			} else {
+				updater.AssertNotCalled(t, "UpdatePassword", mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
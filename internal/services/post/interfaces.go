@@ -12,6 +12,11 @@ type PostAdder interface {
 
 type PostProvider interface {
 	FilteredPosts(ctx context.Context, limit int, offset int, filter *models.PostsFilter) ([]*models.PostWithDocument, error)
+	PostByID(ctx context.Context, id string) (*models.PostWithDocument, error)
+}
+
+type PostUpdater interface {
+	UpdatePost(ctx context.Context, post *models.PostWithDocument) error
 }
 
 type PostRemover interface {
@@ -28,4 +33,27 @@ type Cache interface {
 	Get(ctx context.Context, key string) (string, error)
 	Set(ctx context.Context, key string, value interface{}) error
 	Del(ctx context.Context, keys ...string) error
+	ListVersion(ctx context.Context) (int64, error)
+	InvalidateLists(ctx context.Context) error
+}
+
+type BlockProvider interface {
+	BlockedIDs(ctx context.Context, blockerID string) ([]string, error)
+}
+
+// BlockCache keeps the block lists of users. The bool reports whether the list
+// was cached at all.
+type BlockCache interface {
+	BlockedIDs(ctx context.Context, blockerID string) ([]string, bool, error)
+	SaveBlockedIDs(ctx context.Context, blockerID string, ids []string) error
+}
+
+type EventPublisher interface {
+	Publish(ctx context.Context, userID string, eventType models.EventType, payload any) error
+}
+
+// PostListener is notified after a post has been committed. Implementations
+// must not block, since they run on the request path.
+type PostListener interface {
+	PostAdded(ctx context.Context, post *models.PostWithDocument)
 }
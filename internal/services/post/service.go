@@ -2,6 +2,8 @@ package postservice
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
@@ -9,6 +11,8 @@ import (
 	"marketplace/internal/models"
 	"marketplace/internal/utils/mapper"
 	"marketplace/internal/utils/validator"
+	"slices"
+	"strings"
 	"time"
 
 	uuid "github.com/satori/go.uuid"
@@ -20,29 +24,46 @@ type PostService struct {
 	log          *slog.Logger
 	postAdder    PostAdder
 	postProvider PostProvider
+	postUpdater  PostUpdater
 	postRemover  PostRemover
 	fileStorage  FileStorage
 	cache        Cache
+	events       EventPublisher
+	blocks       BlockProvider
+	blockCache   BlockCache
+	listeners    []PostListener
 }
 
 func New(
 	log *slog.Logger,
 	postAdder PostAdder,
 	postProvider PostProvider,
+	postUpdater PostUpdater,
 	postRemover PostRemover,
 	fileStorage FileStorage,
 	cache Cache,
+	events EventPublisher,
+	blocks BlockProvider,
+	blockCache BlockCache,
 ) *PostService {
 	return &PostService{
 		log:          log,
 		postAdder:    postAdder,
 		postProvider: postProvider,
+		postUpdater:  postUpdater,
 		postRemover:  postRemover,
 		fileStorage:  fileStorage,
 		cache:        cache,
+		events:       events,
+		blocks:       blocks,
+		blockCache:   blockCache,
 	}
 }
 
+func (ps *PostService) AddListener(listener PostListener) {
+	ps.listeners = append(ps.listeners, listener)
+}
+
 func (ps *PostService) AddPost(ctx context.Context, requerster *models.User, post *models.PostWithDocument, file io.Reader) (*models.PostWithDocument, error) {
 	op := pkg + "AddPost"
 
@@ -50,6 +71,21 @@ func (ps *PostService) AddPost(ctx context.Context, requerster *models.User, pos
 
 	log.Debug("attempting to add post")
 
+	if post.Auction != nil {
+		if err := validator.ValidateAuction(post.Auction, time.Now()); err != nil {
+			log.Warn("invalid auction recieved", slog.String("error", err.Error()))
+			return nil, err
+		}
+
+		// Only the terms come from the seller, bidding always starts empty.
+		post.Auction = &models.Auction{
+			StartPrice:   post.Auction.StartPrice,
+			MinIncrement: post.Auction.MinIncrement,
+			EndsAt:       post.Auction.EndsAt,
+		}
+		post.Price = post.Auction.StartPrice
+	}
+
 	if err := validator.ValidatePost(post); err != nil {
 		log.Warn("invalid post recieved", slog.String("error", err.Error()))
 		return nil, err
@@ -91,9 +127,22 @@ func (ps *PostService) AddPost(ctx context.Context, requerster *models.User, pos
 	}
 
 	log.Debug("post added successfully", slog.String("post_id", post.ID), slog.String("document_id", post.Document.ID))
+
+	err = ps.events.Publish(ctx, post.OwnerID, models.EventPostCreated, models.PostEventPayload{PostID: post.ID, Header: post.Header})
+	if err != nil {
+		log.Warn("failed to publish post created event", slog.String("error", err.Error()))
+	}
+
+	for _, listener := range ps.listeners {
+		listener.PostAdded(ctx, post)
+	}
+
 	return post, nil
 }
 
+// FilteredPosts returns a page of posts, without the posts of the users the
+// requester has blocked. Pages are cached by filter and block list rather than
+// by requester, so that everyone who has blocked nobody shares one entry.
 func (ps *PostService) FilteredPosts(ctx context.Context, limit int, offset int, filter *models.PostsFilter, requester *models.User) ([]*models.PostWithDocument, error) {
 	op := pkg + "FilteredPosts"
 
@@ -101,25 +150,44 @@ func (ps *PostService) FilteredPosts(ctx context.Context, limit int, offset int,
 
 	log.Debug("attempting to get filtered posts")
 
-	var posts []*models.PostWithDocument
-
-	var cacheKey string
+	var blocked []string
 
 	if requester != nil {
-		cacheKey = fmt.Sprintf("posts:%s:%v:%v:%s:%s:%v:%v", requester.Login, limit, offset, filter.SortBy, filter.SortOrder, filter.MinPrice, filter.MaxPrice)
-	} else {
-		cacheKey = fmt.Sprintf("posts:%v:%v:%s:%s:%v:%v", limit, offset, filter.SortBy, filter.SortOrder, filter.MinPrice, filter.MaxPrice)
+		var err error
+
+		blocked, err = ps.blockedIDs(ctx, log, requester.ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var posts []*models.PostWithDocument
+
+	// Without the version the key could point at a page from before the
+	// latest change, so the cache is skipped altogether.
+	version, err := ps.cache.ListVersion(ctx)
+	if err != nil {
+		log.Warn("failed to get posts version from cache", slog.String("error", err.Error()))
 	}
+	cacheable := err == nil
+
+	cacheKey := postsCacheKey(version, limit, offset, filter, blocked)
 
-	postsJSON, err := ps.cache.Get(ctx, cacheKey)
+	var postsJSON string
+	if cacheable {
+		postsJSON, err = ps.cache.Get(ctx, cacheKey)
+	}
 	if err != nil || postsJSON == "" {
 		if err == nil {
 			log.Debug("cache miss")
-		} else {
+		} else if cacheable {
 			log.Warn("failed to get posts from cache")
 		}
 
-		posts, err := ps.postProvider.FilteredPosts(ctx, limit, offset, filter)
+		dbFilter := *filter
+		dbFilter.ExcludedOwnerIDs = blocked
+
+		posts, err := ps.postProvider.FilteredPosts(ctx, limit, offset, &dbFilter)
 		if err != nil {
 			if errors.Is(err, models.ErrPostNotFound) {
 				log.Warn("filtered posts not found")
@@ -135,23 +203,19 @@ func (ps *PostService) FilteredPosts(ctx context.Context, limit int, offset int,
 			return nil, models.ErrInternal
 		}
 
-		if requester != nil {
-			for _, post := range posts {
-				if post.OwnerID == requester.ID {
-					post.RequesterIsOwner = true
+		if cacheable {
+			postsJSON, err := mapper.PostsToJSON(posts)
+			if err != nil {
+				log.Error("failed to convert docs to json", slog.String("error", err.Error()))
+			} else {
+				err = ps.cache.Set(ctx, cacheKey, postsJSON)
+				if err != nil {
+					log.Error("failed to set docs in cache", slog.String("error", err.Error()))
 				}
 			}
 		}
 
-		postsJSON, err := mapper.PostsToJSON(posts)
-		if err != nil {
-			log.Error("failed to convert docs to json", slog.String("error", err.Error()))
-		} else {
-			err = ps.cache.Set(ctx, cacheKey, postsJSON)
-			if err != nil {
-				log.Error("failed to set docs in cache", slog.String("error", err.Error()))
-			}
-		}
+		markOwned(posts, requester)
 
 		log.Debug("filtered posts found successfully", slog.Int("count", len(posts)))
 
@@ -164,7 +228,212 @@ func (ps *PostService) FilteredPosts(ctx context.Context, limit int, offset int,
 		}
 	}
 
+	markOwned(posts, requester)
+
 	log.Debug("filtered posts found in cache successfully", slog.Int("count", len(posts)))
 
 	return posts, nil
 }
+
+// UpdatePost changes the header, text or price of a post of the requester, or
+// of any post if the requester is a moderator. The price of an auction is
+// driven by its bids and cannot be edited.
+func (ps *PostService) UpdatePost(ctx context.Context, requester *models.User, id string, update *models.PostUpdate) (*models.PostWithDocument, error) {
+	op := pkg + "UpdatePost"
+
+	log := ps.log.With(slog.String("op", op))
+
+	log.Debug("attempting to update post")
+
+	if _, err := uuid.FromString(id); err != nil {
+		log.Warn("invalid post id received", slog.String("post_id", id))
+		return nil, models.ErrPostNotFound
+	}
+
+	post, err := ps.postProvider.PostByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, models.ErrPostNotFound) {
+			log.Warn("post not found", slog.String("post_id", id))
+			return nil, models.ErrPostNotFound
+		}
+
+		log.Error("failed to get post", slog.String("error", err.Error()))
+		return nil, models.ErrInternal
+	}
+
+	if !canManage(requester, post) {
+		log.Warn("requester is not the owner of the post", slog.String("post_id", id))
+		return nil, models.ErrForbidden
+	}
+
+	if update.Price != nil && post.Auction != nil {
+		log.Warn("price of an auction cannot be edited", slog.String("post_id", id))
+		return nil, fmt.Errorf("%w: price of an auction is set by its bids", models.ErrInvalidPrice)
+	}
+
+	if update.Header != nil {
+		post.Header = *update.Header
+	}
+
+	if update.Text != nil {
+		post.Text = *update.Text
+	}
+
+	if update.Price != nil {
+		post.Price = *update.Price
+	}
+
+	if err := validator.ValidatePost(post); err != nil {
+		log.Warn("invalid post recieved", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	if post.OwnerID != requester.ID {
+		log.Info("moderator edits post of another user", slog.String("post_id", id), slog.String("moderator_id", requester.ID))
+	}
+
+	err = ps.postUpdater.UpdatePost(ctx, post)
+	if err != nil {
+		if errors.Is(err, models.ErrPostNotFound) {
+			log.Warn("post not found", slog.String("post_id", id))
+			return nil, models.ErrPostNotFound
+		}
+
+		log.Error("failed to update post", slog.String("error", err.Error()))
+		return nil, models.ErrInternal
+	}
+
+	post.RequesterIsOwner = post.OwnerID == requester.ID
+
+	log.Debug("post updated successfully", slog.String("post_id", id))
+
+	return post, nil
+}
+
+// DeletePost removes a post of the requester, or any post if the requester is
+// a moderator.
+func (ps *PostService) DeletePost(ctx context.Context, requester *models.User, id string) error {
+	op := pkg + "DeletePost"
+
+	log := ps.log.With(slog.String("op", op))
+
+	log.Debug("attempting to delete post")
+
+	if _, err := uuid.FromString(id); err != nil {
+		log.Warn("invalid post id received", slog.String("post_id", id))
+		return models.ErrPostNotFound
+	}
+
+	post, err := ps.postProvider.PostByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, models.ErrPostNotFound) {
+			log.Warn("post not found", slog.String("post_id", id))
+			return models.ErrPostNotFound
+		}
+
+		log.Error("failed to get post", slog.String("error", err.Error()))
+		return models.ErrInternal
+	}
+
+	if !canManage(requester, post) {
+		log.Warn("requester is not the owner of the post", slog.String("post_id", id))
+		return models.ErrForbidden
+	}
+
+	if post.OwnerID != requester.ID {
+		log.Info("moderator deletes post of another user", slog.String("post_id", id), slog.String("moderator_id", requester.ID))
+	}
+
+	err = ps.postRemover.DeletePost(ctx, id)
+	if err != nil {
+		log.Error("failed to delete post", slog.String("error", err.Error()))
+		return models.ErrInternal
+	}
+
+	if err := ps.fileStorage.DeleteFile(post.Document); err != nil {
+		log.Warn("failed to delete post file", slog.String("error", err.Error()))
+	}
+
+	ps.invalidateLists(ctx, log)
+
+	err = ps.events.Publish(ctx, post.OwnerID, models.EventPostDeleted, models.PostEventPayload{PostID: post.ID, Header: post.Header})
+	if err != nil {
+		log.Warn("failed to publish post deleted event", slog.String("error", err.Error()))
+	}
+
+	log.Debug("post deleted successfully", slog.String("post_id", id))
+
+	return nil
+}
+
+// blockedIDs returns the block list of the user from the cache, and reads it
+// from the database only on a miss, so listings stay off the database when the
+// page is cached too.
+func (ps *PostService) blockedIDs(ctx context.Context, log *slog.Logger, userID string) ([]string, error) {
+	blocked, cached, err := ps.blockCache.BlockedIDs(ctx, userID)
+	if err != nil {
+		log.Warn("failed to get blocked users from cache", slog.String("error", err.Error()))
+	}
+
+	if cached {
+		return blocked, nil
+	}
+
+	blocked, err = ps.blocks.BlockedIDs(ctx, userID)
+	if err != nil {
+		log.Error("failed to get blocked users", slog.String("error", err.Error()))
+		return nil, models.ErrInternal
+	}
+
+	if err := ps.blockCache.SaveBlockedIDs(ctx, userID, blocked); err != nil {
+		log.Warn("failed to set blocked users in cache", slog.String("error", err.Error()))
+	}
+
+	return blocked, nil
+}
+
+// canManage reports whether the requester may change or remove the post:
+// owners may manage their own posts and moderators any post.
+func canManage(requester *models.User, post *models.PostWithDocument) bool {
+	return post.OwnerID == requester.ID || requester.IsModerator()
+}
+
+// invalidateLists drops the cached pages of posts after posts leave the
+// listings. Pages expire on their own if this fails.
+func (ps *PostService) invalidateLists(ctx context.Context, log *slog.Logger) {
+	if err := ps.cache.InvalidateLists(ctx); err != nil {
+		log.Warn("failed to invalidate cached posts", slog.String("error", err.Error()))
+	}
+}
+
+// postsCacheKey builds the cache key of a page of posts. The version changes
+// whenever posts leave the listings. A block list adds a short digest of the sorted
+// IDs, so a block or an unblock moves the requester to a fresh entry at once.
+func postsCacheKey(version int64, limit int, offset int, filter *models.PostsFilter, blocked []string) string {
+	key := fmt.Sprintf("posts:v%d:%v:%v:%s:%s:%v:%v", version, limit, offset, filter.SortBy, filter.SortOrder, filter.MinPrice, filter.MaxPrice)
+
+	if len(blocked) == 0 {
+		return key
+	}
+
+	ids := slices.Clone(blocked)
+	slices.Sort(ids)
+
+	sum := sha256.Sum256([]byte(strings.Join(ids, ",")))
+
+	return key + ":blocks:" + hex.EncodeToString(sum[:8])
+}
+
+// markOwned flags the posts of the requester. It runs after caching, since
+// cached pages are shared between requesters.
+func markOwned(posts []*models.PostWithDocument, requester *models.User) {
+	if requester == nil {
+		return
+	}
+
+	for _, post := range posts {
+		if post.OwnerLogin == requester.Login {
+			post.RequesterIsOwner = true
+		}
+	}
+}
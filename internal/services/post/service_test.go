@@ -8,9 +8,12 @@ import (
 	"log/slog"
 	"marketplace/internal/models"
 	"marketplace/internal/utils/mapper"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	uuid "github.com/satori/go.uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -33,6 +36,38 @@ func (m *mockPostProvider) FilteredPosts(ctx context.Context, limit int, offset
 	return args.Get(0).([]*models.PostWithDocument), args.Error(1)
 }
 
+func (m *mockPostProvider) PostByID(ctx context.Context, id string) (*models.PostWithDocument, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*models.PostWithDocument), args.Error(1)
+}
+
+type mockPostUpdater struct {
+	mock.Mock
+}
+
+func (m *mockPostUpdater) UpdatePost(ctx context.Context, post *models.PostWithDocument) error {
+	args := m.Called(ctx, post)
+	return args.Error(0)
+}
+
+type mockPostRemover struct {
+	mock.Mock
+}
+
+func (m *mockPostRemover) DeletePost(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+type mockEventPublisher struct {
+	mock.Mock
+}
+
+func (m *mockEventPublisher) Publish(ctx context.Context, userID string, eventType models.EventType, payload any) error {
+	args := m.Called(ctx, userID, eventType, payload)
+	return args.Error(0)
+}
+
 type mockFileStorage struct {
 	mock.Mock
 }
@@ -66,6 +101,16 @@ func (m *mockCache) Set(ctx context.Context, key string, value interface{}) erro
 	return args.Error(0)
 }
 
+func (m *mockCache) ListVersion(ctx context.Context) (int64, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *mockCache) InvalidateLists(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
 func (m *mockCache) Del(ctx context.Context, keys ...string) error {
 	args := m.Called(ctx, keys)
 	return args.Error(0)
@@ -76,13 +121,18 @@ func TestAddPost_Success(t *testing.T) {
 
 	mockPostAdder := new(mockPostAdder)
 	mockFileStorage := new(mockFileStorage)
+	mockEventPublisher := new(mockEventPublisher)
 	mockService := New(
 		slog.Default(),
 		mockPostAdder,
 		nil,
 		nil,
+		nil,
 		mockFileStorage,
 		nil,
+		mockEventPublisher,
+		nil,
+		nil,
 	)
 
 	requester := &models.User{
@@ -103,6 +153,7 @@ func TestAddPost_Success(t *testing.T) {
 
 	mockPostAdder.On("AddPost", mock.Anything, post).Return(nil)
 	mockFileStorage.On("SaveFile", mock.Anything, mock.Anything).Return("path/to/image/1.jpg", nil)
+	mockEventPublisher.On("Publish", mock.Anything, "123", models.EventPostCreated, mock.Anything).Return(nil)
 
 	post, err := mockService.AddPost(context.Background(), requester, post, nil)
 
@@ -111,6 +162,183 @@ func TestAddPost_Success(t *testing.T) {
 	assert.NotEmpty(t, post.CreatedAt)
 
 	mockPostAdder.AssertExpectations(t)
+	mockEventPublisher.AssertExpectations(t)
+}
+
+func TestAddPost_AuctionResetsBidding(t *testing.T) {
+	t.Parallel()
+
+	mockPostAdder := new(mockPostAdder)
+	mockFileStorage := new(mockFileStorage)
+	mockEventPublisher := new(mockEventPublisher)
+	mockService := New(
+		slog.Default(),
+		mockPostAdder,
+		nil,
+		nil,
+		nil,
+		mockFileStorage,
+		nil,
+		mockEventPublisher,
+		nil,
+		nil,
+	)
+
+	requester := &models.User{
+		ID:    "123",
+		Login: "test_login",
+	}
+
+	endsAt := time.Now().Add(24 * time.Hour)
+
+	post := &models.PostWithDocument{
+		Header: "header",
+		Text:   "texttexttext",
+		Price:  1,
+		Auction: &models.Auction{
+			StartPrice:   1000,
+			MinIncrement: 50,
+			EndsAt:       endsAt,
+			CurrentBid:   5000,
+			BidCount:     3,
+			LeaderLogin:  "cheater",
+		},
+		Document: &models.Document{
+			Name: "1.jpg",
+			Mime: "image/jpeg",
+		},
+	}
+
+	mockPostAdder.On("AddPost", mock.Anything, post).Return(nil)
+	mockFileStorage.On("SaveFile", mock.Anything, mock.Anything).Return("path/to/image/1.jpg", nil)
+	mockEventPublisher.On("Publish", mock.Anything, "123", models.EventPostCreated, mock.Anything).Return(nil)
+
+	post, err := mockService.AddPost(context.Background(), requester, post, nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1000), post.Price)
+	assert.Equal(t, &models.Auction{StartPrice: 1000, MinIncrement: 50, EndsAt: endsAt}, post.Auction)
+
+	mockPostAdder.AssertExpectations(t)
+}
+
+func TestAddPost_InvalidAuction(t *testing.T) {
+	t.Parallel()
+
+	mockService := New(slog.Default(), nil, nil, nil, nil, nil, nil, nil, nil, nil)
+
+	post := &models.PostWithDocument{
+		Header:  "header",
+		Text:    "texttexttext",
+		Auction: &models.Auction{StartPrice: 1000, MinIncrement: 50, EndsAt: time.Now()},
+		Document: &models.Document{
+			Name: "1.jpg",
+			Mime: "image/jpeg",
+		},
+	}
+
+	_, err := mockService.AddPost(context.Background(), &models.User{ID: "123"}, post, nil)
+
+	assert.ErrorIs(t, err, models.ErrInvalidAuction)
+}
+
+type mockBlockProvider struct {
+	mock.Mock
+}
+
+func (m *mockBlockProvider) BlockedIDs(ctx context.Context, blockerID string) ([]string, error) {
+	args := m.Called(ctx, blockerID)
+	ids, _ := args.Get(0).([]string)
+	return ids, args.Error(1)
+}
+
+// listsCache accepts invalidations of the cached pages of posts.
+func listsCache() *mockCache {
+	cache := new(mockCache)
+	cache.On("InvalidateLists", mock.Anything).Return(nil).Maybe()
+	return cache
+}
+
+type mockBlockCache struct {
+	mock.Mock
+}
+
+func (m *mockBlockCache) BlockedIDs(ctx context.Context, blockerID string) ([]string, bool, error) {
+	args := m.Called(ctx, blockerID)
+	return args.Get(0).([]string), args.Bool(1), args.Error(2)
+}
+
+func (m *mockBlockCache) SaveBlockedIDs(ctx context.Context, blockerID string, ids []string) error {
+	args := m.Called(ctx, blockerID, ids)
+	return args.Error(0)
+}
+
+// missingBlockCache never has a block list cached, so the lists come from the
+// block provider.
+func missingBlockCache() *mockBlockCache {
+	blockCache := new(mockBlockCache)
+	blockCache.On("BlockedIDs", mock.Anything, mock.Anything).Return([]string(nil), false, nil).Maybe()
+	blockCache.On("SaveBlockedIDs", mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+	return blockCache
+}
+
+type mockPostListener struct {
+	mock.Mock
+}
+
+func (m *mockPostListener) PostAdded(ctx context.Context, post *models.PostWithDocument) {
+	m.Called(ctx, post)
+}
+
+func TestAddPost_NotifiesListeners(t *testing.T) {
+	t.Parallel()
+
+	mockPostAdder := new(mockPostAdder)
+	mockFileStorage := new(mockFileStorage)
+	mockEventPublisher := new(mockEventPublisher)
+	mockPostListener := new(mockPostListener)
+	mockService := New(
+		slog.Default(),
+		mockPostAdder,
+		nil,
+		nil,
+		nil,
+		mockFileStorage,
+		nil,
+		mockEventPublisher,
+		nil,
+		nil,
+	)
+
+	mockService.AddListener(mockPostListener)
+
+	requester := &models.User{
+		ID:    "123",
+		Login: "test_login",
+	}
+
+	post := &models.PostWithDocument{
+		Header: "header",
+		Text:   "texttexttext",
+		Price:  100500,
+		Document: &models.Document{
+			Name: "1.jpg",
+			Mime: "image/jpeg",
+		},
+	}
+
+	mockPostAdder.On("AddPost", mock.Anything, post).Return(nil)
+	mockFileStorage.On("SaveFile", mock.Anything, mock.Anything).Return("path/to/image/1.jpg", nil)
+	mockEventPublisher.On("Publish", mock.Anything, "123", models.EventPostCreated, mock.Anything).Return(nil)
+	mockPostListener.On("PostAdded", mock.Anything, post).Return()
+
+	_, err := mockService.AddPost(context.Background(), requester, post, nil)
+
+	assert.NoError(t, err)
+
+	mockPostAdder.AssertExpectations(t)
+	mockEventPublisher.AssertExpectations(t)
+	mockPostListener.AssertExpectations(t)
 }
 
 func TestAddPost_UniqueConstraintFails(t *testing.T) {
@@ -123,8 +351,12 @@ func TestAddPost_UniqueConstraintFails(t *testing.T) {
 		mockPostAdder,
 		nil,
 		nil,
+		nil,
 		mockFileStorage,
 		nil,
+		nil,
+		nil,
+		nil,
 	)
 
 	requester := &models.User{
@@ -165,8 +397,12 @@ func TestAddPost_UniqueConstraintFailsDeleteFileFails(t *testing.T) {
 		mockPostAdder,
 		nil,
 		nil,
+		nil,
 		mockFileStorage,
 		nil,
+		nil,
+		nil,
+		nil,
 	)
 
 	requester := &models.User{
@@ -209,8 +445,12 @@ func TestAddPost_SaveFileFails(t *testing.T) {
 		mockPostAdder,
 		nil,
 		nil,
+		nil,
 		mockFileStorage,
 		nil,
+		nil,
+		nil,
+		nil,
 	)
 
 	requester := &models.User{
@@ -249,8 +489,12 @@ func TestAddPost_OtherErr(t *testing.T) {
 		mockPostAdder,
 		nil,
 		nil,
+		nil,
 		mockFileStorage,
 		nil,
+		nil,
+		nil,
+		nil,
 	)
 
 	requester := &models.User{
@@ -288,6 +532,8 @@ func TestFilteredPosts_CacheHitSuccess(t *testing.T) {
 
 	mockPostProvider := new(mockPostProvider)
 	mockCache := new(mockCache)
+	mockCache.On("ListVersion", mock.Anything).Return(int64(0), nil)
+	mockBlockProvider := new(mockBlockProvider)
 
 	mockService := New(
 		slog.Default(),
@@ -295,7 +541,11 @@ func TestFilteredPosts_CacheHitSuccess(t *testing.T) {
 		mockPostProvider,
 		nil,
 		nil,
+		nil,
 		mockCache,
+		nil,
+		mockBlockProvider,
+		missingBlockCache(),
 	)
 
 	requester := &models.User{
@@ -347,6 +597,8 @@ func TestFilteredPosts_CacheHitSuccess(t *testing.T) {
 
 	mockCache.On("Get", mock.Anything, mock.Anything).Return(postsJSON, nil)
 
+	mockBlockProvider.On("BlockedIDs", mock.Anything, requester.ID).Return([]string(nil), nil)
+
 	actualPosts, err := mockService.FilteredPosts(context.Background(), limit, offset, filter, requester)
 
 	assert.NoError(t, err)
@@ -354,6 +606,7 @@ func TestFilteredPosts_CacheHitSuccess(t *testing.T) {
 
 	mockPostProvider.AssertExpectations(t)
 	mockCache.AssertExpectations(t)
+	mockBlockProvider.AssertExpectations(t)
 }
 
 func TestFilteredPosts_CacheMissSuccess(t *testing.T) {
@@ -361,6 +614,8 @@ func TestFilteredPosts_CacheMissSuccess(t *testing.T) {
 
 	mockPostProvider := new(mockPostProvider)
 	mockCache := new(mockCache)
+	mockCache.On("ListVersion", mock.Anything).Return(int64(0), nil)
+	mockBlockProvider := new(mockBlockProvider)
 
 	mockService := New(
 		slog.Default(),
@@ -368,7 +623,11 @@ func TestFilteredPosts_CacheMissSuccess(t *testing.T) {
 		mockPostProvider,
 		nil,
 		nil,
+		nil,
 		mockCache,
+		nil,
+		mockBlockProvider,
+		missingBlockCache(),
 	)
 
 	requester := &models.User{
@@ -498,15 +757,17 @@ func TestFilteredPosts_CacheMissSuccess(t *testing.T) {
 
 	someErr := errors.New("some error")
 
-	cacheKey := fmt.Sprintf("posts:%s:%v:%v:%s:%s:%v:%v", requester.Login, limit, offset, filter.SortBy, filter.SortOrder, filter.MinPrice, filter.MaxPrice)
+	cacheKey := fmt.Sprintf("posts:v0:%v:%v:%s:%s:%v:%v", limit, offset, filter.SortBy, filter.SortOrder, filter.MinPrice, filter.MaxPrice)
 
-	postsJSON, err := mapper.PostsToJSON(expPosts)
+	postsJSON, err := mapper.PostsToJSON(dbPosts)
 	assert.NoError(t, err)
 
 	mockCache.On("Get", mock.Anything, mock.Anything).Return("", someErr)
 	mockCache.On("Set", mock.Anything, cacheKey, postsJSON).Return(nil)
 	mockPostProvider.On("FilteredPosts", mock.Anything, limit, offset, filter).Return(dbPosts, nil)
 
+	mockBlockProvider.On("BlockedIDs", mock.Anything, requester.ID).Return([]string(nil), nil)
+
 	actualPosts, err := mockService.FilteredPosts(context.Background(), limit, offset, filter, requester)
 
 	assert.NoError(t, err)
@@ -514,6 +775,7 @@ func TestFilteredPosts_CacheMissSuccess(t *testing.T) {
 
 	mockPostProvider.AssertExpectations(t)
 	mockCache.AssertExpectations(t)
+	mockBlockProvider.AssertExpectations(t)
 }
 
 func TestFilteredPosts_CacheMissSetFails(t *testing.T) {
@@ -521,6 +783,8 @@ func TestFilteredPosts_CacheMissSetFails(t *testing.T) {
 
 	mockPostProvider := new(mockPostProvider)
 	mockCache := new(mockCache)
+	mockCache.On("ListVersion", mock.Anything).Return(int64(0), nil)
+	mockBlockProvider := new(mockBlockProvider)
 
 	mockService := New(
 		slog.Default(),
@@ -528,7 +792,11 @@ func TestFilteredPosts_CacheMissSetFails(t *testing.T) {
 		mockPostProvider,
 		nil,
 		nil,
+		nil,
 		mockCache,
+		nil,
+		mockBlockProvider,
+		missingBlockCache(),
 	)
 
 	requester := &models.User{
@@ -658,15 +926,17 @@ func TestFilteredPosts_CacheMissSetFails(t *testing.T) {
 
 	someErr := errors.New("some error")
 
-	cacheKey := fmt.Sprintf("posts:%s:%v:%v:%s:%s:%v:%v", requester.Login, limit, offset, filter.SortBy, filter.SortOrder, filter.MinPrice, filter.MaxPrice)
+	cacheKey := fmt.Sprintf("posts:v0:%v:%v:%s:%s:%v:%v", limit, offset, filter.SortBy, filter.SortOrder, filter.MinPrice, filter.MaxPrice)
 
-	postsJSON, err := mapper.PostsToJSON(expPosts)
+	postsJSON, err := mapper.PostsToJSON(dbPosts)
 	assert.NoError(t, err)
 
 	mockCache.On("Get", mock.Anything, mock.Anything).Return("", someErr)
 	mockCache.On("Set", mock.Anything, cacheKey, postsJSON).Return(someErr)
 	mockPostProvider.On("FilteredPosts", mock.Anything, limit, offset, filter).Return(dbPosts, nil)
 
+	mockBlockProvider.On("BlockedIDs", mock.Anything, requester.ID).Return([]string(nil), nil)
+
 	actualPosts, err := mockService.FilteredPosts(context.Background(), limit, offset, filter, requester)
 
 	assert.NoError(t, err)
@@ -674,6 +944,7 @@ func TestFilteredPosts_CacheMissSetFails(t *testing.T) {
 
 	mockPostProvider.AssertExpectations(t)
 	mockCache.AssertExpectations(t)
+	mockBlockProvider.AssertExpectations(t)
 }
 
 func TestFilteredPosts_PostsNotFound(t *testing.T) {
@@ -681,6 +952,8 @@ func TestFilteredPosts_PostsNotFound(t *testing.T) {
 
 	mockPostProvider := new(mockPostProvider)
 	mockCache := new(mockCache)
+	mockCache.On("ListVersion", mock.Anything).Return(int64(0), nil)
+	mockBlockProvider := new(mockBlockProvider)
 
 	mockService := New(
 		slog.Default(),
@@ -688,7 +961,11 @@ func TestFilteredPosts_PostsNotFound(t *testing.T) {
 		mockPostProvider,
 		nil,
 		nil,
+		nil,
 		mockCache,
+		nil,
+		mockBlockProvider,
+		missingBlockCache(),
 	)
 
 	requester := &models.User{
@@ -712,6 +989,8 @@ func TestFilteredPosts_PostsNotFound(t *testing.T) {
 	mockCache.On("Get", mock.Anything, mock.Anything).Return("", someErr)
 	mockPostProvider.On("FilteredPosts", mock.Anything, limit, offset, filter).Return(([]*models.PostWithDocument)(nil), models.ErrPostNotFound)
 
+	mockBlockProvider.On("BlockedIDs", mock.Anything, requester.ID).Return([]string(nil), nil)
+
 	actualPosts, err := mockService.FilteredPosts(context.Background(), limit, offset, filter, requester)
 
 	assert.ErrorIs(t, err, models.ErrPostNotFound)
@@ -719,6 +998,7 @@ func TestFilteredPosts_PostsNotFound(t *testing.T) {
 
 	mockPostProvider.AssertExpectations(t)
 	mockCache.AssertExpectations(t)
+	mockBlockProvider.AssertExpectations(t)
 }
 
 func TestFilteredPosts_OtherErr(t *testing.T) {
@@ -726,6 +1006,8 @@ func TestFilteredPosts_OtherErr(t *testing.T) {
 
 	mockPostProvider := new(mockPostProvider)
 	mockCache := new(mockCache)
+	mockCache.On("ListVersion", mock.Anything).Return(int64(0), nil)
+	mockBlockProvider := new(mockBlockProvider)
 
 	mockService := New(
 		slog.Default(),
@@ -733,7 +1015,11 @@ func TestFilteredPosts_OtherErr(t *testing.T) {
 		mockPostProvider,
 		nil,
 		nil,
+		nil,
 		mockCache,
+		nil,
+		mockBlockProvider,
+		missingBlockCache(),
 	)
 
 	requester := &models.User{
@@ -757,6 +1043,8 @@ func TestFilteredPosts_OtherErr(t *testing.T) {
 	mockCache.On("Get", mock.Anything, mock.Anything).Return("", someErr)
 	mockPostProvider.On("FilteredPosts", mock.Anything, limit, offset, filter).Return(([]*models.PostWithDocument)(nil), someErr)
 
+	mockBlockProvider.On("BlockedIDs", mock.Anything, requester.ID).Return([]string(nil), nil)
+
 	actualPosts, err := mockService.FilteredPosts(context.Background(), limit, offset, filter, requester)
 
 	assert.ErrorIs(t, err, models.ErrInternal)
@@ -764,4 +1052,437 @@ func TestFilteredPosts_OtherErr(t *testing.T) {
 
 	mockPostProvider.AssertExpectations(t)
 	mockCache.AssertExpectations(t)
+	mockBlockProvider.AssertExpectations(t)
+}
+
+func TestFilteredPosts_BlockedUsers(t *testing.T) {
+	t.Parallel()
+
+	mockPostProvider := new(mockPostProvider)
+	mockCache := new(mockCache)
+	mockCache.On("ListVersion", mock.Anything).Return(int64(0), nil)
+	mockBlockProvider := new(mockBlockProvider)
+
+	mockService := New(slog.Default(), nil, mockPostProvider, nil, nil, nil, mockCache, nil, mockBlockProvider, missingBlockCache())
+
+	requester := &models.User{ID: "1", Login: "test1"}
+
+	filter := &models.PostsFilter{SortBy: "price", SortOrder: "asc"}
+
+	plainKey := fmt.Sprintf("posts:v0:%v:%v:%s:%s:%v:%v", 10, 0, filter.SortBy, filter.SortOrder, filter.MinPrice, filter.MaxPrice)
+
+	var blockedKey string
+
+	mockBlockProvider.On("BlockedIDs", mock.Anything, "1").Return([]string{"3", "2"}, nil)
+	mockCache.On("Get", mock.Anything, mock.MatchedBy(func(key string) bool {
+		blockedKey = key
+		return strings.HasPrefix(key, plainKey+":blocks:")
+	})).Return("", nil)
+	mockCache.On("Set", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockPostProvider.On("FilteredPosts", mock.Anything, 10, 0, &models.PostsFilter{
+		SortBy:           "price",
+		SortOrder:        "asc",
+		ExcludedOwnerIDs: []string{"3", "2"},
+	}).Return([]*models.PostWithDocument{{ID: "p1", OwnerID: "1", OwnerLogin: "test1"}}, nil)
+
+	posts, err := mockService.FilteredPosts(context.Background(), 10, 0, filter, requester)
+
+	assert.NoError(t, err)
+	assert.True(t, posts[0].RequesterIsOwner)
+	assert.Empty(t, filter.ExcludedOwnerIDs)
+
+	assert.Equal(t, blockedKey, postsCacheKey(0, 10, 0, filter, []string{"2", "3"}), "block order must not matter")
+	assert.NotEqual(t, blockedKey, postsCacheKey(0, 10, 0, filter, []string{"2"}))
+	assert.Equal(t, plainKey, postsCacheKey(0, 10, 0, filter, nil))
+
+	mockPostProvider.AssertExpectations(t)
+	mockCache.AssertExpectations(t)
+	mockBlockProvider.AssertExpectations(t)
+}
+
+func TestFilteredPosts_VersionUnavailable(t *testing.T) {
+	t.Parallel()
+
+	mockPostProvider := new(mockPostProvider)
+	mockCache := new(mockCache)
+
+	mockService := New(slog.Default(), nil, mockPostProvider, nil, nil, nil, mockCache, nil, nil, nil)
+
+	filter := &models.PostsFilter{SortBy: "price", SortOrder: "asc"}
+
+	mockCache.On("ListVersion", mock.Anything).Return(int64(0), errors.New("redis down"))
+	mockPostProvider.On("FilteredPosts", mock.Anything, 10, 0, mock.Anything).Return([]*models.PostWithDocument{{ID: "p1"}}, nil)
+
+	posts, err := mockService.FilteredPosts(context.Background(), 10, 0, filter, nil)
+
+	assert.NoError(t, err)
+	assert.Len(t, posts, 1)
+
+	mockPostProvider.AssertExpectations(t)
+	mockCache.AssertNotCalled(t, "Get", mock.Anything, mock.Anything)
+	mockCache.AssertNotCalled(t, "Set", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestFilteredPosts_BlockListCached(t *testing.T) {
+	t.Parallel()
+
+	mockPostProvider := new(mockPostProvider)
+	mockCache := new(mockCache)
+	mockCache.On("ListVersion", mock.Anything).Return(int64(0), nil)
+	mockBlockProvider := new(mockBlockProvider)
+	mockBlockCache := new(mockBlockCache)
+
+	mockService := New(slog.Default(), nil, mockPostProvider, nil, nil, nil, mockCache, nil, mockBlockProvider, mockBlockCache)
+
+	requester := &models.User{ID: "1", Login: "test1"}
+
+	filter := &models.PostsFilter{SortBy: "price", SortOrder: "asc"}
+
+	postsJSON, _ := mapper.PostsToJSON([]*models.PostWithDocument{{ID: "p1", OwnerLogin: "seller"}})
+
+	mockBlockCache.On("BlockedIDs", mock.Anything, "1").Return([]string{"2"}, true, nil)
+	mockCache.On("Get", mock.Anything, postsCacheKey(0, 10, 0, filter, []string{"2"})).Return(postsJSON, nil)
+
+	posts, err := mockService.FilteredPosts(context.Background(), 10, 0, filter, requester)
+
+	assert.NoError(t, err)
+	assert.Len(t, posts, 1)
+
+	mockBlockCache.AssertExpectations(t)
+	mockCache.AssertExpectations(t)
+	mockBlockProvider.AssertNotCalled(t, "BlockedIDs", mock.Anything, mock.Anything)
+	mockPostProvider.AssertNotCalled(t, "FilteredPosts", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestUpdatePost(t *testing.T) {
+	t.Parallel()
+
+	header := "new header"
+	price := int64(250)
+
+	tests := []struct {
+		name       string
+		requester  *models.User
+		auction    *models.Auction
+		update     *models.PostUpdate
+		wantErr    error
+		wantUpdate bool
+	}{
+		{
+			name:       "owner",
+			requester:  &models.User{ID: "1"},
+			update:     &models.PostUpdate{Header: &header, Price: &price},
+			wantUpdate: true,
+		},
+		{
+			name:       "moderator",
+			requester:  &models.User{ID: "2", Role: models.RoleModerator},
+			update:     &models.PostUpdate{Header: &header},
+			wantUpdate: true,
+		},
+		{
+			name:      "not owner",
+			requester: &models.User{ID: "2"},
+			update:    &models.PostUpdate{Header: &header},
+			wantErr:   models.ErrForbidden,
+		},
+		{
+			name:      "auction price",
+			requester: &models.User{ID: "1"},
+			auction:   &models.Auction{StartPrice: 100, MinIncrement: 10},
+			update:    &models.PostUpdate{Price: &price},
+			wantErr:   models.ErrInvalidPrice,
+		},
+		{
+			name:      "invalid header",
+			requester: &models.User{ID: "1"},
+			update:    &models.PostUpdate{Header: new(string)},
+			wantErr:   models.ErrInvalidHeader,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockPostProvider := new(mockPostProvider)
+			mockPostUpdater := new(mockPostUpdater)
+
+			mockService := New(slog.Default(), nil, mockPostProvider, mockPostUpdater, nil, nil, nil, nil, nil, nil)
+
+			id := uuid.NewV4().String()
+
+			mockPostProvider.On("PostByID", mock.Anything, id).Return(&models.PostWithDocument{
+				ID:      id,
+				OwnerID: "1",
+				Header:  "old header",
+				Text:    "some long text",
+				Price:   100,
+				Auction: tt.auction,
+			}, nil)
+			if tt.wantUpdate {
+				mockPostUpdater.On("UpdatePost", mock.Anything, mock.MatchedBy(func(post *models.PostWithDocument) bool {
+					return post.ID == id && post.Header == header && post.Text == "some long text"
+				})).Return(nil)
+			}
+
+			post, err := mockService.UpdatePost(context.Background(), tt.requester, id, tt.update)
+
+			assert.ErrorIs(t, err, tt.wantErr)
+			if tt.wantUpdate {
+				assert.Equal(t, header, post.Header)
+				assert.Equal(t, tt.requester.ID == "1", post.RequesterIsOwner)
+			}
+			mockPostProvider.AssertExpectations(t)
+			mockPostUpdater.AssertExpectations(t)
+		})
+	}
+}
+
+func TestDeletePost_Success(t *testing.T) {
+	t.Parallel()
+
+	mockPostProvider := new(mockPostProvider)
+	mockPostRemover := new(mockPostRemover)
+	mockFileStorage := new(mockFileStorage)
+	mockEventPublisher := new(mockEventPublisher)
+	mockCache := new(mockCache)
+
+	mockService := New(
+		slog.Default(),
+		nil,
+		mockPostProvider,
+		nil,
+		mockPostRemover,
+		mockFileStorage,
+		mockCache,
+		mockEventPublisher,
+		nil,
+		nil,
+	)
+
+	requester := &models.User{ID: "1", Login: "test1"}
+
+	id := uuid.NewV4().String()
+
+	post := &models.PostWithDocument{
+		ID:       id,
+		OwnerID:  "1",
+		Header:   "header",
+		Document: &models.Document{ID: "11", PostID: id, Path: "/static/files/1.jpg"},
+	}
+
+	mockPostProvider.On("PostByID", mock.Anything, id).Return(post, nil)
+	mockPostRemover.On("DeletePost", mock.Anything, id).Return(nil)
+	mockFileStorage.On("DeleteFile", post.Document).Return(nil)
+	mockEventPublisher.On("Publish", mock.Anything, "1", models.EventPostDeleted, models.PostEventPayload{PostID: id, Header: "header"}).Return(nil)
+	mockCache.On("InvalidateLists", mock.Anything).Return(nil)
+
+	err := mockService.DeletePost(context.Background(), requester, id)
+
+	assert.NoError(t, err)
+
+	mockPostProvider.AssertExpectations(t)
+	mockPostRemover.AssertExpectations(t)
+	mockFileStorage.AssertExpectations(t)
+	mockEventPublisher.AssertExpectations(t)
+	mockCache.AssertExpectations(t)
+}
+
+// memCache keeps cached pages in memory, so a test can follow them across
+// calls.
+type memCache struct {
+	mu      sync.Mutex
+	version int64
+	values  map[string]string
+}
+
+func newMemCache() *memCache {
+	return &memCache{values: make(map[string]string)}
+}
+
+func (c *memCache) Get(_ context.Context, key string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.values[key], nil
+}
+
+func (c *memCache) Set(_ context.Context, key string, value interface{}) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.values[key] = value.(string)
+	return nil
+}
+
+func (c *memCache) Del(_ context.Context, keys ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, key := range keys {
+		delete(c.values, key)
+	}
+	return nil
+}
+
+func (c *memCache) ListVersion(_ context.Context) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.version, nil
+}
+
+func (c *memCache) InvalidateLists(_ context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.version++
+	return nil
+}
+
+func TestDeletePost_LeavesCachedListing(t *testing.T) {
+	t.Parallel()
+
+	mockPostProvider := new(mockPostProvider)
+	mockPostRemover := new(mockPostRemover)
+	mockFileStorage := new(mockFileStorage)
+	mockEventPublisher := new(mockEventPublisher)
+
+	mockService := New(slog.Default(), nil, mockPostProvider, nil, mockPostRemover, mockFileStorage, newMemCache(), mockEventPublisher, nil, nil)
+
+	id := uuid.NewV4().String()
+
+	deleted := &models.PostWithDocument{ID: id, OwnerID: "1", Header: "header", Document: &models.Document{ID: "11", PostID: id}}
+	kept := &models.PostWithDocument{ID: "p2", OwnerID: "2", Header: "other", Document: &models.Document{ID: "22", PostID: "p2"}}
+
+	filter := &models.PostsFilter{SortBy: "price", SortOrder: "asc"}
+
+	mockPostProvider.On("FilteredPosts", mock.Anything, 10, 0, filter).Return([]*models.PostWithDocument{deleted, kept}, nil).Once()
+	mockPostProvider.On("FilteredPosts", mock.Anything, 10, 0, filter).Return([]*models.PostWithDocument{kept}, nil).Once()
+	mockPostProvider.On("PostByID", mock.Anything, id).Return(deleted, nil)
+	mockPostRemover.On("DeletePost", mock.Anything, id).Return(nil)
+	mockFileStorage.On("DeleteFile", deleted.Document).Return(nil)
+	mockEventPublisher.On("Publish", mock.Anything, "1", models.EventPostDeleted, mock.Anything).Return(nil)
+
+	// The second page comes from the cache.
+	for range 2 {
+		posts, err := mockService.FilteredPosts(context.Background(), 10, 0, filter, nil)
+		assert.NoError(t, err)
+		assert.Len(t, posts, 2)
+	}
+
+	err := mockService.DeletePost(context.Background(), &models.User{ID: "1"}, id)
+	assert.NoError(t, err)
+
+	posts, err := mockService.FilteredPosts(context.Background(), 10, 0, filter, nil)
+	assert.NoError(t, err)
+	if assert.Len(t, posts, 1) {
+		assert.Equal(t, "p2", posts[0].ID)
+	}
+
+	mockPostProvider.AssertNumberOfCalls(t, "FilteredPosts", 2)
+}
+
+func TestDeletePost_NotOwner(t *testing.T) {
+	t.Parallel()
+
+	mockPostProvider := new(mockPostProvider)
+	mockPostRemover := new(mockPostRemover)
+
+	mockService := New(
+		slog.Default(),
+		nil,
+		mockPostProvider,
+		nil,
+		mockPostRemover,
+		nil,
+		listsCache(),
+		nil,
+		nil,
+		nil,
+	)
+
+	requester := &models.User{ID: "2", Login: "test2"}
+
+	id := uuid.NewV4().String()
+
+	mockPostProvider.On("PostByID", mock.Anything, id).Return(&models.PostWithDocument{ID: id, OwnerID: "1"}, nil)
+
+	err := mockService.DeletePost(context.Background(), requester, id)
+
+	assert.ErrorIs(t, err, models.ErrForbidden)
+
+	mockPostProvider.AssertExpectations(t)
+	mockPostRemover.AssertExpectations(t)
+}
+
+func TestDeletePost_Moderator(t *testing.T) {
+	t.Parallel()
+
+	for _, role := range []models.Role{models.RoleModerator, models.RoleAdmin} {
+		t.Run(string(role), func(t *testing.T) {
+			t.Parallel()
+
+			mockPostProvider := new(mockPostProvider)
+			mockPostRemover := new(mockPostRemover)
+			mockFileStorage := new(mockFileStorage)
+			mockEventPublisher := new(mockEventPublisher)
+
+			mockService := New(slog.Default(), nil, mockPostProvider, nil, mockPostRemover, mockFileStorage, listsCache(), mockEventPublisher, nil, nil)
+
+			requester := &models.User{ID: "2", Login: "moderator", Role: role}
+
+			id := uuid.NewV4().String()
+
+			post := &models.PostWithDocument{ID: id, OwnerID: "1", Header: "header", Document: &models.Document{ID: "11", PostID: id}}
+
+			mockPostProvider.On("PostByID", mock.Anything, id).Return(post, nil)
+			mockPostRemover.On("DeletePost", mock.Anything, id).Return(nil)
+			mockFileStorage.On("DeleteFile", post.Document).Return(nil)
+			mockEventPublisher.On("Publish", mock.Anything, "1", models.EventPostDeleted, mock.Anything).Return(nil)
+
+			err := mockService.DeletePost(context.Background(), requester, id)
+
+			assert.NoError(t, err)
+			mockPostRemover.AssertExpectations(t)
+			mockEventPublisher.AssertExpectations(t)
+		})
+	}
+}
+
+func TestDeletePost_NotFound(t *testing.T) {
+	t.Parallel()
+
+	mockPostProvider := new(mockPostProvider)
+
+	mockService := New(
+		slog.Default(),
+		nil,
+		mockPostProvider,
+		nil,
+		nil,
+		nil,
+		listsCache(),
+		nil,
+		nil,
+		nil,
+	)
+
+	requester := &models.User{ID: "1", Login: "test1"}
+
+	id := uuid.NewV4().String()
+
+	mockPostProvider.On("PostByID", mock.Anything, id).Return((*models.PostWithDocument)(nil), models.ErrPostNotFound)
+
+	err := mockService.DeletePost(context.Background(), requester, id)
+
+	assert.ErrorIs(t, err, models.ErrPostNotFound)
+
+	mockPostProvider.AssertExpectations(t)
+}
+
+func TestDeletePost_InvalidID(t *testing.T) {
+	t.Parallel()
+
+	mockService := New(slog.Default(), nil, nil, nil, nil, nil, nil, nil, nil, nil)
+
+	err := mockService.DeletePost(context.Background(), &models.User{ID: "1"}, "bad")
+
+	assert.ErrorIs(t, err, models.ErrPostNotFound)
 }
@@ -0,0 +1,21 @@
+package profileservice
+
+import (
+	"context"
+	"io"
+	"marketplace/internal/models"
+)
+
+type ProfileProvider interface {
+	ProfileByLogin(ctx context.Context, login string) (*models.Profile, error)
+	ProfileByID(ctx context.Context, id string) (*models.Profile, error)
+}
+
+type ProfileUpdater interface {
+	UpdateProfile(ctx context.Context, profile *models.Profile) error
+}
+
+type FileStorage interface {
+	SaveFile(doc *models.Document, reader io.Reader) (string, error)
+	DeleteFile(doc *models.Document) error
+}
@@ -0,0 +1,163 @@
+package profileservice
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"marketplace/internal/models"
+	"marketplace/internal/utils/validator"
+
+	uuid "github.com/satori/go.uuid"
+)
+
+const pkg = "profileService/"
+
+const avatarName = "avatar.jpg"
+
+type ProfileService struct {
+	log             *slog.Logger
+	profileProvider ProfileProvider
+	profileUpdater  ProfileUpdater
+	fileStorage     FileStorage
+}
+
+func New(
+	log *slog.Logger,
+	profileProvider ProfileProvider,
+	profileUpdater ProfileUpdater,
+	fileStorage FileStorage,
+) *ProfileService {
+	return &ProfileService{
+		log:             log,
+		profileProvider: profileProvider,
+		profileUpdater:  profileUpdater,
+		fileStorage:     fileStorage,
+	}
+}
+
+func (ps *ProfileService) Profile(ctx context.Context, login string) (*models.Profile, error) {
+	op := pkg + "Profile"
+
+	log := ps.log.With(slog.String("op", op))
+
+	log.Debug("attempting to get profile")
+
+	profile, err := ps.profileProvider.ProfileByLogin(ctx, login)
+	if err != nil {
+		if errors.Is(err, models.ErrUserNotFound) {
+			log.Warn("user not found", slog.String("login", login))
+			return nil, models.ErrUserNotFound
+		}
+		log.Error("failed to get profile", slog.String("error", err.Error()))
+		return nil, models.ErrInternal
+	}
+
+	log.Debug("profile found successfully")
+
+	return profile, nil
+}
+
+func (ps *ProfileService) MyProfile(ctx context.Context, requester *models.User) (*models.Profile, error) {
+	op := pkg + "MyProfile"
+
+	log := ps.log.With(slog.String("op", op))
+
+	log.Debug("attempting to get own profile")
+
+	profile, err := ps.myProfile(ctx, log, requester)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Debug("profile found successfully")
+
+	return profile, nil
+}
+
+// UpdateProfile changes the fields set in update and, when avatar is not nil,
+// replaces the avatar image. The previous avatar file is removed once the new
+// one is stored.
+func (ps *ProfileService) UpdateProfile(ctx context.Context, requester *models.User, update *models.ProfileUpdate, avatar io.Reader) (*models.Profile, error) {
+	op := pkg + "UpdateProfile"
+
+	log := ps.log.With(slog.String("op", op))
+
+	log.Debug("attempting to update profile")
+
+	if err := validator.ValidateProfileUpdate(update); err != nil {
+		log.Warn("invalid profile update received", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	profile, err := ps.myProfile(ctx, log, requester)
+	if err != nil {
+		return nil, err
+	}
+
+	if update.DisplayName != nil {
+		profile.DisplayName = *update.DisplayName
+	}
+
+	if update.Bio != nil {
+		profile.Bio = *update.Bio
+	}
+
+	oldAvatarPath := profile.AvatarPath
+
+	var newAvatar *models.Document
+
+	if avatar != nil {
+		newAvatar = &models.Document{
+			ID:   uuid.NewV4().String(),
+			Name: avatarName,
+			Mime: "image/jpeg",
+		}
+
+		path, err := ps.fileStorage.SaveFile(newAvatar, avatar)
+		if err != nil {
+			log.Error("failed to save avatar", slog.String("error", err.Error()))
+			return nil, models.ErrInternal
+		}
+
+		profile.AvatarPath = path
+	}
+
+	err = ps.profileUpdater.UpdateProfile(ctx, profile)
+	if err != nil {
+		if newAvatar != nil {
+			_ = ps.fileStorage.DeleteFile(newAvatar)
+		}
+
+		if errors.Is(err, models.ErrUserNotFound) {
+			log.Warn("user not found", slog.String("user_id", requester.ID))
+			return nil, models.ErrUserNotFound
+		}
+		log.Error("failed to update profile", slog.String("error", err.Error()))
+		return nil, models.ErrInternal
+	}
+
+	if newAvatar != nil && oldAvatarPath != "" {
+		if err := ps.fileStorage.DeleteFile(&models.Document{Path: oldAvatarPath}); err != nil {
+			log.Warn("failed to delete previous avatar", slog.String("error", err.Error()))
+		}
+	}
+
+	log.Debug("profile updated successfully")
+
+	return profile, nil
+}
+
+func (ps *ProfileService) myProfile(ctx context.Context, log *slog.Logger, requester *models.User) (*models.Profile, error) {
+	profile, err := ps.profileProvider.ProfileByID(ctx, requester.ID)
+	if err != nil {
+		if errors.Is(err, models.ErrUserNotFound) {
+			log.Warn("user not found", slog.String("user_id", requester.ID))
+			return nil, models.ErrUserNotFound
+		}
+		log.Error("failed to get profile", slog.String("error", err.Error()))
+		return nil, models.ErrInternal
+	}
+
+	return profile, nil
+}
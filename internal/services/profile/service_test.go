@@ -0,0 +1,147 @@
+package profileservice
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"marketplace/internal/models"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockProfileProvider struct {
+	mock.Mock
+}
+
+func (m *mockProfileProvider) ProfileByLogin(ctx context.Context, login string) (*models.Profile, error) {
+	args := m.Called(ctx, login)
+	return args.Get(0).(*models.Profile), args.Error(1)
+}
+
+func (m *mockProfileProvider) ProfileByID(ctx context.Context, id string) (*models.Profile, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*models.Profile), args.Error(1)
+}
+
+type mockProfileUpdater struct {
+	mock.Mock
+}
+
+func (m *mockProfileUpdater) UpdateProfile(ctx context.Context, profile *models.Profile) error {
+	args := m.Called(ctx, profile)
+	return args.Error(0)
+}
+
+type mockFileStorage struct {
+	mock.Mock
+}
+
+func (m *mockFileStorage) SaveFile(doc *models.Document, reader io.Reader) (string, error) {
+	args := m.Called(doc, reader)
+	return args.String(0), args.Error(1)
+}
+
+func (m *mockFileStorage) DeleteFile(doc *models.Document) error {
+	args := m.Called(doc)
+	return args.Error(0)
+}
+
+func ptr(s string) *string {
+	return &s
+}
+
+func TestProfile_NotFound(t *testing.T) {
+	t.Parallel()
+
+	provider := new(mockProfileProvider)
+
+	service := New(slog.Default(), provider, nil, nil)
+
+	provider.On("ProfileByLogin", mock.Anything, "ghost").Return((*models.Profile)(nil), models.ErrUserNotFound)
+
+	_, err := service.Profile(context.Background(), "ghost")
+
+	assert.ErrorIs(t, err, models.ErrUserNotFound)
+}
+
+func TestUpdateProfile_KeepsUnsetFields(t *testing.T) {
+	t.Parallel()
+
+	provider := new(mockProfileProvider)
+	updater := new(mockProfileUpdater)
+
+	service := New(slog.Default(), provider, updater, nil)
+
+	provider.On("ProfileByID", mock.Anything, "1").Return(&models.Profile{UserID: "1", DisplayName: "Old", Bio: "old bio"}, nil)
+	updater.On("UpdateProfile", mock.Anything, &models.Profile{UserID: "1", DisplayName: "New", Bio: "old bio"}).Return(nil)
+
+	profile, err := service.UpdateProfile(context.Background(), &models.User{ID: "1"}, &models.ProfileUpdate{DisplayName: ptr("New")}, nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "New", profile.DisplayName)
+	updater.AssertExpectations(t)
+}
+
+func TestUpdateProfile_InvalidBio(t *testing.T) {
+	t.Parallel()
+
+	service := New(slog.Default(), nil, nil, nil)
+
+	_, err := service.UpdateProfile(context.Background(), &models.User{ID: "1"}, &models.ProfileUpdate{Bio: ptr(strings.Repeat("a", 501))}, nil)
+
+	assert.ErrorIs(t, err, models.ErrInvalidText)
+}
+
+func TestUpdateProfile_ReplacesAvatar(t *testing.T) {
+	t.Parallel()
+
+	provider := new(mockProfileProvider)
+	updater := new(mockProfileUpdater)
+	storage := new(mockFileStorage)
+
+	service := New(slog.Default(), provider, updater, storage)
+
+	avatar := strings.NewReader("jpeg")
+
+	provider.On("ProfileByID", mock.Anything, "1").Return(&models.Profile{UserID: "1", AvatarPath: "/static/old.jpg"}, nil)
+	storage.On("SaveFile", mock.AnythingOfType("*models.Document"), avatar).Return("/static/new.jpg", nil)
+	updater.On("UpdateProfile", mock.Anything, mock.MatchedBy(func(p *models.Profile) bool {
+		return p.AvatarPath == "/static/new.jpg"
+	})).Return(nil)
+	storage.On("DeleteFile", &models.Document{Path: "/static/old.jpg"}).Return(nil)
+
+	profile, err := service.UpdateProfile(context.Background(), &models.User{ID: "1"}, &models.ProfileUpdate{}, avatar)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "/static/new.jpg", profile.AvatarPath)
+	storage.AssertExpectations(t)
+}
+
+func TestUpdateProfile_RemovesNewAvatarOnFailure(t *testing.T) {
+	t.Parallel()
+
+	provider := new(mockProfileProvider)
+	updater := new(mockProfileUpdater)
+	storage := new(mockFileStorage)
+
+	service := New(slog.Default(), provider, updater, storage)
+
+	avatar := strings.NewReader("jpeg")
+
+	provider.On("ProfileByID", mock.Anything, "1").Return(&models.Profile{UserID: "1", AvatarPath: "/static/old.jpg"}, nil)
+	storage.On("SaveFile", mock.AnythingOfType("*models.Document"), avatar).Return("/static/new.jpg", nil)
+	updater.On("UpdateProfile", mock.Anything, mock.Anything).Return(errors.New("db error"))
+	storage.On("DeleteFile", mock.MatchedBy(func(doc *models.Document) bool {
+		return doc.Name == avatarName
+	})).Return(nil)
+
+	_, err := service.UpdateProfile(context.Background(), &models.User{ID: "1"}, &models.ProfileUpdate{}, avatar)
+
+	assert.ErrorIs(t, err, models.ErrInternal)
+	storage.AssertExpectations(t)
+	storage.AssertNotCalled(t, "DeleteFile", &models.Document{Path: "/static/old.jpg"})
+}
@@ -0,0 +1,29 @@
+package reportservice
+
+import (
+	"context"
+	"marketplace/internal/models"
+	"time"
+)
+
+type ReportStorer interface {
+	AddReport(ctx context.Context, report *models.Report) (int, error)
+	HidePost(ctx context.Context, postID string) (bool, error)
+	OpenReports(ctx context.Context, limit int, offset int) ([]*models.Report, error)
+	ReportByID(ctx context.Context, id string) (*models.Report, error)
+	CloseReports(ctx context.Context, postID string, status models.ReportStatus, moderatorID string, closedAt time.Time) (bool, error)
+}
+
+type PostProvider interface {
+	PostByID(ctx context.Context, id string) (*models.PostWithDocument, error)
+}
+
+type Notifier interface {
+	Notify(ctx context.Context, userID string, kind models.NotificationKind, payload models.NotificationPayload) error
+}
+
+// PostCache drops the cached post listings after a post is hidden or shown
+// again.
+type PostCache interface {
+	InvalidateLists(ctx context.Context) error
+}
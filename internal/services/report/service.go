@@ -0,0 +1,252 @@
+package reportservice
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"marketplace/internal/models"
+	"marketplace/internal/utils/validator"
+	"strings"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+)
+
+const pkg = "reportService/"
+
+type ReportService struct {
+	log          *slog.Logger
+	reportStorer ReportStorer
+	postProvider PostProvider
+	postCache    PostCache
+	notifier     Notifier
+	threshold    int
+	moderators   models.Moderators
+}
+
+func New(
+	log *slog.Logger,
+	reportStorer ReportStorer,
+	postProvider PostProvider,
+	postCache PostCache,
+	notifier Notifier,
+	threshold int,
+	moderators models.Moderators,
+) *ReportService {
+	return &ReportService{
+		log:          log,
+		reportStorer: reportStorer,
+		postProvider: postProvider,
+		postCache:    postCache,
+		notifier:     notifier,
+		threshold:    threshold,
+		moderators:   moderators,
+	}
+}
+
+// Report flags the post for moderators. A user can report a post only once
+// and never their own. Once the post collects threshold open reports it is
+// hidden from the listings until a moderator looks at it.
+func (s *ReportService) Report(ctx context.Context, requester *models.User, postID string, reason models.ReportReason, text string) (*models.Report, error) {
+	op := pkg + "Report"
+
+	log := s.log.With(slog.String("op", op))
+
+	log.Debug("attempting to report post")
+
+	text = strings.TrimSpace(text)
+
+	if err := validator.ValidateReport(reason, text); err != nil {
+		log.Warn("invalid report received", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	if _, err := uuid.FromString(postID); err != nil {
+		log.Warn("invalid post id received", slog.String("post_id", postID))
+		return nil, models.ErrPostNotFound
+	}
+
+	post, err := s.postProvider.PostByID(ctx, postID)
+	if err != nil {
+		if errors.Is(err, models.ErrPostNotFound) {
+			log.Warn("post not found", slog.String("post_id", postID))
+			return nil, models.ErrPostNotFound
+		}
+		log.Error("failed to get post", slog.String("error", err.Error()))
+		return nil, models.ErrInternal
+	}
+
+	if post.OwnerID == requester.ID {
+		log.Warn("owner tried to report own post", slog.String("post_id", postID))
+		return nil, models.ErrOwnPost
+	}
+
+	report := &models.Report{
+		ID:            uuid.NewV4().String(),
+		PostID:        post.ID,
+		PostOwnerID:   post.OwnerID,
+		PostHeader:    post.Header,
+		ReporterID:    requester.ID,
+		ReporterLogin: requester.Login,
+		Reason:        reason,
+		Text:          text,
+		Status:        models.ReportOpen,
+		CreatedAt:     time.Now(),
+	}
+
+	count, err := s.reportStorer.AddReport(ctx, report)
+	if err != nil {
+		var uce *models.UniqueConstraintError
+		if errors.As(err, &uce) {
+			log.Warn("post already reported by requester", slog.String("post_id", postID))
+			return nil, models.ErrReportExists
+		}
+		log.Error("failed to add report", slog.String("error", err.Error()))
+		return nil, models.ErrInternal
+	}
+
+	if s.threshold > 0 && count >= s.threshold {
+		s.hide(ctx, log, report, count)
+	}
+
+	log.Debug("post reported successfully", slog.String("report_id", report.ID), slog.Int("open_reports", count))
+
+	return report, nil
+}
+
+// hide takes the reported post out of the listings. The owner is told only
+// when this report hid the post, not on every later report.
+func (s *ReportService) hide(ctx context.Context, log *slog.Logger, report *models.Report, count int) {
+	hidden, err := s.reportStorer.HidePost(ctx, report.PostID)
+	if err != nil {
+		log.Error("failed to hide reported post", slog.String("post_id", report.PostID), slog.String("error", err.Error()))
+		return
+	}
+
+	report.PostHidden = true
+
+	if !hidden {
+		return
+	}
+
+	s.invalidatePosts(ctx, log)
+
+	log.Info("post hidden after reports", slog.String("post_id", report.PostID), slog.Int("open_reports", count))
+
+	err = s.notifier.Notify(ctx, report.PostOwnerID, models.NotificationModeration, models.ModerationPayload{
+		Action: "post_hidden",
+		PostID: report.PostID,
+	})
+	if err != nil {
+		log.Warn("failed to notify about hidden post", slog.String("error", err.Error()))
+	}
+}
+
+// OpenReports returns the moderation queue of reports, oldest first.
+func (s *ReportService) OpenReports(ctx context.Context, requester *models.User, limit int, offset int) ([]*models.Report, error) {
+	op := pkg + "OpenReports"
+
+	log := s.log.With(slog.String("op", op))
+
+	log.Debug("attempting to get open reports")
+
+	if !s.moderators.Allows(requester) {
+		log.Warn("requester is not a moderator", slog.String("login", requester.Login))
+		return nil, models.ErrForbidden
+	}
+
+	reports, err := s.reportStorer.OpenReports(ctx, limit, offset)
+	if err != nil {
+		log.Error("failed to get open reports", slog.String("error", err.Error()))
+		return nil, models.ErrInternal
+	}
+
+	log.Debug("open reports found successfully", slog.Int("count", len(reports)))
+
+	return reports, nil
+}
+
+// Resolve upholds the report. Every open report of the post is closed with it
+// and the post stays hidden.
+func (s *ReportService) Resolve(ctx context.Context, requester *models.User, id string) (*models.Report, error) {
+	return s.close(ctx, pkg+"Resolve", requester, id, models.ReportResolved)
+}
+
+// Dismiss rejects the report. Every open report of the post is closed with it
+// and the post is shown again, unless an earlier report of it was resolved.
+func (s *ReportService) Dismiss(ctx context.Context, requester *models.User, id string) (*models.Report, error) {
+	return s.close(ctx, pkg+"Dismiss", requester, id, models.ReportDismissed)
+}
+
+func (s *ReportService) close(ctx context.Context, op string, requester *models.User, id string, status models.ReportStatus) (*models.Report, error) {
+	log := s.log.With(slog.String("op", op))
+
+	log.Debug("attempting to close report")
+
+	if !s.moderators.Allows(requester) {
+		log.Warn("requester is not a moderator", slog.String("login", requester.Login))
+		return nil, models.ErrForbidden
+	}
+
+	if _, err := uuid.FromString(id); err != nil {
+		log.Warn("invalid report id received", slog.String("report_id", id))
+		return nil, models.ErrReportNotFound
+	}
+
+	report, err := s.reportStorer.ReportByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, models.ErrReportNotFound) {
+			log.Warn("report not found", slog.String("report_id", id))
+			return nil, models.ErrReportNotFound
+		}
+		log.Error("failed to get report", slog.String("error", err.Error()))
+		return nil, models.ErrInternal
+	}
+
+	if report.Status != models.ReportOpen {
+		log.Warn("report is already closed", slog.String("report_id", id))
+		return nil, models.ErrReportClosed
+	}
+
+	now := time.Now()
+
+	hidden, err := s.reportStorer.CloseReports(ctx, report.PostID, status, requester.ID, now)
+	if err != nil {
+		if errors.Is(err, models.ErrReportNotFound) {
+			log.Warn("reports closed concurrently", slog.String("report_id", id))
+			return nil, models.ErrReportClosed
+		}
+		log.Error("failed to close reports", slog.String("error", err.Error()))
+		return nil, models.ErrInternal
+	}
+
+	wasHidden := report.PostHidden
+
+	report.Status = status
+	report.ClosedAt = &now
+	report.PostHidden = hidden
+
+	if wasHidden && !hidden {
+		s.invalidatePosts(ctx, log)
+
+		err = s.notifier.Notify(ctx, report.PostOwnerID, models.NotificationModeration, models.ModerationPayload{
+			Action: "post_restored",
+			PostID: report.PostID,
+		})
+		if err != nil {
+			log.Warn("failed to notify about restored post", slog.String("error", err.Error()))
+		}
+	}
+
+	log.Info("report closed", slog.String("report_id", id), slog.String("status", string(status)), slog.String("moderator_id", requester.ID))
+
+	return report, nil
+}
+
+// invalidatePosts drops the cached listings after the post was hidden or shown
+// again. They expire on their own if this fails.
+func (s *ReportService) invalidatePosts(ctx context.Context, log *slog.Logger) {
+	if err := s.postCache.InvalidateLists(ctx); err != nil {
+		log.Warn("failed to invalidate cached posts", slog.String("error", err.Error()))
+	}
+}
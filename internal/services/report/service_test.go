@@ -0,0 +1,231 @@
+package reportservice
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"marketplace/internal/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockReportStorer struct {
+	mock.Mock
+}
+
+func (m *mockReportStorer) AddReport(ctx context.Context, report *models.Report) (int, error) {
+	args := m.Called(ctx, report)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *mockReportStorer) HidePost(ctx context.Context, postID string) (bool, error) {
+	args := m.Called(ctx, postID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *mockReportStorer) OpenReports(ctx context.Context, limit int, offset int) ([]*models.Report, error) {
+	args := m.Called(ctx, limit, offset)
+	reports, _ := args.Get(0).([]*models.Report)
+	return reports, args.Error(1)
+}
+
+func (m *mockReportStorer) ReportByID(ctx context.Context, id string) (*models.Report, error) {
+	args := m.Called(ctx, id)
+	report, _ := args.Get(0).(*models.Report)
+	return report, args.Error(1)
+}
+
+func (m *mockReportStorer) CloseReports(ctx context.Context, postID string, status models.ReportStatus, moderatorID string, closedAt time.Time) (bool, error) {
+	args := m.Called(ctx, postID, status, moderatorID, closedAt)
+	return args.Bool(0), args.Error(1)
+}
+
+type mockPostProvider struct {
+	mock.Mock
+}
+
+func (m *mockPostProvider) PostByID(ctx context.Context, id string) (*models.PostWithDocument, error) {
+	args := m.Called(ctx, id)
+	post, _ := args.Get(0).(*models.PostWithDocument)
+	return post, args.Error(1)
+}
+
+type mockPostCache struct {
+	mock.Mock
+}
+
+func (m *mockPostCache) InvalidateLists(ctx context.Context) error {
+	return m.Called(ctx).Error(0)
+}
+
+type mockNotifier struct {
+	mock.Mock
+}
+
+func (m *mockNotifier) Notify(ctx context.Context, userID string, kind models.NotificationKind, payload models.NotificationPayload) error {
+	return m.Called(ctx, userID, kind, payload).Error(0)
+}
+
+const (
+	postID   = "6ba7b810-9dad-11d1-80b4-00c04fd430c8"
+	reportID = "6ba7b811-9dad-11d1-80b4-00c04fd430c8"
+)
+
+var (
+	buyer     = &models.User{ID: "u1", Login: "buyer"}
+	seller    = &models.User{ID: "u2", Login: "seller"}
+	moderator = &models.User{ID: "m1", Login: "mod"}
+	post      = &models.PostWithDocument{ID: postID, OwnerID: "u2", Header: "iphone"}
+)
+
+func newService(reports *mockReportStorer, posts *mockPostProvider, postCache *mockPostCache, notifier *mockNotifier) *ReportService {
+	return New(slog.New(slog.NewTextHandler(io.Discard, nil)), reports, posts, postCache, notifier, 3, models.NewModerators([]string{"mod"}))
+}
+
+func TestReport(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		requester  *models.User
+		reason     models.ReportReason
+		addErr     error
+		openCount  int
+		wasVisible bool
+		wantHidden bool
+		wantNotify bool
+		wantErr    error
+	}{
+		{name: "below threshold", requester: buyer, reason: models.ReportScam, openCount: 2},
+		{name: "reaches threshold", requester: buyer, reason: models.ReportScam, openCount: 3, wasVisible: true, wantHidden: true, wantNotify: true},
+		{name: "past threshold", requester: buyer, reason: models.ReportSpam, openCount: 4, wantHidden: true},
+		// Reports added at the same time may all count past the threshold.
+		{name: "threshold skipped", requester: buyer, reason: models.ReportSpam, openCount: 4, wasVisible: true, wantHidden: true, wantNotify: true},
+		{name: "already reported", requester: buyer, reason: models.ReportScam, addErr: &models.UniqueConstraintError{Err: models.ErrUNIQUEConstraintFailed}, wantErr: models.ErrReportExists},
+		{name: "own post", requester: seller, reason: models.ReportScam, wantErr: models.ErrOwnPost},
+		{name: "unknown reason", requester: buyer, reason: "boring", wantErr: models.ErrInvalidReportReason},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			reports := new(mockReportStorer)
+			posts := new(mockPostProvider)
+			postCache := new(mockPostCache)
+			notifier := new(mockNotifier)
+
+			posts.On("PostByID", mock.Anything, postID).Return(post, nil)
+			reports.On("AddReport", mock.Anything, mock.MatchedBy(func(report *models.Report) bool {
+				return report.PostID == postID && report.ReporterID == "u1" && report.Text == "fake" && report.Status == models.ReportOpen
+			})).Return(tt.openCount, tt.addErr)
+			reports.On("HidePost", mock.Anything, postID).Return(tt.wasVisible, nil)
+			postCache.On("InvalidateLists", mock.Anything).Return(nil)
+			notifier.On("Notify", mock.Anything, "u2", models.NotificationModeration, models.ModerationPayload{Action: "post_hidden", PostID: postID}).Return(nil)
+
+			report, err := newService(reports, posts, postCache, notifier).Report(context.Background(), tt.requester, postID, tt.reason, "  fake ")
+
+			assert.ErrorIs(t, err, tt.wantErr)
+			if tt.wantErr == nil {
+				assert.Equal(t, tt.wantHidden, report.PostHidden)
+			}
+			if tt.wantHidden {
+				reports.AssertCalled(t, "HidePost", mock.Anything, postID)
+			} else {
+				reports.AssertNotCalled(t, "HidePost", mock.Anything, mock.Anything)
+			}
+			if tt.wantNotify {
+				postCache.AssertCalled(t, "InvalidateLists", mock.Anything)
+				notifier.AssertExpectations(t)
+			} else {
+				postCache.AssertNotCalled(t, "InvalidateLists", mock.Anything)
+				notifier.AssertNotCalled(t, "Notify", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestOpenReports_NotModerator(t *testing.T) {
+	t.Parallel()
+
+	reports := new(mockReportStorer)
+
+	// A site moderator role is not enough: the queue follows the allowlist.
+	_, err := newService(reports, new(mockPostProvider), nil, new(mockNotifier)).
+		OpenReports(context.Background(), &models.User{ID: "u3", Login: "other", Role: models.RoleModerator}, 20, 0)
+
+	assert.ErrorIs(t, err, models.ErrForbidden)
+	reports.AssertNotCalled(t, "OpenReports", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestCloseReport(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		dismiss    bool
+		report     *models.Report
+		closeErr   error
+		hidden     bool
+		wantHidden bool
+		wantNotify bool
+		wantErr    error
+	}{
+		{name: "resolve", report: &models.Report{ID: reportID, PostID: postID, PostOwnerID: "u2", Status: models.ReportOpen, PostHidden: true}, hidden: true, wantHidden: true},
+		{name: "dismiss hidden post", dismiss: true, report: &models.Report{ID: reportID, PostID: postID, PostOwnerID: "u2", Status: models.ReportOpen, PostHidden: true}, wantNotify: true},
+		{name: "dismiss resolved post", dismiss: true, report: &models.Report{ID: reportID, PostID: postID, PostOwnerID: "u2", Status: models.ReportOpen, PostHidden: true}, hidden: true, wantHidden: true},
+		{name: "dismiss visible post", dismiss: true, report: &models.Report{ID: reportID, PostID: postID, PostOwnerID: "u2", Status: models.ReportOpen}},
+		{name: "already closed", report: &models.Report{ID: reportID, PostID: postID, Status: models.ReportDismissed}, wantErr: models.ErrReportClosed},
+		{name: "closed concurrently", report: &models.Report{ID: reportID, PostID: postID, Status: models.ReportOpen}, closeErr: models.ErrReportNotFound, wantErr: models.ErrReportClosed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			status := models.ReportResolved
+			if tt.dismiss {
+				status = models.ReportDismissed
+			}
+
+			reports := new(mockReportStorer)
+			postCache := new(mockPostCache)
+			notifier := new(mockNotifier)
+
+			reports.On("ReportByID", mock.Anything, reportID).Return(tt.report, nil)
+			reports.On("CloseReports", mock.Anything, postID, status, "m1", mock.Anything).Return(tt.hidden, tt.closeErr)
+			notifier.On("Notify", mock.Anything, "u2", models.NotificationModeration, models.ModerationPayload{Action: "post_restored", PostID: postID}).Return(nil)
+			postCache.On("InvalidateLists", mock.Anything).Return(nil)
+
+			service := newService(reports, new(mockPostProvider), postCache, notifier)
+
+			var (
+				report *models.Report
+				err    error
+			)
+			if tt.dismiss {
+				report, err = service.Dismiss(context.Background(), moderator, reportID)
+			} else {
+				report, err = service.Resolve(context.Background(), moderator, reportID)
+			}
+
+			assert.ErrorIs(t, err, tt.wantErr)
+			if tt.wantErr == nil {
+				assert.Equal(t, status, report.Status)
+				assert.Equal(t, tt.wantHidden, report.PostHidden)
+				assert.NotNil(t, report.ClosedAt)
+			}
+			// Only a restored post changes the listings.
+			if tt.wantNotify {
+				notifier.AssertExpectations(t)
+				postCache.AssertExpectations(t)
+			} else {
+				notifier.AssertNotCalled(t, "Notify", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+				postCache.AssertNotCalled(t, "InvalidateLists", mock.Anything)
+			}
+		})
+	}
+}
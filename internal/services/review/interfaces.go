@@ -0,0 +1,39 @@
+package reviewservice
+
+import (
+	"context"
+	"marketplace/internal/models"
+	"time"
+)
+
+type ReviewAdder interface {
+	AddReview(ctx context.Context, review *models.Review) error
+}
+
+type ReviewProvider interface {
+	ReviewByID(ctx context.Context, id string) (*models.Review, error)
+	ReviewsBySeller(ctx context.Context, sellerID string, limit int, offset int) ([]*models.Review, error)
+	RatingBySeller(ctx context.Context, sellerID string) (models.Rating, error)
+	PendingReplies(ctx context.Context, limit int, offset int) ([]*models.Review, error)
+}
+
+type ReviewUpdater interface {
+	UpdateReply(ctx context.Context, id string, reply string, repliedAt time.Time) error
+	UpdateReplyStatus(ctx context.Context, id string, status models.ReplyStatus) error
+}
+
+type PostProvider interface {
+	PostByID(ctx context.Context, id string) (*models.PostWithDocument, error)
+}
+
+type OrderProvider interface {
+	HasCompletedOrder(ctx context.Context, postID string, buyerID string) (bool, error)
+}
+
+type UserProvider interface {
+	UserByLogin(ctx context.Context, login string) (*models.User, error)
+}
+
+type Notifier interface {
+	Notify(ctx context.Context, userID string, kind models.NotificationKind, payload models.NotificationPayload) error
+}
@@ -0,0 +1,312 @@
+package reviewservice
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"marketplace/internal/models"
+	"marketplace/internal/utils/validator"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+)
+
+const pkg = "reviewService/"
+
+type ReviewService struct {
+	log            *slog.Logger
+	reviewAdder    ReviewAdder
+	reviewProvider ReviewProvider
+	reviewUpdater  ReviewUpdater
+	postProvider   PostProvider
+	orderProvider  OrderProvider
+	userProvider   UserProvider
+	notifier       Notifier
+	moderators     models.Moderators
+}
+
+func New(
+	log *slog.Logger,
+	reviewAdder ReviewAdder,
+	reviewProvider ReviewProvider,
+	reviewUpdater ReviewUpdater,
+	postProvider PostProvider,
+	orderProvider OrderProvider,
+	userProvider UserProvider,
+	notifier Notifier,
+	moderators models.Moderators,
+) *ReviewService {
+	return &ReviewService{
+		log:            log,
+		reviewAdder:    reviewAdder,
+		reviewProvider: reviewProvider,
+		reviewUpdater:  reviewUpdater,
+		postProvider:   postProvider,
+		orderProvider:  orderProvider,
+		userProvider:   userProvider,
+		notifier:       notifier,
+		moderators:     moderators,
+	}
+}
+
+// AddReview rates the seller of the post. Only a buyer with a completed order
+// for the post can review it, once. Since a post is sold at most once, a
+// seller cannot collect reviews by relisting the same thing.
+func (rs *ReviewService) AddReview(ctx context.Context, requester *models.User, postID string, rating int, text string) (*models.Review, error) {
+	op := pkg + "AddReview"
+
+	log := rs.log.With(slog.String("op", op))
+
+	log.Debug("attempting to add review")
+
+	if err := validator.ValidateReview(rating, text); err != nil {
+		log.Warn("invalid review received", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	if _, err := uuid.FromString(postID); err != nil {
+		log.Warn("invalid post id received", slog.String("post_id", postID))
+		return nil, models.ErrPostNotFound
+	}
+
+	post, err := rs.postProvider.PostByID(ctx, postID)
+	if err != nil {
+		if errors.Is(err, models.ErrPostNotFound) {
+			log.Warn("post not found", slog.String("post_id", postID))
+			return nil, models.ErrPostNotFound
+		}
+		log.Error("failed to get post", slog.String("error", err.Error()))
+		return nil, models.ErrInternal
+	}
+
+	if post.OwnerID == requester.ID {
+		log.Warn("seller tried to review own post", slog.String("post_id", postID))
+		return nil, models.ErrOwnPost
+	}
+
+	purchased, err := rs.orderProvider.HasCompletedOrder(ctx, post.ID, requester.ID)
+	if err != nil {
+		log.Error("failed to check order", slog.String("error", err.Error()))
+		return nil, models.ErrInternal
+	}
+
+	if !purchased {
+		log.Warn("review without completed order", slog.String("post_id", postID))
+		return nil, models.ErrNotPurchased
+	}
+
+	review := &models.Review{
+		ID:          uuid.NewV4().String(),
+		PostID:      post.ID,
+		SellerID:    post.OwnerID,
+		SellerLogin: post.OwnerLogin,
+		AuthorID:    requester.ID,
+		AuthorLogin: requester.Login,
+		Rating:      rating,
+		Text:        text,
+		CreatedAt:   time.Now(),
+	}
+
+	err = rs.reviewAdder.AddReview(ctx, review)
+	if err != nil {
+		var uce *models.UniqueConstraintError
+		if errors.As(err, &uce) {
+			log.Warn("post already reviewed by requester", slog.String("post_id", postID))
+			return nil, models.ErrReviewExists
+		}
+		log.Error("failed to add review", slog.String("error", err.Error()))
+		return nil, models.ErrInternal
+	}
+
+	err = rs.notifier.Notify(ctx, review.SellerID, models.NotificationReview, models.ReviewPayload{
+		ReviewID: review.ID,
+		PostID:   review.PostID,
+		Rating:   review.Rating,
+	})
+	if err != nil {
+		log.Warn("failed to notify about review", slog.String("error", err.Error()))
+	}
+
+	log.Debug("review added successfully", slog.String("review_id", review.ID))
+
+	return review, nil
+}
+
+// Reviews returns the reviews left for the seller together with the
+// aggregate rating.
+func (rs *ReviewService) Reviews(ctx context.Context, login string, limit int, offset int) ([]*models.Review, models.Rating, error) {
+	op := pkg + "Reviews"
+
+	log := rs.log.With(slog.String("op", op))
+
+	log.Debug("attempting to get seller reviews")
+
+	seller, err := rs.userProvider.UserByLogin(ctx, login)
+	if err != nil {
+		if errors.Is(err, models.ErrUserNotFound) {
+			log.Warn("seller not found", slog.String("login", login))
+			return nil, models.Rating{}, models.ErrUserNotFound
+		}
+		log.Error("failed to get seller", slog.String("error", err.Error()))
+		return nil, models.Rating{}, models.ErrInternal
+	}
+
+	reviews, err := rs.reviewProvider.ReviewsBySeller(ctx, seller.ID, limit, offset)
+	if err != nil {
+		log.Error("failed to get reviews", slog.String("error", err.Error()))
+		return nil, models.Rating{}, models.ErrInternal
+	}
+
+	rating, err := rs.reviewProvider.RatingBySeller(ctx, seller.ID)
+	if err != nil {
+		log.Error("failed to get rating", slog.String("error", err.Error()))
+		return nil, models.Rating{}, models.ErrInternal
+	}
+
+	log.Debug("reviews found successfully", slog.Int("count", len(reviews)))
+
+	return reviews, rating, nil
+}
+
+// Reply sets the seller's answer to a review. The reply is hidden from other
+// users until a moderator approves it; editing it sends it to moderation
+// again.
+func (rs *ReviewService) Reply(ctx context.Context, requester *models.User, id string, text string) (*models.Review, error) {
+	op := pkg + "Reply"
+
+	log := rs.log.With(slog.String("op", op))
+
+	log.Debug("attempting to reply to review")
+
+	if err := validator.ValidateReviewReply(text); err != nil {
+		log.Warn("invalid reply received", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	review, err := rs.review(ctx, log, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if review.SellerID != requester.ID {
+		log.Warn("requester is not the reviewed seller", slog.String("review_id", id))
+		return nil, models.ErrForbidden
+	}
+
+	now := time.Now()
+
+	err = rs.reviewUpdater.UpdateReply(ctx, review.ID, text, now)
+	if err != nil {
+		if errors.Is(err, models.ErrReviewNotFound) {
+			log.Warn("review not found", slog.String("review_id", id))
+			return nil, models.ErrReviewNotFound
+		}
+		log.Error("failed to update reply", slog.String("error", err.Error()))
+		return nil, models.ErrInternal
+	}
+
+	review.Reply = text
+	review.ReplyStatus = models.ReplyPending
+	review.RepliedAt = &now
+
+	log.Debug("reply sent to moderation", slog.String("review_id", id))
+
+	return review, nil
+}
+
+// PendingReplies returns the moderation queue of seller replies.
+func (rs *ReviewService) PendingReplies(ctx context.Context, requester *models.User, limit int, offset int) ([]*models.Review, error) {
+	op := pkg + "PendingReplies"
+
+	log := rs.log.With(slog.String("op", op))
+
+	log.Debug("attempting to get pending replies")
+
+	if !rs.isModerator(requester) {
+		log.Warn("requester is not a moderator", slog.String("login", requester.Login))
+		return nil, models.ErrForbidden
+	}
+
+	reviews, err := rs.reviewProvider.PendingReplies(ctx, limit, offset)
+	if err != nil {
+		log.Error("failed to get pending replies", slog.String("error", err.Error()))
+		return nil, models.ErrInternal
+	}
+
+	log.Debug("pending replies found successfully", slog.Int("count", len(reviews)))
+
+	return reviews, nil
+}
+
+func (rs *ReviewService) ApproveReply(ctx context.Context, requester *models.User, id string) (*models.Review, error) {
+	return rs.moderateReply(ctx, pkg+"ApproveReply", requester, id, models.ReplyApproved)
+}
+
+func (rs *ReviewService) RejectReply(ctx context.Context, requester *models.User, id string) (*models.Review, error) {
+	return rs.moderateReply(ctx, pkg+"RejectReply", requester, id, models.ReplyRejected)
+}
+
+func (rs *ReviewService) moderateReply(ctx context.Context, op string, requester *models.User, id string, status models.ReplyStatus) (*models.Review, error) {
+	log := rs.log.With(slog.String("op", op))
+
+	log.Debug("attempting to moderate reply")
+
+	if !rs.isModerator(requester) {
+		log.Warn("requester is not a moderator", slog.String("login", requester.Login))
+		return nil, models.ErrForbidden
+	}
+
+	review, err := rs.review(ctx, log, id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = rs.reviewUpdater.UpdateReplyStatus(ctx, review.ID, status)
+	if err != nil {
+		if errors.Is(err, models.ErrNoPendingReply) {
+			log.Warn("review has no pending reply", slog.String("review_id", id))
+			return nil, models.ErrNoPendingReply
+		}
+		log.Error("failed to update reply status", slog.String("error", err.Error()))
+		return nil, models.ErrInternal
+	}
+
+	review.ReplyStatus = status
+
+	err = rs.notifier.Notify(ctx, review.SellerID, models.NotificationModeration, models.ModerationPayload{
+		Action: "reply_" + string(status),
+		PostID: review.PostID,
+	})
+	if err != nil {
+		log.Warn("failed to notify about moderation", slog.String("error", err.Error()))
+	}
+
+	log.Debug("reply moderated successfully", slog.String("review_id", id), slog.String("status", string(status)))
+
+	return review, nil
+}
+
+func (rs *ReviewService) review(ctx context.Context, log *slog.Logger, id string) (*models.Review, error) {
+	if _, err := uuid.FromString(id); err != nil {
+		log.Warn("invalid review id received", slog.String("review_id", id))
+		return nil, models.ErrReviewNotFound
+	}
+
+	review, err := rs.reviewProvider.ReviewByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, models.ErrReviewNotFound) {
+			log.Warn("review not found", slog.String("review_id", id))
+			return nil, models.ErrReviewNotFound
+		}
+		log.Error("failed to get review", slog.String("error", err.Error()))
+		return nil, models.ErrInternal
+	}
+
+	return review, nil
+}
+
+// isModerator reports whether the requester moderates reviews, by role or by
+// the moderator allowlist.
+func (rs *ReviewService) isModerator(user *models.User) bool {
+	return user.IsModerator() || rs.moderators.Allows(user)
+}
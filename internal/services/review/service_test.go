@@ -0,0 +1,342 @@
+package reviewservice
+
+import (
+	"context"
+	"log/slog"
+	"marketplace/internal/models"
+	"testing"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockReviewAdder struct {
+	mock.Mock
+}
+
+func (m *mockReviewAdder) AddReview(ctx context.Context, review *models.Review) error {
+	args := m.Called(ctx, review)
+	return args.Error(0)
+}
+
+type mockReviewProvider struct {
+	mock.Mock
+}
+
+func (m *mockReviewProvider) ReviewByID(ctx context.Context, id string) (*models.Review, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*models.Review), args.Error(1)
+}
+
+func (m *mockReviewProvider) ReviewsBySeller(ctx context.Context, sellerID string, limit int, offset int) ([]*models.Review, error) {
+	args := m.Called(ctx, sellerID, limit, offset)
+	return args.Get(0).([]*models.Review), args.Error(1)
+}
+
+func (m *mockReviewProvider) RatingBySeller(ctx context.Context, sellerID string) (models.Rating, error) {
+	args := m.Called(ctx, sellerID)
+	return args.Get(0).(models.Rating), args.Error(1)
+}
+
+func (m *mockReviewProvider) PendingReplies(ctx context.Context, limit int, offset int) ([]*models.Review, error) {
+	args := m.Called(ctx, limit, offset)
+	return args.Get(0).([]*models.Review), args.Error(1)
+}
+
+type mockReviewUpdater struct {
+	mock.Mock
+}
+
+func (m *mockReviewUpdater) UpdateReply(ctx context.Context, id string, reply string, repliedAt time.Time) error {
+	args := m.Called(ctx, id, reply, repliedAt)
+	return args.Error(0)
+}
+
+func (m *mockReviewUpdater) UpdateReplyStatus(ctx context.Context, id string, status models.ReplyStatus) error {
+	args := m.Called(ctx, id, status)
+	return args.Error(0)
+}
+
+type mockPostProvider struct {
+	mock.Mock
+}
+
+func (m *mockPostProvider) PostByID(ctx context.Context, id string) (*models.PostWithDocument, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*models.PostWithDocument), args.Error(1)
+}
+
+type mockOrderProvider struct {
+	mock.Mock
+}
+
+func (m *mockOrderProvider) HasCompletedOrder(ctx context.Context, postID string, buyerID string) (bool, error) {
+	args := m.Called(ctx, postID, buyerID)
+	return args.Bool(0), args.Error(1)
+}
+
+type mockUserProvider struct {
+	mock.Mock
+}
+
+func (m *mockUserProvider) UserByLogin(ctx context.Context, login string) (*models.User, error) {
+	args := m.Called(ctx, login)
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+type mockNotifier struct {
+	mock.Mock
+}
+
+func (m *mockNotifier) Notify(ctx context.Context, userID string, kind models.NotificationKind, payload models.NotificationPayload) error {
+	args := m.Called(ctx, userID, kind, payload)
+	return args.Error(0)
+}
+
+func TestAddReview_Success(t *testing.T) {
+	t.Parallel()
+
+	adder := new(mockReviewAdder)
+	posts := new(mockPostProvider)
+	orders := new(mockOrderProvider)
+	notifier := new(mockNotifier)
+
+	service := New(slog.Default(), adder, nil, nil, posts, orders, nil, notifier, nil)
+
+	postID := uuid.NewV4().String()
+
+	posts.On("PostByID", mock.Anything, postID).Return(&models.PostWithDocument{ID: postID, OwnerID: "seller", OwnerLogin: "seller"}, nil)
+	orders.On("HasCompletedOrder", mock.Anything, postID, "buyer").Return(true, nil)
+	adder.On("AddReview", mock.Anything, mock.MatchedBy(func(r *models.Review) bool {
+		return r.PostID == postID && r.SellerID == "seller" && r.AuthorID == "buyer" && r.Rating == 4 && r.ReplyStatus == models.ReplyNone
+	})).Return(nil)
+	notifier.On("Notify", mock.Anything, "seller", models.NotificationReview, mock.AnythingOfType("models.ReviewPayload")).Return(nil)
+
+	review, err := service.AddReview(context.Background(), &models.User{ID: "buyer", Login: "buyer"}, postID, 4, "fast delivery")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "seller", review.SellerLogin)
+	adder.AssertExpectations(t)
+	notifier.AssertExpectations(t)
+}
+
+func TestAddReview_InvalidRating(t *testing.T) {
+	t.Parallel()
+
+	service := New(slog.Default(), nil, nil, nil, nil, nil, nil, nil, nil)
+
+	_, err := service.AddReview(context.Background(), &models.User{ID: "buyer"}, uuid.NewV4().String(), 6, "")
+
+	assert.ErrorIs(t, err, models.ErrInvalidRating)
+}
+
+func TestAddReview_OwnPost(t *testing.T) {
+	t.Parallel()
+
+	posts := new(mockPostProvider)
+
+	service := New(slog.Default(), nil, nil, nil, posts, nil, nil, nil, nil)
+
+	postID := uuid.NewV4().String()
+
+	posts.On("PostByID", mock.Anything, postID).Return(&models.PostWithDocument{ID: postID, OwnerID: "seller"}, nil)
+
+	_, err := service.AddReview(context.Background(), &models.User{ID: "seller"}, postID, 5, "")
+
+	assert.ErrorIs(t, err, models.ErrOwnPost)
+}
+
+func TestAddReview_NotPurchased(t *testing.T) {
+	t.Parallel()
+
+	adder := new(mockReviewAdder)
+	posts := new(mockPostProvider)
+	orders := new(mockOrderProvider)
+
+	service := New(slog.Default(), adder, nil, nil, posts, orders, nil, nil, nil)
+
+	postID := uuid.NewV4().String()
+
+	posts.On("PostByID", mock.Anything, postID).Return(&models.PostWithDocument{ID: postID, OwnerID: "seller"}, nil)
+	orders.On("HasCompletedOrder", mock.Anything, postID, "buyer").Return(false, nil)
+
+	_, err := service.AddReview(context.Background(), &models.User{ID: "buyer"}, postID, 5, "")
+
+	assert.ErrorIs(t, err, models.ErrNotPurchased)
+	adder.AssertNotCalled(t, "AddReview", mock.Anything, mock.Anything)
+}
+
+func TestAddReview_AlreadyReviewed(t *testing.T) {
+	t.Parallel()
+
+	adder := new(mockReviewAdder)
+	posts := new(mockPostProvider)
+	orders := new(mockOrderProvider)
+
+	service := New(slog.Default(), adder, nil, nil, posts, orders, nil, nil, nil)
+
+	postID := uuid.NewV4().String()
+
+	posts.On("PostByID", mock.Anything, postID).Return(&models.PostWithDocument{ID: postID, OwnerID: "seller"}, nil)
+	orders.On("HasCompletedOrder", mock.Anything, postID, "buyer").Return(true, nil)
+	adder.On("AddReview", mock.Anything, mock.Anything).Return(&models.UniqueConstraintError{
+		Constraint: "reviews_post_id_author_id_key",
+		Err:        models.ErrUNIQUEConstraintFailed,
+	})
+
+	_, err := service.AddReview(context.Background(), &models.User{ID: "buyer"}, postID, 5, "")
+
+	assert.ErrorIs(t, err, models.ErrReviewExists)
+}
+
+func TestReviews_Success(t *testing.T) {
+	t.Parallel()
+
+	provider := new(mockReviewProvider)
+	users := new(mockUserProvider)
+
+	service := New(slog.Default(), nil, provider, nil, nil, nil, users, nil, nil)
+
+	users.On("UserByLogin", mock.Anything, "seller").Return(&models.User{ID: "s1", Login: "seller"}, nil)
+	provider.On("ReviewsBySeller", mock.Anything, "s1", 20, 0).Return([]*models.Review{{ID: "r1"}}, nil)
+	provider.On("RatingBySeller", mock.Anything, "s1").Return(models.Rating{Count: 1, Sum: 5}, nil)
+
+	reviews, rating, err := service.Reviews(context.Background(), "seller", 20, 0)
+
+	assert.NoError(t, err)
+	assert.Len(t, reviews, 1)
+	assert.Equal(t, 5.0, rating.Average())
+}
+
+func TestReviews_UnknownSeller(t *testing.T) {
+	t.Parallel()
+
+	users := new(mockUserProvider)
+
+	service := New(slog.Default(), nil, nil, nil, nil, nil, users, nil, nil)
+
+	users.On("UserByLogin", mock.Anything, "ghost").Return((*models.User)(nil), models.ErrUserNotFound)
+
+	_, _, err := service.Reviews(context.Background(), "ghost", 20, 0)
+
+	assert.ErrorIs(t, err, models.ErrUserNotFound)
+}
+
+func TestReply(t *testing.T) {
+	t.Parallel()
+
+	reviewID := uuid.NewV4().String()
+
+	tests := []struct {
+		name      string
+		requester *models.User
+		wantErr   error
+	}{
+		{name: "seller", requester: &models.User{ID: "seller"}, wantErr: nil},
+		{name: "someone else", requester: &models.User{ID: "buyer"}, wantErr: models.ErrForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			provider := new(mockReviewProvider)
+			updater := new(mockReviewUpdater)
+
+			service := New(slog.Default(), nil, provider, updater, nil, nil, nil, nil, nil)
+
+			provider.On("ReviewByID", mock.Anything, reviewID).Return(&models.Review{ID: reviewID, SellerID: "seller", ReplyStatus: models.ReplyApproved}, nil)
+			updater.On("UpdateReply", mock.Anything, reviewID, "thank you", mock.Anything).Return(nil)
+
+			review, err := service.Reply(context.Background(), tt.requester, reviewID, "thank you")
+
+			assert.ErrorIs(t, err, tt.wantErr)
+			if tt.wantErr == nil {
+				assert.Equal(t, models.ReplyPending, review.ReplyStatus)
+				assert.Empty(t, review.PublicReply())
+				updater.AssertExpectations(t)
+			} else {
+				updater.AssertNotCalled(t, "UpdateReply")
+			}
+		})
+	}
+}
+
+func TestApproveReply_Success(t *testing.T) {
+	t.Parallel()
+
+	provider := new(mockReviewProvider)
+	updater := new(mockReviewUpdater)
+	notifier := new(mockNotifier)
+
+	service := New(slog.Default(), nil, provider, updater, nil, nil, nil, notifier, models.NewModerators([]string{"moderator"}))
+
+	reviewID := uuid.NewV4().String()
+
+	provider.On("ReviewByID", mock.Anything, reviewID).Return(&models.Review{ID: reviewID, SellerID: "seller", Reply: "thanks", ReplyStatus: models.ReplyPending}, nil)
+	updater.On("UpdateReplyStatus", mock.Anything, reviewID, models.ReplyApproved).Return(nil)
+	notifier.On("Notify", mock.Anything, "seller", models.NotificationModeration, models.ModerationPayload{Action: "reply_approved"}).Return(nil)
+
+	review, err := service.ApproveReply(context.Background(), &models.User{ID: "m1", Login: "moderator"}, reviewID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "thanks", review.PublicReply())
+	notifier.AssertExpectations(t)
+}
+
+func TestRejectReply_NotModerator(t *testing.T) {
+	t.Parallel()
+
+	service := New(slog.Default(), nil, nil, nil, nil, nil, nil, nil, models.NewModerators([]string{"moderator"}))
+
+	_, err := service.RejectReply(context.Background(), &models.User{ID: "seller", Login: "seller"}, uuid.NewV4().String())
+
+	assert.ErrorIs(t, err, models.ErrForbidden)
+}
+
+func TestRejectReply_AlreadyModerated(t *testing.T) {
+	t.Parallel()
+
+	provider := new(mockReviewProvider)
+	updater := new(mockReviewUpdater)
+
+	service := New(slog.Default(), nil, provider, updater, nil, nil, nil, nil, models.NewModerators([]string{"moderator"}))
+
+	reviewID := uuid.NewV4().String()
+
+	provider.On("ReviewByID", mock.Anything, reviewID).Return(&models.Review{ID: reviewID, ReplyStatus: models.ReplyApproved}, nil)
+	updater.On("UpdateReplyStatus", mock.Anything, reviewID, models.ReplyRejected).Return(models.ErrNoPendingReply)
+
+	_, err := service.RejectReply(context.Background(), &models.User{Login: "moderator"}, reviewID)
+
+	assert.ErrorIs(t, err, models.ErrNoPendingReply)
+}
+
+func TestPendingReplies_NotModerator(t *testing.T) {
+	t.Parallel()
+
+	service := New(slog.Default(), nil, nil, nil, nil, nil, nil, nil, nil)
+
+	_, err := service.PendingReplies(context.Background(), &models.User{Login: "seller"}, 20, 0)
+
+	assert.ErrorIs(t, err, models.ErrForbidden)
+}
+
+func TestPendingReplies_ModeratorRole(t *testing.T) {
+	t.Parallel()
+
+	reviewProvider := new(mockReviewProvider)
+
+	service := New(slog.Default(), nil, reviewProvider, nil, nil, nil, nil, nil, nil)
+
+	reviews := []*models.Review{{ID: uuid.NewV4().String(), ReplyStatus: models.ReplyPending}}
+
+	reviewProvider.On("PendingReplies", mock.Anything, 20, 0).Return(reviews, nil)
+
+	got, err := service.PendingReplies(context.Background(), &models.User{Login: "mod", Role: models.RoleModerator}, 20, 0)
+
+	assert.NoError(t, err)
+	assert.Equal(t, reviews, got)
+}
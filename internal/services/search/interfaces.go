@@ -0,0 +1,35 @@
+package searchservice
+
+import (
+	"context"
+	"marketplace/internal/models"
+	"time"
+)
+
+type SearchAdder interface {
+	AddSearch(ctx context.Context, search *models.SavedSearch) error
+}
+
+type SearchProvider interface {
+	SearchByID(ctx context.Context, id string) (*models.SavedSearch, error)
+	SearchesByUser(ctx context.Context, userID string) ([]*models.SavedSearch, error)
+	SearchesMatchingPost(ctx context.Context, post *models.PostWithDocument) ([]*models.SavedSearch, error)
+}
+
+type SearchUpdater interface {
+	UpdateSearch(ctx context.Context, search *models.SavedSearch) error
+}
+
+type SearchRemover interface {
+	DeleteSearch(ctx context.Context, id string) error
+}
+
+type MatchStorer interface {
+	AddMatches(ctx context.Context, postID string, searchIDs []string, matchedAt time.Time) error
+	UnseenMatches(ctx context.Context, searchID string, limit int) ([]*models.SearchMatch, error)
+	MarkMatchesSeen(ctx context.Context, searchID string, postIDs []string, seenAt time.Time) error
+}
+
+type Notifier interface {
+	Notify(ctx context.Context, userID string, kind models.NotificationKind, payload models.NotificationPayload) error
+}
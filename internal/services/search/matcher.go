@@ -0,0 +1,101 @@
+package searchservice
+
+import (
+	"context"
+	"log/slog"
+	"marketplace/internal/models"
+	"time"
+)
+
+// Matcher evaluates newly added posts against saved searches in the
+// background, so that post creation is not slowed down by matching.
+type Matcher struct {
+	log            *slog.Logger
+	searchProvider SearchProvider
+	matchStorer    MatchStorer
+	notifier       Notifier
+	queue          chan *models.PostWithDocument
+}
+
+func NewMatcher(
+	log *slog.Logger,
+	searchProvider SearchProvider,
+	matchStorer MatchStorer,
+	notifier Notifier,
+	queueSize int,
+) *Matcher {
+	return &Matcher{
+		log:            log,
+		searchProvider: searchProvider,
+		matchStorer:    matchStorer,
+		notifier:       notifier,
+		queue:          make(chan *models.PostWithDocument, queueSize),
+	}
+}
+
+// PostAdded enqueues the post for matching. It never blocks: when the queue is
+// full the post is dropped and a warning is logged.
+func (m *Matcher) PostAdded(_ context.Context, post *models.PostWithDocument) {
+	select {
+	case m.queue <- post:
+	default:
+		m.log.Warn("matcher queue is full, post skipped", slog.String("op", pkg+"PostAdded"), slog.String("post_id", post.ID))
+	}
+}
+
+// Run processes queued posts until ctx is cancelled.
+func (m *Matcher) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case post := <-m.queue:
+			m.match(ctx, post)
+		}
+	}
+}
+
+func (m *Matcher) match(ctx context.Context, post *models.PostWithDocument) {
+	op := pkg + "match"
+
+	log := m.log.With(slog.String("op", op), slog.String("post_id", post.ID))
+
+	log.Debug("attempting to match post against saved searches")
+
+	searches, err := m.searchProvider.SearchesMatchingPost(ctx, post)
+	if err != nil {
+		log.Error("failed to get matching searches", slog.String("error", err.Error()))
+		return
+	}
+
+	matched := make([]*models.SavedSearch, 0, len(searches))
+	searchIDs := make([]string, 0, len(searches))
+	for _, search := range searches {
+		if search.Filter.Matches(post) {
+			matched = append(matched, search)
+			searchIDs = append(searchIDs, search.ID)
+		}
+	}
+
+	if len(searchIDs) == 0 {
+		log.Debug("no saved searches matched")
+		return
+	}
+
+	err = m.matchStorer.AddMatches(ctx, post.ID, searchIDs, time.Now())
+	if err != nil {
+		log.Error("failed to store matches", slog.String("error", err.Error()))
+		return
+	}
+
+	for _, search := range matched {
+		payload := models.SearchMatchPayload{SearchID: search.ID, PostID: post.ID}
+
+		err = m.notifier.Notify(ctx, search.UserID, payload.Kind(), payload)
+		if err != nil {
+			log.Error("failed to notify search owner", slog.String("search_id", search.ID), slog.String("error", err.Error()))
+		}
+	}
+
+	log.Debug("post matched successfully", slog.Int("count", len(searchIDs)))
+}
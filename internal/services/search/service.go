@@ -0,0 +1,266 @@
+package searchservice
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"marketplace/internal/models"
+	"marketplace/internal/utils/validator"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+)
+
+const pkg = "searchService/"
+
+type SearchService struct {
+	log            *slog.Logger
+	searchAdder    SearchAdder
+	searchProvider SearchProvider
+	searchUpdater  SearchUpdater
+	searchRemover  SearchRemover
+	matchStorer    MatchStorer
+}
+
+func New(
+	log *slog.Logger,
+	searchAdder SearchAdder,
+	searchProvider SearchProvider,
+	searchUpdater SearchUpdater,
+	searchRemover SearchRemover,
+	matchStorer MatchStorer,
+) *SearchService {
+	return &SearchService{
+		log:            log,
+		searchAdder:    searchAdder,
+		searchProvider: searchProvider,
+		searchUpdater:  searchUpdater,
+		searchRemover:  searchRemover,
+		matchStorer:    matchStorer,
+	}
+}
+
+func (ss *SearchService) AddSearch(ctx context.Context, requester *models.User, filter *models.PostsFilter) (*models.SavedSearch, error) {
+	op := pkg + "AddSearch"
+
+	log := ss.log.With(slog.String("op", op))
+
+	log.Debug("attempting to add saved search")
+
+	if err := validator.ValidatePostsFilter(filter); err != nil {
+		log.Warn("invalid filter received", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	search := &models.SavedSearch{
+		ID:        uuid.NewV4().String(),
+		UserID:    requester.ID,
+		Filter:    *filter,
+		CreatedAt: time.Now(),
+	}
+
+	err := ss.searchAdder.AddSearch(ctx, search)
+	if err != nil {
+		log.Error("failed to add saved search", slog.String("error", err.Error()))
+		return nil, models.ErrInternal
+	}
+
+	log.Debug("saved search added successfully", slog.String("search_id", search.ID))
+
+	return search, nil
+}
+
+func (ss *SearchService) Searches(ctx context.Context, requester *models.User) ([]*models.SavedSearch, error) {
+	op := pkg + "Searches"
+
+	log := ss.log.With(slog.String("op", op))
+
+	log.Debug("attempting to get saved searches")
+
+	searches, err := ss.searchProvider.SearchesByUser(ctx, requester.ID)
+	if err != nil {
+		log.Error("failed to get saved searches", slog.String("error", err.Error()))
+		return nil, models.ErrInternal
+	}
+
+	log.Debug("saved searches found successfully", slog.Int("count", len(searches)))
+
+	return searches, nil
+}
+
+func (ss *SearchService) Search(ctx context.Context, requester *models.User, id string) (*models.SavedSearch, error) {
+	op := pkg + "Search"
+
+	log := ss.log.With(slog.String("op", op))
+
+	log.Debug("attempting to get saved search")
+
+	search, err := ss.ownSearch(ctx, requester, id)
+	if err != nil {
+		if errors.Is(err, models.ErrSearchNotFound) {
+			log.Warn("saved search not found", slog.String("search_id", id))
+			return nil, models.ErrSearchNotFound
+		}
+
+		log.Error("failed to get saved search", slog.String("error", err.Error()))
+		return nil, models.ErrInternal
+	}
+
+	log.Debug("saved search found successfully")
+
+	return search, nil
+}
+
+func (ss *SearchService) UpdateSearch(ctx context.Context, requester *models.User, id string, filter *models.PostsFilter) (*models.SavedSearch, error) {
+	op := pkg + "UpdateSearch"
+
+	log := ss.log.With(slog.String("op", op))
+
+	log.Debug("attempting to update saved search")
+
+	if err := validator.ValidatePostsFilter(filter); err != nil {
+		log.Warn("invalid filter received", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	search, err := ss.ownSearch(ctx, requester, id)
+	if err != nil {
+		if errors.Is(err, models.ErrSearchNotFound) {
+			log.Warn("saved search not found", slog.String("search_id", id))
+			return nil, models.ErrSearchNotFound
+		}
+
+		log.Error("failed to get saved search", slog.String("error", err.Error()))
+		return nil, models.ErrInternal
+	}
+
+	search.Filter = *filter
+
+	err = ss.searchUpdater.UpdateSearch(ctx, search)
+	if err != nil {
+		if errors.Is(err, models.ErrSearchNotFound) {
+			log.Warn("saved search not found", slog.String("search_id", id))
+			return nil, models.ErrSearchNotFound
+		}
+
+		log.Error("failed to update saved search", slog.String("error", err.Error()))
+		return nil, models.ErrInternal
+	}
+
+	log.Debug("saved search updated successfully")
+
+	return search, nil
+}
+
+func (ss *SearchService) DeleteSearch(ctx context.Context, requester *models.User, id string) error {
+	op := pkg + "DeleteSearch"
+
+	log := ss.log.With(slog.String("op", op))
+
+	log.Debug("attempting to delete saved search")
+
+	_, err := ss.ownSearch(ctx, requester, id)
+	if err != nil {
+		if errors.Is(err, models.ErrSearchNotFound) {
+			log.Warn("saved search not found", slog.String("search_id", id))
+			return models.ErrSearchNotFound
+		}
+
+		log.Error("failed to get saved search", slog.String("error", err.Error()))
+		return models.ErrInternal
+	}
+
+	err = ss.searchRemover.DeleteSearch(ctx, id)
+	if err != nil {
+		log.Error("failed to delete saved search", slog.String("error", err.Error()))
+		return models.ErrInternal
+	}
+
+	log.Debug("saved search deleted successfully")
+
+	return nil
+}
+
+func (ss *SearchService) Matches(ctx context.Context, requester *models.User, id string, limit int) ([]*models.SearchMatch, error) {
+	op := pkg + "Matches"
+
+	log := ss.log.With(slog.String("op", op))
+
+	log.Debug("attempting to get unseen matches")
+
+	_, err := ss.ownSearch(ctx, requester, id)
+	if err != nil {
+		if errors.Is(err, models.ErrSearchNotFound) {
+			log.Warn("saved search not found", slog.String("search_id", id))
+			return nil, models.ErrSearchNotFound
+		}
+
+		log.Error("failed to get saved search", slog.String("error", err.Error()))
+		return nil, models.ErrInternal
+	}
+
+	matches, err := ss.matchStorer.UnseenMatches(ctx, id, limit)
+	if err != nil {
+		log.Error("failed to get unseen matches", slog.String("error", err.Error()))
+		return nil, models.ErrInternal
+	}
+
+	log.Debug("unseen matches found successfully", slog.Int("count", len(matches)))
+
+	return matches, nil
+}
+
+func (ss *SearchService) MarkSeen(ctx context.Context, requester *models.User, id string, postIDs []string) error {
+	op := pkg + "MarkSeen"
+
+	log := ss.log.With(slog.String("op", op))
+
+	log.Debug("attempting to mark matches as seen")
+
+	for _, postID := range postIDs {
+		if _, err := uuid.FromString(postID); err != nil {
+			log.Warn("invalid post id received", slog.String("post_id", postID))
+			return models.ErrInvalidParams
+		}
+	}
+
+	_, err := ss.ownSearch(ctx, requester, id)
+	if err != nil {
+		if errors.Is(err, models.ErrSearchNotFound) {
+			log.Warn("saved search not found", slog.String("search_id", id))
+			return models.ErrSearchNotFound
+		}
+
+		log.Error("failed to get saved search", slog.String("error", err.Error()))
+		return models.ErrInternal
+	}
+
+	err = ss.matchStorer.MarkMatchesSeen(ctx, id, postIDs, time.Now())
+	if err != nil {
+		log.Error("failed to mark matches as seen", slog.String("error", err.Error()))
+		return models.ErrInternal
+	}
+
+	log.Debug("matches marked as seen successfully")
+
+	return nil
+}
+
+// ownSearch returns the saved search only if it belongs to the requester, so
+// that foreign searches are indistinguishable from missing ones.
+func (ss *SearchService) ownSearch(ctx context.Context, requester *models.User, id string) (*models.SavedSearch, error) {
+	if _, err := uuid.FromString(id); err != nil {
+		return nil, models.ErrSearchNotFound
+	}
+
+	search, err := ss.searchProvider.SearchByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if search.UserID != requester.ID {
+		return nil, models.ErrSearchNotFound
+	}
+
+	return search, nil
+}
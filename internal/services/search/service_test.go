@@ -0,0 +1,411 @@
+package searchservice
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"marketplace/internal/models"
+	"reflect"
+	"testing"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockSearchAdder struct {
+	mock.Mock
+}
+
+func (m *mockSearchAdder) AddSearch(ctx context.Context, search *models.SavedSearch) error {
+	args := m.Called(ctx, search)
+	return args.Error(0)
+}
+
+type mockSearchProvider struct {
+	mock.Mock
+}
+
+func (m *mockSearchProvider) SearchByID(ctx context.Context, id string) (*models.SavedSearch, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*models.SavedSearch), args.Error(1)
+}
+
+func (m *mockSearchProvider) SearchesByUser(ctx context.Context, userID string) ([]*models.SavedSearch, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]*models.SavedSearch), args.Error(1)
+}
+
+func (m *mockSearchProvider) SearchesMatchingPost(ctx context.Context, post *models.PostWithDocument) ([]*models.SavedSearch, error) {
+	args := m.Called(ctx, post)
+	return args.Get(0).([]*models.SavedSearch), args.Error(1)
+}
+
+type mockSearchUpdater struct {
+	mock.Mock
+}
+
+func (m *mockSearchUpdater) UpdateSearch(ctx context.Context, search *models.SavedSearch) error {
+	args := m.Called(ctx, search)
+	return args.Error(0)
+}
+
+type mockSearchRemover struct {
+	mock.Mock
+}
+
+func (m *mockSearchRemover) DeleteSearch(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+type mockMatchStorer struct {
+	mock.Mock
+}
+
+func (m *mockMatchStorer) AddMatches(ctx context.Context, postID string, searchIDs []string, matchedAt time.Time) error {
+	args := m.Called(ctx, postID, searchIDs, matchedAt)
+	return args.Error(0)
+}
+
+func (m *mockMatchStorer) UnseenMatches(ctx context.Context, searchID string, limit int) ([]*models.SearchMatch, error) {
+	args := m.Called(ctx, searchID, limit)
+	return args.Get(0).([]*models.SearchMatch), args.Error(1)
+}
+
+func (m *mockMatchStorer) MarkMatchesSeen(ctx context.Context, searchID string, postIDs []string, seenAt time.Time) error {
+	args := m.Called(ctx, searchID, postIDs, seenAt)
+	return args.Error(0)
+}
+
+type mockNotifier struct {
+	mock.Mock
+}
+
+func (m *mockNotifier) Notify(ctx context.Context, userID string, kind models.NotificationKind, payload models.NotificationPayload) error {
+	args := m.Called(ctx, userID, kind, payload)
+	return args.Error(0)
+}
+
+func TestAddSearch_Success(t *testing.T) {
+	t.Parallel()
+
+	mockSearchAdder := new(mockSearchAdder)
+
+	service := New(slog.Default(), mockSearchAdder, nil, nil, nil, nil)
+
+	requester := &models.User{ID: "1", Login: "user1"}
+
+	filter := &models.PostsFilter{MinPrice: 100, MaxPrice: 200, SortBy: "price", SortOrder: "asc"}
+
+	mockSearchAdder.On("AddSearch", mock.Anything, mock.MatchedBy(func(s *models.SavedSearch) bool {
+		return s.UserID == requester.ID && reflect.DeepEqual(s.Filter, *filter) && s.ID != ""
+	})).Return(nil)
+
+	search, err := service.AddSearch(context.Background(), requester, filter)
+
+	assert.NoError(t, err)
+	assert.Equal(t, *filter, search.Filter)
+	assert.NotEmpty(t, search.CreatedAt)
+
+	mockSearchAdder.AssertExpectations(t)
+}
+
+func TestAddSearch_InvalidFilter(t *testing.T) {
+	t.Parallel()
+
+	mockSearchAdder := new(mockSearchAdder)
+
+	service := New(slog.Default(), mockSearchAdder, nil, nil, nil, nil)
+
+	requester := &models.User{ID: "1", Login: "user1"}
+
+	filter := &models.PostsFilter{MinPrice: 300, MaxPrice: 200}
+
+	search, err := service.AddSearch(context.Background(), requester, filter)
+
+	assert.ErrorIs(t, err, models.ErrInvalidFilter)
+	assert.Empty(t, search)
+
+	mockSearchAdder.AssertExpectations(t)
+}
+
+func TestAddSearch_OtherErr(t *testing.T) {
+	t.Parallel()
+
+	mockSearchAdder := new(mockSearchAdder)
+
+	service := New(slog.Default(), mockSearchAdder, nil, nil, nil, nil)
+
+	requester := &models.User{ID: "1", Login: "user1"}
+
+	mockSearchAdder.On("AddSearch", mock.Anything, mock.Anything).Return(errors.New("some error"))
+
+	search, err := service.AddSearch(context.Background(), requester, &models.PostsFilter{})
+
+	assert.ErrorIs(t, err, models.ErrInternal)
+	assert.Empty(t, search)
+
+	mockSearchAdder.AssertExpectations(t)
+}
+
+func TestSearches_Success(t *testing.T) {
+	t.Parallel()
+
+	mockSearchProvider := new(mockSearchProvider)
+
+	service := New(slog.Default(), nil, mockSearchProvider, nil, nil, nil)
+
+	requester := &models.User{ID: "1", Login: "user1"}
+
+	expSearches := []*models.SavedSearch{
+		{ID: "s1", UserID: "1"},
+		{ID: "s2", UserID: "1"},
+	}
+
+	mockSearchProvider.On("SearchesByUser", mock.Anything, requester.ID).Return(expSearches, nil)
+
+	searches, err := service.Searches(context.Background(), requester)
+
+	assert.NoError(t, err)
+	assert.Equal(t, expSearches, searches)
+
+	mockSearchProvider.AssertExpectations(t)
+}
+
+func TestSearch_ForeignSearchNotFound(t *testing.T) {
+	t.Parallel()
+
+	mockSearchProvider := new(mockSearchProvider)
+
+	service := New(slog.Default(), nil, mockSearchProvider, nil, nil, nil)
+
+	requester := &models.User{ID: "1", Login: "user1"}
+
+	id := uuid.NewV4().String()
+
+	mockSearchProvider.On("SearchByID", mock.Anything, id).Return(&models.SavedSearch{ID: id, UserID: "2"}, nil)
+
+	search, err := service.Search(context.Background(), requester, id)
+
+	assert.ErrorIs(t, err, models.ErrSearchNotFound)
+	assert.Empty(t, search)
+
+	mockSearchProvider.AssertExpectations(t)
+}
+
+func TestSearch_InvalidID(t *testing.T) {
+	t.Parallel()
+
+	mockSearchProvider := new(mockSearchProvider)
+
+	service := New(slog.Default(), nil, mockSearchProvider, nil, nil, nil)
+
+	requester := &models.User{ID: "1", Login: "user1"}
+
+	search, err := service.Search(context.Background(), requester, "not-uuid")
+
+	assert.ErrorIs(t, err, models.ErrSearchNotFound)
+	assert.Empty(t, search)
+
+	mockSearchProvider.AssertExpectations(t)
+}
+
+func TestUpdateSearch_Success(t *testing.T) {
+	t.Parallel()
+
+	mockSearchProvider := new(mockSearchProvider)
+	mockSearchUpdater := new(mockSearchUpdater)
+
+	service := New(slog.Default(), nil, mockSearchProvider, mockSearchUpdater, nil, nil)
+
+	requester := &models.User{ID: "1", Login: "user1"}
+
+	id := uuid.NewV4().String()
+
+	filter := &models.PostsFilter{MaxPrice: 500}
+
+	mockSearchProvider.On("SearchByID", mock.Anything, id).Return(&models.SavedSearch{ID: id, UserID: "1"}, nil)
+	mockSearchUpdater.On("UpdateSearch", mock.Anything, &models.SavedSearch{ID: id, UserID: "1", Filter: *filter}).Return(nil)
+
+	search, err := service.UpdateSearch(context.Background(), requester, id, filter)
+
+	assert.NoError(t, err)
+	assert.Equal(t, *filter, search.Filter)
+
+	mockSearchProvider.AssertExpectations(t)
+	mockSearchUpdater.AssertExpectations(t)
+}
+
+func TestDeleteSearch_Success(t *testing.T) {
+	t.Parallel()
+
+	mockSearchProvider := new(mockSearchProvider)
+	mockSearchRemover := new(mockSearchRemover)
+
+	service := New(slog.Default(), nil, mockSearchProvider, nil, mockSearchRemover, nil)
+
+	requester := &models.User{ID: "1", Login: "user1"}
+
+	id := uuid.NewV4().String()
+
+	mockSearchProvider.On("SearchByID", mock.Anything, id).Return(&models.SavedSearch{ID: id, UserID: "1"}, nil)
+	mockSearchRemover.On("DeleteSearch", mock.Anything, id).Return(nil)
+
+	err := service.DeleteSearch(context.Background(), requester, id)
+
+	assert.NoError(t, err)
+
+	mockSearchProvider.AssertExpectations(t)
+	mockSearchRemover.AssertExpectations(t)
+}
+
+func TestDeleteSearch_NotFound(t *testing.T) {
+	t.Parallel()
+
+	mockSearchProvider := new(mockSearchProvider)
+	mockSearchRemover := new(mockSearchRemover)
+
+	service := New(slog.Default(), nil, mockSearchProvider, nil, mockSearchRemover, nil)
+
+	requester := &models.User{ID: "1", Login: "user1"}
+
+	id := uuid.NewV4().String()
+
+	mockSearchProvider.On("SearchByID", mock.Anything, id).Return((*models.SavedSearch)(nil), models.ErrSearchNotFound)
+
+	err := service.DeleteSearch(context.Background(), requester, id)
+
+	assert.ErrorIs(t, err, models.ErrSearchNotFound)
+
+	mockSearchProvider.AssertExpectations(t)
+	mockSearchRemover.AssertExpectations(t)
+}
+
+func TestMatches_Success(t *testing.T) {
+	t.Parallel()
+
+	mockSearchProvider := new(mockSearchProvider)
+	mockMatchStorer := new(mockMatchStorer)
+
+	service := New(slog.Default(), nil, mockSearchProvider, nil, nil, mockMatchStorer)
+
+	requester := &models.User{ID: "1", Login: "user1"}
+
+	id := uuid.NewV4().String()
+
+	expMatches := []*models.SearchMatch{
+		{SearchID: id, Post: &models.PostWithDocument{ID: "p1"}},
+	}
+
+	mockSearchProvider.On("SearchByID", mock.Anything, id).Return(&models.SavedSearch{ID: id, UserID: "1"}, nil)
+	mockMatchStorer.On("UnseenMatches", mock.Anything, id, 10).Return(expMatches, nil)
+
+	matches, err := service.Matches(context.Background(), requester, id, 10)
+
+	assert.NoError(t, err)
+	assert.Equal(t, expMatches, matches)
+
+	mockSearchProvider.AssertExpectations(t)
+	mockMatchStorer.AssertExpectations(t)
+}
+
+func TestMarkSeen_Success(t *testing.T) {
+	t.Parallel()
+
+	mockSearchProvider := new(mockSearchProvider)
+	mockMatchStorer := new(mockMatchStorer)
+
+	service := New(slog.Default(), nil, mockSearchProvider, nil, nil, mockMatchStorer)
+
+	requester := &models.User{ID: "1", Login: "user1"}
+
+	id := uuid.NewV4().String()
+
+	postIDs := []string{uuid.NewV4().String()}
+
+	mockSearchProvider.On("SearchByID", mock.Anything, id).Return(&models.SavedSearch{ID: id, UserID: "1"}, nil)
+	mockMatchStorer.On("MarkMatchesSeen", mock.Anything, id, postIDs, mock.AnythingOfType("time.Time")).Return(nil)
+
+	err := service.MarkSeen(context.Background(), requester, id, postIDs)
+
+	assert.NoError(t, err)
+
+	mockSearchProvider.AssertExpectations(t)
+	mockMatchStorer.AssertExpectations(t)
+}
+
+func TestMarkSeen_InvalidPostID(t *testing.T) {
+	t.Parallel()
+
+	mockSearchProvider := new(mockSearchProvider)
+	mockMatchStorer := new(mockMatchStorer)
+
+	service := New(slog.Default(), nil, mockSearchProvider, nil, nil, mockMatchStorer)
+
+	requester := &models.User{ID: "1", Login: "user1"}
+
+	err := service.MarkSeen(context.Background(), requester, uuid.NewV4().String(), []string{"bad"})
+
+	assert.ErrorIs(t, err, models.ErrInvalidParams)
+
+	mockSearchProvider.AssertExpectations(t)
+	mockMatchStorer.AssertExpectations(t)
+}
+
+func TestMatcher_StoresMatches(t *testing.T) {
+	t.Parallel()
+
+	mockSearchProvider := new(mockSearchProvider)
+	mockMatchStorer := new(mockMatchStorer)
+	mockNotifier := new(mockNotifier)
+
+	matcher := NewMatcher(slog.Default(), mockSearchProvider, mockMatchStorer, mockNotifier, 1)
+
+	post := &models.PostWithDocument{ID: "p1", OwnerID: "2", Price: 150}
+
+	searches := []*models.SavedSearch{
+		{ID: "s1", UserID: "1", Filter: models.PostsFilter{MinPrice: 100, MaxPrice: 200}},
+		{ID: "s2", Filter: models.PostsFilter{MinPrice: 160}},
+	}
+
+	done := make(chan struct{})
+
+	mockSearchProvider.On("SearchesMatchingPost", mock.Anything, post).Return(searches, nil)
+	mockMatchStorer.On("AddMatches", mock.Anything, post.ID, []string{"s1"}, mock.AnythingOfType("time.Time")).
+		Return(nil)
+	mockNotifier.On("Notify", mock.Anything, "1", models.NotificationSearchMatch, models.SearchMatchPayload{SearchID: "s1", PostID: post.ID}).
+		Return(nil).
+		Run(func(args mock.Arguments) { close(done) })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go matcher.Run(ctx)
+
+	matcher.PostAdded(context.Background(), post)
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("matcher did not store matches")
+	}
+
+	mockSearchProvider.AssertExpectations(t)
+	mockMatchStorer.AssertExpectations(t)
+	mockNotifier.AssertExpectations(t)
+}
+
+func TestMatcher_QueueFullDoesNotBlock(t *testing.T) {
+	t.Parallel()
+
+	matcher := NewMatcher(slog.Default(), nil, nil, nil, 1)
+
+	post := &models.PostWithDocument{ID: "p1"}
+
+	matcher.PostAdded(context.Background(), post)
+	matcher.PostAdded(context.Background(), post)
+
+	assert.Len(t, matcher.queue, 1)
+}
@@ -0,0 +1,28 @@
+package twofactorservice
+
+import (
+	"context"
+	"marketplace/internal/models"
+	"time"
+)
+
+type TOTPStorer interface {
+	SaveTOTP(ctx context.Context, totp *models.TOTP) error
+	TOTPByUser(ctx context.Context, userID string) (*models.TOTP, error)
+	EnableTOTP(ctx context.Context, userID string, step int64, enabledAt time.Time, codeHashes []string) error
+	DeleteTOTP(ctx context.Context, userID string) error
+	UseTOTPStep(ctx context.Context, userID string, step int64) error
+	UseRecoveryCode(ctx context.Context, userID string, codeHash string, usedAt time.Time) error
+}
+
+type SecretCipher interface {
+	Encrypt(plaintext []byte) (string, error)
+	Decrypt(ciphertext string) ([]byte, error)
+}
+
+// LoginLimiter counts wrong codes together with failed logins.
+type LoginLimiter interface {
+	Check(ctx context.Context, login string, ip string) error
+	Fail(ctx context.Context, login string, ip string) error
+	Succeed(ctx context.Context, login string) error
+}
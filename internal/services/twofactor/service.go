@@ -0,0 +1,354 @@
+package twofactorservice
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"log/slog"
+	"marketplace/internal/models"
+	"marketplace/internal/utils/token"
+	"marketplace/internal/utils/totp"
+	"strings"
+	"time"
+)
+
+const pkg = "twoFactorService/"
+
+// recoveryCodeSize is the number of base32 characters in a recovery code.
+const recoveryCodeSize = 10
+
+type TwoFactorService struct {
+	log           *slog.Logger
+	totps         TOTPStorer
+	cipher        SecretCipher
+	limiter       LoginLimiter
+	issuer        string
+	skew          int
+	recoveryCodes int
+}
+
+func New(
+	log *slog.Logger,
+	totps TOTPStorer,
+	cipher SecretCipher,
+	limiter LoginLimiter,
+	issuer string,
+	skew int,
+	recoveryCodes int,
+) *TwoFactorService {
+	return &TwoFactorService{
+		log:           log,
+		totps:         totps,
+		cipher:        cipher,
+		limiter:       limiter,
+		issuer:        issuer,
+		skew:          skew,
+		recoveryCodes: recoveryCodes,
+	}
+}
+
+// Enroll generates a TOTP secret for the requester. It stays pending, and
+// logins do not ask for codes, until Confirm is called with a code from the
+// app. Enrolling again replaces a pending secret.
+func (s *TwoFactorService) Enroll(ctx context.Context, requester *models.User) (*models.TOTPEnrollment, error) {
+	op := pkg + "Enroll"
+
+	log := s.log.With(slog.String("op", op), slog.String("user_id", requester.ID))
+
+	log.Debug("attempting to enroll totp")
+
+	secret, err := totp.NewSecret()
+	if err != nil {
+		log.Error("failed to generate secret", slog.String("error", err.Error()))
+		return nil, models.ErrInternal
+	}
+
+	sealed, err := s.cipher.Encrypt([]byte(secret))
+	if err != nil {
+		log.Error("failed to encrypt secret", slog.String("error", err.Error()))
+		return nil, models.ErrInternal
+	}
+
+	err = s.totps.SaveTOTP(ctx, &models.TOTP{
+		UserID:    requester.ID,
+		Secret:    sealed,
+		CreatedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		if errors.Is(err, models.ErrTwoFactorEnabled) {
+			log.Warn("two-factor authentication already enabled")
+			return nil, models.ErrTwoFactorEnabled
+		}
+		log.Error("failed to save totp", slog.String("error", err.Error()))
+		return nil, models.ErrInternal
+	}
+
+	log.Debug("totp enrolled successfully")
+
+	return &models.TOTPEnrollment{
+		Secret: secret,
+		URI:    totp.URI(s.issuer, requester.Login, secret),
+	}, nil
+}
+
+// Confirm enables two-factor authentication once the requester proves the app
+// has the secret. It returns the recovery codes, which are shown only this
+// once. Wrong codes count as failed logins, as in Disable.
+func (s *TwoFactorService) Confirm(ctx context.Context, requester *models.User, code string, client *models.ClientInfo) ([]string, error) {
+	op := pkg + "Confirm"
+
+	log := s.log.With(slog.String("op", op), slog.String("user_id", requester.ID))
+
+	log.Debug("attempting to confirm totp")
+
+	setup, err := s.totp(ctx, log, requester.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if setup.Enabled() {
+		log.Warn("two-factor authentication already enabled")
+		return nil, models.ErrTwoFactorEnabled
+	}
+
+	key, err := s.key(log, setup)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkAttempts(ctx, log, requester.Login, client.IP); err != nil {
+		return nil, err
+	}
+
+	step, ok := totp.Validate(key, normalizeCode(code), time.Now(), s.skew)
+	if !ok {
+		log.Warn("invalid confirmation code")
+		return nil, s.fail(ctx, log, requester.Login, client.IP)
+	}
+
+	codes := make([]string, 0, s.recoveryCodes)
+	hashes := make([]string, 0, s.recoveryCodes)
+
+	for range s.recoveryCodes {
+		code, err := newRecoveryCode()
+		if err != nil {
+			log.Error("failed to generate recovery code", slog.String("error", err.Error()))
+			return nil, models.ErrInternal
+		}
+
+		codes = append(codes, code)
+		hashes = append(hashes, token.Hash(normalizeRecoveryCode(code)))
+	}
+
+	err = s.totps.EnableTOTP(ctx, requester.ID, step, time.Now().UTC(), hashes)
+	if err != nil {
+		if errors.Is(err, models.ErrTwoFactorEnabled) {
+			log.Warn("two-factor authentication already enabled")
+			return nil, models.ErrTwoFactorEnabled
+		}
+		log.Error("failed to enable totp", slog.String("error", err.Error()))
+		return nil, models.ErrInternal
+	}
+
+	s.succeed(ctx, log, requester.Login)
+
+	log.Info("two-factor authentication enabled")
+
+	return codes, nil
+}
+
+// Disable turns two-factor authentication off. It takes a current code or a
+// recovery code, so that a stolen session alone is not enough. Wrong codes
+// count as failed logins, so the code cannot be guessed past the login
+// lockout either.
+func (s *TwoFactorService) Disable(ctx context.Context, requester *models.User, code string, client *models.ClientInfo) error {
+	op := pkg + "Disable"
+
+	log := s.log.With(slog.String("op", op), slog.String("user_id", requester.ID))
+
+	log.Debug("attempting to disable totp")
+
+	if err := s.checkAttempts(ctx, log, requester.Login, client.IP); err != nil {
+		return err
+	}
+
+	if err := s.Verify(ctx, requester.ID, code); err != nil {
+		if errors.Is(err, models.ErrInvalidTwoFactorCode) {
+			return s.fail(ctx, log, requester.Login, client.IP)
+		}
+		return err
+	}
+
+	s.succeed(ctx, log, requester.Login)
+
+	if err := s.totps.DeleteTOTP(ctx, requester.ID); err != nil {
+		if errors.Is(err, models.ErrTwoFactorNotEnrolled) {
+			log.Warn("two-factor authentication not set up")
+			return models.ErrTwoFactorNotEnrolled
+		}
+		log.Error("failed to delete totp", slog.String("error", err.Error()))
+		return models.ErrInternal
+	}
+
+	log.Info("two-factor authentication disabled")
+
+	return nil
+}
+
+// Enabled reports whether logins of the user need a second factor.
+func (s *TwoFactorService) Enabled(ctx context.Context, userID string) (bool, error) {
+	op := pkg + "Enabled"
+
+	log := s.log.With(slog.String("op", op), slog.String("user_id", userID))
+
+	setup, err := s.totps.TOTPByUser(ctx, userID)
+	if err != nil {
+		if errors.Is(err, models.ErrTwoFactorNotEnrolled) {
+			return false, nil
+		}
+		log.Error("failed to get totp", slog.String("error", err.Error()))
+		return false, models.ErrInternal
+	}
+
+	return setup.Enabled(), nil
+}
+
+// Verify accepts a code from the app, each at most once, or an unused
+// recovery code.
+func (s *TwoFactorService) Verify(ctx context.Context, userID string, code string) error {
+	op := pkg + "Verify"
+
+	log := s.log.With(slog.String("op", op), slog.String("user_id", userID))
+
+	log.Debug("attempting to verify two-factor code")
+
+	setup, err := s.totp(ctx, log, userID)
+	if err != nil {
+		return err
+	}
+
+	if !setup.Enabled() {
+		log.Warn("two-factor authentication not enabled")
+		return models.ErrTwoFactorNotEnrolled
+	}
+
+	code = normalizeCode(code)
+
+	if len(code) == totp.Digits {
+		key, err := s.key(log, setup)
+		if err != nil {
+			return err
+		}
+
+		step, ok := totp.Validate(key, code, time.Now(), s.skew)
+		if !ok {
+			log.Warn("invalid totp code")
+			return models.ErrInvalidTwoFactorCode
+		}
+
+		return s.use(log, s.totps.UseTOTPStep(ctx, userID, step), "totp code already used")
+	}
+
+	codeHash := token.Hash(normalizeRecoveryCode(code))
+
+	return s.use(log, s.totps.UseRecoveryCode(ctx, userID, codeHash, time.Now().UTC()), "invalid recovery code")
+}
+
+func (s *TwoFactorService) use(log *slog.Logger, err error, msg string) error {
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidTwoFactorCode) {
+			log.Warn(msg)
+			return models.ErrInvalidTwoFactorCode
+		}
+		log.Error("failed to use two-factor code", slog.String("error", err.Error()))
+		return models.ErrInternal
+	}
+
+	log.Debug("two-factor code verified")
+
+	return nil
+}
+
+// checkAttempts refuses codes while the login or the client is locked out
+// after failed logins.
+func (s *TwoFactorService) checkAttempts(ctx context.Context, log *slog.Logger, login string, ip string) error {
+	if err := s.limiter.Check(ctx, login, ip); err != nil {
+		var retry *models.RetryAfterError
+		if errors.As(err, &retry) {
+			log.Warn("two-factor code check blocked", slog.String("ip", ip))
+			return err
+		}
+		log.Error("failed to check login attempts", slog.String("error", err.Error()))
+		return models.ErrInternal
+	}
+
+	return nil
+}
+
+// fail counts a wrong code as a failed login and returns
+// models.ErrInvalidTwoFactorCode.
+func (s *TwoFactorService) fail(ctx context.Context, log *slog.Logger, login string, ip string) error {
+	if err := s.limiter.Fail(ctx, login, ip); err != nil {
+		log.Error("failed to register failed two-factor code", slog.String("error", err.Error()))
+		return models.ErrInternal
+	}
+
+	return models.ErrInvalidTwoFactorCode
+}
+
+func (s *TwoFactorService) succeed(ctx context.Context, log *slog.Logger, login string) {
+	if err := s.limiter.Succeed(ctx, login); err != nil {
+		log.Warn("failed to reset failed logins", slog.String("error", err.Error()))
+	}
+}
+
+func (s *TwoFactorService) totp(ctx context.Context, log *slog.Logger, userID string) (*models.TOTP, error) {
+	setup, err := s.totps.TOTPByUser(ctx, userID)
+	if err != nil {
+		if errors.Is(err, models.ErrTwoFactorNotEnrolled) {
+			log.Warn("two-factor authentication not set up")
+			return nil, models.ErrTwoFactorNotEnrolled
+		}
+		log.Error("failed to get totp", slog.String("error", err.Error()))
+		return nil, models.ErrInternal
+	}
+
+	return setup, nil
+}
+
+func (s *TwoFactorService) key(log *slog.Logger, setup *models.TOTP) ([]byte, error) {
+	secret, err := s.cipher.Decrypt(setup.Secret)
+	if err != nil {
+		log.Error("failed to decrypt secret", slog.String("error", err.Error()))
+		return nil, models.ErrInternal
+	}
+
+	key, err := totp.DecodeSecret(string(secret))
+	if err != nil {
+		log.Error("failed to decode secret", slog.String("error", err.Error()))
+		return nil, models.ErrInternal
+	}
+
+	return key, nil
+}
+
+// newRecoveryCode returns a code like "k3j5q-7w2mx".
+func newRecoveryCode() (string, error) {
+	raw := make([]byte, 8)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+
+	code := strings.ToLower(base32.StdEncoding.EncodeToString(raw))[:recoveryCodeSize]
+
+	return code[:recoveryCodeSize/2] + "-" + code[recoveryCodeSize/2:], nil
+}
+
+func normalizeCode(code string) string {
+	return strings.ReplaceAll(strings.TrimSpace(code), " ", "")
+}
+
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.ReplaceAll(normalizeCode(code), "-", ""))
+}
@@ -0,0 +1,303 @@
+package twofactorservice
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/base64"
+	"io"
+	"log/slog"
+	"marketplace/internal/models"
+	"marketplace/internal/utils/encryption"
+	"marketplace/internal/utils/token"
+	"marketplace/internal/utils/totp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockTOTPStorer struct {
+	mock.Mock
+}
+
+func (m *mockTOTPStorer) SaveTOTP(ctx context.Context, totp *models.TOTP) error {
+	return m.Called(ctx, totp).Error(0)
+}
+
+func (m *mockTOTPStorer) TOTPByUser(ctx context.Context, userID string) (*models.TOTP, error) {
+	args := m.Called(ctx, userID)
+	setup, _ := args.Get(0).(*models.TOTP)
+	return setup, args.Error(1)
+}
+
+func (m *mockTOTPStorer) EnableTOTP(ctx context.Context, userID string, step int64, enabledAt time.Time, codeHashes []string) error {
+	return m.Called(ctx, userID, step, enabledAt, codeHashes).Error(0)
+}
+
+func (m *mockTOTPStorer) DeleteTOTP(ctx context.Context, userID string) error {
+	return m.Called(ctx, userID).Error(0)
+}
+
+func (m *mockTOTPStorer) UseTOTPStep(ctx context.Context, userID string, step int64) error {
+	return m.Called(ctx, userID, step).Error(0)
+}
+
+func (m *mockTOTPStorer) UseRecoveryCode(ctx context.Context, userID string, codeHash string, usedAt time.Time) error {
+	return m.Called(ctx, userID, codeHash, usedAt).Error(0)
+}
+
+type mockLoginLimiter struct {
+	mock.Mock
+}
+
+func (m *mockLoginLimiter) Check(ctx context.Context, login string, ip string) error {
+	return m.Called(ctx, login, ip).Error(0)
+}
+
+func (m *mockLoginLimiter) Fail(ctx context.Context, login string, ip string) error {
+	return m.Called(ctx, login, ip).Error(0)
+}
+
+func (m *mockLoginLimiter) Succeed(ctx context.Context, login string) error {
+	return m.Called(ctx, login).Error(0)
+}
+
+const secret = "JBSWY3DPEHPK3PXP"
+
+var (
+	user   = &models.User{ID: "u1", Login: "alice"}
+	client = &models.ClientInfo{IP: "10.0.0.1"}
+)
+
+func newService(t *testing.T, totps TOTPStorer, limiter LoginLimiter) (*TwoFactorService, *encryption.Cipher) {
+	cipher, err := encryption.New(base64.StdEncoding.EncodeToString([]byte(strings.Repeat("k", encryption.KeySize))))
+	assert.NoError(t, err)
+
+	return New(slog.New(slog.NewTextHandler(io.Discard, nil)), totps, cipher, limiter, "Marketplace", 1, 10), cipher
+}
+
+func sealedSetup(t *testing.T, cipher *encryption.Cipher, enabled bool) *models.TOTP {
+	sealed, err := cipher.Encrypt([]byte(secret))
+	assert.NoError(t, err)
+
+	setup := &models.TOTP{UserID: user.ID, Secret: sealed}
+	if enabled {
+		enabledAt := time.Now()
+		setup.EnabledAt = &enabledAt
+	}
+
+	return setup
+}
+
+func currentCode(t *testing.T) (string, int64) {
+	key, err := totp.DecodeSecret(secret)
+	assert.NoError(t, err)
+
+	step := totp.Step(time.Now(), totp.Period)
+
+	return totp.Code(key, step, totp.Digits, sha1.New), step
+}
+
+func TestEnroll_StoresEncryptedSecret(t *testing.T) {
+	t.Parallel()
+
+	totps := new(mockTOTPStorer)
+	service, cipher := newService(t, totps, nil)
+
+	var saved *models.TOTP
+	totps.On("SaveTOTP", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		saved = args.Get(1).(*models.TOTP)
+	}).Return(nil)
+
+	enrollment, err := service.Enroll(context.Background(), user)
+
+	assert.NoError(t, err)
+	assert.Contains(t, enrollment.URI, "otpauth://totp/Marketplace:alice?")
+	assert.NotContains(t, saved.Secret, enrollment.Secret)
+
+	plain, err := cipher.Decrypt(saved.Secret)
+	assert.NoError(t, err)
+	assert.Equal(t, enrollment.Secret, string(plain))
+}
+
+func TestEnroll_AlreadyEnabled(t *testing.T) {
+	t.Parallel()
+
+	totps := new(mockTOTPStorer)
+	service, _ := newService(t, totps, nil)
+
+	totps.On("SaveTOTP", mock.Anything, mock.Anything).Return(models.ErrTwoFactorEnabled)
+
+	_, err := service.Enroll(context.Background(), user)
+
+	assert.ErrorIs(t, err, models.ErrTwoFactorEnabled)
+}
+
+func TestConfirm(t *testing.T) {
+	t.Parallel()
+
+	totps := new(mockTOTPStorer)
+	limiter := new(mockLoginLimiter)
+	service, cipher := newService(t, totps, limiter)
+
+	limiter.On("Check", mock.Anything, "alice", "10.0.0.1").Return(nil)
+	limiter.On("Succeed", mock.Anything, "alice").Return(nil)
+
+	code, step := currentCode(t)
+
+	var hashes []string
+	totps.On("TOTPByUser", mock.Anything, user.ID).Return(sealedSetup(t, cipher, false), nil)
+	totps.On("EnableTOTP", mock.Anything, user.ID, step, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		hashes = args.Get(4).([]string)
+	}).Return(nil)
+
+	codes, err := service.Confirm(context.Background(), user, code, client)
+
+	assert.NoError(t, err)
+	assert.Len(t, codes, 10)
+	assert.Len(t, hashes, 10)
+	assert.Regexp(t, `^[a-z2-7]{5}-[a-z2-7]{5}$`, codes[0])
+	assert.Equal(t, token.Hash(strings.ReplaceAll(codes[0], "-", "")), hashes[0])
+}
+
+func TestConfirm_InvalidCode(t *testing.T) {
+	t.Parallel()
+
+	totps := new(mockTOTPStorer)
+	limiter := new(mockLoginLimiter)
+	service, cipher := newService(t, totps, limiter)
+
+	totps.On("TOTPByUser", mock.Anything, user.ID).Return(sealedSetup(t, cipher, false), nil)
+	limiter.On("Check", mock.Anything, "alice", "10.0.0.1").Return(nil)
+	limiter.On("Fail", mock.Anything, "alice", "10.0.0.1").Return(nil)
+
+	_, err := service.Confirm(context.Background(), user, "000000x", client)
+
+	assert.ErrorIs(t, err, models.ErrInvalidTwoFactorCode)
+	totps.AssertNotCalled(t, "EnableTOTP", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	limiter.AssertExpectations(t)
+}
+
+func TestDisable(t *testing.T) {
+	t.Parallel()
+
+	totps := new(mockTOTPStorer)
+	limiter := new(mockLoginLimiter)
+	service, cipher := newService(t, totps, limiter)
+
+	code, step := currentCode(t)
+
+	totps.On("TOTPByUser", mock.Anything, user.ID).Return(sealedSetup(t, cipher, true), nil)
+	totps.On("UseTOTPStep", mock.Anything, user.ID, step).Return(nil)
+	totps.On("DeleteTOTP", mock.Anything, user.ID).Return(nil)
+	limiter.On("Check", mock.Anything, "alice", "10.0.0.1").Return(nil)
+	limiter.On("Succeed", mock.Anything, "alice").Return(nil)
+
+	err := service.Disable(context.Background(), user, code, client)
+
+	assert.NoError(t, err)
+	totps.AssertExpectations(t)
+	limiter.AssertExpectations(t)
+}
+
+func TestDisable_WrongCodeCounted(t *testing.T) {
+	t.Parallel()
+
+	totps := new(mockTOTPStorer)
+	limiter := new(mockLoginLimiter)
+	service, cipher := newService(t, totps, limiter)
+
+	totps.On("TOTPByUser", mock.Anything, user.ID).Return(sealedSetup(t, cipher, true), nil)
+	totps.On("UseRecoveryCode", mock.Anything, user.ID, mock.Anything, mock.Anything).Return(models.ErrInvalidTwoFactorCode)
+	limiter.On("Check", mock.Anything, "alice", "10.0.0.1").Return(nil)
+	limiter.On("Fail", mock.Anything, "alice", "10.0.0.1").Return(nil)
+
+	err := service.Disable(context.Background(), user, "abcde-fghij", client)
+
+	assert.ErrorIs(t, err, models.ErrInvalidTwoFactorCode)
+	totps.AssertNotCalled(t, "DeleteTOTP", mock.Anything, mock.Anything)
+	limiter.AssertExpectations(t)
+}
+
+func TestDisable_LockedOut(t *testing.T) {
+	t.Parallel()
+
+	totps := new(mockTOTPStorer)
+	limiter := new(mockLoginLimiter)
+	service, _ := newService(t, totps, limiter)
+
+	locked := &models.RetryAfterError{After: time.Minute, Err: models.ErrTooManyRequests}
+	limiter.On("Check", mock.Anything, "alice", "10.0.0.1").Return(locked)
+
+	err := service.Disable(context.Background(), user, "123456", client)
+
+	assert.ErrorIs(t, err, locked)
+	totps.AssertNotCalled(t, "TOTPByUser", mock.Anything, mock.Anything)
+	totps.AssertNotCalled(t, "DeleteTOTP", mock.Anything, mock.Anything)
+}
+
+func TestVerify_TOTPCodeOnce(t *testing.T) {
+	t.Parallel()
+
+	totps := new(mockTOTPStorer)
+	service, cipher := newService(t, totps, nil)
+
+	code, step := currentCode(t)
+
+	totps.On("TOTPByUser", mock.Anything, user.ID).Return(sealedSetup(t, cipher, true), nil)
+	totps.On("UseTOTPStep", mock.Anything, user.ID, step).Return(nil).Once()
+	totps.On("UseTOTPStep", mock.Anything, user.ID, step).Return(models.ErrInvalidTwoFactorCode).Once()
+
+	assert.NoError(t, service.Verify(context.Background(), user.ID, code[:3]+" "+code[3:]))
+	assert.ErrorIs(t, service.Verify(context.Background(), user.ID, code), models.ErrInvalidTwoFactorCode)
+}
+
+func TestVerify_RecoveryCode(t *testing.T) {
+	t.Parallel()
+
+	totps := new(mockTOTPStorer)
+	service, cipher := newService(t, totps, nil)
+
+	totps.On("TOTPByUser", mock.Anything, user.ID).Return(sealedSetup(t, cipher, true), nil)
+	totps.On("UseRecoveryCode", mock.Anything, user.ID, token.Hash("abcdefghij"), mock.Anything).Return(nil)
+
+	err := service.Verify(context.Background(), user.ID, "ABCDE-FGHIJ")
+
+	assert.NoError(t, err)
+	totps.AssertExpectations(t)
+}
+
+func TestVerify_NotEnabled(t *testing.T) {
+	t.Parallel()
+
+	totps := new(mockTOTPStorer)
+	service, cipher := newService(t, totps, nil)
+
+	code, _ := currentCode(t)
+
+	totps.On("TOTPByUser", mock.Anything, user.ID).Return(sealedSetup(t, cipher, false), nil)
+
+	err := service.Verify(context.Background(), user.ID, code)
+
+	assert.ErrorIs(t, err, models.ErrTwoFactorNotEnrolled)
+}
+
+func TestEnabled(t *testing.T) {
+	t.Parallel()
+
+	totps := new(mockTOTPStorer)
+	service, cipher := newService(t, totps, nil)
+
+	totps.On("TOTPByUser", mock.Anything, "u1").Return(sealedSetup(t, cipher, true), nil)
+	totps.On("TOTPByUser", mock.Anything, "u2").Return(nil, models.ErrTwoFactorNotEnrolled)
+
+	enabled, err := service.Enabled(context.Background(), "u1")
+	assert.NoError(t, err)
+	assert.True(t, enabled)
+
+	enabled, err = service.Enabled(context.Background(), "u2")
+	assert.NoError(t, err)
+	assert.False(t, enabled)
+}
@@ -10,5 +10,14 @@ type UserAdder interface {
 }
 
 type UserProvider interface {
+	UserByID(ctx context.Context, id string) (*models.User, error)
 	UserByLogin(ctx context.Context, login string) (*models.User, error)
 }
+
+type RoleSetter interface {
+	SetRole(ctx context.Context, login string, role models.Role) error
+}
+
+type SessionInvalidator interface {
+	InvalidateUserSessions(ctx context.Context, userID string) error
+}
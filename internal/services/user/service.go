@@ -9,21 +9,29 @@ import (
 
 const pkg = "userService/"
 
+const emailConstraint = "users_email_key"
+
 type UserService struct {
 	log          *slog.Logger
 	userAdder    UserAdder
 	userProvider UserProvider
+	roleSetter   RoleSetter
+	sessions     SessionInvalidator
 }
 
 func New(
 	log *slog.Logger,
 	userAdder UserAdder,
 	userProvider UserProvider,
+	roleSetter RoleSetter,
+	sessions SessionInvalidator,
 ) *UserService {
 	return &UserService{
 		log:          log,
 		userAdder:    userAdder,
 		userProvider: userProvider,
+		roleSetter:   roleSetter,
+		sessions:     sessions,
 	}
 }
 
@@ -39,6 +47,9 @@ func (us *UserService) AddUser(ctx context.Context, user *models.User) error {
 		var uce *models.UniqueConstraintError
 		if errors.As(err, &uce) {
 			log.Warn("unique constraint violated", slog.String("constraint", uce.Constraint))
+			if uce.Constraint == emailConstraint {
+				return models.ErrEmailExists
+			}
 			return models.ErrUserExists
 		}
 		log.Error("failed to add user", slog.String("error", err.Error()))
@@ -72,3 +83,67 @@ func (us *UserService) UserByLogin(ctx context.Context, login string) (*models.U
 
 	return user, nil
 }
+
+func (us *UserService) UserByID(ctx context.Context, id string) (*models.User, error) {
+	op := pkg + "UserByID"
+
+	log := us.log.With(slog.String("op", op))
+
+	log.Debug("attempting to get user by id")
+
+	user, err := us.userProvider.UserByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, models.ErrUserNotFound) {
+			log.Warn("user not found", slog.String("user_id", id))
+			return nil, models.ErrUserNotFound
+		}
+
+		log.Error("failed to get user by id", slog.String("error", err.Error()))
+		return nil, models.ErrInternal
+	}
+
+	log.Debug("user founded successfully")
+
+	return user, nil
+}
+
+// SetRole gives the user with the login a role. The user's sessions are
+// invalidated, since every replica keeps the user with the old role cached
+// until then.
+func (us *UserService) SetRole(ctx context.Context, login string, role models.Role) error {
+	op := pkg + "SetRole"
+
+	log := us.log.With(slog.String("op", op), slog.String("login", login))
+
+	log.Debug("attempting to set role")
+
+	if !role.Valid() {
+		log.Warn("invalid role", slog.String("role", string(role)))
+		return models.ErrInvalidRole
+	}
+
+	if err := us.roleSetter.SetRole(ctx, login, role); err != nil {
+		if errors.Is(err, models.ErrUserNotFound) {
+			log.Warn("user not found")
+			return models.ErrUserNotFound
+		}
+
+		log.Error("failed to set role", slog.String("error", err.Error()))
+		return models.ErrInternal
+	}
+
+	user, err := us.userProvider.UserByLogin(ctx, login)
+	if err != nil {
+		log.Error("failed to get user", slog.String("error", err.Error()))
+		return models.ErrInternal
+	}
+
+	if err := us.sessions.InvalidateUserSessions(ctx, user.ID); err != nil {
+		log.Error("failed to invalidate sessions", slog.String("error", err.Error()))
+		return models.ErrInternal
+	}
+
+	log.Info("role set", slog.String("role", string(role)))
+
+	return nil
+}
@@ -34,12 +34,30 @@ func (m *MockUserProvider) UserByLogin(ctx context.Context, login string) (*mode
 	return args.Get(0).(*models.User), args.Error(1)
 }
 
+type MockSessionInvalidator struct {
+	mock.Mock
+}
+
+func (m *MockSessionInvalidator) InvalidateUserSessions(ctx context.Context, userID string) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+type MockRoleSetter struct {
+	mock.Mock
+}
+
+func (m *MockRoleSetter) SetRole(ctx context.Context, login string, role models.Role) error {
+	args := m.Called(ctx, login, role)
+	return args.Error(0)
+}
+
 func TestAddUser_Success(t *testing.T) {
 	t.Parallel()
 
 	mockAdder := new(MockUserAdder)
 	mockProvider := new(MockUserProvider)
-	service := New(slog.Default(), mockAdder, mockProvider)
+	service := New(slog.Default(), mockAdder, mockProvider, nil, nil)
 
 	user := models.User{
 		ID:       "1",
@@ -58,7 +76,7 @@ func TestAddUser_UniqueConstraint(t *testing.T) {
 
 	mockAdder := new(MockUserAdder)
 	mockProvider := new(MockUserProvider)
-	service := New(slog.Default(), mockAdder, mockProvider)
+	service := New(slog.Default(), mockAdder, mockProvider, nil, nil)
 
 	user := models.User{
 		ID:       "1",
@@ -74,12 +92,33 @@ func TestAddUser_UniqueConstraint(t *testing.T) {
 	assert.ErrorIs(t, err, models.ErrUserExists)
 }
 
+func TestAddUser_EmailTaken(t *testing.T) {
+	t.Parallel()
+
+	mockAdder := new(MockUserAdder)
+	mockProvider := new(MockUserProvider)
+	service := New(slog.Default(), mockAdder, mockProvider, nil, nil)
+
+	user := models.User{
+		ID:    "1",
+		Login: "test",
+		Email: "user@example.com",
+	}
+
+	mockAdder.On("AddUser", mock.Anything, &user).Return(&models.UniqueConstraintError{
+		Constraint: "users_email_key"})
+
+	err := service.AddUser(context.Background(), &user)
+
+	assert.ErrorIs(t, err, models.ErrEmailExists)
+}
+
 func TestAddUser_OtherErr(t *testing.T) {
 	t.Parallel()
 
 	mockAdder := new(MockUserAdder)
 	mockProvider := new(MockUserProvider)
-	service := New(slog.Default(), mockAdder, mockProvider)
+	service := New(slog.Default(), mockAdder, mockProvider, nil, nil)
 
 	user := models.User{
 		ID:       "1",
@@ -101,7 +140,7 @@ func TestUserByLogin_Success(t *testing.T) {
 
 	mockAdder := new(MockUserAdder)
 	mockProvider := new(MockUserProvider)
-	service := New(slog.Default(), mockAdder, mockProvider)
+	service := New(slog.Default(), mockAdder, mockProvider, nil, nil)
 
 	mockUser := models.User{
 		ID:       "1",
@@ -123,7 +162,7 @@ func TestUserByLogin_NotFound(t *testing.T) {
 
 	mockAdder := new(MockUserAdder)
 	mockProvider := new(MockUserProvider)
-	service := New(slog.Default(), mockAdder, mockProvider)
+	service := New(slog.Default(), mockAdder, mockProvider, nil, nil)
 
 	mockProvider.On("UserByLogin", mock.Anything, "test").Return((*models.User)(nil), models.ErrUserNotFound)
 
@@ -138,7 +177,7 @@ func TestUserByLogin_OtherErr(t *testing.T) {
 
 	mockAdder := new(MockUserAdder)
 	mockProvider := new(MockUserProvider)
-	service := New(slog.Default(), mockAdder, mockProvider)
+	service := New(slog.Default(), mockAdder, mockProvider, nil, nil)
 
 	someErr := errors.New("some error")
 
@@ -149,3 +188,70 @@ func TestUserByLogin_OtherErr(t *testing.T) {
 
 	mockProvider.AssertExpectations(t)
 }
+
+func TestUserByID_NotFound(t *testing.T) {
+	t.Parallel()
+
+	mockProvider := new(MockUserProvider)
+	service := New(slog.Default(), nil, mockProvider, nil, nil)
+
+	mockProvider.On("UserByID", mock.Anything, "1").Return((*models.User)(nil), models.ErrUserNotFound)
+
+	_, err := service.UserByID(context.Background(), "1")
+	assert.ErrorIs(t, err, models.ErrUserNotFound)
+
+	mockProvider.AssertExpectations(t)
+}
+
+func TestSetRole(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		role          models.Role
+		setErr        error
+		invalidateErr error
+		wantErr       error
+		wantCall      bool
+		wantRevoke    bool
+	}{
+		{name: "success", role: models.RoleModerator, wantCall: true, wantRevoke: true},
+		{name: "invalid role", role: "root", wantErr: models.ErrInvalidRole},
+		{name: "user not found", role: models.RoleAdmin, setErr: models.ErrUserNotFound, wantErr: models.ErrUserNotFound, wantCall: true},
+		{name: "internal", role: models.RoleUser, setErr: errors.New("some error"), wantErr: models.ErrInternal, wantCall: true},
+		{name: "sessions not invalidated", role: models.RoleUser, invalidateErr: errors.New("some error"), wantErr: models.ErrInternal, wantCall: true, wantRevoke: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			roleSetter := new(MockRoleSetter)
+			provider := new(MockUserProvider)
+			sessions := new(MockSessionInvalidator)
+			service := New(slog.Default(), nil, provider, roleSetter, sessions)
+
+			roleSetter.On("SetRole", mock.Anything, "test", tt.role).Return(tt.setErr)
+			provider.On("UserByLogin", mock.Anything, "test").Return(&models.User{ID: "u1", Login: "test"}, nil)
+			sessions.On("InvalidateUserSessions", mock.Anything, "u1").Return(tt.invalidateErr)
+
+			err := service.SetRole(context.Background(), "test", tt.role)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			if tt.wantCall {
+				roleSetter.AssertExpectations(t)
+			} else {
+				roleSetter.AssertNotCalled(t, "SetRole", mock.Anything, mock.Anything, mock.Anything)
+			}
+			if tt.wantRevoke {
+				sessions.AssertExpectations(t)
+			} else {
+				sessions.AssertNotCalled(t, "InvalidateUserSessions", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package jwttokens
+
+import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+const (
+	AlgorithmHS256 = "HS256"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+// minSecretSize is the shortest HS256 secret accepted, the size of the hash.
+const minSecretSize = sha256.Size
+
+// Key is a key tokens are signed or verified with. Secret is base64 encoded:
+// a shared secret of at least 32 bytes for HS256, or a 32 byte Ed25519 seed
+// for EdDSA.
+type Key struct {
+	ID        string
+	Algorithm string
+	Secret    string
+}
+
+type signingKey struct {
+	id        string
+	algorithm string
+	secret    []byte
+	private   ed25519.PrivateKey
+	public    ed25519.PublicKey
+}
+
+func parseKey(key Key) (*signingKey, error) {
+	if key.ID == "" {
+		return nil, errors.New("key without id")
+	}
+
+	secret, err := base64.StdEncoding.DecodeString(key.Secret)
+	if err != nil {
+		return nil, fmt.Errorf("key %q: %w", key.ID, err)
+	}
+
+	switch key.Algorithm {
+	case AlgorithmHS256:
+		if len(secret) < minSecretSize {
+			return nil, fmt.Errorf("key %q: secret shorter than %d bytes", key.ID, minSecretSize)
+		}
+		return &signingKey{id: key.ID, algorithm: key.Algorithm, secret: secret}, nil
+	case AlgorithmEdDSA:
+		if len(secret) != ed25519.SeedSize {
+			return nil, fmt.Errorf("key %q: seed must be %d bytes", key.ID, ed25519.SeedSize)
+		}
+		private := ed25519.NewKeyFromSeed(secret)
+		return &signingKey{id: key.ID, algorithm: key.Algorithm, private: private, public: private.Public().(ed25519.PublicKey)}, nil
+	default:
+		return nil, fmt.Errorf("key %q: unsupported algorithm %q", key.ID, key.Algorithm)
+	}
+}
+
+func (k *signingKey) sign(signingInput []byte) []byte {
+	if k.algorithm == AlgorithmEdDSA {
+		return ed25519.Sign(k.private, signingInput)
+	}
+
+	mac := hmac.New(sha256.New, k.secret)
+	mac.Write(signingInput)
+
+	return mac.Sum(nil)
+}
+
+func (k *signingKey) verify(signingInput []byte, signature []byte) bool {
+	if k.algorithm == AlgorithmEdDSA {
+		return ed25519.Verify(k.public, signingInput, signature)
+	}
+
+	return hmac.Equal(k.sign(signingInput), signature)
+}
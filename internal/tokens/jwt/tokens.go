@@ -0,0 +1,203 @@
+package jwttokens
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"marketplace/internal/models"
+	"strings"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+)
+
+const pkg = "jwtTokens/"
+
+type Denylist interface {
+	TokenDenied(ctx context.Context, tokenID string) (bool, error)
+}
+
+type header struct {
+	Algorithm string `json:"alg"`
+	Type      string `json:"typ"`
+	KeyID     string `json:"kid"`
+}
+
+type claims struct {
+	Issuer    string `json:"iss"`
+	Subject   string `json:"sub"`
+	SessionID string `json:"sid"`
+	Version   int64  `json:"ver"`
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt int64  `json:"exp"`
+	ID        string `json:"jti"`
+}
+
+// Tokens are signed JWT access tokens that carry their grant, so they are
+// checked without looking them up. A revoked token stays valid by signature
+// until it expires, so its ID is checked against the denylist, which is the
+// one lookup a request costs. The session version is taken from the token:
+// invalidating the sessions of a user puts their tokens on the denylist.
+//
+// Tokens are signed with the active key. The other keys are only used to
+// verify, so a key can be rotated out: make the new key active, and drop the
+// old one once the tokens it signed have expired.
+type Tokens struct {
+	issuer   string
+	ttl      time.Duration
+	active   *signingKey
+	keys     map[string]*signingKey
+	denylist Denylist
+}
+
+func New(keys []Key, activeKey string, issuer string, ttl time.Duration, denylist Denylist) (*Tokens, error) {
+	t := &Tokens{
+		issuer:   issuer,
+		ttl:      ttl,
+		keys:     make(map[string]*signingKey, len(keys)),
+		denylist: denylist,
+	}
+
+	for _, key := range keys {
+		parsed, err := parseKey(key)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := t.keys[parsed.id]; ok {
+			return nil, fmt.Errorf("duplicate key %q", parsed.id)
+		}
+		t.keys[parsed.id] = parsed
+	}
+
+	active, ok := t.keys[activeKey]
+	if !ok {
+		return nil, fmt.Errorf("active key %q is not among the keys", activeKey)
+	}
+	t.active = active
+
+	return t, nil
+}
+
+// NewAccessToken signs a token for the grant and sets the token ID and the
+// expiry on it, for the session store to deny the token by when revoked.
+func (t *Tokens) NewAccessToken(ctx context.Context, grant *models.AccessGrant) (string, error) {
+	op := pkg + "NewAccessToken"
+
+	grant.TokenID = uuid.NewV4().String()
+	grant.ExpiresAt = grant.IssuedAt.Add(t.ttl)
+
+	rawHeader, err := json.Marshal(header{Algorithm: t.active.algorithm, Type: "JWT", KeyID: t.active.id})
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	rawClaims, err := json.Marshal(claims{
+		Issuer:    t.issuer,
+		Subject:   grant.UserID,
+		SessionID: grant.SessionID,
+		Version:   grant.Version,
+		IssuedAt:  grant.IssuedAt.Unix(),
+		ExpiresAt: grant.ExpiresAt.Unix(),
+		ID:        grant.TokenID,
+	})
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(rawHeader) + "." + base64.RawURLEncoding.EncodeToString(rawClaims)
+	signature := t.active.sign([]byte(signingInput))
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// AccessGrant verifies the token and returns its grant. Tokens that are
+// malformed, signed with an unknown key, expired or denied are reported as
+// models.ErrSessionNotFound.
+func (t *Tokens) AccessGrant(ctx context.Context, accessToken string) (*models.AccessGrant, error) {
+	op := pkg + "AccessGrant"
+
+	c, err := t.verify(accessToken)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", models.ErrSessionNotFound, err)
+	}
+
+	denied, err := t.denylist.TokenDenied(ctx, c.ID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if denied {
+		return nil, fmt.Errorf("%w: token revoked", models.ErrSessionNotFound)
+	}
+
+	return &models.AccessGrant{
+		UserID:    c.Subject,
+		SessionID: c.SessionID,
+		Version:   c.Version,
+		IssuedAt:  time.Unix(c.IssuedAt, 0).UTC(),
+		TokenID:   c.ID,
+		ExpiresAt: time.Unix(c.ExpiresAt, 0).UTC(),
+	}, nil
+}
+
+func (t *Tokens) verify(accessToken string) (*claims, error) {
+	parts := strings.Split(accessToken, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("malformed token")
+	}
+
+	rawHeader, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, err
+	}
+
+	var h header
+	if err := json.Unmarshal(rawHeader, &h); err != nil {
+		return nil, err
+	}
+
+	key, ok := t.keys[h.KeyID]
+	if !ok {
+		return nil, fmt.Errorf("unknown key %q", h.KeyID)
+	}
+
+	// The algorithm is fixed by the key, never taken from the token.
+	if h.Algorithm != key.algorithm {
+		return nil, fmt.Errorf("algorithm %q does not match the key", h.Algorithm)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, err
+	}
+
+	if !key.verify([]byte(parts[0]+"."+parts[1]), signature) {
+		return nil, errors.New("invalid signature")
+	}
+
+	rawClaims, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, err
+	}
+
+	var c claims
+	if err := json.Unmarshal(rawClaims, &c); err != nil {
+		return nil, err
+	}
+
+	if c.Issuer != t.issuer {
+		return nil, fmt.Errorf("issuer %q does not match", c.Issuer)
+	}
+
+	if c.ID == "" || c.Subject == "" {
+		return nil, errors.New("token without id or subject")
+	}
+
+	if !time.Now().Before(time.Unix(c.ExpiresAt, 0)) {
+		return nil, errors.New("token expired")
+	}
+
+	return &c, nil
+}
@@ -0,0 +1,195 @@
+package jwttokens
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"marketplace/internal/models"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockDenylist struct {
+	mock.Mock
+}
+
+func (m *mockDenylist) TokenDenied(ctx context.Context, tokenID string) (bool, error) {
+	args := m.Called(ctx, tokenID)
+	return args.Bool(0), args.Error(1)
+}
+
+var (
+	hsKey = Key{ID: "hs-1", Algorithm: AlgorithmHS256, Secret: base64.StdEncoding.EncodeToString([]byte(strings.Repeat("s", 32)))}
+	edKey = Key{ID: "ed-1", Algorithm: AlgorithmEdDSA, Secret: base64.StdEncoding.EncodeToString([]byte(strings.Repeat("e", 32)))}
+)
+
+func newTokens(t *testing.T, keys []Key, active string, denylist Denylist) *Tokens {
+	t.Helper()
+
+	tokens, err := New(keys, active, "marketplace", time.Minute, denylist)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return tokens
+}
+
+func newGrant() *models.AccessGrant {
+	return &models.AccessGrant{UserID: "u1", SessionID: "s1", Version: 2, IssuedAt: time.Now().UTC()}
+}
+
+func TestTokens_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	for _, key := range []Key{hsKey, edKey} {
+		t.Run(key.Algorithm, func(t *testing.T) {
+			t.Parallel()
+
+			denylist := new(mockDenylist)
+			tokens := newTokens(t, []Key{key}, key.ID, denylist)
+
+			grant := newGrant()
+
+			accessToken, err := tokens.NewAccessToken(context.Background(), grant)
+			assert.NoError(t, err)
+			assert.NotEmpty(t, grant.TokenID)
+
+			denylist.On("TokenDenied", mock.Anything, grant.TokenID).Return(false, nil)
+
+			actual, err := tokens.AccessGrant(context.Background(), accessToken)
+
+			assert.NoError(t, err)
+			assert.Equal(t, "u1", actual.UserID)
+			assert.Equal(t, "s1", actual.SessionID)
+			assert.Equal(t, int64(2), actual.Version)
+			assert.Equal(t, grant.TokenID, actual.TokenID)
+			assert.Equal(t, grant.ExpiresAt.Unix(), actual.ExpiresAt.Unix())
+		})
+	}
+}
+
+func TestTokens_Rotation(t *testing.T) {
+	t.Parallel()
+
+	denylist := new(mockDenylist)
+	denylist.On("TokenDenied", mock.Anything, mock.Anything).Return(false, nil)
+
+	old := newTokens(t, []Key{hsKey}, hsKey.ID, denylist)
+
+	accessToken, err := old.NewAccessToken(context.Background(), newGrant())
+	assert.NoError(t, err)
+
+	rotated := newTokens(t, []Key{hsKey, edKey}, edKey.ID, denylist)
+
+	_, err = rotated.AccessGrant(context.Background(), accessToken)
+	assert.NoError(t, err)
+
+	retired := newTokens(t, []Key{edKey}, edKey.ID, denylist)
+
+	_, err = retired.AccessGrant(context.Background(), accessToken)
+	assert.ErrorIs(t, err, models.ErrSessionNotFound)
+}
+
+func TestTokens_Rejects(t *testing.T) {
+	t.Parallel()
+
+	denylist := new(mockDenylist)
+	denylist.On("TokenDenied", mock.Anything, mock.Anything).Return(false, nil)
+
+	tokens := newTokens(t, []Key{hsKey, edKey}, hsKey.ID, denylist)
+
+	valid, err := tokens.NewAccessToken(context.Background(), newGrant())
+	assert.NoError(t, err)
+
+	expiredGrant := newGrant()
+	expiredGrant.IssuedAt = time.Now().Add(-time.Hour)
+	expired, err := tokens.NewAccessToken(context.Background(), expiredGrant)
+	assert.NoError(t, err)
+
+	otherIssuer, err := New([]Key{hsKey}, hsKey.ID, "someone-else", time.Minute, denylist)
+	assert.NoError(t, err)
+	foreign, err := otherIssuer.NewAccessToken(context.Background(), newGrant())
+	assert.NoError(t, err)
+
+	parts := strings.Split(valid, ".")
+
+	// A token claiming the EdDSA key but signed with HMAC must not pass.
+	confused := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT","kid":"ed-1"}`)) + "." + parts[1] + "." + parts[2]
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{name: "malformed", token: "not-a-jwt"},
+		{name: "tampered claims", token: parts[0] + "." + base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"u2"}`)) + "." + parts[2]},
+		{name: "algorithm confusion", token: confused},
+		{name: "expired", token: expired},
+		{name: "other issuer", token: foreign},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			grant, err := tokens.AccessGrant(context.Background(), tt.token)
+
+			assert.ErrorIs(t, err, models.ErrSessionNotFound)
+			assert.Nil(t, grant)
+		})
+	}
+}
+
+func TestTokens_Denied(t *testing.T) {
+	t.Parallel()
+
+	denylist := new(mockDenylist)
+	tokens := newTokens(t, []Key{edKey}, edKey.ID, denylist)
+
+	grant := newGrant()
+	accessToken, err := tokens.NewAccessToken(context.Background(), grant)
+	assert.NoError(t, err)
+
+	denylist.On("TokenDenied", mock.Anything, grant.TokenID).Return(true, nil).Once()
+
+	_, err = tokens.AccessGrant(context.Background(), accessToken)
+	assert.ErrorIs(t, err, models.ErrSessionNotFound)
+
+	someErr := errors.New("some error")
+	denylist.On("TokenDenied", mock.Anything, grant.TokenID).Return(false, someErr).Once()
+
+	_, err = tokens.AccessGrant(context.Background(), accessToken)
+	assert.ErrorIs(t, err, someErr)
+	assert.NotErrorIs(t, err, models.ErrSessionNotFound)
+}
+
+func TestNew_InvalidKeys(t *testing.T) {
+	t.Parallel()
+
+	short := Key{ID: "short", Algorithm: AlgorithmHS256, Secret: base64.StdEncoding.EncodeToString([]byte("short"))}
+	rsa := Key{ID: "rsa", Algorithm: "RS256", Secret: hsKey.Secret}
+
+	tests := []struct {
+		name   string
+		keys   []Key
+		active string
+	}{
+		{name: "short secret", keys: []Key{short}, active: "short"},
+		{name: "unsupported algorithm", keys: []Key{rsa}, active: "rsa"},
+		{name: "unknown active key", keys: []Key{hsKey}, active: "missing"},
+		{name: "duplicate key", keys: []Key{hsKey, hsKey}, active: hsKey.ID},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := New(tt.keys, tt.active, "marketplace", time.Minute, nil)
+
+			assert.Error(t, err)
+		})
+	}
+}
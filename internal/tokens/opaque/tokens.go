@@ -0,0 +1,52 @@
+package opaquetokens
+
+import (
+	"context"
+	"fmt"
+	"marketplace/internal/models"
+
+	uuid "github.com/satori/go.uuid"
+)
+
+const pkg = "opaqueTokens/"
+
+type GrantProvider interface {
+	AccessGrant(ctx context.Context, accessToken string) (*models.AccessGrant, error)
+	SessionVersion(ctx context.Context, userID string) (int64, error)
+}
+
+// Tokens are random access tokens that mean nothing by themselves. The grant
+// of a token is stored with its session and looked up on every request,
+// together with the session version of the user, so that a token stored
+// while the sessions were being invalidated is rejected too.
+type Tokens struct {
+	grants GrantProvider
+}
+
+func New(grants GrantProvider) *Tokens {
+	return &Tokens{grants: grants}
+}
+
+func (t *Tokens) NewAccessToken(ctx context.Context, grant *models.AccessGrant) (string, error) {
+	return uuid.NewV4().String(), nil
+}
+
+func (t *Tokens) AccessGrant(ctx context.Context, accessToken string) (*models.AccessGrant, error) {
+	op := pkg + "AccessGrant"
+
+	grant, err := t.grants.AccessGrant(ctx, accessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	version, err := t.grants.SessionVersion(ctx, grant.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if grant.Version != version {
+		return nil, fmt.Errorf("%w: token issued before sessions were invalidated", models.ErrSessionNotFound)
+	}
+
+	return grant, nil
+}
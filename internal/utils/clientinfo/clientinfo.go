@@ -0,0 +1,18 @@
+package clientinfo
+
+import (
+	"marketplace/internal/models"
+	"net"
+	"net/http"
+)
+
+// FromRequest describes the device the request came from. RemoteAddr is used
+// as is: the server is not deployed behind a proxy that sets forwarding headers.
+func FromRequest(r *http.Request) *models.ClientInfo {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		ip = r.RemoteAddr
+	}
+
+	return &models.ClientInfo{UserAgent: r.UserAgent(), IP: ip}
+}
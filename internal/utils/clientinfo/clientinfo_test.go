@@ -0,0 +1,37 @@
+package clientinfo
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromRequest(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		wantIP     string
+	}{
+		{name: "host and port", remoteAddr: "10.0.0.1:4321", wantIP: "10.0.0.1"},
+		{name: "ipv6", remoteAddr: "[::1]:4321", wantIP: "::1"},
+		{name: "no port", remoteAddr: "10.0.0.1", wantIP: "10.0.0.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := httptest.NewRequest("GET", "/", nil)
+			r.RemoteAddr = tt.remoteAddr
+			r.Header.Set("User-Agent", "test-agent")
+
+			client := FromRequest(r)
+
+			assert.Equal(t, tt.wantIP, client.IP)
+			assert.Equal(t, "test-agent", client.UserAgent)
+		})
+	}
+}
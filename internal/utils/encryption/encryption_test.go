@@ -0,0 +1,56 @@
+package encryption
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testKey = base64.StdEncoding.EncodeToString([]byte(strings.Repeat("k", KeySize)))
+
+func TestCipher_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	c, err := New(testKey)
+	assert.NoError(t, err)
+
+	first, err := c.Encrypt([]byte("secret"))
+	assert.NoError(t, err)
+	second, err := c.Encrypt([]byte("secret"))
+	assert.NoError(t, err)
+
+	assert.NotContains(t, first, "secret")
+	assert.NotEqual(t, first, second)
+
+	plaintext, err := c.Decrypt(first)
+	assert.NoError(t, err)
+	assert.Equal(t, "secret", string(plaintext))
+}
+
+func TestCipher_WrongKey(t *testing.T) {
+	t.Parallel()
+
+	c, _ := New(testKey)
+	other, _ := New(base64.StdEncoding.EncodeToString([]byte(strings.Repeat("o", KeySize))))
+
+	ciphertext, err := c.Encrypt([]byte("secret"))
+	assert.NoError(t, err)
+
+	_, err = other.Decrypt(ciphertext)
+	assert.ErrorIs(t, err, ErrInvalidCiphertext)
+
+	_, err = c.Decrypt("not base64!")
+	assert.ErrorIs(t, err, ErrInvalidCiphertext)
+}
+
+func TestNew_InvalidKey(t *testing.T) {
+	t.Parallel()
+
+	_, err := New(base64.StdEncoding.EncodeToString([]byte("short")))
+	assert.Error(t, err)
+
+	_, err = New("%%%")
+	assert.Error(t, err)
+}
@@ -2,7 +2,10 @@ package utils
 
 import (
 	"encoding/json"
+	"marketplace/internal/models"
+	"math"
 	"net/http"
+	"strconv"
 )
 
 func WriteJSONError(w http.ResponseWriter, statusCode int, message string) {
@@ -18,3 +21,10 @@ func WriteJSONError(w http.ResponseWriter, statusCode int, message string) {
 func WriteStatusError(w http.ResponseWriter, statusCode int) {
 	w.WriteHeader(statusCode)
 }
+
+// WriteRetryAfter answers 429 and tells the client in whole seconds when it
+// may try again.
+func WriteRetryAfter(w http.ResponseWriter, retry *models.RetryAfterError) {
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retry.After.Seconds()))))
+	WriteJSONError(w, http.StatusTooManyRequests, models.ErrTooManyRequests.Error())
+}
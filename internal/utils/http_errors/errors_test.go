@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -36,3 +37,14 @@ func TestWriteStatusError(t *testing.T) {
 	assert.Equal(t, 0, rr.Body.Len())
 
 }
+
+func TestWriteRetryAfter(t *testing.T) {
+	t.Parallel()
+
+	rr := httptest.NewRecorder()
+
+	WriteRetryAfter(rr, &models.RetryAfterError{After: 1500 * time.Millisecond, Err: models.ErrLoginBlocked})
+
+	assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+	assert.Equal(t, "2", rr.Header().Get("Retry-After"))
+}
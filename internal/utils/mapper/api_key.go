@@ -0,0 +1,59 @@
+package mapper
+
+import (
+	"marketplace/internal/dto"
+	"marketplace/internal/entities"
+	"marketplace/internal/models"
+)
+
+func APIKeysByEntities(rawKeys []*entities.APIKey) []*models.APIKey {
+	keys := make([]*models.APIKey, len(rawKeys))
+	for i, rawKey := range rawKeys {
+		keys[i] = APIKeyByEntity(rawKey)
+	}
+
+	return keys
+}
+
+func APIKeyByEntity(rawKey *entities.APIKey) *models.APIKey {
+	key := &models.APIKey{
+		ID:        rawKey.ID,
+		UserID:    rawKey.UserID,
+		Name:      rawKey.Name,
+		Prefix:    rawKey.Prefix,
+		Scopes:    []string(rawKey.Scopes),
+		CreatedAt: rawKey.CreatedAt,
+	}
+
+	if rawKey.ExpiresAt.Valid {
+		expiresAt := rawKey.ExpiresAt.Time
+		key.ExpiresAt = &expiresAt
+	}
+	if rawKey.LastUsedAt.Valid {
+		lastUsedAt := rawKey.LastUsedAt.Time
+		key.LastUsedAt = &lastUsedAt
+	}
+
+	return key
+}
+
+func DtoFromAPIKeys(keys []*models.APIKey) []*dto.APIKeyResponse {
+	responses := make([]*dto.APIKeyResponse, len(keys))
+	for i, key := range keys {
+		responses[i] = DtoFromAPIKey(key)
+	}
+
+	return responses
+}
+
+func DtoFromAPIKey(key *models.APIKey) *dto.APIKeyResponse {
+	return &dto.APIKeyResponse{
+		ID:         key.ID,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Scopes:     key.Scopes,
+		ExpiresAt:  key.ExpiresAt,
+		LastUsedAt: key.LastUsedAt,
+		CreatedAt:  key.CreatedAt,
+	}
+}
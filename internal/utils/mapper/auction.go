@@ -0,0 +1,87 @@
+package mapper
+
+import (
+	"marketplace/internal/dto"
+	"marketplace/internal/entities"
+	"marketplace/internal/models"
+)
+
+func AuctionByEntity(rawAuction *entities.Auction) *models.Auction {
+	auction := &models.Auction{
+		StartPrice:   rawAuction.StartPrice,
+		MinIncrement: rawAuction.MinIncrement,
+		EndsAt:       rawAuction.EndsAt,
+		CurrentBid:   rawAuction.CurrentBid,
+		BidCount:     rawAuction.BidCount,
+		LeaderID:     rawAuction.LeaderID.String,
+	}
+
+	if rawAuction.ClosedAt.Valid {
+		closedAt := rawAuction.ClosedAt.Time
+		auction.ClosedAt = &closedAt
+	}
+
+	return auction
+}
+
+func auctionFromPostEntity(rawPost *entities.PostWithDocument) *models.Auction {
+	auction := &models.Auction{
+		StartPrice:   rawPost.AuctionStartPrice.Int64,
+		MinIncrement: rawPost.AuctionMinIncrement.Int64,
+		EndsAt:       rawPost.AuctionEndsAt.Time,
+		CurrentBid:   rawPost.AuctionCurrentBid.Int64,
+		BidCount:     int(rawPost.AuctionBidCount.Int64),
+		LeaderID:     rawPost.AuctionLeaderID.String,
+		LeaderLogin:  rawPost.AuctionLeaderLogin.String,
+	}
+
+	if rawPost.AuctionClosedAt.Valid {
+		closedAt := rawPost.AuctionClosedAt.Time
+		auction.ClosedAt = &closedAt
+	}
+
+	return auction
+}
+
+func AuctionResultsByEntities(rawResults []*entities.AuctionResult) []*models.AuctionResult {
+	results := make([]*models.AuctionResult, len(rawResults))
+	for i, rawResult := range rawResults {
+		results[i] = &models.AuctionResult{
+			PostID:      rawResult.PostID,
+			SellerID:    rawResult.SellerID,
+			WinnerID:    rawResult.WinnerID.String,
+			WinnerLogin: rawResult.WinnerLogin.String,
+			Amount:      rawResult.Amount,
+			ClosedAt:    rawResult.ClosedAt,
+		}
+	}
+
+	return results
+}
+
+func DtoFromAuction(auction *models.Auction) *dto.AuctionResponse {
+	if auction == nil {
+		return nil
+	}
+
+	return &dto.AuctionResponse{
+		StartPrice:   auction.StartPrice,
+		MinIncrement: auction.MinIncrement,
+		CurrentBid:   auction.CurrentBid,
+		MinimumBid:   auction.MinimumBid(),
+		BidCount:     auction.BidCount,
+		LeaderLogin:  auction.LeaderLogin,
+		EndsAt:       auction.EndsAt,
+		Closed:       auction.ClosedAt != nil,
+	}
+}
+
+func DtoFromBid(bid *models.Bid, auction *models.Auction) *dto.BidResponse {
+	return &dto.BidResponse{
+		ID:        bid.ID,
+		PostID:    bid.PostID,
+		Amount:    bid.Amount,
+		CreatedAt: bid.CreatedAt,
+		Auction:   DtoFromAuction(auction),
+	}
+}
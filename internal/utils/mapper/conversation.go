@@ -0,0 +1,139 @@
+package mapper
+
+import (
+	"encoding/base64"
+	"marketplace/internal/dto"
+	"marketplace/internal/entities"
+	"marketplace/internal/models"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func ConversationsByEntities(rawConversations []*entities.Conversation) []*models.Conversation {
+	conversations := make([]*models.Conversation, len(rawConversations))
+	for i, rawConversation := range rawConversations {
+		conversations[i] = conversationByEntity(rawConversation)
+	}
+
+	return conversations
+}
+
+func ConversationByEntity(rawConversation *entities.Conversation) *models.Conversation {
+	return conversationByEntity(rawConversation)
+}
+
+func conversationByEntity(rawConversation *entities.Conversation) *models.Conversation {
+	return &models.Conversation{
+		ID:            rawConversation.ID,
+		PostID:        rawConversation.PostID,
+		PostHeader:    rawConversation.PostHeader,
+		BuyerID:       rawConversation.BuyerID,
+		BuyerLogin:    rawConversation.BuyerLogin,
+		SellerID:      rawConversation.SellerID,
+		SellerLogin:   rawConversation.SellerLogin,
+		CreatedAt:     rawConversation.CreatedAt,
+		LastMessageAt: rawConversation.LastMessageAt,
+		UnreadCount:   rawConversation.UnreadCount,
+	}
+}
+
+func MessagesByEntities(rawMessages []*entities.Message) []*models.Message {
+	messages := make([]*models.Message, len(rawMessages))
+	for i, rawMessage := range rawMessages {
+		message := &models.Message{
+			ID:             rawMessage.ID,
+			ConversationID: rawMessage.ConversationID,
+			SenderID:       rawMessage.SenderID,
+			SenderLogin:    rawMessage.SenderLogin,
+			Text:           rawMessage.Text,
+			CreatedAt:      rawMessage.CreatedAt,
+		}
+
+		if rawMessage.ReadAt.Valid {
+			readAt := rawMessage.ReadAt.Time
+			message.ReadAt = &readAt
+		}
+
+		messages[i] = message
+	}
+
+	return messages
+}
+
+func DtoFromConversations(conversations []*models.Conversation) []*dto.ConversationResponse {
+	res := make([]*dto.ConversationResponse, 0)
+
+	for _, conversation := range conversations {
+		res = append(res, dtoFromConversation(conversation))
+	}
+
+	return res
+}
+
+func DtoFromConversation(conversation *models.Conversation) *dto.ConversationResponse {
+	return dtoFromConversation(conversation)
+}
+
+func dtoFromConversation(conversation *models.Conversation) *dto.ConversationResponse {
+	return &dto.ConversationResponse{
+		ID:            conversation.ID,
+		PostID:        conversation.PostID,
+		PostHeader:    conversation.PostHeader,
+		BuyerLogin:    conversation.BuyerLogin,
+		SellerLogin:   conversation.SellerLogin,
+		CreatedAt:     conversation.CreatedAt,
+		LastMessageAt: conversation.LastMessageAt,
+		UnreadCount:   conversation.UnreadCount,
+	}
+}
+
+func DtoFromMessages(messages []*models.Message, requesterID string) []*dto.MessageResponse {
+	res := make([]*dto.MessageResponse, 0)
+
+	for _, message := range messages {
+		res = append(res, DtoFromMessage(message, requesterID))
+	}
+
+	return res
+}
+
+func DtoFromMessage(message *models.Message, requesterID string) *dto.MessageResponse {
+	return &dto.MessageResponse{
+		ID:          message.ID,
+		SenderLogin: message.SenderLogin,
+		Text:        message.Text,
+		IsMine:      message.SenderID == requesterID,
+		CreatedAt:   message.CreatedAt,
+		ReadAt:      message.ReadAt,
+	}
+}
+
+func CursorToString(cursor *models.MessageCursor) string {
+	if cursor == nil {
+		return ""
+	}
+
+	raw := strconv.FormatInt(cursor.CreatedAt.UnixNano(), 10) + ":" + cursor.ID
+
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func StringToCursor(s string) (*models.MessageCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, models.ErrInvalidCursor
+	}
+
+	nanos, id, ok := strings.Cut(string(raw), ":")
+	if !ok || id == "" {
+		return nil, models.ErrInvalidCursor
+	}
+
+	unixNano, err := strconv.ParseInt(nanos, 10, 64)
+	if err != nil {
+		return nil, models.ErrInvalidCursor
+	}
+
+	return &models.MessageCursor{CreatedAt: time.Unix(0, unixNano).UTC(), ID: id}, nil
+}
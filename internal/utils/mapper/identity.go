@@ -0,0 +1,15 @@
+package mapper
+
+import (
+	"marketplace/internal/entities"
+	"marketplace/internal/models"
+)
+
+func IdentityByEntity(rawIdentity *entities.ExternalIdentity) *models.ExternalIdentity {
+	return &models.ExternalIdentity{
+		Provider:  rawIdentity.Provider,
+		Subject:   rawIdentity.Subject,
+		UserID:    rawIdentity.UserID,
+		CreatedAt: rawIdentity.CreatedAt,
+	}
+}
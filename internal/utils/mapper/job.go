@@ -0,0 +1,51 @@
+package mapper
+
+import (
+	"marketplace/internal/dto"
+	"marketplace/internal/models"
+	"path/filepath"
+)
+
+func DtoFromJob(job *models.Job) *dto.JobResponse {
+	return &dto.JobResponse{
+		ID:         job.ID,
+		Kind:       string(job.Kind),
+		Status:     string(job.Status),
+		Error:      job.Error,
+		CreatedAt:  job.CreatedAt,
+		FinishedAt: job.FinishedAt,
+	}
+}
+
+func DtoFromExportProfile(user *models.User, profile *models.Profile) *dto.ExportProfile {
+	res := &dto.ExportProfile{
+		Login:        profile.Login,
+		Email:        user.Email,
+		DisplayName:  profile.DisplayName,
+		Bio:          profile.Bio,
+		RegisteredAt: profile.RegisteredAt,
+	}
+
+	if profile.AvatarPath != "" {
+		res.Avatar = "avatar" + filepath.Ext(profile.AvatarPath)
+	}
+
+	return res
+}
+
+func DtoFromExportPosts(posts []*models.PostWithDocument) []*dto.ExportPost {
+	res := make([]*dto.ExportPost, 0, len(posts))
+
+	for _, post := range posts {
+		res = append(res, &dto.ExportPost{
+			ID:        post.ID,
+			Header:    post.Header,
+			Text:      post.Text,
+			Price:     post.Price,
+			CreatedAt: post.CreatedAt,
+			Image:     "images/" + post.ID + filepath.Ext(post.Document.Name),
+		})
+	}
+
+	return res
+}
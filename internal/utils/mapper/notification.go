@@ -0,0 +1,45 @@
+package mapper
+
+import (
+	"marketplace/internal/dto"
+	"marketplace/internal/entities"
+	"marketplace/internal/models"
+)
+
+func NotificationsByEntities(rawNotifications []*entities.Notification) []*models.Notification {
+	notifications := make([]*models.Notification, len(rawNotifications))
+	for i, rawNotification := range rawNotifications {
+		notification := &models.Notification{
+			ID:        rawNotification.ID,
+			UserID:    rawNotification.UserID,
+			Kind:      models.NotificationKind(rawNotification.Kind),
+			Payload:   rawNotification.Payload,
+			CreatedAt: rawNotification.CreatedAt,
+		}
+
+		if rawNotification.ReadAt.Valid {
+			readAt := rawNotification.ReadAt.Time
+			notification.ReadAt = &readAt
+		}
+
+		notifications[i] = notification
+	}
+
+	return notifications
+}
+
+func DtoFromNotifications(notifications []*models.Notification) []*dto.NotificationResponse {
+	res := make([]*dto.NotificationResponse, 0)
+
+	for _, notification := range notifications {
+		res = append(res, &dto.NotificationResponse{
+			ID:        notification.ID,
+			Kind:      string(notification.Kind),
+			Payload:   notification.Payload,
+			CreatedAt: notification.CreatedAt,
+			ReadAt:    notification.ReadAt,
+		})
+	}
+
+	return res
+}
@@ -0,0 +1,79 @@
+package mapper
+
+import (
+	"marketplace/internal/dto"
+	"marketplace/internal/entities"
+	"marketplace/internal/models"
+)
+
+func OffersByEntities(rawOffers []*entities.Offer) []*models.Offer {
+	offers := make([]*models.Offer, len(rawOffers))
+	for i, rawOffer := range rawOffers {
+		offers[i] = offerByEntity(rawOffer)
+	}
+
+	return offers
+}
+
+func OfferByEntity(rawOffer *entities.Offer) *models.Offer {
+	return offerByEntity(rawOffer)
+}
+
+func offerByEntity(rawOffer *entities.Offer) *models.Offer {
+	offer := &models.Offer{
+		ID:          rawOffer.ID,
+		PostID:      rawOffer.PostID,
+		BuyerID:     rawOffer.BuyerID,
+		BuyerLogin:  rawOffer.BuyerLogin,
+		SellerID:    rawOffer.SellerID,
+		SellerLogin: rawOffer.SellerLogin,
+		ProposerID:  rawOffer.ProposerID,
+		PreviousID:  rawOffer.PreviousID.String,
+		Amount:      rawOffer.Amount,
+		Status:      models.OfferStatus(rawOffer.Status),
+		CreatedAt:   rawOffer.CreatedAt,
+		ExpiresAt:   rawOffer.ExpiresAt,
+	}
+
+	if rawOffer.RespondedAt.Valid {
+		respondedAt := rawOffer.RespondedAt.Time
+		offer.RespondedAt = &respondedAt
+	}
+
+	return offer
+}
+
+func DtoFromOffers(offers []*models.Offer) []*dto.OfferResponse {
+	res := make([]*dto.OfferResponse, 0)
+
+	for _, offer := range offers {
+		res = append(res, dtoFromOffer(offer))
+	}
+
+	return res
+}
+
+func DtoFromOffer(offer *models.Offer) *dto.OfferResponse {
+	return dtoFromOffer(offer)
+}
+
+func dtoFromOffer(offer *models.Offer) *dto.OfferResponse {
+	proposerLogin := offer.BuyerLogin
+	if offer.ProposerID == offer.SellerID {
+		proposerLogin = offer.SellerLogin
+	}
+
+	return &dto.OfferResponse{
+		ID:            offer.ID,
+		PostID:        offer.PostID,
+		BuyerLogin:    offer.BuyerLogin,
+		SellerLogin:   offer.SellerLogin,
+		ProposerLogin: proposerLogin,
+		PreviousID:    offer.PreviousID,
+		Amount:        offer.Amount,
+		Status:        string(offer.Status),
+		CreatedAt:     offer.CreatedAt,
+		ExpiresAt:     offer.ExpiresAt,
+		RespondedAt:   offer.RespondedAt,
+	}
+}
@@ -0,0 +1,65 @@
+package mapper
+
+import (
+	"marketplace/internal/dto"
+	"marketplace/internal/entities"
+	"marketplace/internal/models"
+)
+
+func OrdersByEntities(rawOrders []*entities.Order) []*models.Order {
+	orders := make([]*models.Order, len(rawOrders))
+	for i, rawOrder := range rawOrders {
+		orders[i] = orderByEntity(rawOrder)
+	}
+
+	return orders
+}
+
+func OrderByEntity(rawOrder *entities.Order) *models.Order {
+	return orderByEntity(rawOrder)
+}
+
+func orderByEntity(rawOrder *entities.Order) *models.Order {
+	return &models.Order{
+		ID:          rawOrder.ID,
+		PostID:      rawOrder.PostID,
+		BuyerID:     rawOrder.BuyerID,
+		BuyerLogin:  rawOrder.BuyerLogin,
+		SellerID:    rawOrder.SellerID,
+		SellerLogin: rawOrder.SellerLogin,
+		Amount:      rawOrder.Amount,
+		Status:      models.OrderStatus(rawOrder.Status),
+		PaymentID:   rawOrder.PaymentID.String,
+		CreatedAt:   rawOrder.CreatedAt,
+		UpdatedAt:   rawOrder.UpdatedAt,
+		ExpiresAt:   rawOrder.ExpiresAt,
+	}
+}
+
+func DtoFromOrders(orders []*models.Order) []*dto.OrderResponse {
+	res := make([]*dto.OrderResponse, 0)
+
+	for _, order := range orders {
+		res = append(res, dtoFromOrder(order))
+	}
+
+	return res
+}
+
+func DtoFromOrder(order *models.Order) *dto.OrderResponse {
+	return dtoFromOrder(order)
+}
+
+func dtoFromOrder(order *models.Order) *dto.OrderResponse {
+	return &dto.OrderResponse{
+		ID:          order.ID,
+		PostID:      order.PostID,
+		BuyerLogin:  order.BuyerLogin,
+		SellerLogin: order.SellerLogin,
+		Amount:      order.Amount,
+		Status:      string(order.Status),
+		CreatedAt:   order.CreatedAt,
+		UpdatedAt:   order.UpdatedAt,
+		ExpiresAt:   order.ExpiresAt,
+	}
+}
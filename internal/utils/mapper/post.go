@@ -22,10 +22,11 @@ func PostByEntity(rawPost *entities.PostWithDocument) *models.PostWithDocument {
 }
 
 func postByEntity(rawPost *entities.PostWithDocument) *models.PostWithDocument {
-	return &models.PostWithDocument{
+	post := &models.PostWithDocument{
 		ID:          rawPost.ID,
 		OwnerID:     rawPost.OwnerID,
 		OwnerLogin:  rawPost.OwnerLogin,
+		OwnerRating: models.Rating{Count: rawPost.OwnerReviewCount, Sum: rawPost.OwnerRatingSum},
 		Header:      rawPost.Header,
 		Text:        rawPost.Text,
 		PathToImage: rawPost.DocPath,
@@ -39,6 +40,12 @@ func postByEntity(rawPost *entities.PostWithDocument) *models.PostWithDocument {
 			Path:   rawPost.DocPath,
 		},
 	}
+
+	if rawPost.AuctionStartPrice.Valid {
+		post.Auction = auctionFromPostEntity(rawPost)
+	}
+
+	return post
 }
 
 func DtoFromPosts(posts []*models.PostWithDocument) []*dto.PostResponse {
@@ -51,6 +58,14 @@ func DtoFromPosts(posts []*models.PostWithDocument) []*dto.PostResponse {
 	return res
 }
 
+func PostUpdateFromDto(req *dto.PostUpdateRequest) *models.PostUpdate {
+	return &models.PostUpdate{
+		Header: req.Header,
+		Text:   req.Text,
+		Price:  req.Price,
+	}
+}
+
 func DtoFromPost(post *models.PostWithDocument) *dto.PostResponse {
 	return dtoFromPost(post)
 }
@@ -62,7 +77,10 @@ func dtoFromPost(post *models.PostWithDocument) *dto.PostResponse {
 		PathToImage:      post.PathToImage,
 		Price:            post.Price,
 		OwnerLogin:       post.OwnerLogin,
+		OwnerRating:      post.OwnerRating.Average(),
+		OwnerReviewCount: post.OwnerRating.Count,
 		RequesterIsOwner: post.RequesterIsOwner,
+		Auction:          DtoFromAuction(post.Auction),
 	}
 }
 
@@ -0,0 +1,64 @@
+package mapper
+
+import (
+	"marketplace/internal/dto"
+	"marketplace/internal/entities"
+	"marketplace/internal/models"
+)
+
+func ReportsByEntities(rawReports []*entities.Report) []*models.Report {
+	reports := make([]*models.Report, len(rawReports))
+	for i, rawReport := range rawReports {
+		reports[i] = ReportByEntity(rawReport)
+	}
+
+	return reports
+}
+
+func ReportByEntity(rawReport *entities.Report) *models.Report {
+	report := &models.Report{
+		ID:            rawReport.ID,
+		PostID:        rawReport.PostID,
+		PostOwnerID:   rawReport.PostOwnerID,
+		PostHeader:    rawReport.PostHeader,
+		PostHidden:    rawReport.PostHidden,
+		ReporterID:    rawReport.ReporterID,
+		ReporterLogin: rawReport.ReporterLogin,
+		Reason:        models.ReportReason(rawReport.Reason),
+		Text:          rawReport.Text,
+		Status:        models.ReportStatus(rawReport.Status),
+		CreatedAt:     rawReport.CreatedAt,
+	}
+
+	if rawReport.ClosedAt.Valid {
+		closedAt := rawReport.ClosedAt.Time
+		report.ClosedAt = &closedAt
+	}
+
+	return report
+}
+
+func DtoFromReports(reports []*models.Report) []*dto.ReportResponse {
+	res := make([]*dto.ReportResponse, 0, len(reports))
+
+	for _, report := range reports {
+		res = append(res, DtoFromReport(report))
+	}
+
+	return res
+}
+
+func DtoFromReport(report *models.Report) *dto.ReportResponse {
+	return &dto.ReportResponse{
+		ID:            report.ID,
+		PostID:        report.PostID,
+		PostHeader:    report.PostHeader,
+		PostHidden:    report.PostHidden,
+		ReporterLogin: report.ReporterLogin,
+		Reason:        string(report.Reason),
+		Text:          report.Text,
+		Status:        string(report.Status),
+		CreatedAt:     report.CreatedAt,
+		ClosedAt:      report.ClosedAt,
+	}
+}
@@ -0,0 +1,108 @@
+package mapper
+
+import (
+	"marketplace/internal/dto"
+	"marketplace/internal/entities"
+	"marketplace/internal/models"
+)
+
+func ReviewsByEntities(rawReviews []*entities.Review) []*models.Review {
+	reviews := make([]*models.Review, len(rawReviews))
+	for i, rawReview := range rawReviews {
+		reviews[i] = reviewByEntity(rawReview)
+	}
+
+	return reviews
+}
+
+func ReviewByEntity(rawReview *entities.Review) *models.Review {
+	return reviewByEntity(rawReview)
+}
+
+func reviewByEntity(rawReview *entities.Review) *models.Review {
+	review := &models.Review{
+		ID:          rawReview.ID,
+		PostID:      rawReview.PostID.String,
+		SellerID:    rawReview.SellerID,
+		SellerLogin: rawReview.SellerLogin,
+		AuthorID:    rawReview.AuthorID,
+		AuthorLogin: rawReview.AuthorLogin,
+		Rating:      rawReview.Rating,
+		Text:        rawReview.Text,
+		Reply:       rawReview.Reply,
+		ReplyStatus: models.ReplyStatus(rawReview.ReplyStatus),
+		CreatedAt:   rawReview.CreatedAt,
+	}
+
+	if rawReview.RepliedAt.Valid {
+		repliedAt := rawReview.RepliedAt.Time
+		review.RepliedAt = &repliedAt
+	}
+
+	return review
+}
+
+// DtoFromReviews hides replies that have not been approved yet.
+func DtoFromReviews(reviews []*models.Review) []*dto.ReviewResponse {
+	res := make([]*dto.ReviewResponse, 0)
+
+	for _, review := range reviews {
+		res = append(res, dtoFromReview(review, false))
+	}
+
+	return res
+}
+
+// DtoFromPendingReplies includes the replies waiting for moderation.
+func DtoFromPendingReplies(reviews []*models.Review) []*dto.ReviewResponse {
+	res := make([]*dto.ReviewResponse, 0)
+
+	for _, review := range reviews {
+		res = append(res, dtoFromReview(review, true))
+	}
+
+	return res
+}
+
+// DtoFromReview includes the reply and its moderation status, for the seller
+// and moderators.
+func DtoFromReview(review *models.Review) *dto.ReviewResponse {
+	return dtoFromReview(review, true)
+}
+
+func dtoFromReview(review *models.Review, withPendingReply bool) *dto.ReviewResponse {
+	res := &dto.ReviewResponse{
+		ID:          review.ID,
+		PostID:      review.PostID,
+		SellerLogin: review.SellerLogin,
+		AuthorLogin: review.AuthorLogin,
+		Rating:      review.Rating,
+		Text:        review.Text,
+		CreatedAt:   review.CreatedAt,
+	}
+
+	if withPendingReply {
+		res.Reply = review.Reply
+		res.ReplyStatus = string(review.ReplyStatus)
+		res.RepliedAt = review.RepliedAt
+	} else if reply := review.PublicReply(); reply != "" {
+		res.Reply = reply
+		res.RepliedAt = review.RepliedAt
+	}
+
+	return res
+}
+
+func RatingByEntity(rawRating *entities.Rating) models.Rating {
+	return models.Rating{
+		Count: rawRating.Count,
+		Sum:   rawRating.Sum,
+	}
+}
+
+func DtoFromRating(rating models.Rating) *dto.RatingResponse {
+	return &dto.RatingResponse{
+		Average: rating.Average(),
+		Count:   rating.Count,
+	}
+}
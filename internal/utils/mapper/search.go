@@ -0,0 +1,95 @@
+package mapper
+
+import (
+	"marketplace/internal/dto"
+	"marketplace/internal/entities"
+	"marketplace/internal/models"
+)
+
+func SearchesByEntities(rawSearches []*entities.SavedSearch) []*models.SavedSearch {
+	searches := make([]*models.SavedSearch, len(rawSearches))
+	for i, rawSearch := range rawSearches {
+		searches[i] = searchByEntity(rawSearch)
+	}
+
+	return searches
+}
+
+func SearchByEntity(rawSearch *entities.SavedSearch) *models.SavedSearch {
+	return searchByEntity(rawSearch)
+}
+
+func searchByEntity(rawSearch *entities.SavedSearch) *models.SavedSearch {
+	return &models.SavedSearch{
+		ID:     rawSearch.ID,
+		UserID: rawSearch.UserID,
+		Filter: models.PostsFilter{
+			MinPrice:  uint(rawSearch.MinPrice),
+			MaxPrice:  uint(rawSearch.MaxPrice),
+			SortBy:    rawSearch.SortBy,
+			SortOrder: rawSearch.SortOrder,
+		},
+		CreatedAt: rawSearch.CreatedAt,
+	}
+}
+
+func MatchesByEntities(rawMatches []*entities.SearchMatch) []*models.SearchMatch {
+	matches := make([]*models.SearchMatch, len(rawMatches))
+	for i, rawMatch := range rawMatches {
+		matches[i] = &models.SearchMatch{
+			SearchID:  rawMatch.SearchID,
+			Post:      postByEntity(&rawMatch.PostWithDocument),
+			MatchedAt: rawMatch.MatchedAt,
+		}
+	}
+
+	return matches
+}
+
+func FilterFromDto(req *dto.SearchRequest) *models.PostsFilter {
+	return &models.PostsFilter{
+		MinPrice:  req.MinPrice,
+		MaxPrice:  req.MaxPrice,
+		SortBy:    req.SortBy,
+		SortOrder: req.SortOrder,
+	}
+}
+
+func DtoFromSearches(searches []*models.SavedSearch) []*dto.SearchResponse {
+	res := make([]*dto.SearchResponse, 0)
+
+	for _, search := range searches {
+		res = append(res, dtoFromSearch(search))
+	}
+
+	return res
+}
+
+func DtoFromSearch(search *models.SavedSearch) *dto.SearchResponse {
+	return dtoFromSearch(search)
+}
+
+func dtoFromSearch(search *models.SavedSearch) *dto.SearchResponse {
+	return &dto.SearchResponse{
+		ID:        search.ID,
+		MinPrice:  search.Filter.MinPrice,
+		MaxPrice:  search.Filter.MaxPrice,
+		SortBy:    search.Filter.SortBy,
+		SortOrder: search.Filter.SortOrder,
+		CreatedAt: search.CreatedAt,
+	}
+}
+
+func DtoFromMatches(matches []*models.SearchMatch) []*dto.SearchMatchResponse {
+	res := make([]*dto.SearchMatchResponse, 0)
+
+	for _, match := range matches {
+		res = append(res, &dto.SearchMatchResponse{
+			PostID:    match.Post.ID,
+			Post:      dtoFromPost(match.Post),
+			MatchedAt: match.MatchedAt,
+		})
+	}
+
+	return res
+}
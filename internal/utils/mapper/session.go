@@ -0,0 +1,23 @@
+package mapper
+
+import (
+	"marketplace/internal/dto"
+	"marketplace/internal/models"
+)
+
+func DtoFromSessions(sessions []*models.Session) []*dto.SessionResponse {
+	res := make([]*dto.SessionResponse, 0)
+
+	for _, session := range sessions {
+		res = append(res, &dto.SessionResponse{
+			ID:         session.ID,
+			UserAgent:  session.UserAgent,
+			IP:         session.IP,
+			CreatedAt:  session.CreatedAt,
+			LastSeenAt: session.LastSeenAt,
+			Current:    session.Current,
+		})
+	}
+
+	return res
+}
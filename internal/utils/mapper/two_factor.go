@@ -0,0 +1,22 @@
+package mapper
+
+import (
+	"marketplace/internal/entities"
+	"marketplace/internal/models"
+)
+
+func TOTPByEntity(rawTOTP *entities.TOTP) *models.TOTP {
+	totp := &models.TOTP{
+		UserID:    rawTOTP.UserID,
+		Secret:    rawTOTP.Secret,
+		LastStep:  rawTOTP.LastStep,
+		CreatedAt: rawTOTP.CreatedAt,
+	}
+
+	if rawTOTP.EnabledAt.Valid {
+		enabledAt := rawTOTP.EnabledAt.Time
+		totp.EnabledAt = &enabledAt
+	}
+
+	return totp
+}
@@ -1,14 +1,67 @@
 package mapper
 
 import (
+	"marketplace/internal/dto"
 	"marketplace/internal/entities"
 	"marketplace/internal/models"
 )
 
 func UserByEntity(rawUser *entities.User) *models.User {
-	return &models.User{
+	user := &models.User{
 		ID:       rawUser.ID,
 		Login:    rawUser.Login,
 		PassHash: rawUser.PassHash,
+		Email:    rawUser.Email.String,
+		Role:     models.Role(rawUser.Role),
+	}
+
+	if rawUser.EmailVerifiedAt.Valid {
+		verifiedAt := rawUser.EmailVerifiedAt.Time
+		user.EmailVerifiedAt = &verifiedAt
+	}
+
+	if rawUser.BannedAt.Valid {
+		user.Ban = &models.Ban{
+			Reason:   rawUser.BanReason.String,
+			BannedBy: rawUser.BannedBy.String,
+			BannedAt: rawUser.BannedAt.Time,
+		}
+
+		if rawUser.BannedUntil.Valid {
+			until := rawUser.BannedUntil.Time
+			user.Ban.Until = &until
+		}
+	}
+
+	return user
+}
+
+func ProfileByEntity(rawProfile *entities.Profile) *models.Profile {
+	return &models.Profile{
+		UserID:       rawProfile.UserID,
+		Login:        rawProfile.Login,
+		DisplayName:  rawProfile.DisplayName,
+		Bio:          rawProfile.Bio,
+		AvatarPath:   rawProfile.AvatarPath,
+		RegisteredAt: rawProfile.RegisteredAt,
+		PostCount:    rawProfile.PostCount,
+	}
+}
+
+func ProfileUpdateFromDto(req *dto.ProfileUpdateRequest) *models.ProfileUpdate {
+	return &models.ProfileUpdate{
+		DisplayName: req.DisplayName,
+		Bio:         req.Bio,
+	}
+}
+
+func DtoFromProfile(profile *models.Profile) *dto.ProfileResponse {
+	return &dto.ProfileResponse{
+		Login:        profile.Login,
+		DisplayName:  profile.DisplayName,
+		Bio:          profile.Bio,
+		AvatarPath:   profile.AvatarPath,
+		RegisteredAt: profile.RegisteredAt,
+		PostCount:    profile.PostCount,
 	}
 }
@@ -0,0 +1,100 @@
+// Package totp implements time-based one-time passwords (RFC 6238) as used by
+// authenticator apps.
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// Period is the time step of a code.
+	Period = 30 * time.Second
+	// Digits is the length of a code.
+	Digits = 6
+
+	secretSize = 20
+)
+
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// NewSecret returns a random secret in the base32 form that authenticator apps
+// accept.
+func NewSecret() (string, error) {
+	raw := make([]byte, secretSize)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+
+	return encoding.EncodeToString(raw), nil
+}
+
+// DecodeSecret parses a base32 secret, ignoring case, spaces and padding.
+func DecodeSecret(secret string) ([]byte, error) {
+	secret = strings.ToUpper(strings.ReplaceAll(secret, " ", ""))
+	return encoding.DecodeString(strings.TrimRight(secret, "="))
+}
+
+// URI returns the otpauth URI of the secret, usually shown as a QR code.
+func URI(issuer string, account string, secret string) string {
+	label := url.PathEscape(issuer + ":" + account)
+
+	query := url.Values{}
+	query.Set("secret", secret)
+	query.Set("issuer", issuer)
+
+	return "otpauth://totp/" + label + "?" + query.Encode()
+}
+
+// Step returns the number of the time step t falls in.
+func Step(t time.Time, period time.Duration) int64 {
+	return t.Unix() / int64(period/time.Second)
+}
+
+// Code returns the code of the given time step (RFC 4226, section 5.3).
+func Code(key []byte, step int64, digits int, h func() hash.Hash) string {
+	mac := hmac.New(h, key)
+
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], uint64(step))
+	mac.Write(counter[:])
+
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+	binCode := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	mod := uint32(1)
+	for range digits {
+		mod *= 10
+	}
+
+	return fmt.Sprintf("%0*d", digits, binCode%mod)
+}
+
+// Validate checks a six-digit SHA-1 code against the steps around t, allowing
+// for skew steps of clock drift in either direction. It returns the step that
+// matched, which the caller keeps to refuse the same code twice.
+func Validate(key []byte, code string, t time.Time, skew int) (int64, bool) {
+	if len(code) != Digits {
+		return 0, false
+	}
+
+	current := Step(t, Period)
+
+	for delta := -int64(skew); delta <= int64(skew); delta++ {
+		step := current + delta
+		if hmac.Equal([]byte(Code(key, step, Digits, sha1.New)), []byte(code)) {
+			return step, true
+		}
+	}
+
+	return 0, false
+}
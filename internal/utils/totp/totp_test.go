@@ -0,0 +1,107 @@
+package totp
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"hash"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Test vectors from RFC 6238, appendix B. The seed is the ASCII string
+// "12345678901234567890" repeated to the size of the hash.
+func TestCode_RFC6238(t *testing.T) {
+	t.Parallel()
+
+	seeds := map[string][]byte{
+		"SHA1":   []byte("12345678901234567890"),
+		"SHA256": []byte("12345678901234567890123456789012"),
+		"SHA512": []byte("1234567890123456789012345678901234567890123456789012345678901234"),
+	}
+	hashes := map[string]func() hash.Hash{
+		"SHA1":   sha1.New,
+		"SHA256": sha256.New,
+		"SHA512": sha512.New,
+	}
+
+	tests := []struct {
+		unix int64
+		mode string
+		want string
+	}{
+		{unix: 59, mode: "SHA1", want: "94287082"},
+		{unix: 59, mode: "SHA256", want: "46119246"},
+		{unix: 59, mode: "SHA512", want: "90693936"},
+		{unix: 1111111109, mode: "SHA1", want: "07081804"},
+		{unix: 1111111109, mode: "SHA256", want: "68084774"},
+		{unix: 1111111109, mode: "SHA512", want: "25091201"},
+		{unix: 1111111111, mode: "SHA1", want: "14050471"},
+		{unix: 1111111111, mode: "SHA256", want: "67062674"},
+		{unix: 1111111111, mode: "SHA512", want: "99943326"},
+		{unix: 1234567890, mode: "SHA1", want: "89005924"},
+		{unix: 1234567890, mode: "SHA256", want: "91819424"},
+		{unix: 1234567890, mode: "SHA512", want: "93441116"},
+		{unix: 2000000000, mode: "SHA1", want: "69279037"},
+		{unix: 2000000000, mode: "SHA256", want: "90698825"},
+		{unix: 2000000000, mode: "SHA512", want: "38618901"},
+		{unix: 20000000000, mode: "SHA1", want: "65353130"},
+		{unix: 20000000000, mode: "SHA256", want: "77737706"},
+		{unix: 20000000000, mode: "SHA512", want: "47863826"},
+	}
+
+	for _, tt := range tests {
+		step := Step(time.Unix(tt.unix, 0), Period)
+		got := Code(seeds[tt.mode], step, 8, hashes[tt.mode])
+		assert.Equal(t, tt.want, got, "%s at %d", tt.mode, tt.unix)
+	}
+}
+
+func TestValidate_Skew(t *testing.T) {
+	t.Parallel()
+
+	key := []byte("12345678901234567890")
+	now := time.Unix(1111111111, 0)
+	step := Step(now, Period)
+
+	previous := Code(key, step-1, Digits, sha1.New)
+	next := Code(key, step+1, Digits, sha1.New)
+	stale := Code(key, step-2, Digits, sha1.New)
+
+	matched, ok := Validate(key, previous, now, 1)
+	assert.True(t, ok)
+	assert.Equal(t, step-1, matched)
+
+	matched, ok = Validate(key, next, now, 1)
+	assert.True(t, ok)
+	assert.Equal(t, step+1, matched)
+
+	_, ok = Validate(key, stale, now, 1)
+	assert.False(t, ok)
+
+	_, ok = Validate(key, previous, now, 0)
+	assert.False(t, ok)
+
+	_, ok = Validate(key, "12345", now, 1)
+	assert.False(t, ok)
+}
+
+func TestSecret_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	secret, err := NewSecret()
+	assert.NoError(t, err)
+	assert.Len(t, secret, 32)
+
+	key, err := DecodeSecret(strings.ToLower(secret))
+	assert.NoError(t, err)
+	assert.Len(t, key, secretSize)
+
+	uri := URI("Marketplace", "alice", secret)
+	assert.True(t, strings.HasPrefix(uri, "otpauth://totp/Marketplace:alice?"))
+	assert.Contains(t, uri, "secret="+secret)
+	assert.Contains(t, uri, "issuer=Marketplace")
+}
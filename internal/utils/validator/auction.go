@@ -0,0 +1,33 @@
+package validator
+
+import (
+	"fmt"
+	"marketplace/internal/models"
+	"time"
+)
+
+const (
+	MinAuctionDuration = time.Hour
+	MaxAuctionDuration = 30 * 24 * time.Hour
+)
+
+func ValidateAuction(auction *models.Auction, now time.Time) error {
+	if auction.StartPrice < MinPrice || auction.StartPrice > MaxPrice {
+		return fmt.Errorf("%w: start price must be between %d and %d", models.ErrInvalidAuction, MinPrice, MaxPrice)
+	}
+
+	if auction.MinIncrement < 1 || auction.MinIncrement > auction.StartPrice {
+		return fmt.Errorf("%w: min increment must be between 1 and the start price", models.ErrInvalidAuction)
+	}
+
+	duration := auction.EndsAt.Sub(now)
+	if duration < MinAuctionDuration || duration > MaxAuctionDuration {
+		return fmt.Errorf("%w: auction must last between %s and %s", models.ErrInvalidAuction, MinAuctionDuration, MaxAuctionDuration)
+	}
+
+	return nil
+}
+
+func ValidateBidAmount(amount int64) error {
+	return validatePrice(amount)
+}
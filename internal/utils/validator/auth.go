@@ -1,10 +1,13 @@
 package validator
 
 import (
+	"net/mail"
 	"regexp"
 	"unicode"
 )
 
+const maxEmailLength = 254
+
 var loginRegex = regexp.MustCompile(`^[a-zA-Z0-9]{4,}$`)
 
 func IsValidLogin(login string) bool {
@@ -33,3 +36,18 @@ func IsValidPassword(password string) bool {
 
 	return hasLower && hasUpper && hasDigit && hasSymbol
 }
+
+// IsValidEmail accepts a bare address such as user@example.com, without a
+// display name or angle brackets.
+func IsValidEmail(email string) bool {
+	if len(email) > maxEmailLength {
+		return false
+	}
+
+	addr, err := mail.ParseAddress(email)
+	if err != nil {
+		return false
+	}
+
+	return addr.Name == "" && addr.Address == email
+}
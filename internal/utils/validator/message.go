@@ -0,0 +1,17 @@
+package validator
+
+import (
+	"fmt"
+	"marketplace/internal/models"
+	"strings"
+)
+
+const MaxMessageLength = 2000
+
+func ValidateMessage(text string) error {
+	if strings.TrimSpace(text) == "" || len(text) > MaxMessageLength {
+		return fmt.Errorf("%w: message must be between 1 and %d characters", models.ErrInvalidText, MaxMessageLength)
+	}
+
+	return nil
+}
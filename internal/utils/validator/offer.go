@@ -0,0 +1,5 @@
+package validator
+
+func ValidateOfferAmount(amount int64) error {
+	return validatePrice(amount)
+}
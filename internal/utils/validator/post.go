@@ -24,7 +24,11 @@ func ValidatePost(post *models.PostWithDocument) error {
 		return fmt.Errorf("%w: text must be between %d and %d characters", models.ErrInvalidText, MinTextLength, MaxTextLength)
 	}
 
-	if post.Price < MinPrice || post.Price > MaxPrice {
+	return validatePrice(post.Price)
+}
+
+func validatePrice(price int64) error {
+	if price < MinPrice || price > MaxPrice {
 		return fmt.Errorf("%w: price must be between %d and %d", models.ErrInvalidPrice, MinPrice, MaxPrice)
 	}
 
@@ -0,0 +1,24 @@
+package validator
+
+import (
+	"fmt"
+	"marketplace/internal/models"
+	"unicode/utf8"
+)
+
+const (
+	MaxDisplayNameLength = 64
+	MaxBioLength         = 500
+)
+
+func ValidateProfileUpdate(update *models.ProfileUpdate) error {
+	if update.DisplayName != nil && utf8.RuneCountInString(*update.DisplayName) > MaxDisplayNameLength {
+		return fmt.Errorf("%w: display name must be at most %d characters", models.ErrInvalidText, MaxDisplayNameLength)
+	}
+
+	if update.Bio != nil && utf8.RuneCountInString(*update.Bio) > MaxBioLength {
+		return fmt.Errorf("%w: bio must be at most %d characters", models.ErrInvalidText, MaxBioLength)
+	}
+
+	return nil
+}
@@ -0,0 +1,27 @@
+package validator
+
+import (
+	"fmt"
+	"marketplace/internal/models"
+	"unicode/utf8"
+)
+
+const MaxReportLength = 1000
+
+// ValidateReport checks the reason code and the free text of a report. The
+// text is optional, but a report for another reason must explain itself.
+func ValidateReport(reason models.ReportReason, text string) error {
+	if !reason.Valid() {
+		return fmt.Errorf("%w: %q", models.ErrInvalidReportReason, reason)
+	}
+
+	if reason == models.ReportOther && text == "" {
+		return fmt.Errorf("%w: text is required for reason %q", models.ErrInvalidText, reason)
+	}
+
+	if utf8.RuneCountInString(text) > MaxReportLength {
+		return fmt.Errorf("%w: report must be at most %d characters", models.ErrInvalidText, MaxReportLength)
+	}
+
+	return nil
+}
@@ -0,0 +1,34 @@
+package validator
+
+import (
+	"fmt"
+	"marketplace/internal/models"
+	"strings"
+)
+
+const (
+	MinRating            = 1
+	MaxRating            = 5
+	MaxReviewLength      = 2000
+	MaxReviewReplyLength = 1000
+)
+
+func ValidateReview(rating int, text string) error {
+	if rating < MinRating || rating > MaxRating {
+		return fmt.Errorf("%w: rating must be between %d and %d", models.ErrInvalidRating, MinRating, MaxRating)
+	}
+
+	if len(text) > MaxReviewLength {
+		return fmt.Errorf("%w: review must be at most %d characters", models.ErrInvalidText, MaxReviewLength)
+	}
+
+	return nil
+}
+
+func ValidateReviewReply(text string) error {
+	if strings.TrimSpace(text) == "" || len(text) > MaxReviewReplyLength {
+		return fmt.Errorf("%w: reply must be between 1 and %d characters", models.ErrInvalidText, MaxReviewReplyLength)
+	}
+
+	return nil
+}
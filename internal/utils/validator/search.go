@@ -0,0 +1,31 @@
+package validator
+
+import (
+	"fmt"
+	"marketplace/internal/models"
+)
+
+func ValidatePostsFilter(filter *models.PostsFilter) error {
+	if filter.MaxPrice > 0 && filter.MinPrice > filter.MaxPrice {
+		return fmt.Errorf("%w: min price must not exceed max price", models.ErrInvalidFilter)
+	}
+
+	if filter.MaxPrice > MaxPrice {
+		return fmt.Errorf("%w: price must be between %d and %d", models.ErrInvalidFilter, MinPrice, MaxPrice)
+	}
+
+	switch filter.SortBy {
+	case "":
+		if filter.SortOrder != "" {
+			return fmt.Errorf("%w: sort order requires sort by", models.ErrInvalidFilter)
+		}
+	case "price", "created_at":
+		if filter.SortOrder != "asc" && filter.SortOrder != "desc" {
+			return fmt.Errorf("%w: invalid sort order: %s", models.ErrInvalidFilter, filter.SortOrder)
+		}
+	default:
+		return fmt.Errorf("%w: invalid sort by: %s", models.ErrInvalidFilter, filter.SortBy)
+	}
+
+	return nil
+}
@@ -1,6 +1,12 @@
 package validator
 
-import "testing"
+import (
+	"errors"
+	"marketplace/internal/models"
+	"strings"
+	"testing"
+	"time"
+)
 
 func TestIsValidPassword(t *testing.T) {
 	t.Parallel()
@@ -93,3 +99,308 @@ func TestIsValidLogin(t *testing.T) {
 		}
 	}
 }
+
+func TestIsValidEmail(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		Name  string
+		Email string
+		Want  bool
+	}{
+		{
+			Name:  "empty",
+			Email: "",
+			Want:  false,
+		},
+		{
+			Name:  "plain address",
+			Email: "user@example.com",
+			Want:  true,
+		},
+		{
+			Name:  "no domain",
+			Email: "user@",
+			Want:  false,
+		},
+		{
+			Name:  "display name",
+			Email: "User <user@example.com>",
+			Want:  false,
+		},
+		{
+			Name:  "too long",
+			Email: strings.Repeat("a", 250) + "@example.com",
+			Want:  false,
+		},
+	}
+
+	for _, test := range tests {
+		if res := IsValidEmail(test.Email); res != test.Want {
+			t.Errorf("\ntest: %s\nvalue: %v\nexpected: %v", test.Name, res, test.Want)
+		}
+	}
+}
+
+func TestValidatePostsFilter(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		Name   string
+		Filter models.PostsFilter
+		Want   error
+	}{
+		{
+			Name:   "empty",
+			Filter: models.PostsFilter{},
+			Want:   nil,
+		},
+		{
+			Name:   "price range",
+			Filter: models.PostsFilter{MinPrice: 10, MaxPrice: 100},
+			Want:   nil,
+		},
+		{
+			Name:   "min above max",
+			Filter: models.PostsFilter{MinPrice: 100, MaxPrice: 10},
+			Want:   models.ErrInvalidFilter,
+		},
+		{
+			Name:   "sort by price desc",
+			Filter: models.PostsFilter{SortBy: "price", SortOrder: "desc"},
+			Want:   nil,
+		},
+		{
+			Name:   "sort by without order",
+			Filter: models.PostsFilter{SortBy: "created_at"},
+			Want:   models.ErrInvalidFilter,
+		},
+		{
+			Name:   "order without sort by",
+			Filter: models.PostsFilter{SortOrder: "asc"},
+			Want:   models.ErrInvalidFilter,
+		},
+		{
+			Name:   "unknown sort by",
+			Filter: models.PostsFilter{SortBy: "header", SortOrder: "asc"},
+			Want:   models.ErrInvalidFilter,
+		},
+	}
+
+	for _, test := range tests {
+		if err := ValidatePostsFilter(&test.Filter); !errors.Is(err, test.Want) {
+			t.Errorf("\ntest: %s\nvalue: %v\nexpected: %v", test.Name, err, test.Want)
+		}
+	}
+}
+
+func TestValidateMessage(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		Name string
+		Text string
+		Want error
+	}{
+		{
+			Name: "valid message",
+			Text: "Is it still available?",
+			Want: nil,
+		},
+		{
+			Name: "empty message",
+			Text: "",
+			Want: models.ErrInvalidText,
+		},
+		{
+			Name: "only spaces",
+			Text: "   ",
+			Want: models.ErrInvalidText,
+		},
+		{
+			Name: "too long",
+			Text: strings.Repeat("a", MaxMessageLength+1),
+			Want: models.ErrInvalidText,
+		},
+	}
+
+	for _, test := range tests {
+		if err := ValidateMessage(test.Text); !errors.Is(err, test.Want) {
+			t.Errorf("\ntest: %s\nvalue: %v\nexpected: %v", test.Name, err, test.Want)
+		}
+	}
+}
+
+func TestValidateOfferAmount(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		Name   string
+		Amount int64
+		Want   error
+	}{
+		{
+			Name:   "valid amount",
+			Amount: 500,
+			Want:   nil,
+		},
+		{
+			Name:   "min amount",
+			Amount: MinPrice,
+			Want:   nil,
+		},
+		{
+			Name:   "zero amount",
+			Amount: 0,
+			Want:   models.ErrInvalidPrice,
+		},
+		{
+			Name:   "negative amount",
+			Amount: -10,
+			Want:   models.ErrInvalidPrice,
+		},
+		{
+			Name:   "above max",
+			Amount: MaxPrice + 1,
+			Want:   models.ErrInvalidPrice,
+		},
+	}
+
+	for _, test := range tests {
+		if err := ValidateOfferAmount(test.Amount); !errors.Is(err, test.Want) {
+			t.Errorf("\ntest: %s\nvalue: %v\nexpected: %v", test.Name, err, test.Want)
+		}
+	}
+}
+
+func TestValidateAuction(t *testing.T) {
+	t.Parallel()
+	now := time.Now()
+	tests := []struct {
+		Name    string
+		Auction *models.Auction
+		Want    error
+	}{
+		{
+			Name:    "valid auction",
+			Auction: &models.Auction{StartPrice: 1000, MinIncrement: 50, EndsAt: now.Add(24 * time.Hour)},
+			Want:    nil,
+		},
+		{
+			Name:    "zero start price",
+			Auction: &models.Auction{StartPrice: 0, MinIncrement: 50, EndsAt: now.Add(24 * time.Hour)},
+			Want:    models.ErrInvalidAuction,
+		},
+		{
+			Name:    "zero increment",
+			Auction: &models.Auction{StartPrice: 1000, MinIncrement: 0, EndsAt: now.Add(24 * time.Hour)},
+			Want:    models.ErrInvalidAuction,
+		},
+		{
+			Name:    "increment above start price",
+			Auction: &models.Auction{StartPrice: 1000, MinIncrement: 1001, EndsAt: now.Add(24 * time.Hour)},
+			Want:    models.ErrInvalidAuction,
+		},
+		{
+			Name:    "ends too soon",
+			Auction: &models.Auction{StartPrice: 1000, MinIncrement: 50, EndsAt: now.Add(time.Minute)},
+			Want:    models.ErrInvalidAuction,
+		},
+		{
+			Name:    "ends in the past",
+			Auction: &models.Auction{StartPrice: 1000, MinIncrement: 50, EndsAt: now.Add(-time.Hour)},
+			Want:    models.ErrInvalidAuction,
+		},
+		{
+			Name:    "lasts too long",
+			Auction: &models.Auction{StartPrice: 1000, MinIncrement: 50, EndsAt: now.Add(MaxAuctionDuration + time.Hour)},
+			Want:    models.ErrInvalidAuction,
+		},
+	}
+
+	for _, test := range tests {
+		if err := ValidateAuction(test.Auction, now); !errors.Is(err, test.Want) {
+			t.Errorf("\ntest: %s\nvalue: %v\nexpected: %v", test.Name, err, test.Want)
+		}
+	}
+}
+
+func TestValidateReview(t *testing.T) {
+	tests := []struct {
+		Name   string
+		Rating int
+		Text   string
+		Want   error
+	}{
+		{Name: "valid", Rating: 5, Text: "great seller", Want: nil},
+		{Name: "empty text", Rating: 1, Text: "", Want: nil},
+		{Name: "zero rating", Rating: 0, Text: "ok", Want: models.ErrInvalidRating},
+		{Name: "rating above max", Rating: 6, Text: "ok", Want: models.ErrInvalidRating},
+		{Name: "text too long", Rating: 4, Text: strings.Repeat("a", MaxReviewLength+1), Want: models.ErrInvalidText},
+	}
+
+	for _, test := range tests {
+		if err := ValidateReview(test.Rating, test.Text); !errors.Is(err, test.Want) {
+			t.Errorf("\ntest: %s\nvalue: %v\nexpected: %v", test.Name, err, test.Want)
+		}
+	}
+}
+
+func TestValidateReviewReply(t *testing.T) {
+	tests := []struct {
+		Name string
+		Text string
+		Want error
+	}{
+		{Name: "valid", Text: "thank you", Want: nil},
+		{Name: "blank", Text: "   ", Want: models.ErrInvalidText},
+		{Name: "too long", Text: strings.Repeat("a", MaxReviewReplyLength+1), Want: models.ErrInvalidText},
+	}
+
+	for _, test := range tests {
+		if err := ValidateReviewReply(test.Text); !errors.Is(err, test.Want) {
+			t.Errorf("\ntest: %s\nvalue: %v\nexpected: %v", test.Name, err, test.Want)
+		}
+	}
+}
+
+func TestValidateProfileUpdate(t *testing.T) {
+	name := "Иван Петров"
+	longName := strings.Repeat("я", MaxDisplayNameLength+1)
+	longBio := strings.Repeat("a", MaxBioLength+1)
+
+	tests := []struct {
+		Name   string
+		Update *models.ProfileUpdate
+		Want   error
+	}{
+		{Name: "empty update", Update: &models.ProfileUpdate{}, Want: nil},
+		{Name: "cyrillic name", Update: &models.ProfileUpdate{DisplayName: &name}, Want: nil},
+		{Name: "name too long", Update: &models.ProfileUpdate{DisplayName: &longName}, Want: models.ErrInvalidText},
+		{Name: "bio too long", Update: &models.ProfileUpdate{Bio: &longBio}, Want: models.ErrInvalidText},
+	}
+
+	for _, test := range tests {
+		if err := ValidateProfileUpdate(test.Update); !errors.Is(err, test.Want) {
+			t.Errorf("\ntest: %s\nvalue: %v\nexpected: %v", test.Name, err, test.Want)
+		}
+	}
+}
+
+func TestValidateReport(t *testing.T) {
+	tests := []struct {
+		Name   string
+		Reason models.ReportReason
+		Text   string
+		Want   error
+	}{
+		{Name: "valid", Reason: models.ReportScam, Text: "asks for prepayment", Want: nil},
+		{Name: "no text", Reason: models.ReportProhibited, Text: "", Want: nil},
+		{Name: "other with text", Reason: models.ReportOther, Text: "duplicate of another post", Want: nil},
+		{Name: "other without text", Reason: models.ReportOther, Text: "", Want: models.ErrInvalidText},
+		{Name: "unknown reason", Reason: "boring", Text: "", Want: models.ErrInvalidReportReason},
+		{Name: "text too long", Reason: models.ReportSpam, Text: strings.Repeat("я", MaxReportLength+1), Want: models.ErrInvalidText},
+	}
+
+	for _, test := range tests {
+		if err := ValidateReport(test.Reason, test.Text); !errors.Is(err, test.Want) {
+			t.Errorf("\ntest: %s\nvalue: %v\nexpected: %v", test.Name, err, test.Want)
+		}
+	}
+}
@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS saved_searches (
+		id UUID PRIMARY KEY,
+        user_id UUID NOT NULL,
+        min_price INTEGER NOT NULL DEFAULT 0,
+        max_price INTEGER NOT NULL DEFAULT 0,
+        sort_by TEXT NOT NULL DEFAULT '',
+        sort_order TEXT NOT NULL DEFAULT '',
+        created_at TIMESTAMP NOT NULL,
+        FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE
+        );
+
+CREATE INDEX IF NOT EXISTS saved_searches_user_id_idx ON saved_searches(user_id);
+
+CREATE TABLE IF NOT EXISTS saved_search_matches (
+		search_id UUID NOT NULL,
+        post_id UUID NOT NULL,
+        matched_at TIMESTAMP NOT NULL,
+        seen_at TIMESTAMP,
+        PRIMARY KEY(search_id, post_id),
+        FOREIGN KEY(search_id) REFERENCES saved_searches(id) ON DELETE CASCADE,
+        FOREIGN KEY(post_id) REFERENCES posts(id) ON DELETE CASCADE
+        );
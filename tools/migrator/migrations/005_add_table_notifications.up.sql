@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS notifications (
+		id UUID PRIMARY KEY,
+        user_id UUID NOT NULL,
+        kind TEXT NOT NULL,
+        payload JSONB NOT NULL,
+        created_at TIMESTAMP NOT NULL,
+        read_at TIMESTAMP,
+        FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE
+        );
+
+CREATE INDEX IF NOT EXISTS notifications_user_id_created_at_idx ON notifications(user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS notifications_unread_idx ON notifications(user_id) WHERE read_at IS NULL;
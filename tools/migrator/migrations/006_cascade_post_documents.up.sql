@@ -0,0 +1,2 @@
+ALTER TABLE documents DROP CONSTRAINT IF EXISTS documents_post_id_fkey;
+ALTER TABLE documents ADD CONSTRAINT documents_post_id_fkey FOREIGN KEY(post_id) REFERENCES posts(id) ON DELETE CASCADE;
@@ -0,0 +1,29 @@
+CREATE TABLE IF NOT EXISTS conversations (
+		id UUID PRIMARY KEY,
+        post_id UUID NOT NULL,
+        buyer_id UUID NOT NULL,
+        seller_id UUID NOT NULL,
+        created_at TIMESTAMP NOT NULL,
+        last_message_at TIMESTAMP NOT NULL,
+        UNIQUE(post_id, buyer_id),
+        FOREIGN KEY(post_id) REFERENCES posts(id) ON DELETE CASCADE,
+        FOREIGN KEY(buyer_id) REFERENCES users(id) ON DELETE CASCADE,
+        FOREIGN KEY(seller_id) REFERENCES users(id) ON DELETE CASCADE
+        );
+
+CREATE INDEX IF NOT EXISTS conversations_buyer_id_idx ON conversations(buyer_id, last_message_at DESC);
+CREATE INDEX IF NOT EXISTS conversations_seller_id_idx ON conversations(seller_id, last_message_at DESC);
+
+CREATE TABLE IF NOT EXISTS messages (
+		id UUID PRIMARY KEY,
+        conversation_id UUID NOT NULL,
+        sender_id UUID NOT NULL,
+        text TEXT NOT NULL,
+        created_at TIMESTAMP NOT NULL,
+        read_at TIMESTAMP,
+        FOREIGN KEY(conversation_id) REFERENCES conversations(id) ON DELETE CASCADE,
+        FOREIGN KEY(sender_id) REFERENCES users(id) ON DELETE CASCADE
+        );
+
+CREATE INDEX IF NOT EXISTS messages_conversation_id_created_at_idx ON messages(conversation_id, created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS messages_unread_idx ON messages(conversation_id, sender_id) WHERE read_at IS NULL;
@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS offers (
+		id UUID PRIMARY KEY,
+        post_id UUID NOT NULL,
+        buyer_id UUID NOT NULL,
+        seller_id UUID NOT NULL,
+        proposer_id UUID NOT NULL,
+        previous_id UUID,
+        amount BIGINT NOT NULL,
+        status TEXT NOT NULL,
+        created_at TIMESTAMP NOT NULL,
+        expires_at TIMESTAMP NOT NULL,
+        responded_at TIMESTAMP,
+        FOREIGN KEY(post_id) REFERENCES posts(id) ON DELETE CASCADE,
+        FOREIGN KEY(buyer_id) REFERENCES users(id) ON DELETE CASCADE,
+        FOREIGN KEY(seller_id) REFERENCES users(id) ON DELETE CASCADE,
+        FOREIGN KEY(previous_id) REFERENCES offers(id) ON DELETE SET NULL
+        );
+
+CREATE UNIQUE INDEX IF NOT EXISTS offers_pending_post_id_buyer_id_idx ON offers(post_id, buyer_id) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS offers_buyer_id_idx ON offers(buyer_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS offers_seller_id_idx ON offers(seller_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS offers_pending_expires_at_idx ON offers(expires_at) WHERE status = 'pending';
@@ -0,0 +1,28 @@
+CREATE TABLE IF NOT EXISTS auctions (
+		post_id UUID PRIMARY KEY,
+        start_price BIGINT NOT NULL,
+        min_increment BIGINT NOT NULL,
+        ends_at TIMESTAMP NOT NULL,
+        current_bid BIGINT NOT NULL DEFAULT 0,
+        bid_count INTEGER NOT NULL DEFAULT 0,
+        leader_id UUID,
+        closed_at TIMESTAMP,
+        winner_id UUID,
+        FOREIGN KEY(post_id) REFERENCES posts(id) ON DELETE CASCADE,
+        FOREIGN KEY(leader_id) REFERENCES users(id) ON DELETE SET NULL,
+        FOREIGN KEY(winner_id) REFERENCES users(id) ON DELETE SET NULL
+        );
+
+CREATE INDEX IF NOT EXISTS auctions_open_ends_at_idx ON auctions(ends_at) WHERE closed_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS bids (
+		id UUID PRIMARY KEY,
+        post_id UUID NOT NULL,
+        bidder_id UUID NOT NULL,
+        amount BIGINT NOT NULL,
+        created_at TIMESTAMP NOT NULL,
+        FOREIGN KEY(post_id) REFERENCES auctions(post_id) ON DELETE CASCADE,
+        FOREIGN KEY(bidder_id) REFERENCES users(id) ON DELETE CASCADE
+        );
+
+CREATE INDEX IF NOT EXISTS bids_post_id_created_at_idx ON bids(post_id, created_at DESC);
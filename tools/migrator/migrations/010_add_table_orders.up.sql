@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS orders (
+		id UUID PRIMARY KEY,
+        post_id UUID NOT NULL,
+        buyer_id UUID NOT NULL,
+        seller_id UUID NOT NULL,
+        amount BIGINT NOT NULL,
+        status TEXT NOT NULL,
+        payment_id TEXT,
+        created_at TIMESTAMP NOT NULL,
+        updated_at TIMESTAMP NOT NULL,
+        expires_at TIMESTAMP NOT NULL,
+        FOREIGN KEY(post_id) REFERENCES posts(id) ON DELETE CASCADE,
+        FOREIGN KEY(buyer_id) REFERENCES users(id) ON DELETE CASCADE,
+        FOREIGN KEY(seller_id) REFERENCES users(id) ON DELETE CASCADE
+        );
+
+CREATE UNIQUE INDEX IF NOT EXISTS orders_reserved_post_id_idx ON orders(post_id) WHERE status <> 'cancelled';
+CREATE INDEX IF NOT EXISTS orders_buyer_id_idx ON orders(buyer_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS orders_seller_id_idx ON orders(seller_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS orders_pending_expires_at_idx ON orders(expires_at) WHERE status = 'pending';
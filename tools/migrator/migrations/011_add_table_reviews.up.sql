@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS reviews (
+		id UUID PRIMARY KEY,
+        post_id UUID,
+        seller_id UUID NOT NULL,
+        author_id UUID NOT NULL,
+        rating SMALLINT NOT NULL CHECK (rating BETWEEN 1 AND 5),
+        text TEXT NOT NULL,
+        reply TEXT NOT NULL DEFAULT '',
+        reply_status TEXT NOT NULL DEFAULT '',
+        created_at TIMESTAMP NOT NULL,
+        replied_at TIMESTAMP,
+        CONSTRAINT reviews_post_id_author_id_key UNIQUE (post_id, author_id),
+        FOREIGN KEY(post_id) REFERENCES posts(id) ON DELETE SET NULL,
+        FOREIGN KEY(seller_id) REFERENCES users(id) ON DELETE CASCADE,
+        FOREIGN KEY(author_id) REFERENCES users(id) ON DELETE CASCADE
+        );
+
+CREATE INDEX IF NOT EXISTS reviews_seller_id_idx ON reviews(seller_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS reviews_pending_reply_idx ON reviews(replied_at) WHERE reply_status = 'pending';
@@ -0,0 +1,5 @@
+ALTER TABLE users
+        ADD COLUMN IF NOT EXISTS display_name VARCHAR(64) NOT NULL DEFAULT '',
+        ADD COLUMN IF NOT EXISTS bio TEXT NOT NULL DEFAULT '',
+        ADD COLUMN IF NOT EXISTS avatar_path TEXT NOT NULL DEFAULT '',
+        ADD COLUMN IF NOT EXISTS registered_at TIMESTAMP NOT NULL DEFAULT NOW();
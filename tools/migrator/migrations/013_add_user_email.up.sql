@@ -0,0 +1,5 @@
+ALTER TABLE users
+        ADD COLUMN IF NOT EXISTS email VARCHAR(254),
+        ADD COLUMN IF NOT EXISTS email_verified_at TIMESTAMP;
+
+CREATE UNIQUE INDEX IF NOT EXISTS users_email_key ON users (email);
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS user_totp (
+        user_id UUID PRIMARY KEY,
+        secret TEXT NOT NULL,
+        enabled_at TIMESTAMP,
+        last_step BIGINT NOT NULL DEFAULT 0,
+        created_at TIMESTAMP NOT NULL,
+        FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE
+        );
+
+CREATE TABLE IF NOT EXISTS user_recovery_codes (
+        user_id UUID NOT NULL,
+        code_hash TEXT NOT NULL,
+        used_at TIMESTAMP,
+        PRIMARY KEY (user_id, code_hash),
+        FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE
+        );
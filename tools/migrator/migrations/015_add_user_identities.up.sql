@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS user_identities (
+        provider VARCHAR(64) NOT NULL,
+        subject VARCHAR(255) NOT NULL,
+        user_id UUID NOT NULL,
+        created_at TIMESTAMP NOT NULL,
+        PRIMARY KEY (provider, subject),
+        UNIQUE (user_id, provider),
+        FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE
+        );
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS api_keys (
+        id UUID PRIMARY KEY,
+        user_id UUID NOT NULL,
+        name VARCHAR(64) NOT NULL,
+        key_hash TEXT NOT NULL UNIQUE,
+        prefix VARCHAR(16) NOT NULL,
+        scopes TEXT[] NOT NULL,
+        expires_at TIMESTAMP,
+        last_used_at TIMESTAMP,
+        created_at TIMESTAMP NOT NULL,
+        FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE
+        );
+
+CREATE INDEX IF NOT EXISTS api_keys_user_id_idx ON api_keys(user_id, created_at);
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS audit_records;
+
+ALTER TABLE users
+        DROP COLUMN IF EXISTS banned_at,
+        DROP COLUMN IF EXISTS banned_until,
+        DROP COLUMN IF EXISTS ban_reason,
+        DROP COLUMN IF EXISTS banned_by;
@@ -0,0 +1,17 @@
+ALTER TABLE users
+        ADD COLUMN IF NOT EXISTS banned_at TIMESTAMP,
+        ADD COLUMN IF NOT EXISTS banned_until TIMESTAMP,
+        ADD COLUMN IF NOT EXISTS ban_reason TEXT,
+        ADD COLUMN IF NOT EXISTS banned_by UUID;
+
+CREATE TABLE IF NOT EXISTS audit_records (
+        id UUID PRIMARY KEY,
+        actor_id UUID NOT NULL,
+        target_id UUID NOT NULL,
+        action VARCHAR(16) NOT NULL,
+        reason TEXT NOT NULL,
+        expires_at TIMESTAMP,
+        created_at TIMESTAMP NOT NULL
+        );
+
+CREATE INDEX IF NOT EXISTS audit_records_target_id_idx ON audit_records(target_id, created_at);
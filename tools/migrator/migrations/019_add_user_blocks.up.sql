@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS user_blocks (
+        blocker_id UUID NOT NULL,
+        blocked_id UUID NOT NULL,
+        created_at TIMESTAMP NOT NULL,
+        PRIMARY KEY(blocker_id, blocked_id),
+        FOREIGN KEY(blocker_id) REFERENCES users(id) ON DELETE CASCADE,
+        FOREIGN KEY(blocked_id) REFERENCES users(id) ON DELETE CASCADE
+        );
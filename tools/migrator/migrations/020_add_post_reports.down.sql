@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS post_reports;
+
+ALTER TABLE posts
+        DROP COLUMN IF EXISTS hidden_at;
@@ -0,0 +1,19 @@
+ALTER TABLE posts
+        ADD COLUMN IF NOT EXISTS hidden_at TIMESTAMP;
+
+CREATE TABLE IF NOT EXISTS post_reports (
+        id UUID PRIMARY KEY,
+        post_id UUID NOT NULL,
+        reporter_id UUID NOT NULL,
+        reason VARCHAR(32) NOT NULL,
+        text TEXT NOT NULL DEFAULT '',
+        status VARCHAR(16) NOT NULL DEFAULT 'open',
+        created_at TIMESTAMP NOT NULL,
+        closed_at TIMESTAMP,
+        closed_by UUID,
+        UNIQUE(post_id, reporter_id),
+        FOREIGN KEY(post_id) REFERENCES posts(id) ON DELETE CASCADE,
+        FOREIGN KEY(reporter_id) REFERENCES users(id) ON DELETE CASCADE
+        );
+
+CREATE INDEX IF NOT EXISTS post_reports_status_idx ON post_reports(status, created_at);